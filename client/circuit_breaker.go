@@ -0,0 +1,198 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/generated/thrift/rpc"
+
+	"github.com/uber-go/tally"
+)
+
+type circuitBreakerState int
+
+const (
+	// circuitBreakerClosed is the state of a circuit breaker that allows all
+	// requests to the host
+	circuitBreakerClosed circuitBreakerState = iota
+
+	// circuitBreakerOpen is the state of a circuit breaker that fails all
+	// requests to the host fast after consecutive requests failed
+	circuitBreakerOpen
+
+	// circuitBreakerHalfOpen is the state of a circuit breaker that allows a
+	// single probe request to the host to determine whether to close
+	circuitBreakerHalfOpen
+)
+
+func (s circuitBreakerState) String() string {
+	switch s {
+	case circuitBreakerClosed:
+		return "closed"
+	case circuitBreakerOpen:
+		return "open"
+	case circuitBreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type circuitBreakerMetrics struct {
+	state      tally.Gauge
+	opened     tally.Counter
+	halfOpened tally.Counter
+	closed     tally.Counter
+	rejected   tally.Counter
+}
+
+func newCircuitBreakerMetrics(scope tally.Scope) circuitBreakerMetrics {
+	return circuitBreakerMetrics{
+		state:      scope.Gauge("state"),
+		opened:     scope.Counter("opened"),
+		halfOpened: scope.Counter("half-opened"),
+		closed:     scope.Counter("closed"),
+		rejected:   scope.Counter("rejected"),
+	}
+}
+
+// circuitBreaker opens after a number of consecutive requests to a host fail,
+// failing further requests fast until the open duration elapses at which
+// point it half opens and allows a probe request, closing if the probe
+// succeeds and opening again if it fails. If a probe never reports its result
+// another probe is allowed each time the open duration elapses.
+type circuitBreaker struct {
+	sync.Mutex
+
+	failLimit    int
+	openDuration time.Duration
+	nowFn        clock.NowFn
+	metrics      circuitBreakerMetrics
+	state        circuitBreakerState
+	failures     int
+	lastChangeAt time.Time
+}
+
+func newCircuitBreaker(
+	failLimit int,
+	openDuration time.Duration,
+	nowFn clock.NowFn,
+	scope tally.Scope,
+) *circuitBreaker {
+	b := &circuitBreaker{
+		failLimit:    failLimit,
+		openDuration: openDuration,
+		nowFn:        nowFn,
+		metrics:      newCircuitBreakerMetrics(scope),
+	}
+	b.metrics.state.Update(float64(circuitBreakerClosed))
+	return b
+}
+
+// Allow returns whether a request to the host is allowed.
+func (b *circuitBreaker) Allow() bool {
+	if b.failLimit <= 0 {
+		return true
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case circuitBreakerOpen:
+		now := b.nowFn()
+		if now.Sub(b.lastChangeAt) < b.openDuration {
+			b.metrics.rejected.Inc(1)
+			return false
+		}
+		// Allow this request as the probe
+		b.setStateWithLock(circuitBreakerHalfOpen, now)
+		b.metrics.halfOpened.Inc(1)
+	case circuitBreakerHalfOpen:
+		now := b.nowFn()
+		if now.Sub(b.lastChangeAt) < b.openDuration {
+			// Probe already in flight
+			b.metrics.rejected.Inc(1)
+			return false
+		}
+		// Probe never reported its result, allow another
+		b.lastChangeAt = now
+	}
+	return true
+}
+
+// Report reports the result of a request to the host.
+func (b *circuitBreaker) Report(err error) {
+	if b.failLimit <= 0 {
+		return
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if !isCircuitBreakerError(err) {
+		b.failures = 0
+		if b.state != circuitBreakerClosed {
+			b.setStateWithLock(circuitBreakerClosed, b.nowFn())
+			b.metrics.closed.Inc(1)
+		}
+		return
+	}
+
+	b.failures++
+	switch b.state {
+	case circuitBreakerClosed:
+		if b.failures >= b.failLimit {
+			b.setStateWithLock(circuitBreakerOpen, b.nowFn())
+			b.metrics.opened.Inc(1)
+		}
+	case circuitBreakerHalfOpen:
+		b.setStateWithLock(circuitBreakerOpen, b.nowFn())
+		b.metrics.opened.Inc(1)
+	}
+}
+
+// State returns the state of the circuit breaker.
+func (b *circuitBreaker) State() circuitBreakerState {
+	b.Lock()
+	state := b.state
+	b.Unlock()
+	return state
+}
+
+func (b *circuitBreaker) setStateWithLock(state circuitBreakerState, now time.Time) {
+	b.state = state
+	b.lastChangeAt = now
+	b.metrics.state.Update(float64(state))
+}
+
+// isCircuitBreakerError returns whether an error counts towards opening the
+// circuit breaker, errors returned by the host itself do not count as the
+// host is responding and only timeouts and transport errors do.
+func isCircuitBreakerError(err error) bool {
+	switch err.(type) {
+	case nil, *rpc.Error, *rpc.WriteBatchRawErrors:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/generated/thrift/rpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+func TestCircuitBreakerOpensAndHalfOpensAndCloses(t *testing.T) {
+	now := time.Now()
+	nowFn := func() time.Time { return now }
+	b := newCircuitBreaker(3, time.Second, nowFn, tally.NoopScope)
+	timeoutErr := errors.New("timed out")
+
+	// Errors returned by the host do not count towards opening
+	b.Report(timeoutErr)
+	b.Report(timeoutErr)
+	b.Report(&rpc.Error{Type: rpc.ErrorType_INTERNAL_ERROR})
+	b.Report(timeoutErr)
+	assert.Equal(t, circuitBreakerClosed, b.State())
+
+	// Successful requests reset the consecutive failures
+	b.Report(nil)
+	b.Report(timeoutErr)
+	b.Report(timeoutErr)
+	assert.Equal(t, circuitBreakerClosed, b.State())
+	assert.True(t, b.Allow())
+
+	b.Report(timeoutErr)
+	assert.Equal(t, circuitBreakerOpen, b.State())
+	assert.False(t, b.Allow())
+
+	// Half opens to allow a single probe once the open duration elapses
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow())
+	assert.Equal(t, circuitBreakerHalfOpen, b.State())
+	assert.False(t, b.Allow())
+
+	// Opens again if the probe fails
+	b.Report(timeoutErr)
+	assert.Equal(t, circuitBreakerOpen, b.State())
+	assert.False(t, b.Allow())
+
+	// Closes if the next probe succeeds
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow())
+	b.Report(nil)
+	assert.Equal(t, circuitBreakerClosed, b.State())
+	assert.True(t, b.Allow())
+}
+
+func TestCircuitBreakerAllowsProbeIfProbeNeverReports(t *testing.T) {
+	now := time.Now()
+	nowFn := func() time.Time { return now }
+	b := newCircuitBreaker(1, time.Second, nowFn, tally.NoopScope)
+
+	b.Report(errors.New("timed out"))
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow())
+	assert.False(t, b.Allow())
+
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow())
+	assert.Equal(t, circuitBreakerHalfOpen, b.State())
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := newCircuitBreaker(0, time.Second, time.Now, tally.NoopScope)
+	for i := 0; i < 10; i++ {
+		b.Report(errors.New("timed out"))
+	}
+	assert.Equal(t, circuitBreakerClosed, b.State())
+	assert.True(t, b.Allow())
+}
@@ -32,6 +32,7 @@ import (
 	rpc "github.com/m3db/m3db/generated/thrift/rpc"
 	block "github.com/m3db/m3db/storage/block"
 	result "github.com/m3db/m3db/storage/bootstrap/result"
+	index "github.com/m3db/m3db/storage/index"
 	topology "github.com/m3db/m3db/topology"
 	ts "github.com/m3db/m3db/ts"
 	instrument "github.com/m3db/m3x/instrument"
@@ -127,6 +128,46 @@ func (_mr *_MockSessionRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5 interf
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockSession) WriteTagged(namespace string, id string, tags ts.Tags, t time0.Time, value float64, unit time.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", namespace, id, tags, t, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSessionRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockSession) WriteAsync(namespace string, id string, t time0.Time, value float64, unit time.Unit, annotation []byte, callback WriteCallback) error {
+	ret := _m.ctrl.Call(_m, "WriteAsync", namespace, id, t, value, unit, annotation, callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSessionRecorder) WriteAsync(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteAsync", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockSession) WriteBatch(namespace string, writes []BatchWrite) error {
+	ret := _m.ctrl.Call(_m, "WriteBatch", namespace, writes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSessionRecorder) WriteBatch(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteBatch", arg0, arg1)
+}
+
+func (_m *MockSession) Delete(namespace string, id string, start time0.Time, end time0.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", namespace, id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockSessionRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockSession) Fetch(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterator, error) {
 	ret := _m.ctrl.Call(_m, "Fetch", namespace, id, startInclusive, endExclusive)
 	ret0, _ := ret[0].(encoding.SeriesIterator)
@@ -149,6 +190,39 @@ func (_mr *_MockSessionRecorder) FetchAll(arg0, arg1, arg2, arg3 interface{}) *g
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAll", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockSession) FetchDownsampled(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterator, error) {
+	ret := _m.ctrl.Call(_m, "FetchDownsampled", namespace, id, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSessionRecorder) FetchDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockSession) FetchAllDownsampled(namespace string, ids []string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchAllDownsampled", namespace, ids, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSessionRecorder) FetchAllDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAllDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockSession) FetchTagged(namespace string, query index.Query, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchTagged", namespace, query, startInclusive, endExclusive)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockSessionRecorder) FetchTagged(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchTagged", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockSession) ShardID(id string) (uint32, error) {
 	ret := _m.ctrl.Call(_m, "ShardID", id)
 	ret0, _ := ret[0].(uint32)
@@ -381,6 +455,46 @@ func (_mr *_MockAdminSessionRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5 i
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockAdminSession) WriteTagged(namespace string, id string, tags ts.Tags, t time0.Time, value float64, unit time.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", namespace, id, tags, t, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockAdminSessionRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockAdminSession) WriteAsync(namespace string, id string, t time0.Time, value float64, unit time.Unit, annotation []byte, callback WriteCallback) error {
+	ret := _m.ctrl.Call(_m, "WriteAsync", namespace, id, t, value, unit, annotation, callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockAdminSessionRecorder) WriteAsync(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteAsync", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockAdminSession) WriteBatch(namespace string, writes []BatchWrite) error {
+	ret := _m.ctrl.Call(_m, "WriteBatch", namespace, writes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockAdminSessionRecorder) WriteBatch(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteBatch", arg0, arg1)
+}
+
+func (_m *MockAdminSession) Delete(namespace string, id string, start time0.Time, end time0.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", namespace, id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockAdminSessionRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockAdminSession) Fetch(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterator, error) {
 	ret := _m.ctrl.Call(_m, "Fetch", namespace, id, startInclusive, endExclusive)
 	ret0, _ := ret[0].(encoding.SeriesIterator)
@@ -403,6 +517,39 @@ func (_mr *_MockAdminSessionRecorder) FetchAll(arg0, arg1, arg2, arg3 interface{
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAll", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockAdminSession) FetchDownsampled(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterator, error) {
+	ret := _m.ctrl.Call(_m, "FetchDownsampled", namespace, id, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockAdminSessionRecorder) FetchDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockAdminSession) FetchAllDownsampled(namespace string, ids []string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchAllDownsampled", namespace, ids, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockAdminSessionRecorder) FetchAllDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAllDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockAdminSession) FetchTagged(namespace string, query index.Query, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchTagged", namespace, query, startInclusive, endExclusive)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockAdminSessionRecorder) FetchTagged(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchTagged", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockAdminSession) ShardID(id string) (uint32, error) {
 	ret := _m.ctrl.Call(_m, "ShardID", id)
 	ret0, _ := ret[0].(uint32)
@@ -519,6 +666,46 @@ func (_mr *_MockclientSessionRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockclientSession) WriteTagged(namespace string, id string, tags ts.Tags, t time0.Time, value float64, unit time.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", namespace, id, tags, t, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockclientSessionRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockclientSession) WriteAsync(namespace string, id string, t time0.Time, value float64, unit time.Unit, annotation []byte, callback WriteCallback) error {
+	ret := _m.ctrl.Call(_m, "WriteAsync", namespace, id, t, value, unit, annotation, callback)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockclientSessionRecorder) WriteAsync(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteAsync", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockclientSession) WriteBatch(namespace string, writes []BatchWrite) error {
+	ret := _m.ctrl.Call(_m, "WriteBatch", namespace, writes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockclientSessionRecorder) WriteBatch(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteBatch", arg0, arg1)
+}
+
+func (_m *MockclientSession) Delete(namespace string, id string, start time0.Time, end time0.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", namespace, id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockclientSessionRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockclientSession) Fetch(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterator, error) {
 	ret := _m.ctrl.Call(_m, "Fetch", namespace, id, startInclusive, endExclusive)
 	ret0, _ := ret[0].(encoding.SeriesIterator)
@@ -541,6 +728,39 @@ func (_mr *_MockclientSessionRecorder) FetchAll(arg0, arg1, arg2, arg3 interface
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAll", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockclientSession) FetchDownsampled(namespace string, id string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterator, error) {
+	ret := _m.ctrl.Call(_m, "FetchDownsampled", namespace, id, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockclientSessionRecorder) FetchDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockclientSession) FetchAllDownsampled(namespace string, ids []string, startInclusive time0.Time, endExclusive time0.Time, downsample Downsample) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchAllDownsampled", namespace, ids, startInclusive, endExclusive, downsample)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockclientSessionRecorder) FetchAllDownsampled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchAllDownsampled", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockclientSession) FetchTagged(namespace string, query index.Query, startInclusive time0.Time, endExclusive time0.Time) (encoding.SeriesIterators, error) {
+	ret := _m.ctrl.Call(_m, "FetchTagged", namespace, query, startInclusive, endExclusive)
+	ret0, _ := ret[0].(encoding.SeriesIterators)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockclientSessionRecorder) FetchTagged(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchTagged", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockclientSession) ShardID(id string) (uint32, error) {
 	ret := _m.ctrl.Call(_m, "ShardID", id)
 	ret0, _ := ret[0].(uint32)
@@ -675,6 +895,16 @@ func (_mr *_MockhostQueueRecorder) Len() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Len")
 }
 
+func (_m *MockhostQueue) FetchLatency(percentile float64) time0.Duration {
+	ret := _m.ctrl.Call(_m, "FetchLatency", percentile)
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockhostQueueRecorder) FetchLatency(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchLatency", arg0)
+}
+
 func (_m *MockhostQueue) Enqueue(op op) error {
 	ret := _m.ctrl.Call(_m, "Enqueue", op)
 	ret0, _ := ret[0].(error)
@@ -705,6 +935,16 @@ func (_mr *_MockhostQueueRecorder) ConnectionCount() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ConnectionCount")
 }
 
+func (_m *MockhostQueue) CircuitBreakerState() circuitBreakerState {
+	ret := _m.ctrl.Call(_m, "CircuitBreakerState")
+	ret0, _ := ret[0].(circuitBreakerState)
+	return ret0
+}
+
+func (_mr *_MockhostQueueRecorder) CircuitBreakerState() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CircuitBreakerState")
+}
+
 func (_m *MockhostQueue) ConnectionPool() connectionPool {
 	ret := _m.ctrl.Call(_m, "ConnectionPool")
 	ret0, _ := ret[0].(connectionPool)
@@ -783,6 +1023,24 @@ func (_mr *_MockconnectionPoolRecorder) NextClient() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "NextClient")
 }
 
+func (_m *MockconnectionPool) ReportResult(err error) {
+	_m.ctrl.Call(_m, "ReportResult", err)
+}
+
+func (_mr *_MockconnectionPoolRecorder) ReportResult(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReportResult", arg0)
+}
+
+func (_m *MockconnectionPool) CircuitBreakerState() circuitBreakerState {
+	ret := _m.ctrl.Call(_m, "CircuitBreakerState")
+	ret0, _ := ret[0].(circuitBreakerState)
+	return ret0
+}
+
+func (_mr *_MockconnectionPoolRecorder) CircuitBreakerState() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CircuitBreakerState")
+}
+
 func (_m *MockconnectionPool) Close() {
 	_m.ctrl.Call(_m, "Close")
 }
@@ -1045,6 +1303,86 @@ func (_mr *_MockOptionsRecorder) ReadConsistencyLevel() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadConsistencyLevel")
 }
 
+func (_m *MockOptions) SetReadReplicaStrategy(value ReadReplicaStrategy) Options {
+	ret := _m.ctrl.Call(_m, "SetReadReplicaStrategy", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetReadReplicaStrategy(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadReplicaStrategy", arg0)
+}
+
+func (_m *MockOptions) ReadReplicaStrategy() ReadReplicaStrategy {
+	ret := _m.ctrl.Call(_m, "ReadReplicaStrategy")
+	ret0, _ := ret[0].(ReadReplicaStrategy)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) ReadReplicaStrategy() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadReplicaStrategy")
+}
+
+func (_m *MockOptions) SetReadHedgeDelay(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgeDelay", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetReadHedgeDelay(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgeDelay", arg0)
+}
+
+func (_m *MockOptions) ReadHedgeDelay() time0.Duration {
+	ret := _m.ctrl.Call(_m, "ReadHedgeDelay")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) ReadHedgeDelay() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgeDelay")
+}
+
+func (_m *MockOptions) SetReadHedgePercentile(value float64) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgePercentile", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetReadHedgePercentile(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgePercentile", arg0)
+}
+
+func (_m *MockOptions) ReadHedgePercentile() float64 {
+	ret := _m.ctrl.Call(_m, "ReadHedgePercentile")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) ReadHedgePercentile() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgePercentile")
+}
+
+func (_m *MockOptions) SetReadHedgeMaxRatio(value float64) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgeMaxRatio", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetReadHedgeMaxRatio(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgeMaxRatio", arg0)
+}
+
+func (_m *MockOptions) ReadHedgeMaxRatio() float64 {
+	ret := _m.ctrl.Call(_m, "ReadHedgeMaxRatio")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) ReadHedgeMaxRatio() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgeMaxRatio")
+}
+
 func (_m *MockOptions) SetChannelOptions(value *tchannel_go.ChannelOptions) Options {
 	ret := _m.ctrl.Call(_m, "SetChannelOptions", value)
 	ret0, _ := ret[0].(Options)
@@ -1345,6 +1683,46 @@ func (_mr *_MockOptionsRecorder) BackgroundHealthCheckFailThrottleFactor() *gomo
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "BackgroundHealthCheckFailThrottleFactor")
 }
 
+func (_m *MockOptions) SetHostCircuitBreakerFailLimit(value int) Options {
+	ret := _m.ctrl.Call(_m, "SetHostCircuitBreakerFailLimit", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHostCircuitBreakerFailLimit(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHostCircuitBreakerFailLimit", arg0)
+}
+
+func (_m *MockOptions) HostCircuitBreakerFailLimit() int {
+	ret := _m.ctrl.Call(_m, "HostCircuitBreakerFailLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HostCircuitBreakerFailLimit() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HostCircuitBreakerFailLimit")
+}
+
+func (_m *MockOptions) SetHostCircuitBreakerOpenDuration(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHostCircuitBreakerOpenDuration", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHostCircuitBreakerOpenDuration(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHostCircuitBreakerOpenDuration", arg0)
+}
+
+func (_m *MockOptions) HostCircuitBreakerOpenDuration() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HostCircuitBreakerOpenDuration")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HostCircuitBreakerOpenDuration() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HostCircuitBreakerOpenDuration")
+}
+
 func (_m *MockOptions) SetWriteRetrier(value retry.Retrier) Options {
 	ret := _m.ctrl.Call(_m, "SetWriteRetrier", value)
 	ret0, _ := ret[0].(Options)
@@ -1445,6 +1823,106 @@ func (_mr *_MockOptionsRecorder) WriteOpPoolSize() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteOpPoolSize")
 }
 
+func (_m *MockOptions) SetWriteAsyncMaxPending(value int) Options {
+	ret := _m.ctrl.Call(_m, "SetWriteAsyncMaxPending", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetWriteAsyncMaxPending(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetWriteAsyncMaxPending", arg0)
+}
+
+func (_m *MockOptions) WriteAsyncMaxPending() int {
+	ret := _m.ctrl.Call(_m, "WriteAsyncMaxPending")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) WriteAsyncMaxPending() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteAsyncMaxPending")
+}
+
+func (_m *MockOptions) SetHintsFilePathPrefix(value string) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsFilePathPrefix", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHintsFilePathPrefix(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsFilePathPrefix", arg0)
+}
+
+func (_m *MockOptions) HintsFilePathPrefix() string {
+	ret := _m.ctrl.Call(_m, "HintsFilePathPrefix")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HintsFilePathPrefix() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsFilePathPrefix")
+}
+
+func (_m *MockOptions) SetHintsTTL(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsTTL", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHintsTTL(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsTTL", arg0)
+}
+
+func (_m *MockOptions) HintsTTL() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HintsTTL")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HintsTTL() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsTTL")
+}
+
+func (_m *MockOptions) SetHintsMaxBytesPerHost(value int64) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsMaxBytesPerHost", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHintsMaxBytesPerHost(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsMaxBytesPerHost", arg0)
+}
+
+func (_m *MockOptions) HintsMaxBytesPerHost() int64 {
+	ret := _m.ctrl.Call(_m, "HintsMaxBytesPerHost")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HintsMaxBytesPerHost() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsMaxBytesPerHost")
+}
+
+func (_m *MockOptions) SetHintsReplayInterval(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsReplayInterval", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetHintsReplayInterval(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsReplayInterval", arg0)
+}
+
+func (_m *MockOptions) HintsReplayInterval() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HintsReplayInterval")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) HintsReplayInterval() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsReplayInterval")
+}
+
 func (_m *MockOptions) SetFetchBatchOpPoolSize(value int) Options {
 	ret := _m.ctrl.Call(_m, "SetFetchBatchOpPoolSize", value)
 	ret0, _ := ret[0].(Options)
@@ -1766,6 +2244,86 @@ func (_mr *_MockAdminOptionsRecorder) ReadConsistencyLevel() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadConsistencyLevel")
 }
 
+func (_m *MockAdminOptions) SetReadReplicaStrategy(value ReadReplicaStrategy) Options {
+	ret := _m.ctrl.Call(_m, "SetReadReplicaStrategy", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetReadReplicaStrategy(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadReplicaStrategy", arg0)
+}
+
+func (_m *MockAdminOptions) ReadReplicaStrategy() ReadReplicaStrategy {
+	ret := _m.ctrl.Call(_m, "ReadReplicaStrategy")
+	ret0, _ := ret[0].(ReadReplicaStrategy)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) ReadReplicaStrategy() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadReplicaStrategy")
+}
+
+func (_m *MockAdminOptions) SetReadHedgeDelay(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgeDelay", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetReadHedgeDelay(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgeDelay", arg0)
+}
+
+func (_m *MockAdminOptions) ReadHedgeDelay() time0.Duration {
+	ret := _m.ctrl.Call(_m, "ReadHedgeDelay")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) ReadHedgeDelay() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgeDelay")
+}
+
+func (_m *MockAdminOptions) SetReadHedgePercentile(value float64) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgePercentile", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetReadHedgePercentile(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgePercentile", arg0)
+}
+
+func (_m *MockAdminOptions) ReadHedgePercentile() float64 {
+	ret := _m.ctrl.Call(_m, "ReadHedgePercentile")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) ReadHedgePercentile() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgePercentile")
+}
+
+func (_m *MockAdminOptions) SetReadHedgeMaxRatio(value float64) Options {
+	ret := _m.ctrl.Call(_m, "SetReadHedgeMaxRatio", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetReadHedgeMaxRatio(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadHedgeMaxRatio", arg0)
+}
+
+func (_m *MockAdminOptions) ReadHedgeMaxRatio() float64 {
+	ret := _m.ctrl.Call(_m, "ReadHedgeMaxRatio")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) ReadHedgeMaxRatio() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadHedgeMaxRatio")
+}
+
 func (_m *MockAdminOptions) SetChannelOptions(value *tchannel_go.ChannelOptions) Options {
 	ret := _m.ctrl.Call(_m, "SetChannelOptions", value)
 	ret0, _ := ret[0].(Options)
@@ -2066,6 +2624,46 @@ func (_mr *_MockAdminOptionsRecorder) BackgroundHealthCheckFailThrottleFactor()
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "BackgroundHealthCheckFailThrottleFactor")
 }
 
+func (_m *MockAdminOptions) SetHostCircuitBreakerFailLimit(value int) Options {
+	ret := _m.ctrl.Call(_m, "SetHostCircuitBreakerFailLimit", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHostCircuitBreakerFailLimit(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHostCircuitBreakerFailLimit", arg0)
+}
+
+func (_m *MockAdminOptions) HostCircuitBreakerFailLimit() int {
+	ret := _m.ctrl.Call(_m, "HostCircuitBreakerFailLimit")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HostCircuitBreakerFailLimit() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HostCircuitBreakerFailLimit")
+}
+
+func (_m *MockAdminOptions) SetHostCircuitBreakerOpenDuration(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHostCircuitBreakerOpenDuration", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHostCircuitBreakerOpenDuration(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHostCircuitBreakerOpenDuration", arg0)
+}
+
+func (_m *MockAdminOptions) HostCircuitBreakerOpenDuration() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HostCircuitBreakerOpenDuration")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HostCircuitBreakerOpenDuration() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HostCircuitBreakerOpenDuration")
+}
+
 func (_m *MockAdminOptions) SetWriteRetrier(value retry.Retrier) Options {
 	ret := _m.ctrl.Call(_m, "SetWriteRetrier", value)
 	ret0, _ := ret[0].(Options)
@@ -2166,6 +2764,106 @@ func (_mr *_MockAdminOptionsRecorder) WriteOpPoolSize() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteOpPoolSize")
 }
 
+func (_m *MockAdminOptions) SetWriteAsyncMaxPending(value int) Options {
+	ret := _m.ctrl.Call(_m, "SetWriteAsyncMaxPending", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetWriteAsyncMaxPending(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetWriteAsyncMaxPending", arg0)
+}
+
+func (_m *MockAdminOptions) WriteAsyncMaxPending() int {
+	ret := _m.ctrl.Call(_m, "WriteAsyncMaxPending")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) WriteAsyncMaxPending() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteAsyncMaxPending")
+}
+
+func (_m *MockAdminOptions) SetHintsFilePathPrefix(value string) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsFilePathPrefix", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHintsFilePathPrefix(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsFilePathPrefix", arg0)
+}
+
+func (_m *MockAdminOptions) HintsFilePathPrefix() string {
+	ret := _m.ctrl.Call(_m, "HintsFilePathPrefix")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HintsFilePathPrefix() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsFilePathPrefix")
+}
+
+func (_m *MockAdminOptions) SetHintsTTL(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsTTL", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHintsTTL(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsTTL", arg0)
+}
+
+func (_m *MockAdminOptions) HintsTTL() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HintsTTL")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HintsTTL() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsTTL")
+}
+
+func (_m *MockAdminOptions) SetHintsMaxBytesPerHost(value int64) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsMaxBytesPerHost", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHintsMaxBytesPerHost(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsMaxBytesPerHost", arg0)
+}
+
+func (_m *MockAdminOptions) HintsMaxBytesPerHost() int64 {
+	ret := _m.ctrl.Call(_m, "HintsMaxBytesPerHost")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HintsMaxBytesPerHost() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsMaxBytesPerHost")
+}
+
+func (_m *MockAdminOptions) SetHintsReplayInterval(value time0.Duration) Options {
+	ret := _m.ctrl.Call(_m, "SetHintsReplayInterval", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) SetHintsReplayInterval(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetHintsReplayInterval", arg0)
+}
+
+func (_m *MockAdminOptions) HintsReplayInterval() time0.Duration {
+	ret := _m.ctrl.Call(_m, "HintsReplayInterval")
+	ret0, _ := ret[0].(time0.Duration)
+	return ret0
+}
+
+func (_mr *_MockAdminOptionsRecorder) HintsReplayInterval() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "HintsReplayInterval")
+}
+
 func (_m *MockAdminOptions) SetFetchBatchOpPoolSize(value int) Options {
 	ret := _m.ctrl.Call(_m, "SetFetchBatchOpPoolSize", value)
 	ret0, _ := ret[0].(Options)
@@ -46,6 +46,7 @@ const (
 var (
 	errConnectionPoolClosed           = errors.New("connection pool closed")
 	errConnectionPoolHasNoConnections = errors.New("connection pool has no connections")
+	errConnectionPoolCircuitOpen      = errors.New("connection pool circuit breaker open")
 )
 
 type connPool struct {
@@ -64,6 +65,7 @@ type connPool struct {
 	sleepConnect       sleepFn
 	sleepHealth        sleepFn
 	sleepHealthRetry   sleepFn
+	breaker            *circuitBreaker
 	state              state
 }
 
@@ -83,6 +85,16 @@ var globalNewConn = newConn
 
 func newConnectionPool(host topology.Host, opts Options) connectionPool {
 	seed := int64(murmur3.Sum32([]byte(host.Address())))
+	breaker := newCircuitBreaker(
+		opts.HostCircuitBreakerFailLimit(),
+		opts.HostCircuitBreakerOpenDuration(),
+		opts.ClockOptions().NowFn(),
+		opts.InstrumentOptions().MetricsScope().
+			SubScope("circuit-breaker").
+			Tagged(map[string]string{
+				"hostID": host.ID(),
+			}),
+	)
 
 	p := &connPool{
 		opts:               opts,
@@ -97,6 +109,7 @@ func newConnectionPool(host topology.Host, opts Options) connectionPool {
 		sleepConnect:       time.Sleep,
 		sleepHealth:        time.Sleep,
 		sleepHealthRetry:   time.Sleep,
+		breaker:            breaker,
 	}
 
 	return p
@@ -138,12 +151,24 @@ func (p *connPool) NextClient() (rpc.TChanNode, error) {
 		p.RUnlock()
 		return nil, errConnectionPoolHasNoConnections
 	}
+	if !p.breaker.Allow() {
+		p.RUnlock()
+		return nil, errConnectionPoolCircuitOpen
+	}
 	n := atomic.AddInt64(&p.used, 1)
 	conn := p.pool[n%p.poolLen]
 	p.RUnlock()
 	return conn.client, nil
 }
 
+func (p *connPool) ReportResult(err error) {
+	p.breaker.Report(err)
+}
+
+func (p *connPool) CircuitBreakerState() circuitBreakerState {
+	return p.breaker.State()
+}
+
 func (p *connPool) Close() {
 	p.Lock()
 	if p.state != stateOpen {
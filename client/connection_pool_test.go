@@ -320,3 +320,55 @@ func TestConnectionPoolHealthChecks(t *testing.T) {
 type nullChannel struct{}
 
 func (*nullChannel) Close() {}
+
+func TestConnectionPoolNextClientFailsFastWhenCircuitBreakerOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newConnectionPoolTestOptions().
+		SetHostCircuitBreakerFailLimit(2).
+		SetHostCircuitBreakerOpenDuration(time.Hour)
+	conns := newConnectionPool(h, opts).(*connPool)
+
+	// Add a connection without opening to avoid background connecting
+	client := rpc.NewMockTChanNode(ctrl)
+	conns.state = stateOpen
+	conns.pool = append(conns.pool, conn{channel: channelNone, client: client})
+	conns.poolLen = 1
+
+	next, err := conns.NextClient()
+	assert.NoError(t, err)
+	assert.Equal(t, client, next)
+
+	for i := 0; i < 2; i++ {
+		conns.ReportResult(fmt.Errorf("a timeout error"))
+	}
+	assert.Equal(t, circuitBreakerOpen, conns.CircuitBreakerState())
+
+	_, err = conns.NextClient()
+	assert.Equal(t, errConnectionPoolCircuitOpen, err)
+}
+
+func TestConnectionPoolCircuitBreakerDisabledByDefault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newConnectionPoolTestOptions()
+	assert.Equal(t, 0, opts.HostCircuitBreakerFailLimit())
+	conns := newConnectionPool(h, opts).(*connPool)
+
+	// Add a connection without opening to avoid background connecting
+	client := rpc.NewMockTChanNode(ctrl)
+	conns.state = stateOpen
+	conns.pool = append(conns.pool, conn{channel: channelNone, client: client})
+	conns.poolLen = 1
+
+	for i := 0; i < 10; i++ {
+		conns.ReportResult(fmt.Errorf("a timeout error"))
+	}
+	assert.Equal(t, circuitBreakerClosed, conns.CircuitBreakerState())
+
+	next, err := conns.NextClient()
+	assert.NoError(t, err)
+	assert.Equal(t, client, next)
+}
@@ -42,10 +42,11 @@ type fetchAttempt struct {
 }
 
 type fetchAttemptArgs struct {
-	namespace string
-	ids       []string
-	start     time.Time
-	end       time.Time
+	namespace  string
+	ids        []string
+	start      time.Time
+	end        time.Time
+	downsample Downsample
 }
 
 func (f *fetchAttempt) reset() {
@@ -55,7 +56,7 @@ func (f *fetchAttempt) reset() {
 
 func (f *fetchAttempt) perform() error {
 	result, err := f.session.fetchAllAttempt(f.args.namespace,
-		f.args.ids, f.args.start, f.args.end)
+		f.args.ids, f.args.start, f.args.end, f.args.downsample)
 	f.result = result
 
 	if IsBadRequestError(err) {
@@ -37,6 +37,8 @@ func (f *fetchBatchOp) reset() {
 	f.IncWrites()
 	f.request.RangeStart = 0
 	f.request.RangeEnd = 0
+	f.request.Step = 0
+	f.request.Aggregation = rpc.AggregationType_NONE
 	f.request.NameSpace = nil
 	for i := range f.request.Ids {
 		f.request.Ids[i] = nil
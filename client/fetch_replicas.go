@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+
+	"github.com/m3db/m3db/generated/thrift/rpc"
+)
+
+// fetchHedgeBudgetMax is the max number of hedged reads that can be earned
+// and not yet spent
+const fetchHedgeBudgetMax = 128
+
+// readReplicasRequired returns the number of replicas of an ID that must be
+// read from to meet a read consistency level
+func readReplicasRequired(level ReadConsistencyLevel, majority, replicas int) int {
+	var required int
+	switch level {
+	case ReadConsistencyLevelOne:
+		required = 1
+	case ReadConsistencyLevelMajority, ReadConsistencyLevelUnstrictMajority:
+		required = majority
+	default:
+		required = replicas
+	}
+	if required > replicas {
+		required = replicas
+	}
+	return required
+}
+
+// fetchHostLoads ranks the hosts of the host queues to read from, healthy
+// hosts with connections and a circuit breaker that is not open are preferred
+// over unhealthy hosts, then hosts with the least ops queued including those
+// assigned by the fetch so far.
+type fetchHostLoads struct {
+	healthy []bool
+	loads   []int
+}
+
+func newFetchHostLoads(queues []hostQueue) fetchHostLoads {
+	l := fetchHostLoads{
+		healthy: make([]bool, len(queues)),
+		loads:   make([]int, len(queues)),
+	}
+	for i, q := range queues {
+		l.healthy[i] = q.ConnectionCount() > 0 &&
+			q.CircuitBreakerState() != circuitBreakerOpen
+		l.loads[i] = q.Len()
+	}
+	return l
+}
+
+func (l fetchHostLoads) preferred(a, b int) bool {
+	if l.healthy[a] != l.healthy[b] {
+		return l.healthy[a]
+	}
+	if l.loads[a] != l.loads[b] {
+		return l.loads[a] < l.loads[b]
+	}
+	return a < b
+}
+
+// choose orders the host queue indexes of the replicas of an ID by preference
+// in place and assigns a read to each of the first required of them.
+func (l fetchHostLoads) choose(hostIdxs []int, required int) {
+	// Insertion sort as there are only as many host indexes as replicas
+	for i := 1; i < len(hostIdxs); i++ {
+		for j := i; j > 0 && l.preferred(hostIdxs[j], hostIdxs[j-1]); j-- {
+			hostIdxs[j], hostIdxs[j-1] = hostIdxs[j-1], hostIdxs[j]
+		}
+	}
+	for i := 0; i < required && i < len(hostIdxs); i++ {
+		l.loads[hostIdxs[i]]++
+	}
+}
+
+// fetchEscalation is a read of an ID escalated to another of its replicas,
+// the host queue of the replica is captured when the fetch is issued.
+type fetchEscalation struct {
+	queue        hostQueue
+	id           []byte
+	completionFn completionFn
+}
+
+// enqueueFetchEscalations enqueues the escalated reads of IDs batched by the
+// host queue they are read from, any reads that cannot be enqueued are
+// completed with an error. This is called from host queue completions so it
+// must not take the session lock, a topology update holds the session lock
+// while closing host queues which waits for their completions to drain.
+func (s *session) enqueueFetchEscalations(
+	request *rpc.FetchBatchRawRequest,
+	escalations []fetchEscalation,
+) {
+	var (
+		opsByQueue = make(map[hostQueue][]*fetchBatchOp)
+		failed     []completionFn
+		failedErr  error
+	)
+
+	for _, e := range escalations {
+		ops := opsByQueue[e.queue]
+		var f *fetchBatchOp
+		if len(ops) > 0 {
+			f = ops[len(ops)-1]
+		}
+		if f == nil || f.Size() >= s.fetchBatchSize {
+			// The fetch batch op pool is not replaced by topology updates so
+			// it is safe to take from outside of the session lock
+			f = s.fetchBatchOpPool.Get()
+			f.IncRef()
+			opsByQueue[e.queue] = append(opsByQueue[e.queue], f)
+			f.request.RangeStart = request.RangeStart
+			f.request.RangeEnd = request.RangeEnd
+			f.request.RangeTimeType = request.RangeTimeType
+			f.request.Step = request.Step
+			f.request.Aggregation = request.Aggregation
+		}
+		f.append(request.NameSpace, e.id, e.completionFn)
+	}
+
+	for queue, ops := range opsByQueue {
+		for _, f := range ops {
+			// Passing ownership of the op itself to the host queue, a closed
+			// host queue returns an error rather than accepting the op
+			f.DecRef()
+			if err := queue.Enqueue(f); err != nil {
+				failed = append(failed, f.completionFns...)
+				failedErr = err
+			}
+		}
+	}
+
+	// Complete reads that failed to enqueue after enqueueing the rest as they
+	// may escalate again
+	for _, fn := range failed {
+		fn(nil, failedErr)
+	}
+}
+
+// fetchHedgeBudget caps hedged reads to a ratio of the reads, each read earns
+// a fraction of a hedged read up to a max so that a long period without any
+// hedged reads cannot be followed by a burst of them.
+type fetchHedgeBudget struct {
+	sync.Mutex
+
+	ratio  float64
+	tokens float64
+}
+
+func newFetchHedgeBudget(ratio float64) *fetchHedgeBudget {
+	return &fetchHedgeBudget{ratio: ratio}
+}
+
+// Earn earns the fraction of a hedged read for each of the reads.
+func (b *fetchHedgeBudget) Earn(reads int) {
+	b.Lock()
+	b.tokens += b.ratio * float64(reads)
+	if b.tokens > fetchHedgeBudgetMax {
+		b.tokens = fetchHedgeBudgetMax
+	}
+	b.Unlock()
+}
+
+// Spend returns whether a hedged read is within budget and spends it if so.
+func (b *fetchHedgeBudget) Spend() bool {
+	b.Lock()
+	defer b.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Refund refunds a hedged read that was spent but not issued.
+func (b *fetchHedgeBudget) Refund() {
+	b.Lock()
+	b.tokens++
+	b.Unlock()
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/topology"
+	"github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadReplicasRequired(t *testing.T) {
+	assert.Equal(t, 1, readReplicasRequired(ReadConsistencyLevelOne, 2, 3))
+	assert.Equal(t, 2, readReplicasRequired(ReadConsistencyLevelMajority, 2, 3))
+	assert.Equal(t, 2, readReplicasRequired(ReadConsistencyLevelUnstrictMajority, 2, 3))
+	assert.Equal(t, 3, readReplicasRequired(ReadConsistencyLevelAll, 2, 3))
+	assert.Equal(t, 1, readReplicasRequired(ReadConsistencyLevelMajority, 2, 1))
+}
+
+func TestFetchHostLoadsChoose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var queues []hostQueue
+	for _, q := range []struct {
+		connections int
+		breaker     circuitBreakerState
+		len         int
+	}{
+		{connections: 0, len: 0},
+		{connections: 1, len: 2},
+		{connections: 1, len: 1},
+		{connections: 1, breaker: circuitBreakerOpen, len: 0},
+	} {
+		queue := NewMockhostQueue(ctrl)
+		queue.EXPECT().ConnectionCount().Return(q.connections)
+		if q.connections > 0 {
+			queue.EXPECT().CircuitBreakerState().Return(q.breaker)
+		}
+		queue.EXPECT().Len().Return(q.len)
+		queues = append(queues, queue)
+	}
+
+	loads := newFetchHostLoads(queues)
+
+	// Healthy hosts with the least ops queued are preferred
+	hostIdxs := []int{0, 1, 2, 3}
+	loads.choose(hostIdxs, 1)
+	assert.Equal(t, []int{2, 1, 0, 3}, hostIdxs)
+
+	// Reads already assigned count towards the load of a host
+	hostIdxs = []int{0, 1, 2, 3}
+	loads.choose(hostIdxs, 2)
+	assert.Equal(t, []int{1, 2, 0, 3}, hostIdxs)
+	assert.Equal(t, []int{0, 3, 3, 0}, loads.loads)
+}
+
+func TestSessionFetchMinimumReplicasEscalatesOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions().
+		SetReadConsistencyLevel(ReadConsistencyLevelOne).
+		SetReadReplicaStrategy(ReadReplicaStrategyMinimum)
+	s, err := newSession(opts)
+	require.NoError(t, err)
+	session := s.(*session)
+
+	enqueued := make(chan *fetchBatchOp, sessionTestReplicas)
+	session.newHostQueueFn = func(
+		host topology.Host,
+		writeBatchRawRequestPool writeBatchRawRequestPool,
+		writeBatchRawRequestElementArrayPool writeBatchRawRequestElementArrayPool,
+		opts Options,
+	) hostQueue {
+		hostQueue := NewMockhostQueue(ctrl)
+		hostQueue.EXPECT().Open()
+		hostQueue.EXPECT().Host().Return(host).AnyTimes()
+		hostQueue.EXPECT().ConnectionCount().Return(opts.MinConnectionCount()).AnyTimes()
+		hostQueue.EXPECT().CircuitBreakerState().Return(circuitBreakerClosed).AnyTimes()
+		hostQueue.EXPECT().Len().Return(0).AnyTimes()
+		hostQueue.EXPECT().Enqueue(gomock.Any()).Do(func(op op) error {
+			enqueued <- op.(*fetchBatchOp)
+			return nil
+		}).Return(nil).AnyTimes()
+		hostQueue.EXPECT().Close()
+		return hostQueue
+	}
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	fetches := testFetches([]testFetch{
+		{"foo", []testValue{
+			{1.0, start.Add(1 * time.Second), xtime.Second, nil},
+			{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+		}},
+	})
+
+	go func() {
+		// Fail the read from the first replica then fulfill the escalated read
+		fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{<-enqueued}, 1)
+		fulfillTszFetchBatchOps(t, fetches, []*fetchBatchOp{<-enqueued}, 0)
+	}()
+
+	require.NoError(t, session.Open())
+
+	results, err := session.FetchAll(testNamespaceName, fetches.IDs(), start, end)
+	require.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	// Only the failed replica and the replica escalated to were read from
+	assert.Equal(t, 0, len(enqueued))
+
+	assert.NoError(t, session.Close())
+}
+
+func TestFetchHedgeBudget(t *testing.T) {
+	b := newFetchHedgeBudget(0.1)
+	assert.False(t, b.Spend())
+
+	// Each read earns a tenth of a hedged read
+	b.Earn(25)
+	assert.True(t, b.Spend())
+	assert.True(t, b.Spend())
+	assert.False(t, b.Spend())
+
+	b.Refund()
+	assert.True(t, b.Spend())
+
+	// Hedged reads earned are capped
+	b.Earn(100 * fetchHedgeBudgetMax)
+	for i := 0; i < fetchHedgeBudgetMax; i++ {
+		assert.True(t, b.Spend())
+	}
+	assert.False(t, b.Spend())
+}
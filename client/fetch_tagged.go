@@ -0,0 +1,48 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	"github.com/m3db/m3db/topology"
+)
+
+type fetchTaggedOp struct {
+	request      rpc.FetchTaggedRequest
+	completionFn completionFn
+}
+
+func (f *fetchTaggedOp) Size() int {
+	// Fetch tagged is always a single op
+	return 1
+}
+
+func (f *fetchTaggedOp) CompletionFn() completionFn {
+	return f.completionFn
+}
+
+// fetchTaggedHostResult is the result passed to a fetch tagged op
+// completion function, the host is always set so that failures can
+// be attributed to the shards the host owns.
+type fetchTaggedHostResult struct {
+	host   topology.Host
+	result *rpc.FetchTaggedResult_
+}
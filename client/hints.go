@@ -0,0 +1,568 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/uber-go/tally"
+	"github.com/uber/tchannel-go/thrift"
+)
+
+const (
+	hintsFilePrefix       = "hints-"
+	hintsFileSuffix       = ".log"
+	hintsReplayFileSuffix = ".replay"
+	hintsDirectoryMode    = 0755
+	hintsFileMode         = 0644
+
+	// hintsSyncInterval is the interval between syncs of the hints logs to
+	// disk, writes storing hints do not wait for the hints to be synced
+	hintsSyncInterval = time.Second
+
+	// hintHeaderLen is the length of the header of each hint, which is the
+	// length of the hint followed by its checksum
+	hintHeaderLen = 8
+)
+
+var (
+	// errHintCorrupt is returned for a hint that fails its checksum, the
+	// hints following it in the log can still be read
+	errHintCorrupt = errors.New("hint corrupt")
+
+	// errHintTruncated is returned for a hint that cannot be read in full,
+	// none of the hints following it in the log can be read
+	errHintTruncated = errors.New("hint truncated")
+)
+
+// hint is a write that failed to reach a replica to replay to it later.
+type hint struct {
+	createdAt time.Time
+	namespace []byte
+	id        []byte
+	tags      []*rpc.Tag
+	datapoint rpc.Datapoint
+}
+
+type hintsMetrics struct {
+	stored          tally.Counter
+	replayed        tally.Counter
+	droppedFull     tally.Counter
+	droppedExpired  tally.Counter
+	droppedRejected tally.Counter
+	droppedErrors   tally.Counter
+	droppedCorrupt  tally.Counter
+}
+
+func newHintsMetrics(scope tally.Scope) hintsMetrics {
+	dropped := func(reason string) tally.Counter {
+		return scope.Tagged(map[string]string{"reason": reason}).Counter("dropped")
+	}
+	return hintsMetrics{
+		stored:          scope.Counter("stored"),
+		replayed:        scope.Counter("replayed"),
+		droppedFull:     dropped("full"),
+		droppedExpired:  dropped("expired"),
+		droppedRejected: dropped("rejected"),
+		droppedErrors:   dropped("error"),
+		droppedCorrupt:  dropped("corrupt"),
+	}
+}
+
+// hints stores hints for writes that failed to reach a replica in a bounded
+// log per host on local disk and replays them to the host once it is healthy.
+type hints struct {
+	sync.Mutex
+
+	dir            string
+	ttl            time.Duration
+	maxBytes       int64
+	writeTimeout   time.Duration
+	writeBatchSize int
+	nowFn          clock.NowFn
+	log            xlog.Logger
+	metrics        hintsMetrics
+	logs           map[string]*hintLog
+	closed         bool
+}
+
+// hintLog is the log of hints for a single host, hints are appended to the
+// log until it is replayed at which point it is renamed to the replay log so
+// that hints stored while replaying are appended to a new log. The hints not
+// yet replayed from the replay log count towards the max bytes of the host.
+type hintLog struct {
+	sync.Mutex
+
+	path       string
+	replayPath string
+	fd         *os.File
+	size       int64
+	replaySize int64
+	dirty      bool
+	buf        []byte
+}
+
+func newHints(opts Options) (*hints, error) {
+	dir := opts.HintsFilePathPrefix()
+	if err := os.MkdirAll(dir, hintsDirectoryMode); err != nil {
+		return nil, err
+	}
+	scope := opts.InstrumentOptions().MetricsScope().SubScope("hints")
+	return &hints{
+		dir:            dir,
+		ttl:            opts.HintsTTL(),
+		maxBytes:       opts.HintsMaxBytesPerHost(),
+		writeTimeout:   opts.WriteRequestTimeout(),
+		writeBatchSize: opts.WriteBatchSize(),
+		nowFn:          opts.ClockOptions().NowFn(),
+		log:            opts.InstrumentOptions().Logger(),
+		metrics:        newHintsMetrics(scope),
+		logs:           make(map[string]*hintLog),
+	}, nil
+}
+
+func (h *hints) logFor(hostID string) *hintLog {
+	h.Lock()
+	defer h.Unlock()
+
+	l, ok := h.logs[hostID]
+	if !ok {
+		// Host IDs are used in file names so must not contain separators
+		name := hintsFilePrefix + strings.Replace(hostID, string(filepath.Separator), "_", -1)
+		path := filepath.Join(h.dir, name)
+		l = &hintLog{
+			path:       path + hintsFileSuffix,
+			replayPath: path + hintsReplayFileSuffix,
+		}
+		h.logs[hostID] = l
+	}
+	return l
+}
+
+// Store stores a hint for a write that failed to reach the host.
+func (h *hints) Store(hostID string, op *writeOp) {
+	h.Lock()
+	closed := h.closed
+	h.Unlock()
+	if closed {
+		return
+	}
+
+	h.store(h.logFor(hostID), hint{
+		createdAt: h.nowFn(),
+		namespace: op.namespace.Data().Get(),
+		id:        op.request.ID,
+		tags:      op.tags,
+		datapoint: *op.request.Datapoint,
+	})
+}
+
+func (h *hints) store(l *hintLog, hint hint) {
+	l.Lock()
+	defer l.Unlock()
+
+	// Check closed while holding the log lock so the log is not reopened
+	// after its file is closed
+	h.Lock()
+	closed := h.closed
+	h.Unlock()
+	if closed {
+		return
+	}
+
+	if l.fd == nil {
+		fd, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, hintsFileMode)
+		if err != nil {
+			h.log.Errorf("could not open hints log %s: %v", l.path, err)
+			h.metrics.droppedErrors.Inc(1)
+			return
+		}
+		info, err := fd.Stat()
+		if err != nil {
+			fd.Close()
+			h.log.Errorf("could not stat hints log %s: %v", l.path, err)
+			h.metrics.droppedErrors.Inc(1)
+			return
+		}
+		l.fd, l.size = fd, info.Size()
+	}
+
+	l.buf = encodeHint(l.buf, hint)
+	if l.size+l.replaySize+int64(len(l.buf)) > h.maxBytes {
+		h.metrics.droppedFull.Inc(1)
+		return
+	}
+
+	n, err := l.fd.Write(l.buf)
+	l.size += int64(n)
+	l.dirty = true
+	if err != nil {
+		h.log.Errorf("could not write to hints log %s: %v", l.path, err)
+		h.metrics.droppedErrors.Inc(1)
+		return
+	}
+	h.metrics.stored.Inc(1)
+}
+
+// Exists returns whether there are any hints stored for the host.
+func (h *hints) Exists(hostID string) bool {
+	l := h.logFor(hostID)
+	for _, path := range []string{l.replayPath, l.path} {
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay replays the hints stored for the host to it, dropping hints older
+// than the TTL and hints the host rejects. If the host cannot be reached the
+// hints not yet replayed are stored again to replay later.
+func (h *hints) Replay(hostID string, client rpc.TChanNode) error {
+	l := h.logFor(hostID)
+
+	// Replay the replay log left over by a previous replay that did not
+	// complete before rotating the log
+	l.Lock()
+	if _, err := os.Stat(l.replayPath); os.IsNotExist(err) {
+		if l.fd != nil {
+			h.sync(l)
+			l.fd.Close()
+			l.fd, l.size = nil, 0
+		}
+		if err := os.Rename(l.path, l.replayPath); err != nil {
+			l.Unlock()
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	fd, err := os.Open(l.replayPath)
+	if err != nil {
+		l.Unlock()
+		return err
+	}
+	info, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		l.Unlock()
+		return err
+	}
+	l.replaySize = info.Size()
+	l.Unlock()
+
+	var (
+		reader    = bufio.NewReader(fd)
+		now       = h.nowFn()
+		batch     []hint
+		replayErr error
+	)
+	replayBatch := func() {
+		if replayErr == nil && len(batch) > 0 {
+			replayErr = h.replayBatch(client, batch)
+		}
+		if replayErr != nil {
+			// Store the hints not replayed again to replay later
+			for _, hint := range batch {
+				h.store(l, hint)
+			}
+		}
+		batch = batch[:0]
+	}
+	for {
+		hint, n, err := readHint(reader, h.maxBytes)
+
+		// Hints read no longer count towards the max bytes as they are either
+		// replayed, dropped or stored again in the log
+		l.Lock()
+		l.replaySize -= int64(n)
+		l.Unlock()
+
+		if err == io.EOF {
+			break
+		}
+		if err == errHintCorrupt {
+			h.metrics.droppedCorrupt.Inc(1)
+			continue
+		}
+		if err != nil {
+			h.log.Errorf("could not read hints log %s: %v", l.replayPath, err)
+			h.metrics.droppedCorrupt.Inc(1)
+			break
+		}
+		if now.Sub(hint.createdAt) > h.ttl {
+			h.metrics.droppedExpired.Inc(1)
+			continue
+		}
+		if len(hint.tags) > 0 {
+			// Tagged writes are replayed individually as they are not batched
+			replayBatch()
+			batch = append(batch, hint)
+			replayBatch()
+			continue
+		}
+		if n := len(batch); n > 0 && string(batch[n-1].namespace) != string(hint.namespace) {
+			replayBatch()
+		}
+		batch = append(batch, hint)
+		if len(batch) >= h.writeBatchSize {
+			replayBatch()
+		}
+	}
+	replayBatch()
+
+	fd.Close()
+	if err := os.Remove(l.replayPath); err != nil {
+		return err
+	}
+	l.Lock()
+	l.replaySize = 0
+	l.Unlock()
+	return replayErr
+}
+
+func (h *hints) replayBatch(client rpc.TChanNode, batch []hint) error {
+	ctx, _ := thrift.NewContext(h.writeTimeout)
+	if len(batch[0].tags) > 0 {
+		err := client.WriteTagged(ctx, &rpc.WriteTaggedRequest{
+			NameSpace: string(batch[0].namespace),
+			ID:        string(batch[0].id),
+			Tags:      batch[0].tags,
+			Datapoint: &batch[0].datapoint,
+		})
+		if _, ok := err.(*rpc.Error); ok {
+			// The host rejected the write rather than failed to be reached
+			h.metrics.droppedRejected.Inc(1)
+			return nil
+		}
+		if err == nil {
+			h.metrics.replayed.Inc(1)
+		}
+		return err
+	}
+
+	req := &rpc.WriteBatchRawRequest{
+		NameSpace: batch[0].namespace,
+		Elements:  make([]*rpc.WriteBatchRawRequestElement, 0, len(batch)),
+	}
+	for i := range batch {
+		req.Elements = append(req.Elements, &rpc.WriteBatchRawRequestElement{
+			ID:        batch[i].id,
+			Datapoint: &batch[i].datapoint,
+		})
+	}
+	err := client.WriteBatchRaw(ctx, req)
+	if batchErrs, ok := err.(*rpc.WriteBatchRawErrors); ok {
+		h.metrics.droppedRejected.Inc(int64(len(batchErrs.Errors)))
+		h.metrics.replayed.Inc(int64(len(batch) - len(batchErrs.Errors)))
+		return nil
+	}
+	if err == nil {
+		h.metrics.replayed.Inc(int64(len(batch)))
+	}
+	return err
+}
+
+// syncEvery syncs the hints logs written to since the last sync to disk
+// until the hints are closed.
+func (h *hints) syncEvery(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+
+		h.Lock()
+		if h.closed {
+			h.Unlock()
+			return
+		}
+		logs := make([]*hintLog, 0, len(h.logs))
+		for _, l := range h.logs {
+			logs = append(logs, l)
+		}
+		h.Unlock()
+
+		for _, l := range logs {
+			l.Lock()
+			h.sync(l)
+			l.Unlock()
+		}
+	}
+}
+
+// sync syncs the log to disk if written to since the last sync, the log
+// lock must be held.
+func (h *hints) sync(l *hintLog) {
+	if l.fd == nil || !l.dirty {
+		return
+	}
+	l.dirty = false
+	if err := l.fd.Sync(); err != nil {
+		h.log.Errorf("could not sync hints log %s: %v", l.path, err)
+	}
+}
+
+// Close closes the hints logs, hints stored after closing are ignored.
+func (h *hints) Close() {
+	h.Lock()
+	h.closed = true
+	logs := h.logs
+	h.Unlock()
+
+	for _, l := range logs {
+		l.Lock()
+		if l.fd != nil {
+			h.sync(l)
+			l.fd.Close()
+			l.fd = nil
+		}
+		l.Unlock()
+	}
+}
+
+func encodeHint(buf []byte, h hint) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	putVarint := func(v int64) {
+		n := binary.PutVarint(scratch[:], v)
+		buf = append(buf, scratch[:n]...)
+	}
+	putBytes := func(b []byte) {
+		putVarint(int64(len(b)))
+		buf = append(buf, b...)
+	}
+
+	buf = append(buf[:0], make([]byte, hintHeaderLen)...)
+	putVarint(h.createdAt.UnixNano())
+	putBytes(h.namespace)
+	putBytes(h.id)
+	putVarint(h.datapoint.Timestamp)
+	putVarint(int64(h.datapoint.TimestampTimeType))
+	putVarint(int64(math.Float64bits(h.datapoint.Value)))
+	putBytes(h.datapoint.Annotation)
+	putVarint(int64(len(h.tags)))
+	for _, tag := range h.tags {
+		putBytes([]byte(tag.Name))
+		putBytes([]byte(tag.Value))
+	}
+
+	payload := buf[hintHeaderLen:]
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	return buf
+}
+
+// readHint reads the next hint from the log returning the number of bytes
+// read, hints longer than maxBytes could never have been stored so are
+// treated as truncated rather than allocated.
+func readHint(r *bufio.Reader, maxBytes int64) (hint, int, error) {
+	var header [hintHeaderLen]byte
+	n, err := io.ReadFull(r, header[:])
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			// Partially written hint at the end of the log
+			return hint{}, n, errHintTruncated
+		}
+		return hint{}, n, err
+	}
+
+	payloadLen := int64(binary.BigEndian.Uint32(header[0:4]))
+	if payloadLen > maxBytes-hintHeaderLen {
+		return hint{}, n, errHintTruncated
+	}
+	payload := make([]byte, payloadLen)
+	m, err := io.ReadFull(r, payload)
+	n += m
+	if err != nil {
+		return hint{}, n, errHintTruncated
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return hint{}, n, errHintCorrupt
+	}
+
+	d := hintDecoder{buf: payload}
+	h := hint{
+		createdAt: time.Unix(0, d.varint()),
+		namespace: d.bytes(),
+		id:        d.bytes(),
+	}
+	h.datapoint.Timestamp = d.varint()
+	h.datapoint.TimestampTimeType = rpc.TimeType(d.varint())
+	h.datapoint.Value = math.Float64frombits(uint64(d.varint()))
+	h.datapoint.Annotation = d.bytes()
+	if numTags := d.varint(); numTags > 0 && d.err == nil {
+		h.tags = make([]*rpc.Tag, 0, numTags)
+		for i := int64(0); i < numTags && d.err == nil; i++ {
+			h.tags = append(h.tags, &rpc.Tag{Name: string(d.bytes()), Value: string(d.bytes())})
+		}
+	}
+	if d.err != nil {
+		return hint{}, n, d.err
+	}
+	return h, n, nil
+}
+
+type hintDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *hintDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = errHintCorrupt
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *hintDecoder) bytes() []byte {
+	l := d.varint()
+	if d.err != nil {
+		return nil
+	}
+	if l < 0 || l > int64(len(d.buf)) {
+		d.err = errHintCorrupt
+		return nil
+	}
+	if l == 0 {
+		return nil
+	}
+	b := d.buf[:l]
+	d.buf = d.buf[l:]
+	return b
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	"github.com/m3db/m3db/ts"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func newHintsTestOptions(t *testing.T, nowFn func() time.Time) (Options, func()) {
+	dir, err := ioutil.TempDir("", "hints")
+	require.NoError(t, err)
+	opts := NewOptions().
+		SetHintsFilePathPrefix(dir).
+		SetHintsTTL(time.Hour)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(nowFn))
+	return opts, func() { os.RemoveAll(dir) }
+}
+
+func newHintsTestWriteOp(namespace, id string, value float64, tags []*rpc.Tag) *writeOp {
+	op := &writeOp{}
+	op.reset()
+	op.namespace = ts.StringID(namespace)
+	op.request.ID = []byte(id)
+	op.request.Datapoint.Timestamp = 1
+	op.request.Datapoint.TimestampTimeType = rpc.TimeType_UNIX_SECONDS
+	op.request.Datapoint.Value = value
+	op.tags = tags
+	return op
+}
+
+func TestHintsReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	opts, cleanup := newHintsTestOptions(t, func() time.Time { return now })
+	defer cleanup()
+
+	h, err := newHints(opts)
+	require.NoError(t, err)
+	defer h.Close()
+
+	assert.False(t, h.Exists("host"))
+
+	// Hints stored over an hour before replaying are expired
+	h.Store("host", newHintsTestWriteOp("ns", "expired", 0, nil))
+	now = now.Add(time.Hour)
+
+	tags := []*rpc.Tag{{Name: "a", Value: "b"}}
+	h.Store("host", newHintsTestWriteOp("ns", "foo", 1, nil))
+	h.Store("host", newHintsTestWriteOp("ns", "bar", 2, nil))
+	h.Store("host", newHintsTestWriteOp("ns", "baz", 3, tags))
+	now = now.Add(time.Minute)
+	assert.True(t, h.Exists("host"))
+
+	client := rpc.NewMockTChanNode(ctrl)
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+			assert.Equal(t, "ns", string(req.NameSpace))
+			require.Equal(t, 2, len(req.Elements))
+			assert.Equal(t, "foo", string(req.Elements[0].ID))
+			assert.Equal(t, 1.0, req.Elements[0].Datapoint.Value)
+			assert.Equal(t, rpc.TimeType_UNIX_SECONDS, req.Elements[0].Datapoint.TimestampTimeType)
+			assert.Equal(t, "bar", string(req.Elements[1].ID))
+			assert.Equal(t, 2.0, req.Elements[1].Datapoint.Value)
+		}).Return(nil)
+	client.EXPECT().WriteTagged(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteTaggedRequest) {
+			assert.Equal(t, "ns", req.NameSpace)
+			assert.Equal(t, "baz", req.ID)
+			assert.Equal(t, tags, req.Tags)
+			assert.Equal(t, 3.0, req.Datapoint.Value)
+		}).Return(nil)
+
+	require.NoError(t, h.Replay("host", client))
+	assert.False(t, h.Exists("host"))
+}
+
+func TestHintsReplayStoresHintsAgainOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, cleanup := newHintsTestOptions(t, time.Now)
+	defer cleanup()
+
+	h, err := newHints(opts)
+	require.NoError(t, err)
+	defer h.Close()
+
+	h.Store("host", newHintsTestWriteOp("ns", "foo", 1, nil))
+
+	replayErr := errors.New("timed out")
+	client := rpc.NewMockTChanNode(ctrl)
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Return(replayErr)
+	assert.Equal(t, replayErr, h.Replay("host", client))
+	assert.True(t, h.Exists("host"))
+
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+			require.Equal(t, 1, len(req.Elements))
+			assert.Equal(t, "foo", string(req.Elements[0].ID))
+		}).Return(nil)
+	require.NoError(t, h.Replay("host", client))
+	assert.False(t, h.Exists("host"))
+}
+
+func TestHintsDroppedWhenFull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, cleanup := newHintsTestOptions(t, time.Now)
+	defer cleanup()
+
+	op := newHintsTestWriteOp("ns", "foo", 1, nil)
+	hintLen := len(encodeHint(nil, hint{
+		createdAt: time.Now(),
+		namespace: op.namespace.Data().Get(),
+		id:        op.request.ID,
+		datapoint: *op.request.Datapoint,
+	}))
+
+	h, err := newHints(opts.SetHintsMaxBytesPerHost(int64(2 * hintLen)))
+	require.NoError(t, err)
+	defer h.Close()
+
+	for i := 0; i < 3; i++ {
+		h.Store("host", op)
+	}
+
+	client := rpc.NewMockTChanNode(ctrl)
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+			assert.Equal(t, 2, len(req.Elements))
+		}).Return(nil)
+	require.NoError(t, h.Replay("host", client))
+}
+
+func TestHintsReplaySkipsCorruptHints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	opts, cleanup := newHintsTestOptions(t, func() time.Time { return now })
+	defer cleanup()
+
+	scope := tally.NewTestScope("", nil)
+	opts = opts.SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(scope))
+
+	h, err := newHints(opts)
+	require.NoError(t, err)
+	defer h.Close()
+
+	for _, id := range []string{"foo", "bar", "baz"} {
+		h.Store("host", newHintsTestWriteOp("ns", id, 1, nil))
+	}
+
+	// Corrupt the payload of the second hint
+	op := newHintsTestWriteOp("ns", "foo", 1, nil)
+	hintLen := len(encodeHint(nil, hint{
+		createdAt: now,
+		namespace: op.namespace.Data().Get(),
+		id:        op.request.ID,
+		datapoint: *op.request.Datapoint,
+	}))
+	path := h.logFor("host").path
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 3*hintLen, len(data))
+	data[hintLen+hintHeaderLen] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(path, data, hintsFileMode))
+
+	client := rpc.NewMockTChanNode(ctrl)
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+			require.Equal(t, 2, len(req.Elements))
+			assert.Equal(t, "foo", string(req.Elements[0].ID))
+			assert.Equal(t, "baz", string(req.Elements[1].ID))
+		}).Return(nil)
+	require.NoError(t, h.Replay("host", client))
+
+	key := tally.KeyForPrefixedStringMap("hints.dropped", map[string]string{"reason": "corrupt"})
+	dropped, ok := scope.Snapshot().Counters()[key]
+	require.True(t, ok)
+	assert.Equal(t, int64(1), dropped.Value())
+}
+
+func TestHintsReplayLogCountsTowardsMaxBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, cleanup := newHintsTestOptions(t, time.Now)
+	defer cleanup()
+
+	op := newHintsTestWriteOp("ns", "foo", 1, nil)
+	hintLen := len(encodeHint(nil, hint{
+		createdAt: time.Now(),
+		namespace: op.namespace.Data().Get(),
+		id:        op.request.ID,
+		datapoint: *op.request.Datapoint,
+	}))
+
+	h, err := newHints(opts.
+		SetHintsMaxBytesPerHost(int64(2 * hintLen)).
+		SetWriteBatchSize(1))
+	require.NoError(t, err)
+	defer h.Close()
+
+	h.Store("host", op)
+	h.Store("host", op)
+
+	// While replaying the first hint the second hint not yet replayed still
+	// counts towards the max bytes so only one more hint can be stored
+	client := rpc.NewMockTChanNode(ctrl)
+	gomock.InOrder(
+		client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+			func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+				h.Store("host", op)
+				h.Store("host", op)
+			}).Return(nil),
+		client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Return(nil),
+	)
+	require.NoError(t, h.Replay("host", client))
+
+	client.EXPECT().WriteBatchRaw(gomock.Any(), gomock.Any()).Do(
+		func(ctx interface{}, req *rpc.WriteBatchRawRequest) {
+			assert.Equal(t, 1, len(req.Elements))
+		}).Return(nil)
+	require.NoError(t, h.Replay("host", client))
+}
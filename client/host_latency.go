@@ -0,0 +1,100 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// hostLatencySamples is the number of the most recent latencies that
+	// percentiles are calculated over
+	hostLatencySamples = 512
+
+	// hostLatencyRecalculateEvery is the number of latencies recorded before
+	// percentiles are calculated again rather than returned from cache
+	hostLatencyRecalculateEvery = hostLatencySamples / 16
+)
+
+type durationsAsc []time.Duration
+
+func (d durationsAsc) Len() int           { return len(d) }
+func (d durationsAsc) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d durationsAsc) Less(i, j int) bool { return d[i] < d[j] }
+
+// hostLatencies tracks the latencies of the most recent requests to a host.
+type hostLatencies struct {
+	sync.Mutex
+
+	samples     []time.Duration
+	next        int
+	sorted      durationsAsc
+	sortedStale int
+}
+
+func newHostLatencies() *hostLatencies {
+	return &hostLatencies{
+		samples: make([]time.Duration, 0, hostLatencySamples),
+		sorted:  make(durationsAsc, 0, hostLatencySamples),
+	}
+}
+
+// Record records the latency of a request.
+func (l *hostLatencies) Record(latency time.Duration) {
+	l.Lock()
+	if len(l.samples) < hostLatencySamples {
+		l.samples = append(l.samples, latency)
+	} else {
+		l.samples[l.next] = latency
+		l.next = (l.next + 1) % hostLatencySamples
+	}
+	l.sortedStale++
+	l.Unlock()
+}
+
+// Percentile returns the given percentile, between zero and one hundred, of
+// the latencies recorded, zero if no latencies have been recorded.
+func (l *hostLatencies) Percentile(p float64) time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	if len(l.samples) == 0 {
+		return 0
+	}
+	// Recalculate on every new latency until the samples are full
+	warming := len(l.samples) < hostLatencySamples && l.sortedStale > 0
+	if warming || l.sortedStale >= hostLatencyRecalculateEvery {
+		l.sorted = append(l.sorted[:0], l.samples...)
+		sort.Sort(l.sorted)
+		l.sortedStale = 0
+	}
+
+	idx := int(p / 100 * float64(len(l.sorted)))
+	if idx >= len(l.sorted) {
+		idx = len(l.sorted) - 1
+	}
+	if idx < 0 {
+		idx = 0
+	}
+	return l.sorted[idx]
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostLatenciesPercentile(t *testing.T) {
+	l := newHostLatencies()
+	assert.Equal(t, time.Duration(0), l.Percentile(99))
+
+	for i := 1; i <= 100; i++ {
+		l.Record(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 51*time.Millisecond, l.Percentile(50))
+	assert.Equal(t, 100*time.Millisecond, l.Percentile(99))
+	assert.Equal(t, 100*time.Millisecond, l.Percentile(100))
+	assert.Equal(t, time.Millisecond, l.Percentile(0))
+}
+
+func TestHostLatenciesPercentileOfMostRecent(t *testing.T) {
+	l := newHostLatencies()
+	for i := 0; i < hostLatencySamples; i++ {
+		l.Record(time.Second)
+	}
+	assert.Equal(t, time.Second, l.Percentile(50))
+
+	// Older latencies are replaced by the most recent latencies
+	for i := 0; i < hostLatencySamples; i++ {
+		l.Record(time.Millisecond)
+	}
+	assert.Equal(t, time.Millisecond, l.Percentile(99))
+}
@@ -51,6 +51,7 @@ type queue struct {
 	opsLastRotatedAt                     time.Time
 	opsArrayPool                         *opArrayPool
 	drainIn                              chan []op
+	fetchLatencies                       *hostLatencies
 	state                                state
 }
 
@@ -87,10 +88,11 @@ func newHostQueue(
 		connPool:                             newConnectionPool(host, opts),
 		writeBatchRawRequestPool:             writeBatchRawRequestPool,
 		writeBatchRawRequestElementArrayPool: writeBatchRawRequestElementArrayPool,
-		size:         size,
-		ops:          opArrayPool.Get(),
-		opsArrayPool: opArrayPool,
-		drainIn:      make(chan []op, opsArraysLen),
+		size:           size,
+		ops:            opArrayPool.Get(),
+		opsArrayPool:   opArrayPool,
+		drainIn:        make(chan []op, opsArraysLen),
+		fetchLatencies: newHostLatencies(),
 	}
 }
 
@@ -191,6 +193,11 @@ func (q *queue) drain() {
 		for i := 0; i < opsLen; i++ {
 			switch v := ops[i].(type) {
 			case *writeOp:
+				if v.tags != nil {
+					// Tagged writes are sent individually as they are not batched
+					q.asyncWriteTagged(v)
+					continue
+				}
 				namespace := v.namespace
 				namespaceKey := namespace.Hash()
 				currWriteOps = currWriteOpsByNamespace[namespaceKey]
@@ -215,6 +222,10 @@ func (q *queue) drain() {
 				q.asyncFetch(v)
 			case *truncateOp:
 				q.asyncTruncate(v)
+			case *deleteOp:
+				q.asyncDelete(v)
+			case *fetchTaggedOp:
+				q.asyncFetchTagged(v)
 			default:
 				completionFn := ops[i].CompletionFn()
 				completionFn(nil, errQueueUnknownOperation(q.host.ID()))
@@ -275,6 +286,7 @@ func (q *queue) asyncWrite(
 
 		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
 		err = client.WriteBatchRaw(ctx, req)
+		q.connPool.ReportResult(err)
 		if err == nil {
 			// All succeeded
 			callAllCompletionFns(ops, q.host, nil)
@@ -327,7 +339,10 @@ func (q *queue) asyncFetch(op *fetchBatchOp) {
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
+		start := q.nowFn()
 		result, err := client.FetchBatchRaw(ctx, &op.request)
+		q.fetchLatencies.Record(q.nowFn().Sub(start))
+		q.connPool.ReportResult(err)
 		if err != nil {
 			op.completeAll(nil, err)
 			cleanup()
@@ -367,7 +382,9 @@ func (q *queue) asyncTruncate(op *truncateOp) {
 		}
 
 		ctx, _ := thrift.NewContext(q.opts.TruncateRequestTimeout())
-		if res, err := client.Truncate(ctx, &op.request); err != nil {
+		res, err := client.Truncate(ctx, &op.request)
+		q.connPool.ReportResult(err)
+		if err != nil {
 			op.completionFn(nil, err)
 		} else {
 			op.completionFn(res, nil)
@@ -377,6 +394,82 @@ func (q *queue) asyncTruncate(op *truncateOp) {
 	}()
 }
 
+func (q *queue) asyncDelete(op *deleteOp) {
+	q.Add(1)
+
+	go func() {
+		cleanup := q.Done
+
+		client, err := q.connPool.NextClient()
+		if err != nil {
+			// No client available
+			op.completionFn(nil, err)
+			cleanup()
+			return
+		}
+
+		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
+		err = client.Delete(ctx, &op.request)
+		q.connPool.ReportResult(err)
+		op.completionFn(nil, err)
+
+		cleanup()
+	}()
+}
+
+func (q *queue) asyncWriteTagged(op *writeOp) {
+	q.Add(1)
+
+	go func() {
+		cleanup := q.Done
+
+		client, err := q.connPool.NextClient()
+		if err != nil {
+			// No client available
+			op.completionFn(q.host, err)
+			cleanup()
+			return
+		}
+
+		req := &rpc.WriteTaggedRequest{
+			NameSpace: op.namespace.String(),
+			ID:        string(op.request.ID),
+			Tags:      op.tags,
+			Datapoint: op.request.Datapoint,
+		}
+
+		ctx, _ := thrift.NewContext(q.opts.WriteRequestTimeout())
+		err = client.WriteTagged(ctx, req)
+		q.connPool.ReportResult(err)
+		op.completionFn(q.host, err)
+
+		cleanup()
+	}()
+}
+
+func (q *queue) asyncFetchTagged(op *fetchTaggedOp) {
+	q.Add(1)
+
+	go func() {
+		cleanup := q.Done
+
+		client, err := q.connPool.NextClient()
+		if err != nil {
+			// No client available
+			op.completionFn(fetchTaggedHostResult{host: q.host}, err)
+			cleanup()
+			return
+		}
+
+		ctx, _ := thrift.NewContext(q.opts.FetchRequestTimeout())
+		res, err := client.FetchTagged(ctx, &op.request)
+		q.connPool.ReportResult(err)
+		op.completionFn(fetchTaggedHostResult{host: q.host, result: res}, err)
+
+		cleanup()
+	}()
+}
+
 func (q *queue) Len() int {
 	q.RLock()
 	v := q.opsSumSize
@@ -384,6 +477,10 @@ func (q *queue) Len() int {
 	return v
 }
 
+func (q *queue) FetchLatency(percentile float64) time.Duration {
+	return q.fetchLatencies.Percentile(percentile)
+}
+
 func (q *queue) Enqueue(o op) error {
 	if fetchOp, ok := o.(*fetchBatchOp); ok {
 		// Need to take ownership if its a fetch batch op
@@ -419,6 +516,10 @@ func (q *queue) ConnectionCount() int {
 	return q.connPool.ConnectionCount()
 }
 
+func (q *queue) CircuitBreakerState() circuitBreakerState {
+	return q.connPool.CircuitBreakerState()
+}
+
 func (q *queue) ConnectionPool() connectionPool {
 	return q.connPool
 }
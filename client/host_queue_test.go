@@ -82,6 +82,7 @@ func TestHostQueueWriteBatches(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	queue := newHostQueue(h, testWriteBatchRawPool, testWriteArrayPool, opts).(*queue)
@@ -159,6 +160,7 @@ func TestHostQueueWriteBatchesDifferentNamespaces(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	queue := newHostQueue(h, testWriteBatchRawPool, testWriteArrayPool, opts).(*queue)
@@ -239,6 +241,7 @@ func TestHostQueueWriteBatchesNoClientAvailable(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	opts = opts.SetHostQueueOpsFlushInterval(time.Millisecond)
@@ -282,6 +285,7 @@ func TestHostQueueWriteBatchesPartialBatchErrs(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	opts = opts.SetHostQueueOpsFlushSize(2)
@@ -352,6 +356,7 @@ func TestHostQueueWriteBatchesEntireBatchErr(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	opts = opts.SetHostQueueOpsFlushSize(2)
@@ -501,6 +506,7 @@ func TestHostQueueDrainOnClose(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	queue := newHostQueue(h, testWriteBatchRawPool, testWriteArrayPool, opts).(*queue)
@@ -593,6 +599,7 @@ func testHostQueueFetchBatches(
 	defer ctrl.Finish()
 
 	mockConnPool := NewMockconnectionPool(ctrl)
+	mockConnPool.EXPECT().ReportResult(gomock.Any()).AnyTimes()
 
 	opts := newHostQueueTestOptions()
 	queue := newHostQueue(h, testWriteBatchRawPool, testWriteArrayPool, opts).(*queue)
@@ -47,6 +47,12 @@ const (
 	// defaultReadConsistencyLevel is the default read consistency level
 	defaultReadConsistencyLevel = ReadConsistencyLevelMajority
 
+	// defaultReadReplicaStrategy is the default read replica strategy
+	defaultReadReplicaStrategy = ReadReplicaStrategyAll
+
+	// defaultReadHedgeMaxRatio is the default max ratio of hedged reads to reads
+	defaultReadHedgeMaxRatio = 0.05
+
 	// defaultMaxConnectionCount is the default max connection count
 	defaultMaxConnectionCount = 32
 
@@ -77,6 +83,20 @@ const (
 	// defaultWriteOpPoolSize is the default write op pool size
 	defaultWriteOpPoolSize = 262144
 
+	// defaultWriteAsyncMaxPending is the default max pending asynchronous writes
+	defaultWriteAsyncMaxPending = 65536
+
+	// defaultHintsTTL is the default duration hints are replayed for
+	defaultHintsTTL = 3 * time.Hour
+
+	// defaultHintsMaxBytesPerHost is the default max bytes of hints stored
+	// for a host
+	defaultHintsMaxBytesPerHost = 256 * 1024 * 1024
+
+	// defaultHintsReplayInterval is the default interval between replays
+	// of the hints stored for healthy hosts
+	defaultHintsReplayInterval = 10 * time.Second
+
 	// defaultFetchBatchOpPoolSize is the default fetch op pool size
 	defaultFetchBatchOpPoolSize = 8192
 
@@ -117,6 +137,15 @@ const (
 	// timeout to produce a throttle sleep value.
 	defaultBackgroundHealthCheckFailThrottleFactor = 0.5
 
+	// defaultHostCircuitBreakerFailLimit is the default number of consecutive
+	// failed requests to a host before its circuit breaker opens, zero leaves
+	// the circuit breaker disabled unless configured
+	defaultHostCircuitBreakerFailLimit = 0
+
+	// defaultHostCircuitBreakerOpenDuration is the default duration a host
+	// circuit breaker stays open before allowing a probe request
+	defaultHostCircuitBreakerOpenDuration = 5 * time.Second
+
 	// defaultSeriesIteratorPoolSize is the default size of the series iterator pools
 	defaultSeriesIteratorPoolSize = 100000
 
@@ -154,6 +183,12 @@ var (
 
 	errNoTopologyInitializerSet    = errors.New("no topology initializer set")
 	errNoReaderIteratorAllocateSet = errors.New("no reader iterator allocator set, encoding not set")
+	errWriteAsyncMaxPendingInvalid = errors.New("write async max pending must be positive")
+	errReadHedgePercentileInvalid  = errors.New("read hedge percentile must be between 0 and 100")
+	errReadHedgeMaxRatioInvalid    = errors.New("read hedge max ratio must not be negative")
+	errHintsTTLInvalid             = errors.New("hints ttl must be positive")
+	errHintsMaxBytesInvalid        = errors.New("hints max bytes per host must be positive")
+	errHintsReplayIntervalInvalid  = errors.New("hints replay interval must be positive")
 )
 
 type options struct {
@@ -162,6 +197,10 @@ type options struct {
 	topologyInitializer                     topology.Initializer
 	writeConsistencyLevel                   topology.ConsistencyLevel
 	readConsistencyLevel                    ReadConsistencyLevel
+	readReplicaStrategy                     ReadReplicaStrategy
+	readHedgeDelay                          time.Duration
+	readHedgePercentile                     float64
+	readHedgeMaxRatio                       float64
 	channelOptions                          *tchannel.ChannelOptions
 	maxConnectionCount                      int
 	minConnectionCount                      int
@@ -177,10 +216,17 @@ type options struct {
 	backgroundHealthCheckStutter            time.Duration
 	backgroundHealthCheckFailLimit          int
 	backgroundHealthCheckFailThrottleFactor float64
+	hostCircuitBreakerFailLimit             int
+	hostCircuitBreakerOpenDuration          time.Duration
 	writeRetrier                            xretry.Retrier
 	fetchRetrier                            xretry.Retrier
 	readerIteratorAllocate                  encoding.ReaderIteratorAllocate
 	writeOpPoolSize                         int
+	writeAsyncMaxPending                    int
+	hintsFilePathPrefix                     string
+	hintsTTL                                time.Duration
+	hintsMaxBytesPerHost                    int64
+	hintsReplayInterval                     time.Duration
 	fetchBatchOpPoolSize                    int
 	writeBatchSize                          int
 	fetchBatchSize                          int
@@ -229,6 +275,8 @@ func newOptions() *options {
 		instrumentOpts:                          instrument.NewOptions(),
 		writeConsistencyLevel:                   defaultWriteConsistencyLevel,
 		readConsistencyLevel:                    defaultReadConsistencyLevel,
+		readReplicaStrategy:                     defaultReadReplicaStrategy,
+		readHedgeMaxRatio:                       defaultReadHedgeMaxRatio,
 		maxConnectionCount:                      defaultMaxConnectionCount,
 		minConnectionCount:                      defaultMinConnectionCount,
 		hostConnectTimeout:                      defaultHostConnectTimeout,
@@ -243,9 +291,15 @@ func newOptions() *options {
 		backgroundHealthCheckStutter:            defaultBackgroundHealthCheckStutter,
 		backgroundHealthCheckFailLimit:          defaultBackgroundHealthCheckFailLimit,
 		backgroundHealthCheckFailThrottleFactor: defaultBackgroundHealthCheckFailThrottleFactor,
+		hostCircuitBreakerFailLimit:             defaultHostCircuitBreakerFailLimit,
+		hostCircuitBreakerOpenDuration:          defaultHostCircuitBreakerOpenDuration,
 		writeRetrier:                            defaultWriteRetrier,
 		fetchRetrier:                            defaultFetchRetrier,
 		writeOpPoolSize:                         defaultWriteOpPoolSize,
+		writeAsyncMaxPending:                    defaultWriteAsyncMaxPending,
+		hintsTTL:                                defaultHintsTTL,
+		hintsMaxBytesPerHost:                    defaultHintsMaxBytesPerHost,
+		hintsReplayInterval:                     defaultHintsReplayInterval,
 		fetchBatchOpPoolSize:                    defaultFetchBatchOpPoolSize,
 		writeBatchSize:                          defaultWriteBatchSize,
 		fetchBatchSize:                          defaultFetchBatchSize,
@@ -272,6 +326,26 @@ func (o *options) Validate() error {
 	if o.readerIteratorAllocate == nil {
 		return errNoReaderIteratorAllocateSet
 	}
+	if o.writeAsyncMaxPending <= 0 {
+		return errWriteAsyncMaxPendingInvalid
+	}
+	if o.readHedgePercentile < 0 || o.readHedgePercentile > 100 {
+		return errReadHedgePercentileInvalid
+	}
+	if o.readHedgeMaxRatio < 0 {
+		return errReadHedgeMaxRatioInvalid
+	}
+	if o.hintsFilePathPrefix != "" {
+		if o.hintsTTL <= 0 {
+			return errHintsTTLInvalid
+		}
+		if o.hintsMaxBytesPerHost <= 0 {
+			return errHintsMaxBytesInvalid
+		}
+		if o.hintsReplayInterval <= 0 {
+			return errHintsReplayIntervalInvalid
+		}
+	}
 	return nil
 }
 
@@ -333,6 +407,46 @@ func (o *options) ReadConsistencyLevel() ReadConsistencyLevel {
 	return o.readConsistencyLevel
 }
 
+func (o *options) SetReadReplicaStrategy(value ReadReplicaStrategy) Options {
+	opts := *o
+	opts.readReplicaStrategy = value
+	return &opts
+}
+
+func (o *options) ReadReplicaStrategy() ReadReplicaStrategy {
+	return o.readReplicaStrategy
+}
+
+func (o *options) SetReadHedgeDelay(value time.Duration) Options {
+	opts := *o
+	opts.readHedgeDelay = value
+	return &opts
+}
+
+func (o *options) ReadHedgeDelay() time.Duration {
+	return o.readHedgeDelay
+}
+
+func (o *options) SetReadHedgePercentile(value float64) Options {
+	opts := *o
+	opts.readHedgePercentile = value
+	return &opts
+}
+
+func (o *options) ReadHedgePercentile() float64 {
+	return o.readHedgePercentile
+}
+
+func (o *options) SetReadHedgeMaxRatio(value float64) Options {
+	opts := *o
+	opts.readHedgeMaxRatio = value
+	return &opts
+}
+
+func (o *options) ReadHedgeMaxRatio() float64 {
+	return o.readHedgeMaxRatio
+}
+
 func (o *options) SetChannelOptions(value *tchannel.ChannelOptions) Options {
 	opts := *o
 	opts.channelOptions = value
@@ -483,6 +597,26 @@ func (o *options) BackgroundHealthCheckFailThrottleFactor() float64 {
 	return o.backgroundHealthCheckFailThrottleFactor
 }
 
+func (o *options) SetHostCircuitBreakerFailLimit(value int) Options {
+	opts := *o
+	opts.hostCircuitBreakerFailLimit = value
+	return &opts
+}
+
+func (o *options) HostCircuitBreakerFailLimit() int {
+	return o.hostCircuitBreakerFailLimit
+}
+
+func (o *options) SetHostCircuitBreakerOpenDuration(value time.Duration) Options {
+	opts := *o
+	opts.hostCircuitBreakerOpenDuration = value
+	return &opts
+}
+
+func (o *options) HostCircuitBreakerOpenDuration() time.Duration {
+	return o.hostCircuitBreakerOpenDuration
+}
+
 func (o *options) SetWriteRetrier(value xretry.Retrier) Options {
 	opts := *o
 	opts.writeRetrier = value
@@ -513,6 +647,56 @@ func (o *options) WriteOpPoolSize() int {
 	return o.writeOpPoolSize
 }
 
+func (o *options) SetWriteAsyncMaxPending(value int) Options {
+	opts := *o
+	opts.writeAsyncMaxPending = value
+	return &opts
+}
+
+func (o *options) WriteAsyncMaxPending() int {
+	return o.writeAsyncMaxPending
+}
+
+func (o *options) SetHintsFilePathPrefix(value string) Options {
+	opts := *o
+	opts.hintsFilePathPrefix = value
+	return &opts
+}
+
+func (o *options) HintsFilePathPrefix() string {
+	return o.hintsFilePathPrefix
+}
+
+func (o *options) SetHintsTTL(value time.Duration) Options {
+	opts := *o
+	opts.hintsTTL = value
+	return &opts
+}
+
+func (o *options) HintsTTL() time.Duration {
+	return o.hintsTTL
+}
+
+func (o *options) SetHintsMaxBytesPerHost(value int64) Options {
+	opts := *o
+	opts.hintsMaxBytesPerHost = value
+	return &opts
+}
+
+func (o *options) HintsMaxBytesPerHost() int64 {
+	return o.hintsMaxBytesPerHost
+}
+
+func (o *options) SetHintsReplayInterval(value time.Duration) Options {
+	opts := *o
+	opts.hintsReplayInterval = value
+	return &opts
+}
+
+func (o *options) HintsReplayInterval() time.Duration {
+	return o.hintsReplayInterval
+}
+
 func (o *options) SetFetchBatchOpPoolSize(value int) Options {
 	opts := *o
 	opts.fetchBatchOpPoolSize = value
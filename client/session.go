@@ -39,8 +39,10 @@ import (
 	"github.com/m3db/m3db/encoding"
 	"github.com/m3db/m3db/generated/thrift/rpc"
 	"github.com/m3db/m3db/network/server/tchannelthrift/convert"
+	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/index"
 	"github.com/m3db/m3db/topology"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
@@ -124,6 +126,9 @@ type session struct {
 	seriesIteratorsPool              encoding.MutableSeriesIteratorsPool
 	writeAttemptPool                 *writeAttemptPool
 	writeStatePool                   *writeStatePool
+	writeAsyncPending                chan struct{}
+	fetchHedgeBudget                 *fetchHedgeBudget
+	hints                            *hints
 	digestPool                       sync.Pool
 	fetchAttemptPool                 *fetchAttemptPool
 	fetchBatchSize                   int
@@ -151,6 +156,10 @@ type sessionMetrics struct {
 	fetchSuccess               tally.Counter
 	fetchErrors                tally.Counter
 	fetchNodesRespondingErrors []tally.Counter
+	fetchEscalatedOnError      tally.Counter
+	fetchHedgesIssued          tally.Counter
+	fetchHedgesWon             tally.Counter
+	fetchHedgesThrottled       tally.Counter
 	topologyUpdatedSuccess     tally.Counter
 	topologyUpdatedError       tally.Counter
 	streamFromPeersMetrics     map[shardMetricsKey]streamFromPeersMetrics
@@ -162,6 +171,10 @@ func newSessionMetrics(scope tally.Scope) sessionMetrics {
 		writeErrors:            scope.Counter("write.errors"),
 		fetchSuccess:           scope.Counter("fetch.success"),
 		fetchErrors:            scope.Counter("fetch.errors"),
+		fetchEscalatedOnError:  scope.Tagged(map[string]string{"reason": "error"}).Counter("fetch.escalated"),
+		fetchHedgesIssued:      scope.Counter("fetch.hedges-issued"),
+		fetchHedgesWon:         scope.Counter("fetch.hedges-won"),
+		fetchHedgesThrottled:   scope.Counter("fetch.hedges-throttled"),
 		topologyUpdatedSuccess: scope.Counter("topology.updated-success"),
 		topologyUpdatedError:   scope.Counter("topology.updated-error"),
 		streamFromPeersMetrics: make(map[shardMetricsKey]streamFromPeersMetrics),
@@ -214,9 +227,16 @@ func newSession(opts Options) (clientSession, error) {
 		fetchRetrier:         opts.FetchRetrier(),
 		contextPool:          opts.ContextPool(),
 		idPool:               opts.IdentifierPool(),
+		writeAsyncPending:    make(chan struct{}, opts.WriteAsyncMaxPending()),
+		fetchHedgeBudget:     newFetchHedgeBudget(opts.ReadHedgeMaxRatio()),
 		metrics:              newSessionMetrics(scope),
 	}
 	s.reattemptStreamBlocksFromPeersFn = s.streamBlocksReattemptFromPeers
+	if opts.HintsFilePathPrefix() != "" {
+		if s.hints, err = newHints(opts); err != nil {
+			return nil, err
+		}
+	}
 	writeAttemptPoolOpts := pool.NewObjectPoolOptions().
 		SetSize(opts.WriteOpPoolSize()).
 		SetInstrumentOptions(opts.InstrumentOptions().SetMetricsScope(
@@ -403,6 +423,11 @@ func (s *session) Open() error {
 	s.state = stateOpen
 	s.Unlock()
 
+	if s.hints != nil {
+		go s.hints.syncEvery(hintsSyncInterval)
+		go s.replayHintsEvery(s.opts.HintsReplayInterval())
+	}
+
 	go func() {
 		for range watch.C() {
 			s.log.Info("received update for topology")
@@ -706,15 +731,239 @@ func (s *session) Write(
 	return err
 }
 
+func (s *session) WriteTagged(
+	namespace, id string,
+	tags ts.Tags,
+	t time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	w := s.writeAttemptPool.Get()
+	w.args.namespace, w.args.id, w.args.tags =
+		namespace, id, convert.ToRPCTags(tags)
+	w.args.t, w.args.value, w.args.unit, w.args.annotation =
+		t, value, unit, annotation
+	err := s.writeRetrier.Attempt(w.attemptFn)
+	s.writeAttemptPool.Put(w)
+	return err
+}
+
+func (s *session) WriteAsync(
+	namespace, id string,
+	t time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+	callback WriteCallback,
+) error {
+	if s.RLock(); s.state != stateOpen {
+		s.RUnlock()
+		return errSessionStateNotOpen
+	}
+	s.RUnlock()
+
+	// Block until the number of pending asynchronous writes is under the max
+	s.writeAsyncPending <- struct{}{}
+
+	err := s.writeAsyncAttempt(namespace, id, nil, t, value, unit, annotation,
+		func(err error) {
+			// Release the pending write before calling back so the callback can
+			// perform another asynchronous write without blocking on itself
+			s.releaseWriteAsync()
+			callback(err)
+		})
+	if err != nil {
+		s.releaseWriteAsync()
+		return err
+	}
+	return nil
+}
+
+func (s *session) WriteBatch(namespace string, writes []BatchWrite) error {
+	var (
+		wg      sync.WaitGroup
+		results = make([]error, len(writes))
+	)
+	for i := range writes {
+		idx := i
+		wg.Add(1)
+		if err := s.WriteAsync(namespace, writes[i].ID, writes[i].Timestamp,
+			writes[i].Value, writes[i].Unit, writes[i].Annotation, func(err error) {
+				results[idx] = err
+				wg.Done()
+			}); err != nil {
+			results[idx] = err
+			wg.Done()
+		}
+	}
+	wg.Wait()
+
+	var batchErrs []*rpc.WriteBatchRawError
+	for i, err := range results {
+		if err == nil {
+			continue
+		}
+		if IsBadRequestError(err) {
+			batchErrs = append(batchErrs, tterrors.NewBadRequestWriteBatchRawError(i, err))
+		} else {
+			batchErrs = append(batchErrs, tterrors.NewWriteBatchRawError(i, err))
+		}
+	}
+	if len(batchErrs) > 0 {
+		return &rpc.WriteBatchRawErrors{Errors: batchErrs}
+	}
+	return nil
+}
+
+// releaseWriteAsync releases a pending asynchronous write
+func (s *session) releaseWriteAsync() {
+	<-s.writeAsyncPending
+}
+
+func (s *session) Delete(
+	namespace, id string,
+	start, end time.Time,
+) error {
+	return s.writeRetrier.Attempt(func() error {
+		err := s.deleteAttempt(namespace, id, start, end)
+		if IsBadRequestError(err) {
+			// Do not retry bad request errors
+			err = xerrors.NewNonRetryableError(err)
+		}
+		return err
+	})
+}
+
+func (s *session) deleteAttempt(
+	namespace, id string,
+	start, end time.Time,
+) error {
+	rangeStart, tsErr := convert.ToValue(start, rpc.TimeType_UNIX_NANOSECONDS)
+	if tsErr != nil {
+		return tsErr
+	}
+
+	rangeEnd, tsErr := convert.ToValue(end, rpc.TimeType_UNIX_NANOSECONDS)
+	if tsErr != nil {
+		return tsErr
+	}
+
+	var (
+		wg         sync.WaitGroup
+		enqueued   int32
+		errsLock   sync.Mutex
+		errs       []error
+		enqueueErr xerrors.MultiError
+	)
+
+	d := &deleteOp{}
+	d.request.NameSpace = namespace
+	d.request.ID = id
+	d.request.RangeStart = rangeStart
+	d.request.RangeEnd = rangeEnd
+	d.request.RangeType = rpc.TimeType_UNIX_NANOSECONDS
+	d.completionFn = func(_ interface{}, err error) {
+		if err != nil {
+			errsLock.Lock()
+			errs = append(errs, err)
+			errsLock.Unlock()
+		}
+		wg.Done()
+	}
+
+	s.RLock()
+	if s.state != stateOpen {
+		s.RUnlock()
+		return errSessionStateNotOpen
+	}
+	majority := atomic.LoadInt32(&s.majority)
+	tsID := ts.StringID(id)
+	if err := s.topoMap.RouteForEach(tsID, func(idx int, host topology.Host) {
+		wg.Add(1)
+		if err := s.queues[idx].Enqueue(d); err != nil {
+			wg.Done()
+			enqueueErr = enqueueErr.Add(err)
+			return
+		}
+		enqueued++
+	}); err != nil {
+		s.RUnlock()
+		wg.Wait()
+		return err
+	}
+	s.RUnlock()
+
+	// Wait for the delete to complete on all replicas that were enqueued
+	wg.Wait()
+
+	if err := enqueueErr.FinalError(); err != nil {
+		s.log.Errorf("failed to enqueue delete: %v", err)
+		return err
+	}
+
+	return s.writeConsistencyResult(majority, enqueued, enqueued,
+		int32(len(errs)), errs)
+}
+
 func (s *session) writeAttempt(
 	namespace, id string,
+	tags []*rpc.Tag,
+	t time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	state, err := s.enqueueWrite(namespace, id, tags, t, value, unit, annotation, nil)
+	if err != nil {
+		return err
+	}
+
+	state.Wait()
+
+	err = s.writeConsistencyResult(state.majority, state.enqueued,
+		state.enqueued-state.pending, int32(len(state.errors)), state.errors)
+	s.incWriteMetrics(err, int32(len(state.errors)))
+
+	state.Unlock()
+	state.decRef()
+
+	return err
+}
+
+// writeAsyncAttempt enqueues a write to the host queues of its replicas and
+// returns without waiting, the callback is called by the completion of the
+// write on the host queue that meets the write consistency level.
+func (s *session) writeAsyncAttempt(
+	namespace, id string,
+	tags []*rpc.Tag,
 	t time.Time,
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
+	callback WriteCallback,
 ) error {
+	state, err := s.enqueueWrite(namespace, id, tags, t, value, unit, annotation, callback)
+	if err != nil {
+		return err
+	}
+	state.Unlock()
+	state.decRef()
+	return nil
+}
+
+// enqueueWrite enqueues a write to the host queues of its replicas and
+// returns the write state locked so completions wait until it is unlocked.
+func (s *session) enqueueWrite(
+	namespace, id string,
+	tags []*rpc.Tag,
+	t time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+	callback WriteCallback,
+) (*writeState, error) {
 	var (
-		enqueued int32
 		majority = atomic.LoadInt32(&s.majority)
 		ctx      = s.contextPool.Get()
 		nsID     = s.idPool.GetStringID(ctx, namespace)
@@ -723,17 +972,17 @@ func (s *session) writeAttempt(
 
 	timeType, timeTypeErr := convert.ToTimeType(unit)
 	if timeTypeErr != nil {
-		return timeTypeErr
+		return nil, timeTypeErr
 	}
 
 	timestamp, timestampErr := convert.ToValue(t, timeType)
 	if timestampErr != nil {
-		return timestampErr
+		return nil, timestampErr
 	}
 
 	if s.RLock(); s.state != stateOpen {
 		s.RUnlock()
-		return errSessionStateNotOpen
+		return nil, errSessionStateNotOpen
 	}
 
 	state := s.writeStatePool.Get()
@@ -752,7 +1001,9 @@ func (s *session) writeAttempt(
 	state.op.request.Datapoint.Timestamp = timestamp
 	state.op.request.Datapoint.TimestampTimeType = timeType
 	state.op.request.Datapoint.Annotation = annotation
+	state.op.tags = tags
 	state.op.completionFn = state.completionFn
+	state.callbackFn = callback
 
 	if err := s.topoMap.RouteForEach(tsID, func(idx int, host topology.Host) {
 		// Count pending write requests before we enqueue the completion fns,
@@ -762,7 +1013,7 @@ func (s *session) writeAttempt(
 	}); err != nil {
 		state.decRef()
 		s.RUnlock()
-		return err
+		return nil, err
 	}
 
 	state.Lock()
@@ -770,6 +1021,9 @@ func (s *session) writeAttempt(
 	for i := range state.queues {
 		state.incRef()
 		if err := state.queues[i].Enqueue(state.op); err != nil {
+			// Do not call back for the replicas already enqueued as
+			// the write is returned as not performed
+			state.callbackFn = nil
 			state.Unlock()
 			state.decRef()
 
@@ -777,22 +1031,14 @@ func (s *session) writeAttempt(
 			// lock the current queues should never be closed
 			s.RUnlock()
 			s.opts.InstrumentOptions().Logger().Errorf("failed to enqueue write: %v", err)
-			return err
+			return nil, err
 		}
 
-		enqueued++
+		state.enqueued++
 	}
 
 	s.RUnlock()
-	state.Wait()
-
-	err := s.writeConsistencyResult(majority, enqueued, enqueued-state.pending, int32(len(state.errors)), state.errors)
-	s.incWriteMetrics(err, int32(len(state.errors)))
-
-	state.Unlock()
-	state.decRef()
-
-	return err
+	return state, nil
 }
 
 func (s *session) Fetch(
@@ -800,7 +1046,17 @@ func (s *session) Fetch(
 	id string,
 	startInclusive, endExclusive time.Time,
 ) (encoding.SeriesIterator, error) {
-	results, err := s.FetchAll(namespace, []string{id}, startInclusive, endExclusive)
+	return s.FetchDownsampled(namespace, id, startInclusive, endExclusive, Downsample{})
+}
+
+func (s *session) FetchDownsampled(
+	namespace string,
+	id string,
+	startInclusive, endExclusive time.Time,
+	downsample Downsample,
+) (encoding.SeriesIterator, error) {
+	results, err := s.FetchAllDownsampled(namespace, []string{id},
+		startInclusive, endExclusive, downsample)
 	if err != nil {
 		return nil, err
 	}
@@ -817,10 +1073,20 @@ func (s *session) FetchAll(
 	namespace string,
 	ids []string,
 	startInclusive, endExclusive time.Time,
+) (encoding.SeriesIterators, error) {
+	return s.FetchAllDownsampled(namespace, ids, startInclusive, endExclusive, Downsample{})
+}
+
+func (s *session) FetchAllDownsampled(
+	namespace string,
+	ids []string,
+	startInclusive, endExclusive time.Time,
+	downsample Downsample,
 ) (encoding.SeriesIterators, error) {
 	f := s.fetchAttemptPool.Get()
 	f.args.namespace, f.args.ids, f.args.start, f.args.end =
 		namespace, ids, startInclusive, endExclusive
+	f.args.downsample = downsample
 	err := s.fetchRetrier.Attempt(f.attemptFn)
 	result := f.result
 	s.fetchAttemptPool.Put(f)
@@ -831,6 +1097,7 @@ func (s *session) fetchAllAttempt(
 	namespace string,
 	ids []string,
 	startInclusive, endExclusive time.Time,
+	downsample Downsample,
 ) (encoding.SeriesIterators, error) {
 	var (
 		wg                     sync.WaitGroup
@@ -842,7 +1109,11 @@ func (s *session) fetchAllAttempt(
 		resultErrs             int32
 		majority               int32
 		fetchBatchOpsByHostIdx [][]*fetchBatchOp
+		hostLoads              fetchHostLoads
+		hostIdxs               []int
+		hedgeFns               []func() (fetchEscalation, bool)
 		nsID                   = []byte(namespace)
+		minReplicas            = s.opts.ReadReplicaStrategy() == ReadReplicaStrategyMinimum
 		success                = false
 	)
 
@@ -856,6 +1127,21 @@ func (s *session) fetchAllAttempt(
 		return nil, tsErr
 	}
 
+	aggregation, aggErr := convert.ToRPCAggregationType(downsample.Aggregation)
+	if aggErr != nil {
+		return nil, aggErr
+	}
+
+	// Escalated reads are enqueued with the same range as the initial reads
+	escalationRequest := &rpc.FetchBatchRawRequest{
+		NameSpace:     nsID,
+		RangeStart:    rangeStart,
+		RangeEnd:      rangeEnd,
+		RangeTimeType: rpc.TimeType_UNIX_NANOSECONDS,
+		Step:          int64(downsample.Step),
+		Aggregation:   aggregation,
+	}
+
 	s.RLock()
 	if s.state != stateOpen {
 		s.RUnlock()
@@ -883,6 +1169,10 @@ func (s *session) fetchAllAttempt(
 
 	majority = atomic.LoadInt32(&s.majority)
 
+	if minReplicas {
+		hostLoads = newFetchHostLoads(s.queues)
+	}
+
 	for idx := range ids {
 		idx := idx
 
@@ -900,6 +1190,11 @@ func (s *session) fetchAllAttempt(
 			success          int32
 			errors           []error
 			errs             int32
+			// standby is the host queues of the replicas not yet read from when
+			// reading from the minimum replicas, in order of preference, these
+			// are captured when the fetch is issued so that escalating does not
+			// need the session lock
+			standby []hostQueue
 		)
 
 		wg.Add(1)
@@ -911,8 +1206,9 @@ func (s *session) fetchAllAttempt(
 				reportErrors = errors[:]
 				resultErrLock.RUnlock()
 			}
-			responded := enqueued - atomic.LoadInt32(&pending)
-			err := s.readConsistencyResult(majority, enqueued, responded, errsLen, reportErrors)
+			numEnqueued := atomic.LoadInt32(&enqueued)
+			responded := numEnqueued - atomic.LoadInt32(&pending)
+			err := s.readConsistencyResult(majority, numEnqueued, responded, errsLen, reportErrors)
 			s.incFetchMetrics(err, errsLen)
 			if err != nil {
 				resultErrLock.Lock()
@@ -934,7 +1230,38 @@ func (s *session) fetchAllAttempt(
 			}
 			wg.Done()
 		}
-		completionFn := func(result interface{}, err error) {
+
+		tsID := ts.StringID(ids[idx])
+
+		// escalate reserves the next replica not yet read from, if any, to read
+		// the ID from once a read fails or the hedge delay elapses.
+		escalate := func() (hostQueue, bool) {
+			resultsLock.Lock()
+			if len(standby) == 0 {
+				resultsLock.Unlock()
+				return nil, false
+			}
+			// Only escalate while the results are still accessed
+			for {
+				accessors := atomic.LoadInt32(&resultsAccessors)
+				if accessors == 0 {
+					resultsLock.Unlock()
+					return nil, false
+				}
+				if atomic.CompareAndSwapInt32(&resultsAccessors, accessors, accessors+1) {
+					break
+				}
+			}
+			queue := standby[0]
+			standby = standby[1:]
+			atomic.AddInt32(&enqueued, 1)
+			atomic.AddInt32(&pending, 1)
+			resultsLock.Unlock()
+			return queue, true
+		}
+
+		var completionFn completionFn
+		complete := func(result interface{}, err error, hedged bool) {
 			var snapshotSuccess int32
 			if err != nil {
 				atomic.AddInt32(&errs, 1)
@@ -946,6 +1273,17 @@ func (s *session) fetchAllAttempt(
 				resultErrLock.Lock()
 				errors = append(errors, err)
 				resultErrLock.Unlock()
+
+				// Read from the next replica before this read is no longer pending
+				// so that the ID is not completed while there are replicas left
+				if minReplicas && atomic.LoadInt32(&wgIsDone) == 0 {
+					if queue, ok := escalate(); ok {
+						s.metrics.fetchEscalatedOnError.Inc(1)
+						s.enqueueFetchEscalations(escalationRequest, []fetchEscalation{
+							{queue: queue, id: tsID.Data().Get(), completionFn: completionFn},
+						})
+					}
+				}
 			} else {
 				slicesIter := s.readerSliceOfSlicesIteratorPool.Get()
 				slicesIter.Reset(result.([]*rpc.Segments))
@@ -964,31 +1302,69 @@ func (s *session) fetchAllAttempt(
 			// which would cause a nil pointer exception.
 			remaining := atomic.AddInt32(&pending, -1)
 			doneAll := remaining == 0
+			var done bool
 			switch s.readLevel {
 			case ReadConsistencyLevelOne:
-				complete := snapshotSuccess > 0 || doneAll
-				if complete && atomic.CompareAndSwapInt32(&wgIsDone, 0, 1) {
-					allCompletionFn()
-				}
+				done = snapshotSuccess > 0 || doneAll
 			case ReadConsistencyLevelMajority, ReadConsistencyLevelUnstrictMajority:
-				complete := snapshotSuccess >= majority || doneAll
-				if complete && atomic.CompareAndSwapInt32(&wgIsDone, 0, 1) {
-					allCompletionFn()
-				}
+				done = snapshotSuccess >= majority || doneAll
 			case ReadConsistencyLevelAll:
-				if doneAll && atomic.CompareAndSwapInt32(&wgIsDone, 0, 1) {
-					allCompletionFn()
+				done = doneAll
+			}
+			if done && atomic.CompareAndSwapInt32(&wgIsDone, 0, 1) {
+				if hedged && err == nil {
+					// The hedged read completed the ID before the reads it hedged
+					s.metrics.fetchHedgesWon.Inc(1)
 				}
+				allCompletionFn()
 			}
 
 			if atomic.AddInt32(&resultsAccessors, -1) == 0 {
 				s.iteratorArrayPool.Put(results)
 			}
 		}
+		completionFn = func(result interface{}, err error) {
+			complete(result, err, false)
+		}
+		hedgedCompletionFn := func(result interface{}, err error) {
+			complete(result, err, true)
+		}
 
-		tsID := ts.StringID(ids[idx])
-
+		hostIdxs = hostIdxs[:0]
 		if err := s.topoMap.RouteForEach(tsID, func(hostIdx int, host topology.Host) {
+			hostIdxs = append(hostIdxs, hostIdx)
+		}); err != nil {
+			routeErr = err
+			break
+		}
+
+		required := len(hostIdxs)
+		if minReplicas {
+			required = readReplicasRequired(s.readLevel, int(majority), len(hostIdxs))
+			hostLoads.choose(hostIdxs, required)
+			for _, hostIdx := range hostIdxs[required:] {
+				standby = append(standby, s.queues[hostIdx])
+			}
+			if len(standby) > 0 {
+				hedgeFns = append(hedgeFns, func() (fetchEscalation, bool) {
+					if atomic.LoadInt32(&wgIsDone) == 1 {
+						return fetchEscalation{}, false
+					}
+					if !s.fetchHedgeBudget.Spend() {
+						s.metrics.fetchHedgesThrottled.Inc(1)
+						return fetchEscalation{}, false
+					}
+					queue, ok := escalate()
+					if !ok {
+						s.fetchHedgeBudget.Refund()
+						return fetchEscalation{}, false
+					}
+					return fetchEscalation{queue: queue, id: tsID.Data().Get(), completionFn: hedgedCompletionFn}, true
+				})
+			}
+		}
+
+		for _, hostIdx := range hostIdxs[:required] {
 			// Inc safely as this for each is sequential
 			enqueued++
 			pending++
@@ -1013,18 +1389,18 @@ func (s *session) fetchAllAttempt(
 				f.request.RangeStart = rangeStart
 				f.request.RangeEnd = rangeEnd
 				f.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
+				f.request.Step = int64(downsample.Step)
+				f.request.Aggregation = aggregation
 			}
 
 			// Append IDWithNamespace to this request
 			f.append(nsID, tsID.Data().Get(), completionFn)
-		}); err != nil {
-			routeErr = err
-			break
 		}
 
-		// Once we've enqueued we know how many to expect so retrieve and set length
-		results = s.iteratorArrayPool.Get(int(enqueued))
-		results = results[:enqueued]
+		// Once we've enqueued we know how many to expect so retrieve and set
+		// length, reads escalated to further replicas are also set in results
+		results = s.iteratorArrayPool.Get(len(hostIdxs))
+		results = results[:len(hostIdxs)]
 	}
 
 	if routeErr != nil {
@@ -1033,7 +1409,17 @@ func (s *session) fetchAllAttempt(
 	}
 
 	// Enqueue fetch ops
+	hedgeDelay := s.opts.ReadHedgeDelay()
+	hedgePercentile := s.opts.ReadHedgePercentile()
+	var hedgeLatency time.Duration
 	for idx := range fetchBatchOpsByHostIdx {
+		if hedgePercentile > 0 && len(fetchBatchOpsByHostIdx[idx]) > 0 {
+			// Hedge once the reads take longer than the given percentile of
+			// the recent fetches from the slowest of the hosts read from
+			if latency := s.queues[idx].FetchLatency(hedgePercentile); latency > hedgeLatency {
+				hedgeLatency = latency
+			}
+		}
 		for _, f := range fetchBatchOpsByHostIdx[idx] {
 			// Passing ownership of the op itself to the host queue
 			f.DecRef()
@@ -1054,6 +1440,18 @@ func (s *session) fetchAllAttempt(
 		return nil, enqueueErr
 	}
 
+	if minReplicas {
+		s.fetchHedgeBudget.Earn(len(ids))
+	}
+	if hedgeLatency > 0 {
+		hedgeDelay = hedgeLatency
+	}
+	if len(hedgeFns) > 0 && hedgeDelay > 0 {
+		hedgeDone := make(chan struct{})
+		defer close(hedgeDone)
+		go s.hedgeFetches(escalationRequest, hedgeFns, hedgeDelay, hedgeDone)
+	}
+
 	wg.Wait()
 
 	resultErrLock.RLock()
@@ -1066,6 +1464,166 @@ func (s *session) fetchAllAttempt(
 	return iters, nil
 }
 
+// hedgeFetches escalates the reads of IDs that have yet to complete to another
+// replica each time the hedge delay elapses until all reads are complete or
+// there are no replicas left to read from.
+func (s *session) hedgeFetches(
+	request *rpc.FetchBatchRawRequest,
+	hedgeFns []func() (fetchEscalation, bool),
+	hedgeDelay time.Duration,
+	done <-chan struct{},
+) {
+	timer := time.NewTimer(hedgeDelay)
+	defer timer.Stop()
+
+	var escalations []fetchEscalation
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		escalations = escalations[:0]
+		for _, fn := range hedgeFns {
+			if e, ok := fn(); ok {
+				escalations = append(escalations, e)
+			}
+		}
+		if len(escalations) == 0 {
+			return
+		}
+		s.metrics.fetchHedgesIssued.Inc(int64(len(escalations)))
+		s.enqueueFetchEscalations(request, escalations)
+		timer.Reset(hedgeDelay)
+	}
+}
+
+func (s *session) FetchTagged(
+	namespace string,
+	query index.Query,
+	startInclusive, endExclusive time.Time,
+) (encoding.SeriesIterators, error) {
+	matchers, err := convert.ToRPCTagMatchers(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeStart, tsErr := convert.ToValue(startInclusive, rpc.TimeType_UNIX_NANOSECONDS)
+	if tsErr != nil {
+		return nil, tsErr
+	}
+
+	rangeEnd, tsErr := convert.ToValue(endExclusive, rpc.TimeType_UNIX_NANOSECONDS)
+	if tsErr != nil {
+		return nil, tsErr
+	}
+
+	var (
+		wg          sync.WaitGroup
+		enqueueErr  xerrors.MultiError
+		resultsLock sync.Mutex
+		hostErrs    = make(map[string]error)
+		segments    = make(map[string][][]*rpc.Segments)
+	)
+
+	f := &fetchTaggedOp{}
+	f.request.NameSpace = []byte(namespace)
+	f.request.Matchers = matchers
+	f.request.RangeStart = rangeStart
+	f.request.RangeEnd = rangeEnd
+	f.request.RangeTimeType = rpc.TimeType_UNIX_NANOSECONDS
+	f.request.FetchData = true
+	f.completionFn = func(result interface{}, err error) {
+		r := result.(fetchTaggedHostResult)
+		resultsLock.Lock()
+		if err != nil {
+			hostErrs[r.host.ID()] = err
+		} else {
+			for _, elem := range r.result.Elements {
+				id := string(elem.ID)
+				segments[id] = append(segments[id], elem.Segments)
+			}
+		}
+		resultsLock.Unlock()
+		wg.Done()
+	}
+
+	s.RLock()
+	if s.state != stateOpen {
+		s.RUnlock()
+		return nil, errSessionStateNotOpen
+	}
+	topoMap := s.topoMap
+	majority := atomic.LoadInt32(&s.majority)
+	for idx := range s.queues {
+		wg.Add(1)
+		if err := s.queues[idx].Enqueue(f); err != nil {
+			wg.Done()
+			enqueueErr = enqueueErr.Add(err)
+		}
+	}
+	s.RUnlock()
+
+	if err := enqueueErr.FinalError(); err != nil {
+		s.log.Errorf("failed to enqueue request: %v", err)
+		return nil, err
+	}
+
+	// Wait for the query to be evaluated on all hosts
+	wg.Wait()
+
+	// The index of each host only covers the shards it owns, so every
+	// shard must meet the read consistency level for the results to be complete
+	for _, shard := range topoMap.ShardSet().AllIDs() {
+		var (
+			enqueued   int32
+			resultErrs int32
+			shardErrs  []error
+		)
+		if err := topoMap.RouteShardForEach(shard, func(_ int, host topology.Host) {
+			enqueued++
+			if err, ok := hostErrs[host.ID()]; ok {
+				resultErrs++
+				shardErrs = append(shardErrs, err)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		err := s.readConsistencyResult(majority, enqueued, enqueued, resultErrs, shardErrs)
+		if err != nil {
+			s.incFetchMetrics(err, resultErrs)
+			return nil, err
+		}
+	}
+	s.incFetchMetrics(nil, int32(len(hostErrs)))
+
+	ids := make([]string, 0, len(segments))
+	for id := range segments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	iters := s.seriesIteratorsPool.Get(len(ids))
+	iters.Reset(len(ids))
+	for i, id := range ids {
+		replicas := segments[id]
+		results := make([]encoding.Iterator, 0, len(replicas))
+		for _, segs := range replicas {
+			slicesIter := s.readerSliceOfSlicesIteratorPool.Get()
+			slicesIter.Reset(segs)
+			multiIter := s.multiReaderIteratorPool.Get()
+			multiIter.ResetSliceOfSlices(slicesIter)
+			results = append(results, multiIter)
+		}
+		iter := s.seriesIteratorPool.Get()
+		iter.Reset(id, startInclusive, endExclusive, results)
+		iters.SetAt(i, iter)
+	}
+
+	return iters, nil
+}
+
 func (s *session) writeConsistencyResult(
 	majority, enqueued, responded, resultErrs int32,
 	errs []error,
@@ -1139,9 +1697,49 @@ func (s *session) Close() error {
 
 	s.topoWatch.Close()
 	s.topo.Close()
+
+	if s.hints != nil {
+		s.hints.Close()
+	}
 	return nil
 }
 
+// replayHintsEvery replays the hints stored for writes that failed to reach
+// hosts once the hosts are healthy again.
+func (s *session) replayHintsEvery(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+
+		s.RLock()
+		if s.state != stateOpen {
+			s.RUnlock()
+			return
+		}
+		queues := make([]hostQueue, len(s.queues))
+		copy(queues, s.queues)
+		s.RUnlock()
+
+		for _, q := range queues {
+			hostID := q.Host().ID()
+			healthy := q.ConnectionCount() > 0 &&
+				q.CircuitBreakerState() == circuitBreakerClosed
+			if !healthy || !s.hints.Exists(hostID) {
+				continue
+			}
+
+			var replayErr error
+			if err := q.BorrowConnection(func(client rpc.TChanNode) {
+				replayErr = s.hints.Replay(hostID, client)
+			}); err != nil {
+				replayErr = err
+			}
+			if replayErr != nil {
+				s.log.Errorf("could not replay hints to host %s: %v", hostID, replayErr)
+			}
+		}
+	}
+}
+
 func (s *session) Origin() topology.Host {
 	return s.origin
 }
@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package client
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/generated/thrift/rpc"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(time.Hour)
+
+	var deletes int32
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			d, ok := op.(*deleteOp)
+			assert.True(t, ok)
+			assert.Equal(t, "metrics", d.request.NameSpace)
+			assert.Equal(t, "foo", d.request.ID)
+			assert.Equal(t, start.UnixNano(), d.request.RangeStart)
+			assert.Equal(t, end.UnixNano(), d.request.RangeEnd)
+			assert.Equal(t, rpc.TimeType_UNIX_NANOSECONDS, d.request.RangeType)
+
+			atomic.AddInt32(&deletes, 1)
+			d.completionFn(nil, nil)
+		},
+	})
+
+	assert.NoError(t, session.Open())
+
+	require.NoError(t, s.Delete("metrics", "foo", start, end))
+	assert.Equal(t, int32(sessionTestReplicas), atomic.LoadInt32(&deletes))
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionDeleteBadRequestNotRetried(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	var attempts int32
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			atomic.AddInt32(&attempts, 1)
+			op.CompletionFn()(nil, &rpc.Error{
+				Type:    rpc.ErrorType_BAD_REQUEST,
+				Message: "invalid range",
+			})
+		},
+	})
+
+	assert.NoError(t, session.Open())
+
+	end := time.Now()
+	err = s.Delete("metrics", "foo", end, end)
+	require.Error(t, err)
+	assert.True(t, IsBadRequestError(err))
+	assert.Equal(t, int32(sessionTestReplicas), atomic.LoadInt32(&attempts))
+
+	assert.NoError(t, session.Close())
+}
@@ -145,6 +145,48 @@ func TestSessionFetchAll(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestSessionFetchAllDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := newSessionTestOptions()
+	s, err := newSession(opts)
+	assert.NoError(t, err)
+	session := s.(*session)
+
+	start := time.Now().Truncate(time.Hour)
+	end := start.Add(2 * time.Hour)
+
+	// Values are as returned by nodes already downsampled
+	fetches := testFetches([]testFetch{
+		{"foo", []testValue{
+			{1.0, start, xtime.Second, nil},
+			{2.0, start.Add(1 * time.Minute), xtime.Second, nil},
+		}},
+	})
+
+	fetchBatchOps, enqueueWg := prepareEnqueues(t, ctrl, session, fetches)
+
+	go func() {
+		// Fulfill fetch ops once enqueued
+		enqueueWg.Wait()
+		for _, op := range *fetchBatchOps {
+			assert.Equal(t, int64(time.Minute), op.request.Step)
+			assert.Equal(t, rpc.AggregationType_AVG, op.request.Aggregation)
+		}
+		fulfillTszFetchBatchOps(t, fetches, *fetchBatchOps, 0)
+	}()
+
+	assert.NoError(t, session.Open())
+
+	results, err := session.FetchAllDownsampled(testNamespaceName, fetches.IDs(), start, end,
+		Downsample{Step: time.Minute, Aggregation: encoding.AggregationAvg})
+	assert.NoError(t, err)
+	assertFetchResults(t, start, end, fetches, results)
+
+	assert.NoError(t, session.Close())
+}
+
 func TestSessionFetchAllTrimsWindowsInTimeWindow(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
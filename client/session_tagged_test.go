@@ -0,0 +1,166 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package client
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/encoding/m3tsz"
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/ts"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionWriteTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	w := newWriteStub()
+	tags := ts.Tags{{Name: ts.StringID("city"), Value: ts.StringID("nyc")}}
+	var completionFn completionFn
+	enqueueWg := mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{func(idx int, op op) {
+		completionFn = op.CompletionFn()
+		write, ok := op.(*writeOp)
+		assert.True(t, ok)
+		assert.Equal(t, w.id, string(write.request.ID))
+		assert.Equal(t, w.value, write.request.Datapoint.Value)
+		assert.Equal(t, []*rpc.Tag{{Name: "city", Value: "nyc"}}, write.tags)
+	}})
+
+	assert.NoError(t, session.Open())
+
+	var resultErr error
+	var writeWg sync.WaitGroup
+	writeWg.Add(1)
+	go func() {
+		resultErr = session.WriteTagged(w.ns, w.id, tags, w.t, w.value, w.unit, w.annotation)
+		writeWg.Done()
+	}()
+
+	enqueueWg.Wait()
+	for i := 0; i < session.topoMap.Replicas(); i++ {
+		completionFn(session.topoMap.Hosts()[0], nil)
+	}
+
+	writeWg.Wait()
+	assert.Nil(t, resultErr)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionFetchTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	start := time.Now().Truncate(time.Second).Add(-time.Minute)
+	end := start.Add(time.Minute)
+	values := []float64{1, 2}
+
+	encoder := m3tsz.NewEncoder(start, nil, true, nil)
+	for i, v := range values {
+		dp := ts.Datapoint{Timestamp: start.Add(time.Duration(i) * time.Second), Value: v}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	seg := encoder.Discard()
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{func(idx int, op op) {
+		fetch, ok := op.(*fetchTaggedOp)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("metrics"), fetch.request.NameSpace)
+		assert.Equal(t, []*rpc.TagMatcher{
+			{Type: rpc.TagMatcherType_EQUAL, Name: "city", Value: "nyc"},
+		}, fetch.request.Matchers)
+		assert.True(t, fetch.request.FetchData)
+
+		result := rpc.NewFetchTaggedResult_()
+		result.Exhaustive = true
+		result.Elements = []*rpc.FetchTaggedIDResult_{{
+			ID:   []byte("foo"),
+			Tags: []*rpc.Tag{{Name: "city", Value: "nyc"}},
+			Segments: []*rpc.Segments{{
+				Merged: &rpc.Segment{Head: seg.Head.Get(), Tail: seg.Tail.Get()},
+			}},
+		}}
+		fetch.completionFn(fetchTaggedHostResult{
+			host:   session.topoMap.Hosts()[idx],
+			result: result,
+		}, nil)
+	}})
+
+	assert.NoError(t, session.Open())
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchEqual, Name: "city", Value: "nyc"},
+	}}
+	iters, err := session.FetchTagged("metrics", query, start, end)
+	require.NoError(t, err)
+	require.Equal(t, 1, iters.Len())
+
+	iter := iters.Iters()[0]
+	assert.Equal(t, "foo", iter.ID())
+	var actual []float64
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		actual = append(actual, dp.Value)
+	}
+	require.NoError(t, iter.Err())
+	assert.Equal(t, values, actual)
+	iters.Close()
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionFetchTaggedConsistencyError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{func(idx int, op op) {
+		fetch, ok := op.(*fetchTaggedOp)
+		assert.True(t, ok)
+		fetch.completionFn(fetchTaggedHostResult{
+			host: session.topoMap.Hosts()[idx],
+		}, &rpc.Error{Type: rpc.ErrorType_INTERNAL_ERROR, Message: "an error"})
+	}})
+
+	assert.NoError(t, session.Open())
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchEqual, Name: "city", Value: "nyc"},
+	}}
+	now := time.Now()
+	_, err := session.FetchTagged("metrics", query, now.Add(-time.Minute), now)
+	require.Error(t, err)
+
+	assert.NoError(t, session.Close())
+}
@@ -35,10 +35,10 @@ import (
 	xerrors "github.com/m3db/m3x/errors"
 	xtime "github.com/m3db/m3x/time"
 
-	"github.com/uber-go/tally"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
 )
 
 func TestSessionWriteNotOpenError(t *testing.T) {
@@ -178,6 +178,135 @@ func TestSessionWriteBadRequestErrorIsNonRetryable(t *testing.T) {
 	assert.NoError(t, session.Close())
 }
 
+func TestSessionWriteAsync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	w := newWriteStub()
+	var hosts []topology.Host
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			go func() {
+				op.CompletionFn()(hosts[idx], nil)
+			}()
+		},
+	})
+
+	assert.NoError(t, session.Open())
+
+	session.RLock()
+	hosts = session.topoMap.Hosts()
+	session.RUnlock()
+
+	resultCh := make(chan error, 1)
+	require.NoError(t, session.WriteAsync(w.ns, w.id, w.t, w.value, w.unit, w.annotation,
+		func(err error) {
+			// The pending write is released before calling back
+			assert.Equal(t, 0, len(session.writeAsyncPending))
+			resultCh <- err
+		}))
+	assert.NoError(t, <-resultCh)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionWriteAsyncError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	w := newWriteStub()
+	var hosts []topology.Host
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		func(idx int, op op) {
+			go func() {
+				op.CompletionFn()(hosts[idx], errors.New("timed out"))
+			}()
+		},
+	})
+
+	assert.NoError(t, session.Open())
+
+	session.RLock()
+	hosts = session.topoMap.Hosts()
+	session.RUnlock()
+
+	// Asynchronous writes are not retried, the error is called back
+	resultCh := make(chan error, 1)
+	require.NoError(t, session.WriteAsync(w.ns, w.id, w.t, w.value, w.unit, w.annotation,
+		func(err error) {
+			resultCh <- err
+		}))
+	assert.Error(t, <-resultCh)
+
+	assert.NoError(t, session.Close())
+}
+
+func TestSessionWriteAsyncNotOpenError(t *testing.T) {
+	opts := newSessionTestOptions().SetWriteAsyncMaxPending(1)
+	s := newTestSession(t, opts).(*session)
+
+	// Ensure the session state is checked before blocking on pending writes
+	s.writeAsyncPending <- struct{}{}
+	err := s.WriteAsync("namespace", "foo", time.Now(), 1.337, xtime.Second, nil,
+		func(err error) {
+			assert.Fail(t, "unexpected callback")
+		})
+	assert.Equal(t, errSessionStateNotOpen, err)
+	assert.Equal(t, 1, len(s.writeAsyncPending))
+}
+
+func TestSessionWriteBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := newDefaultTestSession(t).(*session)
+
+	var (
+		hosts []topology.Host
+		start = time.Now()
+	)
+	completeWrite := func(idx int, op op) {
+		write := op.(*writeOp)
+		var err error
+		if string(write.request.ID) == "bar" {
+			err = &rpc.Error{
+				Type:    rpc.ErrorType_BAD_REQUEST,
+				Message: "expected bad request error",
+			}
+		}
+		go func() {
+			op.CompletionFn()(hosts[idx], err)
+		}()
+	}
+	mockHostQueues(ctrl, session, sessionTestReplicas, []testEnqueueFn{
+		completeWrite, completeWrite, completeWrite,
+	})
+
+	assert.NoError(t, session.Open())
+
+	session.RLock()
+	hosts = session.topoMap.Hosts()
+	session.RUnlock()
+
+	err := session.WriteBatch("testNs", []BatchWrite{
+		{ID: "foo", Timestamp: start, Value: 1.0, Unit: xtime.Second},
+		{ID: "bar", Timestamp: start, Value: 2.0, Unit: xtime.Second},
+		{ID: "baz", Timestamp: start, Value: 3.0, Unit: xtime.Second},
+	})
+	require.Error(t, err)
+	batchErrs, ok := err.(*rpc.WriteBatchRawErrors)
+	require.True(t, ok)
+	require.Equal(t, 1, len(batchErrs.Errors))
+	assert.Equal(t, int64(1), batchErrs.Errors[0].Index)
+	assert.True(t, IsBadRequestError(batchErrs.Errors[0].Err))
+
+	assert.NoError(t, session.Close())
+}
+
 func TestSessionWriteConsistencyLevelAll(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3db/generated/thrift/rpc"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/index"
 	"github.com/m3db/m3db/topology"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/instrument"
@@ -111,6 +112,33 @@ func (l ReadConsistencyLevel) String() string {
 	return "ReadConsistencyLevelUnknown"
 }
 
+// ReadReplicaStrategy is the strategy for choosing which replicas of an ID
+// to read from when fetching
+type ReadReplicaStrategy int
+
+const (
+	// ReadReplicaStrategyAll corresponds to reading from all of the replicas
+	// of an ID regardless of the read consistency level
+	ReadReplicaStrategyAll ReadReplicaStrategy = iota
+
+	// ReadReplicaStrategyMinimum corresponds to reading from only as many of
+	// the replicas of an ID as the read consistency level requires, preferring
+	// healthy and least loaded hosts, and escalating to the remaining replicas
+	// when a read fails or the read hedge delay elapses
+	ReadReplicaStrategyMinimum
+)
+
+// String returns the read replica strategy as a string
+func (s ReadReplicaStrategy) String() string {
+	switch s {
+	case ReadReplicaStrategyAll:
+		return "ReadReplicaStrategyAll"
+	case ReadReplicaStrategyMinimum:
+		return "ReadReplicaStrategyMinimum"
+	}
+	return "ReadReplicaStrategyUnknown"
+}
+
 // Client can create sessions to write and read to a cluster
 type Client interface {
 	// NewSession creates a new session
@@ -123,17 +151,67 @@ type Client interface {
 	DefaultSessionActive() bool
 }
 
+// Downsample is a read-time downsampling of fetched values, values are
+// aggregated into steps aligned to the start of the fetch.
+type Downsample struct {
+	// Step is the resolution of the downsampled values
+	Step time.Duration
+
+	// Aggregation is the aggregation applied to the values within a step
+	Aggregation encoding.AggregationType
+}
+
+// WriteCallback is called with the result of an asynchronous write once the
+// write consistency level is met or all replicas respond, it is called from
+// the host queue completing the write once the pending asynchronous write is
+// released and must not block
+type WriteCallback func(err error)
+
+// BatchWrite is a single write of a batch of writes to a namespace
+type BatchWrite struct {
+	ID         string
+	Timestamp  time.Time
+	Value      float64
+	Unit       xtime.Unit
+	Annotation []byte
+}
+
 // Session can write and read to a cluster
 type Session interface {
 	// Write value to the database for an ID
 	Write(namespace string, id string, t time.Time, value float64, unit xtime.Unit, annotation []byte) error
 
+	// WriteTagged value to the database for an ID and the tags it is indexed by
+	WriteTagged(namespace string, id string, tags ts.Tags, t time.Time, value float64, unit xtime.Unit, annotation []byte) error
+
+	// WriteAsync writes a value to the database for an ID without retrying and calls
+	// the callback once complete, it blocks while the max pending asynchronous writes
+	// are outstanding and returns an error only if the write is not performed
+	WriteAsync(namespace string, id string, t time.Time, value float64, unit xtime.Unit, annotation []byte, callback WriteCallback) error
+
+	// WriteBatch writes values to the database for a batch of writes, returning
+	// a rpc.WriteBatchRawErrors with the index of each of the writes that failed
+	WriteBatch(namespace string, writes []BatchWrite) error
+
+	// Delete all values for an ID within a time range, the deleted range
+	// is hidden from reads immediately and removed from disk on the next flush
+	Delete(namespace string, id string, start, end time.Time) error
+
 	// Fetch values from the database for an ID
 	Fetch(namespace string, id string, startInclusive, endExclusive time.Time) (encoding.SeriesIterator, error)
 
 	// FetchAll values from the database for a set of IDs
 	FetchAll(namespace string, ids []string, startInclusive, endExclusive time.Time) (encoding.SeriesIterators, error)
 
+	// FetchDownsampled values from the database for an ID downsampled node-side
+	FetchDownsampled(namespace string, id string, startInclusive, endExclusive time.Time, downsample Downsample) (encoding.SeriesIterator, error)
+
+	// FetchAllDownsampled values from the database for a set of IDs downsampled node-side
+	FetchAllDownsampled(namespace string, ids []string, startInclusive, endExclusive time.Time, downsample Downsample) (encoding.SeriesIterators, error)
+
+	// FetchTagged values from the database for the IDs matching an index query
+	FetchTagged(namespace string, query index.Query, startInclusive, endExclusive time.Time) (encoding.SeriesIterators, error)
+
 	// ShardID returns the given shard for an ID for callers
 	// to easily discern what shard is failing when operations
 	// for given IDs begin failing
@@ -235,6 +313,11 @@ type hostQueue interface {
 	// Len returns the length of the queue
 	Len() int
 
+	// FetchLatency returns the given percentile, between zero and one hundred,
+	// of the latencies of the most recent fetches from the host, zero if no
+	// fetches have completed
+	FetchLatency(percentile float64) time.Duration
+
 	// Enqueue an operation
 	Enqueue(op op) error
 
@@ -244,6 +327,9 @@ type hostQueue interface {
 	// ConnectionCount gets the current open connection count
 	ConnectionCount() int
 
+	// CircuitBreakerState gets the current circuit breaker state of the host
+	CircuitBreakerState() circuitBreakerState
+
 	// ConnectionPool gets the connection pool
 	ConnectionPool() connectionPool
 
@@ -263,9 +349,17 @@ type connectionPool interface {
 	// ConnectionCount gets the current open connection count
 	ConnectionCount() int
 
-	// NextClient gets the next client for use by the connection pool
+	// NextClient gets the next client for use by the connection pool, fails
+	// fast while the circuit breaker of the host is open
 	NextClient() (rpc.TChanNode, error)
 
+	// ReportResult reports the result of a request made with a client from
+	// the connection pool to the circuit breaker of the host
+	ReportResult(err error)
+
+	// CircuitBreakerState gets the current circuit breaker state of the host
+	CircuitBreakerState() circuitBreakerState
+
 	// Close the connection pool
 	Close()
 }
@@ -337,6 +431,42 @@ type Options interface {
 	// ReadConsistencyLevel returns the read consistency level
 	ReadConsistencyLevel() ReadConsistencyLevel
 
+	// SetReadReplicaStrategy sets the strategy for choosing replicas to read from
+	SetReadReplicaStrategy(value ReadReplicaStrategy) Options
+
+	// ReadReplicaStrategy returns the strategy for choosing replicas to read from
+	ReadReplicaStrategy() ReadReplicaStrategy
+
+	// SetReadHedgeDelay sets the delay after which reads of IDs that have not
+	// met the read consistency level are escalated to another replica when
+	// reading from the minimum replicas, zero escalates only on read failures
+	SetReadHedgeDelay(value time.Duration) Options
+
+	// ReadHedgeDelay returns the delay after which reads of IDs that have not
+	// met the read consistency level are escalated to another replica when
+	// reading from the minimum replicas, zero escalates only on read failures
+	ReadHedgeDelay() time.Duration
+
+	// SetReadHedgePercentile sets the percentile, between zero and one hundred,
+	// of the recent fetch latencies of the replicas read from after which reads
+	// are hedged to another replica when reading from the minimum replicas,
+	// zero uses the read hedge delay instead
+	SetReadHedgePercentile(value float64) Options
+
+	// ReadHedgePercentile returns the percentile, between zero and one hundred,
+	// of the recent fetch latencies of the replicas read from after which reads
+	// are hedged to another replica when reading from the minimum replicas,
+	// zero uses the read hedge delay instead
+	ReadHedgePercentile() float64
+
+	// SetReadHedgeMaxRatio sets the max ratio of hedged reads to reads, which
+	// caps the extra load hedged reads put on replicas
+	SetReadHedgeMaxRatio(value float64) Options
+
+	// ReadHedgeMaxRatio returns the max ratio of hedged reads to reads, which
+	// caps the extra load hedged reads put on replicas
+	ReadHedgeMaxRatio() float64
+
 	// SetChannelOptions sets the channelOptions
 	SetChannelOptions(value *tchannel.ChannelOptions) Options
 
@@ -435,6 +565,26 @@ type Options interface {
 	// timeout to produce a throttle sleep value.
 	BackgroundHealthCheckFailThrottleFactor() float64
 
+	// SetHostCircuitBreakerFailLimit sets the number of consecutive requests
+	// to a host that time out or fail to reach it before its circuit breaker
+	// opens and requests to the host fail fast, zero disables the breaker
+	// and is the default
+	SetHostCircuitBreakerFailLimit(value int) Options
+
+	// HostCircuitBreakerFailLimit returns the number of consecutive requests
+	// to a host that time out or fail to reach it before its circuit breaker
+	// opens and requests to the host fail fast, zero disables the breaker
+	// and is the default
+	HostCircuitBreakerFailLimit() int
+
+	// SetHostCircuitBreakerOpenDuration sets the duration a host circuit
+	// breaker stays open before half opening to allow a probe request
+	SetHostCircuitBreakerOpenDuration(value time.Duration) Options
+
+	// HostCircuitBreakerOpenDuration returns the duration a host circuit
+	// breaker stays open before half opening to allow a probe request
+	HostCircuitBreakerOpenDuration() time.Duration
+
 	// SetWriteRetrier sets the write retrier when performing a write for
 	// a write operation. Only retryable errors are retried.
 	SetWriteRetrier(value xretry.Retrier) Options
@@ -475,6 +625,48 @@ type Options interface {
 	// WriteOpPoolSize returns the writeOpPoolSize
 	WriteOpPoolSize() int
 
+	// SetWriteAsyncMaxPending sets the max number of asynchronous writes that
+	// can be pending completion before further asynchronous writes block
+	SetWriteAsyncMaxPending(value int) Options
+
+	// WriteAsyncMaxPending returns the max number of asynchronous writes that
+	// can be pending completion before further asynchronous writes block
+	WriteAsyncMaxPending() int
+
+	// SetHintsFilePathPrefix sets the directory hints for writes that failed
+	// to reach a replica are stored in to replay to the replica once it is
+	// healthy again, empty disables storing hints
+	SetHintsFilePathPrefix(value string) Options
+
+	// HintsFilePathPrefix returns the directory hints for writes that failed
+	// to reach a replica are stored in to replay to the replica once it is
+	// healthy again, empty disables storing hints
+	HintsFilePathPrefix() string
+
+	// SetHintsTTL sets the duration after which hints are dropped rather
+	// than replayed
+	SetHintsTTL(value time.Duration) Options
+
+	// HintsTTL returns the duration after which hints are dropped rather
+	// than replayed
+	HintsTTL() time.Duration
+
+	// SetHintsMaxBytesPerHost sets the max bytes of hints stored for a host,
+	// hints are dropped once exceeded
+	SetHintsMaxBytesPerHost(value int64) Options
+
+	// HintsMaxBytesPerHost returns the max bytes of hints stored for a host,
+	// hints are dropped once exceeded
+	HintsMaxBytesPerHost() int64
+
+	// SetHintsReplayInterval sets the interval between replays of the hints
+	// stored for hosts that are healthy
+	SetHintsReplayInterval(value time.Duration) Options
+
+	// HintsReplayInterval returns the interval between replays of the hints
+	// stored for hosts that are healthy
+	HintsReplayInterval() time.Duration
+
 	// SetFetchBatchOpPoolSize sets the fetchBatchOpPoolSize
 	SetFetchBatchOpPoolSize(value int) Options
 
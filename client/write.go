@@ -44,6 +44,7 @@ type writeOp struct {
 	shardID      uint32
 	request      rpc.WriteBatchRawRequestElement
 	datapoint    rpc.Datapoint
+	tags         []*rpc.Tag
 	completionFn completionFn
 }
 
@@ -100,8 +101,9 @@ type writeState struct {
 	nsID              ts.ID
 	tsID              ts.ID
 	majority, pending int32
-	success           int32
+	enqueued, success int32
 	errors            []error
+	callbackFn        WriteCallback
 
 	queues []hostQueue
 }
@@ -118,6 +120,7 @@ func (w *writeState) close() {
 	w.session.writeOpPool.Put(w.op)
 
 	w.op, w.majority, w.pending, w.success = nil, 0, 0, 0
+	w.enqueued, w.callbackFn = 0, nil
 	w.nsID, w.tsID = nil, nil
 
 	for i := range w.errors {
@@ -140,6 +143,11 @@ func (w *writeState) completionFn(result interface{}, err error) {
 	hostID := result.(topology.Host).ID()
 	// NB(bl) panic on invalid result, it indicates a bug in the code
 
+	if err != nil && w.session.hints != nil && !IsBadRequestError(err) {
+		// Store a hint to replay the write to the host once it is healthy
+		w.session.hints.Store(hostID, w.op)
+	}
+
 	w.Lock()
 	w.pending--
 
@@ -173,22 +181,39 @@ func (w *writeState) completionFn(result interface{}, err error) {
 		w.errors = append(w.errors, wErr)
 	}
 
+	var done bool
 	switch w.session.writeLevel {
 	case topology.ConsistencyLevelOne:
-		if w.success > 0 || w.pending == 0 {
-			w.Signal()
-		}
+		done = w.success > 0 || w.pending == 0
 	case topology.ConsistencyLevelMajority:
-		if w.success >= w.majority || w.pending == 0 {
-			w.Signal()
-		}
+		done = w.success >= w.majority || w.pending == 0
 	case topology.ConsistencyLevelAll:
-		if w.pending == 0 {
-			w.Signal()
-		}
+		done = w.pending == 0
 	}
 
+	if !done {
+		w.Unlock()
+		w.decRef()
+		return
+	}
+
+	if w.callbackFn == nil {
+		w.Signal()
+		w.Unlock()
+		w.decRef()
+		return
+	}
+
+	// Asynchronous writes call back once, when the consistency level
+	// is first met, with the result at that point
+	callbackFn := w.callbackFn
+	w.callbackFn = nil
+	resultErr := w.session.writeConsistencyResult(w.majority, w.enqueued,
+		w.enqueued-w.pending, int32(len(w.errors)), w.errors)
+	w.session.incWriteMetrics(resultErr, int32(len(w.errors)))
 	w.Unlock()
+
+	callbackFn(resultErr)
 	w.decRef()
 }
 
@@ -23,6 +23,7 @@ package client
 import (
 	"time"
 
+	"github.com/m3db/m3db/generated/thrift/rpc"
 	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/pool"
 	xretry "github.com/m3db/m3x/retry"
@@ -42,6 +43,7 @@ type writeAttempt struct {
 type writeAttemptArgs struct {
 	namespace  string
 	id         string
+	tags       []*rpc.Tag
 	t          time.Time
 	value      float64
 	unit       xtime.Unit
@@ -53,7 +55,7 @@ func (w *writeAttempt) reset() {
 }
 
 func (w *writeAttempt) perform() error {
-	err := w.session.writeAttempt(w.args.namespace, w.args.id,
+	err := w.session.writeAttempt(w.args.namespace, w.args.id, w.args.tags,
 		w.args.t, w.args.value, w.args.unit, w.args.annotation)
 
 	if IsBadRequestError(err) {
@@ -0,0 +1,186 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/m3db/m3db/ts"
+	xtime "github.com/m3db/m3x/time"
+)
+
+var aggregationTypeNames = map[AggregationType]string{
+	AggregationNone:  "none",
+	AggregationMin:   "min",
+	AggregationMax:   "max",
+	AggregationSum:   "sum",
+	AggregationCount: "count",
+	AggregationAvg:   "avg",
+	AggregationLast:  "last",
+}
+
+// String returns the name of the aggregation type
+func (t AggregationType) String() string {
+	if name, ok := aggregationTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
+// ParseAggregationType parses an aggregation type from its name
+func ParseAggregationType(str string) (AggregationType, error) {
+	for t, name := range aggregationTypeNames {
+		if name == str {
+			return t, nil
+		}
+	}
+	return AggregationNone, fmt.Errorf("unknown aggregation type %s", str)
+}
+
+type downsampleIterator struct {
+	iter        Iterator
+	start       time.Time
+	step        time.Duration
+	aggregation AggregationType
+
+	curr     ts.Datapoint
+	currUnit xtime.Unit
+	next     ts.Datapoint
+	nextUnit xtime.Unit
+	hasNext  bool
+	started  bool
+	err      error
+	closed   bool
+}
+
+// NewDownsampleIterator returns an iterator that aggregates the datapoints of
+// an iterator into steps aligned to start, each step yields a single datapoint
+// timestamped at the beginning of the step.
+func NewDownsampleIterator(
+	iter Iterator,
+	start time.Time,
+	step time.Duration,
+	aggregation AggregationType,
+) Iterator {
+	return &downsampleIterator{
+		iter:        iter,
+		start:       start,
+		step:        step,
+		aggregation: aggregation,
+	}
+}
+
+func (it *downsampleIterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.advance()
+	}
+	if !it.hasNext {
+		return false
+	}
+
+	var (
+		stepStart = it.stepStart(it.next.Timestamp)
+		value     float64
+		count     int
+	)
+	for it.hasNext && it.stepStart(it.next.Timestamp).Equal(stepStart) {
+		value = it.aggregate(value, it.next.Value, count)
+		count++
+		it.currUnit = it.nextUnit
+		it.advance()
+	}
+
+	switch it.aggregation {
+	case AggregationCount:
+		value = float64(count)
+	case AggregationAvg:
+		value = value / float64(count)
+	}
+
+	it.curr = ts.Datapoint{Timestamp: stepStart, Value: value}
+	if !isAlignedToUnit(stepStart, it.currUnit) {
+		it.currUnit = xtime.Nanosecond
+	}
+	return true
+}
+
+func (it *downsampleIterator) advance() {
+	if !it.iter.Next() {
+		it.hasNext = false
+		it.err = it.iter.Err()
+		return
+	}
+	it.next, it.nextUnit, _ = it.iter.Current()
+	it.hasNext = true
+}
+
+func (it *downsampleIterator) stepStart(t time.Time) time.Time {
+	if t.Before(it.start) {
+		return t.Truncate(it.step)
+	}
+	return it.start.Add(t.Sub(it.start) / it.step * it.step)
+}
+
+func (it *downsampleIterator) aggregate(curr, value float64, count int) float64 {
+	if count == 0 {
+		return value
+	}
+	switch it.aggregation {
+	case AggregationMin:
+		return math.Min(curr, value)
+	case AggregationMax:
+		return math.Max(curr, value)
+	case AggregationSum, AggregationAvg:
+		return curr + value
+	}
+	// Last and count only depend on the latest value and number of values
+	return value
+}
+
+func (it *downsampleIterator) Current() (ts.Datapoint, xtime.Unit, ts.Annotation) {
+	return it.curr, it.currUnit, nil
+}
+
+func (it *downsampleIterator) Err() error {
+	return it.err
+}
+
+func (it *downsampleIterator) Close() {
+	if it.closed {
+		return
+	}
+	it.closed = true
+	it.iter.Close()
+}
+
+func isAlignedToUnit(t time.Time, unit xtime.Unit) bool {
+	d, err := unit.Value()
+	if err != nil {
+		return false
+	}
+	return t.UnixNano()%int64(d) == 0
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package encoding
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsampleIterator(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	values := []testValue{
+		{1.0, start.Add(10 * time.Second), xtime.Second, nil},
+		{4.0, start.Add(20 * time.Second), xtime.Second, []byte{1}},
+		{3.0, start.Add(50 * time.Second), xtime.Second, nil},
+		{7.0, start.Add(2*time.Minute + 10*time.Second), xtime.Second, nil},
+		{5.0, start.Add(2*time.Minute + 20*time.Second), xtime.Second, nil},
+	}
+
+	tests := []struct {
+		aggregation AggregationType
+		expected    []float64
+	}{
+		{AggregationMin, []float64{1, 5}},
+		{AggregationMax, []float64{4, 7}},
+		{AggregationSum, []float64{8, 12}},
+		{AggregationCount, []float64{3, 2}},
+		{AggregationAvg, []float64{8.0 / 3.0, 6}},
+		{AggregationLast, []float64{3, 5}},
+	}
+
+	for _, test := range tests {
+		iter := NewDownsampleIterator(newTestIterator(values), start, time.Minute, test.aggregation)
+
+		var (
+			actual     []float64
+			timestamps []time.Time
+		)
+		for iter.Next() {
+			dp, unit, annotation := iter.Current()
+			assert.Equal(t, xtime.Second, unit)
+			assert.Nil(t, annotation)
+			actual = append(actual, dp.Value)
+			timestamps = append(timestamps, dp.Timestamp)
+		}
+		require.NoError(t, iter.Err())
+		iter.Close()
+
+		assert.Equal(t, test.expected, actual, test.aggregation)
+		assert.Equal(t, []time.Time{start, start.Add(2 * time.Minute)}, timestamps)
+	}
+}
+
+func TestDownsampleIteratorUnalignedStart(t *testing.T) {
+	start := time.Now().Truncate(time.Hour).Add(500 * time.Millisecond)
+	values := []testValue{
+		{1.0, start.Add(time.Second), xtime.Second, nil},
+		{2.0, start.Add(2 * time.Second), xtime.Second, nil},
+	}
+
+	iter := NewDownsampleIterator(newTestIterator(values), start, time.Minute, AggregationSum)
+	require.True(t, iter.Next())
+	dp, unit, _ := iter.Current()
+	assert.Equal(t, start, dp.Timestamp)
+	assert.Equal(t, 3.0, dp.Value)
+	assert.Equal(t, xtime.Nanosecond, unit)
+	assert.False(t, iter.Next())
+}
+
+func TestDownsampleIteratorError(t *testing.T) {
+	start := time.Now().Truncate(time.Hour)
+	values := []testValue{
+		{1.0, start.Add(time.Second), xtime.Second, nil},
+	}
+
+	expectedErr := errors.New("an error")
+	inner := newTestIterator(values).(*testIterator)
+	inner.onNext = func(oldIdx, newIdx int) {
+		if newIdx == 1 {
+			inner.err = expectedErr
+		}
+	}
+
+	iter := NewDownsampleIterator(inner, start, time.Minute, AggregationMax)
+	assert.True(t, iter.Next())
+	assert.False(t, iter.Next())
+	assert.Equal(t, expectedErr, iter.Err())
+
+	iter.Close()
+	assert.True(t, inner.closed)
+}
+
+func TestParseAggregationType(t *testing.T) {
+	for aggregation := AggregationNone; aggregation <= AggregationLast; aggregation++ {
+		parsed, err := ParseAggregationType(aggregation.String())
+		require.NoError(t, err)
+		assert.Equal(t, aggregation, parsed)
+	}
+
+	_, err := ParseAggregationType("median")
+	assert.Error(t, err)
+}
@@ -103,6 +103,32 @@ type Options interface {
 	SegmentReaderPool() xio.SegmentReaderPool
 }
 
+// AggregationType is the aggregation applied to datapoints within a step when downsampling.
+type AggregationType int
+
+const (
+	// AggregationNone applies no aggregation
+	AggregationNone AggregationType = iota
+
+	// AggregationMin takes the minimum value
+	AggregationMin
+
+	// AggregationMax takes the maximum value
+	AggregationMax
+
+	// AggregationSum takes the sum of values
+	AggregationSum
+
+	// AggregationCount takes the number of values
+	AggregationCount
+
+	// AggregationAvg takes the average of values
+	AggregationAvg
+
+	// AggregationLast takes the last value
+	AggregationLast
+)
+
 // Iterator is the generic interface for iterating over encoded data.
 type Iterator interface {
 	// Next moves to the next item
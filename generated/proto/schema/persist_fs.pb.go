@@ -8,6 +8,7 @@ Package schema is a generated protocol buffer package.
 It is generated from these files:
 	persist_fs.proto
 	persist_fs_commitlog.proto
+	storage_namespace.proto
 
 It has these top-level messages:
 	IndexInfo
@@ -15,6 +16,10 @@ It has these top-level messages:
 	CommitLogInfo
 	CommitLog
 	CommitLogMetadata
+	NamespaceRegistry
+	NamespaceMetadata
+	NamespaceOptions
+	NamespaceRetentionOptions
 */
 package schema
 
@@ -0,0 +1,147 @@
+// Code generated by protoc-gen-go.
+// source: storage_namespace.proto
+// DO NOT EDIT!
+
+package schema
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+type NamespaceRegistry struct {
+	Namespaces []*NamespaceMetadata `protobuf:"bytes,1,rep,name=namespaces" json:"namespaces,omitempty"`
+}
+
+func (m *NamespaceRegistry) Reset()                    { *m = NamespaceRegistry{} }
+func (m *NamespaceRegistry) String() string            { return proto.CompactTextString(m) }
+func (*NamespaceRegistry) ProtoMessage()               {}
+func (*NamespaceRegistry) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
+
+func (m *NamespaceRegistry) GetNamespaces() []*NamespaceMetadata {
+	if m != nil {
+		return m.Namespaces
+	}
+	return nil
+}
+
+type NamespaceMetadata struct {
+	Id      string            `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
+	Options *NamespaceOptions `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
+}
+
+func (m *NamespaceMetadata) Reset()                    { *m = NamespaceMetadata{} }
+func (m *NamespaceMetadata) String() string            { return proto.CompactTextString(m) }
+func (*NamespaceMetadata) ProtoMessage()               {}
+func (*NamespaceMetadata) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+
+func (m *NamespaceMetadata) GetOptions() *NamespaceOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+type NamespaceOptions struct {
+	NeedsBootstrap      bool                       `protobuf:"varint,1,opt,name=needsBootstrap" json:"needsBootstrap,omitempty"`
+	NeedsFlush          bool                       `protobuf:"varint,2,opt,name=needsFlush" json:"needsFlush,omitempty"`
+	WritesToCommitLog   bool                       `protobuf:"varint,3,opt,name=writesToCommitLog" json:"writesToCommitLog,omitempty"`
+	NeedsFilesetCleanup bool                       `protobuf:"varint,4,opt,name=needsFilesetCleanup" json:"needsFilesetCleanup,omitempty"`
+	NeedsRepair         bool                       `protobuf:"varint,5,opt,name=needsRepair" json:"needsRepair,omitempty"`
+	RetentionOptions    *NamespaceRetentionOptions `protobuf:"bytes,6,opt,name=retentionOptions" json:"retentionOptions,omitempty"`
+	RollupOptions       *NamespaceRollupOptions    `protobuf:"bytes,7,opt,name=rollupOptions" json:"rollupOptions,omitempty"`
+	ColdWritesEnabled   bool                       `protobuf:"varint,8,opt,name=coldWritesEnabled" json:"coldWritesEnabled,omitempty"`
+	SyncCommitLogWrites bool                       `protobuf:"varint,9,opt,name=syncCommitLogWrites" json:"syncCommitLogWrites,omitempty"`
+}
+
+func (m *NamespaceOptions) Reset()                    { *m = NamespaceOptions{} }
+func (m *NamespaceOptions) String() string            { return proto.CompactTextString(m) }
+func (*NamespaceOptions) ProtoMessage()               {}
+func (*NamespaceOptions) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+
+func (m *NamespaceOptions) GetRetentionOptions() *NamespaceRetentionOptions {
+	if m != nil {
+		return m.RetentionOptions
+	}
+	return nil
+}
+
+func (m *NamespaceOptions) GetRollupOptions() *NamespaceRollupOptions {
+	if m != nil {
+		return m.RollupOptions
+	}
+	return nil
+}
+
+type NamespaceRetentionOptions struct {
+	RetentionPeriodNanos                     int64 `protobuf:"varint,1,opt,name=retentionPeriodNanos" json:"retentionPeriodNanos,omitempty"`
+	BlockSizeNanos                           int64 `protobuf:"varint,2,opt,name=blockSizeNanos" json:"blockSizeNanos,omitempty"`
+	BufferFutureNanos                        int64 `protobuf:"varint,3,opt,name=bufferFutureNanos" json:"bufferFutureNanos,omitempty"`
+	BufferPastNanos                          int64 `protobuf:"varint,4,opt,name=bufferPastNanos" json:"bufferPastNanos,omitempty"`
+	BlockDataExpiry                          bool  `protobuf:"varint,5,opt,name=blockDataExpiry" json:"blockDataExpiry,omitempty"`
+	BlockDataExpiryAfterNotAccessPeriodNanos int64 `protobuf:"varint,6,opt,name=blockDataExpiryAfterNotAccessPeriodNanos" json:"blockDataExpiryAfterNotAccessPeriodNanos,omitempty"`
+}
+
+func (m *NamespaceRetentionOptions) Reset()                    { *m = NamespaceRetentionOptions{} }
+func (m *NamespaceRetentionOptions) String() string            { return proto.CompactTextString(m) }
+func (*NamespaceRetentionOptions) ProtoMessage()               {}
+func (*NamespaceRetentionOptions) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{3} }
+
+type NamespaceRollupOptions struct {
+	SourceNamespace string `protobuf:"bytes,1,opt,name=sourceNamespace" json:"sourceNamespace,omitempty"`
+	ResolutionNanos int64  `protobuf:"varint,2,opt,name=resolutionNanos" json:"resolutionNanos,omitempty"`
+	Aggregation     string `protobuf:"bytes,3,opt,name=aggregation" json:"aggregation,omitempty"`
+}
+
+func (m *NamespaceRollupOptions) Reset()                    { *m = NamespaceRollupOptions{} }
+func (m *NamespaceRollupOptions) String() string            { return proto.CompactTextString(m) }
+func (*NamespaceRollupOptions) ProtoMessage()               {}
+func (*NamespaceRollupOptions) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{4} }
+
+func init() {
+	proto.RegisterType((*NamespaceRegistry)(nil), "schema.NamespaceRegistry")
+	proto.RegisterType((*NamespaceMetadata)(nil), "schema.NamespaceMetadata")
+	proto.RegisterType((*NamespaceOptions)(nil), "schema.NamespaceOptions")
+	proto.RegisterType((*NamespaceRetentionOptions)(nil), "schema.NamespaceRetentionOptions")
+	proto.RegisterType((*NamespaceRollupOptions)(nil), "schema.NamespaceRollupOptions")
+}
+
+var fileDescriptor2 = []byte{
+	// 498 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8d, 0x94, 0x4f, 0x6f, 0xd3, 0x30,
+	0x18, 0xc6, 0xd5, 0x66, 0x74, 0xad, 0x2b, 0xc6, 0x66, 0x10, 0x78, 0x97, 0x69, 0xe4, 0x80, 0x7a,
+	0x40, 0xd5, 0x54, 0x4e, 0x1c, 0xc7, 0xfe, 0x9c, 0x58, 0x99, 0x0c, 0xd2, 0x24, 0x2e, 0xc8, 0x4d,
+	0xde, 0x66, 0x16, 0x6e, 0xde, 0xc8, 0x76, 0xb4, 0x95, 0x0f, 0xc1, 0x77, 0xe3, 0xc0, 0xf7, 0x21,
+	0xb6, 0xdb, 0x28, 0x24, 0x45, 0xe2, 0x16, 0x3d, 0xcf, 0xef, 0x7d, 0xed, 0xf7, 0xb1, 0x1d, 0xf2,
+	0xca, 0x58, 0xd4, 0x22, 0x83, 0x6f, 0xb9, 0x58, 0x81, 0x29, 0x44, 0x02, 0xd3, 0x42, 0xa3, 0x45,
+	0x3a, 0x30, 0xc9, 0x3d, 0xac, 0x44, 0x3c, 0x27, 0x47, 0xf3, 0xad, 0xc5, 0x21, 0x93, 0xc6, 0xea,
+	0x35, 0x7d, 0x4f, 0x48, 0xcd, 0x1b, 0xd6, 0x3b, 0x8d, 0x26, 0xe3, 0xd9, 0xf1, 0x34, 0x54, 0x4c,
+	0x6b, 0xfc, 0x06, 0xac, 0x48, 0x85, 0x15, 0xbc, 0x01, 0xc7, 0x77, 0x8d, 0x7e, 0x5b, 0x80, 0x1e,
+	0x90, 0xbe, 0x4c, 0xab, 0x3e, 0xbd, 0xc9, 0x88, 0x57, 0x5f, 0x74, 0x46, 0xf6, 0xb1, 0xb0, 0x12,
+	0x73, 0xc3, 0xfa, 0x95, 0x38, 0x9e, 0xb1, 0x4e, 0xf3, 0x4f, 0xc1, 0xe7, 0x5b, 0x30, 0xfe, 0x15,
+	0x91, 0xc3, 0xb6, 0x4b, 0xdf, 0x90, 0x83, 0x1c, 0x20, 0x35, 0x1f, 0x10, 0x6d, 0xb5, 0x73, 0x51,
+	0xf8, 0x45, 0x86, 0xbc, 0xa5, 0xd2, 0x93, 0x6a, 0x20, 0xa7, 0x5c, 0xab, 0xd2, 0xdc, 0xfb, 0x35,
+	0x87, 0xbc, 0xa1, 0xd0, 0xb7, 0xe4, 0xe8, 0x41, 0x4b, 0x0b, 0xe6, 0x0b, 0x5e, 0xe0, 0x6a, 0x25,
+	0xed, 0x47, 0xcc, 0x58, 0xe4, 0xb1, 0xae, 0x41, 0xcf, 0xc8, 0xf3, 0x50, 0x2b, 0x15, 0x18, 0xb0,
+	0x17, 0x0a, 0x44, 0x5e, 0x16, 0x6c, 0xcf, 0xf3, 0xbb, 0x2c, 0x7a, 0x4a, 0xc6, 0x5e, 0xe6, 0x50,
+	0x08, 0xa9, 0xd9, 0x13, 0x4f, 0x36, 0x25, 0x7a, 0x43, 0x0e, 0x35, 0x58, 0xc8, 0xdd, 0x5c, 0x9b,
+	0xe9, 0xd8, 0xc0, 0x67, 0xf3, 0xba, 0x93, 0x0d, 0x6f, 0x81, 0xbc, 0x53, 0x4a, 0x2f, 0xc9, 0x53,
+	0x8d, 0x4a, 0x95, 0xc5, 0xb6, 0xd7, 0xbe, 0xef, 0x75, 0xd2, 0xed, 0xd5, 0xa4, 0xf8, 0xdf, 0x45,
+	0x2e, 0x96, 0x04, 0x55, 0x7a, 0xe7, 0x13, 0xb8, 0xca, 0xc5, 0x42, 0x41, 0xca, 0x86, 0x21, 0x96,
+	0x8e, 0xe1, 0x62, 0x31, 0xeb, 0x3c, 0xa9, 0x73, 0x0a, 0x2e, 0x1b, 0x85, 0x58, 0x76, 0x58, 0xf1,
+	0xef, 0x3e, 0x39, 0xfe, 0xe7, 0x54, 0xd5, 0x2d, 0x79, 0x51, 0xcf, 0x75, 0x0b, 0x5a, 0x62, 0x3a,
+	0x17, 0x39, 0x1a, 0x7f, 0xc4, 0x11, 0xdf, 0xe9, 0xb9, 0x0b, 0xb1, 0x50, 0x98, 0x7c, 0xff, 0x2c,
+	0x7f, 0x40, 0xa0, 0xfb, 0x9e, 0x6e, 0xa9, 0x6e, 0xb2, 0x45, 0xb9, 0x5c, 0x82, 0xbe, 0x2e, 0x6d,
+	0xa9, 0x37, 0x68, 0xe4, 0xd1, 0xae, 0x41, 0x27, 0xe4, 0x59, 0x10, 0x6f, 0x85, 0xb1, 0x81, 0xdd,
+	0xf3, 0x6c, 0x5b, 0xf6, 0xa4, 0x5b, 0xe9, 0xb2, 0xba, 0xf6, 0x57, 0x8f, 0x85, 0xd4, 0xeb, 0xcd,
+	0x61, 0xb7, 0x65, 0xfa, 0x95, 0x4c, 0x5a, 0xd2, 0xf9, 0xd2, 0x82, 0x9e, 0xa3, 0x3d, 0x4f, 0xaa,
+	0x87, 0x64, 0x9a, 0x13, 0x0f, 0xfc, 0x62, 0xff, 0xcd, 0xc7, 0x3f, 0x7b, 0xe4, 0xe5, 0xee, 0x13,
+	0x76, 0x1b, 0x34, 0x58, 0xea, 0x04, 0x6a, 0x7f, 0xf3, 0x2e, 0xdb, 0xb2, 0x23, 0x35, 0x18, 0x54,
+	0xa5, 0x2b, 0x6c, 0x66, 0xd9, 0x96, 0xdd, 0xed, 0x16, 0x59, 0xa6, 0x21, 0x13, 0x4e, 0xf3, 0x31,
+	0x8e, 0x78, 0x53, 0x5a, 0x0c, 0xfc, 0x4f, 0xe7, 0xdd, 0x1f, 0x60, 0xfc, 0x6d, 0x75, 0x8f, 0x04,
+	0x00, 0x00,
+}
@@ -32,6 +32,23 @@ enum ErrorType {
 	BAD_REQUEST
 }
 
+enum TagMatcherType {
+	EQUAL,
+	NOT_EQUAL,
+	REGEXP,
+	NOT_REGEXP
+}
+
+enum AggregationType {
+	NONE,
+	MIN,
+	MAX,
+	SUM,
+	COUNT,
+	AVG,
+	LAST
+}
+
 exception Error {
 	1: required ErrorType type = ErrorType.INTERNAL_ERROR
 	2: required string message
@@ -53,6 +70,11 @@ service Node {
 	void writeBatchRaw(1: WriteBatchRawRequest req) throws (1: WriteBatchRawErrors err)
 	void repair() throws (1: Error err)
 	TruncateResult truncate(1: TruncateRequest req) throws (1: Error err)
+	void delete(1: DeleteRequest req) throws (1: Error err)
+
+	// Tagged read/write endpoints
+	void writeTagged(1: WriteTaggedRequest req) throws (1: Error err)
+	FetchTaggedResult fetchTagged(1: FetchTaggedRequest req) throws (1: Error err)
 
 	// Management endpoints
 	NodeHealthResult health() throws (1: Error err)
@@ -60,6 +82,9 @@ service Node {
 	NodePersistRateLimitResult setPersistRateLimit(1: NodeSetPersistRateLimitRequest req) throws (1: Error err)
 	NodeWriteNewSeriesAsyncResult getWriteNewSeriesAsync() throws (1: Error err)
 	NodeWriteNewSeriesAsyncResult setWriteNewSeriesAsync(1: NodeSetWriteNewSeriesAsyncRequest req) throws (1: Error err)
+	NodeNamespacesResult getNamespaces() throws (1: Error err)
+	void addNamespace(1: NodeAddNamespaceRequest req) throws (1: Error err)
+	void removeNamespace(1: NodeRemoveNamespaceRequest req) throws (1: Error err)
 }
 
 struct FetchRequest {
@@ -69,6 +94,8 @@ struct FetchRequest {
 	4: required string id
 	5: optional TimeType rangeType = TimeType.UNIX_SECONDS
 	6: optional TimeType resultTimeType = TimeType.UNIX_SECONDS
+	7: optional i64 step = 0
+	8: optional AggregationType aggregation = AggregationType.NONE
 }
 
 struct FetchResult {
@@ -94,6 +121,8 @@ struct FetchBatchRawRequest {
 	3: required binary nameSpace
 	4: required list<binary> ids
 	5: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+	6: optional i64 step = 0
+	7: optional AggregationType aggregation = AggregationType.NONE
 }
 
 struct FetchBatchRawResult {
@@ -196,6 +225,53 @@ struct TruncateResult {
 	1: required i64 numSeries
 }
 
+struct DeleteRequest {
+	1: required string nameSpace
+	2: required string id
+	3: required i64 rangeStart
+	4: required i64 rangeEnd
+	5: optional TimeType rangeType = TimeType.UNIX_SECONDS
+}
+
+struct Tag {
+	1: required string name
+	2: required string value
+}
+
+struct WriteTaggedRequest {
+	1: required string nameSpace
+	2: required string id
+	3: required list<Tag> tags
+	4: required Datapoint datapoint
+}
+
+struct TagMatcher {
+	1: required TagMatcherType type
+	2: required string name
+	3: required string value
+}
+
+struct FetchTaggedRequest {
+	1: required binary nameSpace
+	2: required list<TagMatcher> matchers
+	3: required i64 rangeStart
+	4: required i64 rangeEnd
+	5: required bool fetchData
+	6: required i64 limit
+	7: optional TimeType rangeTimeType = TimeType.UNIX_SECONDS
+}
+
+struct FetchTaggedResult {
+	1: required list<FetchTaggedIDResult> elements
+	2: required bool exhaustive
+}
+
+struct FetchTaggedIDResult {
+	1: required binary id
+	2: required list<Tag> tags
+	3: required list<Segments> segments
+}
+
 struct NodeHealthResult {
 	1: required bool ok
 	2: required string status
@@ -222,11 +298,46 @@ struct NodeSetWriteNewSeriesAsyncRequest {
 	1: required bool writeNewSeriesAsync
 }
 
+struct NodeNamespacesResult {
+	1: required list<NodeNamespace> namespaces
+}
+
+struct NodeNamespaceRollup {
+	1: required string sourceNamespace
+	2: required i64 resolutionNanos
+	3: required AggregationType aggregation
+}
+
+struct NodeNamespace {
+	1: required string name
+	2: required i64 retentionPeriodNanos
+	3: required i64 blockSizeNanos
+	4: required i64 bufferFutureNanos
+	5: required i64 bufferPastNanos
+	6: required bool needsBootstrap
+	7: required bool needsFlush
+	8: required bool writesToCommitLog
+	9: required bool needsFilesetCleanup
+	10: required bool needsRepair
+	11: optional bool coldWritesEnabled
+	12: optional bool syncCommitLogWrites
+	13: optional NodeNamespaceRollup rollup
+}
+
+struct NodeAddNamespaceRequest {
+	1: required NodeNamespace namespace
+}
+
+struct NodeRemoveNamespaceRequest {
+	1: required string name
+}
+
 service Cluster {
 	HealthResult health() throws (1: Error err)
 	void write(1: WriteRequest req) throws (1: Error err)
 	FetchResult fetch(1: FetchRequest req) throws (1: Error err)
 	TruncateResult truncate(1: TruncateRequest req) throws (1: Error err)
+	FetchTaggedResult fetchTagged(1: FetchTaggedRequest req) throws (1: Error err)
 }
 
 struct HealthResult {
@@ -163,6 +163,157 @@ func (p *ErrorType) Value() (driver.Value, error) {
 	return int64(*p), nil
 }
 
+type TagMatcherType int64
+
+const (
+	TagMatcherType_EQUAL      TagMatcherType = 0
+	TagMatcherType_NOT_EQUAL  TagMatcherType = 1
+	TagMatcherType_REGEXP     TagMatcherType = 2
+	TagMatcherType_NOT_REGEXP TagMatcherType = 3
+)
+
+func (p TagMatcherType) String() string {
+	switch p {
+	case TagMatcherType_EQUAL:
+		return "EQUAL"
+	case TagMatcherType_NOT_EQUAL:
+		return "NOT_EQUAL"
+	case TagMatcherType_REGEXP:
+		return "REGEXP"
+	case TagMatcherType_NOT_REGEXP:
+		return "NOT_REGEXP"
+	}
+	return "<UNSET>"
+}
+
+func TagMatcherTypeFromString(s string) (TagMatcherType, error) {
+	switch s {
+	case "EQUAL":
+		return TagMatcherType_EQUAL, nil
+	case "NOT_EQUAL":
+		return TagMatcherType_NOT_EQUAL, nil
+	case "REGEXP":
+		return TagMatcherType_REGEXP, nil
+	case "NOT_REGEXP":
+		return TagMatcherType_NOT_REGEXP, nil
+	}
+	return TagMatcherType(0), fmt.Errorf("not a valid TagMatcherType string")
+}
+
+func TagMatcherTypePtr(v TagMatcherType) *TagMatcherType { return &v }
+
+func (p TagMatcherType) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *TagMatcherType) UnmarshalText(text []byte) error {
+	q, err := TagMatcherTypeFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+func (p *TagMatcherType) Scan(value interface{}) error {
+	v, ok := value.(int64)
+	if !ok {
+		return errors.New("Scan value is not int64")
+	}
+	*p = TagMatcherType(v)
+	return nil
+}
+
+func (p *TagMatcherType) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return int64(*p), nil
+}
+
+type AggregationType int64
+
+const (
+	AggregationType_NONE  AggregationType = 0
+	AggregationType_MIN   AggregationType = 1
+	AggregationType_MAX   AggregationType = 2
+	AggregationType_SUM   AggregationType = 3
+	AggregationType_COUNT AggregationType = 4
+	AggregationType_AVG   AggregationType = 5
+	AggregationType_LAST  AggregationType = 6
+)
+
+func (p AggregationType) String() string {
+	switch p {
+	case AggregationType_NONE:
+		return "NONE"
+	case AggregationType_MIN:
+		return "MIN"
+	case AggregationType_MAX:
+		return "MAX"
+	case AggregationType_SUM:
+		return "SUM"
+	case AggregationType_COUNT:
+		return "COUNT"
+	case AggregationType_AVG:
+		return "AVG"
+	case AggregationType_LAST:
+		return "LAST"
+	}
+	return "<UNSET>"
+}
+
+func AggregationTypeFromString(s string) (AggregationType, error) {
+	switch s {
+	case "NONE":
+		return AggregationType_NONE, nil
+	case "MIN":
+		return AggregationType_MIN, nil
+	case "MAX":
+		return AggregationType_MAX, nil
+	case "SUM":
+		return AggregationType_SUM, nil
+	case "COUNT":
+		return AggregationType_COUNT, nil
+	case "AVG":
+		return AggregationType_AVG, nil
+	case "LAST":
+		return AggregationType_LAST, nil
+	}
+	return AggregationType(0), fmt.Errorf("not a valid AggregationType string")
+}
+
+func AggregationTypePtr(v AggregationType) *AggregationType { return &v }
+
+func (p AggregationType) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *AggregationType) UnmarshalText(text []byte) error {
+	q, err := AggregationTypeFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*p = q
+	return nil
+}
+
+func (p *AggregationType) Scan(value interface{}) error {
+	v, ok := value.(int64)
+	if !ok {
+		return errors.New("Scan value is not int64")
+	}
+	*p = AggregationType(v)
+	return nil
+}
+
+func (p *AggregationType) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return int64(*p), nil
+}
+
 // Attributes:
 //  - Type
 //  - Message
@@ -438,13 +589,17 @@ func (p *WriteBatchRawErrors) Error() string {
 //  - ID
 //  - RangeType
 //  - ResultTimeType
+//  - Step
+//  - Aggregation
 type FetchRequest struct {
-	RangeStart     int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd       int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
-	NameSpace      string   `thrift:"nameSpace,3,required" db:"nameSpace" json:"nameSpace"`
-	ID             string   `thrift:"id,4,required" db:"id" json:"id"`
-	RangeType      TimeType `thrift:"rangeType,5" db:"rangeType" json:"rangeType,omitempty"`
-	ResultTimeType TimeType `thrift:"resultTimeType,6" db:"resultTimeType" json:"resultTimeType,omitempty"`
+	RangeStart     int64           `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd       int64           `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
+	NameSpace      string          `thrift:"nameSpace,3,required" db:"nameSpace" json:"nameSpace"`
+	ID             string          `thrift:"id,4,required" db:"id" json:"id"`
+	RangeType      TimeType        `thrift:"rangeType,5" db:"rangeType" json:"rangeType,omitempty"`
+	ResultTimeType TimeType        `thrift:"resultTimeType,6" db:"resultTimeType" json:"resultTimeType,omitempty"`
+	Step           int64           `thrift:"step,7" db:"step" json:"step,omitempty"`
+	Aggregation    AggregationType `thrift:"aggregation,8" db:"aggregation" json:"aggregation,omitempty"`
 }
 
 func NewFetchRequest() *FetchRequest {
@@ -452,6 +607,10 @@ func NewFetchRequest() *FetchRequest {
 		RangeType: 0,
 
 		ResultTimeType: 0,
+
+		Step: 0,
+
+		Aggregation: 0,
 	}
 }
 
@@ -482,6 +641,18 @@ var FetchRequest_ResultTimeType_DEFAULT TimeType = 0
 func (p *FetchRequest) GetResultTimeType() TimeType {
 	return p.ResultTimeType
 }
+
+var FetchRequest_Step_DEFAULT int64 = 0
+
+func (p *FetchRequest) GetStep() int64 {
+	return p.Step
+}
+
+var FetchRequest_Aggregation_DEFAULT AggregationType = 0
+
+func (p *FetchRequest) GetAggregation() AggregationType {
+	return p.Aggregation
+}
 func (p *FetchRequest) IsSetRangeType() bool {
 	return p.RangeType != FetchRequest_RangeType_DEFAULT
 }
@@ -490,6 +661,14 @@ func (p *FetchRequest) IsSetResultTimeType() bool {
 	return p.ResultTimeType != FetchRequest_ResultTimeType_DEFAULT
 }
 
+func (p *FetchRequest) IsSetStep() bool {
+	return p.Step != FetchRequest_Step_DEFAULT
+}
+
+func (p *FetchRequest) IsSetAggregation() bool {
+	return p.Aggregation != FetchRequest_Aggregation_DEFAULT
+}
+
 func (p *FetchRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -537,6 +716,14 @@ func (p *FetchRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField6(iprot); err != nil {
 				return err
 			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -620,6 +807,25 @@ func (p *FetchRequest) ReadField6(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.Step = v
+	}
+	return nil
+}
+
+func (p *FetchRequest) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		temp := AggregationType(v)
+		p.Aggregation = temp
+	}
+	return nil
+}
+
 func (p *FetchRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -643,6 +849,12 @@ func (p *FetchRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField6(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -735,6 +947,36 @@ func (p *FetchRequest) writeField6(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetStep() {
+		if err := oprot.WriteFieldBegin("step", thrift.I64, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:step: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(p.Step)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.step (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:step: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchRequest) writeField8(oprot thrift.TProtocol) (err error) {
+	if p.IsSetAggregation() {
+		if err := oprot.WriteFieldBegin("aggregation", thrift.I32, 8); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:aggregation: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.Aggregation)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.aggregation (8) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 8:aggregation: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -1285,17 +1527,25 @@ func (p *WriteRequest) String() string {
 //  - NameSpace
 //  - Ids
 //  - RangeTimeType
+//  - Step
+//  - Aggregation
 type FetchBatchRawRequest struct {
-	RangeStart    int64    `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
-	RangeEnd      int64    `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
-	NameSpace     []byte   `thrift:"nameSpace,3,required" db:"nameSpace" json:"nameSpace"`
-	Ids           [][]byte `thrift:"ids,4,required" db:"ids" json:"ids"`
-	RangeTimeType TimeType `thrift:"rangeTimeType,5" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	RangeStart    int64           `thrift:"rangeStart,1,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd      int64           `thrift:"rangeEnd,2,required" db:"rangeEnd" json:"rangeEnd"`
+	NameSpace     []byte          `thrift:"nameSpace,3,required" db:"nameSpace" json:"nameSpace"`
+	Ids           [][]byte        `thrift:"ids,4,required" db:"ids" json:"ids"`
+	RangeTimeType TimeType        `thrift:"rangeTimeType,5" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+	Step          int64           `thrift:"step,6" db:"step" json:"step,omitempty"`
+	Aggregation   AggregationType `thrift:"aggregation,7" db:"aggregation" json:"aggregation,omitempty"`
 }
 
 func NewFetchBatchRawRequest() *FetchBatchRawRequest {
 	return &FetchBatchRawRequest{
 		RangeTimeType: 0,
+
+		Step: 0,
+
+		Aggregation: 0,
 	}
 }
 
@@ -1320,10 +1570,30 @@ var FetchBatchRawRequest_RangeTimeType_DEFAULT TimeType = 0
 func (p *FetchBatchRawRequest) GetRangeTimeType() TimeType {
 	return p.RangeTimeType
 }
+
+var FetchBatchRawRequest_Step_DEFAULT int64 = 0
+
+func (p *FetchBatchRawRequest) GetStep() int64 {
+	return p.Step
+}
+
+var FetchBatchRawRequest_Aggregation_DEFAULT AggregationType = 0
+
+func (p *FetchBatchRawRequest) GetAggregation() AggregationType {
+	return p.Aggregation
+}
 func (p *FetchBatchRawRequest) IsSetRangeTimeType() bool {
 	return p.RangeTimeType != FetchBatchRawRequest_RangeTimeType_DEFAULT
 }
 
+func (p *FetchBatchRawRequest) IsSetStep() bool {
+	return p.Step != FetchBatchRawRequest_Step_DEFAULT
+}
+
+func (p *FetchBatchRawRequest) IsSetAggregation() bool {
+	return p.Aggregation != FetchBatchRawRequest_Aggregation_DEFAULT
+}
+
 func (p *FetchBatchRawRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -1367,6 +1637,14 @@ func (p *FetchBatchRawRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField5(iprot); err != nil {
 				return err
 			}
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -1453,6 +1731,25 @@ func (p *FetchBatchRawRequest) ReadField5(iprot thrift.TProtocol) error {
 	return nil
 }
 
+func (p *FetchBatchRawRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.Step = v
+	}
+	return nil
+}
+
+func (p *FetchBatchRawRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		temp := AggregationType(v)
+		p.Aggregation = temp
+	}
+	return nil
+}
+
 func (p *FetchBatchRawRequest) Write(oprot thrift.TProtocol) error {
 	if err := oprot.WriteStructBegin("FetchBatchRawRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
@@ -1473,6 +1770,12 @@ func (p *FetchBatchRawRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField5(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -1558,6 +1861,36 @@ func (p *FetchBatchRawRequest) writeField5(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
+func (p *FetchBatchRawRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if p.IsSetStep() {
+		if err := oprot.WriteFieldBegin("step", thrift.I64, 6); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:step: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(p.Step)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.step (6) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 6:step: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchBatchRawRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetAggregation() {
+		if err := oprot.WriteFieldBegin("aggregation", thrift.I32, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:aggregation: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.Aggregation)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.aggregation (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:aggregation: ", p), err)
+		}
+	}
+	return err
+}
+
 func (p *FetchBatchRawRequest) String() string {
 	if p == nil {
 		return "<nil>"
@@ -4775,38 +5108,59 @@ func (p *TruncateResult_) String() string {
 }
 
 // Attributes:
-//  - Ok
-//  - Status
-//  - Bootstrapped
-type NodeHealthResult_ struct {
-	Ok           bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
-	Status       string `thrift:"status,2,required" db:"status" json:"status"`
-	Bootstrapped bool   `thrift:"bootstrapped,3,required" db:"bootstrapped" json:"bootstrapped"`
+//  - NameSpace
+//  - ID
+//  - RangeStart
+//  - RangeEnd
+//  - RangeType
+type DeleteRequest struct {
+	NameSpace  string   `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	ID         string   `thrift:"id,2,required" db:"id" json:"id"`
+	RangeStart int64    `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd   int64    `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	RangeType  TimeType `thrift:"rangeType,5" db:"rangeType" json:"rangeType,omitempty"`
 }
 
-func NewNodeHealthResult_() *NodeHealthResult_ {
-	return &NodeHealthResult_{}
+func NewDeleteRequest() *DeleteRequest {
+	return &DeleteRequest{
+		RangeType: 0,
+	}
 }
 
-func (p *NodeHealthResult_) GetOk() bool {
-	return p.Ok
+func (p *DeleteRequest) GetNameSpace() string {
+	return p.NameSpace
 }
 
-func (p *NodeHealthResult_) GetStatus() string {
-	return p.Status
+func (p *DeleteRequest) GetID() string {
+	return p.ID
 }
 
-func (p *NodeHealthResult_) GetBootstrapped() bool {
-	return p.Bootstrapped
+func (p *DeleteRequest) GetRangeStart() int64 {
+	return p.RangeStart
 }
-func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
+
+func (p *DeleteRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+var DeleteRequest_RangeType_DEFAULT TimeType = 0
+
+func (p *DeleteRequest) GetRangeType() TimeType {
+	return p.RangeType
+}
+func (p *DeleteRequest) IsSetRangeType() bool {
+	return p.RangeType != DeleteRequest_RangeType_DEFAULT
+}
+
+func (p *DeleteRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetOk bool = false
-	var issetStatus bool = false
-	var issetBootstrapped bool = false
+	var issetNameSpace bool = false
+	var issetID bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -4821,17 +5175,26 @@ func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetOk = true
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetStatus = true
+			issetID = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
-			issetBootstrapped = true
+			issetRangeStart = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -4844,47 +5207,69 @@ func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetOk {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
 	}
-	if !issetStatus {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	if !issetBootstrapped {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Bootstrapped is not set"))
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *DeleteRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.Ok = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField2(iprot thrift.TProtocol) error {
+func (p *DeleteRequest) ReadField2(iprot thrift.TProtocol) error {
 	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Status = v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *DeleteRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
 		return thrift.PrependError("error reading field 3: ", err)
 	} else {
-		p.Bootstrapped = v
+		p.RangeStart = v
 	}
 	return nil
 }
 
-func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeHealthResult"); err != nil {
+func (p *DeleteRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.RangeEnd = v
+	}
+	return nil
+}
+
+func (p *DeleteRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		temp := TimeType(v)
+		p.RangeType = temp
+	}
+	return nil
+}
+
+func (p *DeleteRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("DeleteRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -4897,6 +5282,12 @@ func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -4907,85 +5298,106 @@ func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
+func (p *DeleteRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.NameSpace)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
+func (p *DeleteRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:id: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Status)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.ID)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:id: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("bootstrapped", thrift.BOOL, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:bootstrapped: ", p), err)
+func (p *DeleteRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.Bootstrapped)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.bootstrapped (3) field write error: ", p), err)
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:bootstrapped: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeHealthResult_) String() string {
+func (p *DeleteRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
+}
+
+func (p *DeleteRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeType() {
+		if err := oprot.WriteFieldBegin("rangeType", thrift.I32, 5); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:rangeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.RangeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeType (5) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 5:rangeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *DeleteRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeHealthResult_(%+v)", *p)
+	return fmt.Sprintf("DeleteRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
-type NodePersistRateLimitResult_ struct {
-	LimitEnabled    bool    `thrift:"limitEnabled,1,required" db:"limitEnabled" json:"limitEnabled"`
-	LimitMbps       float64 `thrift:"limitMbps,2,required" db:"limitMbps" json:"limitMbps"`
-	LimitCheckEvery int64   `thrift:"limitCheckEvery,3,required" db:"limitCheckEvery" json:"limitCheckEvery"`
-}
-
-func NewNodePersistRateLimitResult_() *NodePersistRateLimitResult_ {
-	return &NodePersistRateLimitResult_{}
+//  - Name
+//  - Value
+type Tag struct {
+	Name  string `thrift:"name,1,required" db:"name" json:"name"`
+	Value string `thrift:"value,2,required" db:"value" json:"value"`
 }
 
-func (p *NodePersistRateLimitResult_) GetLimitEnabled() bool {
-	return p.LimitEnabled
+func NewTag() *Tag {
+	return &Tag{}
 }
 
-func (p *NodePersistRateLimitResult_) GetLimitMbps() float64 {
-	return p.LimitMbps
+func (p *Tag) GetName() string {
+	return p.Name
 }
 
-func (p *NodePersistRateLimitResult_) GetLimitCheckEvery() int64 {
-	return p.LimitCheckEvery
+func (p *Tag) GetValue() string {
+	return p.Value
 }
-func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
+func (p *Tag) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetLimitEnabled bool = false
-	var issetLimitMbps bool = false
-	var issetLimitCheckEvery bool = false
+	var issetName bool = false
+	var issetValue bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5000,17 +5412,12 @@ func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetLimitEnabled = true
+			issetName = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetLimitMbps = true
-		case 3:
-			if err := p.ReadField3(iprot); err != nil {
-				return err
-			}
-			issetLimitCheckEvery = true
+			issetValue = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5023,47 +5430,35 @@ func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetLimitEnabled {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitEnabled is not set"))
-	}
-	if !issetLimitMbps {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitMbps is not set"))
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
 	}
-	if !issetLimitCheckEvery {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitCheckEvery is not set"))
+	if !issetValue {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Value is not set"))
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *Tag) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.LimitEnabled = v
+		p.Name = v
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadDouble(); err != nil {
+func (p *Tag) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.LimitMbps = v
-	}
-	return nil
-}
-
-func (p *NodePersistRateLimitResult_) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
-	} else {
-		p.LimitCheckEvery = v
+		p.Value = v
 	}
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodePersistRateLimitResult"); err != nil {
+func (p *Tag) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("Tag"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5073,9 +5468,6 @@ func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField2(oprot); err != nil {
 			return err
 		}
-		if err := p.writeField3(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5086,109 +5478,89 @@ func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodePersistRateLimitResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
-	}
-	if err := oprot.WriteBool(bool(p.LimitEnabled)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
-	}
-	return err
-}
-
-func (p *NodePersistRateLimitResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
+func (p *Tag) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
 	}
-	if err := oprot.WriteDouble(float64(p.LimitMbps)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
 	}
 	return err
 }
 
-func (p *NodePersistRateLimitResult_) writeField3(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
+func (p *Tag) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("value", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:value: ", p), err)
 	}
-	if err := oprot.WriteI64(int64(p.LimitCheckEvery)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
+	if err := oprot.WriteString(string(p.Value)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.value (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:value: ", p), err)
 	}
 	return err
 }
 
-func (p *NodePersistRateLimitResult_) String() string {
+func (p *Tag) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodePersistRateLimitResult_(%+v)", *p)
+	return fmt.Sprintf("Tag(%+v)", *p)
 }
 
 // Attributes:
-//  - LimitEnabled
-//  - LimitMbps
-//  - LimitCheckEvery
-type NodeSetPersistRateLimitRequest struct {
-	LimitEnabled    *bool    `thrift:"limitEnabled,1" db:"limitEnabled" json:"limitEnabled,omitempty"`
-	LimitMbps       *float64 `thrift:"limitMbps,2" db:"limitMbps" json:"limitMbps,omitempty"`
-	LimitCheckEvery *int64   `thrift:"limitCheckEvery,3" db:"limitCheckEvery" json:"limitCheckEvery,omitempty"`
+//  - NameSpace
+//  - ID
+//  - Tags
+//  - Datapoint
+type WriteTaggedRequest struct {
+	NameSpace string     `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	ID        string     `thrift:"id,2,required" db:"id" json:"id"`
+	Tags      []*Tag     `thrift:"tags,3,required" db:"tags" json:"tags"`
+	Datapoint *Datapoint `thrift:"datapoint,4,required" db:"datapoint" json:"datapoint"`
 }
 
-func NewNodeSetPersistRateLimitRequest() *NodeSetPersistRateLimitRequest {
-	return &NodeSetPersistRateLimitRequest{}
+func NewWriteTaggedRequest() *WriteTaggedRequest {
+	return &WriteTaggedRequest{}
 }
 
-var NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT bool
-
-func (p *NodeSetPersistRateLimitRequest) GetLimitEnabled() bool {
-	if !p.IsSetLimitEnabled() {
-		return NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT
-	}
-	return *p.LimitEnabled
+func (p *WriteTaggedRequest) GetNameSpace() string {
+	return p.NameSpace
 }
 
-var NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT float64
+func (p *WriteTaggedRequest) GetID() string {
+	return p.ID
+}
 
-func (p *NodeSetPersistRateLimitRequest) GetLimitMbps() float64 {
-	if !p.IsSetLimitMbps() {
-		return NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT
-	}
-	return *p.LimitMbps
+func (p *WriteTaggedRequest) GetTags() []*Tag {
+	return p.Tags
 }
 
-var NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT int64
+var WriteTaggedRequest_Datapoint_DEFAULT *Datapoint
 
-func (p *NodeSetPersistRateLimitRequest) GetLimitCheckEvery() int64 {
-	if !p.IsSetLimitCheckEvery() {
-		return NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT
+func (p *WriteTaggedRequest) GetDatapoint() *Datapoint {
+	if !p.IsSetDatapoint() {
+		return WriteTaggedRequest_Datapoint_DEFAULT
 	}
-	return *p.LimitCheckEvery
-}
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitEnabled() bool {
-	return p.LimitEnabled != nil
-}
-
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitMbps() bool {
-	return p.LimitMbps != nil
+	return p.Datapoint
 }
-
-func (p *NodeSetPersistRateLimitRequest) IsSetLimitCheckEvery() bool {
-	return p.LimitCheckEvery != nil
+func (p *WriteTaggedRequest) IsSetDatapoint() bool {
+	return p.Datapoint != nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
+func (p *WriteTaggedRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
+	var issetNameSpace bool = false
+	var issetID bool = false
+	var issetTags bool = false
+	var issetDatapoint bool = false
+
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
@@ -5202,14 +5574,22 @@ func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
+			issetNameSpace = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
+			issetID = true
 		case 3:
 			if err := p.ReadField3(iprot); err != nil {
 				return err
 			}
+			issetTags = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetDatapoint = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5222,38 +5602,69 @@ func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return nil
-}
-
-func (p *NodeSetPersistRateLimitRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
+	}
+	if !issetTags {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Tags is not set"))
+	}
+	if !issetDatapoint {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Datapoint is not set"))
+	}
+	return nil
+}
+
+func (p *WriteTaggedRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.LimitEnabled = &v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadDouble(); err != nil {
+func (p *WriteTaggedRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.LimitMbps = &v
+		p.ID = v
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) ReadField3(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadI64(); err != nil {
-		return thrift.PrependError("error reading field 3: ", err)
-	} else {
-		p.LimitCheckEvery = &v
+func (p *WriteTaggedRequest) ReadField3(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Tag, 0, size)
+	p.Tags = tSlice
+	for i := 0; i < size; i++ {
+		_elem13 := &Tag{}
+		if err := _elem13.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem13), err)
+		}
+		p.Tags = append(p.Tags, _elem13)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetPersistRateLimitRequest"); err != nil {
+func (p *WriteTaggedRequest) ReadField4(iprot thrift.TProtocol) error {
+	p.Datapoint = &Datapoint{}
+	if err := p.Datapoint.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Datapoint), err)
+	}
+	return nil
+}
+
+func (p *WriteTaggedRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("WriteTaggedRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5266,6 +5677,9 @@ func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
 		if err := p.writeField3(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5276,77 +5690,106 @@ func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitEnabled() {
-		if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
-		}
-		if err := oprot.WriteBool(bool(*p.LimitEnabled)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
-		}
+func (p *WriteTaggedRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.NameSpace)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField2(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitMbps() {
-		if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
-		}
-		if err := oprot.WriteDouble(float64(*p.LimitMbps)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
-		}
+func (p *WriteTaggedRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:id: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.ID)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:id: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) writeField3(oprot thrift.TProtocol) (err error) {
-	if p.IsSetLimitCheckEvery() {
-		if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
-		}
-		if err := oprot.WriteI64(int64(*p.LimitCheckEvery)); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+func (p *WriteTaggedRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("tags", thrift.LIST, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:tags: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Tags)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Tags {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
 		}
 	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:tags: ", p), err)
+	}
 	return err
 }
 
-func (p *NodeSetPersistRateLimitRequest) String() string {
+func (p *WriteTaggedRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("datapoint", thrift.STRUCT, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:datapoint: ", p), err)
+	}
+	if err := p.Datapoint.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Datapoint), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:datapoint: ", p), err)
+	}
+	return err
+}
+
+func (p *WriteTaggedRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetPersistRateLimitRequest(%+v)", *p)
+	return fmt.Sprintf("WriteTaggedRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesAsync
-type NodeWriteNewSeriesAsyncResult_ struct {
-	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
+//  - Type
+//  - Name
+//  - Value
+type TagMatcher struct {
+	Type  TagMatcherType `thrift:"type,1,required" db:"type" json:"type"`
+	Name  string         `thrift:"name,2,required" db:"name" json:"name"`
+	Value string         `thrift:"value,3,required" db:"value" json:"value"`
 }
 
-func NewNodeWriteNewSeriesAsyncResult_() *NodeWriteNewSeriesAsyncResult_ {
-	return &NodeWriteNewSeriesAsyncResult_{}
+func NewTagMatcher() *TagMatcher {
+	return &TagMatcher{}
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) GetWriteNewSeriesAsync() bool {
-	return p.WriteNewSeriesAsync
+func (p *TagMatcher) GetType() TagMatcherType {
+	return p.Type
 }
-func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
+
+func (p *TagMatcher) GetName() string {
+	return p.Name
+}
+
+func (p *TagMatcher) GetValue() string {
+	return p.Value
+}
+func (p *TagMatcher) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesAsync bool = false
+	var issetType bool = false
+	var issetName bool = false
+	var issetValue bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5361,7 +5804,17 @@ func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesAsync = true
+			issetType = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetName = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetValue = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5374,29 +5827,60 @@ func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesAsync {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
+	if !issetType {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Type is not set"))
+	}
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
+	}
+	if !issetValue {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Value is not set"))
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *TagMatcher) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesAsync = v
+		temp := TagMatcherType(v)
+		p.Type = temp
 	}
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeWriteNewSeriesAsyncResult"); err != nil {
+func (p *TagMatcher) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Name = v
+	}
+	return nil
+}
+
+func (p *TagMatcher) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.Value = v
+	}
+	return nil
+}
+
+func (p *TagMatcher) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("TagMatcher"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5407,45 +5891,120 @@ func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
+func (p *TagMatcher) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("type", thrift.I32, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:type: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
+	if err := oprot.WriteI32(int32(p.Type)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.type (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:type: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeWriteNewSeriesAsyncResult_) String() string {
+func (p *TagMatcher) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:name: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:name: ", p), err)
+	}
+	return err
+}
+
+func (p *TagMatcher) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("value", thrift.STRING, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:value: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Value)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.value (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:value: ", p), err)
+	}
+	return err
+}
+
+func (p *TagMatcher) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteNewSeriesAsyncResult_(%+v)", *p)
+	return fmt.Sprintf("TagMatcher(%+v)", *p)
 }
 
 // Attributes:
-//  - WriteNewSeriesAsync
-type NodeSetWriteNewSeriesAsyncRequest struct {
-	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
+//  - NameSpace
+//  - Matchers
+//  - RangeStart
+//  - RangeEnd
+//  - FetchData
+//  - Limit
+//  - RangeTimeType
+type FetchTaggedRequest struct {
+	NameSpace     []byte        `thrift:"nameSpace,1,required" db:"nameSpace" json:"nameSpace"`
+	Matchers      []*TagMatcher `thrift:"matchers,2,required" db:"matchers" json:"matchers"`
+	RangeStart    int64         `thrift:"rangeStart,3,required" db:"rangeStart" json:"rangeStart"`
+	RangeEnd      int64         `thrift:"rangeEnd,4,required" db:"rangeEnd" json:"rangeEnd"`
+	FetchData     bool          `thrift:"fetchData,5,required" db:"fetchData" json:"fetchData"`
+	Limit         int64         `thrift:"limit,6,required" db:"limit" json:"limit"`
+	RangeTimeType TimeType      `thrift:"rangeTimeType,7" db:"rangeTimeType" json:"rangeTimeType,omitempty"`
+}
+
+func NewFetchTaggedRequest() *FetchTaggedRequest {
+	return &FetchTaggedRequest{
+		RangeTimeType: 0,
+	}
 }
 
-func NewNodeSetWriteNewSeriesAsyncRequest() *NodeSetWriteNewSeriesAsyncRequest {
-	return &NodeSetWriteNewSeriesAsyncRequest{}
+func (p *FetchTaggedRequest) GetNameSpace() []byte {
+	return p.NameSpace
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) GetWriteNewSeriesAsync() bool {
-	return p.WriteNewSeriesAsync
+func (p *FetchTaggedRequest) GetMatchers() []*TagMatcher {
+	return p.Matchers
 }
-func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
+
+func (p *FetchTaggedRequest) GetRangeStart() int64 {
+	return p.RangeStart
+}
+
+func (p *FetchTaggedRequest) GetRangeEnd() int64 {
+	return p.RangeEnd
+}
+
+func (p *FetchTaggedRequest) GetFetchData() bool {
+	return p.FetchData
+}
+
+func (p *FetchTaggedRequest) GetLimit() int64 {
+	return p.Limit
+}
+
+var FetchTaggedRequest_RangeTimeType_DEFAULT TimeType = 0
+
+func (p *FetchTaggedRequest) GetRangeTimeType() TimeType {
+	return p.RangeTimeType
+}
+func (p *FetchTaggedRequest) IsSetRangeTimeType() bool {
+	return p.RangeTimeType != FetchTaggedRequest_RangeTimeType_DEFAULT
+}
+
+func (p *FetchTaggedRequest) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetWriteNewSeriesAsync bool = false
+	var issetNameSpace bool = false
+	var issetMatchers bool = false
+	var issetRangeStart bool = false
+	var issetRangeEnd bool = false
+	var issetFetchData bool = false
+	var issetLimit bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5460,42 +6019,170 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetWriteNewSeriesAsync = true
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
+			issetNameSpace = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-		}
-		if err := iprot.ReadFieldEnd(); err != nil {
-			return err
-		}
+			issetMatchers = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetRangeStart = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetRangeEnd = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+			issetFetchData = true
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+			issetLimit = true
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
 	}
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetWriteNewSeriesAsync {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
+	if !issetNameSpace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NameSpace is not set"))
+	}
+	if !issetMatchers {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Matchers is not set"))
+	}
+	if !issetRangeStart {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeStart is not set"))
+	}
+	if !issetRangeEnd {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RangeEnd is not set"))
+	}
+	if !issetFetchData {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field FetchData is not set"))
+	}
+	if !issetLimit {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Limit is not set"))
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
+func (p *FetchTaggedRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
 		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		p.WriteNewSeriesAsync = v
+		p.NameSpace = v
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesAsyncRequest"); err != nil {
+func (p *FetchTaggedRequest) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*TagMatcher, 0, size)
+	p.Matchers = tSlice
+	for i := 0; i < size; i++ {
+		_elem14 := &TagMatcher{}
+		if err := _elem14.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem14), err)
+		}
+		p.Matchers = append(p.Matchers, _elem14)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.RangeStart = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.RangeEnd = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.FetchData = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.Limit = v
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		temp := TimeType(v)
+		p.RangeTimeType = temp
+	}
+	return nil
+}
+
+func (p *FetchTaggedRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedRequest"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -5506,52 +6193,140 @@ func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
+func (p *FetchTaggedRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("nameSpace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:nameSpace: ", p), err)
 	}
-	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
+	if err := oprot.WriteBinary(p.NameSpace); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.nameSpace (1) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:nameSpace: ", p), err)
 	}
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesAsyncRequest) String() string {
+func (p *FetchTaggedRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("matchers", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:matchers: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Matchers)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Matchers {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:matchers: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeStart", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:rangeStart: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeStart)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeStart (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:rangeStart: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("rangeEnd", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:rangeEnd: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RangeEnd)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.rangeEnd (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:rangeEnd: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("fetchData", thrift.BOOL, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:fetchData: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.FetchData)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.fetchData (5) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:fetchData: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField6(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limit", thrift.I64, 6); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:limit: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.Limit)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limit (6) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 6:limit: ", p), err)
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) writeField7(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRangeTimeType() {
+		if err := oprot.WriteFieldBegin("rangeTimeType", thrift.I32, 7); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:rangeTimeType: ", p), err)
+		}
+		if err := oprot.WriteI32(int32(p.RangeTimeType)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.rangeTimeType (7) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 7:rangeTimeType: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *FetchTaggedRequest) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncRequest(%+v)", *p)
+	return fmt.Sprintf("FetchTaggedRequest(%+v)", *p)
 }
 
 // Attributes:
-//  - Ok
-//  - Status
-type HealthResult_ struct {
-	Ok     bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
-	Status string `thrift:"status,2,required" db:"status" json:"status"`
+//  - Elements
+//  - Exhaustive
+type FetchTaggedResult_ struct {
+	Elements   []*FetchTaggedIDResult_ `thrift:"elements,1,required" db:"elements" json:"elements"`
+	Exhaustive bool                    `thrift:"exhaustive,2,required" db:"exhaustive" json:"exhaustive"`
 }
 
-func NewHealthResult_() *HealthResult_ {
-	return &HealthResult_{}
+func NewFetchTaggedResult_() *FetchTaggedResult_ {
+	return &FetchTaggedResult_{}
 }
 
-func (p *HealthResult_) GetOk() bool {
-	return p.Ok
+func (p *FetchTaggedResult_) GetElements() []*FetchTaggedIDResult_ {
+	return p.Elements
 }
 
-func (p *HealthResult_) GetStatus() string {
-	return p.Status
+func (p *FetchTaggedResult_) GetExhaustive() bool {
+	return p.Exhaustive
 }
-func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
+func (p *FetchTaggedResult_) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	var issetOk bool = false
-	var issetStatus bool = false
+	var issetElements bool = false
+	var issetExhaustive bool = false
 
 	for {
 		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
@@ -5566,12 +6341,12 @@ func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
 			if err := p.ReadField1(iprot); err != nil {
 				return err
 			}
-			issetOk = true
+			issetElements = true
 		case 2:
 			if err := p.ReadField2(iprot); err != nil {
 				return err
 			}
-			issetStatus = true
+			issetExhaustive = true
 		default:
 			if err := iprot.Skip(fieldTypeId); err != nil {
 				return err
@@ -5584,35 +6359,46 @@ func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
 	if err := iprot.ReadStructEnd(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if !issetOk {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
+	if !issetElements {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Elements is not set"))
 	}
-	if !issetStatus {
-		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
+	if !issetExhaustive {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exhaustive is not set"))
 	}
 	return nil
 }
 
-func (p *HealthResult_) ReadField1(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadBool(); err != nil {
-		return thrift.PrependError("error reading field 1: ", err)
-	} else {
-		p.Ok = v
+func (p *FetchTaggedResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*FetchTaggedIDResult_, 0, size)
+	p.Elements = tSlice
+	for i := 0; i < size; i++ {
+		_elem15 := &FetchTaggedIDResult_{}
+		if err := _elem15.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem15), err)
+		}
+		p.Elements = append(p.Elements, _elem15)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
 	return nil
 }
 
-func (p *HealthResult_) ReadField2(iprot thrift.TProtocol) error {
-	if v, err := iprot.ReadString(); err != nil {
+func (p *FetchTaggedResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
 		return thrift.PrependError("error reading field 2: ", err)
 	} else {
-		p.Status = v
+		p.Exhaustive = v
 	}
 	return nil
 }
 
-func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("HealthResult"); err != nil {
+func (p *FetchTaggedResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedResult"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -5632,1894 +6418,6320 @@ func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *HealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
-	}
-	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
+func (p *FetchTaggedResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("elements", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:elements: ", p), err)
+	}
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Elements)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
+	}
+	for _, v := range p.Elements {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
+	}
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:elements: ", p), err)
 	}
 	return err
 }
 
-func (p *HealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
+func (p *FetchTaggedResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("exhaustive", thrift.BOOL, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:exhaustive: ", p), err)
 	}
-	if err := oprot.WriteString(string(p.Status)); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
+	if err := oprot.WriteBool(bool(p.Exhaustive)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.exhaustive (2) field write error: ", p), err)
 	}
 	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:exhaustive: ", p), err)
 	}
 	return err
 }
 
-func (p *HealthResult_) String() string {
+func (p *FetchTaggedResult_) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("HealthResult_(%+v)", *p)
+	return fmt.Sprintf("FetchTaggedResult_(%+v)", *p)
 }
 
-type Node interface {
-	// Parameters:
-	//  - Req
-	Fetch(req *FetchRequest) (r *FetchResult_, err error)
-	// Parameters:
-	//  - Req
-	Write(req *WriteRequest) (err error)
-	// Parameters:
-	//  - Req
-	FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error)
-	// Parameters:
-	//  - Req
-	FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error)
-	// Parameters:
-	//  - Req
-	WriteBatchRaw(req *WriteBatchRawRequest) (err error)
-	Repair() (err error)
-	// Parameters:
-	//  - Req
-	Truncate(req *TruncateRequest) (r *TruncateResult_, err error)
-	Health() (r *NodeHealthResult_, err error)
-	GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error)
-	// Parameters:
-	//  - Req
-	SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error)
-	GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error)
-	// Parameters:
-	//  - Req
-	SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error)
+// Attributes:
+//  - ID
+//  - Tags
+//  - Segments
+type FetchTaggedIDResult_ struct {
+	ID       []byte      `thrift:"id,1,required" db:"id" json:"id"`
+	Tags     []*Tag      `thrift:"tags,2,required" db:"tags" json:"tags"`
+	Segments []*Segments `thrift:"segments,3,required" db:"segments" json:"segments"`
 }
 
-type NodeClient struct {
-	Transport       thrift.TTransport
-	ProtocolFactory thrift.TProtocolFactory
-	InputProtocol   thrift.TProtocol
-	OutputProtocol  thrift.TProtocol
-	SeqId           int32
+func NewFetchTaggedIDResult_() *FetchTaggedIDResult_ {
+	return &FetchTaggedIDResult_{}
 }
 
-func NewNodeClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *NodeClient {
-	return &NodeClient{Transport: t,
-		ProtocolFactory: f,
-		InputProtocol:   f.GetProtocol(t),
-		OutputProtocol:  f.GetProtocol(t),
-		SeqId:           0,
-	}
+func (p *FetchTaggedIDResult_) GetID() []byte {
+	return p.ID
 }
 
-func NewNodeClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *NodeClient {
-	return &NodeClient{Transport: t,
-		ProtocolFactory: nil,
-		InputProtocol:   iprot,
-		OutputProtocol:  oprot,
-		SeqId:           0,
-	}
+func (p *FetchTaggedIDResult_) GetTags() []*Tag {
+	return p.Tags
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
-	if err = p.sendFetch(req); err != nil {
-		return
-	}
-	return p.recvFetch()
+func (p *FetchTaggedIDResult_) GetSegments() []*Segments {
+	return p.Segments
 }
+func (p *FetchTaggedIDResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
 
-func (p *NodeClient) sendFetch(req *FetchRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+	var issetID bool = false
+	var issetTags bool = false
+	var issetSegments bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetID = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetTags = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetSegments = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetch", thrift.CALL, p.SeqId); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	args := NodeFetchArgs{
-		Req: req,
+	if !issetID {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ID is not set"))
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if !issetTags {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Tags is not set"))
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if !issetSegments {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Segments is not set"))
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvFetch() (value *FetchResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *FetchTaggedIDResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBinary(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.ID = v
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) ReadField2(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
 	if err != nil {
-		return
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	if method != "fetch" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetch failed: wrong method name")
-		return
+	tSlice := make([]*Tag, 0, size)
+	p.Tags = tSlice
+	for i := 0; i < size; i++ {
+		_elem16 := &Tag{}
+		if err := _elem16.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem16), err)
+		}
+		p.Tags = append(p.Tags, _elem16)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetch failed: out of sequence response")
-		return
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error13 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error14 error
-		error14, err = error13.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) ReadField3(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
+	}
+	tSlice := make([]*Segments, 0, size)
+	p.Segments = tSlice
+	for i := 0; i < size; i++ {
+		_elem17 := &Segments{}
+		if err := _elem17.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem17), err)
 		}
-		err = error14
-		return
+		p.Segments = append(p.Segments, _elem17)
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetch failed: invalid message type")
-		return
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
 	}
-	result := NodeFetchResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	return nil
+}
+
+func (p *FetchTaggedIDResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("FetchTaggedIDResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	value = result.GetSuccess()
-	return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Write(req *WriteRequest) (err error) {
-	if err = p.sendWrite(req); err != nil {
-		return
+func (p *FetchTaggedIDResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("id", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:id: ", p), err)
 	}
-	return p.recvWrite()
+	if err := oprot.WriteBinary(p.ID); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.id (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:id: ", p), err)
+	}
+	return err
 }
 
-func (p *NodeClient) sendWrite(req *WriteRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *FetchTaggedIDResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("tags", thrift.LIST, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:tags: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("write", thrift.CALL, p.SeqId); err != nil {
-		return
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Tags)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	args := NodeWriteArgs{
-		Req: req,
+	for _, v := range p.Tags {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:tags: ", p), err)
 	}
-	return oprot.Flush()
+	return err
 }
 
-func (p *NodeClient) recvWrite() (err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "write" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "write failed: wrong method name")
-		return
+func (p *FetchTaggedIDResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("segments", thrift.LIST, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:segments: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "write failed: out of sequence response")
-		return
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Segments)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error15 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error16 error
-		error16, err = error15.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+	for _, v := range p.Segments {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
 		}
-		err = error16
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "write failed: invalid message type")
-		return
-	}
-	result := NodeWriteResult{}
-	if err = result.Read(iprot); err != nil {
-		return
-	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:segments: ", p), err)
 	}
-	return
+	return err
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error) {
-	if err = p.sendFetchBatchRaw(req); err != nil {
-		return
+func (p *FetchTaggedIDResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return p.recvFetchBatchRaw()
+	return fmt.Sprintf("FetchTaggedIDResult_(%+v)", *p)
 }
 
-func (p *NodeClient) sendFetchBatchRaw(req *FetchBatchRawRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBatchRaw", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeFetchBatchRawArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
-	}
-	return oprot.Flush()
+// Attributes:
+//  - Ok
+//  - Status
+//  - Bootstrapped
+type NodeHealthResult_ struct {
+	Ok           bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
+	Status       string `thrift:"status,2,required" db:"status" json:"status"`
+	Bootstrapped bool   `thrift:"bootstrapped,3,required" db:"bootstrapped" json:"bootstrapped"`
 }
 
-func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "fetchBatchRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBatchRaw failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBatchRaw failed: out of sequence response")
-		return
+func NewNodeHealthResult_() *NodeHealthResult_ {
+	return &NodeHealthResult_{}
+}
+
+func (p *NodeHealthResult_) GetOk() bool {
+	return p.Ok
+}
+
+func (p *NodeHealthResult_) GetStatus() string {
+	return p.Status
+}
+
+func (p *NodeHealthResult_) GetBootstrapped() bool {
+	return p.Bootstrapped
+}
+func (p *NodeHealthResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error17 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error18 error
-		error18, err = error17.Read(iprot)
+
+	var issetOk bool = false
+	var issetStatus bool = false
+	var issetBootstrapped bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetOk = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetStatus = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetBootstrapped = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error18
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBatchRaw failed: invalid message type")
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	result := NodeFetchBatchRawResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if !issetOk {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if !issetStatus {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetBootstrapped {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Bootstrapped is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error) {
-	if err = p.sendFetchBlocksRaw(req); err != nil {
-		return
+func (p *NodeHealthResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Ok = v
 	}
-	return p.recvFetchBlocksRaw()
+	return nil
 }
 
-func (p *NodeClient) sendFetchBlocksRaw(req *FetchBlocksRawRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodeHealthResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Status = v
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.CALL, p.SeqId); err != nil {
-		return
+	return nil
+}
+
+func (p *NodeHealthResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.Bootstrapped = v
 	}
-	args := NodeFetchBlocksRawArgs{
-		Req: req,
+	return nil
+}
+
+func (p *NodeHealthResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeHealthResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	return oprot.Flush()
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+func (p *NodeHealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
 	}
-	if method != "fetchBlocksRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksRaw failed: wrong method name")
-		return
+	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksRaw failed: out of sequence response")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error19 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error20 error
-		error20, err = error19.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
-		}
-		err = error20
-		return
+	return err
+}
+
+func (p *NodeHealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksRaw failed: invalid message type")
-		return
+	if err := oprot.WriteString(string(p.Status)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
 	}
-	result := NodeFetchBlocksRawResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	return err
+}
+
+func (p *NodeHealthResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("bootstrapped", thrift.BOOL, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:bootstrapped: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteBool(bool(p.Bootstrapped)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.bootstrapped (3) field write error: ", p), err)
 	}
-	value = result.GetSuccess()
-	return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:bootstrapped: ", p), err)
+	}
+	return err
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error) {
-	if err = p.sendFetchBlocksMetadataRaw(req); err != nil {
-		return
+func (p *NodeHealthResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return p.recvFetchBlocksMetadataRaw()
+	return fmt.Sprintf("NodeHealthResult_(%+v)", *p)
 }
 
-func (p *NodeClient) sendFetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeFetchBlocksMetadataRawArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
-	}
-	return oprot.Flush()
+// Attributes:
+//  - LimitEnabled
+//  - LimitMbps
+//  - LimitCheckEvery
+type NodePersistRateLimitResult_ struct {
+	LimitEnabled    bool    `thrift:"limitEnabled,1,required" db:"limitEnabled" json:"limitEnabled"`
+	LimitMbps       float64 `thrift:"limitMbps,2,required" db:"limitMbps" json:"limitMbps"`
+	LimitCheckEvery int64   `thrift:"limitCheckEvery,3,required" db:"limitCheckEvery" json:"limitCheckEvery"`
 }
 
-func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRawResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "fetchBlocksMetadataRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRaw failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRaw failed: out of sequence response")
-		return
+func NewNodePersistRateLimitResult_() *NodePersistRateLimitResult_ {
+	return &NodePersistRateLimitResult_{}
+}
+
+func (p *NodePersistRateLimitResult_) GetLimitEnabled() bool {
+	return p.LimitEnabled
+}
+
+func (p *NodePersistRateLimitResult_) GetLimitMbps() float64 {
+	return p.LimitMbps
+}
+
+func (p *NodePersistRateLimitResult_) GetLimitCheckEvery() int64 {
+	return p.LimitCheckEvery
+}
+func (p *NodePersistRateLimitResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error21 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error22 error
-		error22, err = error21.Read(iprot)
+
+	var issetLimitEnabled bool = false
+	var issetLimitMbps bool = false
+	var issetLimitCheckEvery bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetLimitEnabled = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetLimitMbps = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetLimitCheckEvery = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error22
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRaw failed: invalid message type")
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	result := NodeFetchBlocksMetadataRawResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if !issetLimitEnabled {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitEnabled is not set"))
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if !issetLimitMbps {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitMbps is not set"))
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetLimitCheckEvery {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field LimitCheckEvery is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) WriteBatchRaw(req *WriteBatchRawRequest) (err error) {
-	if err = p.sendWriteBatchRaw(req); err != nil {
-		return
+func (p *NodePersistRateLimitResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.LimitEnabled = v
 	}
-	return p.recvWriteBatchRaw()
+	return nil
 }
 
-func (p *NodeClient) sendWriteBatchRaw(req *WriteBatchRawRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("writeBatchRaw", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeWriteBatchRawArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+func (p *NodePersistRateLimitResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadDouble(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.LimitMbps = v
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvWriteBatchRaw() (err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+func (p *NodePersistRateLimitResult_) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.LimitCheckEvery = v
 	}
-	if method != "writeBatchRaw" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeBatchRaw failed: wrong method name")
-		return
+	return nil
+}
+
+func (p *NodePersistRateLimitResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodePersistRateLimitResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeBatchRaw failed: out of sequence response")
-		return
-	}
-	if mTypeId == thrift.EXCEPTION {
-		error23 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error24 error
-		error24, err = error23.Read(iprot)
-		if err != nil {
-			return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
 		}
-		err = error24
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeBatchRaw failed: invalid message type")
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	result := NodeWriteBatchRawResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	return nil
+}
+
+func (p *NodePersistRateLimitResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteBool(bool(p.LimitEnabled)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
 	}
-	return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
+	}
+	return err
 }
 
-func (p *NodeClient) Repair() (err error) {
-	if err = p.sendRepair(); err != nil {
-		return
+func (p *NodePersistRateLimitResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
 	}
-	return p.recvRepair()
+	if err := oprot.WriteDouble(float64(p.LimitMbps)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+	}
+	return err
 }
 
-func (p *NodeClient) sendRepair() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodePersistRateLimitResult_) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("repair", thrift.CALL, p.SeqId); err != nil {
-		return
+	if err := oprot.WriteI64(int64(p.LimitCheckEvery)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
 	}
-	args := NodeRepairArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	return err
+}
+
+func (p *NodePersistRateLimitResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return oprot.Flush()
+	return fmt.Sprintf("NodePersistRateLimitResult_(%+v)", *p)
 }
 
-func (p *NodeClient) recvRepair() (err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+// Attributes:
+//  - LimitEnabled
+//  - LimitMbps
+//  - LimitCheckEvery
+type NodeSetPersistRateLimitRequest struct {
+	LimitEnabled    *bool    `thrift:"limitEnabled,1" db:"limitEnabled" json:"limitEnabled,omitempty"`
+	LimitMbps       *float64 `thrift:"limitMbps,2" db:"limitMbps" json:"limitMbps,omitempty"`
+	LimitCheckEvery *int64   `thrift:"limitCheckEvery,3" db:"limitCheckEvery" json:"limitCheckEvery,omitempty"`
+}
+
+func NewNodeSetPersistRateLimitRequest() *NodeSetPersistRateLimitRequest {
+	return &NodeSetPersistRateLimitRequest{}
+}
+
+var NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT bool
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitEnabled() bool {
+	if !p.IsSetLimitEnabled() {
+		return NodeSetPersistRateLimitRequest_LimitEnabled_DEFAULT
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	return *p.LimitEnabled
+}
+
+var NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT float64
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitMbps() float64 {
+	if !p.IsSetLimitMbps() {
+		return NodeSetPersistRateLimitRequest_LimitMbps_DEFAULT
 	}
-	if method != "repair" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "repair failed: wrong method name")
-		return
+	return *p.LimitMbps
+}
+
+var NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT int64
+
+func (p *NodeSetPersistRateLimitRequest) GetLimitCheckEvery() int64 {
+	if !p.IsSetLimitCheckEvery() {
+		return NodeSetPersistRateLimitRequest_LimitCheckEvery_DEFAULT
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "repair failed: out of sequence response")
-		return
+	return *p.LimitCheckEvery
+}
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitEnabled() bool {
+	return p.LimitEnabled != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitMbps() bool {
+	return p.LimitMbps != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) IsSetLimitCheckEvery() bool {
+	return p.LimitCheckEvery != nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error25 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error26 error
-		error26, err = error25.Read(iprot)
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error26
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "repair failed: invalid message type")
-		return
-	}
-	result := NodeRepairResult{}
-	if err = result.Read(iprot); err != nil {
-		return
-	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
-	if err = p.sendTruncate(req); err != nil {
-		return
+func (p *NodeSetPersistRateLimitRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.LimitEnabled = &v
 	}
-	return p.recvTruncate()
+	return nil
 }
 
-func (p *NodeClient) sendTruncate(req *TruncateRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("truncate", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeTruncateArgs{
-		Req: req,
-	}
-	if err = args.Write(oprot); err != nil {
-		return
+func (p *NodeSetPersistRateLimitRequest) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadDouble(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.LimitMbps = &v
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	return nil
+}
+
+func (p *NodeSetPersistRateLimitRequest) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.LimitCheckEvery = &v
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
-	}
-	if method != "truncate" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "truncate failed: wrong method name")
-		return
-	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "truncate failed: out of sequence response")
-		return
+func (p *NodeSetPersistRateLimitRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetPersistRateLimitRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error27 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error28 error
-		error28, err = error27.Read(iprot)
-		if err != nil {
-			return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
 		}
-		err = error28
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "truncate failed: invalid message type")
-		return
-	}
-	result := NodeTruncateResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-func (p *NodeClient) Health() (r *NodeHealthResult_, err error) {
-	if err = p.sendHealth(); err != nil {
-		return
+func (p *NodeSetPersistRateLimitRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitEnabled() {
+		if err := oprot.WriteFieldBegin("limitEnabled", thrift.BOOL, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:limitEnabled: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.LimitEnabled)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitEnabled (1) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:limitEnabled: ", p), err)
+		}
 	}
-	return p.recvHealth()
+	return err
 }
 
-func (p *NodeClient) sendHealth() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("health", thrift.CALL, p.SeqId); err != nil {
-		return
-	}
-	args := NodeHealthArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
-	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+func (p *NodeSetPersistRateLimitRequest) writeField2(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitMbps() {
+		if err := oprot.WriteFieldBegin("limitMbps", thrift.DOUBLE, 2); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:limitMbps: ", p), err)
+		}
+		if err := oprot.WriteDouble(float64(*p.LimitMbps)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitMbps (2) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 2:limitMbps: ", p), err)
+		}
 	}
-	return oprot.Flush()
+	return err
 }
 
-func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
-	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+func (p *NodeSetPersistRateLimitRequest) writeField3(oprot thrift.TProtocol) (err error) {
+	if p.IsSetLimitCheckEvery() {
+		if err := oprot.WriteFieldBegin("limitCheckEvery", thrift.I64, 3); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:limitCheckEvery: ", p), err)
+		}
+		if err := oprot.WriteI64(int64(*p.LimitCheckEvery)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.limitCheckEvery (3) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 3:limitCheckEvery: ", p), err)
+		}
 	}
-	if method != "health" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "health failed: wrong method name")
-		return
+	return err
+}
+
+func (p *NodeSetPersistRateLimitRequest) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "health failed: out of sequence response")
-		return
+	return fmt.Sprintf("NodeSetPersistRateLimitRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - WriteNewSeriesAsync
+type NodeWriteNewSeriesAsyncResult_ struct {
+	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
+}
+
+func NewNodeWriteNewSeriesAsyncResult_() *NodeWriteNewSeriesAsyncResult_ {
+	return &NodeWriteNewSeriesAsyncResult_{}
+}
+
+func (p *NodeWriteNewSeriesAsyncResult_) GetWriteNewSeriesAsync() bool {
+	return p.WriteNewSeriesAsync
+}
+func (p *NodeWriteNewSeriesAsyncResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error29 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error30 error
-		error30, err = error29.Read(iprot)
+
+	var issetWriteNewSeriesAsync bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetWriteNewSeriesAsync = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error30
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "health failed: invalid message type")
-		return
-	}
-	result := NodeHealthResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetWriteNewSeriesAsync {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-func (p *NodeClient) GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error) {
-	if err = p.sendGetPersistRateLimit(); err != nil {
-		return
+func (p *NodeWriteNewSeriesAsyncResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesAsync = v
 	}
-	return p.recvGetPersistRateLimit()
+	return nil
 }
 
-func (p *NodeClient) sendGetPersistRateLimit() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodeWriteNewSeriesAsyncResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeWriteNewSeriesAsyncResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	args := NodeGetPersistRateLimitArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvGetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeWriteNewSeriesAsyncResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
 	}
-	if method != "getPersistRateLimit" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getPersistRateLimit failed: wrong method name")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getPersistRateLimit failed: out of sequence response")
-		return
+	return err
+}
+
+func (p *NodeWriteNewSeriesAsyncResult_) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error31 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error32 error
-		error32, err = error31.Read(iprot)
+	return fmt.Sprintf("NodeWriteNewSeriesAsyncResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - WriteNewSeriesAsync
+type NodeSetWriteNewSeriesAsyncRequest struct {
+	WriteNewSeriesAsync bool `thrift:"writeNewSeriesAsync,1,required" db:"writeNewSeriesAsync" json:"writeNewSeriesAsync"`
+}
+
+func NewNodeSetWriteNewSeriesAsyncRequest() *NodeSetWriteNewSeriesAsyncRequest {
+	return &NodeSetWriteNewSeriesAsyncRequest{}
+}
+
+func (p *NodeSetWriteNewSeriesAsyncRequest) GetWriteNewSeriesAsync() bool {
+	return p.WriteNewSeriesAsync
+}
+func (p *NodeSetWriteNewSeriesAsyncRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetWriteNewSeriesAsync bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetWriteNewSeriesAsync = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error32
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getPersistRateLimit failed: invalid message type")
-		return
-	}
-	result := NodeGetPersistRateLimitResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetWriteNewSeriesAsync {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WriteNewSeriesAsync is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error) {
-	if err = p.sendSetPersistRateLimit(req); err != nil {
-		return
+func (p *NodeSetWriteNewSeriesAsyncRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.WriteNewSeriesAsync = v
 	}
-	return p.recvSetPersistRateLimit()
+	return nil
 }
 
-func (p *NodeClient) sendSetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
-	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
-		return
+func (p *NodeSetWriteNewSeriesAsyncRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeSetWriteNewSeriesAsyncRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	args := NodeSetPersistRateLimitArgs{
-		Req: req,
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvSetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeSetWriteNewSeriesAsyncRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writeNewSeriesAsync", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:writeNewSeriesAsync: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteBool(bool(p.WriteNewSeriesAsync)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writeNewSeriesAsync (1) field write error: ", p), err)
 	}
-	if method != "setPersistRateLimit" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setPersistRateLimit failed: wrong method name")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:writeNewSeriesAsync: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setPersistRateLimit failed: out of sequence response")
-		return
+	return err
+}
+
+func (p *NodeSetWriteNewSeriesAsyncRequest) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error33 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error34 error
-		error34, err = error33.Read(iprot)
+	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Namespaces
+type NodeNamespacesResult_ struct {
+	Namespaces []*NodeNamespace `thrift:"namespaces,1,required" db:"namespaces" json:"namespaces"`
+}
+
+func NewNodeNamespacesResult_() *NodeNamespacesResult_ {
+	return &NodeNamespacesResult_{}
+}
+
+func (p *NodeNamespacesResult_) GetNamespaces() []*NodeNamespace {
+	return p.Namespaces
+}
+func (p *NodeNamespacesResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetNamespaces bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetNamespaces = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error34
-		return
-	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setPersistRateLimit failed: invalid message type")
-		return
-	}
-	result := NodeSetPersistRateLimitResult{}
-	if err = result.Read(iprot); err != nil {
-		return
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetNamespaces {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Namespaces is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-func (p *NodeClient) GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error) {
-	if err = p.sendGetWriteNewSeriesAsync(); err != nil {
-		return
+func (p *NodeNamespacesResult_) ReadField1(iprot thrift.TProtocol) error {
+	_, size, err := iprot.ReadListBegin()
+	if err != nil {
+		return thrift.PrependError("error reading list begin: ", err)
 	}
-	return p.recvGetWriteNewSeriesAsync()
+	tSlice := make([]*NodeNamespace, 0, size)
+	p.Namespaces = tSlice
+	for i := 0; i < size; i++ {
+		_elem18 := &NodeNamespace{}
+		if err := _elem18.Read(iprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem18), err)
+		}
+		p.Namespaces = append(p.Namespaces, _elem18)
+	}
+	if err := iprot.ReadListEnd(); err != nil {
+		return thrift.PrependError("error reading list end: ", err)
+	}
+	return nil
 }
 
-func (p *NodeClient) sendGetWriteNewSeriesAsync() (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodeNamespacesResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeNamespacesResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	args := NodeGetWriteNewSeriesAsyncArgs{}
-	if err = args.Write(oprot); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return oprot.Flush()
+	return nil
 }
 
-func (p *NodeClient) recvGetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeNamespacesResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("namespaces", thrift.LIST, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:namespaces: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteListBegin(thrift.STRUCT, len(p.Namespaces)); err != nil {
+		return thrift.PrependError("error writing list begin: ", err)
 	}
-	if method != "getWriteNewSeriesAsync" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesAsync failed: wrong method name")
-		return
+	for _, v := range p.Namespaces {
+		if err := v.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+		}
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesAsync failed: out of sequence response")
-		return
+	if err := oprot.WriteListEnd(); err != nil {
+		return thrift.PrependError("error writing list end: ", err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error35 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error36 error
-		error36, err = error35.Read(iprot)
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:namespaces: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeNamespacesResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeNamespacesResult_(%+v)", *p)
+}
+
+// Attributes:
+//  - SourceNamespace
+//  - ResolutionNanos
+//  - Aggregation
+type NodeNamespaceRollup struct {
+	SourceNamespace string          `thrift:"sourceNamespace,1,required" db:"sourceNamespace" json:"sourceNamespace"`
+	ResolutionNanos int64           `thrift:"resolutionNanos,2,required" db:"resolutionNanos" json:"resolutionNanos"`
+	Aggregation     AggregationType `thrift:"aggregation,3,required" db:"aggregation" json:"aggregation"`
+}
+
+func NewNodeNamespaceRollup() *NodeNamespaceRollup {
+	return &NodeNamespaceRollup{}
+}
+
+func (p *NodeNamespaceRollup) GetSourceNamespace() string {
+	return p.SourceNamespace
+}
+
+func (p *NodeNamespaceRollup) GetResolutionNanos() int64 {
+	return p.ResolutionNanos
+}
+
+func (p *NodeNamespaceRollup) GetAggregation() AggregationType {
+	return p.Aggregation
+}
+func (p *NodeNamespaceRollup) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetSourceNamespace bool = false
+	var issetResolutionNanos bool = false
+	var issetAggregation bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
 		if err != nil {
-			return
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
 		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetSourceNamespace = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetResolutionNanos = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetAggregation = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-		err = error36
-		return
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesAsync failed: invalid message type")
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	result := NodeGetWriteNewSeriesAsyncResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	if !issetSourceNamespace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field SourceNamespace is not set"))
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if !issetResolutionNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field ResolutionNanos is not set"))
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if !issetAggregation {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Aggregation is not set"))
 	}
-	value = result.GetSuccess()
-	return
+	return nil
 }
 
-// Parameters:
-//  - Req
-func (p *NodeClient) SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error) {
-	if err = p.sendSetWriteNewSeriesAsync(req); err != nil {
-		return
+func (p *NodeNamespaceRollup) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.SourceNamespace = v
 	}
-	return p.recvSetWriteNewSeriesAsync()
+	return nil
 }
 
-func (p *NodeClient) sendSetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (err error) {
-	oprot := p.OutputProtocol
-	if oprot == nil {
-		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.OutputProtocol = oprot
+func (p *NodeNamespaceRollup) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.ResolutionNanos = v
 	}
-	p.SeqId++
-	if err = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
-		return
+	return nil
+}
+
+func (p *NodeNamespaceRollup) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		temp := AggregationType(v)
+		p.Aggregation = temp
 	}
-	args := NodeSetWriteNewSeriesAsyncArgs{
-		Req: req,
+	return nil
+}
+
+func (p *NodeNamespaceRollup) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeNamespaceRollup"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err = args.Write(oprot); err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
 	}
-	if err = oprot.WriteMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	return oprot.Flush()
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-func (p *NodeClient) recvSetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
-	iprot := p.InputProtocol
-	if iprot == nil {
-		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
-		p.InputProtocol = iprot
+func (p *NodeNamespaceRollup) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("sourceNamespace", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:sourceNamespace: ", p), err)
 	}
-	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return
+	if err := oprot.WriteString(string(p.SourceNamespace)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.sourceNamespace (1) field write error: ", p), err)
 	}
-	if method != "setWriteNewSeriesAsync" {
-		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesAsync failed: wrong method name")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:sourceNamespace: ", p), err)
 	}
-	if p.SeqId != seqId {
-		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesAsync failed: out of sequence response")
-		return
+	return err
+}
+
+func (p *NodeNamespaceRollup) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("resolutionNanos", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:resolutionNanos: ", p), err)
 	}
-	if mTypeId == thrift.EXCEPTION {
-		error37 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error38 error
-		error38, err = error37.Read(iprot)
-		if err != nil {
-			return
-		}
-		if err = iprot.ReadMessageEnd(); err != nil {
-			return
-		}
-		err = error38
-		return
+	if err := oprot.WriteI64(int64(p.ResolutionNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.resolutionNanos (2) field write error: ", p), err)
 	}
-	if mTypeId != thrift.REPLY {
-		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesAsync failed: invalid message type")
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:resolutionNanos: ", p), err)
 	}
-	result := NodeSetWriteNewSeriesAsyncResult{}
-	if err = result.Read(iprot); err != nil {
-		return
+	return err
+}
+
+func (p *NodeNamespaceRollup) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("aggregation", thrift.I32, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:aggregation: ", p), err)
 	}
-	if err = iprot.ReadMessageEnd(); err != nil {
-		return
+	if err := oprot.WriteI32(int32(p.Aggregation)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.aggregation (3) field write error: ", p), err)
 	}
-	if result.Err != nil {
-		err = result.Err
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:aggregation: ", p), err)
 	}
-	value = result.GetSuccess()
-	return
+	return err
 }
 
-type NodeProcessor struct {
-	processorMap map[string]thrift.TProcessorFunction
-	handler      Node
+func (p *NodeNamespaceRollup) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeNamespaceRollup(%+v)", *p)
 }
 
-func (p *NodeProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
-	p.processorMap[key] = processor
+// Attributes:
+//  - Name
+//  - RetentionPeriodNanos
+//  - BlockSizeNanos
+//  - BufferFutureNanos
+//  - BufferPastNanos
+//  - NeedsBootstrap
+//  - NeedsFlush
+//  - WritesToCommitLog
+//  - NeedsFilesetCleanup
+//  - NeedsRepair
+//  - ColdWritesEnabled
+//  - SyncCommitLogWrites
+//  - Rollup
+type NodeNamespace struct {
+	Name                 string               `thrift:"name,1,required" db:"name" json:"name"`
+	RetentionPeriodNanos int64                `thrift:"retentionPeriodNanos,2,required" db:"retentionPeriodNanos" json:"retentionPeriodNanos"`
+	BlockSizeNanos       int64                `thrift:"blockSizeNanos,3,required" db:"blockSizeNanos" json:"blockSizeNanos"`
+	BufferFutureNanos    int64                `thrift:"bufferFutureNanos,4,required" db:"bufferFutureNanos" json:"bufferFutureNanos"`
+	BufferPastNanos      int64                `thrift:"bufferPastNanos,5,required" db:"bufferPastNanos" json:"bufferPastNanos"`
+	NeedsBootstrap       bool                 `thrift:"needsBootstrap,6,required" db:"needsBootstrap" json:"needsBootstrap"`
+	NeedsFlush           bool                 `thrift:"needsFlush,7,required" db:"needsFlush" json:"needsFlush"`
+	WritesToCommitLog    bool                 `thrift:"writesToCommitLog,8,required" db:"writesToCommitLog" json:"writesToCommitLog"`
+	NeedsFilesetCleanup  bool                 `thrift:"needsFilesetCleanup,9,required" db:"needsFilesetCleanup" json:"needsFilesetCleanup"`
+	NeedsRepair          bool                 `thrift:"needsRepair,10,required" db:"needsRepair" json:"needsRepair"`
+	ColdWritesEnabled    *bool                `thrift:"coldWritesEnabled,11" db:"coldWritesEnabled" json:"coldWritesEnabled,omitempty"`
+	SyncCommitLogWrites  *bool                `thrift:"syncCommitLogWrites,12" db:"syncCommitLogWrites" json:"syncCommitLogWrites,omitempty"`
+	Rollup               *NodeNamespaceRollup `thrift:"rollup,13" db:"rollup" json:"rollup,omitempty"`
 }
 
-func (p *NodeProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
-	processor, ok = p.processorMap[key]
-	return processor, ok
+func NewNodeNamespace() *NodeNamespace {
+	return &NodeNamespace{}
 }
 
-func (p *NodeProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
-	return p.processorMap
+func (p *NodeNamespace) GetName() string {
+	return p.Name
 }
 
-func NewNodeProcessor(handler Node) *NodeProcessor {
+func (p *NodeNamespace) GetRetentionPeriodNanos() int64 {
+	return p.RetentionPeriodNanos
+}
 
-	self39 := &NodeProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
-	self39.processorMap["fetch"] = &nodeProcessorFetch{handler: handler}
-	self39.processorMap["write"] = &nodeProcessorWrite{handler: handler}
-	self39.processorMap["fetchBatchRaw"] = &nodeProcessorFetchBatchRaw{handler: handler}
-	self39.processorMap["fetchBlocksRaw"] = &nodeProcessorFetchBlocksRaw{handler: handler}
-	self39.processorMap["fetchBlocksMetadataRaw"] = &nodeProcessorFetchBlocksMetadataRaw{handler: handler}
-	self39.processorMap["writeBatchRaw"] = &nodeProcessorWriteBatchRaw{handler: handler}
-	self39.processorMap["repair"] = &nodeProcessorRepair{handler: handler}
-	self39.processorMap["truncate"] = &nodeProcessorTruncate{handler: handler}
-	self39.processorMap["health"] = &nodeProcessorHealth{handler: handler}
-	self39.processorMap["getPersistRateLimit"] = &nodeProcessorGetPersistRateLimit{handler: handler}
-	self39.processorMap["setPersistRateLimit"] = &nodeProcessorSetPersistRateLimit{handler: handler}
-	self39.processorMap["getWriteNewSeriesAsync"] = &nodeProcessorGetWriteNewSeriesAsync{handler: handler}
-	self39.processorMap["setWriteNewSeriesAsync"] = &nodeProcessorSetWriteNewSeriesAsync{handler: handler}
-	return self39
+func (p *NodeNamespace) GetBlockSizeNanos() int64 {
+	return p.BlockSizeNanos
 }
 
-func (p *NodeProcessor) Process(iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	name, _, seqId, err := iprot.ReadMessageBegin()
-	if err != nil {
-		return false, err
+func (p *NodeNamespace) GetBufferFutureNanos() int64 {
+	return p.BufferFutureNanos
+}
+
+func (p *NodeNamespace) GetBufferPastNanos() int64 {
+	return p.BufferPastNanos
+}
+
+func (p *NodeNamespace) GetNeedsBootstrap() bool {
+	return p.NeedsBootstrap
+}
+
+func (p *NodeNamespace) GetNeedsFlush() bool {
+	return p.NeedsFlush
+}
+
+func (p *NodeNamespace) GetWritesToCommitLog() bool {
+	return p.WritesToCommitLog
+}
+
+func (p *NodeNamespace) GetNeedsFilesetCleanup() bool {
+	return p.NeedsFilesetCleanup
+}
+
+func (p *NodeNamespace) GetNeedsRepair() bool {
+	return p.NeedsRepair
+}
+
+var NodeNamespace_ColdWritesEnabled_DEFAULT bool
+
+func (p *NodeNamespace) GetColdWritesEnabled() bool {
+	if !p.IsSetColdWritesEnabled() {
+		return NodeNamespace_ColdWritesEnabled_DEFAULT
 	}
-	if processor, ok := p.GetProcessorFunction(name); ok {
-		return processor.Process(seqId, iprot, oprot)
+	return *p.ColdWritesEnabled
+}
+
+var NodeNamespace_SyncCommitLogWrites_DEFAULT bool
+
+func (p *NodeNamespace) GetSyncCommitLogWrites() bool {
+	if !p.IsSetSyncCommitLogWrites() {
+		return NodeNamespace_SyncCommitLogWrites_DEFAULT
 	}
-	iprot.Skip(thrift.STRUCT)
-	iprot.ReadMessageEnd()
-	x40 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
-	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
-	x40.Write(oprot)
-	oprot.WriteMessageEnd()
-	oprot.Flush()
-	return false, x40
+	return *p.SyncCommitLogWrites
+}
+
+var NodeNamespace_Rollup_DEFAULT *NodeNamespaceRollup
 
+func (p *NodeNamespace) GetRollup() *NodeNamespaceRollup {
+	if !p.IsSetRollup() {
+		return NodeNamespace_Rollup_DEFAULT
+	}
+	return p.Rollup
+}
+func (p *NodeNamespace) IsSetColdWritesEnabled() bool {
+	return p.ColdWritesEnabled != nil
 }
 
-type nodeProcessorFetch struct {
-	handler Node
+func (p *NodeNamespace) IsSetSyncCommitLogWrites() bool {
+	return p.SyncCommitLogWrites != nil
 }
 
-func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) IsSetRollup() bool {
+	return p.Rollup != nil
+}
+func (p *NodeNamespace) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeFetchResult{}
-	var retval *FetchResult_
-	var err2 error
-	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+	var issetName bool = false
+	var issetRetentionPeriodNanos bool = false
+	var issetBlockSizeNanos bool = false
+	var issetBufferFutureNanos bool = false
+	var issetBufferPastNanos bool = false
+	var issetNeedsBootstrap bool = false
+	var issetNeedsFlush bool = false
+	var issetWritesToCommitLog bool = false
+	var issetNeedsFilesetCleanup bool = false
+	var issetNeedsRepair bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetName = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetRetentionPeriodNanos = true
+		case 3:
+			if err := p.ReadField3(iprot); err != nil {
+				return err
+			}
+			issetBlockSizeNanos = true
+		case 4:
+			if err := p.ReadField4(iprot); err != nil {
+				return err
+			}
+			issetBufferFutureNanos = true
+		case 5:
+			if err := p.ReadField5(iprot); err != nil {
+				return err
+			}
+			issetBufferPastNanos = true
+		case 6:
+			if err := p.ReadField6(iprot); err != nil {
+				return err
+			}
+			issetNeedsBootstrap = true
+		case 7:
+			if err := p.ReadField7(iprot); err != nil {
+				return err
+			}
+			issetNeedsFlush = true
+		case 8:
+			if err := p.ReadField8(iprot); err != nil {
+				return err
+			}
+			issetWritesToCommitLog = true
+		case 9:
+			if err := p.ReadField9(iprot); err != nil {
+				return err
+			}
+			issetNeedsFilesetCleanup = true
+		case 10:
+			if err := p.ReadField10(iprot); err != nil {
+				return err
+			}
+			issetNeedsRepair = true
+		case 11:
+			if err := p.ReadField11(iprot); err != nil {
+				return err
+			}
+		case 12:
+			if err := p.ReadField12(iprot); err != nil {
+				return err
+			}
+		case 13:
+			if err := p.ReadField13(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
-			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
-		err = err2
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if !issetRetentionPeriodNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field RetentionPeriodNanos is not set"))
 	}
-	if err != nil {
-		return
+	if !issetBlockSizeNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field BlockSizeNanos is not set"))
 	}
-	return true, err
-}
-
-type nodeProcessorWrite struct {
-	handler Node
-}
-
-func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+	if !issetBufferFutureNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field BufferFutureNanos is not set"))
 	}
-
-	iprot.ReadMessageEnd()
-	result := NodeWriteResult{}
-	var err2 error
-	if err2 = p.handler.Write(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing write: "+err2.Error())
-			oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
+	if !issetBufferPastNanos {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field BufferPastNanos is not set"))
 	}
-	if err2 = oprot.WriteMessageBegin("write", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if !issetNeedsBootstrap {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NeedsBootstrap is not set"))
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if !issetNeedsFlush {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NeedsFlush is not set"))
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	if !issetWritesToCommitLog {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field WritesToCommitLog is not set"))
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if !issetNeedsFilesetCleanup {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NeedsFilesetCleanup is not set"))
 	}
-	if err != nil {
-		return
+	if !issetNeedsRepair {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field NeedsRepair is not set"))
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorFetchBatchRaw struct {
-	handler Node
+func (p *NodeNamespace) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Name = v
+	}
+	return nil
 }
 
-func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBatchRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.RetentionPeriodNanos = v
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeFetchBatchRawResult{}
-	var retval *FetchBatchRawResult_
-	var err2 error
-	if retval, err2 = p.handler.FetchBatchRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBatchRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
+func (p *NodeNamespace) ReadField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
 	} else {
-		result.Success = retval
+		p.BlockSizeNanos = v
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBatchRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeNamespace) ReadField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.BufferFutureNanos = v
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeNamespace) ReadField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.BufferPastNanos = v
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeNamespace) ReadField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.NeedsBootstrap = v
 	}
-	if err != nil {
-		return
+	return nil
+}
+
+func (p *NodeNamespace) ReadField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.NeedsFlush = v
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorFetchBlocksRaw struct {
-	handler Node
+func (p *NodeNamespace) ReadField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		p.WritesToCommitLog = v
+	}
+	return nil
 }
 
-func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBlocksRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) ReadField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.NeedsFilesetCleanup = v
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeFetchBlocksRawResult{}
-	var retval *FetchBlocksRawResult_
-	var err2 error
-	if retval, err2 = p.handler.FetchBlocksRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
+func (p *NodeNamespace) ReadField10(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 10: ", err)
 	} else {
-		result.Success = retval
+		p.NeedsRepair = v
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeNamespace) ReadField11(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 11: ", err)
+	} else {
+		p.ColdWritesEnabled = &v
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
-	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorFetchBlocksMetadataRaw struct {
-	handler Node
+func (p *NodeNamespace) ReadField12(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 12: ", err)
+	} else {
+		p.SyncCommitLogWrites = &v
+	}
+	return nil
 }
 
-func (p *nodeProcessorFetchBlocksMetadataRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeFetchBlocksMetadataRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) ReadField13(iprot thrift.TProtocol) error {
+	p.Rollup = &NodeNamespaceRollup{}
+	if err := p.Rollup.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Rollup), err)
 	}
+	return nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeFetchBlocksMetadataRawResult{}
-	var retval *FetchBlocksMetadataRawResult_
-	var err2 error
-	if retval, err2 = p.handler.FetchBlocksMetadataRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRaw: "+err2.Error())
-			oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeNamespace) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeNamespace"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField3(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField4(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField5(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField6(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField7(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField8(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField9(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField10(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField11(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField12(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField13(oprot); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeNamespace) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorWriteBatchRaw struct {
-	handler Node
+func (p *NodeNamespace) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("retentionPeriodNanos", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:retentionPeriodNanos: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.RetentionPeriodNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.retentionPeriodNanos (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:retentionPeriodNanos: ", p), err)
+	}
+	return err
 }
 
-func (p *nodeProcessorWriteBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeWriteBatchRawArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("blockSizeNanos", thrift.I64, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:blockSizeNanos: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.BlockSizeNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.blockSizeNanos (3) field write error: ", p), err)
 	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:blockSizeNanos: ", p), err)
+	}
+	return err
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeWriteBatchRawResult{}
-	var err2 error
-	if err2 = p.handler.WriteBatchRaw(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *WriteBatchRawErrors:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeBatchRaw: "+err2.Error())
-			oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
+func (p *NodeNamespace) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("bufferFutureNanos", thrift.I64, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:bufferFutureNanos: ", p), err)
 	}
-	if err2 = oprot.WriteMessageBegin("writeBatchRaw", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteI64(int64(p.BufferFutureNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.bufferFutureNanos (4) field write error: ", p), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:bufferFutureNanos: ", p), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return err
+}
+
+func (p *NodeNamespace) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("bufferPastNanos", thrift.I64, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:bufferPastNanos: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteI64(int64(p.BufferPastNanos)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.bufferPastNanos (5) field write error: ", p), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:bufferPastNanos: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorRepair struct {
-	handler Node
+func (p *NodeNamespace) writeField6(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("needsBootstrap", thrift.BOOL, 6); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:needsBootstrap: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.NeedsBootstrap)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.needsBootstrap (6) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 6:needsBootstrap: ", p), err)
+	}
+	return err
 }
 
-func (p *nodeProcessorRepair) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeRepairArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) writeField7(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("needsFlush", thrift.BOOL, 7); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:needsFlush: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.NeedsFlush)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.needsFlush (7) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 7:needsFlush: ", p), err)
 	}
+	return err
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeRepairResult{}
-	var err2 error
-	if err2 = p.handler.Repair(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing repair: "+err2.Error())
-			oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
+func (p *NodeNamespace) writeField8(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("writesToCommitLog", thrift.BOOL, 8); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:writesToCommitLog: ", p), err)
 	}
-	if err2 = oprot.WriteMessageBegin("repair", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteBool(bool(p.WritesToCommitLog)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.writesToCommitLog (8) field write error: ", p), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 8:writesToCommitLog: ", p), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return err
+}
+
+func (p *NodeNamespace) writeField9(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("needsFilesetCleanup", thrift.BOOL, 9); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:needsFilesetCleanup: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteBool(bool(p.NeedsFilesetCleanup)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.needsFilesetCleanup (9) field write error: ", p), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 9:needsFilesetCleanup: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorTruncate struct {
-	handler Node
+func (p *NodeNamespace) writeField10(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("needsRepair", thrift.BOOL, 10); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 10:needsRepair: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.NeedsRepair)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.needsRepair (10) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 10:needsRepair: ", p), err)
+	}
+	return err
 }
 
-func (p *nodeProcessorTruncate) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeTruncateArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeNamespace) writeField11(oprot thrift.TProtocol) (err error) {
+	if p.IsSetColdWritesEnabled() {
+		if err := oprot.WriteFieldBegin("coldWritesEnabled", thrift.BOOL, 11); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 11:coldWritesEnabled: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.ColdWritesEnabled)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.coldWritesEnabled (11) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 11:coldWritesEnabled: ", p), err)
+		}
 	}
+	return err
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeTruncateResult{}
-	var retval *TruncateResult_
-	var err2 error
-	if retval, err2 = p.handler.Truncate(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing truncate: "+err2.Error())
-			oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+func (p *NodeNamespace) writeField12(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSyncCommitLogWrites() {
+		if err := oprot.WriteFieldBegin("syncCommitLogWrites", thrift.BOOL, 12); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 12:syncCommitLogWrites: ", p), err)
+		}
+		if err := oprot.WriteBool(bool(*p.SyncCommitLogWrites)); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T.syncCommitLogWrites (12) field write error: ", p), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 12:syncCommitLogWrites: ", p), err)
 		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("truncate", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	return err
+}
+
+func (p *NodeNamespace) writeField13(oprot thrift.TProtocol) (err error) {
+	if p.IsSetRollup() {
+		if err := oprot.WriteFieldBegin("rollup", thrift.STRUCT, 13); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 13:rollup: ", p), err)
+		}
+		if err := p.Rollup.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Rollup), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 13:rollup: ", p), err)
+		}
 	}
-	if err != nil {
-		return
+	return err
+}
+
+func (p *NodeNamespace) String() string {
+	if p == nil {
+		return "<nil>"
 	}
-	return true, err
+	return fmt.Sprintf("NodeNamespace(%+v)", *p)
 }
 
-type nodeProcessorHealth struct {
-	handler Node
+// Attributes:
+//  - Namespace
+type NodeAddNamespaceRequest struct {
+	Namespace *NodeNamespace `thrift:"namespace,1,required" db:"namespace" json:"namespace"`
 }
 
-func (p *nodeProcessorHealth) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeHealthArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func NewNodeAddNamespaceRequest() *NodeAddNamespaceRequest {
+	return &NodeAddNamespaceRequest{}
+}
+
+var NodeAddNamespaceRequest_Namespace_DEFAULT *NodeNamespace
+
+func (p *NodeAddNamespaceRequest) GetNamespace() *NodeNamespace {
+	if !p.IsSetNamespace() {
+		return NodeAddNamespaceRequest_Namespace_DEFAULT
 	}
+	return p.Namespace
+}
+func (p *NodeAddNamespaceRequest) IsSetNamespace() bool {
+	return p.Namespace != nil
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeHealthResult{}
-	var retval *NodeHealthResult_
-	var err2 error
-	if retval, err2 = p.handler.Health(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+func (p *NodeAddNamespaceRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetNamespace bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetNamespace = true
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing health: "+err2.Error())
-			oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("health", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if !issetNamespace {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Namespace is not set"))
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeAddNamespaceRequest) ReadField1(iprot thrift.TProtocol) error {
+	p.Namespace = &NodeNamespace{}
+	if err := p.Namespace.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Namespace), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeAddNamespaceRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeAddNamespaceRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err != nil {
-		return
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	return true, err
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-type nodeProcessorGetPersistRateLimit struct {
-	handler Node
+func (p *NodeAddNamespaceRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("namespace", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:namespace: ", p), err)
+	}
+	if err := p.Namespace.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Namespace), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:namespace: ", p), err)
+	}
+	return err
 }
 
-func (p *nodeProcessorGetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetPersistRateLimitArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeAddNamespaceRequest) String() string {
+	if p == nil {
+		return "<nil>"
 	}
+	return fmt.Sprintf("NodeAddNamespaceRequest(%+v)", *p)
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetPersistRateLimitResult{}
-	var retval *NodePersistRateLimitResult_
-	var err2 error
-	if retval, err2 = p.handler.GetPersistRateLimit(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+// Attributes:
+//  - Name
+type NodeRemoveNamespaceRequest struct {
+	Name string `thrift:"name,1,required" db:"name" json:"name"`
+}
+
+func NewNodeRemoveNamespaceRequest() *NodeRemoveNamespaceRequest {
+	return &NodeRemoveNamespaceRequest{}
+}
+
+func (p *NodeRemoveNamespaceRequest) GetName() string {
+	return p.Name
+}
+func (p *NodeRemoveNamespaceRequest) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetName bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetName = true
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getPersistRateLimit: "+err2.Error())
-			oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetName {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Name is not set"))
+	}
+	return nil
+}
+
+func (p *NodeRemoveNamespaceRequest) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
 	} else {
-		result.Success = retval
+		p.Name = v
 	}
-	if err2 = oprot.WriteMessageBegin("getPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeRemoveNamespaceRequest) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("NodeRemoveNamespaceRequest"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeRemoveNamespaceRequest) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("name", thrift.STRING, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:name: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Name)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.name (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:name: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeRemoveNamespaceRequest) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeRemoveNamespaceRequest(%+v)", *p)
+}
+
+// Attributes:
+//  - Ok
+//  - Status
+type HealthResult_ struct {
+	Ok     bool   `thrift:"ok,1,required" db:"ok" json:"ok"`
+	Status string `thrift:"status,2,required" db:"status" json:"status"`
+}
+
+func NewHealthResult_() *HealthResult_ {
+	return &HealthResult_{}
+}
+
+func (p *HealthResult_) GetOk() bool {
+	return p.Ok
+}
+
+func (p *HealthResult_) GetStatus() string {
+	return p.Status
+}
+func (p *HealthResult_) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	var issetOk bool = false
+	var issetStatus bool = false
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+			issetOk = true
+		case 2:
+			if err := p.ReadField2(iprot); err != nil {
+				return err
+			}
+			issetStatus = true
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	if !issetOk {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Ok is not set"))
+	}
+	if !issetStatus {
+		return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Status is not set"))
+	}
+	return nil
+}
+
+func (p *HealthResult_) ReadField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadBool(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.Ok = v
+	}
+	return nil
+}
+
+func (p *HealthResult_) ReadField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.Status = v
+	}
+	return nil
+}
+
+func (p *HealthResult_) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("HealthResult"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField2(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *HealthResult_) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ok", thrift.BOOL, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ok: ", p), err)
+	}
+	if err := oprot.WriteBool(bool(p.Ok)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ok (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ok: ", p), err)
+	}
+	return err
+}
+
+func (p *HealthResult_) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("status", thrift.STRING, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:status: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Status)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.status (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:status: ", p), err)
+	}
+	return err
+}
+
+func (p *HealthResult_) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("HealthResult_(%+v)", *p)
+}
+
+type Node interface {
+	// Parameters:
+	//  - Req
+	Fetch(req *FetchRequest) (r *FetchResult_, err error)
+	// Parameters:
+	//  - Req
+	Write(req *WriteRequest) (err error)
+	// Parameters:
+	//  - Req
+	FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error)
+	// Parameters:
+	//  - Req
+	WriteBatchRaw(req *WriteBatchRawRequest) (err error)
+	Repair() (err error)
+	// Parameters:
+	//  - Req
+	Truncate(req *TruncateRequest) (r *TruncateResult_, err error)
+	// Parameters:
+	//  - Req
+	Delete(req *DeleteRequest) (err error)
+	// Parameters:
+	//  - Req
+	WriteTagged(req *WriteTaggedRequest) (err error)
+	// Parameters:
+	//  - Req
+	FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error)
+	Health() (r *NodeHealthResult_, err error)
+	GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error)
+	// Parameters:
+	//  - Req
+	SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error)
+	GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error)
+	// Parameters:
+	//  - Req
+	SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error)
+	GetNamespaces() (r *NodeNamespacesResult_, err error)
+	// Parameters:
+	//  - Req
+	AddNamespace(req *NodeAddNamespaceRequest) (err error)
+	// Parameters:
+	//  - Req
+	RemoveNamespace(req *NodeRemoveNamespaceRequest) (err error)
+}
+
+type NodeClient struct {
+	Transport       thrift.TTransport
+	ProtocolFactory thrift.TProtocolFactory
+	InputProtocol   thrift.TProtocol
+	OutputProtocol  thrift.TProtocol
+	SeqId           int32
+}
+
+func NewNodeClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *NodeClient {
+	return &NodeClient{Transport: t,
+		ProtocolFactory: f,
+		InputProtocol:   f.GetProtocol(t),
+		OutputProtocol:  f.GetProtocol(t),
+		SeqId:           0,
+	}
+}
+
+func NewNodeClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *NodeClient {
+	return &NodeClient{Transport: t,
+		ProtocolFactory: nil,
+		InputProtocol:   iprot,
+		OutputProtocol:  oprot,
+		SeqId:           0,
+	}
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Fetch(req *FetchRequest) (r *FetchResult_, err error) {
+	if err = p.sendFetch(req); err != nil {
+		return
+	}
+	return p.recvFetch()
+}
+
+func (p *NodeClient) sendFetch(req *FetchRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetch", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetch() (value *FetchResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetch" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetch failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetch failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error19 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error20 error
+		error20, err = error19.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error20
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetch failed: invalid message type")
+		return
+	}
+	result := NodeFetchResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Write(req *WriteRequest) (err error) {
+	if err = p.sendWrite(req); err != nil {
+		return
+	}
+	return p.recvWrite()
+}
+
+func (p *NodeClient) sendWrite(req *WriteRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("write", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWrite() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "write" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "write failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "write failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error21 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error22 error
+		error22, err = error21.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error22
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "write failed: invalid message type")
+		return
+	}
+	result := NodeWriteResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBatchRaw(req *FetchBatchRawRequest) (r *FetchBatchRawResult_, err error) {
+	if err = p.sendFetchBatchRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBatchRaw()
+}
+
+func (p *NodeClient) sendFetchBatchRaw(req *FetchBatchRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBatchRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBatchRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBatchRaw() (value *FetchBatchRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBatchRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBatchRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBatchRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error23 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error24 error
+		error24, err = error23.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error24
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBatchRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBatchRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBlocksRaw(req *FetchBlocksRawRequest) (r *FetchBlocksRawResult_, err error) {
+	if err = p.sendFetchBlocksRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBlocksRaw()
+}
+
+func (p *NodeClient) sendFetchBlocksRaw(req *FetchBlocksRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBlocksRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBlocksRaw() (value *FetchBlocksRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBlocksRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error25 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error26 error
+		error26, err = error25.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error26
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBlocksRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (r *FetchBlocksMetadataRawResult_, err error) {
+	if err = p.sendFetchBlocksMetadataRaw(req); err != nil {
+		return
+	}
+	return p.recvFetchBlocksMetadataRaw()
+}
+
+func (p *NodeClient) sendFetchBlocksMetadataRaw(req *FetchBlocksMetadataRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchBlocksMetadataRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchBlocksMetadataRaw() (value *FetchBlocksMetadataRawResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchBlocksMetadataRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchBlocksMetadataRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchBlocksMetadataRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error27 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error28 error
+		error28, err = error27.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error28
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchBlocksMetadataRaw failed: invalid message type")
+		return
+	}
+	result := NodeFetchBlocksMetadataRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) WriteBatchRaw(req *WriteBatchRawRequest) (err error) {
+	if err = p.sendWriteBatchRaw(req); err != nil {
+		return
+	}
+	return p.recvWriteBatchRaw()
+}
+
+func (p *NodeClient) sendWriteBatchRaw(req *WriteBatchRawRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("writeBatchRaw", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteBatchRawArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWriteBatchRaw() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "writeBatchRaw" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeBatchRaw failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeBatchRaw failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error29 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error30 error
+		error30, err = error29.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error30
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeBatchRaw failed: invalid message type")
+		return
+	}
+	result := NodeWriteBatchRawResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+func (p *NodeClient) Repair() (err error) {
+	if err = p.sendRepair(); err != nil {
+		return
+	}
+	return p.recvRepair()
+}
+
+func (p *NodeClient) sendRepair() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("repair", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeRepairArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvRepair() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "repair" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "repair failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "repair failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error31 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error32 error
+		error32, err = error31.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error32
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "repair failed: invalid message type")
+		return
+	}
+	result := NodeRepairResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Truncate(req *TruncateRequest) (r *TruncateResult_, err error) {
+	if err = p.sendTruncate(req); err != nil {
+		return
+	}
+	return p.recvTruncate()
+}
+
+func (p *NodeClient) sendTruncate(req *TruncateRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("truncate", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeTruncateArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvTruncate() (value *TruncateResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "truncate" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "truncate failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "truncate failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error33 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error34 error
+		error34, err = error33.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error34
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "truncate failed: invalid message type")
+		return
+	}
+	result := NodeTruncateResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) Delete(req *DeleteRequest) (err error) {
+	if err = p.sendDelete(req); err != nil {
+		return
+	}
+	return p.recvDelete()
+}
+
+func (p *NodeClient) sendDelete(req *DeleteRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("delete", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeDeleteArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvDelete() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "delete" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "delete failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "delete failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error35 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error36 error
+		error36, err = error35.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error36
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "delete failed: invalid message type")
+		return
+	}
+	result := NodeDeleteResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) WriteTagged(req *WriteTaggedRequest) (err error) {
+	if err = p.sendWriteTagged(req); err != nil {
+		return
+	}
+	return p.recvWriteTagged()
+}
+
+func (p *NodeClient) sendWriteTagged(req *WriteTaggedRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("writeTagged", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeWriteTaggedArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvWriteTagged() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "writeTagged" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "writeTagged failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "writeTagged failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error37 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error38 error
+		error38, err = error37.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error38
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "writeTagged failed: invalid message type")
+		return
+	}
+	result := NodeWriteTaggedResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error) {
+	if err = p.sendFetchTagged(req); err != nil {
+		return
+	}
+	return p.recvFetchTagged()
+}
+
+func (p *NodeClient) sendFetchTagged(req *FetchTaggedRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchTagged", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeFetchTaggedArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchTagged" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchTagged failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchTagged failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error39 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error40 error
+		error40, err = error39.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error40
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchTagged failed: invalid message type")
+		return
+	}
+	result := NodeFetchTaggedResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+func (p *NodeClient) Health() (r *NodeHealthResult_, err error) {
+	if err = p.sendHealth(); err != nil {
+		return
+	}
+	return p.recvHealth()
+}
+
+func (p *NodeClient) sendHealth() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("health", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeHealthArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvHealth() (value *NodeHealthResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "health" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "health failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "health failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error41 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error42 error
+		error42, err = error41.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error42
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "health failed: invalid message type")
+		return
+	}
+	result := NodeHealthResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+func (p *NodeClient) GetPersistRateLimit() (r *NodePersistRateLimitResult_, err error) {
+	if err = p.sendGetPersistRateLimit(); err != nil {
+		return
+	}
+	return p.recvGetPersistRateLimit()
+}
+
+func (p *NodeClient) sendGetPersistRateLimit() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("getPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeGetPersistRateLimitArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvGetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "getPersistRateLimit" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getPersistRateLimit failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getPersistRateLimit failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error43 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error44 error
+		error44, err = error43.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error44
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getPersistRateLimit failed: invalid message type")
+		return
+	}
+	result := NodeGetPersistRateLimitResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) SetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (r *NodePersistRateLimitResult_, err error) {
+	if err = p.sendSetPersistRateLimit(req); err != nil {
+		return
+	}
+	return p.recvSetPersistRateLimit()
+}
+
+func (p *NodeClient) sendSetPersistRateLimit(req *NodeSetPersistRateLimitRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("setPersistRateLimit", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeSetPersistRateLimitArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvSetPersistRateLimit() (value *NodePersistRateLimitResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "setPersistRateLimit" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setPersistRateLimit failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setPersistRateLimit failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error45 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error46 error
+		error46, err = error45.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error46
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setPersistRateLimit failed: invalid message type")
+		return
+	}
+	result := NodeSetPersistRateLimitResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+func (p *NodeClient) GetWriteNewSeriesAsync() (r *NodeWriteNewSeriesAsyncResult_, err error) {
+	if err = p.sendGetWriteNewSeriesAsync(); err != nil {
+		return
+	}
+	return p.recvGetWriteNewSeriesAsync()
+}
+
+func (p *NodeClient) sendGetWriteNewSeriesAsync() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeGetWriteNewSeriesAsyncArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvGetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "getWriteNewSeriesAsync" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getWriteNewSeriesAsync failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getWriteNewSeriesAsync failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error47 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error48 error
+		error48, err = error47.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error48
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getWriteNewSeriesAsync failed: invalid message type")
+		return
+	}
+	result := NodeGetWriteNewSeriesAsyncResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) SetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (r *NodeWriteNewSeriesAsyncResult_, err error) {
+	if err = p.sendSetWriteNewSeriesAsync(req); err != nil {
+		return
+	}
+	return p.recvSetWriteNewSeriesAsync()
+}
+
+func (p *NodeClient) sendSetWriteNewSeriesAsync(req *NodeSetWriteNewSeriesAsyncRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeSetWriteNewSeriesAsyncArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvSetWriteNewSeriesAsync() (value *NodeWriteNewSeriesAsyncResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "setWriteNewSeriesAsync" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "setWriteNewSeriesAsync failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "setWriteNewSeriesAsync failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error49 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error50 error
+		error50, err = error49.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error50
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "setWriteNewSeriesAsync failed: invalid message type")
+		return
+	}
+	result := NodeSetWriteNewSeriesAsyncResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+func (p *NodeClient) GetNamespaces() (r *NodeNamespacesResult_, err error) {
+	if err = p.sendGetNamespaces(); err != nil {
+		return
+	}
+	return p.recvGetNamespaces()
+}
+
+func (p *NodeClient) sendGetNamespaces() (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("getNamespaces", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeGetNamespacesArgs{}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvGetNamespaces() (value *NodeNamespacesResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "getNamespaces" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "getNamespaces failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "getNamespaces failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error51 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error52 error
+		error52, err = error51.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error52
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "getNamespaces failed: invalid message type")
+		return
+	}
+	result := NodeGetNamespacesResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) AddNamespace(req *NodeAddNamespaceRequest) (err error) {
+	if err = p.sendAddNamespace(req); err != nil {
+		return
+	}
+	return p.recvAddNamespace()
+}
+
+func (p *NodeClient) sendAddNamespace(req *NodeAddNamespaceRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("addNamespace", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeAddNamespaceArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvAddNamespace() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "addNamespace" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "addNamespace failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "addNamespace failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error53 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error54 error
+		error54, err = error53.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error54
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "addNamespace failed: invalid message type")
+		return
+	}
+	result := NodeAddNamespaceResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *NodeClient) RemoveNamespace(req *NodeRemoveNamespaceRequest) (err error) {
+	if err = p.sendRemoveNamespace(req); err != nil {
+		return
+	}
+	return p.recvRemoveNamespace()
+}
+
+func (p *NodeClient) sendRemoveNamespace(req *NodeRemoveNamespaceRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("removeNamespace", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := NodeRemoveNamespaceArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *NodeClient) recvRemoveNamespace() (err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "removeNamespace" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "removeNamespace failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "removeNamespace failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error55 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error56 error
+		error56, err = error55.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error56
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "removeNamespace failed: invalid message type")
+		return
+	}
+	result := NodeRemoveNamespaceResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	return
+}
+
+type NodeProcessor struct {
+	processorMap map[string]thrift.TProcessorFunction
+	handler      Node
+}
+
+func (p *NodeProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
+	p.processorMap[key] = processor
+}
+
+func (p *NodeProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
+	processor, ok = p.processorMap[key]
+	return processor, ok
+}
+
+func (p *NodeProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
+	return p.processorMap
+}
+
+func NewNodeProcessor(handler Node) *NodeProcessor {
+
+	self57 := &NodeProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
+	self57.processorMap["fetch"] = &nodeProcessorFetch{handler: handler}
+	self57.processorMap["write"] = &nodeProcessorWrite{handler: handler}
+	self57.processorMap["fetchBatchRaw"] = &nodeProcessorFetchBatchRaw{handler: handler}
+	self57.processorMap["fetchBlocksRaw"] = &nodeProcessorFetchBlocksRaw{handler: handler}
+	self57.processorMap["fetchBlocksMetadataRaw"] = &nodeProcessorFetchBlocksMetadataRaw{handler: handler}
+	self57.processorMap["writeBatchRaw"] = &nodeProcessorWriteBatchRaw{handler: handler}
+	self57.processorMap["repair"] = &nodeProcessorRepair{handler: handler}
+	self57.processorMap["truncate"] = &nodeProcessorTruncate{handler: handler}
+	self57.processorMap["delete"] = &nodeProcessorDelete{handler: handler}
+	self57.processorMap["writeTagged"] = &nodeProcessorWriteTagged{handler: handler}
+	self57.processorMap["fetchTagged"] = &nodeProcessorFetchTagged{handler: handler}
+	self57.processorMap["health"] = &nodeProcessorHealth{handler: handler}
+	self57.processorMap["getPersistRateLimit"] = &nodeProcessorGetPersistRateLimit{handler: handler}
+	self57.processorMap["setPersistRateLimit"] = &nodeProcessorSetPersistRateLimit{handler: handler}
+	self57.processorMap["getWriteNewSeriesAsync"] = &nodeProcessorGetWriteNewSeriesAsync{handler: handler}
+	self57.processorMap["setWriteNewSeriesAsync"] = &nodeProcessorSetWriteNewSeriesAsync{handler: handler}
+	self57.processorMap["getNamespaces"] = &nodeProcessorGetNamespaces{handler: handler}
+	self57.processorMap["addNamespace"] = &nodeProcessorAddNamespace{handler: handler}
+	self57.processorMap["removeNamespace"] = &nodeProcessorRemoveNamespace{handler: handler}
+	return self57
+}
+
+func (p *NodeProcessor) Process(iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	name, _, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return false, err
+	}
+	if processor, ok := p.GetProcessorFunction(name); ok {
+		return processor.Process(seqId, iprot, oprot)
+	}
+	iprot.Skip(thrift.STRUCT)
+	iprot.ReadMessageEnd()
+	x58 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
+	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
+	x58.Write(oprot)
+	oprot.WriteMessageEnd()
+	oprot.Flush()
+	return false, x58
+
+}
+
+type nodeProcessorFetch struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetch) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchResult{}
+	var retval *FetchResult_
+	var err2 error
+	if retval, err2 = p.handler.Fetch(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetch: "+err2.Error())
+			oprot.WriteMessageBegin("fetch", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetch", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorWrite struct {
+	handler Node
+}
+
+func (p *nodeProcessorWrite) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteResult{}
+	var err2 error
+	if err2 = p.handler.Write(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing write: "+err2.Error())
+			oprot.WriteMessageBegin("write", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("write", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorFetchBatchRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBatchRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBatchRawResult{}
+	var retval *FetchBatchRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBatchRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBatchRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBatchRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetchBatchRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorFetchBlocksRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBlocksRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBlocksRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBlocksRawResult{}
+	var retval *FetchBlocksRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBlocksRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBlocksRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetchBlocksRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorFetchBlocksMetadataRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchBlocksMetadataRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchBlocksMetadataRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchBlocksMetadataRawResult{}
+	var retval *FetchBlocksMetadataRawResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchBlocksMetadataRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchBlocksMetadataRaw: "+err2.Error())
+			oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetchBlocksMetadataRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorWriteBatchRaw struct {
+	handler Node
+}
+
+func (p *nodeProcessorWriteBatchRaw) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteBatchRawArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteBatchRawResult{}
+	var err2 error
+	if err2 = p.handler.WriteBatchRaw(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *WriteBatchRawErrors:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeBatchRaw: "+err2.Error())
+			oprot.WriteMessageBegin("writeBatchRaw", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("writeBatchRaw", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorRepair struct {
+	handler Node
+}
+
+func (p *nodeProcessorRepair) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeRepairArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeRepairResult{}
+	var err2 error
+	if err2 = p.handler.Repair(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing repair: "+err2.Error())
+			oprot.WriteMessageBegin("repair", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("repair", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorTruncate struct {
+	handler Node
+}
+
+func (p *nodeProcessorTruncate) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeTruncateArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeTruncateResult{}
+	var retval *TruncateResult_
+	var err2 error
+	if retval, err2 = p.handler.Truncate(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing truncate: "+err2.Error())
+			oprot.WriteMessageBegin("truncate", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("truncate", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorDelete struct {
+	handler Node
+}
+
+func (p *nodeProcessorDelete) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeDeleteArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("delete", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeDeleteResult{}
+	var err2 error
+	if err2 = p.handler.Delete(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing delete: "+err2.Error())
+			oprot.WriteMessageBegin("delete", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("delete", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorWriteTagged struct {
+	handler Node
+}
+
+func (p *nodeProcessorWriteTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeWriteTaggedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeWriteTaggedResult{}
+	var err2 error
+	if err2 = p.handler.WriteTagged(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing writeTagged: "+err2.Error())
+			oprot.WriteMessageBegin("writeTagged", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("writeTagged", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorFetchTagged struct {
+	handler Node
+}
+
+func (p *nodeProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeFetchTaggedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeFetchTaggedResult{}
+	var retval *FetchTaggedResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
+			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorHealth struct {
+	handler Node
+}
+
+func (p *nodeProcessorHealth) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeHealthArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeHealthResult{}
+	var retval *NodeHealthResult_
+	var err2 error
+	if retval, err2 = p.handler.Health(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing health: "+err2.Error())
+			oprot.WriteMessageBegin("health", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("health", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorGetPersistRateLimit struct {
+	handler Node
+}
+
+func (p *nodeProcessorGetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetPersistRateLimitArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeGetPersistRateLimitResult{}
+	var retval *NodePersistRateLimitResult_
+	var err2 error
+	if retval, err2 = p.handler.GetPersistRateLimit(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getPersistRateLimit: "+err2.Error())
+			oprot.WriteMessageBegin("getPersistRateLimit", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("getPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorSetPersistRateLimit struct {
+	handler Node
+}
+
+func (p *nodeProcessorSetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetPersistRateLimitArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeSetPersistRateLimitResult{}
+	var retval *NodePersistRateLimitResult_
+	var err2 error
+	if retval, err2 = p.handler.SetPersistRateLimit(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setPersistRateLimit: "+err2.Error())
+			oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("setPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorGetWriteNewSeriesAsync struct {
+	handler Node
+}
+
+func (p *nodeProcessorGetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetWriteNewSeriesAsyncArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeGetWriteNewSeriesAsyncResult{}
+	var retval *NodeWriteNewSeriesAsyncResult_
+	var err2 error
+	if retval, err2 = p.handler.GetWriteNewSeriesAsync(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesAsync: "+err2.Error())
+			oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorSetWriteNewSeriesAsync struct {
+	handler Node
+}
+
+func (p *nodeProcessorSetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeSetWriteNewSeriesAsyncArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeSetWriteNewSeriesAsyncResult{}
+	var retval *NodeWriteNewSeriesAsyncResult_
+	var err2 error
+	if retval, err2 = p.handler.SetWriteNewSeriesAsync(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesAsync: "+err2.Error())
+			oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorGetNamespaces struct {
+	handler Node
+}
+
+func (p *nodeProcessorGetNamespaces) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeGetNamespacesArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("getNamespaces", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeGetNamespacesResult{}
+	var retval *NodeNamespacesResult_
+	var err2 error
+	if retval, err2 = p.handler.GetNamespaces(); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getNamespaces: "+err2.Error())
+			oprot.WriteMessageBegin("getNamespaces", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("getNamespaces", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorAddNamespace struct {
+	handler Node
+}
+
+func (p *nodeProcessorAddNamespace) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeAddNamespaceArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("addNamespace", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeAddNamespaceResult{}
+	var err2 error
+	if err2 = p.handler.AddNamespace(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing addNamespace: "+err2.Error())
+			oprot.WriteMessageBegin("addNamespace", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("addNamespace", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+type nodeProcessorRemoveNamespace struct {
+	handler Node
+}
+
+func (p *nodeProcessorRemoveNamespace) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := NodeRemoveNamespaceArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("removeNamespace", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := NodeRemoveNamespaceResult{}
+	var err2 error
+	if err2 = p.handler.RemoveNamespace(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing removeNamespace: "+err2.Error())
+			oprot.WriteMessageBegin("removeNamespace", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	}
+	if err2 = oprot.WriteMessageBegin("removeNamespace", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
+// HELPER FUNCTIONS AND STRUCTURES
+
+// Attributes:
+//  - Req
+type NodeFetchArgs struct {
+	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeFetchArgs() *NodeFetchArgs {
+	return &NodeFetchArgs{}
+}
+
+var NodeFetchArgs_Req_DEFAULT *FetchRequest
+
+func (p *NodeFetchArgs) GetReq() *FetchRequest {
+	if !p.IsSetReq() {
+		return NodeFetchArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeFetchArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchRequest{
+		RangeType: 0,
+
+		ResultTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetch_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeFetchArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchResult struct {
+	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeFetchResult() *NodeFetchResult {
+	return &NodeFetchResult{}
+}
+
+var NodeFetchResult_Success_DEFAULT *FetchResult_
+
+func (p *NodeFetchResult) GetSuccess() *FetchResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeFetchResult_Err_DEFAULT *Error
+
+func (p *NodeFetchResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeFetchResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeFetchResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetch_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Req
+type NodeWriteArgs struct {
+	Req *WriteRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeWriteArgs() *NodeWriteArgs {
+	return &NodeWriteArgs{}
+}
+
+var NodeWriteArgs_Req_DEFAULT *WriteRequest
+
+func (p *NodeWriteArgs) GetReq() *WriteRequest {
+	if !p.IsSetReq() {
+		return NodeWriteArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeWriteArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeWriteArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeWriteArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &WriteRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeWriteArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("write_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeWriteArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeWriteArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeWriteArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Err
+type NodeWriteResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeWriteResult() *NodeWriteResult {
+	return &NodeWriteResult{}
+}
+
+var NodeWriteResult_Err_DEFAULT *Error
+
+func (p *NodeWriteResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeWriteResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeWriteResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeWriteResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeWriteResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeWriteResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("write_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeWriteResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeWriteResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeWriteResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Req
+type NodeFetchBatchRawArgs struct {
+	Req *FetchBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeFetchBatchRawArgs() *NodeFetchBatchRawArgs {
+	return &NodeFetchBatchRawArgs{}
+}
+
+var NodeFetchBatchRawArgs_Req_DEFAULT *FetchBatchRawRequest
+
+func (p *NodeFetchBatchRawArgs) GetReq() *FetchBatchRawRequest {
+	if !p.IsSetReq() {
+		return NodeFetchBatchRawArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeFetchBatchRawArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeFetchBatchRawArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchBatchRawRequest{
+		RangeTimeType: 0,
+	}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBatchRaw_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeFetchBatchRawArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBatchRawArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchBatchRawResult struct {
+	Success *FetchBatchRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeFetchBatchRawResult() *NodeFetchBatchRawResult {
+	return &NodeFetchBatchRawResult{}
+}
+
+var NodeFetchBatchRawResult_Success_DEFAULT *FetchBatchRawResult_
+
+func (p *NodeFetchBatchRawResult) GetSuccess() *FetchBatchRawResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchBatchRawResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeFetchBatchRawResult_Err_DEFAULT *Error
+
+func (p *NodeFetchBatchRawResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchBatchRawResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeFetchBatchRawResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeFetchBatchRawResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeFetchBatchRawResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchBatchRawResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBatchRaw_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBatchRawResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBatchRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBatchRawResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBatchRawResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Req
+type NodeFetchBlocksRawArgs struct {
+	Req *FetchBlocksRawRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeFetchBlocksRawArgs() *NodeFetchBlocksRawArgs {
+	return &NodeFetchBlocksRawArgs{}
+}
+
+var NodeFetchBlocksRawArgs_Req_DEFAULT *FetchBlocksRawRequest
+
+func (p *NodeFetchBlocksRawArgs) GetReq() *FetchBlocksRawRequest {
+	if !p.IsSetReq() {
+		return NodeFetchBlocksRawArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeFetchBlocksRawArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeFetchBlocksRawArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchBlocksRawRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBlocksRaw_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksRawArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBlocksRawArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchBlocksRawResult struct {
+	Success *FetchBlocksRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeFetchBlocksRawResult() *NodeFetchBlocksRawResult {
+	return &NodeFetchBlocksRawResult{}
+}
+
+var NodeFetchBlocksRawResult_Success_DEFAULT *FetchBlocksRawResult_
+
+func (p *NodeFetchBlocksRawResult) GetSuccess() *FetchBlocksRawResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchBlocksRawResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeFetchBlocksRawResult_Err_DEFAULT *Error
+
+func (p *NodeFetchBlocksRawResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchBlocksRawResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeFetchBlocksRawResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeFetchBlocksRawResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeFetchBlocksRawResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchBlocksRawResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBlocksRaw_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksRawResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksRawResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBlocksRawResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Req
+type NodeFetchBlocksMetadataRawArgs struct {
+	Req *FetchBlocksMetadataRawRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeFetchBlocksMetadataRawArgs() *NodeFetchBlocksMetadataRawArgs {
+	return &NodeFetchBlocksMetadataRawArgs{}
+}
+
+var NodeFetchBlocksMetadataRawArgs_Req_DEFAULT *FetchBlocksMetadataRawRequest
+
+func (p *NodeFetchBlocksMetadataRawArgs) GetReq() *FetchBlocksMetadataRawRequest {
+	if !p.IsSetReq() {
+		return NodeFetchBlocksMetadataRawArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeFetchBlocksMetadataRawArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeFetchBlocksMetadataRawArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchBlocksMetadataRawRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBlocksMetadataRaw_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksMetadataRawArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBlocksMetadataRawArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type NodeFetchBlocksMetadataRawResult struct {
+	Success *FetchBlocksMetadataRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                         `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewNodeFetchBlocksMetadataRawResult() *NodeFetchBlocksMetadataRawResult {
+	return &NodeFetchBlocksMetadataRawResult{}
+}
+
+var NodeFetchBlocksMetadataRawResult_Success_DEFAULT *FetchBlocksMetadataRawResult_
+
+func (p *NodeFetchBlocksMetadataRawResult) GetSuccess() *FetchBlocksMetadataRawResult_ {
+	if !p.IsSetSuccess() {
+		return NodeFetchBlocksMetadataRawResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeFetchBlocksMetadataRawResult_Err_DEFAULT *Error
+
+func (p *NodeFetchBlocksMetadataRawResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return NodeFetchBlocksMetadataRawResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeFetchBlocksMetadataRawResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchBlocksMetadataRawResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchBlocksMetadataRaw_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeFetchBlocksMetadataRawResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeFetchBlocksMetadataRawResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Req
+type NodeWriteBatchRawArgs struct {
+	Req *WriteBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewNodeWriteBatchRawArgs() *NodeWriteBatchRawArgs {
+	return &NodeWriteBatchRawArgs{}
+}
+
+var NodeWriteBatchRawArgs_Req_DEFAULT *WriteBatchRawRequest
+
+func (p *NodeWriteBatchRawArgs) GetReq() *WriteBatchRawRequest {
+	if !p.IsSetReq() {
+		return NodeWriteBatchRawArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeWriteBatchRawArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeWriteBatchRawArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
 	}
-	if err != nil {
-		return
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	return true, err
+	return nil
 }
 
-type nodeProcessorSetPersistRateLimit struct {
-	handler Node
+func (p *NodeWriteBatchRawArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &WriteBatchRawRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
 }
 
-func (p *nodeProcessorSetPersistRateLimit) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetPersistRateLimitArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeWriteBatchRawArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("writeBatchRaw_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-
-	iprot.ReadMessageEnd()
-	result := NodeSetPersistRateLimitResult{}
-	var retval *NodePersistRateLimitResult_
-	var err2 error
-	if retval, err2 = p.handler.SetPersistRateLimit(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setPersistRateLimit: "+err2.Error())
-			oprot.WriteMessageBegin("setPersistRateLimit", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("setPersistRateLimit", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeWriteBatchRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
 	}
-	return true, err
+	return err
 }
 
-type nodeProcessorGetWriteNewSeriesAsync struct {
-	handler Node
+func (p *NodeWriteBatchRawArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeWriteBatchRawArgs(%+v)", *p)
 }
 
-func (p *nodeProcessorGetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeGetWriteNewSeriesAsyncArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
-	}
+// Attributes:
+//  - Err
+type NodeWriteBatchRawResult struct {
+	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
 
-	iprot.ReadMessageEnd()
-	result := NodeGetWriteNewSeriesAsyncResult{}
-	var retval *NodeWriteNewSeriesAsyncResult_
-	var err2 error
-	if retval, err2 = p.handler.GetWriteNewSeriesAsync(); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
-		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing getWriteNewSeriesAsync: "+err2.Error())
-			oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
-		}
-	} else {
-		result.Success = retval
-	}
-	if err2 = oprot.WriteMessageBegin("getWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
-		err = err2
-	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
-	}
-	if err != nil {
-		return
-	}
-	return true, err
+func NewNodeWriteBatchRawResult() *NodeWriteBatchRawResult {
+	return &NodeWriteBatchRawResult{}
 }
 
-type nodeProcessorSetWriteNewSeriesAsync struct {
-	handler Node
+var NodeWriteBatchRawResult_Err_DEFAULT *WriteBatchRawErrors
+
+func (p *NodeWriteBatchRawResult) GetErr() *WriteBatchRawErrors {
+	if !p.IsSetErr() {
+		return NodeWriteBatchRawResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *NodeWriteBatchRawResult) IsSetErr() bool {
+	return p.Err != nil
 }
 
-func (p *nodeProcessorSetWriteNewSeriesAsync) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-	args := NodeSetWriteNewSeriesAsyncArgs{}
-	if err = args.Read(iprot); err != nil {
-		iprot.ReadMessageEnd()
-		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
-		oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-		x.Write(oprot)
-		oprot.WriteMessageEnd()
-		oprot.Flush()
-		return false, err
+func (p *NodeWriteBatchRawResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
 
-	iprot.ReadMessageEnd()
-	result := NodeSetWriteNewSeriesAsyncResult{}
-	var retval *NodeWriteNewSeriesAsyncResult_
-	var err2 error
-	if retval, err2 = p.handler.SetWriteNewSeriesAsync(args.Req); err2 != nil {
-		switch v := err2.(type) {
-		case *Error:
-			result.Err = v
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
 		default:
-			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing setWriteNewSeriesAsync: "+err2.Error())
-			oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.EXCEPTION, seqId)
-			x.Write(oprot)
-			oprot.WriteMessageEnd()
-			oprot.Flush()
-			return true, err2
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
 		}
-	} else {
-		result.Success = retval
 	}
-	if err2 = oprot.WriteMessageBegin("setWriteNewSeriesAsync", thrift.REPLY, seqId); err2 != nil {
-		err = err2
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
 	}
-	if err2 = result.Write(oprot); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeWriteBatchRawResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &WriteBatchRawErrors{}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
-	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
-		err = err2
+	return nil
+}
+
+func (p *NodeWriteBatchRawResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("writeBatchRaw_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
-	if err2 = oprot.Flush(); err == nil && err2 != nil {
-		err = err2
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
-	if err != nil {
-		return
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
 	}
-	return true, err
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
 }
 
-// HELPER FUNCTIONS AND STRUCTURES
-
-// Attributes:
-//  - Req
-type NodeFetchArgs struct {
-	Req *FetchRequest `thrift:"req,1" db:"req" json:"req"`
+func (p *NodeWriteBatchRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
 }
 
-func NewNodeFetchArgs() *NodeFetchArgs {
-	return &NodeFetchArgs{}
+func (p *NodeWriteBatchRawResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("NodeWriteBatchRawResult(%+v)", *p)
 }
 
-var NodeFetchArgs_Req_DEFAULT *FetchRequest
-
-func (p *NodeFetchArgs) GetReq() *FetchRequest {
-	if !p.IsSetReq() {
-		return NodeFetchArgs_Req_DEFAULT
-	}
-	return p.Req
+type NodeRepairArgs struct {
 }
-func (p *NodeFetchArgs) IsSetReq() bool {
-	return p.Req != nil
+
+func NewNodeRepairArgs() *NodeRepairArgs {
+	return &NodeRepairArgs{}
 }
 
-func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeRepairArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -7532,15 +12744,8 @@ func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
+		if err := iprot.Skip(fieldTypeId); err != nil {
+			return err
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -7552,26 +12757,11 @@ func (p *NodeFetchArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchRequest{
-		RangeType: 0,
-
-		ResultTimeType: 0,
-	}
-	if err := p.Req.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
-	}
-	return nil
-}
-
-func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetch_args"); err != nil {
+func (p *NodeRepairArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("repair_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -7582,64 +12772,36 @@ func (p *NodeFetchArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchArgs) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
-	}
-	if err := p.Req.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
-	}
-	return err
-}
-
-func (p *NodeFetchArgs) String() string {
+func (p *NodeRepairArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchArgs(%+v)", *p)
+	return fmt.Sprintf("NodeRepairArgs(%+v)", *p)
 }
 
 // Attributes:
-//  - Success
 //  - Err
-type NodeFetchResult struct {
-	Success *FetchResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error        `thrift:"err,1" db:"err" json:"err,omitempty"`
-}
-
-func NewNodeFetchResult() *NodeFetchResult {
-	return &NodeFetchResult{}
+type NodeRepairResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-var NodeFetchResult_Success_DEFAULT *FetchResult_
-
-func (p *NodeFetchResult) GetSuccess() *FetchResult_ {
-	if !p.IsSetSuccess() {
-		return NodeFetchResult_Success_DEFAULT
-	}
-	return p.Success
+func NewNodeRepairResult() *NodeRepairResult {
+	return &NodeRepairResult{}
 }
 
-var NodeFetchResult_Err_DEFAULT *Error
+var NodeRepairResult_Err_DEFAULT *Error
 
-func (p *NodeFetchResult) GetErr() *Error {
+func (p *NodeRepairResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchResult_Err_DEFAULT
+		return NodeRepairResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchResult) IsSetSuccess() bool {
-	return p.Success != nil
-}
-
-func (p *NodeFetchResult) IsSetErr() bool {
+func (p *NodeRepairResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeRepairResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -7653,10 +12815,6 @@ func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
-		case 0:
-			if err := p.ReadField0(iprot); err != nil {
-				return err
-			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -7676,15 +12834,7 @@ func (p *NodeFetchResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchResult_{}
-	if err := p.Success.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
-	}
-	return nil
-}
-
-func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeRepairResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -7694,14 +12844,11 @@ func (p *NodeFetchResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetch_result"); err != nil {
+func (p *NodeRepairResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("repair_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField0(oprot); err != nil {
-			return err
-		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -7715,22 +12862,7 @@ func (p *NodeFetchResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchResult) writeField0(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSuccess() {
-		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
-		}
-		if err := p.Success.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeRepairResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -7745,36 +12877,36 @@ func (p *NodeFetchResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeFetchResult) String() string {
+func (p *NodeRepairResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchResult(%+v)", *p)
+	return fmt.Sprintf("NodeRepairResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeWriteArgs struct {
-	Req *WriteRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeTruncateArgs struct {
+	Req *TruncateRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeWriteArgs() *NodeWriteArgs {
-	return &NodeWriteArgs{}
+func NewNodeTruncateArgs() *NodeTruncateArgs {
+	return &NodeTruncateArgs{}
 }
 
-var NodeWriteArgs_Req_DEFAULT *WriteRequest
+var NodeTruncateArgs_Req_DEFAULT *TruncateRequest
 
-func (p *NodeWriteArgs) GetReq() *WriteRequest {
+func (p *NodeTruncateArgs) GetReq() *TruncateRequest {
 	if !p.IsSetReq() {
-		return NodeWriteArgs_Req_DEFAULT
+		return NodeTruncateArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeWriteArgs) IsSetReq() bool {
+func (p *NodeTruncateArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeWriteArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeTruncateArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -7807,16 +12939,16 @@ func (p *NodeWriteArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &WriteRequest{}
+func (p *NodeTruncateArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &TruncateRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeWriteArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("write_args"); err != nil {
+func (p *NodeTruncateArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("truncate_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -7833,7 +12965,7 @@ func (p *NodeWriteArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeTruncateArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -7846,36 +12978,51 @@ func (p *NodeWriteArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeWriteArgs) String() string {
+func (p *NodeTruncateArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteArgs(%+v)", *p)
+	return fmt.Sprintf("NodeTruncateArgs(%+v)", *p)
 }
 
 // Attributes:
+//  - Success
 //  - Err
-type NodeWriteResult struct {
-	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeTruncateResult struct {
+	Success *TruncateResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error           `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeWriteResult() *NodeWriteResult {
-	return &NodeWriteResult{}
+func NewNodeTruncateResult() *NodeTruncateResult {
+	return &NodeTruncateResult{}
 }
 
-var NodeWriteResult_Err_DEFAULT *Error
+var NodeTruncateResult_Success_DEFAULT *TruncateResult_
 
-func (p *NodeWriteResult) GetErr() *Error {
+func (p *NodeTruncateResult) GetSuccess() *TruncateResult_ {
+	if !p.IsSetSuccess() {
+		return NodeTruncateResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeTruncateResult_Err_DEFAULT *Error
+
+func (p *NodeTruncateResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeWriteResult_Err_DEFAULT
+		return NodeTruncateResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeWriteResult) IsSetErr() bool {
+func (p *NodeTruncateResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeTruncateResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeWriteResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeTruncateResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -7889,6 +13036,10 @@ func (p *NodeWriteResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -7908,7 +13059,15 @@ func (p *NodeWriteResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeTruncateResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &TruncateResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeTruncateResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -7918,11 +13077,14 @@ func (p *NodeWriteResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("write_result"); err != nil {
+func (p *NodeTruncateResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("truncate_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -7936,7 +13098,22 @@ func (p *NodeWriteResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeTruncateResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeTruncateResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -7951,36 +13128,36 @@ func (p *NodeWriteResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeWriteResult) String() string {
+func (p *NodeTruncateResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteResult(%+v)", *p)
+	return fmt.Sprintf("NodeTruncateResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeFetchBatchRawArgs struct {
-	Req *FetchBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeDeleteArgs struct {
+	Req *DeleteRequest `thrift:"req,1" db:"req" json:"req"`
 }
-
-func NewNodeFetchBatchRawArgs() *NodeFetchBatchRawArgs {
-	return &NodeFetchBatchRawArgs{}
+
+func NewNodeDeleteArgs() *NodeDeleteArgs {
+	return &NodeDeleteArgs{}
 }
 
-var NodeFetchBatchRawArgs_Req_DEFAULT *FetchBatchRawRequest
+var NodeDeleteArgs_Req_DEFAULT *DeleteRequest
 
-func (p *NodeFetchBatchRawArgs) GetReq() *FetchBatchRawRequest {
+func (p *NodeDeleteArgs) GetReq() *DeleteRequest {
 	if !p.IsSetReq() {
-		return NodeFetchBatchRawArgs_Req_DEFAULT
+		return NodeDeleteArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeFetchBatchRawArgs) IsSetReq() bool {
+func (p *NodeDeleteArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeFetchBatchRawArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeDeleteArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8013,18 +13190,16 @@ func (p *NodeFetchBatchRawArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBatchRawArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchBatchRawRequest{
-		RangeTimeType: 0,
-	}
+func (p *NodeDeleteArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &DeleteRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchBatchRawArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBatchRaw_args"); err != nil {
+func (p *NodeDeleteArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("delete_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8041,7 +13216,7 @@ func (p *NodeFetchBatchRawArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBatchRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeDeleteArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -8054,51 +13229,36 @@ func (p *NodeFetchBatchRawArgs) writeField1(oprot thrift.TProtocol) (err error)
 	return err
 }
 
-func (p *NodeFetchBatchRawArgs) String() string {
+func (p *NodeDeleteArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBatchRawArgs(%+v)", *p)
+	return fmt.Sprintf("NodeDeleteArgs(%+v)", *p)
 }
 
 // Attributes:
-//  - Success
 //  - Err
-type NodeFetchBatchRawResult struct {
-	Success *FetchBatchRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                `thrift:"err,1" db:"err" json:"err,omitempty"`
-}
-
-func NewNodeFetchBatchRawResult() *NodeFetchBatchRawResult {
-	return &NodeFetchBatchRawResult{}
+type NodeDeleteResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-var NodeFetchBatchRawResult_Success_DEFAULT *FetchBatchRawResult_
-
-func (p *NodeFetchBatchRawResult) GetSuccess() *FetchBatchRawResult_ {
-	if !p.IsSetSuccess() {
-		return NodeFetchBatchRawResult_Success_DEFAULT
-	}
-	return p.Success
+func NewNodeDeleteResult() *NodeDeleteResult {
+	return &NodeDeleteResult{}
 }
 
-var NodeFetchBatchRawResult_Err_DEFAULT *Error
+var NodeDeleteResult_Err_DEFAULT *Error
 
-func (p *NodeFetchBatchRawResult) GetErr() *Error {
+func (p *NodeDeleteResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchBatchRawResult_Err_DEFAULT
+		return NodeDeleteResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchBatchRawResult) IsSetSuccess() bool {
-	return p.Success != nil
-}
-
-func (p *NodeFetchBatchRawResult) IsSetErr() bool {
+func (p *NodeDeleteResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchBatchRawResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeDeleteResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8112,10 +13272,6 @@ func (p *NodeFetchBatchRawResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
-		case 0:
-			if err := p.ReadField0(iprot); err != nil {
-				return err
-			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -8135,15 +13291,7 @@ func (p *NodeFetchBatchRawResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBatchRawResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchBatchRawResult_{}
-	if err := p.Success.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
-	}
-	return nil
-}
-
-func (p *NodeFetchBatchRawResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeDeleteResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -8153,14 +13301,11 @@ func (p *NodeFetchBatchRawResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBatchRawResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBatchRaw_result"); err != nil {
+func (p *NodeDeleteResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("delete_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField0(oprot); err != nil {
-			return err
-		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -8174,22 +13319,7 @@ func (p *NodeFetchBatchRawResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBatchRawResult) writeField0(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSuccess() {
-		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
-		}
-		if err := p.Success.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *NodeFetchBatchRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeDeleteResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -8204,36 +13334,36 @@ func (p *NodeFetchBatchRawResult) writeField1(oprot thrift.TProtocol) (err error
 	return err
 }
 
-func (p *NodeFetchBatchRawResult) String() string {
+func (p *NodeDeleteResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBatchRawResult(%+v)", *p)
+	return fmt.Sprintf("NodeDeleteResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeFetchBlocksRawArgs struct {
-	Req *FetchBlocksRawRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeWriteTaggedArgs struct {
+	Req *WriteTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeFetchBlocksRawArgs() *NodeFetchBlocksRawArgs {
-	return &NodeFetchBlocksRawArgs{}
+func NewNodeWriteTaggedArgs() *NodeWriteTaggedArgs {
+	return &NodeWriteTaggedArgs{}
 }
 
-var NodeFetchBlocksRawArgs_Req_DEFAULT *FetchBlocksRawRequest
+var NodeWriteTaggedArgs_Req_DEFAULT *WriteTaggedRequest
 
-func (p *NodeFetchBlocksRawArgs) GetReq() *FetchBlocksRawRequest {
+func (p *NodeWriteTaggedArgs) GetReq() *WriteTaggedRequest {
 	if !p.IsSetReq() {
-		return NodeFetchBlocksRawArgs_Req_DEFAULT
+		return NodeWriteTaggedArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeFetchBlocksRawArgs) IsSetReq() bool {
+func (p *NodeWriteTaggedArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeFetchBlocksRawArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeWriteTaggedArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8266,16 +13396,16 @@ func (p *NodeFetchBlocksRawArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksRawArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchBlocksRawRequest{}
+func (p *NodeWriteTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &WriteTaggedRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchBlocksRawArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBlocksRaw_args"); err != nil {
+func (p *NodeWriteTaggedArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("writeTagged_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8292,7 +13422,7 @@ func (p *NodeFetchBlocksRawArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeWriteTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -8305,51 +13435,36 @@ func (p *NodeFetchBlocksRawArgs) writeField1(oprot thrift.TProtocol) (err error)
 	return err
 }
 
-func (p *NodeFetchBlocksRawArgs) String() string {
+func (p *NodeWriteTaggedArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBlocksRawArgs(%+v)", *p)
+	return fmt.Sprintf("NodeWriteTaggedArgs(%+v)", *p)
 }
 
 // Attributes:
-//  - Success
 //  - Err
-type NodeFetchBlocksRawResult struct {
-	Success *FetchBlocksRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
-}
-
-func NewNodeFetchBlocksRawResult() *NodeFetchBlocksRawResult {
-	return &NodeFetchBlocksRawResult{}
+type NodeWriteTaggedResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-var NodeFetchBlocksRawResult_Success_DEFAULT *FetchBlocksRawResult_
-
-func (p *NodeFetchBlocksRawResult) GetSuccess() *FetchBlocksRawResult_ {
-	if !p.IsSetSuccess() {
-		return NodeFetchBlocksRawResult_Success_DEFAULT
-	}
-	return p.Success
+func NewNodeWriteTaggedResult() *NodeWriteTaggedResult {
+	return &NodeWriteTaggedResult{}
 }
 
-var NodeFetchBlocksRawResult_Err_DEFAULT *Error
+var NodeWriteTaggedResult_Err_DEFAULT *Error
 
-func (p *NodeFetchBlocksRawResult) GetErr() *Error {
+func (p *NodeWriteTaggedResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchBlocksRawResult_Err_DEFAULT
+		return NodeWriteTaggedResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchBlocksRawResult) IsSetSuccess() bool {
-	return p.Success != nil
-}
-
-func (p *NodeFetchBlocksRawResult) IsSetErr() bool {
+func (p *NodeWriteTaggedResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchBlocksRawResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeWriteTaggedResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8363,10 +13478,6 @@ func (p *NodeFetchBlocksRawResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
-		case 0:
-			if err := p.ReadField0(iprot); err != nil {
-				return err
-			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -8386,15 +13497,7 @@ func (p *NodeFetchBlocksRawResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksRawResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchBlocksRawResult_{}
-	if err := p.Success.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
-	}
-	return nil
-}
-
-func (p *NodeFetchBlocksRawResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeWriteTaggedResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -8404,14 +13507,11 @@ func (p *NodeFetchBlocksRawResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksRawResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBlocksRaw_result"); err != nil {
+func (p *NodeWriteTaggedResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("writeTagged_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField0(oprot); err != nil {
-			return err
-		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -8425,22 +13525,7 @@ func (p *NodeFetchBlocksRawResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksRawResult) writeField0(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSuccess() {
-		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
-		}
-		if err := p.Success.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *NodeFetchBlocksRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeWriteTaggedResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -8455,36 +13540,36 @@ func (p *NodeFetchBlocksRawResult) writeField1(oprot thrift.TProtocol) (err erro
 	return err
 }
 
-func (p *NodeFetchBlocksRawResult) String() string {
+func (p *NodeWriteTaggedResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBlocksRawResult(%+v)", *p)
+	return fmt.Sprintf("NodeWriteTaggedResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeFetchBlocksMetadataRawArgs struct {
-	Req *FetchBlocksMetadataRawRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeFetchTaggedArgs struct {
+	Req *FetchTaggedRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeFetchBlocksMetadataRawArgs() *NodeFetchBlocksMetadataRawArgs {
-	return &NodeFetchBlocksMetadataRawArgs{}
+func NewNodeFetchTaggedArgs() *NodeFetchTaggedArgs {
+	return &NodeFetchTaggedArgs{}
 }
 
-var NodeFetchBlocksMetadataRawArgs_Req_DEFAULT *FetchBlocksMetadataRawRequest
+var NodeFetchTaggedArgs_Req_DEFAULT *FetchTaggedRequest
 
-func (p *NodeFetchBlocksMetadataRawArgs) GetReq() *FetchBlocksMetadataRawRequest {
+func (p *NodeFetchTaggedArgs) GetReq() *FetchTaggedRequest {
 	if !p.IsSetReq() {
-		return NodeFetchBlocksMetadataRawArgs_Req_DEFAULT
+		return NodeFetchTaggedArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeFetchBlocksMetadataRawArgs) IsSetReq() bool {
+func (p *NodeFetchTaggedArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeFetchBlocksMetadataRawArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8517,16 +13602,16 @@ func (p *NodeFetchBlocksMetadataRawArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &FetchBlocksMetadataRawRequest{}
+func (p *NodeFetchTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchTaggedRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBlocksMetadataRaw_args"); err != nil {
+func (p *NodeFetchTaggedArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8543,7 +13628,7 @@ func (p *NodeFetchBlocksMetadataRawArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -8556,51 +13641,51 @@ func (p *NodeFetchBlocksMetadataRawArgs) writeField1(oprot thrift.TProtocol) (er
 	return err
 }
 
-func (p *NodeFetchBlocksMetadataRawArgs) String() string {
+func (p *NodeFetchTaggedArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBlocksMetadataRawArgs(%+v)", *p)
+	return fmt.Sprintf("NodeFetchTaggedArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeFetchBlocksMetadataRawResult struct {
-	Success *FetchBlocksMetadataRawResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                         `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeFetchTaggedResult struct {
+	Success *FetchTaggedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeFetchBlocksMetadataRawResult() *NodeFetchBlocksMetadataRawResult {
-	return &NodeFetchBlocksMetadataRawResult{}
+func NewNodeFetchTaggedResult() *NodeFetchTaggedResult {
+	return &NodeFetchTaggedResult{}
 }
 
-var NodeFetchBlocksMetadataRawResult_Success_DEFAULT *FetchBlocksMetadataRawResult_
+var NodeFetchTaggedResult_Success_DEFAULT *FetchTaggedResult_
 
-func (p *NodeFetchBlocksMetadataRawResult) GetSuccess() *FetchBlocksMetadataRawResult_ {
+func (p *NodeFetchTaggedResult) GetSuccess() *FetchTaggedResult_ {
 	if !p.IsSetSuccess() {
-		return NodeFetchBlocksMetadataRawResult_Success_DEFAULT
+		return NodeFetchTaggedResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeFetchBlocksMetadataRawResult_Err_DEFAULT *Error
+var NodeFetchTaggedResult_Err_DEFAULT *Error
 
-func (p *NodeFetchBlocksMetadataRawResult) GetErr() *Error {
+func (p *NodeFetchTaggedResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeFetchBlocksMetadataRawResult_Err_DEFAULT
+		return NodeFetchTaggedResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeFetchBlocksMetadataRawResult) IsSetSuccess() bool {
+func (p *NodeFetchTaggedResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) IsSetErr() bool {
+func (p *NodeFetchTaggedResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8637,15 +13722,15 @@ func (p *NodeFetchBlocksMetadataRawResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &FetchBlocksMetadataRawResult_{}
+func (p *NodeFetchTaggedResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchTaggedResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeFetchTaggedResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -8655,8 +13740,8 @@ func (p *NodeFetchBlocksMetadataRawResult) ReadField1(iprot thrift.TProtocol) er
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("fetchBlocksMetadataRaw_result"); err != nil {
+func (p *NodeFetchTaggedResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8676,7 +13761,7 @@ func (p *NodeFetchBlocksMetadataRawResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -8691,7 +13776,7 @@ func (p *NodeFetchBlocksMetadataRawResult) writeField0(oprot thrift.TProtocol) (
 	return err
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeFetchTaggedResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -8706,36 +13791,21 @@ func (p *NodeFetchBlocksMetadataRawResult) writeField1(oprot thrift.TProtocol) (
 	return err
 }
 
-func (p *NodeFetchBlocksMetadataRawResult) String() string {
+func (p *NodeFetchTaggedResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeFetchBlocksMetadataRawResult(%+v)", *p)
+	return fmt.Sprintf("NodeFetchTaggedResult(%+v)", *p)
 }
 
-// Attributes:
-//  - Req
-type NodeWriteBatchRawArgs struct {
-	Req *WriteBatchRawRequest `thrift:"req,1" db:"req" json:"req"`
-}
-
-func NewNodeWriteBatchRawArgs() *NodeWriteBatchRawArgs {
-	return &NodeWriteBatchRawArgs{}
+type NodeHealthArgs struct {
 }
 
-var NodeWriteBatchRawArgs_Req_DEFAULT *WriteBatchRawRequest
-
-func (p *NodeWriteBatchRawArgs) GetReq() *WriteBatchRawRequest {
-	if !p.IsSetReq() {
-		return NodeWriteBatchRawArgs_Req_DEFAULT
-	}
-	return p.Req
-}
-func (p *NodeWriteBatchRawArgs) IsSetReq() bool {
-	return p.Req != nil
+func NewNodeHealthArgs() *NodeHealthArgs {
+	return &NodeHealthArgs{}
 }
 
-func (p *NodeWriteBatchRawArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeHealthArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8748,15 +13818,8 @@ func (p *NodeWriteBatchRawArgs) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
+		if err := iprot.Skip(fieldTypeId); err != nil {
+			return err
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -8768,75 +13831,66 @@ func (p *NodeWriteBatchRawArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteBatchRawArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &WriteBatchRawRequest{}
-	if err := p.Req.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
-	}
-	return nil
-}
-
-func (p *NodeWriteBatchRawArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("writeBatchRaw_args"); err != nil {
+func (p *NodeHealthArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("health_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
 	}
-	if err := oprot.WriteStructEnd(); err != nil {
-		return thrift.PrependError("write struct stop error: ", err)
-	}
-	return nil
-}
-
-func (p *NodeWriteBatchRawArgs) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
-	}
-	if err := p.Req.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
 	}
-	return err
+	return nil
 }
 
-func (p *NodeWriteBatchRawArgs) String() string {
+func (p *NodeHealthArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteBatchRawArgs(%+v)", *p)
+	return fmt.Sprintf("NodeHealthArgs(%+v)", *p)
 }
 
 // Attributes:
+//  - Success
 //  - Err
-type NodeWriteBatchRawResult struct {
-	Err *WriteBatchRawErrors `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeHealthResult struct {
+	Success *NodeHealthResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error             `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeWriteBatchRawResult() *NodeWriteBatchRawResult {
-	return &NodeWriteBatchRawResult{}
+func NewNodeHealthResult() *NodeHealthResult {
+	return &NodeHealthResult{}
 }
 
-var NodeWriteBatchRawResult_Err_DEFAULT *WriteBatchRawErrors
+var NodeHealthResult_Success_DEFAULT *NodeHealthResult_
 
-func (p *NodeWriteBatchRawResult) GetErr() *WriteBatchRawErrors {
+func (p *NodeHealthResult) GetSuccess() *NodeHealthResult_ {
+	if !p.IsSetSuccess() {
+		return NodeHealthResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeHealthResult_Err_DEFAULT *Error
+
+func (p *NodeHealthResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeWriteBatchRawResult_Err_DEFAULT
+		return NodeHealthResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeWriteBatchRawResult) IsSetErr() bool {
+func (p *NodeHealthResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeHealthResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeWriteBatchRawResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeHealthResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8850,6 +13904,10 @@ func (p *NodeWriteBatchRawResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -8869,19 +13927,32 @@ func (p *NodeWriteBatchRawResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteBatchRawResult) ReadField1(iprot thrift.TProtocol) error {
-	p.Err = &WriteBatchRawErrors{}
+func (p *NodeHealthResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodeHealthResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeHealthResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
 	if err := p.Err.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
 	}
 	return nil
 }
 
-func (p *NodeWriteBatchRawResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("writeBatchRaw_result"); err != nil {
+func (p *NodeHealthResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("health_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -8895,7 +13966,22 @@ func (p *NodeWriteBatchRawResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeWriteBatchRawResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeHealthResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeHealthResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -8910,21 +13996,21 @@ func (p *NodeWriteBatchRawResult) writeField1(oprot thrift.TProtocol) (err error
 	return err
 }
 
-func (p *NodeWriteBatchRawResult) String() string {
+func (p *NodeHealthResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeWriteBatchRawResult(%+v)", *p)
+	return fmt.Sprintf("NodeHealthResult(%+v)", *p)
 }
 
-type NodeRepairArgs struct {
+type NodeGetPersistRateLimitArgs struct {
 }
 
-func NewNodeRepairArgs() *NodeRepairArgs {
-	return &NodeRepairArgs{}
+func NewNodeGetPersistRateLimitArgs() *NodeGetPersistRateLimitArgs {
+	return &NodeGetPersistRateLimitArgs{}
 }
 
-func (p *NodeRepairArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -8950,8 +14036,8 @@ func (p *NodeRepairArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeRepairArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("repair_args"); err != nil {
+func (p *NodeGetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getPersistRateLimit_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -8965,36 +14051,51 @@ func (p *NodeRepairArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeRepairArgs) String() string {
+func (p *NodeGetPersistRateLimitArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeRepairArgs(%+v)", *p)
+	return fmt.Sprintf("NodeGetPersistRateLimitArgs(%+v)", *p)
 }
 
 // Attributes:
+//  - Success
 //  - Err
-type NodeRepairResult struct {
-	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeGetPersistRateLimitResult struct {
+	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeRepairResult() *NodeRepairResult {
-	return &NodeRepairResult{}
+func NewNodeGetPersistRateLimitResult() *NodeGetPersistRateLimitResult {
+	return &NodeGetPersistRateLimitResult{}
 }
 
-var NodeRepairResult_Err_DEFAULT *Error
+var NodeGetPersistRateLimitResult_Success_DEFAULT *NodePersistRateLimitResult_
 
-func (p *NodeRepairResult) GetErr() *Error {
+func (p *NodeGetPersistRateLimitResult) GetSuccess() *NodePersistRateLimitResult_ {
+	if !p.IsSetSuccess() {
+		return NodeGetPersistRateLimitResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var NodeGetPersistRateLimitResult_Err_DEFAULT *Error
+
+func (p *NodeGetPersistRateLimitResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeRepairResult_Err_DEFAULT
+		return NodeGetPersistRateLimitResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeRepairResult) IsSetErr() bool {
+func (p *NodeGetPersistRateLimitResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *NodeGetPersistRateLimitResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeRepairResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9008,6 +14109,10 @@ func (p *NodeRepairResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -9027,7 +14132,15 @@ func (p *NodeRepairResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeRepairResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeGetPersistRateLimitResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodePersistRateLimitResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *NodeGetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -9037,11 +14150,14 @@ func (p *NodeRepairResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeRepairResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("repair_result"); err != nil {
+func (p *NodeGetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getPersistRateLimit_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -9055,7 +14171,22 @@ func (p *NodeRepairResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeRepairResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeGetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *NodeGetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -9070,36 +14201,36 @@ func (p *NodeRepairResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeRepairResult) String() string {
+func (p *NodeGetPersistRateLimitResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeRepairResult(%+v)", *p)
+	return fmt.Sprintf("NodeGetPersistRateLimitResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeTruncateArgs struct {
-	Req *TruncateRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeSetPersistRateLimitArgs struct {
+	Req *NodeSetPersistRateLimitRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeTruncateArgs() *NodeTruncateArgs {
-	return &NodeTruncateArgs{}
+func NewNodeSetPersistRateLimitArgs() *NodeSetPersistRateLimitArgs {
+	return &NodeSetPersistRateLimitArgs{}
 }
 
-var NodeTruncateArgs_Req_DEFAULT *TruncateRequest
+var NodeSetPersistRateLimitArgs_Req_DEFAULT *NodeSetPersistRateLimitRequest
 
-func (p *NodeTruncateArgs) GetReq() *TruncateRequest {
+func (p *NodeSetPersistRateLimitArgs) GetReq() *NodeSetPersistRateLimitRequest {
 	if !p.IsSetReq() {
-		return NodeTruncateArgs_Req_DEFAULT
+		return NodeSetPersistRateLimitArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeTruncateArgs) IsSetReq() bool {
+func (p *NodeSetPersistRateLimitArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeTruncateArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeSetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9132,16 +14263,16 @@ func (p *NodeTruncateArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeTruncateArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &TruncateRequest{}
+func (p *NodeSetPersistRateLimitArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &NodeSetPersistRateLimitRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeTruncateArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("truncate_args"); err != nil {
+func (p *NodeSetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("setPersistRateLimit_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9158,7 +14289,7 @@ func (p *NodeTruncateArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeTruncateArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeSetPersistRateLimitArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -9171,51 +14302,51 @@ func (p *NodeTruncateArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeTruncateArgs) String() string {
+func (p *NodeSetPersistRateLimitArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeTruncateArgs(%+v)", *p)
+	return fmt.Sprintf("NodeSetPersistRateLimitArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeTruncateResult struct {
-	Success *TruncateResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error           `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeSetPersistRateLimitResult struct {
+	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeTruncateResult() *NodeTruncateResult {
-	return &NodeTruncateResult{}
+func NewNodeSetPersistRateLimitResult() *NodeSetPersistRateLimitResult {
+	return &NodeSetPersistRateLimitResult{}
 }
 
-var NodeTruncateResult_Success_DEFAULT *TruncateResult_
+var NodeSetPersistRateLimitResult_Success_DEFAULT *NodePersistRateLimitResult_
 
-func (p *NodeTruncateResult) GetSuccess() *TruncateResult_ {
+func (p *NodeSetPersistRateLimitResult) GetSuccess() *NodePersistRateLimitResult_ {
 	if !p.IsSetSuccess() {
-		return NodeTruncateResult_Success_DEFAULT
+		return NodeSetPersistRateLimitResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeTruncateResult_Err_DEFAULT *Error
+var NodeSetPersistRateLimitResult_Err_DEFAULT *Error
 
-func (p *NodeTruncateResult) GetErr() *Error {
+func (p *NodeSetPersistRateLimitResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeTruncateResult_Err_DEFAULT
+		return NodeSetPersistRateLimitResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeTruncateResult) IsSetSuccess() bool {
+func (p *NodeSetPersistRateLimitResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeTruncateResult) IsSetErr() bool {
+func (p *NodeSetPersistRateLimitResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeTruncateResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeSetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9252,15 +14383,15 @@ func (p *NodeTruncateResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeTruncateResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &TruncateResult_{}
+func (p *NodeSetPersistRateLimitResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodePersistRateLimitResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeTruncateResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeSetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -9270,8 +14401,8 @@ func (p *NodeTruncateResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeTruncateResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("truncate_result"); err != nil {
+func (p *NodeSetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("setPersistRateLimit_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9291,7 +14422,7 @@ func (p *NodeTruncateResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeTruncateResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeSetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -9306,7 +14437,7 @@ func (p *NodeTruncateResult) writeField0(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeTruncateResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeSetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -9321,21 +14452,21 @@ func (p *NodeTruncateResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeTruncateResult) String() string {
+func (p *NodeSetPersistRateLimitResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeTruncateResult(%+v)", *p)
+	return fmt.Sprintf("NodeSetPersistRateLimitResult(%+v)", *p)
 }
 
-type NodeHealthArgs struct {
+type NodeGetWriteNewSeriesAsyncArgs struct {
 }
 
-func NewNodeHealthArgs() *NodeHealthArgs {
-	return &NodeHealthArgs{}
+func NewNodeGetWriteNewSeriesAsyncArgs() *NodeGetWriteNewSeriesAsyncArgs {
+	return &NodeGetWriteNewSeriesAsyncArgs{}
 }
 
-func (p *NodeHealthArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9361,8 +14492,8 @@ func (p *NodeHealthArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("health_args"); err != nil {
+func (p *NodeGetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getWriteNewSeriesAsync_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9376,51 +14507,51 @@ func (p *NodeHealthArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthArgs) String() string {
+func (p *NodeGetWriteNewSeriesAsyncArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeHealthArgs(%+v)", *p)
+	return fmt.Sprintf("NodeGetWriteNewSeriesAsyncArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeHealthResult struct {
-	Success *NodeHealthResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error             `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeGetWriteNewSeriesAsyncResult struct {
+	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeHealthResult() *NodeHealthResult {
-	return &NodeHealthResult{}
+func NewNodeGetWriteNewSeriesAsyncResult() *NodeGetWriteNewSeriesAsyncResult {
+	return &NodeGetWriteNewSeriesAsyncResult{}
 }
 
-var NodeHealthResult_Success_DEFAULT *NodeHealthResult_
+var NodeGetWriteNewSeriesAsyncResult_Success_DEFAULT *NodeWriteNewSeriesAsyncResult_
 
-func (p *NodeHealthResult) GetSuccess() *NodeHealthResult_ {
+func (p *NodeGetWriteNewSeriesAsyncResult) GetSuccess() *NodeWriteNewSeriesAsyncResult_ {
 	if !p.IsSetSuccess() {
-		return NodeHealthResult_Success_DEFAULT
+		return NodeGetWriteNewSeriesAsyncResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeHealthResult_Err_DEFAULT *Error
+var NodeGetWriteNewSeriesAsyncResult_Err_DEFAULT *Error
 
-func (p *NodeHealthResult) GetErr() *Error {
+func (p *NodeGetWriteNewSeriesAsyncResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeHealthResult_Err_DEFAULT
+		return NodeGetWriteNewSeriesAsyncResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeHealthResult) IsSetSuccess() bool {
+func (p *NodeGetWriteNewSeriesAsyncResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeHealthResult) IsSetErr() bool {
+func (p *NodeGetWriteNewSeriesAsyncResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeHealthResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9457,15 +14588,15 @@ func (p *NodeHealthResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &NodeHealthResult_{}
+func (p *NodeGetWriteNewSeriesAsyncResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodeWriteNewSeriesAsyncResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeHealthResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeGetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -9475,8 +14606,8 @@ func (p *NodeHealthResult) ReadField1(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("health_result"); err != nil {
+func (p *NodeGetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getWriteNewSeriesAsync_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9496,7 +14627,7 @@ func (p *NodeHealthResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeHealthResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeGetWriteNewSeriesAsyncResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -9511,7 +14642,7 @@ func (p *NodeHealthResult) writeField0(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeHealthResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeGetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -9526,21 +14657,36 @@ func (p *NodeHealthResult) writeField1(oprot thrift.TProtocol) (err error) {
 	return err
 }
 
-func (p *NodeHealthResult) String() string {
+func (p *NodeGetWriteNewSeriesAsyncResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeHealthResult(%+v)", *p)
+	return fmt.Sprintf("NodeGetWriteNewSeriesAsyncResult(%+v)", *p)
 }
 
-type NodeGetPersistRateLimitArgs struct {
+// Attributes:
+//  - Req
+type NodeSetWriteNewSeriesAsyncArgs struct {
+	Req *NodeSetWriteNewSeriesAsyncRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeGetPersistRateLimitArgs() *NodeGetPersistRateLimitArgs {
-	return &NodeGetPersistRateLimitArgs{}
+func NewNodeSetWriteNewSeriesAsyncArgs() *NodeSetWriteNewSeriesAsyncArgs {
+	return &NodeSetWriteNewSeriesAsyncArgs{}
 }
 
-func (p *NodeGetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
+var NodeSetWriteNewSeriesAsyncArgs_Req_DEFAULT *NodeSetWriteNewSeriesAsyncRequest
+
+func (p *NodeSetWriteNewSeriesAsyncArgs) GetReq() *NodeSetWriteNewSeriesAsyncRequest {
+	if !p.IsSetReq() {
+		return NodeSetWriteNewSeriesAsyncArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeSetWriteNewSeriesAsyncArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeSetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9553,8 +14699,15 @@ func (p *NodeGetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		if err := iprot.Skip(fieldTypeId); err != nil {
-			return err
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -9566,11 +14719,22 @@ func (p *NodeGetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("getPersistRateLimit_args"); err != nil {
+func (p *NodeSetWriteNewSeriesAsyncArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &NodeSetWriteNewSeriesAsyncRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeSetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("setWriteNewSeriesAsync_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9581,51 +14745,64 @@ func (p *NodeGetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitArgs) String() string {
+func (p *NodeSetWriteNewSeriesAsyncArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeSetWriteNewSeriesAsyncArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeGetPersistRateLimitArgs(%+v)", *p)
+	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeGetPersistRateLimitResult struct {
-	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeSetWriteNewSeriesAsyncResult struct {
+	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeGetPersistRateLimitResult() *NodeGetPersistRateLimitResult {
-	return &NodeGetPersistRateLimitResult{}
+func NewNodeSetWriteNewSeriesAsyncResult() *NodeSetWriteNewSeriesAsyncResult {
+	return &NodeSetWriteNewSeriesAsyncResult{}
 }
 
-var NodeGetPersistRateLimitResult_Success_DEFAULT *NodePersistRateLimitResult_
+var NodeSetWriteNewSeriesAsyncResult_Success_DEFAULT *NodeWriteNewSeriesAsyncResult_
 
-func (p *NodeGetPersistRateLimitResult) GetSuccess() *NodePersistRateLimitResult_ {
+func (p *NodeSetWriteNewSeriesAsyncResult) GetSuccess() *NodeWriteNewSeriesAsyncResult_ {
 	if !p.IsSetSuccess() {
-		return NodeGetPersistRateLimitResult_Success_DEFAULT
+		return NodeSetWriteNewSeriesAsyncResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeGetPersistRateLimitResult_Err_DEFAULT *Error
+var NodeSetWriteNewSeriesAsyncResult_Err_DEFAULT *Error
 
-func (p *NodeGetPersistRateLimitResult) GetErr() *Error {
+func (p *NodeSetWriteNewSeriesAsyncResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeGetPersistRateLimitResult_Err_DEFAULT
+		return NodeSetWriteNewSeriesAsyncResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeGetPersistRateLimitResult) IsSetSuccess() bool {
+func (p *NodeSetWriteNewSeriesAsyncResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeGetPersistRateLimitResult) IsSetErr() bool {
+func (p *NodeSetWriteNewSeriesAsyncResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeGetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeSetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9662,15 +14839,15 @@ func (p *NodeGetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &NodePersistRateLimitResult_{}
+func (p *NodeSetWriteNewSeriesAsyncResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodeWriteNewSeriesAsyncResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeSetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -9680,8 +14857,8 @@ func (p *NodeGetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("getPersistRateLimit_result"); err != nil {
+func (p *NodeSetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("setWriteNewSeriesAsync_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9701,7 +14878,7 @@ func (p *NodeGetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeSetWriteNewSeriesAsyncResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -9716,7 +14893,7 @@ func (p *NodeGetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *NodeGetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeSetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -9731,36 +14908,21 @@ func (p *NodeGetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *NodeGetPersistRateLimitResult) String() string {
+func (p *NodeSetWriteNewSeriesAsyncResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeGetPersistRateLimitResult(%+v)", *p)
-}
-
-// Attributes:
-//  - Req
-type NodeSetPersistRateLimitArgs struct {
-	Req *NodeSetPersistRateLimitRequest `thrift:"req,1" db:"req" json:"req"`
+	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncResult(%+v)", *p)
 }
 
-func NewNodeSetPersistRateLimitArgs() *NodeSetPersistRateLimitArgs {
-	return &NodeSetPersistRateLimitArgs{}
+type NodeGetNamespacesArgs struct {
 }
 
-var NodeSetPersistRateLimitArgs_Req_DEFAULT *NodeSetPersistRateLimitRequest
-
-func (p *NodeSetPersistRateLimitArgs) GetReq() *NodeSetPersistRateLimitRequest {
-	if !p.IsSetReq() {
-		return NodeSetPersistRateLimitArgs_Req_DEFAULT
-	}
-	return p.Req
-}
-func (p *NodeSetPersistRateLimitArgs) IsSetReq() bool {
-	return p.Req != nil
+func NewNodeGetNamespacesArgs() *NodeGetNamespacesArgs {
+	return &NodeGetNamespacesArgs{}
 }
 
-func (p *NodeSetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetNamespacesArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9773,15 +14935,8 @@ func (p *NodeSetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		switch fieldId {
-		case 1:
-			if err := p.ReadField1(iprot); err != nil {
-				return err
-			}
-		default:
-			if err := iprot.Skip(fieldTypeId); err != nil {
-				return err
-			}
+		if err := iprot.Skip(fieldTypeId); err != nil {
+			return err
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -9793,22 +14948,11 @@ func (p *NodeSetPersistRateLimitArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &NodeSetPersistRateLimitRequest{}
-	if err := p.Req.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
-	}
-	return nil
-}
-
-func (p *NodeSetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("setPersistRateLimit_args"); err != nil {
+func (p *NodeGetNamespacesArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getNamespaces_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField1(oprot); err != nil {
-			return err
-		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -9819,64 +14963,51 @@ func (p *NodeSetPersistRateLimitArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitArgs) writeField1(oprot thrift.TProtocol) (err error) {
-	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
-	}
-	if err := p.Req.Write(oprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
-	}
-	if err := oprot.WriteFieldEnd(); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
-	}
-	return err
-}
-
-func (p *NodeSetPersistRateLimitArgs) String() string {
+func (p *NodeGetNamespacesArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetPersistRateLimitArgs(%+v)", *p)
+	return fmt.Sprintf("NodeGetNamespacesArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
 //  - Err
-type NodeSetPersistRateLimitResult struct {
-	Success *NodePersistRateLimitResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                       `thrift:"err,1" db:"err" json:"err,omitempty"`
+type NodeGetNamespacesResult struct {
+	Success *NodeNamespacesResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error                 `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-func NewNodeSetPersistRateLimitResult() *NodeSetPersistRateLimitResult {
-	return &NodeSetPersistRateLimitResult{}
+func NewNodeGetNamespacesResult() *NodeGetNamespacesResult {
+	return &NodeGetNamespacesResult{}
 }
 
-var NodeSetPersistRateLimitResult_Success_DEFAULT *NodePersistRateLimitResult_
+var NodeGetNamespacesResult_Success_DEFAULT *NodeNamespacesResult_
 
-func (p *NodeSetPersistRateLimitResult) GetSuccess() *NodePersistRateLimitResult_ {
+func (p *NodeGetNamespacesResult) GetSuccess() *NodeNamespacesResult_ {
 	if !p.IsSetSuccess() {
-		return NodeSetPersistRateLimitResult_Success_DEFAULT
+		return NodeGetNamespacesResult_Success_DEFAULT
 	}
 	return p.Success
 }
 
-var NodeSetPersistRateLimitResult_Err_DEFAULT *Error
+var NodeGetNamespacesResult_Err_DEFAULT *Error
 
-func (p *NodeSetPersistRateLimitResult) GetErr() *Error {
+func (p *NodeGetNamespacesResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeSetPersistRateLimitResult_Err_DEFAULT
+		return NodeGetNamespacesResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeSetPersistRateLimitResult) IsSetSuccess() bool {
+func (p *NodeGetNamespacesResult) IsSetSuccess() bool {
 	return p.Success != nil
 }
 
-func (p *NodeSetPersistRateLimitResult) IsSetErr() bool {
+func (p *NodeGetNamespacesResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeSetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeGetNamespacesResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -9913,15 +15044,15 @@ func (p *NodeSetPersistRateLimitResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &NodePersistRateLimitResult_{}
+func (p *NodeGetNamespacesResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &NodeNamespacesResult_{}
 	if err := p.Success.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
 	}
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeGetNamespacesResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -9931,8 +15062,8 @@ func (p *NodeSetPersistRateLimitResult) ReadField1(iprot thrift.TProtocol) error
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("setPersistRateLimit_result"); err != nil {
+func (p *NodeGetNamespacesResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("getNamespaces_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -9952,7 +15083,7 @@ func (p *NodeSetPersistRateLimitResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err error) {
+func (p *NodeGetNamespacesResult) writeField0(oprot thrift.TProtocol) (err error) {
 	if p.IsSetSuccess() {
 		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
@@ -9967,7 +15098,7 @@ func (p *NodeSetPersistRateLimitResult) writeField0(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *NodeSetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeGetNamespacesResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -9982,21 +15113,36 @@ func (p *NodeSetPersistRateLimitResult) writeField1(oprot thrift.TProtocol) (err
 	return err
 }
 
-func (p *NodeSetPersistRateLimitResult) String() string {
+func (p *NodeGetNamespacesResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetPersistRateLimitResult(%+v)", *p)
+	return fmt.Sprintf("NodeGetNamespacesResult(%+v)", *p)
 }
 
-type NodeGetWriteNewSeriesAsyncArgs struct {
+// Attributes:
+//  - Req
+type NodeAddNamespaceArgs struct {
+	Req *NodeAddNamespaceRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeGetWriteNewSeriesAsyncArgs() *NodeGetWriteNewSeriesAsyncArgs {
-	return &NodeGetWriteNewSeriesAsyncArgs{}
+func NewNodeAddNamespaceArgs() *NodeAddNamespaceArgs {
+	return &NodeAddNamespaceArgs{}
 }
 
-func (p *NodeGetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
+var NodeAddNamespaceArgs_Req_DEFAULT *NodeAddNamespaceRequest
+
+func (p *NodeAddNamespaceArgs) GetReq() *NodeAddNamespaceRequest {
+	if !p.IsSetReq() {
+		return NodeAddNamespaceArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *NodeAddNamespaceArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *NodeAddNamespaceArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -10009,8 +15155,15 @@ func (p *NodeGetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
 		if fieldTypeId == thrift.STOP {
 			break
 		}
-		if err := iprot.Skip(fieldTypeId); err != nil {
-			return err
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
 		}
 		if err := iprot.ReadFieldEnd(); err != nil {
 			return err
@@ -10022,11 +15175,22 @@ func (p *NodeGetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("getWriteNewSeriesAsync_args"); err != nil {
+func (p *NodeAddNamespaceArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &NodeAddNamespaceRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *NodeAddNamespaceArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("addNamespace_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
 	}
 	if err := oprot.WriteFieldStop(); err != nil {
 		return thrift.PrependError("write field stop error: ", err)
@@ -10037,51 +15201,49 @@ func (p *NodeGetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncArgs) String() string {
+func (p *NodeAddNamespaceArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *NodeAddNamespaceArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeGetWriteNewSeriesAsyncArgs(%+v)", *p)
+	return fmt.Sprintf("NodeAddNamespaceArgs(%+v)", *p)
 }
 
 // Attributes:
-//  - Success
 //  - Err
-type NodeGetWriteNewSeriesAsyncResult struct {
-	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
-}
-
-func NewNodeGetWriteNewSeriesAsyncResult() *NodeGetWriteNewSeriesAsyncResult {
-	return &NodeGetWriteNewSeriesAsyncResult{}
+type NodeAddNamespaceResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-var NodeGetWriteNewSeriesAsyncResult_Success_DEFAULT *NodeWriteNewSeriesAsyncResult_
-
-func (p *NodeGetWriteNewSeriesAsyncResult) GetSuccess() *NodeWriteNewSeriesAsyncResult_ {
-	if !p.IsSetSuccess() {
-		return NodeGetWriteNewSeriesAsyncResult_Success_DEFAULT
-	}
-	return p.Success
+func NewNodeAddNamespaceResult() *NodeAddNamespaceResult {
+	return &NodeAddNamespaceResult{}
 }
 
-var NodeGetWriteNewSeriesAsyncResult_Err_DEFAULT *Error
+var NodeAddNamespaceResult_Err_DEFAULT *Error
 
-func (p *NodeGetWriteNewSeriesAsyncResult) GetErr() *Error {
+func (p *NodeAddNamespaceResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeGetWriteNewSeriesAsyncResult_Err_DEFAULT
+		return NodeAddNamespaceResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeGetWriteNewSeriesAsyncResult) IsSetSuccess() bool {
-	return p.Success != nil
-}
-
-func (p *NodeGetWriteNewSeriesAsyncResult) IsSetErr() bool {
+func (p *NodeAddNamespaceResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeAddNamespaceResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -10095,10 +15257,6 @@ func (p *NodeGetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
-		case 0:
-			if err := p.ReadField0(iprot); err != nil {
-				return err
-			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -10118,15 +15276,7 @@ func (p *NodeGetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &NodeWriteNewSeriesAsyncResult_{}
-	if err := p.Success.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
-	}
-	return nil
-}
-
-func (p *NodeGetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeAddNamespaceResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -10136,14 +15286,11 @@ func (p *NodeGetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) er
 	return nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("getWriteNewSeriesAsync_result"); err != nil {
+func (p *NodeAddNamespaceResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("addNamespace_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField0(oprot); err != nil {
-			return err
-		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -10157,22 +15304,7 @@ func (p *NodeGetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeGetWriteNewSeriesAsyncResult) writeField0(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSuccess() {
-		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
-		}
-		if err := p.Success.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *NodeGetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeAddNamespaceResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -10187,36 +15319,36 @@ func (p *NodeGetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (
 	return err
 }
 
-func (p *NodeGetWriteNewSeriesAsyncResult) String() string {
+func (p *NodeAddNamespaceResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeGetWriteNewSeriesAsyncResult(%+v)", *p)
+	return fmt.Sprintf("NodeAddNamespaceResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Req
-type NodeSetWriteNewSeriesAsyncArgs struct {
-	Req *NodeSetWriteNewSeriesAsyncRequest `thrift:"req,1" db:"req" json:"req"`
+type NodeRemoveNamespaceArgs struct {
+	Req *NodeRemoveNamespaceRequest `thrift:"req,1" db:"req" json:"req"`
 }
 
-func NewNodeSetWriteNewSeriesAsyncArgs() *NodeSetWriteNewSeriesAsyncArgs {
-	return &NodeSetWriteNewSeriesAsyncArgs{}
+func NewNodeRemoveNamespaceArgs() *NodeRemoveNamespaceArgs {
+	return &NodeRemoveNamespaceArgs{}
 }
 
-var NodeSetWriteNewSeriesAsyncArgs_Req_DEFAULT *NodeSetWriteNewSeriesAsyncRequest
+var NodeRemoveNamespaceArgs_Req_DEFAULT *NodeRemoveNamespaceRequest
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) GetReq() *NodeSetWriteNewSeriesAsyncRequest {
+func (p *NodeRemoveNamespaceArgs) GetReq() *NodeRemoveNamespaceRequest {
 	if !p.IsSetReq() {
-		return NodeSetWriteNewSeriesAsyncArgs_Req_DEFAULT
+		return NodeRemoveNamespaceArgs_Req_DEFAULT
 	}
 	return p.Req
 }
-func (p *NodeSetWriteNewSeriesAsyncArgs) IsSetReq() bool {
+func (p *NodeRemoveNamespaceArgs) IsSetReq() bool {
 	return p.Req != nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
+func (p *NodeRemoveNamespaceArgs) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -10249,16 +15381,16 @@ func (p *NodeSetWriteNewSeriesAsyncArgs) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) ReadField1(iprot thrift.TProtocol) error {
-	p.Req = &NodeSetWriteNewSeriesAsyncRequest{}
+func (p *NodeRemoveNamespaceArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &NodeRemoveNamespaceRequest{}
 	if err := p.Req.Read(iprot); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
 	}
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("setWriteNewSeriesAsync_args"); err != nil {
+func (p *NodeRemoveNamespaceArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("removeNamespace_args"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
@@ -10275,7 +15407,7 @@ func (p *NodeSetWriteNewSeriesAsyncArgs) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeRemoveNamespaceArgs) writeField1(oprot thrift.TProtocol) (err error) {
 	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
 	}
@@ -10288,51 +15420,36 @@ func (p *NodeSetWriteNewSeriesAsyncArgs) writeField1(oprot thrift.TProtocol) (er
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesAsyncArgs) String() string {
+func (p *NodeRemoveNamespaceArgs) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncArgs(%+v)", *p)
+	return fmt.Sprintf("NodeRemoveNamespaceArgs(%+v)", *p)
 }
 
 // Attributes:
-//  - Success
 //  - Err
-type NodeSetWriteNewSeriesAsyncResult struct {
-	Success *NodeWriteNewSeriesAsyncResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
-	Err     *Error                          `thrift:"err,1" db:"err" json:"err,omitempty"`
-}
-
-func NewNodeSetWriteNewSeriesAsyncResult() *NodeSetWriteNewSeriesAsyncResult {
-	return &NodeSetWriteNewSeriesAsyncResult{}
+type NodeRemoveNamespaceResult struct {
+	Err *Error `thrift:"err,1" db:"err" json:"err,omitempty"`
 }
 
-var NodeSetWriteNewSeriesAsyncResult_Success_DEFAULT *NodeWriteNewSeriesAsyncResult_
-
-func (p *NodeSetWriteNewSeriesAsyncResult) GetSuccess() *NodeWriteNewSeriesAsyncResult_ {
-	if !p.IsSetSuccess() {
-		return NodeSetWriteNewSeriesAsyncResult_Success_DEFAULT
-	}
-	return p.Success
+func NewNodeRemoveNamespaceResult() *NodeRemoveNamespaceResult {
+	return &NodeRemoveNamespaceResult{}
 }
 
-var NodeSetWriteNewSeriesAsyncResult_Err_DEFAULT *Error
+var NodeRemoveNamespaceResult_Err_DEFAULT *Error
 
-func (p *NodeSetWriteNewSeriesAsyncResult) GetErr() *Error {
+func (p *NodeRemoveNamespaceResult) GetErr() *Error {
 	if !p.IsSetErr() {
-		return NodeSetWriteNewSeriesAsyncResult_Err_DEFAULT
+		return NodeRemoveNamespaceResult_Err_DEFAULT
 	}
 	return p.Err
 }
-func (p *NodeSetWriteNewSeriesAsyncResult) IsSetSuccess() bool {
-	return p.Success != nil
-}
-
-func (p *NodeSetWriteNewSeriesAsyncResult) IsSetErr() bool {
+func (p *NodeRemoveNamespaceResult) IsSetErr() bool {
 	return p.Err != nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
+func (p *NodeRemoveNamespaceResult) Read(iprot thrift.TProtocol) error {
 	if _, err := iprot.ReadStructBegin(); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
 	}
@@ -10346,10 +15463,6 @@ func (p *NodeSetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 			break
 		}
 		switch fieldId {
-		case 0:
-			if err := p.ReadField0(iprot); err != nil {
-				return err
-			}
 		case 1:
 			if err := p.ReadField1(iprot); err != nil {
 				return err
@@ -10369,15 +15482,7 @@ func (p *NodeSetWriteNewSeriesAsyncResult) Read(iprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncResult) ReadField0(iprot thrift.TProtocol) error {
-	p.Success = &NodeWriteNewSeriesAsyncResult_{}
-	if err := p.Success.Read(iprot); err != nil {
-		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
-	}
-	return nil
-}
-
-func (p *NodeSetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) error {
+func (p *NodeRemoveNamespaceResult) ReadField1(iprot thrift.TProtocol) error {
 	p.Err = &Error{
 		Type: 0,
 	}
@@ -10387,14 +15492,11 @@ func (p *NodeSetWriteNewSeriesAsyncResult) ReadField1(iprot thrift.TProtocol) er
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
-	if err := oprot.WriteStructBegin("setWriteNewSeriesAsync_result"); err != nil {
+func (p *NodeRemoveNamespaceResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("removeNamespace_result"); err != nil {
 		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
 	}
 	if p != nil {
-		if err := p.writeField0(oprot); err != nil {
-			return err
-		}
 		if err := p.writeField1(oprot); err != nil {
 			return err
 		}
@@ -10408,22 +15510,7 @@ func (p *NodeSetWriteNewSeriesAsyncResult) Write(oprot thrift.TProtocol) error {
 	return nil
 }
 
-func (p *NodeSetWriteNewSeriesAsyncResult) writeField0(oprot thrift.TProtocol) (err error) {
-	if p.IsSetSuccess() {
-		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
-		}
-		if err := p.Success.Write(oprot); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
-		}
-		if err := oprot.WriteFieldEnd(); err != nil {
-			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
-		}
-	}
-	return err
-}
-
-func (p *NodeSetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (err error) {
+func (p *NodeRemoveNamespaceResult) writeField1(oprot thrift.TProtocol) (err error) {
 	if p.IsSetErr() {
 		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
 			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
@@ -10438,11 +15525,11 @@ func (p *NodeSetWriteNewSeriesAsyncResult) writeField1(oprot thrift.TProtocol) (
 	return err
 }
 
-func (p *NodeSetWriteNewSeriesAsyncResult) String() string {
+func (p *NodeRemoveNamespaceResult) String() string {
 	if p == nil {
 		return "<nil>"
 	}
-	return fmt.Sprintf("NodeSetWriteNewSeriesAsyncResult(%+v)", *p)
+	return fmt.Sprintf("NodeRemoveNamespaceResult(%+v)", *p)
 }
 
 type Cluster interface {
@@ -10456,6 +15543,9 @@ type Cluster interface {
 	// Parameters:
 	//  - Req
 	Truncate(req *TruncateRequest) (r *TruncateResult_, err error)
+	// Parameters:
+	//  - Req
+	FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error)
 }
 
 type ClusterClient struct {
@@ -10530,16 +15620,16 @@ func (p *ClusterClient) recvHealth() (value *HealthResult_, err error) {
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error95 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error96 error
-		error96, err = error95.Read(iprot)
+		error113 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error114 error
+		error114, err = error113.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error96
+		err = error114
 		return
 	}
 	if mTypeId != thrift.REPLY {
@@ -10611,16 +15701,16 @@ func (p *ClusterClient) recvWrite() (err error) {
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error97 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error98 error
-		error98, err = error97.Read(iprot)
+		error115 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error116 error
+		error116, err = error115.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error98
+		err = error116
 		return
 	}
 	if mTypeId != thrift.REPLY {
@@ -10691,16 +15781,16 @@ func (p *ClusterClient) recvFetch() (value *FetchResult_, err error) {
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error99 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error100 error
-		error100, err = error99.Read(iprot)
+		error117 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error118 error
+		error118, err = error117.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error100
+		err = error118
 		return
 	}
 	if mTypeId != thrift.REPLY {
@@ -10772,23 +15862,104 @@ func (p *ClusterClient) recvTruncate() (value *TruncateResult_, err error) {
 		return
 	}
 	if mTypeId == thrift.EXCEPTION {
-		error101 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
-		var error102 error
-		error102, err = error101.Read(iprot)
+		error119 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error120 error
+		error120, err = error119.Read(iprot)
 		if err != nil {
 			return
 		}
 		if err = iprot.ReadMessageEnd(); err != nil {
 			return
 		}
-		err = error102
+		err = error120
 		return
 	}
 	if mTypeId != thrift.REPLY {
 		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "truncate failed: invalid message type")
 		return
 	}
-	result := ClusterTruncateResult{}
+	result := ClusterTruncateResult{}
+	if err = result.Read(iprot); err != nil {
+		return
+	}
+	if err = iprot.ReadMessageEnd(); err != nil {
+		return
+	}
+	if result.Err != nil {
+		err = result.Err
+		return
+	}
+	value = result.GetSuccess()
+	return
+}
+
+// Parameters:
+//  - Req
+func (p *ClusterClient) FetchTagged(req *FetchTaggedRequest) (r *FetchTaggedResult_, err error) {
+	if err = p.sendFetchTagged(req); err != nil {
+		return
+	}
+	return p.recvFetchTagged()
+}
+
+func (p *ClusterClient) sendFetchTagged(req *FetchTaggedRequest) (err error) {
+	oprot := p.OutputProtocol
+	if oprot == nil {
+		oprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.OutputProtocol = oprot
+	}
+	p.SeqId++
+	if err = oprot.WriteMessageBegin("fetchTagged", thrift.CALL, p.SeqId); err != nil {
+		return
+	}
+	args := ClusterFetchTaggedArgs{
+		Req: req,
+	}
+	if err = args.Write(oprot); err != nil {
+		return
+	}
+	if err = oprot.WriteMessageEnd(); err != nil {
+		return
+	}
+	return oprot.Flush()
+}
+
+func (p *ClusterClient) recvFetchTagged() (value *FetchTaggedResult_, err error) {
+	iprot := p.InputProtocol
+	if iprot == nil {
+		iprot = p.ProtocolFactory.GetProtocol(p.Transport)
+		p.InputProtocol = iprot
+	}
+	method, mTypeId, seqId, err := iprot.ReadMessageBegin()
+	if err != nil {
+		return
+	}
+	if method != "fetchTagged" {
+		err = thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "fetchTagged failed: wrong method name")
+		return
+	}
+	if p.SeqId != seqId {
+		err = thrift.NewTApplicationException(thrift.BAD_SEQUENCE_ID, "fetchTagged failed: out of sequence response")
+		return
+	}
+	if mTypeId == thrift.EXCEPTION {
+		error121 := thrift.NewTApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "Unknown Exception")
+		var error122 error
+		error122, err = error121.Read(iprot)
+		if err != nil {
+			return
+		}
+		if err = iprot.ReadMessageEnd(); err != nil {
+			return
+		}
+		err = error122
+		return
+	}
+	if mTypeId != thrift.REPLY {
+		err = thrift.NewTApplicationException(thrift.INVALID_MESSAGE_TYPE_EXCEPTION, "fetchTagged failed: invalid message type")
+		return
+	}
+	result := ClusterFetchTaggedResult{}
 	if err = result.Read(iprot); err != nil {
 		return
 	}
@@ -10823,12 +15994,13 @@ func (p *ClusterProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
 
 func NewClusterProcessor(handler Cluster) *ClusterProcessor {
 
-	self103 := &ClusterProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
-	self103.processorMap["health"] = &clusterProcessorHealth{handler: handler}
-	self103.processorMap["write"] = &clusterProcessorWrite{handler: handler}
-	self103.processorMap["fetch"] = &clusterProcessorFetch{handler: handler}
-	self103.processorMap["truncate"] = &clusterProcessorTruncate{handler: handler}
-	return self103
+	self123 := &ClusterProcessor{handler: handler, processorMap: make(map[string]thrift.TProcessorFunction)}
+	self123.processorMap["health"] = &clusterProcessorHealth{handler: handler}
+	self123.processorMap["write"] = &clusterProcessorWrite{handler: handler}
+	self123.processorMap["fetch"] = &clusterProcessorFetch{handler: handler}
+	self123.processorMap["truncate"] = &clusterProcessorTruncate{handler: handler}
+	self123.processorMap["fetchTagged"] = &clusterProcessorFetchTagged{handler: handler}
+	return self123
 }
 
 func (p *ClusterProcessor) Process(iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
@@ -10841,12 +16013,12 @@ func (p *ClusterProcessor) Process(iprot, oprot thrift.TProtocol) (success bool,
 	}
 	iprot.Skip(thrift.STRUCT)
 	iprot.ReadMessageEnd()
-	x104 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
+	x122 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function "+name)
 	oprot.WriteMessageBegin(name, thrift.EXCEPTION, seqId)
-	x104.Write(oprot)
+	x122.Write(oprot)
 	oprot.WriteMessageEnd()
 	oprot.Flush()
-	return false, x104
+	return false, x122
 
 }
 
@@ -11059,6 +16231,59 @@ func (p *clusterProcessorTruncate) Process(seqId int32, iprot, oprot thrift.TPro
 	return true, err
 }
 
+type clusterProcessorFetchTagged struct {
+	handler Cluster
+}
+
+func (p *clusterProcessorFetchTagged) Process(seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+	args := ClusterFetchTaggedArgs{}
+	if err = args.Read(iprot); err != nil {
+		iprot.ReadMessageEnd()
+		x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err.Error())
+		oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+		x.Write(oprot)
+		oprot.WriteMessageEnd()
+		oprot.Flush()
+		return false, err
+	}
+
+	iprot.ReadMessageEnd()
+	result := ClusterFetchTaggedResult{}
+	var retval *FetchTaggedResult_
+	var err2 error
+	if retval, err2 = p.handler.FetchTagged(args.Req); err2 != nil {
+		switch v := err2.(type) {
+		case *Error:
+			result.Err = v
+		default:
+			x := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing fetchTagged: "+err2.Error())
+			oprot.WriteMessageBegin("fetchTagged", thrift.EXCEPTION, seqId)
+			x.Write(oprot)
+			oprot.WriteMessageEnd()
+			oprot.Flush()
+			return true, err2
+		}
+	} else {
+		result.Success = retval
+	}
+	if err2 = oprot.WriteMessageBegin("fetchTagged", thrift.REPLY, seqId); err2 != nil {
+		err = err2
+	}
+	if err2 = result.Write(oprot); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.WriteMessageEnd(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err2 = oprot.Flush(); err == nil && err2 != nil {
+		err = err2
+	}
+	if err != nil {
+		return
+	}
+	return true, err
+}
+
 // HELPER FUNCTIONS AND STRUCTURES
 
 type ClusterHealthArgs struct {
@@ -11977,3 +17202,254 @@ func (p *ClusterTruncateResult) String() string {
 	}
 	return fmt.Sprintf("ClusterTruncateResult(%+v)", *p)
 }
+
+// Attributes:
+//  - Req
+type ClusterFetchTaggedArgs struct {
+	Req *FetchTaggedRequest `thrift:"req,1" db:"req" json:"req"`
+}
+
+func NewClusterFetchTaggedArgs() *ClusterFetchTaggedArgs {
+	return &ClusterFetchTaggedArgs{}
+}
+
+var ClusterFetchTaggedArgs_Req_DEFAULT *FetchTaggedRequest
+
+func (p *ClusterFetchTaggedArgs) GetReq() *FetchTaggedRequest {
+	if !p.IsSetReq() {
+		return ClusterFetchTaggedArgs_Req_DEFAULT
+	}
+	return p.Req
+}
+func (p *ClusterFetchTaggedArgs) IsSetReq() bool {
+	return p.Req != nil
+}
+
+func (p *ClusterFetchTaggedArgs) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedArgs) ReadField1(iprot thrift.TProtocol) error {
+	p.Req = &FetchTaggedRequest{}
+	if err := p.Req.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Req), err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedArgs) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_args"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedArgs) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("req", thrift.STRUCT, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:req: ", p), err)
+	}
+	if err := p.Req.Write(oprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Req), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:req: ", p), err)
+	}
+	return err
+}
+
+func (p *ClusterFetchTaggedArgs) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ClusterFetchTaggedArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+//  - Err
+type ClusterFetchTaggedResult struct {
+	Success *FetchTaggedResult_ `thrift:"success,0" db:"success" json:"success,omitempty"`
+	Err     *Error              `thrift:"err,1" db:"err" json:"err,omitempty"`
+}
+
+func NewClusterFetchTaggedResult() *ClusterFetchTaggedResult {
+	return &ClusterFetchTaggedResult{}
+}
+
+var ClusterFetchTaggedResult_Success_DEFAULT *FetchTaggedResult_
+
+func (p *ClusterFetchTaggedResult) GetSuccess() *FetchTaggedResult_ {
+	if !p.IsSetSuccess() {
+		return ClusterFetchTaggedResult_Success_DEFAULT
+	}
+	return p.Success
+}
+
+var ClusterFetchTaggedResult_Err_DEFAULT *Error
+
+func (p *ClusterFetchTaggedResult) GetErr() *Error {
+	if !p.IsSetErr() {
+		return ClusterFetchTaggedResult_Err_DEFAULT
+	}
+	return p.Err
+}
+func (p *ClusterFetchTaggedResult) IsSetSuccess() bool {
+	return p.Success != nil
+}
+
+func (p *ClusterFetchTaggedResult) IsSetErr() bool {
+	return p.Err != nil
+}
+
+func (p *ClusterFetchTaggedResult) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 0:
+			if err := p.ReadField0(iprot); err != nil {
+				return err
+			}
+		case 1:
+			if err := p.ReadField1(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedResult) ReadField0(iprot thrift.TProtocol) error {
+	p.Success = &FetchTaggedResult_{}
+	if err := p.Success.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedResult) ReadField1(iprot thrift.TProtocol) error {
+	p.Err = &Error{
+		Type: 0,
+	}
+	if err := p.Err.Read(iprot); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Err), err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedResult) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("fetchTagged_result"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if p != nil {
+		if err := p.writeField0(oprot); err != nil {
+			return err
+		}
+		if err := p.writeField1(oprot); err != nil {
+			return err
+		}
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *ClusterFetchTaggedResult) writeField0(oprot thrift.TProtocol) (err error) {
+	if p.IsSetSuccess() {
+		if err := oprot.WriteFieldBegin("success", thrift.STRUCT, 0); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err)
+		}
+		if err := p.Success.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *ClusterFetchTaggedResult) writeField1(oprot thrift.TProtocol) (err error) {
+	if p.IsSetErr() {
+		if err := oprot.WriteFieldBegin("err", thrift.STRUCT, 1); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:err: ", p), err)
+		}
+		if err := p.Err.Write(oprot); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Err), err)
+		}
+		if err := oprot.WriteFieldEnd(); err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T write field end error 1:err: ", p), err)
+		}
+	}
+	return err
+}
+
+func (p *ClusterFetchTaggedResult) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("ClusterFetchTaggedResult(%+v)", *p)
+}
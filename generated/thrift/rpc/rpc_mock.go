@@ -60,6 +60,17 @@ func (_mr *_MockTChanClusterRecorder) Fetch(arg0, arg1 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Fetch", arg0, arg1)
 }
 
+func (_m *MockTChanCluster) FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error) {
+	ret := _m.ctrl.Call(_m, "FetchTagged", ctx, req)
+	ret0, _ := ret[0].(*FetchTaggedResult_)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockTChanClusterRecorder) FetchTagged(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchTagged", arg0, arg1)
+}
+
 func (_m *MockTChanCluster) Health(ctx thrift.Context) (*HealthResult_, error) {
 	ret := _m.ctrl.Call(_m, "Health", ctx)
 	ret0, _ := ret[0].(*HealthResult_)
@@ -113,6 +124,26 @@ func (_m *MockTChanNode) EXPECT() *_MockTChanNodeRecorder {
 	return _m.recorder
 }
 
+func (_m *MockTChanNode) AddNamespace(ctx thrift.Context, req *NodeAddNamespaceRequest) error {
+	ret := _m.ctrl.Call(_m, "AddNamespace", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockTChanNodeRecorder) AddNamespace(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddNamespace", arg0, arg1)
+}
+
+func (_m *MockTChanNode) Delete(ctx thrift.Context, req *DeleteRequest) error {
+	ret := _m.ctrl.Call(_m, "Delete", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockTChanNodeRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1)
+}
+
 func (_m *MockTChanNode) Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error) {
 	ret := _m.ctrl.Call(_m, "Fetch", ctx, req)
 	ret0, _ := ret[0].(*FetchResult_)
@@ -157,6 +188,28 @@ func (_mr *_MockTChanNodeRecorder) FetchBlocksRaw(arg0, arg1 interface{}) *gomoc
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchBlocksRaw", arg0, arg1)
 }
 
+func (_m *MockTChanNode) FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error) {
+	ret := _m.ctrl.Call(_m, "FetchTagged", ctx, req)
+	ret0, _ := ret[0].(*FetchTaggedResult_)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockTChanNodeRecorder) FetchTagged(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "FetchTagged", arg0, arg1)
+}
+
+func (_m *MockTChanNode) GetNamespaces(ctx thrift.Context) (*NodeNamespacesResult_, error) {
+	ret := _m.ctrl.Call(_m, "GetNamespaces", ctx)
+	ret0, _ := ret[0].(*NodeNamespacesResult_)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockTChanNodeRecorder) GetNamespaces(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetNamespaces", arg0)
+}
+
 func (_m *MockTChanNode) GetPersistRateLimit(ctx thrift.Context) (*NodePersistRateLimitResult_, error) {
 	ret := _m.ctrl.Call(_m, "GetPersistRateLimit", ctx)
 	ret0, _ := ret[0].(*NodePersistRateLimitResult_)
@@ -190,6 +243,16 @@ func (_mr *_MockTChanNodeRecorder) Health(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Health", arg0)
 }
 
+func (_m *MockTChanNode) RemoveNamespace(ctx thrift.Context, req *NodeRemoveNamespaceRequest) error {
+	ret := _m.ctrl.Call(_m, "RemoveNamespace", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockTChanNodeRecorder) RemoveNamespace(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RemoveNamespace", arg0, arg1)
+}
+
 func (_m *MockTChanNode) Repair(ctx thrift.Context) error {
 	ret := _m.ctrl.Call(_m, "Repair", ctx)
 	ret0, _ := ret[0].(error)
@@ -252,3 +315,13 @@ func (_m *MockTChanNode) WriteBatchRaw(ctx thrift.Context, req *WriteBatchRawReq
 func (_mr *_MockTChanNodeRecorder) WriteBatchRaw(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteBatchRaw", arg0, arg1)
 }
+
+func (_m *MockTChanNode) WriteTagged(ctx thrift.Context, req *WriteTaggedRequest) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockTChanNodeRecorder) WriteTagged(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1)
+}
@@ -35,6 +35,7 @@ import (
 // TChanCluster is the interface that defines the server handler and client interface.
 type TChanCluster interface {
 	Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error)
+	FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error)
 	Health(ctx thrift.Context) (*HealthResult_, error)
 	Truncate(ctx thrift.Context, req *TruncateRequest) (*TruncateResult_, error)
 	Write(ctx thrift.Context, req *WriteRequest) error
@@ -42,19 +43,25 @@ type TChanCluster interface {
 
 // TChanNode is the interface that defines the server handler and client interface.
 type TChanNode interface {
+	AddNamespace(ctx thrift.Context, req *NodeAddNamespaceRequest) error
+	Delete(ctx thrift.Context, req *DeleteRequest) error
 	Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error)
 	FetchBatchRaw(ctx thrift.Context, req *FetchBatchRawRequest) (*FetchBatchRawResult_, error)
 	FetchBlocksMetadataRaw(ctx thrift.Context, req *FetchBlocksMetadataRawRequest) (*FetchBlocksMetadataRawResult_, error)
 	FetchBlocksRaw(ctx thrift.Context, req *FetchBlocksRawRequest) (*FetchBlocksRawResult_, error)
+	FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error)
+	GetNamespaces(ctx thrift.Context) (*NodeNamespacesResult_, error)
 	GetPersistRateLimit(ctx thrift.Context) (*NodePersistRateLimitResult_, error)
 	GetWriteNewSeriesAsync(ctx thrift.Context) (*NodeWriteNewSeriesAsyncResult_, error)
 	Health(ctx thrift.Context) (*NodeHealthResult_, error)
+	RemoveNamespace(ctx thrift.Context, req *NodeRemoveNamespaceRequest) error
 	Repair(ctx thrift.Context) error
 	SetPersistRateLimit(ctx thrift.Context, req *NodeSetPersistRateLimitRequest) (*NodePersistRateLimitResult_, error)
 	SetWriteNewSeriesAsync(ctx thrift.Context, req *NodeSetWriteNewSeriesAsyncRequest) (*NodeWriteNewSeriesAsyncResult_, error)
 	Truncate(ctx thrift.Context, req *TruncateRequest) (*TruncateResult_, error)
 	Write(ctx thrift.Context, req *WriteRequest) error
 	WriteBatchRaw(ctx thrift.Context, req *WriteBatchRawRequest) error
+	WriteTagged(ctx thrift.Context, req *WriteTaggedRequest) error
 }
 
 // Implementation of a client and service handler.
@@ -91,6 +98,21 @@ func (c *tchanClusterClient) Fetch(ctx thrift.Context, req *FetchRequest) (*Fetc
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanClusterClient) FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error) {
+	var resp ClusterFetchTaggedResult
+	args := ClusterFetchTaggedArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "fetchTagged", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanClusterClient) Health(ctx thrift.Context) (*HealthResult_, error) {
 	var resp ClusterHealthResult
 	args := ClusterHealthArgs{}
@@ -153,6 +175,7 @@ func (s *tchanClusterServer) Service() string {
 func (s *tchanClusterServer) Methods() []string {
 	return []string{
 		"fetch",
+		"fetchTagged",
 		"health",
 		"truncate",
 		"write",
@@ -163,6 +186,8 @@ func (s *tchanClusterServer) Handle(ctx thrift.Context, methodName string, proto
 	switch methodName {
 	case "fetch":
 		return s.handleFetch(ctx, protocol)
+	case "fetchTagged":
+		return s.handleFetchTagged(ctx, protocol)
 	case "health":
 		return s.handleHealth(ctx, protocol)
 	case "truncate":
@@ -203,6 +228,34 @@ func (s *tchanClusterServer) handleFetch(ctx thrift.Context, protocol athrift.TP
 	return err == nil, &res, nil
 }
 
+func (s *tchanClusterServer) handleFetchTagged(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req ClusterFetchTaggedArgs
+	var res ClusterFetchTaggedResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.FetchTagged(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanClusterServer) handleHealth(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req ClusterHealthArgs
 	var res ClusterHealthResult
@@ -303,6 +356,36 @@ func NewTChanNodeClient(client thrift.TChanClient) TChanNode {
 	return NewTChanNodeInheritedClient("Node", client)
 }
 
+func (c *tchanNodeClient) AddNamespace(ctx thrift.Context, req *NodeAddNamespaceRequest) error {
+	var resp NodeAddNamespaceResult
+	args := NodeAddNamespaceArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "addNamespace", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
+func (c *tchanNodeClient) Delete(ctx thrift.Context, req *DeleteRequest) error {
+	var resp NodeDeleteResult
+	args := NodeDeleteArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "delete", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
 func (c *tchanNodeClient) Fetch(ctx thrift.Context, req *FetchRequest) (*FetchResult_, error) {
 	var resp NodeFetchResult
 	args := NodeFetchArgs{
@@ -363,6 +446,34 @@ func (c *tchanNodeClient) FetchBlocksRaw(ctx thrift.Context, req *FetchBlocksRaw
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) FetchTagged(ctx thrift.Context, req *FetchTaggedRequest) (*FetchTaggedResult_, error) {
+	var resp NodeFetchTaggedResult
+	args := NodeFetchTaggedArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "fetchTagged", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
+func (c *tchanNodeClient) GetNamespaces(ctx thrift.Context) (*NodeNamespacesResult_, error) {
+	var resp NodeGetNamespacesResult
+	args := NodeGetNamespacesArgs{}
+	success, err := c.client.Call(ctx, c.thriftService, "getNamespaces", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return resp.GetSuccess(), err
+}
+
 func (c *tchanNodeClient) GetPersistRateLimit(ctx thrift.Context) (*NodePersistRateLimitResult_, error) {
 	var resp NodeGetPersistRateLimitResult
 	args := NodeGetPersistRateLimitArgs{}
@@ -402,6 +513,21 @@ func (c *tchanNodeClient) Health(ctx thrift.Context) (*NodeHealthResult_, error)
 	return resp.GetSuccess(), err
 }
 
+func (c *tchanNodeClient) RemoveNamespace(ctx thrift.Context, req *NodeRemoveNamespaceRequest) error {
+	var resp NodeRemoveNamespaceResult
+	args := NodeRemoveNamespaceArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "removeNamespace", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
 func (c *tchanNodeClient) Repair(ctx thrift.Context) error {
 	var resp NodeRepairResult
 	args := NodeRepairArgs{}
@@ -490,6 +616,21 @@ func (c *tchanNodeClient) WriteBatchRaw(ctx thrift.Context, req *WriteBatchRawRe
 	return err
 }
 
+func (c *tchanNodeClient) WriteTagged(ctx thrift.Context, req *WriteTaggedRequest) error {
+	var resp NodeWriteTaggedResult
+	args := NodeWriteTaggedArgs{
+		Req: req,
+	}
+	success, err := c.client.Call(ctx, c.thriftService, "writeTagged", &args, &resp)
+	if err == nil && !success {
+		if e := resp.Err; e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
+
 type tchanNodeServer struct {
 	handler TChanNode
 }
@@ -508,24 +649,34 @@ func (s *tchanNodeServer) Service() string {
 
 func (s *tchanNodeServer) Methods() []string {
 	return []string{
+		"addNamespace",
+		"delete",
 		"fetch",
 		"fetchBatchRaw",
 		"fetchBlocksMetadataRaw",
 		"fetchBlocksRaw",
+		"fetchTagged",
+		"getNamespaces",
 		"getPersistRateLimit",
 		"getWriteNewSeriesAsync",
 		"health",
+		"removeNamespace",
 		"repair",
 		"setPersistRateLimit",
 		"setWriteNewSeriesAsync",
 		"truncate",
 		"write",
 		"writeBatchRaw",
+		"writeTagged",
 	}
 }
 
 func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	switch methodName {
+	case "addNamespace":
+		return s.handleAddNamespace(ctx, protocol)
+	case "delete":
+		return s.handleDelete(ctx, protocol)
 	case "fetch":
 		return s.handleFetch(ctx, protocol)
 	case "fetchBatchRaw":
@@ -534,12 +685,18 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleFetchBlocksMetadataRaw(ctx, protocol)
 	case "fetchBlocksRaw":
 		return s.handleFetchBlocksRaw(ctx, protocol)
+	case "fetchTagged":
+		return s.handleFetchTagged(ctx, protocol)
+	case "getNamespaces":
+		return s.handleGetNamespaces(ctx, protocol)
 	case "getPersistRateLimit":
 		return s.handleGetPersistRateLimit(ctx, protocol)
 	case "getWriteNewSeriesAsync":
 		return s.handleGetWriteNewSeriesAsync(ctx, protocol)
 	case "health":
 		return s.handleHealth(ctx, protocol)
+	case "removeNamespace":
+		return s.handleRemoveNamespace(ctx, protocol)
 	case "repair":
 		return s.handleRepair(ctx, protocol)
 	case "setPersistRateLimit":
@@ -552,12 +709,68 @@ func (s *tchanNodeServer) Handle(ctx thrift.Context, methodName string, protocol
 		return s.handleWrite(ctx, protocol)
 	case "writeBatchRaw":
 		return s.handleWriteBatchRaw(ctx, protocol)
+	case "writeTagged":
+		return s.handleWriteTagged(ctx, protocol)
 
 	default:
 		return false, nil, fmt.Errorf("method %v not found in service %v", methodName, s.Service())
 	}
 }
 
+func (s *tchanNodeServer) handleAddNamespace(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeAddNamespaceArgs
+	var res NodeAddNamespaceResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.AddNamespace(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
+
+func (s *tchanNodeServer) handleDelete(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeDeleteArgs
+	var res NodeDeleteResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.Delete(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleFetch(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeFetchArgs
 	var res NodeFetchResult
@@ -670,6 +883,62 @@ func (s *tchanNodeServer) handleFetchBlocksRaw(ctx thrift.Context, protocol athr
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleFetchTagged(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeFetchTaggedArgs
+	var res NodeFetchTaggedResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.FetchTagged(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
+func (s *tchanNodeServer) handleGetNamespaces(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeGetNamespacesArgs
+	var res NodeGetNamespacesResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	r, err :=
+		s.handler.GetNamespaces(ctx)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+		res.Success = r
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleGetPersistRateLimit(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeGetPersistRateLimitArgs
 	var res NodeGetPersistRateLimitResult
@@ -754,6 +1023,33 @@ func (s *tchanNodeServer) handleHealth(ctx thrift.Context, protocol athrift.TPro
 	return err == nil, &res, nil
 }
 
+func (s *tchanNodeServer) handleRemoveNamespace(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeRemoveNamespaceArgs
+	var res NodeRemoveNamespaceResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.RemoveNamespace(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
+
 func (s *tchanNodeServer) handleRepair(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
 	var req NodeRepairArgs
 	var res NodeRepairResult
@@ -918,3 +1214,30 @@ func (s *tchanNodeServer) handleWriteBatchRaw(ctx thrift.Context, protocol athri
 
 	return err == nil, &res, nil
 }
+
+func (s *tchanNodeServer) handleWriteTagged(ctx thrift.Context, protocol athrift.TProtocol) (bool, athrift.TStruct, error) {
+	var req NodeWriteTaggedArgs
+	var res NodeWriteTaggedResult
+
+	if err := req.Read(protocol); err != nil {
+		return false, nil, err
+	}
+
+	err :=
+		s.handler.WriteTagged(ctx, req.Req)
+
+	if err != nil {
+		switch v := err.(type) {
+		case *Error:
+			if v == nil {
+				return false, nil, fmt.Errorf("Handler for err returned non-nil error type *Error but nil value")
+			}
+			res.Err = v
+		default:
+			return false, nil, err
+		}
+	} else {
+	}
+
+	return err == nil, &res, nil
+}
@@ -46,7 +46,7 @@ func createWriter(storageOpts storage.Options) fs.FileSetWriter {
 	writerBufferSize := fsOpts.WriterBufferSize()
 	newFileMode := fsOpts.NewFileMode()
 	newDirectoryMode := fsOpts.NewDirectoryMode()
-	return fs.NewWriter(blockSize, filePathPrefix, writerBufferSize, newFileMode, newDirectoryMode)
+	return fs.NewWriter(blockSize, filePathPrefix, writerBufferSize, newFileMode, newDirectoryMode, fs.CompressionNone)
 }
 
 func createFilesetFiles(t *testing.T, storageOpts storage.Options, namespace ts.ID, shard uint32, fileTimes []time.Time) {
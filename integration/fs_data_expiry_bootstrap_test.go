@@ -35,7 +35,6 @@ import (
 	bfs "github.com/m3db/m3db/storage/bootstrap/bootstrapper/fs"
 	"github.com/m3db/m3db/storage/bootstrap/result"
 	"github.com/m3db/m3db/storage/namespace"
-	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/log"
 
 	"github.com/stretchr/testify/require"
@@ -58,9 +57,9 @@ func TestFilesystemDataExpiryBootstrap(t *testing.T) {
 	retrieverOpts := fs.NewBlockRetrieverOptions()
 
 	blockRetrieverMgr := block.NewDatabaseBlockRetrieverManager(
-		func(namespace ts.ID) (block.DatabaseBlockRetriever, error) {
+		func(md namespace.Metadata) (block.DatabaseBlockRetriever, error) {
 			retriever := fs.NewBlockRetriever(retrieverOpts, setup.fsOpts)
-			if err := retriever.Open(namespace); err != nil {
+			if err := retriever.Open(md); err != nil {
 				return nil, err
 			}
 			return retriever, nil
@@ -60,7 +60,7 @@ func (w *writer) Write(namespace ts.ID, shardSet sharding.ShardSet, seriesMaps S
 		starts[start] = struct{}{}
 	}
 
-	writer := fs.NewWriter(blockSize, gOpts.FilePathPrefix(), gOpts.WriterBufferSize(), gOpts.NewFileMode(), gOpts.NewDirectoryMode())
+	writer := fs.NewWriter(blockSize, gOpts.FilePathPrefix(), gOpts.WriterBufferSize(), gOpts.NewFileMode(), gOpts.NewDirectoryMode(), fs.CompressionNone)
 	encoder := gOpts.EncoderPool().Get()
 	for start, data := range seriesMaps {
 		err := writeToDisk(writer, shardSet, encoder, start, namespace, data)
@@ -0,0 +1,99 @@
+// +build integration
+
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/generated/thrift/rpc"
+
+	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go/thrift"
+)
+
+func TestIndexBootstrapFromCommitLogAfterRestart(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow() // Just skip if we're doing a short run
+	}
+
+	// Test setup
+	testSetup, err := newTestSetup(newTestOptions())
+	require.NoError(t, err)
+	defer testSetup.close()
+
+	log := testSetup.storageOpts.InstrumentOptions().Logger()
+	require.NoError(t, testSetup.startServer())
+	log.Debug("server is now up")
+
+	// Write a tagged series to the current block which is not flushed
+	// so its tags are only held by the commit log
+	var (
+		namespace = testNamespaces[0].String()
+		timeout   = testSetup.opts.WriteRequestTimeout()
+		now       = testSetup.getNowFn()
+	)
+	ctx, _ := thrift.NewContext(timeout)
+	require.NoError(t, testSetup.tchannelClient.WriteTagged(ctx, &rpc.WriteTaggedRequest{
+		NameSpace: namespace,
+		ID:        "foo",
+		Tags:      []*rpc.Tag{{Name: "city", Value: "nyc"}},
+		Datapoint: &rpc.Datapoint{
+			Timestamp:         now.Unix(),
+			Value:             42,
+			TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+		},
+	}))
+
+	fetchTagged := func() []*rpc.FetchTaggedIDResult_ {
+		ctx, _ := thrift.NewContext(testSetup.opts.ReadRequestTimeout())
+		res, err := testSetup.tchannelClient.FetchTagged(ctx, &rpc.FetchTaggedRequest{
+			NameSpace: []byte(namespace),
+			Matchers: []*rpc.TagMatcher{
+				{Type: rpc.TagMatcherType_EQUAL, Name: "city", Value: "nyc"},
+			},
+			RangeStart:    now.Add(-time.Minute).Unix(),
+			RangeEnd:      now.Add(time.Minute).Unix(),
+			FetchData:     false,
+			RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		})
+		require.NoError(t, err)
+		return res.Elements
+	}
+	require.Equal(t, 1, len(fetchTagged()))
+
+	// Restart the server
+	require.NoError(t, testSetup.stopServer())
+	log.Debug("server is now down")
+	require.NoError(t, testSetup.startServer())
+	log.Debug("server is now up")
+	defer func() {
+		require.NoError(t, testSetup.stopServer())
+		log.Debug("server is now down")
+	}()
+
+	// Verify the series is still found by its tags
+	elements := fetchTagged()
+	require.Equal(t, 1, len(elements))
+	require.Equal(t, "foo", string(elements[0].ID))
+}
@@ -299,8 +299,16 @@ func (ts *testSetup) startServer() error {
 		tchannelNodeAddr = addr
 	}
 
+	// Namespaces in tests use the retention options configured for the database
+	ropts := ts.storageOpts.RetentionOptions()
+	namespaces := make([]namespace.Metadata, 0, len(ts.namespaces))
+	for _, ns := range ts.namespaces {
+		nsOpts := ns.Options().SetRetentionOptions(ropts)
+		namespaces = append(namespaces, namespace.NewMetadata(ns.ID(), nsOpts))
+	}
+
 	var err error
-	ts.db, err = cluster.NewDatabase(ts.namespaces,
+	ts.db, err = cluster.NewDatabase(namespaces,
 		ts.hostID, ts.topoInit, ts.storageOpts)
 	if err != nil {
 		return err
@@ -24,12 +24,16 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/m3db/m3db/client"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/encoding/m3tsz"
 	"github.com/m3db/m3db/generated/thrift/rpc"
 	"github.com/m3db/m3db/network/server/tchannelthrift/convert"
 	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3db/ts"
+	xio "github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/time"
@@ -40,16 +44,18 @@ import (
 type service struct {
 	sync.RWMutex
 
-	client client.Client
-	active client.Session
-	health *rpc.HealthResult_
+	client       client.Client
+	active       client.Session
+	health       *rpc.HealthResult_
+	encodingOpts encoding.Options
 }
 
 // NewService creates a new cluster TChannel Thrift service
 func NewService(client client.Client) rpc.TChanCluster {
 	s := &service{
-		client: client,
-		health: &rpc.HealthResult_{Ok: true, Status: "up"},
+		client:       client,
+		health:       &rpc.HealthResult_{Ok: true, Status: "up"},
+		encodingOpts: encoding.NewOptions(),
 	}
 	// Attempt to warm session
 	go s.session()
@@ -110,7 +116,15 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
 	}
 
-	it, err := session.Fetch(req.NameSpace, req.ID, start, end)
+	step, aggregation, err := convert.ToStepAggregation(req.Step, req.RangeType, req.Aggregation)
+	if err != nil {
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	it, err := session.FetchDownsampled(req.NameSpace, req.ID, start, end, client.Downsample{
+		Step:        step,
+		Aggregation: aggregation,
+	})
 	if err != nil {
 		if client.IsBadRequestError(err) {
 			return nil, tterrors.NewBadRequestError(err)
@@ -193,3 +207,83 @@ func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (*rpc.
 	res.NumSeries = truncated
 	return res, nil
 }
+
+func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest) (*rpc.FetchTaggedResult_, error) {
+	session, err := s.session()
+	if err != nil {
+		return nil, tterrors.NewInternalError(err)
+	}
+
+	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeTimeType)
+	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeTimeType)
+	if rangeStartErr != nil || rangeEndErr != nil {
+		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
+	}
+
+	query, err := convert.ToIndexQuery(req.Matchers)
+	if err != nil {
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	iters, err := session.FetchTagged(string(req.NameSpace), query, start, end)
+	if err != nil {
+		if client.IsBadRequestError(err) {
+			return nil, tterrors.NewBadRequestError(err)
+		}
+		return nil, tterrors.NewInternalError(err)
+	}
+
+	defer iters.Close()
+
+	series := iters.Iters()
+	result := rpc.NewFetchTaggedResult_()
+	result.Exhaustive = true
+	if req.Limit > 0 && int64(len(series)) > req.Limit {
+		series = series[:req.Limit]
+		result.Exhaustive = false
+	}
+	result.Elements = make([]*rpc.FetchTaggedIDResult_, 0, len(series))
+
+	for _, it := range series {
+		elem := rpc.NewFetchTaggedIDResult_()
+		elem.ID = []byte(it.ID())
+		elem.Tags = make([]*rpc.Tag, 0)
+		elem.Segments = make([]*rpc.Segments, 0)
+		result.Elements = append(result.Elements, elem)
+
+		if !req.FetchData {
+			continue
+		}
+
+		seg, err := s.encode(it, start)
+		if err != nil {
+			return nil, tterrors.NewInternalError(err)
+		}
+		if seg == nil {
+			continue
+		}
+		elem.Segments = append(elem.Segments, seg)
+	}
+
+	return result, nil
+}
+
+// encode re-encodes the merged values of a series iterator returned by the
+// session as a single segment.
+func (s *service) encode(it encoding.SeriesIterator, start time.Time) (*rpc.Segments, error) {
+	encoder := m3tsz.NewEncoder(start, nil, m3tsz.DefaultIntOptimizationEnabled, s.encodingOpts)
+	for it.Next() {
+		dp, unit, annotation := it.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		encoder.Close()
+		return nil, err
+	}
+
+	reader := xio.NewSegmentReader(encoder.Discard())
+	return convert.ToSegments([]xio.SegmentReader{reader})
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cluster
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/client"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/encoding/m3tsz"
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	"github.com/m3db/m3db/network/server/tchannelthrift"
+	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/ts"
+	xio "github.com/m3db/m3db/x/io"
+	xtime "github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSeriesIterator(
+	t *testing.T,
+	id string,
+	start, end time.Time,
+	values []ts.Datapoint,
+) encoding.SeriesIterator {
+	enc := m3tsz.NewEncoder(start, nil, m3tsz.DefaultIntOptimizationEnabled, nil)
+	for _, dp := range values {
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+	reader := xio.NewSegmentReader(enc.Discard())
+	it := m3tsz.NewReaderIterator(reader, m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
+	return encoding.NewSeriesIterator(id, start, end, []encoding.Iterator{it}, nil)
+}
+
+func TestServiceFetchTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	values := []ts.Datapoint{
+		{Timestamp: start.Add(10 * time.Second), Value: 1.0},
+		{Timestamp: start.Add(20 * time.Second), Value: 2.0},
+	}
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchRegexp, Name: "city", Value: "n.*"},
+	}}
+	mockSession := client.NewMockSession(ctrl)
+	mockSession.EXPECT().
+		FetchTagged("metrics", query, start, end).
+		Return(encoding.NewSeriesIterators([]encoding.SeriesIterator{
+			newTestSeriesIterator(t, "foo", start, end, values),
+		}, nil), nil)
+
+	mockClient := client.NewMockClient(ctrl)
+	mockClient.EXPECT().DefaultSession().Return(mockSession, nil)
+
+	service := NewService(mockClient)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace: []byte("metrics"),
+		Matchers: []*rpc.TagMatcher{
+			{Type: rpc.TagMatcherType_REGEXP, Name: "city", Value: "n.*"},
+		},
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		FetchData:     true,
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	require.True(t, r.Exhaustive)
+	require.Equal(t, 1, len(r.Elements))
+
+	elem := r.Elements[0]
+	require.Equal(t, "foo", string(elem.ID))
+	require.Equal(t, 1, len(elem.Segments))
+	require.NotNil(t, elem.Segments[0].Merged)
+
+	merged := elem.Segments[0].Merged
+	stream := append(append([]byte(nil), merged.Head...), merged.Tail...)
+	it := m3tsz.NewReaderIterator(bytes.NewReader(stream),
+		m3tsz.DefaultIntOptimizationEnabled, encoding.NewOptions())
+	defer it.Close()
+
+	var fetched []ts.Datapoint
+	for it.Next() {
+		dp, _, _ := it.Current()
+		fetched = append(fetched, dp)
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, len(values), len(fetched))
+	for i := range values {
+		assert.True(t, values[i].Timestamp.Equal(fetched[i].Timestamp))
+		assert.Equal(t, values[i].Value, fetched[i].Value)
+	}
+}
+
+func TestServiceFetchTaggedLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+
+	mockSession := client.NewMockSession(ctrl)
+	mockSession.EXPECT().
+		FetchTagged("metrics", gomock.Any(), start, end).
+		Return(encoding.NewSeriesIterators([]encoding.SeriesIterator{
+			newTestSeriesIterator(t, "bar", start, end, nil),
+			newTestSeriesIterator(t, "foo", start, end, nil),
+		}, nil), nil)
+
+	mockClient := client.NewMockClient(ctrl)
+	mockClient.EXPECT().DefaultSession().Return(mockSession, nil)
+
+	service := NewService(mockClient)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace: []byte("metrics"),
+		Matchers: []*rpc.TagMatcher{
+			{Type: rpc.TagMatcherType_EQUAL, Name: "city", Value: "nyc"},
+		},
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		Limit:         1,
+	})
+	require.NoError(t, err)
+	require.False(t, r.Exhaustive)
+	require.Equal(t, 1, len(r.Elements))
+	require.Equal(t, "bar", string(r.Elements[0].ID))
+	require.Equal(t, 0, len(r.Elements[0].Segments))
+}
+
+func TestServiceFetchTaggedBadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClient := client.NewMockClient(ctrl)
+	mockClient.EXPECT().DefaultSession().Return(client.NewMockSession(ctrl), nil)
+
+	service := NewService(mockClient)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	_, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace: []byte("metrics"),
+		Matchers:  []*rpc.TagMatcher{{Type: rpc.TagMatcherType(42), Name: "city"}},
+	})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
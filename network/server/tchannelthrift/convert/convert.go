@@ -24,8 +24,13 @@ import (
 	"errors"
 	"time"
 
+	"github.com/m3db/m3db/encoding"
 	"github.com/m3db/m3db/generated/thrift/rpc"
 	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/errors"
@@ -33,9 +38,17 @@ import (
 )
 
 var (
-	errUnknownTimeType = errors.New("unknown time type")
-	errUnknownUnit     = errors.New("unknown unit")
-	timeZero           time.Time
+	errUnknownTimeType  = errors.New("unknown time type")
+	errUnknownUnit      = errors.New("unknown unit")
+	errNoNamespace      = errors.New("no namespace")
+	errNoNamespaceName  = errors.New("no namespace name")
+	errInvalidRetention = errors.New("retention period and block size must be positive")
+	errInvalidRollup    = errors.New("rollup source namespace and resolution must be set")
+	errNoTagMatcher     = errors.New("no tag matcher")
+	errUnknownMatcher   = errors.New("unknown tag matcher type")
+	errUnknownAggType   = errors.New("unknown aggregation type")
+	errInvalidStep      = errors.New("downsampling requires both a positive step and an aggregation")
+	timeZero            time.Time
 )
 
 // ToTime converts a value to a time
@@ -99,6 +112,72 @@ func ToTimeType(unit xtime.Unit) (rpc.TimeType, error) {
 	return 0, errUnknownUnit
 }
 
+// ToAggregationType converts a RPC aggregation type to an aggregation type.
+func ToAggregationType(aggregation rpc.AggregationType) (encoding.AggregationType, error) {
+	switch aggregation {
+	case rpc.AggregationType_NONE:
+		return encoding.AggregationNone, nil
+	case rpc.AggregationType_MIN:
+		return encoding.AggregationMin, nil
+	case rpc.AggregationType_MAX:
+		return encoding.AggregationMax, nil
+	case rpc.AggregationType_SUM:
+		return encoding.AggregationSum, nil
+	case rpc.AggregationType_COUNT:
+		return encoding.AggregationCount, nil
+	case rpc.AggregationType_AVG:
+		return encoding.AggregationAvg, nil
+	case rpc.AggregationType_LAST:
+		return encoding.AggregationLast, nil
+	}
+	return 0, errUnknownAggType
+}
+
+// ToRPCAggregationType converts an aggregation type to a RPC aggregation type.
+func ToRPCAggregationType(aggregation encoding.AggregationType) (rpc.AggregationType, error) {
+	switch aggregation {
+	case encoding.AggregationNone:
+		return rpc.AggregationType_NONE, nil
+	case encoding.AggregationMin:
+		return rpc.AggregationType_MIN, nil
+	case encoding.AggregationMax:
+		return rpc.AggregationType_MAX, nil
+	case encoding.AggregationSum:
+		return rpc.AggregationType_SUM, nil
+	case encoding.AggregationCount:
+		return rpc.AggregationType_COUNT, nil
+	case encoding.AggregationAvg:
+		return rpc.AggregationType_AVG, nil
+	case encoding.AggregationLast:
+		return rpc.AggregationType_LAST, nil
+	}
+	return 0, errUnknownAggType
+}
+
+// ToStepAggregation converts a RPC step in units of the time type and
+// aggregation type, a zero step means values are not downsampled.
+func ToStepAggregation(
+	step int64,
+	timeType rpc.TimeType,
+	aggregation rpc.AggregationType,
+) (time.Duration, encoding.AggregationType, error) {
+	agg, err := ToAggregationType(aggregation)
+	if err != nil {
+		return 0, 0, err
+	}
+	if step == 0 && agg == encoding.AggregationNone {
+		return 0, agg, nil
+	}
+	if step <= 0 || agg == encoding.AggregationNone {
+		return 0, 0, errInvalidStep
+	}
+	unit, err := ToDuration(timeType)
+	if err != nil {
+		return 0, 0, err
+	}
+	return time.Duration(step) * unit, agg, nil
+}
+
 // ToSegments converts a list of segment readers to segments.
 func ToSegments(readers []xio.SegmentReader) (*rpc.Segments, error) {
 	if len(readers) == 0 {
@@ -159,3 +238,161 @@ func ToRPCError(err error) *rpc.Error {
 	}
 	return tterrors.NewInternalError(err)
 }
+
+// ToRPCNamespace converts namespace metadata to a RPC namespace.
+func ToRPCNamespace(md namespace.Metadata) (*rpc.NodeNamespace, error) {
+	opts := md.Options()
+	ropts := opts.RetentionOptions()
+	coldWritesEnabled := opts.ColdWritesEnabled()
+	syncCommitLogWrites := opts.SyncCommitLogWrites()
+	ns := &rpc.NodeNamespace{
+		Name:                 md.ID().String(),
+		RetentionPeriodNanos: int64(ropts.RetentionPeriod()),
+		BlockSizeNanos:       int64(ropts.BlockSize()),
+		BufferFutureNanos:    int64(ropts.BufferFuture()),
+		BufferPastNanos:      int64(ropts.BufferPast()),
+		NeedsBootstrap:       opts.NeedsBootstrap(),
+		NeedsFlush:           opts.NeedsFlush(),
+		WritesToCommitLog:    opts.WritesToCommitLog(),
+		NeedsFilesetCleanup:  opts.NeedsFilesetCleanup(),
+		NeedsRepair:          opts.NeedsRepair(),
+		ColdWritesEnabled:    &coldWritesEnabled,
+		SyncCommitLogWrites:  &syncCommitLogWrites,
+	}
+	if rollup := opts.Rollup(); rollup != nil {
+		aggregation, err := ToRPCAggregationType(rollup.Aggregation)
+		if err != nil {
+			return nil, err
+		}
+		ns.Rollup = &rpc.NodeNamespaceRollup{
+			SourceNamespace: rollup.SourceNamespace.String(),
+			ResolutionNanos: int64(rollup.Resolution),
+			Aggregation:     aggregation,
+		}
+	}
+	return ns, nil
+}
+
+// ToNamespaceMetadata converts a RPC namespace to namespace metadata.
+func ToNamespaceMetadata(ns *rpc.NodeNamespace) (namespace.Metadata, error) {
+	if ns == nil {
+		return nil, errNoNamespace
+	}
+	if ns.Name == "" {
+		return nil, errNoNamespaceName
+	}
+	if ns.RetentionPeriodNanos <= 0 || ns.BlockSizeNanos <= 0 {
+		return nil, errInvalidRetention
+	}
+	ropts := retention.NewOptions().
+		SetRetentionPeriod(time.Duration(ns.RetentionPeriodNanos)).
+		SetBlockSize(time.Duration(ns.BlockSizeNanos)).
+		SetBufferFuture(time.Duration(ns.BufferFutureNanos)).
+		SetBufferPast(time.Duration(ns.BufferPastNanos))
+	opts := namespace.NewOptions().
+		SetNeedsBootstrap(ns.NeedsBootstrap).
+		SetNeedsFlush(ns.NeedsFlush).
+		SetWritesToCommitLog(ns.WritesToCommitLog).
+		SetNeedsFilesetCleanup(ns.NeedsFilesetCleanup).
+		SetNeedsRepair(ns.NeedsRepair).
+		SetColdWritesEnabled(ns.GetColdWritesEnabled()).
+		SetSyncCommitLogWrites(ns.GetSyncCommitLogWrites()).
+		SetRetentionOptions(ropts)
+	if rollup := ns.GetRollup(); rollup != nil {
+		if rollup.SourceNamespace == "" || rollup.ResolutionNanos <= 0 {
+			return nil, errInvalidRollup
+		}
+		aggregation, err := ToAggregationType(rollup.Aggregation)
+		if err != nil {
+			return nil, err
+		}
+		opts = opts.SetRollup(&namespace.Rollup{
+			SourceNamespace: ts.StringID(rollup.SourceNamespace),
+			Resolution:      time.Duration(rollup.ResolutionNanos),
+			Aggregation:     aggregation,
+		})
+	}
+	return namespace.NewMetadata(ts.StringID(ns.Name), opts), nil
+}
+
+// ToTags converts RPC tags to tags.
+func ToTags(tags []*rpc.Tag) ts.Tags {
+	result := make(ts.Tags, 0, len(tags))
+	for _, tag := range tags {
+		if tag == nil {
+			continue
+		}
+		result = append(result, ts.Tag{
+			Name:  ts.StringID(tag.Name),
+			Value: ts.StringID(tag.Value),
+		})
+	}
+	return result
+}
+
+// ToRPCTags converts tags to RPC tags.
+func ToRPCTags(tags ts.Tags) []*rpc.Tag {
+	result := make([]*rpc.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, &rpc.Tag{
+			Name:  tag.Name.String(),
+			Value: tag.Value.String(),
+		})
+	}
+	return result
+}
+
+// ToIndexQuery converts RPC tag matchers to an index query.
+func ToIndexQuery(matchers []*rpc.TagMatcher) (index.Query, error) {
+	query := index.Query{Matchers: make([]index.Matcher, 0, len(matchers))}
+	for _, m := range matchers {
+		if m == nil {
+			return index.Query{}, errNoTagMatcher
+		}
+		var matcherType index.MatcherType
+		switch m.Type {
+		case rpc.TagMatcherType_EQUAL:
+			matcherType = index.MatchEqual
+		case rpc.TagMatcherType_NOT_EQUAL:
+			matcherType = index.MatchNotEqual
+		case rpc.TagMatcherType_REGEXP:
+			matcherType = index.MatchRegexp
+		case rpc.TagMatcherType_NOT_REGEXP:
+			matcherType = index.MatchNotRegexp
+		default:
+			return index.Query{}, errUnknownMatcher
+		}
+		query.Matchers = append(query.Matchers, index.Matcher{
+			Type:  matcherType,
+			Name:  m.Name,
+			Value: m.Value,
+		})
+	}
+	return query, nil
+}
+
+// ToRPCTagMatchers converts an index query to RPC tag matchers.
+func ToRPCTagMatchers(query index.Query) ([]*rpc.TagMatcher, error) {
+	matchers := make([]*rpc.TagMatcher, 0, len(query.Matchers))
+	for _, m := range query.Matchers {
+		var matcherType rpc.TagMatcherType
+		switch m.Type {
+		case index.MatchEqual:
+			matcherType = rpc.TagMatcherType_EQUAL
+		case index.MatchNotEqual:
+			matcherType = rpc.TagMatcherType_NOT_EQUAL
+		case index.MatchRegexp:
+			matcherType = rpc.TagMatcherType_REGEXP
+		case index.MatchNotRegexp:
+			matcherType = rpc.TagMatcherType_NOT_REGEXP
+		default:
+			return nil, errUnknownMatcher
+		}
+		matchers = append(matchers, &rpc.TagMatcher{
+			Type:  matcherType,
+			Name:  m.Name,
+			Value: m.Value,
+		})
+	}
+	return matchers, nil
+}
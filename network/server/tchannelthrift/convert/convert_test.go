@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package convert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/generated/thrift/rpc"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamespaceRoundTrip(t *testing.T) {
+	ropts := retention.NewOptions().
+		SetRetentionPeriod(720 * time.Hour).
+		SetBlockSize(12 * time.Hour).
+		SetBufferFuture(2 * time.Minute).
+		SetBufferPast(10 * time.Minute)
+	opts := namespace.NewOptions().
+		SetRetentionOptions(ropts).
+		SetNeedsFlush(false).
+		SetNeedsRepair(true).
+		SetColdWritesEnabled(true).
+		SetSyncCommitLogWrites(true).
+		SetRollup(&namespace.Rollup{
+			SourceNamespace: ts.StringID("metrics"),
+			Resolution:      10 * time.Minute,
+			Aggregation:     encoding.AggregationAvg,
+		})
+	md := namespace.NewMetadata(ts.StringID("metrics_10m"), opts)
+
+	ns, err := ToRPCNamespace(md)
+	require.NoError(t, err)
+
+	// Round trip through the wire format to cover the optional fields
+	data, err := thrift.NewTSerializer().Write(ns)
+	require.NoError(t, err)
+	var decoded rpc.NodeNamespace
+	require.NoError(t, thrift.NewTDeserializer().Read(&decoded, data))
+
+	result, err := ToNamespaceMetadata(&decoded)
+	require.NoError(t, err)
+	require.Equal(t, "metrics_10m", result.ID().String())
+
+	resultOpts := result.Options()
+	resultRopts := resultOpts.RetentionOptions()
+	require.Equal(t, ropts.RetentionPeriod(), resultRopts.RetentionPeriod())
+	require.Equal(t, ropts.BlockSize(), resultRopts.BlockSize())
+	require.Equal(t, ropts.BufferFuture(), resultRopts.BufferFuture())
+	require.Equal(t, ropts.BufferPast(), resultRopts.BufferPast())
+	require.Equal(t, opts.NeedsBootstrap(), resultOpts.NeedsBootstrap())
+	require.False(t, resultOpts.NeedsFlush())
+	require.Equal(t, opts.WritesToCommitLog(), resultOpts.WritesToCommitLog())
+	require.Equal(t, opts.NeedsFilesetCleanup(), resultOpts.NeedsFilesetCleanup())
+	require.True(t, resultOpts.NeedsRepair())
+	require.True(t, resultOpts.ColdWritesEnabled())
+	require.True(t, resultOpts.SyncCommitLogWrites())
+
+	rollup := resultOpts.Rollup()
+	require.NotNil(t, rollup)
+	require.Equal(t, "metrics", rollup.SourceNamespace.String())
+	require.Equal(t, 10*time.Minute, rollup.Resolution)
+	require.Equal(t, encoding.AggregationAvg, rollup.Aggregation)
+}
+
+func TestNamespaceWithoutOptionalFields(t *testing.T) {
+	// Namespaces from clients that predate the optional fields
+	ns := &rpc.NodeNamespace{
+		Name:                 "metrics",
+		RetentionPeriodNanos: int64(48 * time.Hour),
+		BlockSizeNanos:       int64(2 * time.Hour),
+	}
+	md, err := ToNamespaceMetadata(ns)
+	require.NoError(t, err)
+	require.False(t, md.Options().ColdWritesEnabled())
+	require.False(t, md.Options().SyncCommitLogWrites())
+	require.Nil(t, md.Options().Rollup())
+}
+
+func TestNamespaceInvalidRollup(t *testing.T) {
+	ns := &rpc.NodeNamespace{
+		Name:                 "metrics_10m",
+		RetentionPeriodNanos: int64(48 * time.Hour),
+		BlockSizeNanos:       int64(2 * time.Hour),
+		Rollup: &rpc.NodeNamespaceRollup{
+			SourceNamespace: "metrics",
+			Aggregation:     rpc.AggregationType_AVG,
+		},
+	}
+	_, err := ToNamespaceMetadata(ns)
+	require.Equal(t, errInvalidRollup, err)
+}
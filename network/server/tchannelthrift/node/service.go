@@ -23,6 +23,7 @@ package node
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -35,6 +36,8 @@ import (
 	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
 	"github.com/m3db/m3db/storage"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
@@ -63,6 +66,9 @@ type serviceMetrics struct {
 	fetchBlocksMetadata instrument.MethodMetrics
 	repair              instrument.MethodMetrics
 	truncate            instrument.MethodMetrics
+	delete              instrument.MethodMetrics
+	writeTagged         instrument.MethodMetrics
+	fetchTagged         instrument.MethodMetrics
 	fetchBatchRaw       instrument.BatchMethodMetrics
 	writeBatchRaw       instrument.BatchMethodMetrics
 	overloadRejected    tally.Counter
@@ -76,6 +82,9 @@ func newServiceMetrics(scope tally.Scope, samplingRate float64) serviceMetrics {
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
 		repair:              instrument.NewMethodMetrics(scope, "repair", samplingRate),
 		truncate:            instrument.NewMethodMetrics(scope, "truncate", samplingRate),
+		delete:              instrument.NewMethodMetrics(scope, "delete", samplingRate),
+		writeTagged:         instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
+		fetchTagged:         instrument.NewMethodMetrics(scope, "fetchTagged", samplingRate),
 		fetchBatchRaw:       instrument.NewBatchMethodMetrics(scope, "fetchBatchRaw", samplingRate),
 		writeBatchRaw:       instrument.NewBatchMethodMetrics(scope, "writeBatchRaw", samplingRate),
 		overloadRejected:    scope.Counter("overload-rejected"),
@@ -178,6 +187,12 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
 	}
 
+	step, aggregation, err := convert.ToStepAggregation(req.Step, req.RangeType, req.Aggregation)
+	if err != nil {
+		s.metrics.fetch.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
 	encoded, err := s.db.ReadEncoded(ctx,
 		s.idPool.GetStringID(ctx, req.NameSpace),
 		s.idPool.GetStringID(ctx, req.ID),
@@ -195,7 +210,10 @@ func (s *service) Fetch(tctx thrift.Context, req *rpc.FetchRequest) (*rpc.FetchR
 
 	multiIt := s.db.Options().MultiReaderIteratorPool().Get()
 	multiIt.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromSegmentReadersIterator(encoded))
-	it := encoding.NewSeriesIterator(req.ID, start, end, []encoding.Iterator{multiIt}, nil)
+	var it encoding.Iterator = encoding.NewSeriesIterator(req.ID, start, end, []encoding.Iterator{multiIt}, nil)
+	if step > 0 {
+		it = encoding.NewDownsampleIterator(it, start, step, aggregation)
+	}
 	defer it.Close()
 
 	for it.Next() {
@@ -238,6 +256,13 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
 	}
 
+	step, aggregation, err := convert.ToStepAggregation(req.Step, req.RangeTimeType, req.Aggregation)
+	if err != nil {
+		s.metrics.fetchBatchRaw.ReportNonRetryableErrors(len(req.Ids))
+		s.metrics.fetchBatchRaw.ReportLatency(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
 	nsID := s.newID(ctx, req.NameSpace)
 
 	result := rpc.NewFetchBatchRawResult_()
@@ -264,6 +289,21 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 			continue
 		}
 
+		if step > 0 {
+			seg, err := s.downsample(ctx, tsID, encoded, start, end, step, aggregation)
+			if err != nil {
+				rawResult.Err = convert.ToRPCError(err)
+				retryableErrors++
+				continue
+			}
+			success++
+			rawResult.Segments = make([]*rpc.Segments, 0, 1)
+			if seg != nil {
+				rawResult.Segments = append(rawResult.Segments, seg)
+			}
+			continue
+		}
+
 		var streamErr error
 		segments := make([]*rpc.Segments, 0, len(encoded))
 		for _, readers := range encoded {
@@ -300,6 +340,43 @@ func (s *service) FetchBatchRaw(tctx thrift.Context, req *rpc.FetchBatchRawReque
 	return result, nil
 }
 
+// downsample aggregates the encoded values of a series within the range
+// into steps and re-encodes them as a single merged segment.
+func (s *service) downsample(
+	ctx context.Context,
+	id ts.ID,
+	encoded [][]xio.SegmentReader,
+	start, end time.Time,
+	step time.Duration,
+	aggregation encoding.AggregationType,
+) (*rpc.Segments, error) {
+	multiIt := s.db.Options().MultiReaderIteratorPool().Get()
+	multiIt.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromSegmentReadersIterator(encoded))
+	seriesIt := encoding.NewSeriesIterator(id.String(), start, end, []encoding.Iterator{multiIt}, nil)
+	it := encoding.NewDownsampleIterator(seriesIt, start, step, aggregation)
+	defer it.Close()
+
+	encoder := s.db.Options().EncoderPool().Get()
+	encoder.Reset(start, 0)
+	for it.Next() {
+		dp, unit, _ := it.Current()
+		if err := encoder.Encode(dp, unit, nil); err != nil {
+			encoder.Close()
+			return nil, err
+		}
+	}
+	if err := it.Err(); err != nil {
+		encoder.Close()
+		return nil, err
+	}
+
+	// The response references the bytes of the segment so the reader is
+	// only finalized once the request completes
+	reader := xio.NewSegmentReader(encoder.Discard())
+	ctx.RegisterFinalizer(reader)
+	return convert.ToSegments([]xio.SegmentReader{reader})
+}
+
 func (s *service) FetchBlocksRaw(tctx thrift.Context, req *rpc.FetchBlocksRawRequest) (*rpc.FetchBlocksRawResult_, error) {
 	if s.db.IsOverloaded() {
 		s.metrics.overloadRejected.Inc(1)
@@ -596,6 +673,135 @@ func (s *service) Truncate(tctx thrift.Context, req *rpc.TruncateRequest) (r *rp
 	return res, nil
 }
 
+func (s *service) Delete(tctx thrift.Context, req *rpc.DeleteRequest) error {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeType)
+	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeType)
+
+	if rangeStartErr != nil || rangeEndErr != nil {
+		s.metrics.delete.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
+	}
+
+	if err := s.db.Delete(
+		s.idPool.GetStringID(ctx, req.NameSpace), s.idPool.GetStringID(ctx, req.ID),
+		start, end,
+	); err != nil {
+		s.metrics.delete.ReportError(s.nowFn().Sub(callStart))
+		return convert.ToRPCError(err)
+	}
+
+	s.metrics.delete.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return nil
+}
+
+func (s *service) WriteTagged(tctx thrift.Context, req *rpc.WriteTaggedRequest) error {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	if req.Datapoint == nil {
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewBadRequestError(fmt.Errorf("requires datapoint"))
+	}
+
+	dp := req.Datapoint
+	unit, unitErr := convert.ToUnit(dp.TimestampTimeType)
+
+	if unitErr != nil {
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewBadRequestError(unitErr)
+	}
+
+	d, err := unit.Value()
+	if err != nil {
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return tterrors.NewBadRequestError(err)
+	}
+
+	if err = s.db.WriteTagged(
+		ctx, s.idPool.GetStringID(ctx, req.NameSpace), s.idPool.GetStringID(ctx, req.ID),
+		convert.ToTags(req.Tags), xtime.FromNormalizedTime(dp.Timestamp, d), dp.Value,
+		unit, dp.Annotation,
+	); err != nil {
+		s.metrics.writeTagged.ReportError(s.nowFn().Sub(callStart))
+		return convert.ToRPCError(err)
+	}
+
+	s.metrics.writeTagged.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return nil
+}
+
+func (s *service) FetchTagged(tctx thrift.Context, req *rpc.FetchTaggedRequest) (*rpc.FetchTaggedResult_, error) {
+	callStart := s.nowFn()
+	ctx := tchannelthrift.Context(tctx)
+
+	start, rangeStartErr := convert.ToTime(req.RangeStart, req.RangeTimeType)
+	end, rangeEndErr := convert.ToTime(req.RangeEnd, req.RangeTimeType)
+
+	if rangeStartErr != nil || rangeEndErr != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(xerrors.FirstError(rangeStartErr, rangeEndErr))
+	}
+
+	query, err := convert.ToIndexQuery(req.Matchers)
+	if err != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, tterrors.NewBadRequestError(err)
+	}
+
+	nsID := s.newID(ctx, req.NameSpace)
+	queryResults, err := s.db.QueryIDs(ctx, nsID, query, index.QueryOptions{
+		StartInclusive: start,
+		EndExclusive:   end,
+		Limit:          int(req.Limit),
+	})
+	if err != nil {
+		s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+		return nil, convert.ToRPCError(err)
+	}
+
+	result := rpc.NewFetchTaggedResult_()
+	result.Exhaustive = queryResults.Exhaustive
+	result.Elements = make([]*rpc.FetchTaggedIDResult_, 0, len(queryResults.Results))
+
+	for _, r := range queryResults.Results {
+		elem := rpc.NewFetchTaggedIDResult_()
+		elem.ID = []byte(r.ID.String())
+		elem.Tags = convert.ToRPCTags(r.Tags)
+		elem.Segments = make([]*rpc.Segments, 0)
+		result.Elements = append(result.Elements, elem)
+
+		if !req.FetchData {
+			continue
+		}
+
+		encoded, err := s.db.ReadEncoded(ctx, nsID, r.ID, start, end)
+		if err != nil {
+			s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+			return nil, convert.ToRPCError(err)
+		}
+		for _, readers := range encoded {
+			seg, err := convert.ToSegments(readers)
+			if err != nil {
+				s.metrics.fetchTagged.ReportError(s.nowFn().Sub(callStart))
+				return nil, convert.ToRPCError(err)
+			}
+			if seg == nil {
+				continue
+			}
+			elem.Segments = append(elem.Segments, seg)
+		}
+	}
+
+	s.metrics.fetchTagged.ReportSuccess(s.nowFn().Sub(callStart))
+
+	return result, nil
+}
+
 func (s *service) GetPersistRateLimit(
 	ctx thrift.Context,
 ) (*rpc.NodePersistRateLimitResult_, error) {
@@ -654,6 +860,65 @@ func (s *service) SetWriteNewSeriesAsync(
 	return s.GetWriteNewSeriesAsync(ctx)
 }
 
+func (s *service) GetNamespaces(
+	ctx thrift.Context,
+) (*rpc.NodeNamespacesResult_, error) {
+	namespaces := s.db.Namespaces()
+	sort.Sort(storage.NamespacesByID(namespaces))
+	result := &rpc.NodeNamespacesResult_{
+		Namespaces: make([]*rpc.NodeNamespace, 0, len(namespaces)),
+	}
+	for _, n := range namespaces {
+		md := namespace.NewMetadata(n.ID(), n.Options())
+		ns, err := convert.ToRPCNamespace(md)
+		if err != nil {
+			return nil, convert.ToRPCError(err)
+		}
+		result.Namespaces = append(result.Namespaces, ns)
+	}
+	return result, nil
+}
+
+func (s *service) AddNamespace(
+	ctx thrift.Context,
+	req *rpc.NodeAddNamespaceRequest,
+) error {
+	md, err := convert.ToNamespaceMetadata(req.Namespace)
+	if err != nil {
+		return tterrors.NewBadRequestError(err)
+	}
+
+	// NB: When the database watches a namespace registry the namespace is
+	// added to the registry and the database picks it up from the watch
+	if registry := s.db.Options().NamespaceRegistry(); registry != nil {
+		err = registry.Add(md)
+	} else {
+		err = s.db.AddNamespace(md)
+	}
+	if err != nil {
+		return convert.ToRPCError(err)
+	}
+	return nil
+}
+
+func (s *service) RemoveNamespace(
+	ctx thrift.Context,
+	req *rpc.NodeRemoveNamespaceRequest,
+) error {
+	id := ts.StringID(req.Name)
+
+	var err error
+	if registry := s.db.Options().NamespaceRegistry(); registry != nil {
+		err = registry.Remove(id)
+	} else {
+		err = s.db.RemoveNamespace(id)
+	}
+	if err != nil {
+		return convert.ToRPCError(err)
+	}
+	return nil
+}
+
 func (s *service) isOverloaded() bool {
 	// NB(xichen): for now we only use the database load to determine
 	// whether the server is overloaded. In the future we may also take
@@ -21,18 +21,23 @@
 package node
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/digest"
 	"github.com/m3db/m3db/generated/thrift/rpc"
 	"github.com/m3db/m3db/network/server/tchannelthrift"
+	tterrors "github.com/m3db/m3db/network/server/tchannelthrift/errors"
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/storage"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
 	xtime "github.com/m3db/m3x/time"
-	"github.com/m3db/m3db/digest"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -136,6 +141,80 @@ func TestServiceFetch(t *testing.T) {
 	}
 }
 
+func TestServiceFetchDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+
+	enc := testServiceOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+
+	nsID := "metrics"
+
+	values := []struct {
+		t time.Time
+		v float64
+	}{
+		{start.Add(10 * time.Second), 1.0},
+		{start.Add(20 * time.Second), 2.0},
+		{start.Add(70 * time.Second), 3.0},
+	}
+	for _, v := range values {
+		dp := ts.Datapoint{
+			Timestamp: v.t,
+			Value:     v.v,
+		}
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ts.NewIDMatcher(nsID), ts.NewIDMatcher("foo"), start, end).
+		Return([][]xio.SegmentReader{
+			[]xio.SegmentReader{enc.Stream()},
+		}, nil)
+
+	r, err := service.Fetch(tctx, &rpc.FetchRequest{
+		RangeStart:     start.Unix(),
+		RangeEnd:       end.Unix(),
+		RangeType:      rpc.TimeType_UNIX_SECONDS,
+		NameSpace:      nsID,
+		ID:             "foo",
+		ResultTimeType: rpc.TimeType_UNIX_SECONDS,
+		Step:           60,
+		Aggregation:    rpc.AggregationType_SUM,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(r.Datapoints))
+	assert.Equal(t, start, time.Unix(r.Datapoints[0].Timestamp, 0))
+	assert.Equal(t, 3.0, r.Datapoints[0].Value)
+	assert.Equal(t, start.Add(time.Minute), time.Unix(r.Datapoints[1].Timestamp, 0))
+	assert.Equal(t, 3.0, r.Datapoints[1].Value)
+
+	// Step without an aggregation is a bad request
+	_, err = service.Fetch(tctx, &rpc.FetchRequest{
+		RangeStart: start.Unix(),
+		RangeEnd:   end.Unix(),
+		RangeType:  rpc.TimeType_UNIX_SECONDS,
+		NameSpace:  nsID,
+		ID:         "foo",
+		Step:       60,
+	})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
+
 func TestServiceFetchBatchRaw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -228,6 +307,75 @@ func TestServiceFetchBatchRaw(t *testing.T) {
 	}
 }
 
+func TestServiceFetchBatchRawDownsampled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	end := start.Add(2 * time.Hour)
+
+	nsID := "metrics"
+
+	enc := testServiceOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	for i, v := range []float64{4.0, 2.0, 6.0} {
+		dp := ts.Datapoint{
+			Timestamp: start.Add(time.Duration(i*20) * time.Second),
+			Value:     v,
+		}
+		require.NoError(t, enc.Encode(dp, xtime.Second, nil))
+	}
+	// Points past the end of the range must not be downsampled into the result
+	require.NoError(t, enc.Encode(ts.Datapoint{
+		Timestamp: end.Add(10 * time.Second),
+		Value:     100.0,
+	}, xtime.Second, nil))
+
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ts.NewIDMatcher(nsID), ts.NewIDMatcher("foo"), start, end).
+		Return([][]xio.SegmentReader{
+			[]xio.SegmentReader{enc.Stream()},
+		}, nil)
+
+	r, err := service.FetchBatchRaw(tctx, &rpc.FetchBatchRawRequest{
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		NameSpace:     []byte(nsID),
+		Ids:           [][]byte{[]byte("foo")},
+		Step:          60,
+		Aggregation:   rpc.AggregationType_MAX,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1, len(r.Elements))
+	elem := r.Elements[0]
+	assert.Nil(t, elem.Err)
+	require.Equal(t, 1, len(elem.Segments))
+	require.NotNil(t, elem.Segments[0].Merged)
+
+	merged := elem.Segments[0].Merged
+	iter := testServiceOpts.ReaderIteratorPool().Get()
+	iter.Reset(bytes.NewReader(append(merged.Head, merged.Tail...)))
+	defer iter.Close()
+
+	require.True(t, iter.Next())
+	dp, _, _ := iter.Current()
+	assert.True(t, start.Equal(dp.Timestamp))
+	assert.Equal(t, 6.0, dp.Value)
+	assert.False(t, iter.Next())
+	require.NoError(t, iter.Err())
+}
+
 func TestServiceFetchBlocksRaw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -568,6 +716,50 @@ func TestServiceTruncate(t *testing.T) {
 	assert.Equal(t, truncated, r.NumSeries)
 }
 
+func TestServiceDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+
+	id := "foo"
+
+	start := time.Now().Truncate(time.Second).Add(-time.Hour)
+	end := start.Add(30 * time.Minute)
+
+	mockDB.EXPECT().
+		Delete(ts.NewIDMatcher(nsID), ts.NewIDMatcher(id), start, end).
+		Return(nil)
+
+	err := service.Delete(tctx, &rpc.DeleteRequest{
+		NameSpace:  nsID,
+		ID:         id,
+		RangeStart: start.Unix(),
+		RangeEnd:   end.Unix(),
+		RangeType:  rpc.TimeType_UNIX_SECONDS,
+	})
+	require.NoError(t, err)
+
+	err = service.Delete(tctx, &rpc.DeleteRequest{
+		NameSpace:  nsID,
+		ID:         id,
+		RangeStart: start.Unix(),
+		RangeEnd:   end.Unix(),
+		RangeType:  rpc.TimeType(-1),
+	})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
+
 func TestServiceSetPersistRateLimit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -630,3 +822,191 @@ func TestServiceSetWriteNewSeriesAsync(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, true, setResp.WriteNewSeriesAsync)
 }
+
+func TestServiceGetNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	ropts := retention.NewOptions().SetBlockSize(time.Hour)
+	nsOpts := namespace.NewOptions().SetRetentionOptions(ropts)
+
+	var namespaces []storage.Namespace
+	for _, id := range []string{"metrics", "default"} {
+		ns := storage.NewMockNamespace(ctrl)
+		ns.EXPECT().ID().Return(ts.StringID(id)).AnyTimes()
+		ns.EXPECT().Options().Return(nsOpts).AnyTimes()
+		namespaces = append(namespaces, ns)
+	}
+	mockDB.EXPECT().Namespaces().Return(namespaces)
+
+	result, err := service.GetNamespaces(tctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(result.Namespaces))
+	assert.Equal(t, "default", result.Namespaces[0].Name)
+	assert.Equal(t, "metrics", result.Namespaces[1].Name)
+	assert.Equal(t, int64(time.Hour), result.Namespaces[0].BlockSizeNanos)
+	assert.Equal(t, int64(ropts.RetentionPeriod()), result.Namespaces[0].RetentionPeriodNanos)
+}
+
+func TestServiceAddRemoveNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	ns := &rpc.NodeNamespace{
+		Name:                 "metrics",
+		RetentionPeriodNanos: int64(48 * time.Hour),
+		BlockSizeNanos:       int64(2 * time.Hour),
+		NeedsBootstrap:       true,
+	}
+	mockDB.EXPECT().AddNamespace(gomock.Any()).Do(func(md namespace.Metadata) {
+		assert.Equal(t, "metrics", md.ID().String())
+		assert.Equal(t, 2*time.Hour, md.Options().RetentionOptions().BlockSize())
+		assert.True(t, md.Options().NeedsBootstrap())
+		assert.False(t, md.Options().NeedsFlush())
+	}).Return(nil)
+
+	err := service.AddNamespace(tctx, &rpc.NodeAddNamespaceRequest{Namespace: ns})
+	require.NoError(t, err)
+
+	// Invalid namespaces are rejected before reaching the database
+	ns.BlockSizeNanos = 0
+	err = service.AddNamespace(tctx, &rpc.NodeAddNamespaceRequest{Namespace: ns})
+	require.Error(t, err)
+	assert.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+
+	mockDB.EXPECT().RemoveNamespace(ts.StringID("metrics")).Return(nil)
+	err = service.RemoveNamespace(tctx, &rpc.NodeRemoveNamespaceRequest{Name: "metrics"})
+	require.NoError(t, err)
+}
+
+func TestServiceWriteTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	nsID := "metrics"
+	id := "foo"
+	at := time.Now().Truncate(time.Second)
+	value := 42.42
+
+	mockDB.EXPECT().
+		WriteTagged(ctx, ts.NewIDMatcher(nsID), ts.NewIDMatcher(id), gomock.Any(),
+			at, value, xtime.Second, nil).
+		Do(func(_ interface{}, _, _ ts.ID, tags ts.Tags, _ time.Time, _ float64, _ xtime.Unit, _ []byte) {
+			require.Equal(t, 1, len(tags))
+			require.Equal(t, "city", tags[0].Name.String())
+			require.Equal(t, "nyc", tags[0].Value.String())
+		}).
+		Return(nil)
+
+	err := service.WriteTagged(tctx, &rpc.WriteTaggedRequest{
+		NameSpace: nsID,
+		ID:        id,
+		Tags:      []*rpc.Tag{{Name: "city", Value: "nyc"}},
+		Datapoint: &rpc.Datapoint{
+			Timestamp:         at.Unix(),
+			TimestampTimeType: rpc.TimeType_UNIX_SECONDS,
+			Value:             value,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestServiceFetchTagged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := storage.NewMockDatabase(ctrl)
+	mockDB.EXPECT().Options().Return(testServiceOpts).AnyTimes()
+
+	service := NewService(mockDB, nil).(*service)
+
+	tctx, _ := tchannelthrift.NewContext(time.Minute)
+	ctx := tchannelthrift.Context(tctx)
+	defer ctx.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	end := start.Add(2 * time.Hour)
+	nsID := "metrics"
+
+	enc := testServiceOpts.EncoderPool().Get()
+	enc.Reset(start, 0)
+	require.NoError(t, enc.Encode(ts.Datapoint{
+		Timestamp: start.Add(10 * time.Second),
+		Value:     1.0,
+	}, xtime.Second, nil))
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchRegexp, Name: "city", Value: "n.*"},
+	}}
+	mockDB.EXPECT().
+		QueryIDs(ctx, ts.NewIDMatcher(nsID), query, index.QueryOptions{
+			StartInclusive: start,
+			EndExclusive:   end,
+			Limit:          10,
+		}).
+		Return(index.QueryResults{
+			Results: []index.QueryResult{{
+				ID:   ts.StringID("foo"),
+				Tags: ts.Tags{{Name: ts.StringID("city"), Value: ts.StringID("nyc")}},
+			}},
+			Exhaustive: true,
+		}, nil)
+	mockDB.EXPECT().
+		ReadEncoded(ctx, ts.NewIDMatcher(nsID), ts.NewIDMatcher("foo"), start, end).
+		Return([][]xio.SegmentReader{{enc.Stream()}}, nil)
+
+	r, err := service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace: []byte(nsID),
+		Matchers: []*rpc.TagMatcher{
+			{Type: rpc.TagMatcherType_REGEXP, Name: "city", Value: "n.*"},
+		},
+		RangeStart:    start.Unix(),
+		RangeEnd:      end.Unix(),
+		RangeTimeType: rpc.TimeType_UNIX_SECONDS,
+		FetchData:     true,
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	require.True(t, r.Exhaustive)
+	require.Equal(t, 1, len(r.Elements))
+
+	elem := r.Elements[0]
+	require.Equal(t, "foo", string(elem.ID))
+	require.Equal(t, []*rpc.Tag{{Name: "city", Value: "nyc"}}, elem.Tags)
+	require.Equal(t, 1, len(elem.Segments))
+	require.NotNil(t, elem.Segments[0].Merged)
+
+	_, err = service.FetchTagged(tctx, &rpc.FetchTaggedRequest{
+		NameSpace: []byte(nsID),
+		Matchers:  []*rpc.TagMatcher{{Type: rpc.TagMatcherType(42), Name: "city"}},
+	})
+	require.Error(t, err)
+	require.True(t, tterrors.IsBadRequestError(err.(*rpc.Error)))
+}
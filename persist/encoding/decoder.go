@@ -33,6 +33,12 @@ type Decoder interface {
 	// DecodeIndexEntry decodes index entry
 	DecodeIndexEntry() (schema.IndexEntry, error)
 
+	// DecodeIndexSummary decodes index summary
+	DecodeIndexSummary() (schema.IndexSummary, error)
+
+	// DecodeIndexSeries decodes an index segment series
+	DecodeIndexSeries() (schema.IndexSeries, error)
+
 	// DecodeLogInfo decodes commit log info
 	DecodeLogInfo() (schema.LogInfo, error)
 
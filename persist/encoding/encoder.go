@@ -36,6 +36,12 @@ type Encoder interface {
 	// EncodeIndexEntry encodes index entry
 	EncodeIndexEntry(entry schema.IndexEntry) error
 
+	// EncodeIndexSummary encodes index summary
+	EncodeIndexSummary(summary schema.IndexSummary) error
+
+	// EncodeIndexSeries encodes an index segment series
+	EncodeIndexSeries(series schema.IndexSeries) error
+
 	// EncodeLogInfo encodes commit log info
 	EncodeLogInfo(info schema.LogInfo) error
 
@@ -32,11 +32,13 @@ import (
 )
 
 var (
-	emptyIndexInfo   schema.IndexInfo
-	emptyIndexEntry  schema.IndexEntry
-	emptyLogInfo     schema.LogInfo
-	emptyLogEntry    schema.LogEntry
-	emptyLogMetadata schema.LogMetadata
+	emptyIndexInfo    schema.IndexInfo
+	emptyIndexEntry   schema.IndexEntry
+	emptyIndexSeries  schema.IndexSeries
+	emptyIndexSummary schema.IndexSummary
+	emptyLogInfo      schema.LogInfo
+	emptyLogEntry     schema.LogEntry
+	emptyLogMetadata  schema.LogMetadata
 )
 
 type decoder struct {
@@ -93,6 +95,32 @@ func (dec *decoder) DecodeIndexEntry() (schema.IndexEntry, error) {
 	return indexEntry, nil
 }
 
+func (dec *decoder) DecodeIndexSummary() (schema.IndexSummary, error) {
+	if dec.err != nil {
+		return emptyIndexSummary, dec.err
+	}
+	numFieldsToSkip := dec.decodeRootObject(indexSummaryVersion, indexSummaryType)
+	indexSummary := dec.decodeIndexSummary()
+	dec.skip(numFieldsToSkip)
+	if dec.err != nil {
+		return emptyIndexSummary, dec.err
+	}
+	return indexSummary, nil
+}
+
+func (dec *decoder) DecodeIndexSeries() (schema.IndexSeries, error) {
+	if dec.err != nil {
+		return emptyIndexSeries, dec.err
+	}
+	numFieldsToSkip := dec.decodeRootObject(indexSeriesVersion, indexSeriesType)
+	indexSeries := dec.decodeIndexSeries()
+	dec.skip(numFieldsToSkip)
+	if dec.err != nil {
+		return emptyIndexSeries, dec.err
+	}
+	return indexSeries, nil
+}
+
 func (dec *decoder) DecodeLogInfo() (schema.LogInfo, error) {
 	if dec.err != nil {
 		return emptyLogInfo, dec.err
@@ -133,7 +161,7 @@ func (dec *decoder) DecodeLogMetadata() (schema.LogMetadata, error) {
 }
 
 func (dec *decoder) decodeIndexInfo() schema.IndexInfo {
-	numFieldsToSkip, ok := dec.checkNumFieldsFor(indexInfoType)
+	numFieldsToSkip, ok := dec.checkMinNumFields(minNumIndexInfoFields)
 	if !ok {
 		return emptyIndexInfo
 	}
@@ -141,6 +169,16 @@ func (dec *decoder) decodeIndexInfo() schema.IndexInfo {
 	indexInfo.Start = dec.decodeVarint()
 	indexInfo.BlockSize = dec.decodeVarint()
 	indexInfo.Entries = dec.decodeVarint()
+	if numFieldsToSkip >= minNumIndexInfoWithBloomFields-minNumIndexInfoFields {
+		indexInfo.Summaries = dec.decodeVarint()
+		indexInfo.BloomFilterM = dec.decodeVarUint()
+		indexInfo.BloomFilterK = dec.decodeVarUint()
+		numFieldsToSkip -= minNumIndexInfoWithBloomFields - minNumIndexInfoFields
+	}
+	if numFieldsToSkip > 0 {
+		indexInfo.Compression = dec.decodeVarint()
+		numFieldsToSkip--
+	}
 	dec.skip(numFieldsToSkip)
 	if dec.err != nil {
 		return emptyIndexInfo
@@ -166,6 +204,55 @@ func (dec *decoder) decodeIndexEntry() schema.IndexEntry {
 	return indexEntry
 }
 
+func (dec *decoder) decodeIndexSummary() schema.IndexSummary {
+	numFieldsToSkip, ok := dec.checkNumFieldsFor(indexSummaryType)
+	if !ok {
+		return emptyIndexSummary
+	}
+	var indexSummary schema.IndexSummary
+	indexSummary.Index = dec.decodeVarint()
+	indexSummary.ID = dec.decodeBytes()
+	indexSummary.IndexEntryOffset = dec.decodeVarint()
+	dec.skip(numFieldsToSkip)
+	if dec.err != nil {
+		return emptyIndexSummary
+	}
+	return indexSummary
+}
+
+func (dec *decoder) decodeIndexSeries() schema.IndexSeries {
+	numFieldsToSkip, ok := dec.checkNumFieldsFor(indexSeriesType)
+	if !ok {
+		return emptyIndexSeries
+	}
+	var indexSeries schema.IndexSeries
+	indexSeries.ID = dec.decodeBytes()
+	indexSeries.Tags = dec.decodeIndexTags()
+	dec.skip(numFieldsToSkip)
+	if dec.err != nil {
+		return emptyIndexSeries
+	}
+	return indexSeries
+}
+
+func (dec *decoder) decodeIndexTags() []schema.IndexTag {
+	numTagFields := dec.decodeArrayLen()
+	if dec.err == nil && numTagFields%2 != 0 {
+		dec.err = fmt.Errorf("odd number of tag fields %d", numTagFields)
+	}
+	if dec.err != nil || numTagFields == 0 {
+		return nil
+	}
+	tags := make([]schema.IndexTag, 0, numTagFields/2)
+	for i := 0; i < numTagFields/2 && dec.err == nil; i++ {
+		var tag schema.IndexTag
+		tag.Name = dec.decodeBytes()
+		tag.Value = dec.decodeBytes()
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
 func (dec *decoder) decodeLogInfo() schema.LogInfo {
 	numFieldsToSkip, ok := dec.checkNumFieldsFor(logInfoType)
 	if !ok {
@@ -203,7 +290,7 @@ func (dec *decoder) decodeLogEntry() schema.LogEntry {
 }
 
 func (dec *decoder) decodeLogMetadata() schema.LogMetadata {
-	numFieldsToSkip, ok := dec.checkNumFieldsFor(logMetadataType)
+	numFieldsToSkip, ok := dec.checkMinNumFields(minNumLogMetadataFields)
 	if !ok {
 		return emptyLogMetadata
 	}
@@ -211,6 +298,10 @@ func (dec *decoder) decodeLogMetadata() schema.LogMetadata {
 	logMetadata.ID = dec.decodeBytes()
 	logMetadata.Namespace = dec.decodeBytes()
 	logMetadata.Shard = uint32(dec.decodeVarUint())
+	if numFieldsToSkip > 0 {
+		logMetadata.Tags = dec.decodeIndexTags()
+		numFieldsToSkip--
+	}
 	dec.skip(numFieldsToSkip)
 	if dec.err != nil {
 		return emptyLogMetadata
@@ -249,11 +340,14 @@ func (dec *decoder) checkVersion(expected int) {
 }
 
 func (dec *decoder) checkNumFieldsFor(objType objectType) (int, bool) {
+	return dec.checkMinNumFields(numFieldsForType(objType))
+}
+
+func (dec *decoder) checkMinNumFields(expected int) (int, bool) {
 	actual := dec.decodeNumObjectFields()
 	if dec.err != nil {
 		return 0, false
 	}
-	expected := numFieldsForType(objType)
 	if expected > actual {
 		dec.err = fmt.Errorf("number of fields mismatch: expected %d actual %d", expected, actual)
 		return 0, false
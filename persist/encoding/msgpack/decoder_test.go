@@ -23,6 +23,8 @@ package msgpack
 import (
 	"testing"
 
+	"github.com/m3db/m3db/persist/schema"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -118,6 +120,58 @@ func TestDecodeIndexInfoMoreFieldsThanExpected(t *testing.T) {
 	require.Equal(t, testIndexInfo, res)
 }
 
+func TestDecodeIndexInfoWithoutSummaries(t *testing.T) {
+	var (
+		enc = testEncoder(t).(*encoder)
+		dec = testDecoder(t, nil)
+	)
+
+	// Encode index info as written before summaries and bloom filters were added
+	enc.encodeRootObject(indexInfoVersion, indexInfoType)
+	enc.encodeArrayLenFn(minNumIndexInfoFields)
+	enc.encodeVarintFn(testIndexInfo.Start)
+	enc.encodeVarintFn(testIndexInfo.BlockSize)
+	enc.encodeVarintFn(testIndexInfo.Entries)
+	require.NoError(t, enc.err)
+
+	// Verify the summaries and bloom filter parameters are left empty
+	dec.Reset(enc.Bytes())
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	expected := schema.IndexInfo{
+		Start:     testIndexInfo.Start,
+		BlockSize: testIndexInfo.BlockSize,
+		Entries:   testIndexInfo.Entries,
+	}
+	require.Equal(t, expected, res)
+}
+
+func TestDecodeIndexInfoWithoutCompression(t *testing.T) {
+	var (
+		enc = testEncoder(t).(*encoder)
+		dec = testDecoder(t, nil)
+	)
+
+	// Encode index info as written before compression was added
+	enc.encodeRootObject(indexInfoVersion, indexInfoType)
+	enc.encodeArrayLenFn(minNumIndexInfoWithBloomFields)
+	enc.encodeVarintFn(testIndexInfo.Start)
+	enc.encodeVarintFn(testIndexInfo.BlockSize)
+	enc.encodeVarintFn(testIndexInfo.Entries)
+	enc.encodeVarintFn(testIndexInfo.Summaries)
+	enc.encodeVarUintFn(testIndexInfo.BloomFilterM)
+	enc.encodeVarUintFn(testIndexInfo.BloomFilterK)
+	require.NoError(t, enc.err)
+
+	// Verify the compression is left empty which is no compression
+	dec.Reset(enc.Bytes())
+	res, err := dec.DecodeIndexInfo()
+	require.NoError(t, err)
+	expected := testIndexInfo
+	expected.Compression = 0
+	require.Equal(t, expected, res)
+}
+
 func TestDecodeIndexEntryMoreFieldsThanExpected(t *testing.T) {
 	var (
 		enc = testEncoder(t).(*encoder)
@@ -190,6 +244,29 @@ func TestDecodeLogMetadataMoreFieldsThanExpected(t *testing.T) {
 	require.Equal(t, testLogMetadata, res)
 }
 
+func TestDecodeLogMetadataWithoutTags(t *testing.T) {
+	var (
+		enc = testEncoder(t).(*encoder)
+		dec = testDecoder(t, nil)
+	)
+
+	// Encode log metadata as written before tags were added
+	enc.encodeRootObject(logMetadataVersion, logMetadataType)
+	enc.encodeArrayLenFn(minNumLogMetadataFields)
+	enc.encodeBytesFn(testLogMetadata.ID)
+	enc.encodeBytesFn(testLogMetadata.Namespace)
+	enc.encodeVarUintFn(uint64(testLogMetadata.Shard))
+	require.NoError(t, enc.err)
+
+	// Verify the tags are left empty
+	dec.Reset(enc.Bytes())
+	res, err := dec.DecodeLogMetadata()
+	require.NoError(t, err)
+	expected := testLogMetadata
+	expected.Tags = nil
+	require.Equal(t, expected, res)
+}
+
 func TestDecodeLogEntryFewerFieldsThanExpected(t *testing.T) {
 	var (
 		enc = testEncoder(t).(*encoder)
@@ -95,6 +95,24 @@ func (enc *encoder) EncodeIndexEntry(entry schema.IndexEntry) error {
 	return enc.err
 }
 
+func (enc *encoder) EncodeIndexSummary(summary schema.IndexSummary) error {
+	if enc.err != nil {
+		return enc.err
+	}
+	enc.encodeRootObject(indexSummaryVersion, indexSummaryType)
+	enc.encodeIndexSummary(summary)
+	return enc.err
+}
+
+func (enc *encoder) EncodeIndexSeries(series schema.IndexSeries) error {
+	if enc.err != nil {
+		return enc.err
+	}
+	enc.encodeRootObject(indexSeriesVersion, indexSeriesType)
+	enc.encodeIndexSeries(series)
+	return enc.err
+}
+
 func (enc *encoder) EncodeLogInfo(info schema.LogInfo) error {
 	if enc.err != nil {
 		return enc.err
@@ -127,6 +145,10 @@ func (enc *encoder) encodeIndexInfo(info schema.IndexInfo) {
 	enc.encodeVarintFn(info.Start)
 	enc.encodeVarintFn(info.BlockSize)
 	enc.encodeVarintFn(info.Entries)
+	enc.encodeVarintFn(info.Summaries)
+	enc.encodeVarUintFn(info.BloomFilterM)
+	enc.encodeVarUintFn(info.BloomFilterK)
+	enc.encodeVarintFn(info.Compression)
 }
 
 func (enc *encoder) encodeIndexEntry(entry schema.IndexEntry) {
@@ -138,6 +160,28 @@ func (enc *encoder) encodeIndexEntry(entry schema.IndexEntry) {
 	enc.encodeVarintFn(entry.Checksum)
 }
 
+func (enc *encoder) encodeIndexSummary(summary schema.IndexSummary) {
+	enc.encodeNumObjectFieldsForFn(indexSummaryType)
+	enc.encodeVarintFn(summary.Index)
+	enc.encodeBytesFn(summary.ID)
+	enc.encodeVarintFn(summary.IndexEntryOffset)
+}
+
+func (enc *encoder) encodeIndexSeries(series schema.IndexSeries) {
+	enc.encodeNumObjectFieldsForFn(indexSeriesType)
+	enc.encodeBytesFn(series.ID)
+	enc.encodeIndexTags(series.Tags)
+}
+
+func (enc *encoder) encodeIndexTags(tags []schema.IndexTag) {
+	// Tags are encoded as a flat array of alternating names and values
+	enc.encodeArrayLenFn(2 * len(tags))
+	for _, tag := range tags {
+		enc.encodeBytesFn(tag.Name)
+		enc.encodeBytesFn(tag.Value)
+	}
+}
+
 func (enc *encoder) encodeLogInfo(info schema.LogInfo) {
 	enc.encodeNumObjectFieldsForFn(logInfoType)
 	enc.encodeVarintFn(info.Start)
@@ -161,6 +205,7 @@ func (enc *encoder) encodeLogMetadata(metadata schema.LogMetadata) {
 	enc.encodeBytesFn(metadata.ID)
 	enc.encodeBytesFn(metadata.Namespace)
 	enc.encodeVarUintFn(uint64(metadata.Shard))
+	enc.encodeIndexTags(metadata.Tags)
 }
 
 func (enc *encoder) encodeRootObject(version int, objType objectType) {
@@ -68,6 +68,10 @@ func testExpectedResultForIndexInfo(t *testing.T, indexInfo schema.IndexInfo) []
 		indexInfo.Start,
 		indexInfo.BlockSize,
 		indexInfo.Entries,
+		indexInfo.Summaries,
+		indexInfo.BloomFilterM,
+		indexInfo.BloomFilterK,
+		indexInfo.Compression,
 	}
 }
 
@@ -85,6 +89,18 @@ func testExpectedResultForIndexEntry(t *testing.T, indexEntry schema.IndexEntry)
 	}
 }
 
+func testExpectedResultForIndexSummary(t *testing.T, indexSummary schema.IndexSummary) []interface{} {
+	return []interface{}{
+		int64(indexSummaryVersion),
+		numFieldsForType(rootObjectType),
+		int64(indexSummaryType),
+		numFieldsForType(indexSummaryType),
+		indexSummary.Index,
+		indexSummary.ID,
+		indexSummary.IndexEntryOffset,
+	}
+}
+
 func testExpectedResultForLogInfo(t *testing.T, logInfo schema.LogInfo) []interface{} {
 	return []interface{}{
 		int64(logInfoVersion),
@@ -114,7 +130,7 @@ func testExpectedResultForLogEntry(t *testing.T, logEntry schema.LogEntry) []int
 }
 
 func testExpectedResultForLogMetadata(t *testing.T, logMetadata schema.LogMetadata) []interface{} {
-	return []interface{}{
+	result := []interface{}{
 		int64(logMetadataVersion),
 		numFieldsForType(rootObjectType),
 		int64(logMetadataType),
@@ -122,7 +138,12 @@ func testExpectedResultForLogMetadata(t *testing.T, logMetadata schema.LogMetada
 		logMetadata.ID,
 		logMetadata.Namespace,
 		uint64(logMetadata.Shard),
+		2 * len(logMetadata.Tags),
 	}
+	for _, tag := range logMetadata.Tags {
+		result = append(result, tag.Name, tag.Value)
+	}
+	return result
 }
 
 func TestEncodeIndexInfo(t *testing.T) {
@@ -139,6 +160,13 @@ func TestEncodeIndexEntry(t *testing.T) {
 	require.Equal(t, expected, *actual)
 }
 
+func TestEncodeIndexSummary(t *testing.T) {
+	enc, actual := testCapturingEncoder(t)
+	require.NoError(t, enc.EncodeIndexSummary(testIndexSummary))
+	expected := testExpectedResultForIndexSummary(t, testIndexSummary)
+	require.Equal(t, expected, *actual)
+}
+
 func TestEncodeLogInfo(t *testing.T) {
 	enc, actual := testCapturingEncoder(t)
 	require.NoError(t, enc.EncodeLogInfo(testLogInfo))
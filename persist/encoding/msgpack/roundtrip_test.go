@@ -32,9 +32,13 @@ import (
 
 var (
 	testIndexInfo = schema.IndexInfo{
-		Start:     time.Now().UnixNano(),
-		BlockSize: int64(2 * time.Hour),
-		Entries:   2000000,
+		Start:        time.Now().UnixNano(),
+		BlockSize:    int64(2 * time.Hour),
+		Entries:      2000000,
+		Summaries:    31250,
+		BloomFilterM: 16287552,
+		BloomFilterK: 6,
+		Compression:  1,
 	}
 
 	testIndexEntry = schema.IndexEntry{
@@ -45,6 +49,20 @@ var (
 		Checksum: 134245634534,
 	}
 
+	testIndexSummary = schema.IndexSummary{
+		Index:            64,
+		ID:               []byte("testIndexSummary"),
+		IndexEntryOffset: 4096,
+	}
+
+	testIndexSeries = schema.IndexSeries{
+		ID: []byte("testIndexSeries"),
+		Tags: []schema.IndexTag{
+			{Name: []byte("city"), Value: []byte("nyc")},
+			{Name: []byte("host"), Value: []byte("a")},
+		},
+	}
+
 	testLogInfo = schema.LogInfo{
 		Start:    time.Now().UnixNano(),
 		Duration: int64(2 * time.Hour),
@@ -65,6 +83,9 @@ var (
 		ID:        []byte("testLogMetadata"),
 		Namespace: []byte("testNamespace"),
 		Shard:     123,
+		Tags: []schema.IndexTag{
+			{Name: []byte("city"), Value: []byte("nyc")},
+		},
 	}
 )
 
@@ -100,6 +121,30 @@ func TestIndexEntryRoundtrip(t *testing.T) {
 	require.Equal(t, testIndexEntry, res)
 }
 
+func TestIndexSummaryRoundtrip(t *testing.T) {
+	var (
+		enc = testEncoder(t)
+		dec = testDecoder(t, nil)
+	)
+	require.NoError(t, enc.EncodeIndexSummary(testIndexSummary))
+	dec.Reset(enc.Bytes())
+	res, err := dec.DecodeIndexSummary()
+	require.NoError(t, err)
+	require.Equal(t, testIndexSummary, res)
+}
+
+func TestIndexSeriesRoundtrip(t *testing.T) {
+	var (
+		enc = testEncoder(t)
+		dec = testDecoder(t, nil)
+	)
+	require.NoError(t, enc.EncodeIndexSeries(testIndexSeries))
+	dec.Reset(enc.Bytes())
+	res, err := dec.DecodeIndexSeries()
+	require.NoError(t, err)
+	require.Equal(t, testIndexSeries, res)
+}
+
 func TestLogInfoRoundtrip(t *testing.T) {
 	var (
 		enc = testEncoder(t)
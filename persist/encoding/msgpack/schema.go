@@ -21,11 +21,13 @@
 package msgpack
 
 const (
-	indexInfoVersion   = 1
-	indexEntryVersion  = 1
-	logInfoVersion     = 1
-	logEntryVersion    = 1
-	logMetadataVersion = 1
+	indexInfoVersion    = 2
+	indexEntryVersion   = 1
+	indexSeriesVersion  = 1
+	indexSummaryVersion = 1
+	logInfoVersion      = 1
+	logEntryVersion     = 1
+	logMetadataVersion  = 1
 )
 
 type objectType int
@@ -38,18 +40,35 @@ const (
 	logInfoType
 	logEntryType
 	logMetadataType
+	indexSeriesType
+	indexSummaryType
 
 	// Total number of object types
 	numObjectTypes = iota
 )
 
 const (
-	numRootObjectFields  = 2
-	numIndexInfoFields   = 3
-	numIndexEntryFields  = 5
-	numLogInfoFields     = 3
-	numLogEntryFields    = 7
-	numLogMetadataFields = 3
+	numRootObjectFields   = 2
+	numIndexInfoFields    = 7
+	numIndexEntryFields   = 5
+	numLogInfoFields      = 3
+	numLogEntryFields     = 7
+	numLogMetadataFields  = 4
+	numIndexSeriesFields  = 2
+	numIndexSummaryFields = 3
+)
+
+// Commit logs written before series tags were recorded have fewer log
+// metadata fields, these are decoded with the tags left empty
+const minNumLogMetadataFields = 3
+
+// Filesets written before index summaries and bloom filters were added have
+// fewer index info fields, these are decoded with the new fields left empty
+// and filesets written before compression was added are decoded as having no
+// compression
+const (
+	minNumIndexInfoFields          = 3
+	minNumIndexInfoWithBloomFields = 6
 )
 
 var numObjectFields []int
@@ -71,4 +90,6 @@ func init() {
 	setNumFieldsForType(logInfoType, numLogInfoFields)
 	setNumFieldsForType(logEntryType, numLogEntryFields)
 	setNumFieldsForType(logMetadataType, numLogMetadataFields)
+	setNumFieldsForType(indexSeriesType, numIndexSeriesFields)
+	setNumFieldsForType(indexSummaryType, numIndexSummaryFields)
 }
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"errors"
+	"math"
+
+	"github.com/spaolacci/murmur3"
+)
+
+var (
+	// errBloomFilterSizeMismatch returned when the bloom filter bits do not match its parameters
+	errBloomFilterSizeMismatch = errors.New("bloom filter size does not match parameters")
+)
+
+// bloomFilter is a bloom filter over the IDs of a fileset, it uses double
+// hashing of the two halves of a 128 bit murmur3 hash to derive k hashes.
+type bloomFilter struct {
+	m    uint64
+	k    uint64
+	bits []byte
+}
+
+// bloomFilterParams returns the number of bits m and number of hashes k for
+// a bloom filter of n elements with the given false positive rate.
+func bloomFilterParams(n int, falsePositiveRate float64) (uint64, uint64) {
+	if n < 1 {
+		n = 1
+	}
+	m := math.Ceil(-float64(n) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Floor(m/float64(n)*math.Ln2+0.5))
+	return uint64(m), uint64(k)
+}
+
+func newBloomFilter(m, k uint64) *bloomFilter {
+	return &bloomFilter{m: m, k: k, bits: make([]byte, bloomFilterBytesLen(m))}
+}
+
+func newBloomFilterFromBytes(m, k uint64, bits []byte) (*bloomFilter, error) {
+	if m == 0 || k == 0 || uint64(len(bits)) != bloomFilterBytesLen(m) {
+		return nil, errBloomFilterSizeMismatch
+	}
+	return &bloomFilter{m: m, k: k, bits: bits}, nil
+}
+
+func bloomFilterBytesLen(m uint64) uint64 {
+	return (m + 7) / 8
+}
+
+func (f *bloomFilter) Add(id []byte) {
+	h1, h2 := murmur3.Sum128(id)
+	for i := uint64(0); i < f.k; i++ {
+		loc := (h1 + i*h2) % f.m
+		f.bits[loc/8] |= 1 << (loc % 8)
+	}
+}
+
+// Test returns false if the ID is definitely not in the filter.
+func (f *bloomFilter) Test(id []byte) bool {
+	h1, h2 := murmur3.Sum128(id)
+	for i := uint64(0); i < f.k; i++ {
+		loc := (h1 + i*h2) % f.m
+		if f.bits[loc/8]&(1<<(loc%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) Bytes() []byte {
+	return f.bits
+}
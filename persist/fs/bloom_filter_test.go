@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBloomFilterParams(t *testing.T) {
+	m, k := bloomFilterParams(1000, 0.02)
+	require.Equal(t, uint64(8143), m)
+	require.Equal(t, uint64(6), k)
+
+	m, k = bloomFilterParams(0, 0.02)
+	require.True(t, m > 0)
+	require.True(t, k > 0)
+}
+
+func TestBloomFilterAddTest(t *testing.T) {
+	n := 1000
+	m, k := bloomFilterParams(n, 0.02)
+	filter := newBloomFilter(m, k)
+	for i := 0; i < n; i++ {
+		filter.Add([]byte(fmt.Sprintf("foo%d", i)))
+	}
+	for i := 0; i < n; i++ {
+		require.True(t, filter.Test([]byte(fmt.Sprintf("foo%d", i))))
+	}
+
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if filter.Test([]byte(fmt.Sprintf("bar%d", i))) {
+			falsePositives++
+		}
+	}
+	require.True(t, falsePositives < n/10)
+
+	restored, err := newBloomFilterFromBytes(m, k, filter.Bytes())
+	require.NoError(t, err)
+	require.True(t, restored.Test([]byte("foo0")))
+
+	_, err = newBloomFilterFromBytes(m+8, k, filter.Bytes())
+	require.Equal(t, errBloomFilterSizeMismatch, err)
+}
@@ -27,7 +27,8 @@ func (c *cloner) Clone(src FilesetID, dest FilesetID, destBlocksize time.Duratio
 		return fmt.Errorf("unable to read source fileset: %v", err)
 	}
 
-	writer := fs.NewWriter(destBlocksize, dest.PathPrefix, c.opts.BufferSize(), c.opts.FileMode(), c.opts.DirMode())
+	writer := fs.NewWriter(destBlocksize, dest.PathPrefix, c.opts.BufferSize(), c.opts.FileMode(), c.opts.DirMode(),
+		fs.CompressionNone)
 	if err := writer.Open(ts.StringID(dest.Namespace), dest.Shard, dest.Blockstart); err != nil {
 		return fmt.Errorf("unable to open fileset writer: %v", err)
 	}
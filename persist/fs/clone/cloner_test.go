@@ -82,7 +82,7 @@ func TestCloner(t *testing.T) {
 }
 
 func writeTestData(t *testing.T, bs time.Duration, src FilesetID, opts Options) {
-	w := fs.NewWriter(bs, src.PathPrefix, opts.BufferSize(), opts.FileMode(), opts.DirMode())
+	w := fs.NewWriter(bs, src.PathPrefix, opts.BufferSize(), opts.FileMode(), opts.DirMode(), fs.CompressionNone)
 	require.NoError(t, w.Open(ts.StringID(src.Namespace), src.Shard, src.Blockstart))
 	for i := 0; i < numTestSeries; i++ {
 		id := ts.StringID(fmt.Sprintf("testSeries.%d", i))
@@ -21,6 +21,7 @@
 package commitlog
 
 import (
+	"encoding/binary"
 	"errors"
 	"sync"
 	"time"
@@ -28,6 +29,7 @@ import (
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/context"
 	"github.com/m3db/m3db/ts"
+	xerrors "github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/time"
 
@@ -44,12 +46,20 @@ var (
 	timeZero = time.Time{}
 )
 
+const (
+	// maxSyncBatchSize is the maximum number of synchronous writes that
+	// share a single fsync when the write queue does not drain
+	maxSyncBatchSize = 4096
+)
+
 type newCommitLogWriterFn func(flushFn flushFn, opts Options) commitLogWriter
 
 type commitLogFailFn func(err error)
 
 type completionFn func(err error)
 
+type rotateLogsFn func(file File, err error)
+
 type commitLog struct {
 	sync.RWMutex
 	opts    Options
@@ -61,20 +71,31 @@ type commitLog struct {
 
 	newCommitLogWriterFn newCommitLogWriterFn
 	commitLogFailFn      commitLogFailFn
-	writer               commitLogWriter
 
-	// TODO(r): replace buffered channel with concurrent striped
-	// circular buffer to avoid central write lock contention
-	writes chan commitLogWrite
+	// Writes are striped by series across queues each consumed by its own
+	// writer to its own file to avoid a single write goroutine and queue
+	// limiting throughput
+	stripes []*commitLogStripe
+
+	// openMutex serializes opening files as stripes share the sequence
+	// of file indexes for a block start
+	openMutex sync.Mutex
+
+	closed   bool
+	closeErr chan error
+}
+
+type commitLogStripe struct {
+	commitLog *commitLog
+	writer    commitLogWriter
+	writes    chan commitLogWrite
 
 	flushMutex      sync.RWMutex
 	lastFlushAt     time.Time
 	pendingFlushFns []completionFn
+	pendingSyncFns  []completionFn
 
-	bitset         bitset
 	writerExpireAt time.Time
-	closed         bool
-	closeErr       chan error
 }
 
 type commitLogMetrics struct {
@@ -85,6 +106,11 @@ type commitLogMetrics struct {
 	closeErrors tally.Counter
 	flushErrors tally.Counter
 	flushDone   tally.Counter
+	syncErrors  tally.Counter
+	syncs       tally.Counter
+	synced      tally.Counter
+	syncBatch   tally.Gauge
+	syncLatency tally.Timer
 }
 
 type valueType int
@@ -92,6 +118,7 @@ type valueType int
 const (
 	writeValueType valueType = iota
 	flushValueType
+	rotateLogsValueType
 )
 
 type commitLogWrite struct {
@@ -102,6 +129,8 @@ type commitLogWrite struct {
 	unit         xtime.Unit
 	annotation   ts.Annotation
 	completionFn completionFn
+	syncFn       completionFn
+	rotateLogsFn rotateLogsFn
 }
 
 // NewCommitLog creates a new commit log
@@ -122,25 +151,48 @@ func NewCommitLog(opts Options) CommitLog {
 			closeErrors: scope.Counter("writes.close-errors"),
 			flushErrors: scope.Counter("writes.flush-errors"),
 			flushDone:   scope.Counter("writes.flush-done"),
+			syncErrors:  scope.Counter("writes.sync-errors"),
+			syncs:       scope.Counter("writes.syncs"),
+			synced:      scope.Counter("writes.synced"),
+			syncBatch:   scope.Gauge("writes.sync-batch-size"),
+			syncLatency: scope.Timer("writes.sync-latency"),
 		},
 		log:                  iopts.Logger(),
 		newCommitLogWriterFn: newCommitLogWriter,
-		writes:               make(chan commitLogWrite, opts.BacklogQueueSize()),
 		closeErr:             make(chan error),
 	}
 
+	numStripes := opts.NumStripes()
+	if numStripes < 1 {
+		numStripes = 1
+	}
+
+	// Split the backlog across the stripes so the total backlog is unchanged
+	queueSize := (opts.BacklogQueueSize() + numStripes - 1) / numStripes
+
+	commitLog.stripes = make([]*commitLogStripe, numStripes)
+	for i := range commitLog.stripes {
+		commitLog.stripes[i] = &commitLogStripe{
+			commitLog: commitLog,
+			writes:    make(chan commitLogWrite, queueSize),
+		}
+	}
+
 	return commitLog
 }
 
 func (l *commitLog) Open() error {
-	// Open the buffered commit log writer
-	if err := l.openWriter(l.nowFn()); err != nil {
-		return err
-	}
+	now := l.nowFn()
+	for _, stripe := range l.stripes {
+		// Open the buffered commit log writer
+		if _, err := stripe.openWriter(now); err != nil {
+			return err
+		}
 
-	// Flush the info header to ensure we can write to disk
-	if err := l.writer.Flush(); err != nil {
-		return err
+		// Flush the info header to ensure we can write to disk
+		if err := stripe.writer.Flush(); err != nil {
+			return err
+		}
 	}
 
 	// NB(r): In the future we can introduce a commit log failure policy
@@ -152,7 +204,9 @@ func (l *commitLog) Open() error {
 	}
 
 	// Asynchronously write
-	go l.write()
+	for _, stripe := range l.stripes {
+		go stripe.write()
+	}
 
 	if flushInterval := l.opts.FlushInterval(); flushInterval > 0 {
 		// Continually flush the commit log at given interval if set
@@ -163,12 +217,16 @@ func (l *commitLog) Open() error {
 }
 
 func (l *commitLog) flushEvery(interval time.Duration) {
-	// Periodically flush the underlying commit log writer to cover
+	// Periodically flush the underlying commit log writers to cover
 	// the case when writes stall for a considerable time
 	var sleepForOverride time.Duration
 
 	for {
-		l.metrics.queued.Update(float64(len(l.writes)))
+		queued := 0
+		for _, stripe := range l.stripes {
+			queued += len(stripe.writes)
+		}
+		l.metrics.queued.Update(float64(queued))
 
 		sleepFor := interval
 
@@ -179,84 +237,162 @@ func (l *commitLog) flushEvery(interval time.Duration) {
 
 		time.Sleep(sleepFor)
 
-		l.flushMutex.RLock()
-		lastFlushAt := l.lastFlushAt
-		l.flushMutex.RUnlock()
-
-		if sinceFlush := l.nowFn().Sub(lastFlushAt); sinceFlush < interval {
-			// Flushed already recently, sleep until we would next consider flushing
-			sleepForOverride = interval - sinceFlush
-			continue
-		}
-
-		// Request a flush
+		// Request a flush of each stripe not flushed recently
 		l.RLock()
 		if l.closed {
 			l.RUnlock()
 			return
 		}
 
-		l.writes <- commitLogWrite{valueType: flushValueType}
+		now := l.nowFn()
+		for _, stripe := range l.stripes {
+			stripe.flushMutex.RLock()
+			lastFlushAt := stripe.lastFlushAt
+			stripe.flushMutex.RUnlock()
+
+			if sinceFlush := now.Sub(lastFlushAt); sinceFlush < interval {
+				// Flushed already recently, sleep until we would next consider flushing
+				if untilFlush := interval - sinceFlush; sleepForOverride == 0 || untilFlush < sleepForOverride {
+					sleepForOverride = untilFlush
+				}
+				continue
+			}
+
+			stripe.writes <- commitLogWrite{valueType: flushValueType}
+		}
 		l.RUnlock()
 	}
 }
 
-func (l *commitLog) write() {
-	for write := range l.writes {
-		// For writes requiring acks add to pending acks
-		if write.completionFn != nil {
-			l.pendingFlushFns = append(l.pendingFlushFns, write.completionFn)
-		}
+// stripe returns the stripe the writes of a series are written to, all the
+// writes of a series go to the same stripe so that they are read in order
+func (l *commitLog) stripe(series Series) *commitLogStripe {
+	if len(l.stripes) == 1 {
+		return l.stripes[0]
+	}
+	hash := series.ID.Hash()
+	return l.stripes[binary.LittleEndian.Uint64(hash[:8])%uint64(len(l.stripes))]
+}
 
-		if write.valueType == flushValueType {
-			l.writer.Flush()
-			continue
+func (s *commitLogStripe) write() {
+	for write := range s.writes {
+		s.writeValue(write)
+
+		// Group commit, fsync once the queue drains so that concurrently
+		// enqueued synchronous writes share a single fsync
+		if n := len(s.pendingSyncFns); n > 0 && (len(s.writes) == 0 || n >= maxSyncBatchSize) {
+			s.sync()
 		}
+	}
 
-		if now := l.nowFn(); !now.Before(l.writerExpireAt) {
-			if err := l.openWriter(now); err != nil {
+	l := s.commitLog
+	l.Lock()
+	defer l.Unlock()
 
-				l.metrics.errors.Inc(1)
-				l.metrics.openErrors.Inc(1)
-				l.log.Errorf("failed to open commit log: %v", err)
+	writer := s.writer
+	s.writer = nil
+	l.closeErr <- writer.Close()
+}
 
-				if l.commitLogFailFn != nil {
-					l.commitLogFailFn(err)
-				}
+func (s *commitLogStripe) writeValue(write commitLogWrite) {
+	l := s.commitLog
 
-				continue
+	// For writes requiring acks add to pending acks
+	if write.completionFn != nil {
+		s.pendingFlushFns = append(s.pendingFlushFns, write.completionFn)
+	}
+	if write.syncFn != nil {
+		s.pendingSyncFns = append(s.pendingSyncFns, write.syncFn)
+	}
+
+	if write.valueType == flushValueType {
+		s.writer.Flush()
+		return
+	}
+
+	if write.valueType == rotateLogsValueType {
+		file, err := s.openWriter(l.nowFn())
+		if err != nil {
+			l.metrics.errors.Inc(1)
+			l.metrics.openErrors.Inc(1)
+			l.log.Errorf("failed to rotate commit log: %v", err)
+
+			if l.commitLogFailFn != nil {
+				l.commitLogFailFn(err)
 			}
 		}
+		write.rotateLogsFn(file, err)
+		return
+	}
 
-		err := l.writer.Write(write.series,
-			write.datapoint, write.unit, write.annotation)
+	if now := l.nowFn(); !now.Before(s.writerExpireAt) {
+		if _, err := s.openWriter(now); err != nil {
 
-		if err != nil {
 			l.metrics.errors.Inc(1)
-			l.log.Errorf("failed to write to commit log: %v", err)
+			l.metrics.openErrors.Inc(1)
+			l.log.Errorf("failed to open commit log: %v", err)
 
 			if l.commitLogFailFn != nil {
 				l.commitLogFailFn(err)
 			}
 
-			continue
+			return
+		}
+	}
+
+	err := s.writer.Write(write.series,
+		write.datapoint, write.unit, write.annotation)
+
+	if err != nil {
+		l.metrics.errors.Inc(1)
+		l.log.Errorf("failed to write to commit log: %v", err)
+
+		if l.commitLogFailFn != nil {
+			l.commitLogFailFn(err)
 		}
 
-		l.metrics.success.Inc(1)
+		return
 	}
 
-	l.Lock()
-	defer l.Unlock()
+	l.metrics.success.Inc(1)
+}
 
-	writer := l.writer
-	l.writer = nil
-	l.closeErr <- writer.Close()
+func (s *commitLogStripe) sync() {
+	l := s.commitLog
+
+	start := l.nowFn()
+	err := s.writer.Sync()
+	l.metrics.syncLatency.Record(l.nowFn().Sub(start))
+
+	if err != nil {
+		l.metrics.errors.Inc(1)
+		l.metrics.syncErrors.Inc(1)
+		l.log.Errorf("failed to sync commit log: %v", err)
+
+		if l.commitLogFailFn != nil {
+			l.commitLogFailFn(err)
+		}
+	}
+
+	// Like "pendingFlushFns" only ever accessed by "write()"
+	batch := len(s.pendingSyncFns)
+	for i := range s.pendingSyncFns {
+		s.pendingSyncFns[i](err)
+		s.pendingSyncFns[i] = nil
+	}
+	s.pendingSyncFns = s.pendingSyncFns[:0]
+
+	l.metrics.syncs.Inc(1)
+	l.metrics.synced.Inc(int64(batch))
+	l.metrics.syncBatch.Update(float64(batch))
 }
 
-func (l *commitLog) onFlush(err error) {
-	l.flushMutex.Lock()
-	l.lastFlushAt = l.nowFn()
-	l.flushMutex.Unlock()
+func (s *commitLogStripe) onFlush(err error) {
+	l := s.commitLog
+
+	s.flushMutex.Lock()
+	s.lastFlushAt = l.nowFn()
+	s.flushMutex.Unlock()
 
 	if err != nil {
 		l.metrics.errors.Inc(1)
@@ -272,44 +408,55 @@ func (l *commitLog) onFlush(err error) {
 	// before "write()" begins on "Open()" and there are no other
 	// accessors of "pendingFlushFns" so it is safe to read and mutate
 	// without a lock here
-	if len(l.pendingFlushFns) == 0 {
+	if len(s.pendingFlushFns) == 0 {
 		l.metrics.flushDone.Inc(1)
 		return
 	}
 
-	for i := range l.pendingFlushFns {
-		l.pendingFlushFns[i](err)
-		l.pendingFlushFns[i] = nil
+	for i := range s.pendingFlushFns {
+		s.pendingFlushFns[i](err)
+		s.pendingFlushFns[i] = nil
 	}
-	l.pendingFlushFns = l.pendingFlushFns[:0]
+	s.pendingFlushFns = s.pendingFlushFns[:0]
 	l.metrics.flushDone.Inc(1)
 }
 
-func (l *commitLog) openWriter(now time.Time) error {
-	if l.writer != nil {
-		if err := l.writer.Close(); err != nil {
+func (s *commitLogStripe) openWriter(now time.Time) (File, error) {
+	l := s.commitLog
+
+	if s.writer != nil && len(s.pendingSyncFns) > 0 {
+		// Synchronous writes must be fsynced to the file they were written to
+		s.sync()
+	}
+
+	if s.writer != nil {
+		if err := s.writer.Close(); err != nil {
 			l.metrics.closeErrors.Inc(1)
 			l.log.Errorf("failed to close commit log: %v", err)
 
 			// If we failed to close then create a new commit log writer
-			l.writer = nil
+			s.writer = nil
 		}
 	}
 
-	if l.writer == nil {
-		l.writer = l.newCommitLogWriterFn(l.onFlush, l.opts)
+	if s.writer == nil {
+		s.writer = l.newCommitLogWriterFn(s.onFlush, l.opts)
 	}
 
 	blockSize := l.opts.RetentionOptions().BlockSize()
 	start := now.Truncate(blockSize)
 
-	if err := l.writer.Open(start, blockSize); err != nil {
-		return err
+	// Stripes opening files concurrently would otherwise claim the same index
+	l.openMutex.Lock()
+	file, err := s.writer.Open(start, blockSize)
+	l.openMutex.Unlock()
+	if err != nil {
+		return File{}, err
 	}
 
-	l.writerExpireAt = start.Add(blockSize)
+	s.writerExpireAt = start.Add(blockSize)
 
-	return nil
+	return file, nil
 }
 
 func (l *commitLog) Write(
@@ -318,6 +465,26 @@ func (l *commitLog) Write(
 	datapoint ts.Datapoint,
 	unit xtime.Unit,
 	annotation ts.Annotation,
+) error {
+	return l.writeWait(series, datapoint, unit, annotation, false)
+}
+
+func (l *commitLog) WriteSync(
+	ctx context.Context,
+	series Series,
+	datapoint ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	return l.writeWait(series, datapoint, unit, annotation, true)
+}
+
+func (l *commitLog) writeWait(
+	series Series,
+	datapoint ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+	fsync bool,
 ) error {
 	if l.RLock(); l.closed {
 		l.RUnlock()
@@ -337,17 +504,21 @@ func (l *commitLog) Write(
 	}
 
 	write := commitLogWrite{
-		series:       series,
-		datapoint:    datapoint,
-		unit:         unit,
-		annotation:   annotation,
-		completionFn: completion,
+		series:     series,
+		datapoint:  datapoint,
+		unit:       unit,
+		annotation: annotation,
+	}
+	if fsync {
+		write.syncFn = completion
+	} else {
+		write.completionFn = completion
 	}
 
 	enqueued := false
 
 	select {
-	case l.writes <- write:
+	case l.stripe(series).writes <- write:
 		enqueued = true
 	default:
 	}
@@ -385,7 +556,7 @@ func (l *commitLog) WriteBehind(
 	enqueued := false
 
 	select {
-	case l.writes <- write:
+	case l.stripe(series).writes <- write:
 		enqueued = true
 	default:
 	}
@@ -399,8 +570,58 @@ func (l *commitLog) WriteBehind(
 	return nil
 }
 
-func (l *commitLog) Iter() (Iterator, error) {
-	return NewIterator(l.opts)
+func (l *commitLog) Iter(filter SeriesFilterPredicate) (Iterator, error) {
+	return NewIterator(l.opts, ReadAllPredicate(), filter)
+}
+
+func (l *commitLog) RotateLogs() (File, error) {
+	if l.RLock(); l.closed {
+		l.RUnlock()
+		return File{}, errCommitLogClosed
+	}
+
+	var (
+		wg      sync.WaitGroup
+		resultL sync.Mutex
+		file    File
+		rotated bool
+		result  error
+	)
+
+	wg.Add(len(l.stripes))
+
+	// Every stripe rotates, the earliest file rotated to is returned as
+	// all the files from it onwards only hold writes after the rotation
+	rotateLogsFn := func(f File, err error) {
+		resultL.Lock()
+		if err != nil && result == nil {
+			result = err
+		}
+		if err == nil && (!rotated || f.Start.Before(file.Start) ||
+			(f.Start.Equal(file.Start) && f.Index < file.Index)) {
+			file, rotated = f, true
+		}
+		resultL.Unlock()
+		wg.Done()
+	}
+
+	// Rotations are queued behind pending writes so that the writes land in
+	// the files preceding the file rotated to
+	for _, stripe := range l.stripes {
+		stripe.writes <- commitLogWrite{
+			valueType:    rotateLogsValueType,
+			rotateLogsFn: rotateLogsFn,
+		}
+	}
+
+	l.RUnlock()
+
+	wg.Wait()
+
+	if result != nil {
+		return File{}, result
+	}
+	return file, nil
 }
 
 func (l *commitLog) Close() error {
@@ -411,9 +632,17 @@ func (l *commitLog) Close() error {
 	}
 
 	l.closed = true
-	close(l.writes)
+	for _, stripe := range l.stripes {
+		close(stripe.writes)
+	}
 	l.Unlock()
 
-	// Receive the result of closing the writer from asynchronous writer
-	return <-l.closeErr
+	// Receive the result of closing the writers from asynchronous writers
+	multiErr := xerrors.NewMultiError()
+	for range l.stripes {
+		if err := <-l.closeErr; err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
+	return multiErr.FinalError()
 }
@@ -0,0 +1,111 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/time"
+)
+
+const benchmarkNumSeries = 4096
+
+func BenchmarkCommitLogWriteBehind1Stripe(b *testing.B) {
+	benchmarkCommitLogWriteBehind(b, 1)
+}
+
+func BenchmarkCommitLogWriteBehind2Stripes(b *testing.B) {
+	benchmarkCommitLogWriteBehind(b, 2)
+}
+
+func BenchmarkCommitLogWriteBehind4Stripes(b *testing.B) {
+	benchmarkCommitLogWriteBehind(b, 4)
+}
+
+func BenchmarkCommitLogWriteBehindNumCPUStripes(b *testing.B) {
+	benchmarkCommitLogWriteBehind(b, runtime.NumCPU())
+}
+
+func newBenchmarkCommitLog(b *testing.B, numStripes int) (*commitLog, []Series, func()) {
+	dir, err := ioutil.TempDir("", "commitlog-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	opts := NewOptions().
+		SetFilesystemOptions(fs.NewOptions().SetFilePathPrefix(dir)).
+		SetRetentionOptions(retention.NewOptions().SetBlockSize(2 * time.Hour)).
+		SetBacklogQueueSize(1024 * runtime.NumCPU()).
+		SetNumStripes(numStripes)
+
+	commitLog := NewCommitLog(opts).(*commitLog)
+	if err := commitLog.Open(); err != nil {
+		b.Fatal(err)
+	}
+
+	series := make([]Series, benchmarkNumSeries)
+	for i := range series {
+		series[i] = Series{
+			UniqueIndex: uint64(i),
+			Namespace:   ts.StringID("testNS"),
+			ID:          ts.StringID(fmt.Sprintf("foo.%d", i)),
+			Shard:       uint32(i),
+		}
+	}
+
+	return commitLog, series, func() {
+		commitLog.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func benchmarkCommitLogWriteBehind(b *testing.B, numStripes int) {
+	commitLog, series, cleanup := newBenchmarkCommitLog(b, numStripes)
+	defer cleanup()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	var next uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := atomic.AddUint64(&next, 1)
+			dp := ts.Datapoint{Timestamp: time.Now(), Value: float64(n)}
+			s := series[n%uint64(len(series))]
+
+			// Retry when the queue is full to measure the sustained throughput
+			for commitLog.WriteBehind(ctx, s, dp, xtime.Second, nil) == ErrCommitLogQueueFull {
+				runtime.Gosched()
+			}
+		}
+	})
+}
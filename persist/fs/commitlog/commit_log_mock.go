@@ -89,15 +89,36 @@ func (_mr *_MockCommitLogRecorder) WriteBehind(arg0, arg1, arg2, arg3, arg4 inte
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteBehind", arg0, arg1, arg2, arg3, arg4)
 }
 
-func (_m *MockCommitLog) Iter() (Iterator, error) {
-	ret := _m.ctrl.Call(_m, "Iter")
+func (_m *MockCommitLog) WriteSync(ctx context.Context, series Series, datapoint ts.Datapoint, unit time0.Unit, annotation ts.Annotation) error {
+	ret := _m.ctrl.Call(_m, "WriteSync", ctx, series, datapoint, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockCommitLogRecorder) WriteSync(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteSync", arg0, arg1, arg2, arg3, arg4)
+}
+
+func (_m *MockCommitLog) Iter(filter SeriesFilterPredicate) (Iterator, error) {
+	ret := _m.ctrl.Call(_m, "Iter", filter)
 	ret0, _ := ret[0].(Iterator)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (_mr *_MockCommitLogRecorder) Iter() *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "Iter")
+func (_mr *_MockCommitLogRecorder) Iter(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Iter", arg0)
+}
+
+func (_m *MockCommitLog) RotateLogs() (File, error) {
+	ret := _m.ctrl.Call(_m, "RotateLogs")
+	ret0, _ := ret[0].(File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockCommitLogRecorder) RotateLogs() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RotateLogs")
 }
 
 func (_m *MockCommitLog) Close() error {
@@ -372,3 +393,43 @@ func (_m *MockOptions) BytesPool() pool.CheckedBytesPool {
 func (_mr *_MockOptionsRecorder) BytesPool() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "BytesPool")
 }
+
+func (_m *MockOptions) SetReadCorruptionPolicy(value ReadCorruptionPolicy) Options {
+	ret := _m.ctrl.Call(_m, "SetReadCorruptionPolicy", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetReadCorruptionPolicy(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetReadCorruptionPolicy", arg0)
+}
+
+func (_m *MockOptions) ReadCorruptionPolicy() ReadCorruptionPolicy {
+	ret := _m.ctrl.Call(_m, "ReadCorruptionPolicy")
+	ret0, _ := ret[0].(ReadCorruptionPolicy)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) ReadCorruptionPolicy() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadCorruptionPolicy")
+}
+
+func (_m *MockOptions) SetNumStripes(value int) Options {
+	ret := _m.ctrl.Call(_m, "SetNumStripes", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetNumStripes(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetNumStripes", arg0)
+}
+
+func (_m *MockOptions) NumStripes() int {
+	ret := _m.ctrl.Call(_m, "NumStripes")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) NumStripes() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "NumStripes")
+}
@@ -38,15 +38,16 @@ import (
 	"github.com/m3db/m3x/instrument"
 	"github.com/m3db/m3x/time"
 
-	"github.com/uber-go/tally"
 	mclock "github.com/facebookgo/clock"
 	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
 )
 
 type overrides struct {
 	clock            *mclock.Mock
 	flushInterval    *time.Duration
 	backlogQueueSize *int
+	numStripes       *int
 }
 
 func newTestOptions(
@@ -85,6 +86,10 @@ func newTestOptions(
 		opts = opts.SetBacklogQueueSize(*overrides.backlogQueueSize)
 	}
 
+	if overrides.numStripes != nil {
+		opts = opts.SetNumStripes(*overrides.numStripes)
+	}
+
 	return opts, scope
 }
 
@@ -125,6 +130,11 @@ func (w testWrite) assert(
 	assert.Equal(t, w.series.UniqueIndex, series.UniqueIndex)
 	assert.True(t, w.series.ID.Equal(series.ID), fmt.Sprintf("write ID '%s' does not match actual ID '%s'", w.series.ID.String(), series.ID.String()))
 	assert.Equal(t, w.series.Shard, series.Shard)
+	assert.Equal(t, len(w.series.Tags), len(series.Tags))
+	for i := 0; i < len(w.series.Tags) && i < len(series.Tags); i++ {
+		assert.True(t, w.series.Tags[i].Name.Equal(series.Tags[i].Name))
+		assert.True(t, w.series.Tags[i].Value.Equal(series.Tags[i].Value))
+	}
 	assert.True(t, w.t.Equal(datapoint.Timestamp))
 	assert.Equal(t, datapoint.Value, datapoint.Value)
 	assert.Equal(t, w.u, unit)
@@ -144,6 +154,7 @@ type mockCommitLogWriter struct {
 	openFn  func(start time.Time, duration time.Duration) error
 	writeFn func(Series, ts.Datapoint, xtime.Unit, ts.Annotation) error
 	flushFn func() error
+	syncFn  func() error
 	closeFn func() error
 }
 
@@ -158,14 +169,17 @@ func newMockCommitLogWriter() *mockCommitLogWriter {
 		flushFn: func() error {
 			return nil
 		},
+		syncFn: func() error {
+			return nil
+		},
 		closeFn: func() error {
 			return nil
 		},
 	}
 }
 
-func (w *mockCommitLogWriter) Open(start time.Time, duration time.Duration) error {
-	return w.openFn(start, duration)
+func (w *mockCommitLogWriter) Open(start time.Time, duration time.Duration) (File, error) {
+	return File{Start: start, Duration: duration}, w.openFn(start, duration)
 }
 
 func (w *mockCommitLogWriter) Write(
@@ -181,6 +195,10 @@ func (w *mockCommitLogWriter) Flush() error {
 	return w.flushFn()
 }
 
+func (w *mockCommitLogWriter) Sync() error {
+	return w.syncFn()
+}
+
 func (w *mockCommitLogWriter) Close() error {
 	return w.closeFn()
 }
@@ -193,7 +211,7 @@ func newTestCommitLog(t *testing.T, opts Options) *commitLog {
 	fsopts := opts.FilesystemOptions()
 	files, err := fs.CommitLogFiles(fs.CommitLogsDirPath(fsopts.FilePathPrefix()))
 	assert.NoError(t, err)
-	assert.True(t, len(files) == 1)
+	assert.True(t, len(files) == opts.NumStripes())
 
 	return commitLog
 }
@@ -271,7 +289,9 @@ func flushUntilDone(l *commitLog, wg *sync.WaitGroup) {
 	blockWg.Add(1)
 	go func() {
 		for atomic.LoadUint64(&done) == 0 {
-			l.writes <- commitLogWrite{valueType: flushValueType}
+			for _, stripe := range l.stripes {
+				stripe.writes <- commitLogWrite{valueType: flushValueType}
+			}
 			time.Sleep(time.Millisecond)
 		}
 		blockWg.Done()
@@ -286,7 +306,7 @@ func flushUntilDone(l *commitLog, wg *sync.WaitGroup) {
 }
 
 func assertCommitLogWritesByIterating(t *testing.T, l *commitLog, writes []testWrite) {
-	iter, err := l.Iter()
+	iter, err := l.Iter(ReadAllSeriesPredicate())
 	assert.NoError(t, err)
 	defer iter.Close()
 
@@ -331,6 +351,104 @@ func TestCommitLogWrite(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteTagged(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	tagged := testSeries(0, "foo.bar", 127)
+	tagged.Tags = ts.Tags{
+		{Name: ts.StringID("city"), Value: ts.StringID("nyc")},
+		{Name: ts.StringID("host"), Value: ts.StringID("a")},
+	}
+	writes := []testWrite{
+		{tagged, time.Now(), 123.456, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", 150), time.Now(), 456.789, xtime.Second, nil, nil},
+	}
+
+	// Call write sync
+	writeCommitLogs(t, scope, commitLog.Write, writes).Wait()
+
+	// Close the commit log and consequently flush
+	assert.NoError(t, commitLog.Close())
+
+	// Assert the series tags are read back from the commit log
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
+func TestCommitLogRotateLogs(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", 127), time.Now(), 123.456, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", 150), time.Now(), 456.789, xtime.Second, nil, nil},
+	}
+
+	writeCommitLogs(t, scope, commitLog.Write, writes[:1]).Wait()
+
+	file, err := commitLog.RotateLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, file.Index)
+	assert.True(t, fs.FileExists(file.FilePath))
+
+	writeCommitLogs(t, scope, commitLog.Write, writes[1:]).Wait()
+
+	assert.NoError(t, commitLog.Close())
+
+	// Assert all writes are read back in order across the files
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+
+	// Assert only the writes after the rotation are read from the file rotated to
+	iter, err := NewIterator(opts, func(f File) bool {
+		return f.Start.Equal(file.Start) && f.Index >= file.Index
+	}, ReadAllSeriesPredicate())
+	assert.NoError(t, err)
+	defer iter.Close()
+
+	assert.True(t, iter.Next())
+	series, datapoint, unit, annotation := iter.Current()
+	writes[1].assert(t, series, datapoint, unit, annotation)
+	assert.False(t, iter.Next())
+	assert.NoError(t, iter.Err())
+}
+
+func TestCommitLogWriteStriped(t *testing.T) {
+	numStripes := 4
+	opts, scope := newTestOptions(t, overrides{numStripes: &numStripes})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	var writes []testWrite
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 8; j++ {
+			series := testSeries(uint64(j), fmt.Sprintf("foo.%d", j), uint32(j))
+			v := float64(i*8 + j)
+			writes = append(writes, testWrite{series, time.Now(), v, xtime.Second, nil, nil})
+		}
+	}
+
+	writeCommitLogs(t, scope, commitLog.Write, writes).Wait()
+
+	file, err := commitLog.RotateLogs()
+	assert.NoError(t, err)
+	assert.Equal(t, numStripes, file.Index)
+
+	assert.NoError(t, commitLog.Close())
+
+	// Assert the writes are merged back from the stripes in order
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+
+	fsopts := opts.FilesystemOptions()
+	files, err := fs.CommitLogFiles(fs.CommitLogsDirPath(fsopts.FilePathPrefix()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2*numStripes, len(files))
+}
+
 func TestCommitLogWriteBehind(t *testing.T) {
 	opts, scope := newTestOptions(t, overrides{})
 	defer cleanup(t, opts)
@@ -353,6 +471,79 @@ func TestCommitLogWriteBehind(t *testing.T) {
 	assertCommitLogWritesByIterating(t, commitLog, writes)
 }
 
+func TestCommitLogWriteSync(t *testing.T) {
+	// Disable periodic flushing so that only syncs flush the writes
+	flushInterval := time.Duration(0)
+	opts, scope := newTestOptions(t, overrides{flushInterval: &flushInterval})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", 127), time.Now(), 123.456, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", 150), time.Now(), 456.789, xtime.Second, nil, nil},
+		{testSeries(2, "foo.qux", 291), time.Now(), 789.123, xtime.Second, nil, nil},
+	}
+
+	// Call write sync, returns without any flush being requested
+	writeCommitLogs(t, scope, commitLog.WriteSync, writes).Wait()
+
+	syncs, ok := snapshotCounterValue(scope, "commitlog.writes.syncs")
+	assert.True(t, ok)
+	synced, ok := snapshotCounterValue(scope, "commitlog.writes.synced")
+	assert.True(t, ok)
+	assert.Equal(t, int64(len(writes)), synced.Value())
+	assert.True(t, syncs.Value() >= 1 && syncs.Value() <= synced.Value())
+
+	assert.NoError(t, commitLog.Close())
+
+	// Assert writes occurred by reading the commit log
+	assertCommitLogWritesByIterating(t, commitLog, writes)
+}
+
+func TestCommitLogIterFilter(t *testing.T) {
+	opts, scope := newTestOptions(t, overrides{})
+	defer cleanup(t, opts)
+
+	commitLog := newTestCommitLog(t, opts)
+
+	writes := []testWrite{
+		{testSeries(0, "foo.bar", 127), time.Now(), 123.456, xtime.Second, nil, nil},
+		{testSeries(1, "foo.baz", 150), time.Now(), 456.789, xtime.Second, nil, nil},
+		{testSeries(0, "foo.bar", 127), time.Now(), 789.123, xtime.Second, nil, nil},
+	}
+
+	writeCommitLogs(t, scope, commitLog.Write, writes).Wait()
+	assert.NoError(t, commitLog.Close())
+
+	// Only the entries of the series in the accepted shard are returned
+	iter, err := commitLog.Iter(func(namespace ts.ID, shard uint32) bool {
+		return shard == 127
+	})
+	assert.NoError(t, err)
+	for _, write := range []testWrite{writes[0], writes[2]} {
+		assert.True(t, iter.Next())
+		series, datapoint, unit, annotation := iter.Current()
+		write.assert(t, series, datapoint, unit, annotation)
+	}
+	assert.False(t, iter.Next())
+	assert.NoError(t, iter.Err())
+	iter.Close()
+
+	// The file is skipped using its index when no namespace is accepted
+	iter, err = commitLog.Iter(func(namespace ts.ID, shard uint32) bool {
+		return namespace.Equal(ts.StringID("otherNS"))
+	})
+	assert.NoError(t, err)
+	assert.False(t, iter.Next())
+	assert.NoError(t, iter.Err())
+	iter.Close()
+
+	skipped, ok := snapshotCounterValue(scope, "iterator.files.skipped")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), skipped.Value())
+}
+
 func TestCommitLogWriteErrorOnClosed(t *testing.T) {
 	opts, _ := newTestOptions(t, overrides{})
 	defer cleanup(t, opts)
@@ -477,7 +668,7 @@ func TestCommitLogFailOnWriteError(t *testing.T) {
 	}
 
 	writer.flushFn = func() error {
-		commitLog.onFlush(nil)
+		commitLog.stripes[0].onFlush(nil)
 		return nil
 	}
 
@@ -522,7 +713,7 @@ func TestCommitLogFailOnOpenError(t *testing.T) {
 	}
 
 	writer.flushFn = func() error {
-		commitLog.onFlush(nil)
+		commitLog.stripes[0].onFlush(nil)
 		return nil
 	}
 
@@ -541,7 +732,7 @@ func TestCommitLogFailOnOpenError(t *testing.T) {
 		commitLog.RLock()
 		defer commitLog.RUnlock()
 		// Expire the writer so it requires a new open
-		commitLog.writerExpireAt = timeZero
+		commitLog.stripes[0].writerExpireAt = timeZero
 	}()
 
 	writes := []testWrite{
@@ -572,9 +763,9 @@ func TestCommitLogFailOnFlushError(t *testing.T) {
 	var flushes int64
 	writer.flushFn = func() error {
 		if atomic.AddInt64(&flushes, 1) >= 2 {
-			commitLog.onFlush(fmt.Errorf("an error"))
+			commitLog.stripes[0].onFlush(fmt.Errorf("an error"))
 		} else {
-			commitLog.onFlush(nil)
+			commitLog.stripes[0].onFlush(nil)
 		}
 		return nil
 	}
@@ -0,0 +1,190 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+)
+
+const (
+	// fileIndexChecksumLen is the length of the checksum trailing an encoded index
+	fileIndexChecksumLen = 4
+)
+
+var (
+	errCommitLogIndexChecksumMismatch = errors.New("commit log index checksum mismatch")
+	errCommitLogIndexInvalid          = errors.New("commit log index invalid")
+)
+
+// fileIndex is the set of shards written to a commit log file keyed by
+// namespace, it is written alongside the commit log file when it is closed
+// so that iterators can skip files that hold no series of interest
+type fileIndex map[string]map[uint32]struct{}
+
+func (idx fileIndex) add(namespace []byte, shard uint32) {
+	shards, ok := idx[string(namespace)]
+	if !ok {
+		shards = make(map[uint32]struct{})
+		idx[string(namespace)] = shards
+	}
+	shards[shard] = struct{}{}
+}
+
+// matches returns whether any namespace and shard in the index is accepted by the filter
+func (idx fileIndex) matches(filter SeriesFilterPredicate) bool {
+	for namespace, shards := range idx {
+		id := ts.StringID(namespace)
+		for shard := range shards {
+			if filter(id, shard) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (idx fileIndex) clear() {
+	for namespace := range idx {
+		delete(idx, namespace)
+	}
+}
+
+// encode encodes the index as the number of namespaces followed by each
+// namespace and its shards, all as uvarints, with a trailing checksum
+func (idx fileIndex) encode() []byte {
+	namespaces := make([]string, 0, len(idx))
+	for namespace := range idx {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var (
+		buf     []byte
+		scratch = make([]byte, binary.MaxVarintLen64)
+	)
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(scratch, v)
+		buf = append(buf, scratch[:n]...)
+	}
+	putUvarint(uint64(len(namespaces)))
+	for _, namespace := range namespaces {
+		putUvarint(uint64(len(namespace)))
+		buf = append(buf, namespace...)
+		shards := idx[namespace]
+		putUvarint(uint64(len(shards)))
+		for shard := range shards {
+			putUvarint(uint64(shard))
+		}
+	}
+
+	checksum := make([]byte, fileIndexChecksumLen)
+	digest.Buffer(checksum).WriteDigest(digest.Checksum(buf))
+	return append(buf, checksum...)
+}
+
+func decodeFileIndex(data []byte) (fileIndex, error) {
+	if len(data) < fileIndexChecksumLen {
+		return nil, errCommitLogIndexInvalid
+	}
+	body := data[:len(data)-fileIndexChecksumLen]
+	expected := digest.Buffer(data[len(body):]).ReadDigest()
+	if digest.Checksum(body) != expected {
+		return nil, errCommitLogIndexChecksumMismatch
+	}
+
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(body)
+		if n <= 0 {
+			return 0, errCommitLogIndexInvalid
+		}
+		body = body[n:]
+		return v, nil
+	}
+
+	numNamespaces, err := uvarint()
+	if err != nil {
+		return nil, err
+	}
+	idx := make(fileIndex, int(numNamespaces))
+	for i := uint64(0); i < numNamespaces; i++ {
+		nameLen, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(body)) < nameLen {
+			return nil, errCommitLogIndexInvalid
+		}
+		namespace := string(body[:nameLen])
+		body = body[nameLen:]
+
+		numShards, err := uvarint()
+		if err != nil {
+			return nil, err
+		}
+		shards := make(map[uint32]struct{}, int(numShards))
+		for j := uint64(0); j < numShards; j++ {
+			shard, err := uvarint()
+			if err != nil {
+				return nil, err
+			}
+			shards[uint32(shard)] = struct{}{}
+		}
+		idx[namespace] = shards
+	}
+	return idx, nil
+}
+
+func writeFileIndex(commitLogFilePath string, idx fileIndex, newFileMode os.FileMode) error {
+	fd, err := fs.OpenWritable(fs.CommitLogIndexFilePath(commitLogFilePath), newFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(idx.encode()); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// readFileIndex reads the index of a commit log file, returning false if the
+// file has no index because it was never closed cleanly
+func readFileIndex(commitLogFilePath string) (fileIndex, bool, error) {
+	data, err := ioutil.ReadFile(fs.CommitLogIndexFilePath(commitLogFilePath))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	idx, err := decodeFileIndex(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return idx, true, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"testing"
+
+	"github.com/m3db/m3db/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileIndexRoundTrip(t *testing.T) {
+	idx := make(fileIndex)
+	idx.add([]byte("foo"), 1)
+	idx.add([]byte("foo"), 3)
+	idx.add([]byte("bar"), 300)
+
+	decoded, err := decodeFileIndex(idx.encode())
+	require.NoError(t, err)
+	assert.Equal(t, idx, decoded)
+
+	assert.True(t, decoded.matches(func(namespace ts.ID, shard uint32) bool {
+		return namespace.Equal(ts.StringID("bar")) && shard == 300
+	}))
+	assert.False(t, decoded.matches(func(namespace ts.ID, shard uint32) bool {
+		return namespace.Equal(ts.StringID("foo")) && shard == 2
+	}))
+}
+
+func TestFileIndexChecksumMismatch(t *testing.T) {
+	idx := make(fileIndex)
+	idx.add([]byte("foo"), 1)
+
+	data := idx.encode()
+	data[0]++
+	_, err := decodeFileIndex(data)
+	assert.Equal(t, errCommitLogIndexChecksumMismatch, err)
+
+	_, err = decodeFileIndex(data[:2])
+	assert.Error(t, err)
+}
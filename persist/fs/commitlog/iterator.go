@@ -39,7 +39,12 @@ var (
 )
 
 type iteratorMetrics struct {
-	readsErrors tally.Counter
+	readsErrors    tally.Counter
+	filesSkipped   tally.Counter
+	indexErrors    tally.Counter
+	corruptFiles   tally.Counter
+	skippedBytes   tally.Counter
+	skippedEntries tally.Counter
 }
 
 type iterator struct {
@@ -48,13 +53,25 @@ type iterator struct {
 	metrics iteratorMetrics
 	log     xlog.Logger
 	files   []string
-	reader  commitLogReader
+	filter  FileFilterPredicate
+	series  SeriesFilterPredicate
+	readers []*iteratorReader
 	read    iteratorRead
 	setRead bool
 	err     error
+	corrupt CorruptionError
 	closed  bool
 }
 
+// iteratorReader is a reader of one of the files with the same block start,
+// one per stripe and rotation, that are merged by the iterator
+type iteratorReader struct {
+	reader commitLogReader
+	read   iteratorRead
+	// pending is whether the next entry must be read before merging
+	pending bool
+}
+
 type iteratorRead struct {
 	series     Series
 	datapoint  ts.Datapoint
@@ -62,8 +79,15 @@ type iteratorRead struct {
 	annotation []byte
 }
 
-// NewIterator creates a new commit log iterator
-func NewIterator(opts Options) (Iterator, error) {
+// NewIterator creates a new commit log iterator that reads the commit log
+// files accepted by the file filter and returns the entries of the series
+// accepted by the series filter, files whose index shows they hold no
+// accepted series are not read at all
+func NewIterator(
+	opts Options,
+	filter FileFilterPredicate,
+	seriesFilter SeriesFilterPredicate,
+) (Iterator, error) {
 	iops := opts.InstrumentOptions()
 	iops = iops.SetMetricsScope(iops.MetricsScope().SubScope("iterator"))
 	fsopts := opts.FilesystemOptions()
@@ -76,10 +100,17 @@ func NewIterator(opts Options) (Iterator, error) {
 		opts:  opts,
 		scope: scope,
 		metrics: iteratorMetrics{
-			readsErrors: scope.Counter("reads.errors"),
+			readsErrors:    scope.Counter("reads.errors"),
+			filesSkipped:   scope.Counter("files.skipped"),
+			indexErrors:    scope.Counter("index.errors"),
+			corruptFiles:   scope.Counter("corruption.files"),
+			skippedBytes:   scope.Counter("corruption.skipped-bytes"),
+			skippedEntries: scope.Counter("corruption.skipped-entries"),
 		},
-		log:   iops.Logger(),
-		files: files,
+		log:    iops.Logger(),
+		files:  files,
+		filter: filter,
+		series: seriesFilter,
 	}, nil
 }
 
@@ -87,25 +118,68 @@ func (i *iterator) Next() bool {
 	if i.hasError() || i.closed {
 		return false
 	}
-	if i.reader == nil {
-		if !i.nextReader() {
+	for {
+		if len(i.readers) == 0 && !i.nextReaders() {
 			return false
 		}
+
+		if !i.readPending() {
+			return false
+		}
+
+		// Merge the files with the same block start in the order the entries
+		// were written, all entries of a series are written to the same stripe
+		// so the entries of each series are returned in order
+		var next *iteratorReader
+		for _, r := range i.readers {
+			if next == nil || r.reader.Created().Before(next.reader.Created()) {
+				next = r
+			}
+		}
+		if next == nil {
+			// All the readers were exhausted, try the next files
+			continue
+		}
+
+		// The entry is only valid until its reader is read from again
+		next.pending = true
+		i.read = next.read
+		i.setRead = true
+		return true
 	}
-	var err error
-	i.read.series, i.read.datapoint, i.read.unit, i.read.annotation, err = i.reader.Read()
-	if err == io.EOF {
-		// Try the next reader
-		i.reader = nil
-		return i.Next()
-	}
-	if err != nil {
-		// Try the next reader, this enables restoring with best effort from commit logs
+}
+
+// readPending reads the next entry of each reader whose entry has been
+// returned, readers that are exhausted or return an error are closed
+func (i *iterator) readPending() bool {
+	for j := 0; j < len(i.readers); {
+		r := i.readers[j]
+		if !r.pending {
+			j++
+			continue
+		}
+
+		var err error
+		r.read.series, r.read.datapoint, r.read.unit, r.read.annotation, err = r.reader.Read()
+		if err == nil {
+			r.pending = false
+			j++
+			continue
+		}
+
+		i.closeReader(j)
+		if err == io.EOF {
+			continue
+		}
+
 		i.metrics.readsErrors.Inc(1)
+		if i.opts.ReadCorruptionPolicy() == ReadCorruptionPolicyFail {
+			i.err = err
+			return false
+		}
+		// Continue with the other readers, this enables restoring with best effort from commit logs
 		i.log.Errorf("commit log reader returned error, iterator moving to next file: %v", err)
-		return i.Next()
 	}
-	i.setRead = true
 	return true
 }
 
@@ -118,7 +192,13 @@ func (i *iterator) Current() (Series, ts.Datapoint, xtime.Unit, ts.Annotation) {
 }
 
 func (i *iterator) Err() error {
-	return i.err
+	if i.err != nil {
+		return i.err
+	}
+	if i.corrupt.Files > 0 {
+		return i.corrupt
+	}
+	return nil
 }
 
 func (i *iterator) Close() {
@@ -126,46 +206,127 @@ func (i *iterator) Close() {
 		return
 	}
 	i.closed = true
-	if i.reader != nil {
-		i.reader.Close()
-		i.reader = nil
+	for len(i.readers) > 0 {
+		i.closeReader(len(i.readers) - 1)
 	}
 }
 
+func (i *iterator) closeReader(idx int) {
+	reader := i.readers[idx].reader
+	i.recordSkipped(reader)
+	reader.Close()
+	i.readers = append(i.readers[:idx], i.readers[idx+1:]...)
+}
+
+func (i *iterator) recordSkipped(reader commitLogReader) {
+	bytes, entries := reader.Skipped()
+	if bytes == 0 && entries == 0 {
+		return
+	}
+	i.corrupt.Files++
+	i.corrupt.SkippedBytes += bytes
+	i.corrupt.SkippedEntries += entries
+	i.metrics.corruptFiles.Inc(1)
+	i.metrics.skippedBytes.Inc(bytes)
+	i.metrics.skippedEntries.Inc(entries)
+}
+
 func (i *iterator) hasError() bool {
 	return i.err != nil
 }
 
-func (i *iterator) nextReader() bool {
-	if len(i.files) == 0 {
-		return false
+// mayContainSeries returns whether the file may hold entries of series
+// accepted by the series filter, files without an index are always read
+func (i *iterator) mayContainSeries(file string) bool {
+	idx, ok, err := readFileIndex(file)
+	if err != nil {
+		i.metrics.indexErrors.Inc(1)
+		i.log.Errorf("commit log file %s index unreadable, reading whole file: %v", file, err)
+		return true
 	}
+	return !ok || idx.matches(i.series)
+}
 
-	if i.reader != nil {
-		i.reader.Close()
-		i.reader = nil
-	}
+// nextReaders opens readers for the next files with the same block start
+// that hold entries to be read
+func (i *iterator) nextReaders() bool {
+	for len(i.files) > 0 {
+		t, _, err := fs.TimeAndIndexFromFileName(i.files[0])
+		if err != nil {
+			i.err = err
+			return false
+		}
+
+		// Files are sorted by block start and then index
+		n := 1
+		for n < len(i.files) {
+			next, _, err := fs.TimeAndIndexFromFileName(i.files[n])
+			if err != nil || !next.Equal(t) {
+				break
+			}
+			n++
+		}
+		files := i.files[:n]
+		i.files = i.files[n:]
 
-	file := i.files[0]
-	i.files = i.files[1:]
+		for _, file := range files {
+			if !i.openReader(file) {
+				return false
+			}
+		}
+		if len(i.readers) > 0 {
+			return true
+		}
+	}
+	return false
+}
 
+// openReader opens a reader for the file unless it is filtered or corrupt,
+// it returns false if the file cannot be read and iterating must stop
+func (i *iterator) openReader(file string) bool {
 	t, idx, err := fs.TimeAndIndexFromFileName(file)
 	if err != nil {
 		i.err = err
 		return false
 	}
 
-	reader := newCommitLogReader(i.opts)
-	start, duration, index, err := reader.Open(file)
+	if !i.filter(File{
+		FilePath: file,
+		Start:    t,
+		Duration: i.opts.RetentionOptions().BlockSize(),
+		Index:    idx,
+	}) {
+		return true
+	}
+
+	if !i.mayContainSeries(file) {
+		i.metrics.filesSkipped.Inc(1)
+		return true
+	}
+
+	reader := newCommitLogReader(i.opts, i.series)
+	start, dur, index, err := reader.Open(file)
 	if err != nil {
+		if skipped, _ := reader.Skipped(); skipped > 0 &&
+			i.opts.ReadCorruptionPolicy() != ReadCorruptionPolicyFail {
+			// The file header is corrupt, skip the whole file
+			i.recordSkipped(reader)
+			i.metrics.readsErrors.Inc(1)
+			i.log.Errorf("commit log file %s is corrupt, iterator moving to next file: %v", file, err)
+			return true
+		}
 		i.err = err
 		return false
 	}
+
+	// Track the reader before validating so it is closed with the iterator
+	i.readers = append(i.readers, &iteratorReader{reader: reader, pending: true})
+
 	if !t.Equal(start) {
 		i.err = errStartDoesNotMatch
 		return false
 	}
-	if duration != i.opts.RetentionOptions().BlockSize() {
+	if dur != i.opts.RetentionOptions().BlockSize() {
 		i.err = errDurationDoesNotMatch
 		return false
 	}
@@ -173,7 +334,5 @@ func (i *iterator) nextReader() bool {
 		i.err = errIndexDoesNotMatch
 		return false
 	}
-
-	i.reader = reader
 	return true
 }
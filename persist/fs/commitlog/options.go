@@ -40,6 +40,12 @@ const (
 
 	// defaultFlushInterval is the default commit log flush interval
 	defaultFlushInterval = time.Second
+
+	// defaultReadCorruptionPolicy is the default commit log read corruption policy
+	defaultReadCorruptionPolicy = ReadCorruptionPolicyTruncate
+
+	// defaultNumStripes is the default number of commit log stripes
+	defaultNumStripes = 1
 )
 
 var (
@@ -57,6 +63,8 @@ type options struct {
 	flushInterval    time.Duration
 	backlogQueueSize int
 	bytesPool        pool.CheckedBytesPool
+	corruptionPolicy ReadCorruptionPolicy
+	numStripes       int
 }
 
 // NewOptions creates new commit log options
@@ -70,6 +78,8 @@ func NewOptions() Options {
 		flushSize:        defaultFlushSize,
 		flushInterval:    defaultFlushInterval,
 		backlogQueueSize: defaultBacklogQueueSize,
+		corruptionPolicy: defaultReadCorruptionPolicy,
+		numStripes:       defaultNumStripes,
 		bytesPool: pool.NewCheckedBytesPool(nil, nil, func(s []pool.Bucket) pool.BytesPool {
 			return pool.NewBytesPool(s, nil)
 		}),
@@ -167,3 +177,23 @@ func (o *options) SetBytesPool(value pool.CheckedBytesPool) Options {
 func (o *options) BytesPool() pool.CheckedBytesPool {
 	return o.bytesPool
 }
+
+func (o *options) SetReadCorruptionPolicy(value ReadCorruptionPolicy) Options {
+	opts := *o
+	opts.corruptionPolicy = value
+	return &opts
+}
+
+func (o *options) ReadCorruptionPolicy() ReadCorruptionPolicy {
+	return o.corruptionPolicy
+}
+
+func (o *options) SetNumStripes(value int) Options {
+	opts := *o
+	opts.numStripes = value
+	return &opts
+}
+
+func (o *options) NumStripes() int {
+	return o.numStripes
+}
@@ -24,6 +24,7 @@ import (
 	"bufio"
 	"encoding/binary"
 	"errors"
+	"io"
 	"os"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/m3db/m3db/persist/encoding/msgpack"
 	"github.com/m3db/m3db/persist/schema"
 	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/pool"
 	xtime "github.com/m3db/m3x/time"
 )
@@ -43,9 +45,28 @@ var (
 	errCommitLogReaderAlreadyOpen               = errors.New("commit log reader already open")
 	errCommitLogReaderChunkSizeChecksumMismatch = errors.New("commit log reader encountered chunk size checksum mismatch")
 	errCommitLogReaderChunkDataChecksumMismatch = errors.New("commit log reader encountered chunk data checksum mismatch")
+	errCommitLogReaderChunkTruncated            = errors.New("commit log reader encountered truncated chunk")
+	errCommitLogReaderChunkSkipped              = errors.New("commit log reader skipped corrupt chunk")
+	errCommitLogReaderEntrySizeInvalid          = errors.New("commit log reader encountered invalid entry size")
 	errCommitLogReaderMissingLogMetadata        = errors.New("commit log reader encountered message missing metadata")
+	errCommitLogReaderSeriesFiltered            = errors.New("commit log reader filtered series")
 )
 
+// errCorruptEntry is returned when an entry read from valid chunks fails to decode
+type errCorruptEntry struct {
+	err error
+}
+
+func (e errCorruptEntry) Error() string {
+	return "commit log reader encountered corrupt entry: " + e.err.Error()
+}
+
+func isCorruptChunkErr(err error) bool {
+	return err == errCommitLogReaderChunkSizeChecksumMismatch ||
+		err == errCommitLogReaderChunkDataChecksumMismatch ||
+		err == errCommitLogReaderChunkTruncated
+}
+
 type commitLogReader interface {
 	// Open opens the commit log for reading
 	Open(filePath string) (time.Time, time.Duration, int, error)
@@ -53,6 +74,12 @@ type commitLogReader interface {
 	// Read returns the next id and data pair or error, will return io.EOF at end of volume
 	Read() (Series, ts.Datapoint, xtime.Unit, ts.Annotation, error)
 
+	// Created returns the time the entry last read was written to the commit log
+	Created() time.Time
+
+	// Skipped returns the number of corrupt bytes and entries skipped since the reader was opened
+	Skipped() (bytes int64, entries int64)
+
 	// Close the reader
 	Close() error
 }
@@ -66,18 +93,24 @@ type reader struct {
 	logDecoder      encoding.Decoder
 	metadataDecoder encoding.Decoder
 	metadataLookup  map[uint64]Series
+	filteredLookup  map[uint64]struct{}
+	filter          SeriesFilterPredicate
+	created         time.Time
+	skippedEntries  int64
 }
 
-func newCommitLogReader(opts Options) commitLogReader {
+func newCommitLogReader(opts Options, filter SeriesFilterPredicate) commitLogReader {
 	decodingOpts := opts.FilesystemOptions().DecodingOptions()
 	return &reader{
 		opts:            opts,
 		bytesPool:       opts.BytesPool(),
-		chunkReader:     newChunkReader(opts.FlushSize()),
+		chunkReader:     newChunkReader(opts.FlushSize()+chunkHeaderLen, opts.ReadCorruptionPolicy()),
 		sizeBuffer:      make([]byte, binary.MaxVarintLen64),
 		logDecoder:      msgpack.NewDecoder(decodingOpts),
 		metadataDecoder: msgpack.NewDecoder(decodingOpts),
 		metadataLookup:  make(map[uint64]Series),
+		filteredLookup:  make(map[uint64]struct{}),
+		filter:          filter,
 	}
 }
 
@@ -91,9 +124,21 @@ func (r *reader) Open(filePath string) (time.Time, time.Duration, int, error) {
 		return timeZero, 0, 0, err
 	}
 
-	r.chunkReader.reset(fd)
+	stat, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return timeZero, 0, 0, err
+	}
+
+	r.chunkReader.reset(fd, stat.Size())
+	r.skippedEntries = 0
 	info, err := r.readInfo()
 	if err != nil {
+		if _, ok := err.(errCorruptEntry); ok || isCorruptChunkErr(err) ||
+			err == errCommitLogReaderChunkSkipped {
+			// Nothing in the file can be trusted without its info
+			r.chunkReader.skipped = r.chunkReader.size
+		}
 		r.Close()
 		return timeZero, 0, 0, err
 	}
@@ -109,6 +154,57 @@ func (r *reader) Read() (
 	unit xtime.Unit,
 	annotation ts.Annotation,
 	resultErr error,
+) {
+	for {
+		series, datapoint, unit, annotation, resultErr = r.read()
+		if resultErr == errCommitLogReaderSeriesFiltered {
+			continue
+		}
+		if resultErr == nil || resultErr == io.EOF || !r.skipCorrupt(resultErr) {
+			return
+		}
+	}
+}
+
+// skipCorrupt returns whether the entry that failed to read with the given
+// error was skipped and reading can continue with the next entry
+func (r *reader) skipCorrupt(err error) bool {
+	if r.opts.ReadCorruptionPolicy() != ReadCorruptionPolicySkip {
+		return false
+	}
+	switch err {
+	case errCommitLogReaderChunkSkipped, errCommitLogReaderMissingLogMetadata:
+	case errCommitLogReaderEntrySizeInvalid:
+		// The size prefix cannot be trusted, resume at the next chunk
+		if err := r.chunkReader.discardRemaining(); err != nil {
+			return false
+		}
+	case io.ErrUnexpectedEOF:
+		// Torn entry at the end of the file, the next read returns io.EOF
+		r.chunkReader.skipped += r.chunkReader.size - r.chunkReader.offset
+	default:
+		if _, ok := err.(errCorruptEntry); !ok {
+			return false
+		}
+	}
+	r.skippedEntries++
+	return true
+}
+
+func (r *reader) Created() time.Time {
+	return r.created
+}
+
+func (r *reader) Skipped() (int64, int64) {
+	return r.chunkReader.skipped, r.skippedEntries
+}
+
+func (r *reader) read() (
+	series Series,
+	datapoint ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+	resultErr error,
 ) {
 	entry, err := r.readEntry()
 	if err != nil {
@@ -120,34 +216,58 @@ func (r *reader) Read() (
 		r.metadataDecoder.Reset(entry.Metadata)
 		decoded, err := r.metadataDecoder.DecodeLogMetadata()
 		if err != nil {
-			resultErr = err
+			resultErr = errCorruptEntry{err: err}
 			return
 		}
 
-		id := r.bytesPool.Get(len(decoded.ID))
-		id.IncRef()
-		defer id.DecRef()
-		id.AppendAll(decoded.ID)
-
 		namespace := r.bytesPool.Get(len(decoded.Namespace))
 		namespace.IncRef()
 		defer namespace.DecRef()
 		namespace.AppendAll(decoded.Namespace)
 
-		r.metadataLookup[entry.Index] = Series{
-			UniqueIndex: entry.Index,
-			ID:          ts.BinaryID(id),
-			Namespace:   ts.BinaryID(namespace),
-			Shard:       uint32(decoded.Shard),
+		if !r.filter(ts.BinaryID(namespace), uint32(decoded.Shard)) {
+			// Remember the series is filtered to skip its entries without a lookup
+			r.filteredLookup[entry.Index] = struct{}{}
+		} else {
+			id := r.bytesPool.Get(len(decoded.ID))
+			id.IncRef()
+			defer id.DecRef()
+			id.AppendAll(decoded.ID)
+
+			var tags ts.Tags
+			if len(decoded.Tags) > 0 {
+				tags = make(ts.Tags, 0, len(decoded.Tags))
+				for _, tag := range decoded.Tags {
+					tags = append(tags, ts.Tag{
+						Name:  ts.BinaryID(checked.NewBytes(append([]byte(nil), tag.Name...), nil)),
+						Value: ts.BinaryID(checked.NewBytes(append([]byte(nil), tag.Value...), nil)),
+					})
+				}
+			}
+
+			r.metadataLookup[entry.Index] = Series{
+				UniqueIndex: entry.Index,
+				ID:          ts.BinaryID(id),
+				Namespace:   ts.BinaryID(namespace),
+				Shard:       uint32(decoded.Shard),
+				Tags:        tags,
+			}
 		}
 	}
 
+	if _, ok := r.filteredLookup[entry.Index]; ok {
+		resultErr = errCommitLogReaderSeriesFiltered
+		return
+	}
+
 	metadata, ok := r.metadataLookup[entry.Index]
 	if !ok {
 		resultErr = errCommitLogReaderMissingLogMetadata
 		return
 	}
 
+	r.created = time.Unix(0, entry.Create)
+
 	series = metadata
 	datapoint = ts.Datapoint{
 		Timestamp: time.Unix(0, entry.Timestamp),
@@ -164,6 +284,9 @@ func (r *reader) readChunk() ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if size > uint64(r.chunkReader.size-r.chunkReader.offset) {
+		return nil, errCommitLogReaderEntrySizeInvalid
+	}
 
 	// Extend buffer as necessary
 	if len(r.dataBuffer) < int(size) {
@@ -177,7 +300,11 @@ func (r *reader) readChunk() ([]byte, error) {
 	buffer := r.dataBuffer[:size]
 
 	// Read message
-	if _, err := r.chunkReader.Read(buffer); err != nil {
+	n, err := r.chunkReader.Read(buffer)
+	if err == io.EOF && n > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
 		return nil, err
 	}
 	return buffer, nil
@@ -189,7 +316,11 @@ func (r *reader) readInfo() (schema.LogInfo, error) {
 		return emptyLogInfo, err
 	}
 	r.logDecoder.Reset(data)
-	return r.logDecoder.DecodeLogInfo()
+	info, err := r.logDecoder.DecodeLogInfo()
+	if err != nil {
+		return emptyLogInfo, errCorruptEntry{err: err}
+	}
+	return info, nil
 }
 
 func (r *reader) readEntry() (schema.LogEntry, error) {
@@ -198,7 +329,11 @@ func (r *reader) readEntry() (schema.LogEntry, error) {
 		return emptyLogEntry, err
 	}
 	r.logDecoder.Reset(data)
-	return r.logDecoder.DecodeLogEntry()
+	entry, err := r.logDecoder.DecodeLogEntry()
+	if err != nil {
+		return emptyLogEntry, errCorruptEntry{err: err}
+	}
+	return entry, nil
 }
 
 func (r *reader) Close() error {
@@ -212,34 +347,59 @@ func (r *reader) Close() error {
 
 	r.chunkReader.fd = nil
 	r.metadataLookup = make(map[uint64]Series)
+	r.filteredLookup = make(map[uint64]struct{})
 	return nil
 }
 
 type chunkReader struct {
 	fd        *os.File
 	buffer    *bufio.Reader
+	policy    ReadCorruptionPolicy
 	remaining int
 	charBuff  []byte
+	size      int64
+	offset    int64
+	skipped   int64
 }
 
-func newChunkReader(bufferLen int) *chunkReader {
+func newChunkReader(bufferLen int, policy ReadCorruptionPolicy) *chunkReader {
 	return &chunkReader{
 		buffer:   bufio.NewReaderSize(nil, bufferLen),
+		policy:   policy,
 		charBuff: make([]byte, 1),
 	}
 }
 
-func (r *chunkReader) reset(fd *os.File) {
+func (r *chunkReader) reset(fd *os.File, size int64) {
 	r.fd = fd
 	r.buffer.Reset(fd)
 	r.remaining = 0
+	r.size = size
+	r.offset = 0
+	r.skipped = 0
 }
 
 func (r *chunkReader) readHeader() error {
-	header, err := r.buffer.Peek(chunkHeaderLen)
+	size, err := r.validateHeader()
+	if isCorruptChunkErr(err) {
+		return r.handleCorruptChunk(err)
+	}
 	if err != nil {
 		return err
 	}
+	return r.consumeHeader(size)
+}
+
+// validateHeader verifies the checksums of the next chunk without consuming
+// it and returns the size of the chunk's data
+func (r *chunkReader) validateHeader() (int, error) {
+	header, err := r.buffer.Peek(chunkHeaderLen)
+	if err == io.EOF && len(header) > 0 {
+		return 0, errCommitLogReaderChunkTruncated
+	}
+	if err != nil {
+		return 0, err
+	}
 
 	sizeStart, sizeEnd :=
 		0, chunkHeaderSizeLen
@@ -258,30 +418,96 @@ func (r *chunkReader) readHeader() error {
 
 	// Verify size checksum
 	if digest.Checksum(header[:4]) != checksumSize {
-		return errCommitLogReaderChunkSizeChecksumMismatch
+		return 0, errCommitLogReaderChunkSizeChecksumMismatch
 	}
 
-	// Discard the peeked header
-	if _, err := r.buffer.Discard(chunkHeaderLen); err != nil {
-		return err
+	// Verify the chunk fits in the remainder of the file
+	if int64(size) > r.size-r.offset-chunkHeaderLen {
+		return 0, errCommitLogReaderChunkTruncated
 	}
 
 	// Verify data checksum
-	data, err := r.buffer.Peek(int(size))
+	chunk, err := r.buffer.Peek(chunkHeaderLen + int(size))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if digest.Checksum(data) != checksumData {
-		return errCommitLogReaderChunkSizeChecksumMismatch
+	if digest.Checksum(chunk[chunkHeaderLen:]) != checksumData {
+		return 0, errCommitLogReaderChunkDataChecksumMismatch
+	}
+
+	return int(size), nil
+}
+
+func (r *chunkReader) consumeHeader(size int) error {
+	// Discard the peeked header
+	if err := r.discard(chunkHeaderLen); err != nil {
+		return err
 	}
 
 	// Set remaining data to be consumed
-	r.remaining = int(size)
+	r.remaining = size
+
+	return nil
+}
+
+func (r *chunkReader) handleCorruptChunk(err error) error {
+	switch r.policy {
+	case ReadCorruptionPolicySkip:
+		return r.skipToNextChunk()
+	case ReadCorruptionPolicyTruncate:
+		// The remainder of the file is discarded
+		r.skipped += r.size - r.offset
+	}
+	return err
+}
+
+// skipToNextChunk discards bytes until the next valid chunk header, it
+// returns errCommitLogReaderChunkSkipped once positioned at a valid chunk
+// or io.EOF if no valid chunk remains in the file
+func (r *chunkReader) skipToNextChunk() error {
+	for {
+		if err := r.discard(1); err != nil {
+			return err
+		}
+		r.skipped++
+
+		size, err := r.validateHeader()
+		if isCorruptChunkErr(err) {
+			continue
+		}
+		if err == io.EOF {
+			r.skipped += r.size - r.offset
+			return io.EOF
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.consumeHeader(size); err != nil {
+			return err
+		}
+		return errCommitLogReaderChunkSkipped
+	}
+}
 
+// discardRemaining discards the rest of the current chunk and
+// counts it as skipped
+func (r *chunkReader) discardRemaining() error {
+	remaining := r.remaining
+	if err := r.discard(remaining); err != nil {
+		return err
+	}
+	r.remaining = 0
+	r.skipped += int64(remaining)
 	return nil
 }
 
+func (r *chunkReader) discard(n int) error {
+	discarded, err := r.buffer.Discard(n)
+	r.offset += int64(discarded)
+	return err
+}
+
 func (r *chunkReader) Read(p []byte) (int, error) {
 	size := len(p)
 	read := 0
@@ -291,6 +517,7 @@ func (r *chunkReader) Read(p []byte) (int, error) {
 		if r.remaining > 0 {
 			n, err := r.buffer.Read(p[:r.remaining])
 			r.remaining -= n
+			r.offset += int64(n)
 			read += n
 			if err != nil {
 				return read, err
@@ -314,6 +541,7 @@ func (r *chunkReader) Read(p []byte) (int, error) {
 
 	n, err := r.buffer.Read(p)
 	r.remaining -= n
+	r.offset += int64(n)
 	read += n
 	return read, err
 }
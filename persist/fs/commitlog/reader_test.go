@@ -0,0 +1,166 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package commitlog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCorruptWrites = 20
+
+func writeAndCorruptCommitLog(t *testing.T, opts Options) []testWrite {
+	w := newCommitLogWriter(func(err error) {}, opts)
+	start := time.Now().Truncate(opts.RetentionOptions().BlockSize())
+	file, err := w.Open(start, opts.RetentionOptions().BlockSize())
+	require.NoError(t, err)
+
+	var writes []testWrite
+	for i := 0; i < testCorruptWrites; i++ {
+		write := testWrite{
+			series: testSeries(uint64(i), fmt.Sprintf("foo.bar.%d", i), uint32(i)),
+			t:      start.Add(time.Duration(i) * time.Second),
+			v:      float64(i),
+			u:      xtime.Second,
+		}
+		datapoint := ts.Datapoint{Timestamp: write.t, Value: write.v}
+		require.NoError(t, w.Write(write.series, datapoint, write.u, nil))
+		writes = append(writes, write)
+	}
+	require.NoError(t, w.Close())
+
+	// Flip a byte in the middle of the file
+	data, err := ioutil.ReadFile(file.FilePath)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(file.FilePath, data, opts.FilesystemOptions().NewFileMode()))
+
+	return writes
+}
+
+func readCorruptCommitLog(t *testing.T, opts Options, writes []testWrite) (int, error) {
+	iter, err := NewIterator(opts, ReadAllPredicate(), ReadAllSeriesPredicate())
+	require.NoError(t, err)
+	defer iter.Close()
+
+	read := 0
+	for iter.Next() {
+		series, datapoint, unit, annotation := iter.Current()
+		// Entries are read in order, skipping any that were lost
+		for read < len(writes) && writes[read].series.UniqueIndex != series.UniqueIndex {
+			read++
+		}
+		require.True(t, read < len(writes))
+		writes[read].assert(t, series, datapoint, unit, annotation)
+		read++
+	}
+	return read, iter.Err()
+}
+
+func newCorruptionTestOptions(t *testing.T, policy ReadCorruptionPolicy) Options {
+	opts, _ := newTestOptions(t, overrides{})
+	return opts.SetFlushSize(128).SetReadCorruptionPolicy(policy)
+}
+
+func TestReaderCorruptionPolicyTruncate(t *testing.T) {
+	opts := newCorruptionTestOptions(t, ReadCorruptionPolicyTruncate)
+	defer cleanup(t, opts)
+
+	writes := writeAndCorruptCommitLog(t, opts)
+
+	iter, err := NewIterator(opts, ReadAllPredicate(), ReadAllSeriesPredicate())
+	require.NoError(t, err)
+	defer iter.Close()
+
+	// Only a prefix of the writes is read
+	read := 0
+	for iter.Next() {
+		series, datapoint, unit, annotation := iter.Current()
+		writes[read].assert(t, series, datapoint, unit, annotation)
+		read++
+	}
+	assert.True(t, read > 0)
+	assert.True(t, read < testCorruptWrites)
+
+	corruptErr, ok := iter.Err().(CorruptionError)
+	require.True(t, ok)
+	assert.Equal(t, 1, corruptErr.Files)
+	assert.True(t, corruptErr.SkippedBytes > 0)
+}
+
+func TestReaderCorruptionPolicySkip(t *testing.T) {
+	opts := newCorruptionTestOptions(t, ReadCorruptionPolicySkip)
+	defer cleanup(t, opts)
+
+	writes := writeAndCorruptCommitLog(t, opts)
+
+	truncateOpts := opts.SetReadCorruptionPolicy(ReadCorruptionPolicyTruncate)
+	truncated, _ := readCorruptCommitLog(t, truncateOpts, writes)
+
+	var read int
+	iter, err := NewIterator(opts, ReadAllPredicate(), ReadAllSeriesPredicate())
+	require.NoError(t, err)
+	for iter.Next() {
+		read++
+	}
+	iter.Close()
+
+	// Entries after the corrupt chunk are still read
+	assert.True(t, read > truncated)
+	assert.True(t, read < testCorruptWrites)
+
+	_, err = readCorruptCommitLog(t, opts, writes)
+	corruptErr, ok := err.(CorruptionError)
+	require.True(t, ok)
+	assert.Equal(t, 1, corruptErr.Files)
+	assert.True(t, corruptErr.SkippedBytes > 0)
+}
+
+func TestReaderCorruptionPolicyFail(t *testing.T) {
+	opts := newCorruptionTestOptions(t, ReadCorruptionPolicyFail)
+	defer cleanup(t, opts)
+
+	writes := writeAndCorruptCommitLog(t, opts)
+
+	read, err := readCorruptCommitLog(t, opts, writes)
+	assert.True(t, read < testCorruptWrites)
+	require.Error(t, err)
+	_, ok := err.(CorruptionError)
+	assert.False(t, ok)
+}
+
+func TestParseReadCorruptionPolicy(t *testing.T) {
+	for _, policy := range validReadCorruptionPolicies {
+		parsed, err := ParseReadCorruptionPolicy(policy.String())
+		require.NoError(t, err)
+		assert.Equal(t, policy, parsed)
+	}
+	_, err := ParseReadCorruptionPolicy("ignore")
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@
 package commitlog
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/m3db/m3db/clock"
@@ -46,8 +47,61 @@ const (
 	// for the buffered commit log chunk that contains a write to flush
 	// before acknowledging a write
 	StrategyWriteBehind
+
+	// StrategyWriteSync describes the strategy that waits for the
+	// commit log chunk that contains a write to flush and fsync before
+	// acknowledging a write, concurrent writes share a single fsync
+	StrategyWriteSync
+)
+
+// ReadCorruptionPolicy describes how a commit log iterator handles
+// corrupt chunks encountered while reading a commit log file
+type ReadCorruptionPolicy int
+
+const (
+	// ReadCorruptionPolicyTruncate describes the policy that discards the
+	// remainder of a commit log file after a corrupt chunk and continues
+	// with the next file
+	ReadCorruptionPolicyTruncate ReadCorruptionPolicy = iota
+
+	// ReadCorruptionPolicySkip describes the policy that skips past a
+	// corrupt chunk to the next valid chunk header in the same file
+	ReadCorruptionPolicySkip
+
+	// ReadCorruptionPolicyFail describes the policy that stops iterating
+	// and returns an error on the first corrupt chunk
+	ReadCorruptionPolicyFail
 )
 
+var validReadCorruptionPolicies = []ReadCorruptionPolicy{
+	ReadCorruptionPolicyTruncate,
+	ReadCorruptionPolicySkip,
+	ReadCorruptionPolicyFail,
+}
+
+func (p ReadCorruptionPolicy) String() string {
+	switch p {
+	case ReadCorruptionPolicyTruncate:
+		return "truncate"
+	case ReadCorruptionPolicySkip:
+		return "skip"
+	case ReadCorruptionPolicyFail:
+		return "fail"
+	}
+	return "unknown"
+}
+
+// ParseReadCorruptionPolicy parses a read corruption policy from its string representation
+func ParseReadCorruptionPolicy(str string) (ReadCorruptionPolicy, error) {
+	for _, valid := range validReadCorruptionPolicies {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid commit log read corruption policy '%s' valid policies are: %v",
+		str, validReadCorruptionPolicies)
+}
+
 // CommitLog provides a synchronized commit log
 type CommitLog interface {
 	// Open the commit log
@@ -71,8 +125,23 @@ type CommitLog interface {
 		annotation ts.Annotation,
 	) error
 
-	// Iter returns an iterator for accessing commit logs
-	Iter() (Iterator, error)
+	// WriteSync will write an entry in the commit log for a given series and
+	// wait for the entry to be fsynced to disk before returning
+	WriteSync(
+		ctx context.Context,
+		series Series,
+		datapoint ts.Datapoint,
+		unit xtime.Unit,
+		annotation ts.Annotation,
+	) error
+
+	// Iter returns an iterator for accessing the commit log entries
+	// of the series accepted by the filter
+	Iter(filter SeriesFilterPredicate) (Iterator, error)
+
+	// RotateLogs rotates the commit log to a new file and returns it, all
+	// writes enqueued before the rotation are written to preceding files
+	RotateLogs() (File, error)
 
 	// Close the commit log
 	Close() error
@@ -86,13 +155,69 @@ type Iterator interface {
 	// Current returns the current commit log entry
 	Current() (Series, ts.Datapoint, xtime.Unit, ts.Annotation)
 
-	// Err returns an error if an error occurred
+	// Err returns an error if an error occurred, if corrupt data was
+	// skipped or truncated while iterating it returns a CorruptionError
 	Err() error
 
 	// Close the iterator
 	Close()
 }
 
+// CorruptionError is returned by an iterator that skipped or truncated
+// corrupt commit log data while iterating
+type CorruptionError struct {
+	// Files is the number of files that contained corrupt data
+	Files int
+
+	// SkippedBytes is the number of bytes that were skipped
+	SkippedBytes int64
+
+	// SkippedEntries is the number of entries that could not be read
+	SkippedEntries int64
+}
+
+func (e CorruptionError) Error() string {
+	return fmt.Sprintf("commit log corruption in %d files: skipped %d bytes and %d entries",
+		e.Files, e.SkippedBytes, e.SkippedEntries)
+}
+
+// File describes a commit log file
+type File struct {
+	// FilePath is the path of the file
+	FilePath string
+
+	// Start is the start of the block the file was opened for
+	Start time.Time
+
+	// Duration is the block size the file was opened with
+	Duration time.Duration
+
+	// Index is the index of the file among the files with the same start
+	Index int
+}
+
+// FileFilterPredicate is a predicate that determines whether a commit log
+// file is read by an iterator
+type FileFilterPredicate func(f File) bool
+
+// ReadAllPredicate returns a predicate that reads all commit log files
+func ReadAllPredicate() FileFilterPredicate {
+	return func(f File) bool {
+		return true
+	}
+}
+
+// SeriesFilterPredicate is a predicate that determines whether the entries
+// of a series in the given namespace and shard are returned by an iterator
+type SeriesFilterPredicate func(namespace ts.ID, shard uint32) bool
+
+// ReadAllSeriesPredicate returns a predicate that reads the entries of all series
+func ReadAllSeriesPredicate() SeriesFilterPredicate {
+	return func(namespace ts.ID, shard uint32) bool {
+		return true
+	}
+}
+
 // Series describes a series in the commit log
 type Series struct {
 	// UniqueIndex is the unique index assigned to this series
@@ -106,6 +231,9 @@ type Series struct {
 
 	// Shard is the shard the series belongs to
 	Shard uint32
+
+	// Tags are the tags the series was written with, if any
+	Tags ts.Tags
 }
 
 // Options represents the options for the commit log
@@ -163,4 +291,17 @@ type Options interface {
 
 	// BytesPool returns the checked bytes pool
 	BytesPool() pool.CheckedBytesPool
+
+	// SetReadCorruptionPolicy sets the policy for corrupt chunks encountered when reading
+	SetReadCorruptionPolicy(value ReadCorruptionPolicy) Options
+
+	// ReadCorruptionPolicy returns the policy for corrupt chunks encountered when reading
+	ReadCorruptionPolicy() ReadCorruptionPolicy
+
+	// SetNumStripes sets the number of stripes, each stripe is written to its
+	// own commit log file by its own writer and series are assigned to stripes by hash
+	SetNumStripes(value int) Options
+
+	// NumStripes returns the number of stripes
+	NumStripes() int
 }
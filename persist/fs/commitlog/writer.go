@@ -58,8 +58,8 @@ var (
 )
 
 type commitLogWriter interface {
-	// Open opens the commit log for writing data
-	Open(start time.Time, duration time.Duration) error
+	// Open opens the commit log for writing data and returns the file opened
+	Open(start time.Time, duration time.Duration) (File, error)
 
 	// Write will write an entry in the commit log for a given series
 	Write(
@@ -72,6 +72,9 @@ type commitLogWriter interface {
 	// Flush will flush the contents to the disk, useful when first testing if first commit log is writable
 	Flush() error
 
+	// Sync will flush the contents to the disk and fsync the file
+	Sync() error
+
 	// Close the reader
 	Close() error
 }
@@ -84,6 +87,8 @@ type writer struct {
 	newDirectoryMode   os.FileMode
 	nowFn              clock.NowFn
 	bitset             bitset
+	index              fileIndex
+	filePath           string
 	start              time.Time
 	duration           time.Duration
 	chunkWriter        *chunkWriter
@@ -107,20 +112,21 @@ func newCommitLogWriter(
 		chunkReserveHeader: make([]byte, chunkHeaderLen),
 		buffer:             bufio.NewWriterSize(nil, opts.FlushSize()),
 		bitset:             newBitset(),
+		index:              make(fileIndex),
 		sizeBuffer:         make([]byte, binary.MaxVarintLen64),
 		logEncoder:         msgpack.NewEncoder(),
 		metadataEncoder:    msgpack.NewEncoder(),
 	}
 }
 
-func (w *writer) Open(start time.Time, duration time.Duration) error {
+func (w *writer) Open(start time.Time, duration time.Duration) (File, error) {
 	if w.isOpen() {
-		return errCommitLogWriterAlreadyOpen
+		return File{}, errCommitLogWriterAlreadyOpen
 	}
 
 	commitLogsDir := fs.CommitLogsDirPath(w.filePathPrefix)
 	if err := os.MkdirAll(commitLogsDir, w.newDirectoryMode); err != nil {
-		return err
+		return File{}, err
 	}
 
 	filePath, index := fs.NextCommitLogsFile(w.filePathPrefix, start)
@@ -131,23 +137,29 @@ func (w *writer) Open(start time.Time, duration time.Duration) error {
 	}
 	w.logEncoder.Reset()
 	if err := w.logEncoder.EncodeLogInfo(logInfo); err != nil {
-		return err
+		return File{}, err
 	}
 	fd, err := fs.OpenWritable(filePath, w.newFileMode)
 	if err != nil {
-		return err
+		return File{}, err
 	}
 
 	w.chunkWriter.fd = fd
 	w.buffer.Reset(w.chunkWriter)
 	if err := w.write(w.logEncoder.Bytes()); err != nil {
 		w.Close()
-		return err
+		return File{}, err
 	}
 
+	w.filePath = filePath
 	w.start = start
 	w.duration = duration
-	return nil
+	return File{
+		FilePath: filePath,
+		Start:    start,
+		Duration: duration,
+		Index:    index,
+	}, nil
 }
 
 func (w *writer) isOpen() bool {
@@ -172,6 +184,15 @@ func (w *writer) Write(
 		metadata.ID = series.ID.Data().Get()
 		metadata.Namespace = series.Namespace.Data().Get()
 		metadata.Shard = series.Shard
+		if len(series.Tags) > 0 {
+			metadata.Tags = make([]schema.IndexTag, 0, len(series.Tags))
+			for _, tag := range series.Tags {
+				metadata.Tags = append(metadata.Tags, schema.IndexTag{
+					Name:  tag.Name.Data().Get(),
+					Value: tag.Value.Data().Get(),
+				})
+			}
+		}
 		w.metadataEncoder.Reset()
 		if err := w.metadataEncoder.EncodeLogMetadata(metadata); err != nil {
 			return err
@@ -194,6 +215,7 @@ func (w *writer) Write(
 	if !seen {
 		// Record we have seen this series
 		w.bitset.set(logEntry.Index)
+		w.index.add(series.Namespace.Data().Get(), series.Shard)
 	}
 	return nil
 }
@@ -202,6 +224,13 @@ func (w *writer) Flush() error {
 	return w.buffer.Flush()
 }
 
+func (w *writer) Sync() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return w.chunkWriter.fd.Sync()
+}
+
 func (w *writer) Close() error {
 	if !w.isOpen() {
 		return nil
@@ -215,10 +244,16 @@ func (w *writer) Close() error {
 	}
 
 	w.chunkWriter.fd = nil
+
+	// Write the index last so that only files closed cleanly are indexed
+	err := writeFileIndex(w.filePath, w.index, w.newFileMode)
+
 	w.bitset.clearAll()
+	w.index.clear()
+	w.filePath = ""
 	w.start = timeZero
 	w.duration = 0
-	return nil
+	return err
 }
 
 func (w *writer) write(data []byte) error {
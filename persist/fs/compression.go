@@ -0,0 +1,180 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/pool"
+)
+
+// maxDeflateRatio is the max ratio of the size of data to the size of the data
+// compressed with deflate, it bounds the size prefix of compressed data so a
+// corrupt prefix is rejected rather than allocated.
+const maxDeflateRatio = 1032
+
+var (
+	// errCompressedSizeInvalid returned when the size prefix of compressed data is invalid
+	errCompressedSizeInvalid = errors.New("compressed data size prefix invalid")
+
+	// errCompressionTypeUnknown returned when a fileset uses an unknown compression type
+	errCompressionTypeUnknown = errors.New("unknown compression type")
+)
+
+// CompressionType is the codec used to compress the data of each series in a fileset.
+type CompressionType int
+
+const (
+	// CompressionNone stores the data of each series as is
+	CompressionNone CompressionType = iota
+
+	// CompressionDeflate compresses the data of each series with deflate
+	// at its fastest level
+	CompressionDeflate
+)
+
+var validCompressionTypes = []CompressionType{
+	CompressionNone,
+	CompressionDeflate,
+}
+
+func (t CompressionType) String() string {
+	switch t {
+	case CompressionNone:
+		return "none"
+	case CompressionDeflate:
+		return "deflate"
+	}
+	return "unknown"
+}
+
+// Validate returns an error if the compression type is unknown.
+func (t CompressionType) Validate() error {
+	for _, valid := range validCompressionTypes {
+		if t == valid {
+			return nil
+		}
+	}
+	return errCompressionTypeUnknown
+}
+
+// ParseCompressionType parses a compression type from its string representation.
+func ParseCompressionType(str string) (CompressionType, error) {
+	for _, valid := range validCompressionTypes {
+		if str == valid.String() {
+			return valid, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid compression type '%s' valid types are: %v",
+		str, validCompressionTypes)
+}
+
+// compressor compresses the data of a series, compressed data is prefixed
+// with the uncompressed size so it can be decompressed into a single buffer.
+type compressor struct {
+	buf    bytes.Buffer
+	sizes  []byte
+	writer *flate.Writer
+}
+
+func newCompressor() *compressor {
+	return &compressor{sizes: make([]byte, binary.MaxVarintLen64)}
+}
+
+// Compress returns the compressed data, it is only valid until the next call.
+func (c *compressor) Compress(data []checked.Bytes, size int64) ([]byte, error) {
+	c.buf.Reset()
+	n := binary.PutUvarint(c.sizes, uint64(size))
+	c.buf.Write(c.sizes[:n])
+
+	if c.writer == nil {
+		writer, err := flate.NewWriter(&c.buf, flate.BestSpeed)
+		if err != nil {
+			return nil, err
+		}
+		c.writer = writer
+	} else {
+		c.writer.Reset(&c.buf)
+	}
+
+	for _, d := range data {
+		if d == nil {
+			continue
+		}
+		if _, err := c.writer.Write(d.Get()); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.writer.Close(); err != nil {
+		return nil, err
+	}
+	return c.buf.Bytes(), nil
+}
+
+// decompressor decompresses data compressed by the compressor.
+type decompressor struct {
+	src    bytes.Reader
+	reader io.ReadCloser
+}
+
+func newDecompressor() *decompressor {
+	return &decompressor{}
+}
+
+// Decompress returns the decompressed data using bytes from the pool if set.
+func (d *decompressor) Decompress(
+	compressed []byte,
+	bytesPool pool.CheckedBytesPool,
+) (checked.Bytes, error) {
+	size, n := binary.Uvarint(compressed)
+	if n <= 0 || size > uint64(len(compressed)-n)*maxDeflateRatio {
+		return nil, errCompressedSizeInvalid
+	}
+	d.src.Reset(compressed[n:])
+	if d.reader == nil {
+		d.reader = flate.NewReader(&d.src)
+	} else if err := d.reader.(flate.Resetter).Reset(&d.src, nil); err != nil {
+		return nil, err
+	}
+
+	var data checked.Bytes
+	if bytesPool != nil {
+		data = bytesPool.Get(int(size))
+		data.IncRef()
+		defer data.DecRef()
+		data.Resize(int(size))
+	} else {
+		data = checked.NewBytes(make([]byte, size), nil)
+		data.IncRef()
+		defer data.DecRef()
+	}
+
+	if _, err := io.ReadFull(d.reader, data.Get()); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/m3db/m3x/checked"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressorRoundtrip(t *testing.T) {
+	var (
+		c    = newCompressor()
+		d    = newDecompressor()
+		head = bytes.Repeat([]byte{1, 2, 3}, 100)
+		tail = []byte{4, 5, 6}
+	)
+	for i := 0; i < 2; i++ {
+		data := []checked.Bytes{bytesRefd(head), nil, bytesRefd(tail)}
+		compressed, err := c.Compress(data, int64(len(head)+len(tail)))
+		require.NoError(t, err)
+		require.True(t, len(compressed) < len(head))
+
+		decompressed, err := d.Decompress(compressed, nil)
+		require.NoError(t, err)
+		decompressed.IncRef()
+		require.Equal(t, append(append([]byte(nil), head...), tail...), decompressed.Get())
+		decompressed.DecRef()
+	}
+
+	_, err := d.Decompress(nil, nil)
+	require.Equal(t, errCompressedSizeInvalid, err)
+
+	// A size prefix larger than the compressed data could decompress to
+	// is rejected before allocating
+	_, err = d.Decompress([]byte{0xff, 0xff, 0xff, 0xff, 0x0f, 0x01}, nil)
+	require.Equal(t, errCompressedSizeInvalid, err)
+}
+
+func TestParseCompressionType(t *testing.T) {
+	for _, valid := range validCompressionTypes {
+		parsed, err := ParseCompressionType(valid.String())
+		require.NoError(t, err)
+		require.Equal(t, valid, parsed)
+		require.NoError(t, parsed.Validate())
+	}
+
+	_, err := ParseCompressionType("lz4")
+	require.Error(t, err)
+	require.Equal(t, errCompressionTypeUnknown, CompressionType(100).Validate())
+}
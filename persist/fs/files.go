@@ -97,6 +97,35 @@ func DeleteFiles(filePaths []string) error {
 	return multiErr.FinalError()
 }
 
+// DeleteCommitLogFiles removes the given commit log files along with their index files.
+func DeleteCommitLogFiles(filePaths []string) error {
+	multiErr := xerrors.NewMultiError()
+	if err := DeleteFiles(filePaths); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	for _, file := range filePaths {
+		// Commit log files still being written or written before indexes existed have no index
+		indexFile := CommitLogIndexFilePath(file)
+		if err := os.Remove(indexFile); err != nil && !os.IsNotExist(err) {
+			detailedErr := fmt.Errorf("failed to remove file %s: %v", indexFile, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+// DeletePaths removes the given files and directories along with their contents.
+func DeletePaths(paths []string) error {
+	multiErr := xerrors.NewMultiError()
+	for _, p := range paths {
+		if err := os.RemoveAll(p); err != nil {
+			detailedErr := fmt.Errorf("failed to remove path %s: %v", p, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
 // byTimeAscending sorts files by their block start times in ascending order.
 // If the files do not have block start times in their names, the result is undefined.
 type byTimeAscending []string
@@ -144,12 +173,17 @@ func TimeFromFileName(fname string) (time.Time, error) {
 	return t, err
 }
 
-// TimeAndIndexFromFileName extracts the block start and index from file name.
+// TimeAndIndexFromFileName extracts the block start and index from file name,
+// the index of a fileset file is its version and unversioned fileset files
+// are version 0.
 func TimeAndIndexFromFileName(fname string) (time.Time, int, error) {
 	components, t, err := componentsAndTimeFromFileName(fname)
 	if err != nil {
 		return timeZero, 0, err
 	}
+	if len(components) == 3 && components[0] == filesetFilePrefix {
+		return t, 0, nil
+	}
 	str := strings.Replace(components[2], fileSuffix, "", 1)
 	index, err := strconv.ParseInt(str, 10, 64)
 	if err != nil {
@@ -160,24 +194,40 @@ func TimeAndIndexFromFileName(fname string) (time.Time, int, error) {
 
 type infoFileFn func(fname string, infoData []byte)
 
+// forEachInfoFile calls fn with the info file of the latest complete
+// version of the fileset for each block start, skipping any block start
+// whose latest complete version fails validation.
 func forEachInfoFile(filePathPrefix string, namespace ts.ID, shard uint32, readerBufferSize int, fn infoFileFn) {
-	matched, err := filesetFiles(filePathPrefix, namespace, shard, infoFilePattern)
+	matched, err := filesetFiles(filePathPrefix, namespace, shard, checkpointFilePattern)
 	if err != nil {
 		return
 	}
 
-	shardDir := ShardDirPath(filePathPrefix, namespace, shard)
-	digestBuf := digest.NewBuffer()
+	// Matched files are sorted by block start then version so the last
+	// checkpoint file for each block start is the latest version
+	latest := matched[:0]
 	for i := range matched {
-		t, err := TimeFromFileName(matched[i])
+		t, _, err := TimeAndIndexFromFileName(matched[i])
 		if err != nil {
 			continue
 		}
-		checkpointFilePath := filesetPathFromTime(shardDir, t, checkpointFileSuffix)
-		if !FileExists(checkpointFilePath) {
+		if n := len(latest); n > 0 {
+			if prev, _ := TimeFromFileName(latest[n-1]); prev.Equal(t) {
+				latest[n-1] = matched[i]
+				continue
+			}
+		}
+		latest = append(latest, matched[i])
+	}
+
+	shardDir := ShardDirPath(filePathPrefix, namespace, shard)
+	digestBuf := digest.NewBuffer()
+	for i := range latest {
+		t, version, err := TimeAndIndexFromFileName(latest[i])
+		if err != nil {
 			continue
 		}
-		checkpointFd, err := os.Open(checkpointFilePath)
+		checkpointFd, err := os.Open(latest[i])
 		if err != nil {
 			continue
 		}
@@ -188,17 +238,19 @@ func forEachInfoFile(filePathPrefix string, namespace ts.ID, shard uint32, reade
 			continue
 		}
 		// Read and validate the digest file
-		digestData, err := readAndValidate(shardDir, t, digestFileSuffix, readerBufferSize, expectedDigestOfDigest)
+		digestFilePath := filesetPathFromTimeAndVersion(shardDir, t, version, digestFileSuffix)
+		digestData, err := readAndValidate(digestFilePath, readerBufferSize, expectedDigestOfDigest)
 		if err != nil {
 			continue
 		}
 		// Read and validate the info file
 		expectedInfoDigest := digest.ToBuffer(digestData).ReadDigest()
-		infoData, err := readAndValidate(shardDir, t, infoFileSuffix, readerBufferSize, expectedInfoDigest)
+		infoFilePath := filesetPathFromTimeAndVersion(shardDir, t, version, infoFileSuffix)
+		infoData, err := readAndValidate(infoFilePath, readerBufferSize, expectedInfoDigest)
 		if err != nil {
 			continue
 		}
-		fn(matched[i], infoData)
+		fn(infoFilePath, infoData)
 	}
 }
 
@@ -232,6 +284,41 @@ func FilesetBefore(filePathPrefix string, namespace ts.ID, shard uint32, t time.
 	return filesBefore(matched, t)
 }
 
+// SupersededFilesets returns all the fileset files of versions older than the
+// latest complete version of the fileset for their block start.
+func SupersededFilesets(filePathPrefix string, namespace ts.ID, shard uint32) ([]string, error) {
+	checkpoints, err := filesetFiles(filePathPrefix, namespace, shard, checkpointFilePattern)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[time.Time]int, len(checkpoints))
+	for _, f := range checkpoints {
+		t, version, err := TimeAndIndexFromFileName(f)
+		if err != nil {
+			continue
+		}
+		if curr, ok := latest[t]; !ok || version > curr {
+			latest[t] = version
+		}
+	}
+
+	matched, err := filesetFiles(filePathPrefix, namespace, shard, filesetFilePattern)
+	if err != nil {
+		return nil, err
+	}
+	var superseded []string
+	for _, f := range matched {
+		t, version, err := TimeAndIndexFromFileName(f)
+		if err != nil {
+			continue
+		}
+		if curr, ok := latest[t]; ok && version < curr {
+			superseded = append(superseded, f)
+		}
+	}
+	return superseded, nil
+}
+
 // CommitLogFiles returns all the commit log files in the commit logs directory.
 func CommitLogFiles(commitLogsDir string) ([]string, error) {
 	return commitlogFiles(commitLogsDir, commitLogFilePattern)
@@ -272,7 +359,7 @@ func findFiles(fileDir string, pattern string, fn toSortableFn) ([]string, error
 func filesetFiles(filePathPrefix string, namespace ts.ID, shard uint32, pattern string) ([]string, error) {
 	shardDir := ShardDirPath(filePathPrefix, namespace, shard)
 	return findFiles(shardDir, pattern, func(files []string) sort.Interface {
-		return byTimeAscending(files)
+		return byTimeAndIndexAscending(files)
 	})
 }
 
@@ -304,13 +391,10 @@ func filesBefore(files []string, t time.Time) ([]string, error) {
 }
 
 func readAndValidate(
-	prefix string,
-	t time.Time,
-	suffix string,
+	filePath string,
 	readerBufferSize int,
 	expectedDigest uint32,
 ) ([]byte, error) {
-	filePath := filesetPathFromTime(prefix, t, suffix)
 	fd, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -334,9 +418,14 @@ func readAndValidate(
 	return buf[:n], nil
 }
 
+// DataDirPath returns the path to the data directory holding all namespaces.
+func DataDirPath(prefix string) string {
+	return path.Join(prefix, dataDirName)
+}
+
 // NamespaceDirPath returns the path to a given namespace.
 func NamespaceDirPath(prefix string, namespace ts.ID) string {
-	return path.Join(prefix, dataDirName, namespace.String())
+	return path.Join(DataDirPath(prefix), namespace.String())
 }
 
 // ShardDirPath returns the path to a given shard.
@@ -352,9 +441,30 @@ func CommitLogsDirPath(prefix string) string {
 
 // FilesetExistsAt determines whether a data file exists for the given namespace, shard, and block start time.
 func FilesetExistsAt(prefix string, namespace ts.ID, shard uint32, blockStart time.Time) bool {
+	_, exists := LatestFilesetVersion(prefix, namespace, shard, blockStart)
+	return exists
+}
+
+// LatestFilesetVersion returns the latest complete version of the fileset for the given
+// namespace, shard, and block start time, and whether any complete version exists.
+func LatestFilesetVersion(prefix string, namespace ts.ID, shard uint32, blockStart time.Time) (int, bool) {
 	shardDir := ShardDirPath(prefix, namespace, shard)
-	checkpointFile := filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
-	return FileExists(checkpointFile)
+	pattern := fmt.Sprintf(checkpointFileForTimeTemplate, blockStart.UnixNano())
+	matched, err := findFiles(shardDir, pattern, func(files []string) sort.Interface {
+		return byTimeAndIndexAscending(files)
+	})
+	if err != nil {
+		return 0, false
+	}
+	if len(matched) == 0 {
+		// Version 0 is unversioned so filesets written before versioning are readable
+		return 0, FileExists(filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix))
+	}
+	_, version, err := TimeAndIndexFromFileName(matched[len(matched)-1])
+	if err != nil {
+		return 0, false
+	}
+	return version, true
 }
 
 // NextCommitLogsFile returns the next commit logs file.
@@ -369,6 +479,12 @@ func NextCommitLogsFile(prefix string, start time.Time) (string, int) {
 	}
 }
 
+// CommitLogIndexFilePath returns the path to the index file of a commit log file.
+func CommitLogIndexFilePath(commitLogFilePath string) string {
+	dir, name := filepath.Split(commitLogFilePath)
+	return path.Join(dir, commitLogIndexPrefix+strings.TrimPrefix(name, commitLogFilePrefix))
+}
+
 // FileExists returns whether a file at the given path exists.
 func FileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
@@ -384,3 +500,15 @@ func filesetPathFromTime(prefix string, t time.Time, suffix string) string {
 	name := fmt.Sprintf("%s%s%d%s%s%s", filesetFilePrefix, separator, t.UnixNano(), separator, suffix, fileSuffix)
 	return path.Join(prefix, name)
 }
+
+// filesetPathFromTimeAndVersion returns the path of a fileset file, version 0
+// uses the unversioned name so filesets written before versioning are read as
+// version 0.
+func filesetPathFromTimeAndVersion(prefix string, t time.Time, version int, suffix string) string {
+	if version == 0 {
+		return filesetPathFromTime(prefix, t, suffix)
+	}
+	name := fmt.Sprintf("%s%s%d%s%d%s%s%s", filesetFilePrefix, separator, t.UnixNano(),
+		separator, version, separator, suffix, fileSuffix)
+	return path.Join(prefix, name)
+}
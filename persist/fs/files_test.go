@@ -55,7 +55,11 @@ func createTempDir(t *testing.T) string {
 }
 
 func createDataFile(t *testing.T, shardDir string, blockStart time.Time, suffix string, b []byte) {
-	filePath := filesetPathFromTime(shardDir, blockStart, suffix)
+	createDataFileVersion(t, shardDir, blockStart, 0, suffix, b)
+}
+
+func createDataFileVersion(t *testing.T, shardDir string, blockStart time.Time, version int, suffix string, b []byte) {
+	filePath := filesetPathFromTimeAndVersion(shardDir, blockStart, version, suffix)
 	createFile(t, filePath, b)
 }
 
@@ -74,7 +78,7 @@ func createInfoFiles(t *testing.T, namespace ts.ID, shard uint32, iter int) stri
 	require.NoError(t, os.MkdirAll(shardDir, 0755))
 	for i := 0; i < iter; i++ {
 		ts := time.Unix(0, int64(i))
-		infoFilePath := filesetPathFromTime(shardDir, ts, infoFileSuffix)
+		infoFilePath := filesetPathFromTimeAndVersion(shardDir, ts, 0, infoFileSuffix)
 		createFile(t, infoFilePath, nil)
 	}
 	return dir
@@ -147,6 +151,33 @@ func TestDeleteFiles(t *testing.T) {
 	}
 }
 
+func TestDeleteCommitLogFiles(t *testing.T) {
+	dir := createCommitLogFiles(t, 2, 2)
+	defer os.RemoveAll(dir)
+
+	commitLogsDir := CommitLogsDirPath(dir)
+	files, err := CommitLogFiles(commitLogsDir)
+	require.NoError(t, err)
+	require.Equal(t, 4, len(files))
+
+	// Only the first commit log file has an index
+	indexFile := CommitLogIndexFilePath(files[0])
+	fd, err := os.Create(indexFile)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	// Index files are not listed as commit log files
+	listed, err := CommitLogFiles(commitLogsDir)
+	require.NoError(t, err)
+	require.Equal(t, files, listed)
+
+	require.NoError(t, DeleteCommitLogFiles(files))
+	for _, file := range files {
+		require.False(t, FileExists(file))
+	}
+	require.False(t, FileExists(indexFile))
+}
+
 func TestByTimeAscending(t *testing.T) {
 	files := []string{"foo/fileset-1-info.db", "foo/fileset-12-info.db", "foo/fileset-2-info.db"}
 	expected := []string{"foo/fileset-1-info.db", "foo/fileset-2-info.db", "foo/fileset-12-info.db"}
@@ -206,6 +237,15 @@ func TestForEachInfoFile(t *testing.T) {
 	createDataFile(t, shardDir, blockStart, digestFileSuffix, digestOfDigest)
 	createDataFile(t, shardDir, blockStart, checkpointFileSuffix, buf)
 
+	// Only the latest complete version is read
+	latestStart := blockStart.Add(time.Nanosecond)
+	for version := 0; version < 2; version++ {
+		createDataFileVersion(t, shardDir, latestStart, version, infoFileSuffix, infoData)
+		createDataFileVersion(t, shardDir, latestStart, version, digestFileSuffix, digestOfDigest)
+		createDataFileVersion(t, shardDir, latestStart, version, checkpointFileSuffix, buf)
+	}
+	createDataFileVersion(t, shardDir, latestStart, 2, infoFileSuffix, nil)
+
 	var fnames []string
 	var res []byte
 	forEachInfoFile(dir, testNamespaceID, shard, testReaderBufferSize, func(fname string, data []byte) {
@@ -213,8 +253,11 @@ func TestForEachInfoFile(t *testing.T) {
 		res = append(res, data...)
 	})
 
-	require.Equal(t, []string{filesetPathFromTime(shardDir, blockStart, infoFileSuffix)}, fnames)
-	require.Equal(t, infoData, res)
+	require.Equal(t, []string{
+		filesetPathFromTimeAndVersion(shardDir, blockStart, 0, infoFileSuffix),
+		filesetPathFromTimeAndVersion(shardDir, latestStart, 1, infoFileSuffix),
+	}, fnames)
+	require.Equal(t, append(infoData, infoData...), res)
 }
 
 func TestTimeFromName(t *testing.T) {
@@ -259,6 +302,19 @@ func TestTimeAndIndexFromFileName(t *testing.T) {
 	require.Equal(t, exp.t, ts)
 	require.Equal(t, exp.i, i)
 	require.NoError(t, err)
+
+	ts, i, err = TimeAndIndexFromFileName("foo/bar/fileset-21234567890-3-info.db")
+	exp = expected{time.Unix(0, 21234567890), 3}
+	require.Equal(t, exp.t, ts)
+	require.Equal(t, exp.i, i)
+	require.NoError(t, err)
+
+	// Unversioned fileset files are version 0
+	ts, i, err = TimeAndIndexFromFileName("foo/bar/fileset-21234567890-info.db")
+	exp = expected{time.Unix(0, 21234567890), 0}
+	require.Equal(t, exp.t, ts)
+	require.Equal(t, exp.i, i)
+	require.NoError(t, err)
 }
 
 func TestFileExists(t *testing.T) {
@@ -271,12 +327,12 @@ func TestFileExists(t *testing.T) {
 	err := os.MkdirAll(shardDir, defaultNewDirectoryMode)
 	require.NoError(t, err)
 
-	infoFilePath := filesetPathFromTime(shardDir, start, infoFileSuffix)
+	infoFilePath := filesetPathFromTimeAndVersion(shardDir, start, 0, infoFileSuffix)
 	createDataFile(t, shardDir, start, infoFileSuffix, nil)
 	require.True(t, FileExists(infoFilePath))
 	require.False(t, FilesetExistsAt(dir, testNamespaceID, uint32(shard), start))
 
-	checkpointFilePath := filesetPathFromTime(shardDir, start, checkpointFileSuffix)
+	checkpointFilePath := filesetPathFromTimeAndVersion(shardDir, start, 0, checkpointFileSuffix)
 	createDataFile(t, shardDir, start, checkpointFileSuffix, nil)
 	require.True(t, FileExists(checkpointFilePath))
 	require.True(t, FilesetExistsAt(dir, testNamespaceID, uint32(shard), start))
@@ -308,6 +364,100 @@ func TestFilePathFromTime(t *testing.T) {
 	}
 }
 
+func TestFilePathFromTimeAndVersion(t *testing.T) {
+	start := time.Unix(1465501321, 123456789)
+	inputs := []struct {
+		prefix   string
+		version  int
+		suffix   string
+		expected string
+	}{
+		{"foo/bar", 0, infoFileSuffix, "foo/bar/fileset-1465501321123456789-info.db"},
+		{"foo/bar", 1, indexFileSuffix, "foo/bar/fileset-1465501321123456789-1-index.db"},
+		{"foo/bar", 2, dataFileSuffix, "foo/bar/fileset-1465501321123456789-2-data.db"},
+		{"foo/bar", 12, checkpointFileSuffix, "foo/bar/fileset-1465501321123456789-12-checkpoint.db"},
+		{"foo/bar/", 0, infoFileSuffix, "foo/bar/fileset-1465501321123456789-info.db"},
+		{"foo/bar/", 1, infoFileSuffix, "foo/bar/fileset-1465501321123456789-1-info.db"},
+	}
+	for _, input := range inputs {
+		require.Equal(t, input.expected,
+			filesetPathFromTimeAndVersion(input.prefix, start, input.version, input.suffix))
+	}
+}
+
+func TestLatestFilesetVersion(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	shard := uint32(0)
+	shardDir := ShardDirPath(dir, testNamespaceID, shard)
+	require.NoError(t, os.MkdirAll(shardDir, defaultNewDirectoryMode))
+
+	start := time.Unix(0, 7200)
+	_, exists := LatestFilesetVersion(dir, testNamespaceID, shard, start)
+	require.False(t, exists)
+
+	for _, version := range []int{0, 2, 10} {
+		createDataFileVersion(t, shardDir, start, version, checkpointFileSuffix, nil)
+	}
+	// Incomplete version without a checkpoint file
+	createDataFileVersion(t, shardDir, start, 11, infoFileSuffix, nil)
+	// Other block start
+	createDataFileVersion(t, shardDir, start.Add(time.Hour), 12, checkpointFileSuffix, nil)
+
+	version, exists := LatestFilesetVersion(dir, testNamespaceID, shard, start)
+	require.True(t, exists)
+	require.Equal(t, 10, version)
+}
+
+func TestLatestFilesetVersionUnversioned(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	shard := uint32(0)
+	shardDir := ShardDirPath(dir, testNamespaceID, shard)
+	require.NoError(t, os.MkdirAll(shardDir, defaultNewDirectoryMode))
+
+	// Fileset written before versioning
+	start := time.Unix(0, 7200)
+	createFile(t, path.Join(shardDir, fmt.Sprintf("fileset-%d-checkpoint.db", start.UnixNano())), nil)
+
+	version, exists := LatestFilesetVersion(dir, testNamespaceID, shard, start)
+	require.True(t, exists)
+	require.Equal(t, 0, version)
+
+	createDataFileVersion(t, shardDir, start, 1, checkpointFileSuffix, nil)
+	version, exists = LatestFilesetVersion(dir, testNamespaceID, shard, start)
+	require.True(t, exists)
+	require.Equal(t, 1, version)
+}
+
+func TestSupersededFilesets(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	shard := uint32(0)
+	shardDir := ShardDirPath(dir, testNamespaceID, shard)
+	require.NoError(t, os.MkdirAll(shardDir, defaultNewDirectoryMode))
+
+	first, second := time.Unix(0, 7200), time.Unix(0, 14400)
+	for version := 0; version < 2; version++ {
+		createDataFileVersion(t, shardDir, first, version, infoFileSuffix, nil)
+		createDataFileVersion(t, shardDir, first, version, checkpointFileSuffix, nil)
+	}
+	createDataFileVersion(t, shardDir, first, 2, infoFileSuffix, nil)
+	createDataFileVersion(t, shardDir, second, 0, infoFileSuffix, nil)
+	createDataFileVersion(t, shardDir, second, 0, checkpointFileSuffix, nil)
+
+	superseded, err := SupersededFilesets(dir, testNamespaceID, shard)
+	require.NoError(t, err)
+	sort.Strings(superseded)
+	require.Equal(t, []string{
+		filesetPathFromTimeAndVersion(shardDir, first, 0, checkpointFileSuffix),
+		filesetPathFromTimeAndVersion(shardDir, first, 0, infoFileSuffix),
+	}, superseded)
+}
+
 func TestFilesetFilesBefore(t *testing.T) {
 	shard := uint32(0)
 	dir := createInfoFiles(t, testNamespaceID, shard, 20)
@@ -322,7 +472,7 @@ func TestFilesetFilesBefore(t *testing.T) {
 	shardDir := path.Join(dir, dataDirName, testNamespaceID.String(), strconv.Itoa(int(shard)))
 	for i := 0; i < len(res); i++ {
 		ts := time.Unix(0, int64(i))
-		require.Equal(t, filesetPathFromTime(shardDir, ts, infoFileSuffix), res[i])
+		require.Equal(t, filesetPathFromTimeAndVersion(shardDir, ts, 0, infoFileSuffix), res[i])
 	}
 }
 
@@ -25,23 +25,40 @@ import (
 )
 
 const (
-	infoFileSuffix       = "info"
-	indexFileSuffix      = "index"
-	dataFileSuffix       = "data"
-	digestFileSuffix     = "digest"
-	checkpointFileSuffix = "checkpoint"
-	filesetFilePrefix    = "fileset"
-	commitLogFilePrefix  = "commitlog"
-	fileSuffix           = ".db"
-
-	separator                    = "-"
-	infoFilePattern              = filesetFilePrefix + separator + "[0-9]*" + separator + infoFileSuffix + fileSuffix
-	filesetFilePattern           = filesetFilePrefix + separator + "[0-9]*" + separator + "[a-z]*" + fileSuffix
-	commitLogFilePattern         = commitLogFilePrefix + separator + "[0-9]*" + separator + "[0-9]*" + fileSuffix
-	commitLogFileForTimeTemplate = commitLogFilePrefix + separator + "%d" + separator + "[0-9]*" + fileSuffix
+	infoFileSuffix        = "info"
+	indexFileSuffix       = "index"
+	summariesFileSuffix   = "summaries"
+	bloomFilterFileSuffix = "bloomfilter"
+	dataFileSuffix        = "data"
+	digestFileSuffix      = "digest"
+	checkpointFileSuffix  = "checkpoint"
+	filesetFilePrefix     = "fileset"
+	commitLogFilePrefix   = "commitlog"
+	commitLogIndexPrefix  = "commitlogindex"
+	snapshotFilePrefix    = "snapshot"
+	fileSuffix            = ".db"
+	tmpFileSuffix         = ".tmp"
+
+	separator                      = "-"
+	infoFilePattern                = filesetFilePrefix + separator + "[0-9]*" + separator + infoFileSuffix + fileSuffix
+	checkpointFilePattern          = filesetFilePrefix + separator + "[0-9]*" + separator + checkpointFileSuffix + fileSuffix
+	checkpointFileForTimeTemplate  = filesetFilePrefix + separator + "%d" + separator + "[0-9]*" + separator + checkpointFileSuffix + fileSuffix
+	filesetFilePattern             = filesetFilePrefix + separator + "[0-9]*" + separator + "[a-z]*" + fileSuffix
+	filesetFileForTimeTemplate     = filesetFilePrefix + separator + "%d" + separator + "[0-9]*" + separator + "[a-z]*" + fileSuffix
+	unversionedFileForTimeTemplate = filesetFilePrefix + separator + "%d" + separator + "[a-z]*" + fileSuffix
+	commitLogFilePattern           = commitLogFilePrefix + separator + "[0-9]*" + separator + "[0-9]*" + fileSuffix
+	commitLogFileForTimeTemplate   = commitLogFilePrefix + separator + "%d" + separator + "[0-9]*" + fileSuffix
+	snapshotFilePattern            = snapshotFilePrefix + separator + "[0-9]*" + fileSuffix
+	indexCheckpointFilePattern     = filesetFilePrefix + separator + "[0-9]*" + separator + checkpointFileSuffix + fileSuffix
 
 	// Index ID is int64
 	idxLen = 8
+
+	// Every Nth entry of the sorted index is written to the summaries file
+	indexSummariesInterval = 64
+
+	// False positive rate of the bloom filter written with each fileset
+	bloomFilterFalsePositiveRate = 0.02
 )
 
 var (
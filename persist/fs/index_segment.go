@@ -0,0 +1,346 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/persist/encoding"
+	"github.com/m3db/m3db/persist/encoding/msgpack"
+	"github.com/m3db/m3db/persist/schema"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/time"
+)
+
+const (
+	indexDirName           = "index"
+	indexSegmentFileSuffix = "segment"
+)
+
+var (
+	errIndexSegmentCheckpointNotFound = errors.New("index segment checkpoint file does not exist")
+)
+
+type indexSegmentWriter struct {
+	blockSize        time.Duration
+	filePathPrefix   string
+	newFileMode      os.FileMode
+	newDirectoryMode os.FileMode
+
+	infoFdWithDigest           digest.FdWithDigestWriter
+	segmentFdWithDigest        digest.FdWithDigestWriter
+	digestFdWithDigestContents digest.FdWithDigestContentsWriter
+	namespaceDir               string
+
+	start     time.Time
+	entries   int64
+	encoder   encoding.Encoder
+	digestBuf digest.Buffer
+	tags      []schema.IndexTag
+	err       error
+}
+
+// NewIndexSegmentWriter returns a new index segment writer for a filePathPrefix
+func NewIndexSegmentWriter(
+	blockSize time.Duration,
+	filePathPrefix string,
+	bufferSize int,
+	newFileMode os.FileMode,
+	newDirectoryMode os.FileMode,
+) IndexSegmentWriter {
+	return &indexSegmentWriter{
+		blockSize:                  blockSize,
+		filePathPrefix:             filePathPrefix,
+		newFileMode:                newFileMode,
+		newDirectoryMode:           newDirectoryMode,
+		infoFdWithDigest:           digest.NewFdWithDigestWriter(bufferSize),
+		segmentFdWithDigest:        digest.NewFdWithDigestWriter(bufferSize),
+		digestFdWithDigestContents: digest.NewFdWithDigestContentsWriter(bufferSize),
+		encoder:                    msgpack.NewEncoder(),
+		digestBuf:                  digest.NewBuffer(),
+	}
+}
+
+// Open creates the namespace index directory if it doesn't exist and
+// opens the index segment files for the block start for writing. Any
+// existing checkpoint file is removed first so a partially written
+// segment is never considered complete.
+func (w *indexSegmentWriter) Open(namespace ts.ID, blockStart time.Time) error {
+	namespaceDir := IndexNamespaceDirPath(w.filePathPrefix, namespace)
+	if err := os.MkdirAll(namespaceDir, w.newDirectoryMode); err != nil {
+		return err
+	}
+	w.start = blockStart
+	w.entries = 0
+	w.namespaceDir = namespaceDir
+	w.err = nil
+
+	checkpointFilePath := filesetPathFromTime(namespaceDir, blockStart, checkpointFileSuffix)
+	if FileExists(checkpointFilePath) {
+		if err := os.Remove(checkpointFilePath); err != nil {
+			return err
+		}
+	}
+
+	var infoFd, segmentFd, digestFd *os.File
+	if err := openFiles(
+		w.openWritable,
+		map[string]**os.File{
+			filesetPathFromTime(namespaceDir, blockStart, infoFileSuffix):         &infoFd,
+			filesetPathFromTime(namespaceDir, blockStart, indexSegmentFileSuffix): &segmentFd,
+			filesetPathFromTime(namespaceDir, blockStart, digestFileSuffix):       &digestFd,
+		},
+	); err != nil {
+		return err
+	}
+
+	w.infoFdWithDigest.Reset(infoFd)
+	w.segmentFdWithDigest.Reset(segmentFd)
+	w.digestFdWithDigestContents.Reset(digestFd)
+
+	return nil
+}
+
+func (w *indexSegmentWriter) Write(id ts.ID, tags ts.Tags) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	w.tags = w.tags[:0]
+	for _, tag := range tags {
+		w.tags = append(w.tags, schema.IndexTag{
+			Name:  tag.Name.Data().Get(),
+			Value: tag.Value.Data().Get(),
+		})
+	}
+	series := schema.IndexSeries{
+		ID:   id.Data().Get(),
+		Tags: w.tags,
+	}
+
+	w.encoder.Reset()
+	if err := w.encoder.EncodeIndexSeries(series); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.segmentFdWithDigest.WriteBytes(w.encoder.Bytes()); err != nil {
+		w.err = err
+		return err
+	}
+	w.entries++
+	return nil
+}
+
+func (w *indexSegmentWriter) close() error {
+	info := schema.IndexInfo{
+		Start:     xtime.ToNanoseconds(w.start),
+		BlockSize: int64(w.blockSize),
+		Entries:   w.entries,
+	}
+
+	w.encoder.Reset()
+	if err := w.encoder.EncodeIndexInfo(info); err != nil {
+		return err
+	}
+
+	if _, err := w.infoFdWithDigest.WriteBytes(w.encoder.Bytes()); err != nil {
+		return err
+	}
+
+	if err := w.digestFdWithDigestContents.WriteDigests(
+		w.infoFdWithDigest.Digest().Sum32(),
+		w.segmentFdWithDigest.Digest().Sum32(),
+	); err != nil {
+		return err
+	}
+
+	return closeAll(
+		w.infoFdWithDigest,
+		w.segmentFdWithDigest,
+		w.digestFdWithDigestContents,
+	)
+}
+
+func (w *indexSegmentWriter) Close() error {
+	err := w.close()
+	if w.err != nil {
+		return w.err
+	}
+	if err != nil {
+		w.err = err
+		return err
+	}
+	// Only write out the checkpoint file if there are no errors
+	// encountered between calling Open() and Close().
+	if err := w.writeCheckpointFile(); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+func (w *indexSegmentWriter) writeCheckpointFile() error {
+	filePath := filesetPathFromTime(w.namespaceDir, w.start, checkpointFileSuffix)
+	fd, err := w.openWritable(filePath)
+	if err != nil {
+		return err
+	}
+	err = w.digestBuf.WriteDigestToFile(fd, w.digestFdWithDigestContents.Digest().Sum32())
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *indexSegmentWriter) openWritable(filePath string) (*os.File, error) {
+	return OpenWritable(filePath, w.newFileMode)
+}
+
+// ReadIndexSegment reads and validates the index segment for a namespace
+// and block start, returning the indexed series and their tags.
+func ReadIndexSegment(
+	filePathPrefix string,
+	namespace ts.ID,
+	blockStart time.Time,
+	readerBufferSize int,
+	decodingOpts msgpack.DecodingOptions,
+) ([]schema.IndexSeries, error) {
+	namespaceDir := IndexNamespaceDirPath(filePathPrefix, namespace)
+	checkpointFilePath := filesetPathFromTime(namespaceDir, blockStart, checkpointFileSuffix)
+	if !FileExists(checkpointFilePath) {
+		return nil, errIndexSegmentCheckpointNotFound
+	}
+	checkpointFd, err := os.Open(checkpointFilePath)
+	if err != nil {
+		return nil, err
+	}
+	expectedDigestOfDigest, err := digest.NewBuffer().ReadDigestFromFile(checkpointFd)
+	checkpointFd.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	expectedInfoDigest, expectedSegmentDigest, err := readIndexSegmentDigests(
+		namespaceDir, blockStart, readerBufferSize, expectedDigestOfDigest)
+	if err != nil {
+		return nil, err
+	}
+
+	infoData, err := readAndValidate(
+		filesetPathFromTime(namespaceDir, blockStart, infoFileSuffix), readerBufferSize, expectedInfoDigest)
+	if err != nil {
+		return nil, err
+	}
+	decoder := msgpack.NewDecoder(decodingOpts)
+	decoder.Reset(infoData)
+	info, err := decoder.DecodeIndexInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	segmentData, err := readAndValidate(
+		filesetPathFromTime(namespaceDir, blockStart, indexSegmentFileSuffix), readerBufferSize, expectedSegmentDigest)
+	if err != nil {
+		return nil, err
+	}
+	decoder.Reset(segmentData)
+	series := make([]schema.IndexSeries, 0, info.Entries)
+	for i := int64(0); i < info.Entries; i++ {
+		entry, err := decoder.DecodeIndexSeries()
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, entry)
+	}
+	return series, nil
+}
+
+func readIndexSegmentDigests(
+	namespaceDir string,
+	blockStart time.Time,
+	readerBufferSize int,
+	expectedDigestOfDigest uint32,
+) (uint32, uint32, error) {
+	fd, err := os.Open(filesetPathFromTime(namespaceDir, blockStart, digestFileSuffix))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer fd.Close()
+
+	reader := digest.NewFdWithDigestContentsReader(readerBufferSize)
+	reader.Reset(fd)
+	infoDigest, err := reader.ReadDigest()
+	if err != nil {
+		return 0, 0, err
+	}
+	segmentDigest, err := reader.ReadDigest()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := reader.Validate(expectedDigestOfDigest); err != nil {
+		return 0, 0, err
+	}
+	return infoDigest, segmentDigest, nil
+}
+
+// IndexSegmentBlockStarts returns the block starts of all the complete
+// index segments for a namespace in ascending order.
+func IndexSegmentBlockStarts(filePathPrefix string, namespace ts.ID) ([]time.Time, error) {
+	namespaceDir := IndexNamespaceDirPath(filePathPrefix, namespace)
+	matched, err := findFiles(namespaceDir, indexCheckpointFilePattern, func(files []string) sort.Interface {
+		return byTimeAscending(files)
+	})
+	if err != nil {
+		return nil, err
+	}
+	blockStarts := make([]time.Time, 0, len(matched))
+	for _, file := range matched {
+		t, err := TimeFromFileName(file)
+		if err != nil {
+			continue
+		}
+		blockStarts = append(blockStarts, t)
+	}
+	return blockStarts, nil
+}
+
+// IndexSegmentFilesBefore returns all the index segment files of a namespace
+// whose timestamps are earlier than a given time.
+func IndexSegmentFilesBefore(filePathPrefix string, namespace ts.ID, t time.Time) ([]string, error) {
+	namespaceDir := IndexNamespaceDirPath(filePathPrefix, namespace)
+	matched, err := findFiles(namespaceDir, filesetFilePattern, func(files []string) sort.Interface {
+		return byTimeAscending(files)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return filesBefore(matched, t)
+}
+
+// IndexNamespaceDirPath returns the path to the index of a given namespace.
+func IndexNamespaceDirPath(prefix string, namespace ts.ID) string {
+	return path.Join(prefix, indexDirName, namespace.String())
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/ts"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndexSegmentWriter(filePathPrefix string) IndexSegmentWriter {
+	return NewIndexSegmentWriter(testBlockSize, filePathPrefix, testWriterBufferSize,
+		defaultNewFileMode, defaultNewDirectoryMode)
+}
+
+func TestIndexSegmentWriteRead(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := dir
+	defer os.RemoveAll(dir)
+
+	blockStart := time.Unix(0, 0).Add(testBlockSize)
+	w := newTestIndexSegmentWriter(filePathPrefix)
+	require.NoError(t, w.Open(testNamespaceID, blockStart))
+	require.NoError(t, w.Write(ts.StringID("foo"), ts.Tags{
+		{Name: ts.StringID("city"), Value: ts.StringID("nyc")},
+		{Name: ts.StringID("host"), Value: ts.StringID("a")},
+	}))
+	require.NoError(t, w.Write(ts.StringID("bar"), nil))
+	require.NoError(t, w.Close())
+
+	series, err := ReadIndexSegment(filePathPrefix, testNamespaceID, blockStart,
+		testReaderBufferSize, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(series))
+	require.Equal(t, "foo", string(series[0].ID))
+	require.Equal(t, 2, len(series[0].Tags))
+	require.Equal(t, "city", string(series[0].Tags[0].Name))
+	require.Equal(t, "nyc", string(series[0].Tags[0].Value))
+	require.Equal(t, "bar", string(series[1].ID))
+	require.Equal(t, 0, len(series[1].Tags))
+
+	blockStarts, err := IndexSegmentBlockStarts(filePathPrefix, testNamespaceID)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{blockStart}, blockStarts)
+
+	files, err := IndexSegmentFilesBefore(filePathPrefix, testNamespaceID, blockStart)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(files))
+
+	files, err = IndexSegmentFilesBefore(filePathPrefix, testNamespaceID, blockStart.Add(testBlockSize))
+	require.NoError(t, err)
+	require.Equal(t, 4, len(files))
+}
+
+func TestIndexSegmentReadMissingCheckpoint(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := dir
+	defer os.RemoveAll(dir)
+
+	blockStart := time.Unix(0, 0).Add(testBlockSize)
+	w := newTestIndexSegmentWriter(filePathPrefix)
+	require.NoError(t, w.Open(testNamespaceID, blockStart))
+	require.NoError(t, w.Write(ts.StringID("foo"), nil))
+
+	// Not closed so checkpoint file is never written
+	_, err := ReadIndexSegment(filePathPrefix, testNamespaceID, blockStart,
+		testReaderBufferSize, nil)
+	require.Equal(t, errIndexSegmentCheckpointNotFound, err)
+}
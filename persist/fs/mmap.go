@@ -0,0 +1,86 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/m3db/m3x/checked"
+)
+
+// mmapRegion is a read only memory mapping of a file, it is reference
+// counted and only unmapped once the seeker that mapped it and every
+// segment handed out from it have released their reference.
+type mmapRegion struct {
+	refs      int32
+	bytes     []byte
+	bytesOpts checked.BytesOptions
+}
+
+// newMmapRegion maps the whole file, the file descriptor can be closed
+// once mapped as the mapping remains valid until unmapped.
+func newMmapRegion(fd *os.File) (*mmapRegion, error) {
+	stat, err := fd.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var bytes []byte
+	if size := stat.Size(); size > 0 {
+		bytes, err = syscall.Mmap(int(fd.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r := &mmapRegion{refs: 1, bytes: bytes}
+	r.bytesOpts = checked.NewBytesOptions().SetFinalizer(r)
+	return r, nil
+}
+
+func (r *mmapRegion) Bytes() []byte {
+	return r.bytes
+}
+
+func (r *mmapRegion) IncRef() {
+	atomic.AddInt32(&r.refs, 1)
+}
+
+func (r *mmapRegion) DecRef() error {
+	if atomic.AddInt32(&r.refs, -1) != 0 || r.bytes == nil {
+		return nil
+	}
+	bytes := r.bytes
+	r.bytes = nil
+	return syscall.Munmap(bytes)
+}
+
+// Slice returns bytes referencing the mapping without copying, the
+// mapping is kept alive until the returned bytes are finalized.
+func (r *mmapRegion) Slice(start, end int) checked.Bytes {
+	r.IncRef()
+	return checked.NewBytes(r.bytes[start:end], r.bytesOpts)
+}
+
+// FinalizeBytes releases the reference held by bytes returned from Slice.
+func (r *mmapRegion) FinalizeBytes(b checked.Bytes) {
+	r.DecRef()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMmapRegionUnmappedOnceReleased(t *testing.T) {
+	fd, err := ioutil.TempFile("", "mmap")
+	require.NoError(t, err)
+	defer os.Remove(fd.Name())
+	_, err = fd.Write([]byte{1, 2, 3, 4, 5})
+	require.NoError(t, err)
+
+	region, err := newMmapRegion(fd)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	require.Equal(t, []byte{1, 2, 3, 4, 5}, region.Bytes())
+
+	data := region.Slice(1, 4)
+	data.IncRef()
+
+	// Releasing the owner reference keeps the region mapped for the slice
+	require.NoError(t, region.DecRef())
+	require.Equal(t, []byte{2, 3, 4}, data.Get())
+	require.NotNil(t, region.Bytes())
+
+	data.DecRef()
+	data.Finalize()
+	require.Nil(t, region.Bytes())
+}
+
+func TestMmapRegionEmptyFile(t *testing.T) {
+	fd, err := ioutil.TempFile("", "mmap")
+	require.NoError(t, err)
+	defer os.Remove(fd.Name())
+
+	region, err := newMmapRegion(fd)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	require.Equal(t, 0, len(region.Bytes()))
+	require.NoError(t, region.DecRef())
+}
@@ -62,6 +62,9 @@ type options struct {
 	newDirectoryMode os.FileMode
 	writerBufferSize int
 	readerBufferSize int
+	mmapEnabled      bool
+	compression      CompressionType
+	tieringPolicy    TieringPolicy
 }
 
 // NewOptions creates a new set of fs options
@@ -179,3 +182,33 @@ func (o *options) SetReaderBufferSize(value int) Options {
 func (o *options) ReaderBufferSize() int {
 	return o.readerBufferSize
 }
+
+func (o *options) SetMmapEnabled(value bool) Options {
+	opts := *o
+	opts.mmapEnabled = value
+	return &opts
+}
+
+func (o *options) MmapEnabled() bool {
+	return o.mmapEnabled
+}
+
+func (o *options) SetCompression(value CompressionType) Options {
+	opts := *o
+	opts.compression = value
+	return &opts
+}
+
+func (o *options) Compression() CompressionType {
+	return o.compression
+}
+
+func (o *options) SetTieringPolicy(value TieringPolicy) Options {
+	opts := *o
+	opts.tieringPolicy = value
+	return &opts
+}
+
+func (o *options) TieringPolicy() TieringPolicy {
+	return o.tieringPolicy
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/persist"
 	"github.com/m3db/m3db/ratelimit"
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/checked"
@@ -60,11 +61,16 @@ type sleepFn func(time.Duration)
 type persistManager struct {
 	sync.RWMutex
 
-	opts           Options
-	filePathPrefix string
-	nowFn          clock.NowFn
-	sleepFn        sleepFn
-	writer         FileSetWriter
+	opts             Options
+	filePathPrefix   string
+	filePathPrefixes []string
+	nowFn            clock.NowFn
+	sleepFn          sleepFn
+	newWriterFn      newWriterFn
+	// writers holds a writer for each block size of the namespaces flushed
+	// since the block size is written into the info file of each fileset
+	writers map[time.Duration]FileSetWriter
+	writer  FileSetWriter
 	// segmentHolder is a two-item slice that's reused to hold pointers to the
 	// head and the tail of each segment so we don't need to allocate memory
 	// and gc it shortly after.
@@ -94,24 +100,30 @@ func newPersistManagerMetrics(scope tally.Scope) persistManagerMetrics {
 	}
 }
 
+type newWriterFn func(blockSize time.Duration) FileSetWriter
+
 // NewPersistManager creates a new filesystem persist manager
 func NewPersistManager(opts Options) persist.Manager {
 	filePathPrefix := opts.FilePathPrefix()
 	writerBufferSize := opts.WriterBufferSize()
-	blockSize := opts.RetentionOptions().BlockSize()
 	newFileMode := opts.NewFileMode()
 	newDirectoryMode := opts.NewDirectoryMode()
-	writer := NewWriter(blockSize, filePathPrefix, writerBufferSize, newFileMode, newDirectoryMode)
+	newWriterFn := func(blockSize time.Duration) FileSetWriter {
+		return NewWriter(blockSize, filePathPrefix, writerBufferSize, newFileMode, newDirectoryMode,
+			opts.Compression())
+	}
 	scope := opts.InstrumentOptions().MetricsScope().SubScope("persist")
 	pm := &persistManager{
-		opts:           opts,
-		filePathPrefix: filePathPrefix,
-		nowFn:          opts.ClockOptions().NowFn(),
-		sleepFn:        time.Sleep,
-		writer:         writer,
-		segmentHolder:  make([]checked.Bytes, 2),
-		status:         persistManagerIdle,
-		metrics:        newPersistManagerMetrics(scope),
+		opts:             opts,
+		filePathPrefix:   filePathPrefix,
+		filePathPrefixes: TierFilePathPrefixes(filePathPrefix, opts.TieringPolicy()),
+		nowFn:            opts.ClockOptions().NowFn(),
+		sleepFn:          time.Sleep,
+		newWriterFn:      newWriterFn,
+		writers:          make(map[time.Duration]FileSetWriter),
+		segmentHolder:    make([]checked.Bytes, 2),
+		status:           persistManagerIdle,
+		metrics:          newPersistManagerMetrics(scope),
 	}
 	opts.RuntimeOptionsManager().RegisterListener(pm)
 	return pm
@@ -205,7 +217,31 @@ func (pm *persistManager) reset() {
 	pm.slept = 0
 }
 
-func (pm *persistManager) Prepare(namespace ts.ID, shard uint32, blockStart time.Time) (persist.PreparedPersist, error) {
+func (pm *persistManager) Prepare(
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	ropts retention.Options,
+) (persist.PreparedPersist, error) {
+	return pm.prepare(namespace, shard, blockStart, ropts, false)
+}
+
+func (pm *persistManager) PrepareRewrite(
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	ropts retention.Options,
+) (persist.PreparedPersist, error) {
+	return pm.prepare(namespace, shard, blockStart, ropts, true)
+}
+
+func (pm *persistManager) prepare(
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	ropts retention.Options,
+	rewrite bool,
+) (persist.PreparedPersist, error) {
 	var prepared persist.PreparedPersist
 
 	pm.RLock()
@@ -218,12 +254,20 @@ func (pm *persistManager) Prepare(namespace ts.ID, shard uint32, blockStart time
 	// NB(xichen): if the checkpoint file for blockStart already exists, bail.
 	// This allows us to retry failed flushing attempts because they wouldn't
 	// have created the checkpoint file.
-	if FilesetExistsAt(pm.filePathPrefix, namespace, shard, blockStart) {
+	if !rewrite && FilesetExistsAtAnyTier(pm.filePathPrefixes, namespace, shard, blockStart) {
 		return prepared, nil
 	}
-	if err := pm.writer.Open(namespace, shard, blockStart); err != nil {
+	// The writer is only used by the flush that prepared it
+	blockSize := ropts.BlockSize()
+	writer, ok := pm.writers[blockSize]
+	if !ok {
+		writer = pm.newWriterFn(blockSize)
+		pm.writers[blockSize] = writer
+	}
+	if err := writer.Open(namespace, shard, blockStart); err != nil {
 		return prepared, err
 	}
+	pm.writer = writer
 
 	prepared.Persist = pm.persist
 	prepared.Close = pm.close
@@ -60,6 +60,9 @@ func testManager(
 
 	manager := NewPersistManager(opts).(*persistManager)
 	manager.writer = writer
+	manager.newWriterFn = func(time.Duration) FileSetWriter {
+		return writer
+	}
 
 	return manager, writer, opts
 }
@@ -68,13 +71,13 @@ func TestPersistenceManagerPrepareFileExists(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	pm, _, _ := testManager(t, ctrl)
+	pm, _, opts := testManager(t, ctrl)
 	defer os.RemoveAll(pm.filePathPrefix)
 
 	shard := uint32(0)
 	blockStart := time.Unix(1000, 0)
 	shardDir := createShardDir(t, pm.filePathPrefix, testNamespaceID, shard)
-	checkpointFilePath := filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
+	checkpointFilePath := filesetPathFromTimeAndVersion(shardDir, blockStart, 0, checkpointFileSuffix)
 	f, err := os.Create(checkpointFilePath)
 	require.NoError(t, err)
 	f.Close()
@@ -86,17 +89,49 @@ func TestPersistenceManagerPrepareFileExists(t *testing.T) {
 		assert.NoError(t, flush.Done())
 	}()
 
-	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart)
+	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart, opts.RetentionOptions())
 	require.NoError(t, err)
 	require.Nil(t, prepared.Persist)
 	require.Nil(t, prepared.Close)
 }
 
+func TestPersistenceManagerPrepareRewriteFileExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, writer, opts := testManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	shard := uint32(0)
+	blockStart := time.Unix(1000, 0)
+	shardDir := createShardDir(t, pm.filePathPrefix, testNamespaceID, shard)
+	checkpointFilePath := filesetPathFromTimeAndVersion(shardDir, blockStart, 0, checkpointFileSuffix)
+	f, err := os.Create(checkpointFilePath)
+	require.NoError(t, err)
+	f.Close()
+
+	writer.EXPECT().Open(testNamespaceID, shard, blockStart).Return(nil)
+	writer.EXPECT().Close()
+
+	flush, err := pm.StartFlush()
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, flush.Done())
+	}()
+
+	prepared, err := flush.PrepareRewrite(testNamespaceID, shard, blockStart, opts.RetentionOptions())
+	require.NoError(t, err)
+	require.NotNil(t, prepared.Persist)
+	require.NotNil(t, prepared.Close)
+	require.NoError(t, prepared.Close())
+}
+
 func TestPersistenceManagerPrepareOpenError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	pm, writer, _ := testManager(t, ctrl)
+	pm, writer, opts := testManager(t, ctrl)
 	defer os.RemoveAll(pm.filePathPrefix)
 
 	shard := uint32(0)
@@ -111,7 +146,7 @@ func TestPersistenceManagerPrepareOpenError(t *testing.T) {
 		assert.NoError(t, flush.Done())
 	}()
 
-	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart)
+	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart, opts.RetentionOptions())
 	require.Equal(t, expectedErr, err)
 	require.Nil(t, prepared.Persist)
 	require.Nil(t, prepared.Close)
@@ -121,7 +156,7 @@ func TestPersistenceManagerPrepareSuccess(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	pm, writer, _ := testManager(t, ctrl)
+	pm, writer, opts := testManager(t, ctrl)
 	defer os.RemoveAll(pm.filePathPrefix)
 
 	shard := uint32(0)
@@ -150,7 +185,7 @@ func TestPersistenceManagerPrepareSuccess(t *testing.T) {
 	pm.count = 123
 	pm.bytesWritten = 100
 
-	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart)
+	prepared, err := flush.Prepare(testNamespaceID, shard, blockStart, opts.RetentionOptions())
 	defer prepared.Close()
 
 	require.Nil(t, err)
@@ -162,6 +197,39 @@ func TestPersistenceManagerPrepareSuccess(t *testing.T) {
 	require.Equal(t, int64(104), pm.bytesWritten)
 }
 
+func TestPersistenceManagerPrepareUsesNamespaceBlockSize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pm, _, opts := testManager(t, ctrl)
+	defer os.RemoveAll(pm.filePathPrefix)
+
+	var blockSizes []time.Duration
+	pm.newWriterFn = func(blockSize time.Duration) FileSetWriter {
+		blockSizes = append(blockSizes, blockSize)
+		writer := NewMockFileSetWriter(ctrl)
+		writer.EXPECT().Open(testNamespaceID, uint32(0), gomock.Any()).Return(nil).AnyTimes()
+		return writer
+	}
+
+	flush, err := pm.StartFlush()
+	require.NoError(t, err)
+
+	defer func() {
+		assert.NoError(t, flush.Done())
+	}()
+
+	// Each block size is written with its own writer which is reused
+	ropts := opts.RetentionOptions()
+	longRopts := ropts.SetBlockSize(24 * time.Hour)
+	for _, r := range []retention.Options{ropts, longRopts, ropts} {
+		_, err := flush.Prepare(testNamespaceID, 0, time.Unix(0, 0), r)
+		require.NoError(t, err)
+		require.Equal(t, pm.writers[r.BlockSize()], pm.writer)
+	}
+	require.Equal(t, []time.Duration{2 * time.Hour, 24 * time.Hour}, blockSizes)
+}
+
 func TestPersistenceManagerClose(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
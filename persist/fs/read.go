@@ -24,16 +24,19 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"time"
 
+	"github.com/m3db/m3db/digest"
 	"github.com/m3db/m3db/persist/encoding"
 	"github.com/m3db/m3db/persist/encoding/msgpack"
+	"github.com/m3db/m3db/persist/schema"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/pool"
 	"github.com/m3db/m3x/time"
-	"github.com/m3db/m3db/digest"
 )
 
 type fileReader func(fd *os.File, buf []byte) (int, error)
@@ -47,6 +50,9 @@ var (
 
 	// errReadMarkerNotFound returned when the marker is not found at the beginning of a data record
 	errReadMarkerNotFound = errors.New("expected marker not found")
+
+	// errReadCompressedChecksumMismatch returned when compressed data does not match its checksum
+	errReadCompressedChecksumMismatch = errors.New("compressed data checksum mismatch")
 )
 
 // ErrReadWrongIdx returned when the wrong idx is read in the data file
@@ -60,9 +66,9 @@ func (e ErrReadWrongIdx) Error() string {
 }
 
 type reader struct {
-	filePathPrefix string
-	start          time.Time
-	blockSize      time.Duration
+	filePathPrefixes []string
+	start            time.Time
+	blockSize        time.Duration
 
 	infoFdWithDigest           digest.FdWithDigestReader
 	indexFdWithDigest          digest.FdWithDigestReader
@@ -71,17 +77,37 @@ type reader struct {
 	expectedInfoDigest         uint32
 	expectedIndexDigest        uint32
 	expectedDataDigest         uint32
+	expectedSummariesDigest    uint32
+	expectedBloomFilterDigest  uint32
 	expectedDigestOfDigest     uint32
 
-	unreadBuf   []byte
-	entries     int
-	entriesRead int
-	prologue    []byte
-	decoder     encoding.Decoder
-	digestBuf   digest.Buffer
-	bytesPool   pool.CheckedBytesPool
+	unreadBuf      []byte
+	entries        int
+	entriesRead    int
+	indexEntries   []schema.IndexEntry
+	indexSummaries bool
+	prologue       []byte
+	compression    CompressionType
+	compressed     []byte
+	decoder        encoding.Decoder
+	digestBuf      digest.Buffer
+	bytesPool      pool.CheckedBytesPool
+	decompressor   *decompressor
 }
 
+// hasIndexSummaries returns whether a fileset has index summaries and a
+// bloom filter, filesets written before they were added have none and
+// their index info is decoded with the bloom filter parameters left empty.
+func hasIndexSummaries(info schema.IndexInfo) bool {
+	return info.BloomFilterM > 0
+}
+
+type indexEntriesByOffsetAsc []schema.IndexEntry
+
+func (e indexEntriesByOffsetAsc) Len() int           { return len(e) }
+func (e indexEntriesByOffsetAsc) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e indexEntriesByOffsetAsc) Less(i, j int) bool { return e[i].Offset < e[j].Offset }
+
 // NewReader returns a new reader for a filePathPrefix, expects all files to exist.  Will
 // read the index info.
 func NewReader(
@@ -89,9 +115,20 @@ func NewReader(
 	bufferSize int,
 	bytesPool pool.CheckedBytesPool,
 	decodingOpts msgpack.DecodingOptions,
+) FileSetReader {
+	return NewTieredReader([]string{filePathPrefix}, bufferSize, bytesPool, decodingOpts)
+}
+
+// NewTieredReader returns a new reader that reads each fileset from the first
+// of the file path prefixes of the tiers with a complete fileset.
+func NewTieredReader(
+	filePathPrefixes []string,
+	bufferSize int,
+	bytesPool pool.CheckedBytesPool,
+	decodingOpts msgpack.DecodingOptions,
 ) FileSetReader {
 	return &reader{
-		filePathPrefix:             filePathPrefix,
+		filePathPrefixes:           filePathPrefixes,
 		infoFdWithDigest:           digest.NewFdWithDigestReader(bufferSize),
 		indexFdWithDigest:          digest.NewFdWithDigestReader(bufferSize),
 		dataFdWithDigest:           digest.NewFdWithDigestReader(bufferSize),
@@ -100,21 +137,32 @@ func NewReader(
 		decoder:                    msgpack.NewDecoder(decodingOpts),
 		digestBuf:                  digest.NewBuffer(),
 		bytesPool:                  bytesPool,
+		decompressor:               newDecompressor(),
 	}
 }
 
 func (r *reader) Open(namespace ts.ID, shard uint32, blockStart time.Time) error {
 	// If there is no checkpoint file, don't read the data files.
-	shardDir := ShardDirPath(r.filePathPrefix, namespace, shard)
-	if err := r.readCheckpointFile(shardDir, blockStart); err != nil {
+	filePathPrefix, exists := FilesetTierFilePathPrefix(r.filePathPrefixes, namespace, shard, blockStart)
+	if !exists {
+		return errCheckpointFileNotFound
+	}
+	version, exists := LatestFilesetVersion(filePathPrefix, namespace, shard, blockStart)
+	if !exists {
+		return errCheckpointFileNotFound
+	}
+	shardDir := ShardDirPath(filePathPrefix, namespace, shard)
+	if err := r.readCheckpointFile(
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, checkpointFileSuffix),
+	); err != nil {
 		return err
 	}
 	var infoFd, indexFd, dataFd, digestFd *os.File
 	if err := openFiles(os.Open, map[string]**os.File{
-		filesetPathFromTime(shardDir, blockStart, infoFileSuffix):   &infoFd,
-		filesetPathFromTime(shardDir, blockStart, indexFileSuffix):  &indexFd,
-		filesetPathFromTime(shardDir, blockStart, dataFileSuffix):   &dataFd,
-		filesetPathFromTime(shardDir, blockStart, digestFileSuffix): &digestFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, infoFileSuffix):   &infoFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, indexFileSuffix):  &indexFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, dataFileSuffix):   &dataFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, digestFileSuffix): &digestFd,
 	}); err != nil {
 		return err
 	}
@@ -150,6 +198,10 @@ func (r *reader) Open(namespace ts.ID, shard uint32, blockStart time.Time) error
 		r.Close()
 		return err
 	}
+	if err := r.readIndexSummariesDigests(); err != nil {
+		r.Close()
+		return err
+	}
 	if err := r.readIndex(int(indexStat.Size())); err != nil {
 		r.Close()
 		return err
@@ -163,11 +215,7 @@ func (r *reader) prepareUnreadBuf(size int) {
 	}
 }
 
-func (r *reader) readCheckpointFile(shardDir string, blockStart time.Time) error {
-	filePath := filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
-	if !FileExists(filePath) {
-		return errCheckpointFileNotFound
-	}
+func (r *reader) readCheckpointFile(filePath string) error {
 	fd, err := os.Open(filePath)
 	if err != nil {
 		return err
@@ -192,6 +240,21 @@ func (r *reader) readDigest() error {
 	if r.expectedDataDigest, err = r.digestFdWithDigestContents.ReadDigest(); err != nil {
 		return err
 	}
+	return nil
+}
+
+// readIndexSummariesDigests reads the digests of the summaries and bloom
+// filter files if the fileset has them, then validates all digests read.
+func (r *reader) readIndexSummariesDigests() error {
+	if r.indexSummaries {
+		var err error
+		if r.expectedSummariesDigest, err = r.digestFdWithDigestContents.ReadDigest(); err != nil {
+			return err
+		}
+		if r.expectedBloomFilterDigest, err = r.digestFdWithDigestContents.ReadDigest(); err != nil {
+			return err
+		}
+	}
 	return r.digestFdWithDigestContents.Validate(r.expectedDigestOfDigest)
 }
 
@@ -210,7 +273,9 @@ func (r *reader) readInfo(size int) error {
 	r.blockSize = time.Duration(info.BlockSize)
 	r.entries = int(info.Entries)
 	r.entriesRead = 0
-	return nil
+	r.indexSummaries = hasIndexSummaries(info)
+	r.compression = CompressionType(info.Compression)
+	return r.compression.Validate()
 }
 
 func (r *reader) readIndex(size int) error {
@@ -222,12 +287,34 @@ func (r *reader) readIndex(size int) error {
 		return err
 	}
 	r.decoder.Reset(r.unreadBuf[:n][:])
+
+	// Index entries are sorted by ID, read them in the order of the data
+	// file so the data file can be read sequentially.
+	for i := range r.indexEntries {
+		r.indexEntries[i] = schema.IndexEntry{}
+	}
+	r.indexEntries = r.indexEntries[:0]
+	for i := 0; i < r.entries; i++ {
+		entry, err := r.decoder.DecodeIndexEntry()
+		if err != nil {
+			return err
+		}
+		r.indexEntries = append(r.indexEntries, entry)
+	}
+	sort.Sort(indexEntriesByOffsetAsc(r.indexEntries))
 	return nil
 }
 
+func (r *reader) nextIndexEntry() (schema.IndexEntry, error) {
+	if r.entriesRead >= len(r.indexEntries) {
+		return schema.IndexEntry{}, io.EOF
+	}
+	return r.indexEntries[r.entriesRead], nil
+}
+
 func (r *reader) Read() (ts.ID, checked.Bytes, uint32, error) {
 	var none ts.ID
-	entry, err := r.decoder.DecodeIndexEntry()
+	entry, err := r.nextIndexEntry()
 	if err != nil {
 		return none, nil, 0, err
 	}
@@ -246,6 +333,21 @@ func (r *reader) Read() (ts.ID, checked.Bytes, uint32, error) {
 		return none, nil, 0, ErrReadWrongIdx{ExpectedIdx: entry.Index, ActualIdx: idx}
 	}
 
+	if r.compression != CompressionNone {
+		data, err := r.readCompressed(int(entry.Size), uint32(entry.Checksum))
+		if err != nil {
+			return none, nil, 0, err
+		}
+		r.entriesRead++
+
+		// Return the checksum of the decompressed data so it matches the
+		// checksum of the same data written uncompressed
+		data.IncRef()
+		checksum := digest.Checksum(data.Get())
+		data.DecRef()
+		return r.entryID(entry.ID), data, checksum, nil
+	}
+
 	var data checked.Bytes
 	if r.bytesPool != nil {
 		data = r.bytesPool.Get(int(entry.Size))
@@ -271,9 +373,26 @@ func (r *reader) Read() (ts.ID, checked.Bytes, uint32, error) {
 	return r.entryID(entry.ID), data, uint32(entry.Checksum), nil
 }
 
+func (r *reader) readCompressed(size int, checksum uint32) (checked.Bytes, error) {
+	if len(r.compressed) < size {
+		r.compressed = make([]byte, size)
+	}
+	n, err := r.dataFdWithDigest.ReadBytes(r.compressed[:size])
+	if err != nil {
+		return nil, err
+	}
+	if n != size {
+		return nil, errReadNotExpectedSize
+	}
+	if digest.Checksum(r.compressed[:size]) != checksum {
+		return nil, errReadCompressedChecksumMismatch
+	}
+	return r.decompressor.Decompress(r.compressed[:size], r.bytesPool)
+}
+
 func (r *reader) ReadMetadata() (id ts.ID, length int, checksum uint32, err error) {
 	var none ts.ID
-	entry, err := r.decoder.DecodeIndexEntry()
+	entry, err := r.nextIndexEntry()
 	if err != nil {
 		return none, 0, 0, err
 	}
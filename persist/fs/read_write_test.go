@@ -23,6 +23,7 @@ package fs
 import (
 	"bytes"
 	"errors"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -35,6 +36,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/vmihailenco/msgpack.v2"
 )
 
 type testEntry struct {
@@ -46,7 +48,8 @@ func newTestWriter(filePathPrefix string) FileSetWriter {
 	blockSize := testBlockSize
 	newFileMode := defaultNewFileMode
 	newDirectoryMode := defaultNewDirectoryMode
-	return NewWriter(blockSize, filePathPrefix, testWriterBufferSize, newFileMode, newDirectoryMode)
+	return NewWriter(blockSize, filePathPrefix, testWriterBufferSize, newFileMode, newDirectoryMode,
+		CompressionNone)
 }
 
 func writeTestData(t *testing.T, w FileSetWriter, shard uint32, timestamp time.Time, entries []testEntry) {
@@ -85,6 +88,69 @@ func readTestData(t *testing.T, r FileSetReader, shard uint32, timestamp time.Ti
 	assert.NoError(t, r.Close())
 }
 
+// writeBaselineTestData writes a fileset in the layout written before index
+// summaries and bloom filters were added, with a three field info file, an
+// index in write order and only the info, index and data digests.
+func writeBaselineTestData(t *testing.T, filePathPrefix string, shard uint32, timestamp time.Time, entries []testEntry) {
+	shardDir := ShardDirPath(filePathPrefix, testNamespaceID, shard)
+	require.NoError(t, os.MkdirAll(shardDir, defaultNewDirectoryMode))
+
+	// Objects are encoded as the version, the root object fields with
+	// the object type and then the object fields
+	const (
+		version        = 1
+		indexInfoType  = 2
+		indexEntryType = 3
+	)
+	var info, index, data bytes.Buffer
+	infoEnc := msgpack.NewEncoder(&info)
+	require.NoError(t, infoEnc.EncodeInt64(version))
+	require.NoError(t, infoEnc.EncodeArrayLen(2))
+	require.NoError(t, infoEnc.EncodeInt64(indexInfoType))
+	require.NoError(t, infoEnc.EncodeArrayLen(3))
+	require.NoError(t, infoEnc.EncodeInt64(xtime.ToNanoseconds(timestamp)))
+	require.NoError(t, infoEnc.EncodeInt64(int64(testBlockSize)))
+	require.NoError(t, infoEnc.EncodeInt64(int64(len(entries))))
+
+	indexEnc := msgpack.NewEncoder(&index)
+	idx := make([]byte, idxLen)
+	for i, entry := range entries {
+		require.NoError(t, indexEnc.EncodeInt64(version))
+		require.NoError(t, indexEnc.EncodeArrayLen(2))
+		require.NoError(t, indexEnc.EncodeInt64(indexEntryType))
+		require.NoError(t, indexEnc.EncodeArrayLen(5))
+		require.NoError(t, indexEnc.EncodeInt64(int64(i)))
+		require.NoError(t, indexEnc.EncodeBytes([]byte(entry.id)))
+		require.NoError(t, indexEnc.EncodeInt64(int64(len(entry.data))))
+		require.NoError(t, indexEnc.EncodeInt64(int64(data.Len())))
+		require.NoError(t, indexEnc.EncodeInt64(int64(digest.Checksum(entry.data))))
+
+		endianness.PutUint64(idx, uint64(i))
+		data.Write(marker)
+		data.Write(idx)
+		data.Write(entry.data)
+	}
+
+	var digests []byte
+	buf := digest.NewBuffer()
+	for _, contents := range [][]byte{info.Bytes(), index.Bytes(), data.Bytes()} {
+		buf.WriteDigest(digest.Checksum(contents))
+		digests = append(digests, buf...)
+	}
+	buf.WriteDigest(digest.Checksum(digests))
+
+	for suffix, contents := range map[string][]byte{
+		infoFileSuffix:       info.Bytes(),
+		indexFileSuffix:      index.Bytes(),
+		dataFileSuffix:       data.Bytes(),
+		digestFileSuffix:     digests,
+		checkpointFileSuffix: buf,
+	} {
+		filePath := filesetPathFromTime(shardDir, timestamp, suffix)
+		require.NoError(t, ioutil.WriteFile(filePath, contents, defaultNewFileMode))
+	}
+}
+
 func TestSimpleReadWrite(t *testing.T) {
 	dir := createTempDir(t)
 	filePathPrefix := filepath.Join(dir, "")
@@ -105,6 +171,78 @@ func TestSimpleReadWrite(t *testing.T) {
 	readTestData(t, r, 0, testWriterStart, entries)
 }
 
+func TestCompressedReadWrite(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", bytes.Repeat([]byte{4, 5, 6}, 1000)},
+		{"baz", make([]byte, 65536)},
+		{"echo", []byte{7, 8, 9}},
+	}
+
+	w := NewWriter(testBlockSize, filePathPrefix, testWriterBufferSize,
+		defaultNewFileMode, defaultNewDirectoryMode, CompressionDeflate)
+	writeTestData(t, w, 0, testWriterStart, entries)
+
+	// Compressed data is smaller than the data written
+	dataFilePath := filesetPathFromTimeAndVersion(ShardDirPath(filePathPrefix, testNamespaceID, 0),
+		testWriterStart, 0, dataFileSuffix)
+	stat, err := os.Stat(dataFilePath)
+	require.NoError(t, err)
+	require.True(t, stat.Size() < 65536)
+
+	r := newTestReader(filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, entries)
+
+	for _, mmapEnabled := range []bool{false, true} {
+		s := newSeeker(seekerOpts{
+			filePathPrefixes: []string{filePathPrefix},
+			bufferSize:       testReaderBufferSize,
+			mmapEnabled:      mmapEnabled,
+		})
+		require.NoError(t, s.Open(testNamespaceID, 0, testWriterStart))
+		for _, entry := range entries {
+			data, err := s.Seek(ts.StringID(entry.id))
+			require.NoError(t, err)
+			data.IncRef()
+			require.Equal(t, entry.data, data.Get())
+			data.DecRef()
+		}
+		require.NoError(t, s.Close())
+	}
+}
+
+func TestCompressedReadChecksumMismatch(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", bytes.Repeat([]byte{1, 2, 3}, 100)},
+	}
+
+	w := NewWriter(testBlockSize, filePathPrefix, testWriterBufferSize,
+		defaultNewFileMode, defaultNewDirectoryMode, CompressionDeflate)
+	writeTestData(t, w, 0, testWriterStart, entries)
+
+	// Corrupt the last byte of the compressed data of the entry
+	dataFilePath := filesetPathFromTimeAndVersion(ShardDirPath(filePathPrefix, testNamespaceID, 0),
+		testWriterStart, 0, dataFileSuffix)
+	data, err := ioutil.ReadFile(dataFilePath)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(dataFilePath, data, defaultNewFileMode))
+
+	r := newTestReader(filePathPrefix)
+	require.NoError(t, r.Open(testNamespaceID, 0, testWriterStart))
+	_, _, _, err = r.Read()
+	require.Equal(t, errReadCompressedChecksumMismatch, err)
+	require.NoError(t, r.Close())
+}
+
 func TestInfoReadWrite(t *testing.T) {
 	dir := createTempDir(t)
 	filePathPrefix := filepath.Join(dir, "")
@@ -128,6 +266,118 @@ func TestInfoReadWrite(t *testing.T) {
 	require.True(t, testWriterStart.Equal(xtime.FromNanoseconds(infoFile.Start)))
 	require.Equal(t, testBlockSize, time.Duration(infoFile.BlockSize))
 	require.Equal(t, int64(len(entries)), infoFile.Entries)
+	require.Equal(t, int64(1), infoFile.Summaries)
+	require.True(t, infoFile.BloomFilterM > 0)
+	require.True(t, infoFile.BloomFilterK > 0)
+}
+
+func TestBaselineReadWrite(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", []byte{4, 5, 6}},
+		{"baz", make([]byte, 65536)},
+	}
+	writeBaselineTestData(t, filePathPrefix, 0, testWriterStart, entries)
+
+	r := newTestReader(filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, entries)
+}
+
+func TestBaselineRewriteReadWrite(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", []byte{4, 5, 6}},
+	}
+	rewrittenEntries := []testEntry{
+		{"foo", []byte{1, 2, 3, 4}},
+		{"bar", []byte{4, 5, 6}},
+		{"baz", []byte{7, 8, 9}},
+	}
+
+	// Unversioned filesets are read as version 0
+	writeBaselineTestData(t, filePathPrefix, 0, testWriterStart, entries)
+	version, exists := LatestFilesetVersion(filePathPrefix, testNamespaceID, 0, testWriterStart)
+	require.True(t, exists)
+	require.Equal(t, 0, version)
+
+	r := newTestReader(filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, entries)
+
+	s := newTestSeeker(filePathPrefix)
+	require.NoError(t, s.Open(testNamespaceID, 0, testWriterStart))
+	require.Equal(t, 0, s.(*seeker).filesetVersion())
+	data, err := s.Seek(ts.StringID("foo"))
+	require.NoError(t, err)
+	data.IncRef()
+	require.Equal(t, []byte{1, 2, 3}, data.Get())
+	data.DecRef()
+	require.NoError(t, s.Close())
+
+	// Ensure the rewrite supersedes the unversioned fileset
+	w := newTestWriter(filePathPrefix)
+	writeTestData(t, w, 0, testWriterStart, rewrittenEntries)
+	version, exists = LatestFilesetVersion(filePathPrefix, testNamespaceID, 0, testWriterStart)
+	require.True(t, exists)
+	require.Equal(t, 1, version)
+	superseded, err := SupersededFilesets(filePathPrefix, testNamespaceID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(superseded))
+
+	readTestData(t, r, 0, testWriterStart, rewrittenEntries)
+
+	require.NoError(t, s.Open(testNamespaceID, 0, testWriterStart))
+	require.Equal(t, 1, s.(*seeker).filesetVersion())
+	data, err = s.Seek(ts.StringID("baz"))
+	require.NoError(t, err)
+	data.IncRef()
+	require.Equal(t, []byte{7, 8, 9}, data.Get())
+	data.DecRef()
+	require.NoError(t, s.Close())
+}
+
+func TestRewriteReadWrite(t *testing.T) {
+	dir := createTempDir(t)
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", []byte{4, 5, 6}},
+	}
+	rewrittenEntries := []testEntry{
+		{"foo", []byte{1, 2, 3, 4}},
+		{"bar", []byte{4, 5, 6}},
+		{"baz", []byte{7, 8, 9}},
+	}
+
+	w := newTestWriter(filePathPrefix)
+	writeTestData(t, w, 0, testWriterStart, entries)
+	writeTestData(t, w, 0, testWriterStart, rewrittenEntries)
+
+	// Ensure no temporary files are left behind after the rewrite
+	shardDir := ShardDirPath(filePathPrefix, testNamespaceID, 0)
+	matched, err := filepath.Glob(filepath.Join(shardDir, "*"+tmpFileSuffix))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(matched))
+
+	// Ensure the rewrite was written as a new version superseding the first
+	version, exists := LatestFilesetVersion(filePathPrefix, testNamespaceID, 0, testWriterStart)
+	require.True(t, exists)
+	require.Equal(t, 1, version)
+	superseded, err := SupersededFilesets(filePathPrefix, testNamespaceID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, len(superseded))
+
+	r := newTestReader(filePathPrefix)
+	readTestData(t, r, 0, testWriterStart, rewrittenEntries)
 }
 
 func TestReusingReaderWriter(t *testing.T) {
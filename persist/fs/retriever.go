@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/pool"
@@ -99,7 +100,7 @@ func NewBlockRetriever(
 	}
 }
 
-func (r *blockRetriever) Open(namespace ts.ID) error {
+func (r *blockRetriever) Open(nsMetadata namespace.Metadata) error {
 	r.Lock()
 	defer r.Unlock()
 
@@ -110,7 +111,7 @@ func (r *blockRetriever) Open(namespace ts.ID) error {
 	seekerMgrs := make([]FileSetSeekerManager, 0, r.opts.FetchConcurrency())
 	for i := 0; i < r.opts.FetchConcurrency(); i++ {
 		seekerMgr := r.newSeekerMgrFn(r.bytesPool, r.fsOpts)
-		if err := seekerMgr.Open(namespace); err != nil {
+		if err := seekerMgr.Open(nsMetadata); err != nil {
 			for _, opened := range seekerMgrs {
 				opened.Close()
 			}
@@ -119,7 +120,7 @@ func (r *blockRetriever) Open(namespace ts.ID) error {
 		seekerMgrs = append(seekerMgrs, seekerMgr)
 	}
 
-	r.namespace = namespace
+	r.namespace = nsMetadata.ID()
 	r.status = blockRetrieverOpen
 	r.seekerMgrs = seekerMgrs
 
@@ -371,6 +372,12 @@ func (r *blockRetriever) Close() error {
 	r.namespace = nil
 	r.status = blockRetrieverClosed
 
+	// Notify fetch loops so they exit and close their seekers
+	select {
+	case r.notifyFetch <- struct{}{}:
+	default:
+	}
+
 	return nil
 }
 
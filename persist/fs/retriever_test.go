@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
@@ -72,15 +73,15 @@ func newOpenTestBlockRetriever(
 		retriever.newSeekerMgrFn = opts.newSeekerMgrFn
 	}
 
-	namespace := opts.namespace
-	if namespace == "" {
-		namespace = testNamespaceID.String()
+	nsName := opts.namespace
+	if nsName == "" {
+		nsName = testNamespaceID.String()
 	}
 
-	nsID := ts.StringID(namespace)
+	nsID := ts.StringID(nsName)
 	nsPath := NamespaceDirPath(filePathPrefix, nsID)
 	require.NoError(t, os.MkdirAll(nsPath, opts.fsOpts.NewDirectoryMode()))
-	require.NoError(t, retriever.Open(nsID))
+	require.NoError(t, retriever.Open(namespace.NewMetadata(nsID, namespace.NewOptions())))
 
 	return retriever, func() {
 		assert.NoError(t, retriever.Close())
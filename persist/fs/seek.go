@@ -26,51 +26,82 @@ import (
 	"errors"
 	"io"
 	"os"
+	"sort"
 	"time"
 
+	"github.com/m3db/m3db/digest"
 	"github.com/m3db/m3db/persist/encoding"
 	"github.com/m3db/m3db/persist/encoding/msgpack"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/pool"
 	"github.com/m3db/m3x/time"
-	"github.com/m3db/m3db/digest"
 )
 
 var (
 	// errSeekIDNotFound returned when ID cannot be found in the shard
 	errSeekIDNotFound = errors.New("id not found in shard")
+
+	// errSeekIndexChecksumMismatch returned when the index file does not match its digest
+	errSeekIndexChecksumMismatch = errors.New("index file checksum mismatch")
 )
 
 type seeker struct {
-	filePathPrefix string
-	start          time.Time
-	blockSize      time.Duration
+	filePathPrefixes []string
+	filePathPrefix   string
+	start            time.Time
+	blockSize        time.Duration
+	version          int
 
 	infoFdWithDigest           digest.FdWithDigestReader
-	indexFdWithDigest          digest.FdWithDigestReader
+	summariesFdWithDigest      digest.FdWithDigestReader
+	bloomFilterFdWithDigest    digest.FdWithDigestReader
 	dataReader                 *bufio.Reader
 	digestFdWithDigestContents digest.FdWithDigestContentsReader
 	expectedInfoDigest         uint32
 	expectedIndexDigest        uint32
+	expectedSummariesDigest    uint32
+	expectedBloomFilterDigest  uint32
 
 	keepIndexIDs  bool
 	keepUnreadBuf bool
+	mmapEnabled   bool
+
+	unreadBuf      []byte
+	prologue       []byte
+	entries        int
+	indexSummaries bool
+	numSummaries   int
+	bloomFilterM   uint64
+	bloomFilterK   uint64
+	indexSize      int64
+	indexFd        *os.File
+	dataFd         *os.File
+	indexRegion    *mmapRegion
+	dataRegion     *mmapRegion
+	// Summaries are non pointer types with their IDs kept in a
+	// single slice to avoid the GC scanning many small allocations.
+	summaries    []indexSummary
+	summaryIDs   []byte
+	bloomFilter  *bloomFilter
+	indexScanBuf []byte
+	indexIDs     []ts.ID
+	compression  CompressionType
+	compressed   []byte
+	decoder      encoding.Decoder
+	decompressor *decompressor
+	bytesPool    pool.CheckedBytesPool
+}
 
-	unreadBuf []byte
-	prologue  []byte
-	entries   int
-	dataFd    *os.File
-	// NB(r): specifically use a non pointer type for
-	// key and value in this map to avoid the GC scanning
-	// this large map.
-	indexMap  map[ts.Hash]indexMapEntry
-	indexIDs  []ts.ID
-	decoder   encoding.Decoder
-	bytesPool pool.CheckedBytesPool
+type indexSummary struct {
+	idStart     int
+	idEnd       int
+	index       int64
+	indexOffset int64
 }
 
-type indexMapEntry struct {
+type indexLookupEntry struct {
 	size   int64
 	offset int64
 }
@@ -81,24 +112,36 @@ func NewSeeker(
 	bufferSize int,
 	bytesPool pool.CheckedBytesPool,
 	decodingOpts msgpack.DecodingOptions,
+) FileSetSeeker {
+	return NewTieredSeeker([]string{filePathPrefix}, bufferSize, bytesPool, decodingOpts)
+}
+
+// NewTieredSeeker returns a new seeker that seeks each fileset in the first
+// of the file path prefixes of the tiers with a complete fileset.
+func NewTieredSeeker(
+	filePathPrefixes []string,
+	bufferSize int,
+	bytesPool pool.CheckedBytesPool,
+	decodingOpts msgpack.DecodingOptions,
 ) FileSetSeeker {
 	return newSeeker(seekerOpts{
-		filePathPrefix: filePathPrefix,
-		bufferSize:     bufferSize,
-		bytesPool:      bytesPool,
-		keepIndexIDs:   true,
-		keepUnreadBuf:  false,
-		decodingOpts:   decodingOpts,
+		filePathPrefixes: filePathPrefixes,
+		bufferSize:       bufferSize,
+		bytesPool:        bytesPool,
+		keepIndexIDs:     true,
+		keepUnreadBuf:    false,
+		decodingOpts:     decodingOpts,
 	})
 }
 
 type seekerOpts struct {
-	filePathPrefix string
-	bufferSize     int
-	bytesPool      pool.CheckedBytesPool
-	keepIndexIDs   bool
-	keepUnreadBuf  bool
-	decodingOpts   msgpack.DecodingOptions
+	filePathPrefixes []string
+	bufferSize       int
+	bytesPool        pool.CheckedBytesPool
+	keepIndexIDs     bool
+	keepUnreadBuf    bool
+	mmapEnabled      bool
+	decodingOpts     msgpack.DecodingOptions
 }
 
 // fileSetSeeker adds package level access to further methods
@@ -112,20 +155,30 @@ type fileSetSeeker interface {
 
 	// setUnreadBuffer sets the unread buffer
 	setUnreadBuffer(buf []byte)
+
+	// filesetVersion returns the version of the fileset opened
+	filesetVersion() int
+
+	// filesetFilePathPrefix returns the file path prefix of the tier
+	// of the fileset opened
+	filesetFilePathPrefix() string
 }
 
 func newSeeker(opts seekerOpts) fileSetSeeker {
 	return &seeker{
-		filePathPrefix:             opts.filePathPrefix,
+		filePathPrefixes:           opts.filePathPrefixes,
 		infoFdWithDigest:           digest.NewFdWithDigestReader(opts.bufferSize),
-		indexFdWithDigest:          digest.NewFdWithDigestReader(opts.bufferSize),
+		summariesFdWithDigest:      digest.NewFdWithDigestReader(opts.bufferSize),
+		bloomFilterFdWithDigest:    digest.NewFdWithDigestReader(opts.bufferSize),
 		dataReader:                 bufio.NewReaderSize(nil, opts.bufferSize),
 		digestFdWithDigestContents: digest.NewFdWithDigestContentsReader(opts.bufferSize),
 		keepIndexIDs:               opts.keepIndexIDs,
 		keepUnreadBuf:              opts.keepUnreadBuf,
+		mmapEnabled:                opts.mmapEnabled,
 		prologue:                   make([]byte, markerLen+idxLen),
 		bytesPool:                  opts.bytesPool,
 		decoder:                    msgpack.NewDecoder(opts.decodingOpts),
+		decompressor:               newDecompressor(),
 	}
 }
 
@@ -134,25 +187,36 @@ func (s *seeker) IDs() []ts.ID {
 }
 
 func (s *seeker) Open(namespace ts.ID, shard uint32, blockStart time.Time) error {
-	shardDir := ShardDirPath(s.filePathPrefix, namespace, shard)
+	filePathPrefix, exists := FilesetTierFilePathPrefix(s.filePathPrefixes, namespace, shard, blockStart)
+	if !exists {
+		return errCheckpointFileNotFound
+	}
+	version, exists := LatestFilesetVersion(filePathPrefix, namespace, shard, blockStart)
+	if !exists {
+		return errCheckpointFileNotFound
+	}
+	s.filePathPrefix = filePathPrefix
+	shardDir := ShardDirPath(filePathPrefix, namespace, shard)
 	var infoFd, indexFd, dataFd, digestFd *os.File
 	if err := openFiles(os.Open, map[string]**os.File{
-		filesetPathFromTime(shardDir, blockStart, infoFileSuffix):   &infoFd,
-		filesetPathFromTime(shardDir, blockStart, indexFileSuffix):  &indexFd,
-		filesetPathFromTime(shardDir, blockStart, dataFileSuffix):   &dataFd,
-		filesetPathFromTime(shardDir, blockStart, digestFileSuffix): &digestFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, infoFileSuffix):   &infoFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, indexFileSuffix):  &indexFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, dataFileSuffix):   &dataFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, digestFileSuffix): &digestFd,
 	}); err != nil {
 		return err
 	}
+	s.version = version
+	// The index file is kept open to scan the entries between summaries
+	s.indexFd = indexFd
+	s.dataFd = dataFd
 
 	s.infoFdWithDigest.Reset(infoFd)
-	s.indexFdWithDigest.Reset(indexFd)
 	s.digestFdWithDigestContents.Reset(digestFd)
 
 	defer func() {
 		// NB(r): We don't need to keep these FDs open as we use these up front
 		s.infoFdWithDigest.Close()
-		s.indexFdWithDigest.Close()
 		s.digestFdWithDigestContents.Close()
 	}()
 
@@ -171,13 +235,28 @@ func (s *seeker) Open(namespace ts.ID, shard uint32, blockStart time.Time) error
 		s.Close()
 		return err
 	}
+	s.indexSize = indexStat.Size()
 	if err := s.readInfo(int(infoStat.Size())); err != nil {
 		s.Close()
 		return err
 	}
-	if err := s.readIndex(int(indexStat.Size())); err != nil {
-		s.Close()
-		return err
+	if s.indexSummaries {
+		if err := s.readIndexSummaries(shardDir, blockStart, version); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	if s.mmapEnabled {
+		if err := s.mmapIndexAndData(); err != nil {
+			s.Close()
+			return err
+		}
+	}
+	if s.keepIndexIDs {
+		if err := s.readIndexIDs(int(indexStat.Size())); err != nil {
+			s.Close()
+			return err
+		}
 	}
 
 	if !s.keepUnreadBuf {
@@ -187,7 +266,6 @@ func (s *seeker) Open(namespace ts.ID, shard uint32, blockStart time.Time) error
 		s.decoder.Reset(nil)
 	}
 
-	s.dataFd = dataFd
 	return nil
 }
 
@@ -206,6 +284,14 @@ func (s *seeker) setUnreadBuffer(buf []byte) {
 	s.unreadBuf = buf
 }
 
+func (s *seeker) filesetFilePathPrefix() string {
+	return s.filePathPrefix
+}
+
+func (s *seeker) filesetVersion() int {
+	return s.version
+}
+
 func (s *seeker) readDigest() error {
 	var err error
 	if s.expectedInfoDigest, err = s.digestFdWithDigestContents.ReadDigest(); err != nil {
@@ -214,9 +300,52 @@ func (s *seeker) readDigest() error {
 	if s.expectedIndexDigest, err = s.digestFdWithDigestContents.ReadDigest(); err != nil {
 		return err
 	}
+	// Skip the data digest, the data is not read up front
+	if _, err = s.digestFdWithDigestContents.ReadDigest(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// readIndexSummaries reads the summaries and bloom filter of a fileset that
+// has them, lookups in filesets without them scan the whole index instead.
+func (s *seeker) readIndexSummaries(shardDir string, blockStart time.Time, version int) error {
+	var err error
+	if s.expectedSummariesDigest, err = s.digestFdWithDigestContents.ReadDigest(); err != nil {
+		return err
+	}
+	if s.expectedBloomFilterDigest, err = s.digestFdWithDigestContents.ReadDigest(); err != nil {
+		return err
+	}
+
+	var summariesFd, bloomFilterFd *os.File
+	if err := openFiles(os.Open, map[string]**os.File{
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, summariesFileSuffix):   &summariesFd,
+		filesetPathFromTimeAndVersion(shardDir, blockStart, version, bloomFilterFileSuffix): &bloomFilterFd,
+	}); err != nil {
+		return err
+	}
+	s.summariesFdWithDigest.Reset(summariesFd)
+	s.bloomFilterFdWithDigest.Reset(bloomFilterFd)
+	defer func() {
+		s.summariesFdWithDigest.Close()
+		s.bloomFilterFdWithDigest.Close()
+	}()
+
+	summariesStat, err := summariesFd.Stat()
+	if err != nil {
+		return err
+	}
+	bloomFilterStat, err := bloomFilterFd.Stat()
+	if err != nil {
+		return err
+	}
+	if err := s.readSummaries(int(summariesStat.Size())); err != nil {
+		return err
+	}
+	return s.readBloomFilter(int(bloomFilterStat.Size()))
+}
+
 func (s *seeker) readInfo(size int) error {
 	s.prepareUnreadBuf(size)
 	n, err := s.infoFdWithDigest.ReadAllAndValidate(s.unreadBuf[:size], s.expectedInfoDigest)
@@ -231,49 +360,201 @@ func (s *seeker) readInfo(size int) error {
 	s.start = xtime.FromNanoseconds(info.Start)
 	s.blockSize = time.Duration(info.BlockSize)
 	s.entries = int(info.Entries)
+	s.numSummaries = int(info.Summaries)
+	s.bloomFilterM = info.BloomFilterM
+	s.bloomFilterK = info.BloomFilterK
+	s.indexSummaries = hasIndexSummaries(info)
+	s.compression = CompressionType(info.Compression)
+	return s.compression.Validate()
+}
+
+func (s *seeker) readSummaries(size int) error {
+	s.prepareUnreadBuf(size)
+	n, err := s.summariesFdWithDigest.ReadAllAndValidate(s.unreadBuf[:size], s.expectedSummariesDigest)
+	if err != nil {
+		return err
+	}
+
+	s.summaries = s.summaries[:0]
+	s.summaryIDs = s.summaryIDs[:0]
+	s.decoder.Reset(s.unreadBuf[:n])
+	for i := 0; i < s.numSummaries; i++ {
+		summary, err := s.decoder.DecodeIndexSummary()
+		if err != nil {
+			return err
+		}
+		idStart := len(s.summaryIDs)
+		s.summaryIDs = append(s.summaryIDs, summary.ID...)
+		s.summaries = append(s.summaries, indexSummary{
+			idStart:     idStart,
+			idEnd:       len(s.summaryIDs),
+			index:       summary.Index,
+			indexOffset: summary.IndexEntryOffset,
+		})
+	}
+
 	return nil
 }
 
-func (s *seeker) readIndex(size int) error {
+func (s *seeker) readBloomFilter(size int) error {
 	s.prepareUnreadBuf(size)
-	indexBytes := s.unreadBuf[:size]
-	n, err := s.indexFdWithDigest.ReadAllAndValidate(indexBytes, s.expectedIndexDigest)
+	n, err := s.bloomFilterFdWithDigest.ReadAllAndValidate(s.unreadBuf[:size], s.expectedBloomFilterDigest)
 	if err != nil {
 		return err
 	}
 
-	s.decoder.Reset(s.unreadBuf[:n][:])
-	if s.indexMap == nil {
-		s.indexMap = make(map[ts.Hash]indexMapEntry, s.entries)
+	// Copy the bits as the unread buffer is reused
+	bits := append([]byte(nil), s.unreadBuf[:n]...)
+	s.bloomFilter, err = newBloomFilterFromBytes(s.bloomFilterM, s.bloomFilterK, bits)
+	return err
+}
+
+// mmapIndexAndData maps the index and data files, the file descriptors
+// are closed as the mappings remain valid until unmapped.
+func (s *seeker) mmapIndexAndData() error {
+	var err error
+	if s.indexRegion, err = newMmapRegion(s.indexFd); err != nil {
+		return err
+	}
+	if s.dataRegion, err = newMmapRegion(s.dataFd); err != nil {
+		return err
 	}
-	// Read all entries of index
+	err = closeAll(s.indexFd, s.dataFd)
+	s.indexFd = nil
+	s.dataFd = nil
+	return err
+}
+
+// indexBytes returns the bytes of the index file between two offsets, the
+// bytes are only valid until the next call.
+func (s *seeker) indexBytes(start, end int64) ([]byte, error) {
+	if s.indexRegion != nil {
+		mapped := s.indexRegion.Bytes()
+		if end > int64(len(mapped)) {
+			return nil, errReadNotExpectedSize
+		}
+		return mapped[start:end], nil
+	}
+
+	size := int(end - start)
+	if len(s.indexScanBuf) < size {
+		s.indexScanBuf = make([]byte, size)
+	}
+	buf := s.indexScanBuf[:size]
+	if n, err := s.indexFd.ReadAt(buf, start); err != nil && !(err == io.EOF && n == size) {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (s *seeker) readIndexIDs(size int) error {
+	indexBytes, err := s.indexBytes(0, int64(size))
+	if err != nil {
+		return err
+	}
+	if digest.Checksum(indexBytes) != s.expectedIndexDigest {
+		return errSeekIndexChecksumMismatch
+	}
+
+	s.decoder.Reset(indexBytes)
 	for read := 0; read < s.entries; read++ {
 		entry, err := s.decoder.DecodeIndexEntry()
 		if err != nil {
 			return err
 		}
-		s.indexMap[ts.HashFn(entry.ID)] = indexMapEntry{
-			size:   entry.Size,
-			offset: entry.Offset,
-		}
+		entryID := append([]byte(nil), entry.ID...)
+		id := ts.BinaryID(checked.NewBytes(entryID, nil))
+		s.indexIDs = append(s.indexIDs, id)
+	}
 
-		if s.keepIndexIDs {
-			entryID := append([]byte(nil), entry.ID...)
-			id := ts.BinaryID(checked.NewBytes(entryID, nil))
-			s.indexIDs = append(s.indexIDs, id)
+	return nil
+}
+
+func (s *seeker) summaryID(i int) []byte {
+	return s.summaryIDs[s.summaries[i].idStart:s.summaries[i].idEnd]
+}
+
+// lookup finds the index entry for an ID by testing the bloom filter, then
+// binary searching the summaries and scanning the index entries following
+// the closest summary.
+func (s *seeker) lookup(id ts.ID) (indexLookupEntry, bool, error) {
+	idBytes := id.Data().Get()
+	if !s.indexSummaries {
+		return s.scanIndex(idBytes)
+	}
+	if s.bloomFilter == nil || !s.bloomFilter.Test(idBytes) {
+		return indexLookupEntry{}, false, nil
+	}
+
+	// Find the last summary with an ID less than or equal to the ID
+	i := sort.Search(len(s.summaries), func(i int) bool {
+		return bytes.Compare(s.summaryID(i), idBytes) > 0
+	}) - 1
+	if i < 0 {
+		return indexLookupEntry{}, false, nil
+	}
+
+	start, end := s.summaries[i].indexOffset, s.indexSize
+	numEntries := int64(s.entries) - s.summaries[i].index
+	if i+1 < len(s.summaries) {
+		end = s.summaries[i+1].indexOffset
+		numEntries = s.summaries[i+1].index - s.summaries[i].index
+	}
+	buf, err := s.indexBytes(start, end)
+	if err != nil {
+		return indexLookupEntry{}, false, err
+	}
+
+	s.decoder.Reset(buf)
+	for j := int64(0); j < numEntries; j++ {
+		entry, err := s.decoder.DecodeIndexEntry()
+		if err != nil {
+			return indexLookupEntry{}, false, err
+		}
+		switch cmp := bytes.Compare(entry.ID, idBytes); {
+		case cmp == 0:
+			return indexLookupEntry{size: entry.Size, offset: entry.Offset}, true, nil
+		case cmp > 0:
+			return indexLookupEntry{}, false, nil
 		}
 	}
+	return indexLookupEntry{}, false, nil
+}
 
-	return nil
+// scanIndex finds the index entry for an ID by scanning all index entries,
+// filesets without summaries were written with entries unsorted by ID.
+func (s *seeker) scanIndex(idBytes []byte) (indexLookupEntry, bool, error) {
+	buf, err := s.indexBytes(0, s.indexSize)
+	if err != nil {
+		return indexLookupEntry{}, false, err
+	}
+
+	s.decoder.Reset(buf)
+	for i := 0; i < s.entries; i++ {
+		entry, err := s.decoder.DecodeIndexEntry()
+		if err != nil {
+			return indexLookupEntry{}, false, err
+		}
+		if bytes.Equal(entry.ID, idBytes) {
+			return indexLookupEntry{size: entry.Size, offset: entry.Offset}, true, nil
+		}
+	}
+	return indexLookupEntry{}, false, nil
 }
 
 func (s *seeker) Seek(id ts.ID) (checked.Bytes, error) {
-	entry, exists := s.indexMap[id.Hash()]
+	entry, exists, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
 	if !exists {
 		return nil, errSeekIDNotFound
 	}
+	if s.dataRegion != nil {
+		return s.seekMmap(entry)
+	}
 
-	_, err := s.dataFd.Seek(entry.offset, 0)
+	_, err = s.dataFd.Seek(entry.offset, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -288,6 +569,17 @@ func (s *seeker) Seek(id ts.ID) (checked.Bytes, error) {
 		return nil, errReadMarkerNotFound
 	}
 
+	if s.compression != CompressionNone {
+		if len(s.compressed) < int(entry.size) {
+			s.compressed = make([]byte, entry.size)
+		}
+		compressed := s.compressed[:entry.size]
+		if err := s.readData(compressed); err != nil {
+			return nil, err
+		}
+		return s.decompressor.Decompress(compressed, s.bytesPool)
+	}
+
 	var data checked.Bytes
 	if s.bytesPool != nil {
 		data = s.bytesPool.Get(int(entry.size))
@@ -300,35 +592,61 @@ func (s *seeker) Seek(id ts.ID) (checked.Bytes, error) {
 		defer data.DecRef()
 	}
 
-	n, err = s.dataReader.Read(data.Get())
-	if err != nil {
+	if err := s.readData(data.Get()); err != nil {
 		return nil, err
 	}
+	return data, nil
+}
+
+func (s *seeker) readData(b []byte) error {
+	n, err := s.dataReader.Read(b)
+	if err != nil {
+		return err
+	}
 
 	// In case the buffered reader only returns what's remaining in
 	// the buffer, repeatedly read what's left in the underlying reader.
-	for n < int(entry.size) {
-		b := data.Get()[n:]
-		remainder, err := s.dataReader.Read(b)
+	for n < len(b) {
+		remainder, err := s.dataReader.Read(b[n:])
 
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return nil, err
+			return err
 		}
 		n += remainder
 	}
 
-	if n != int(entry.size) {
-		return nil, errReadNotExpectedSize
+	if n != len(b) {
+		return errReadNotExpectedSize
 	}
+	return nil
+}
 
-	return data, nil
+// seekMmap returns the data for an entry without copying it out of the
+// mapped data file, the mapping is kept until the data is finalized.
+// Compressed data is decompressed out of the mapping instead.
+func (s *seeker) seekMmap(entry indexLookupEntry) (checked.Bytes, error) {
+	var (
+		mapped = s.dataRegion.Bytes()
+		start  = entry.offset + int64(len(s.prologue))
+		end    = start + entry.size
+	)
+	if end > int64(len(mapped)) {
+		return nil, errReadNotExpectedSize
+	}
+	if !bytes.Equal(mapped[entry.offset:entry.offset+int64(markerLen)], marker) {
+		return nil, errReadMarkerNotFound
+	}
+	if s.compression != CompressionNone {
+		return s.decompressor.Decompress(mapped[start:end], s.bytesPool)
+	}
+	return s.dataRegion.Slice(int(start), int(end)), nil
 }
 
 func (s *seeker) SeekOffset(id ts.ID) int {
-	entry, exists := s.indexMap[id.Hash()]
-	if !exists {
+	entry, exists, err := s.lookup(id)
+	if err != nil || !exists {
 		return -1
 	}
 	return int(entry.offset)
@@ -344,13 +662,27 @@ func (s *seeker) Entries() int {
 
 func (s *seeker) Close() error {
 	// Prepare for reuse
-	for key := range s.indexMap {
-		delete(s.indexMap, key)
+	s.summaries = s.summaries[:0]
+	s.summaryIDs = s.summaryIDs[:0]
+	s.bloomFilter = nil
+	s.indexSummaries = false
+	multiErr := xerrors.NewMultiError()
+	if s.indexFd != nil {
+		multiErr = multiErr.Add(s.indexFd.Close())
+		s.indexFd = nil
 	}
-	if s.dataFd == nil {
-		return nil
+	if s.dataFd != nil {
+		multiErr = multiErr.Add(s.dataFd.Close())
+		s.dataFd = nil
 	}
-	err := s.dataFd.Close()
-	s.dataFd = nil
-	return err
+	// Segments handed out from the data region keep it mapped until finalized
+	if s.indexRegion != nil {
+		multiErr = multiErr.Add(s.indexRegion.DecRef())
+		s.indexRegion = nil
+	}
+	if s.dataRegion != nil {
+		multiErr = multiErr.Add(s.dataRegion.DecRef())
+		s.dataRegion = nil
+	}
+	return multiErr.FinalError()
 }
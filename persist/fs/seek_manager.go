@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/pool"
@@ -56,12 +57,13 @@ type seekerManager struct {
 
 	opts Options
 
-	bytesPool      pool.CheckedBytesPool
-	filePathPrefix string
+	bytesPool        pool.CheckedBytesPool
+	filePathPrefixes []string
 
 	status                 seekerManagerStatus
 	seekersByShardIdx      []*seekersByTime
 	namespace              ts.ID
+	retentionOpts          retention.Options
 	unreadBuf              seekerUnreadBuf
 	openAnyUnopenSeekersFn openAnyUnopenSeekersFn
 }
@@ -89,16 +91,17 @@ func NewSeekerManager(
 	opts Options,
 ) FileSetSeekerManager {
 	m := &seekerManager{
-		bytesPool:      bytesPool,
-		filePathPrefix: opts.FilePathPrefix(),
-		opts:           opts,
+		bytesPool:        bytesPool,
+		filePathPrefixes: TierFilePathPrefixes(opts.FilePathPrefix(), opts.TieringPolicy()),
+		opts:             opts,
+		retentionOpts:    opts.RetentionOptions(),
 	}
 	m.openAnyUnopenSeekersFn = m.openAnyUnopenSeekers
 	return m
 }
 
 func (m *seekerManager) Open(
-	namespace ts.ID,
+	nsMetadata namespace.Metadata,
 ) error {
 	m.Lock()
 	defer m.Unlock()
@@ -107,7 +110,8 @@ func (m *seekerManager) Open(
 		return errSeekerManagerAlreadyOpenOrClosed
 	}
 
-	m.namespace = namespace
+	m.namespace = nsMetadata.ID()
+	m.retentionOpts = nsMetadata.Options().RetentionOptions()
 	m.status = seekerManagerOpen
 
 	go m.openCloseLoop()
@@ -137,7 +141,7 @@ func (m *seekerManager) CacheShardIndices(shards []uint32) error {
 func (m *seekerManager) openAnyUnopenSeekers(byTime *seekersByTime) error {
 	start := m.earliestSeekableBlockStart()
 	end := m.latestSeekableBlockStart()
-	blockSize := m.opts.RetentionOptions().BlockSize()
+	blockSize := m.retentionOpts.BlockSize()
 	multiErr := xerrors.NewMultiError()
 
 	for t := start; !t.After(end); t = t.Add(blockSize) {
@@ -181,27 +185,68 @@ func (m *seekerManager) Seeker(shard uint32, start time.Time) (FileSetSeeker, er
 	// Track accessed to precache in open/close loop
 	byTime.accessed = true
 
-	seeker, ok := byTime.seekers[start]
-	if ok {
+	existing, ok := byTime.seekers[start]
+	if ok && !m.seekerSuperseded(shard, start, existing) {
 		byTime.Unlock()
-		return seeker, nil
+		return existing, nil
 	}
 
-	var err error
-	seeker, err = m.newOpenSeeker(shard, start)
-	if err == nil {
-		byTime.seekers[start] = seeker
+	seeker, err := m.newOpenSeeker(shard, start)
+	if err != nil {
+		byTime.Unlock()
+		if ok {
+			// Keep reading from the existing version, its files remain
+			// readable while open even once they are removed
+			return existing, nil
+		}
+		return nil, err
 	}
+	byTime.seekers[start] = seeker
 	byTime.Unlock()
 
-	return seeker, err
+	if ok {
+		// Close after releasing lock so any IO is done out of lock
+		existing.Close()
+	}
+
+	return seeker, nil
+}
+
+// seekerSuperseded returns whether a newer version of the fileset an open
+// seeker reads from has been written, either the next version is complete,
+// the version of the seeker has been removed once superseded or moved to
+// another tier, or a complete fileset has been written to an earlier tier.
+func (m *seekerManager) seekerSuperseded(
+	shard uint32,
+	blockStart time.Time,
+	seeker fileSetSeeker,
+) bool {
+	var (
+		filePathPrefix = seeker.filesetFilePathPrefix()
+		shardDir       = ShardDirPath(filePathPrefix, m.namespace, shard)
+		version        = seeker.filesetVersion()
+		next           = filesetPathFromTimeAndVersion(shardDir, blockStart, version+1, checkpointFileSuffix)
+		curr           = filesetPathFromTimeAndVersion(shardDir, blockStart, version, checkpointFileSuffix)
+	)
+	if FileExists(next) || !FileExists(curr) {
+		return true
+	}
+	for _, prefix := range m.filePathPrefixes {
+		if prefix == filePathPrefix {
+			break
+		}
+		if FilesetExistsAt(prefix, m.namespace, shard, blockStart) {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *seekerManager) newOpenSeeker(
 	shard uint32,
 	blockStart time.Time,
 ) (fileSetSeeker, error) {
-	if !FilesetExistsAt(m.filePathPrefix, m.namespace, shard, blockStart) {
+	if !FilesetExistsAtAnyTier(m.filePathPrefixes, m.namespace, shard, blockStart) {
 		return nil, errSeekerManagerFileSetNotFound
 	}
 
@@ -212,11 +257,12 @@ func (m *seekerManager) newOpenSeeker(
 	defer m.unreadBuf.Unlock()
 
 	seeker := newSeeker(seekerOpts{
-		filePathPrefix: m.filePathPrefix,
-		bufferSize:     m.opts.ReaderBufferSize(),
-		bytesPool:      m.bytesPool,
-		keepIndexIDs:   false,
-		keepUnreadBuf:  true,
+		filePathPrefixes: m.filePathPrefixes,
+		bufferSize:       m.opts.ReaderBufferSize(),
+		bytesPool:        m.bytesPool,
+		keepIndexIDs:     false,
+		keepUnreadBuf:    true,
+		mmapEnabled:      m.opts.MmapEnabled(),
 	})
 
 	// Set the unread buffer to reuse it amongst all seekers.
@@ -284,7 +330,7 @@ func (m *seekerManager) Close() error {
 func (m *seekerManager) earliestSeekableBlockStart() time.Time {
 	nowFn := m.opts.ClockOptions().NowFn()
 	now := nowFn()
-	ropts := m.opts.RetentionOptions()
+	ropts := m.retentionOpts
 	blockSize := ropts.BlockSize()
 	earliestReachableBlockStart := retention.FlushTimeStart(ropts, now)
 	earliestSeekableBlockStart := earliestReachableBlockStart.Add(-blockSize)
@@ -294,8 +340,7 @@ func (m *seekerManager) earliestSeekableBlockStart() time.Time {
 func (m *seekerManager) latestSeekableBlockStart() time.Time {
 	nowFn := m.opts.ClockOptions().NowFn()
 	now := nowFn()
-	ropts := m.opts.RetentionOptions()
-	return now.Truncate(ropts.BlockSize())
+	return now.Truncate(m.retentionOpts.BlockSize())
 }
 
 func (m *seekerManager) openCloseLoop() {
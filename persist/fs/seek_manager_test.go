@@ -21,7 +21,15 @@
 package fs
 
 import (
+	"os"
 	"testing"
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/pool"
 
 	"github.com/stretchr/testify/require"
 )
@@ -58,3 +66,66 @@ func TestSeekerManagerCacheShardIndices(t *testing.T) {
 		}
 	}
 }
+
+func TestSeekerManagerSeekerOpensLatestVersion(t *testing.T) {
+	filePathPrefix := createTempDir(t)
+	defer os.RemoveAll(filePathPrefix)
+
+	bytesPool := pool.NewCheckedBytesPool([]pool.Bucket{pool.Bucket{
+		Capacity: 1024,
+		Count:    10,
+	}}, nil, func(s []pool.Bucket) pool.BytesPool {
+		return pool.NewBytesPool(s, nil)
+	})
+	bytesPool.Init()
+
+	w := newTestWriter(filePathPrefix)
+	writeTestData(t, w, 0, testWriterStart, []testEntry{{"foo", []byte{1, 2, 3}}})
+
+	m := NewSeekerManager(bytesPool, NewOptions().SetFilePathPrefix(filePathPrefix))
+	require.NoError(t, m.Open(namespace.NewMetadata(testNamespaceID, namespace.NewOptions())))
+	defer m.Close()
+
+	assertSeek := func(expected []byte) {
+		seeker, err := m.Seeker(0, testWriterStart)
+		require.NoError(t, err)
+		data, err := seeker.Seek(ts.StringID("foo"))
+		require.NoError(t, err)
+		data.IncRef()
+		defer data.DecRef()
+		require.Equal(t, expected, data.Get())
+	}
+	assertSeek([]byte{1, 2, 3})
+
+	// Rewrite the fileset and remove the superseded version
+	writeTestData(t, w, 0, testWriterStart, []testEntry{{"foo", []byte{1, 2, 3, 4}}})
+	assertSeek([]byte{1, 2, 3, 4})
+
+	writeTestData(t, w, 0, testWriterStart, []testEntry{{"foo", []byte{1, 2, 3, 4, 5}}})
+	superseded, err := SupersededFilesets(filePathPrefix, testNamespaceID, 0)
+	require.NoError(t, err)
+	require.NoError(t, DeleteFiles(superseded))
+	assertSeek([]byte{1, 2, 3, 4, 5})
+}
+
+func TestSeekerManagerUsesNamespaceBlockSize(t *testing.T) {
+	now := time.Date(2017, 6, 15, 13, 30, 0, 0, time.UTC)
+	opts := NewOptions().
+		SetClockOptions(clock.NewOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+	ropts := retention.NewOptions().
+		SetBlockSize(24 * time.Hour).
+		SetRetentionPeriod(7 * 24 * time.Hour)
+	md := namespace.NewMetadata(testNamespaceID, namespace.NewOptions().
+		SetRetentionOptions(ropts))
+
+	m := NewSeekerManager(nil, opts).(*seekerManager)
+	require.NoError(t, m.Open(md))
+	defer m.Close()
+
+	require.Equal(t, now.Truncate(24*time.Hour), m.latestSeekableBlockStart())
+	require.Equal(t,
+		retention.FlushTimeStart(ropts, now).Add(-24*time.Hour),
+		m.earliestSeekableBlockStart())
+}
@@ -21,6 +21,7 @@
 package fs
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -290,3 +291,137 @@ func TestReuseSeeker(t *testing.T) {
 	defer data.DecRef()
 	assert.Equal(t, []byte{1, 2, 3}, data.Get())
 }
+
+func TestSeekAcrossSummaries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	// Write entries in reverse order to exercise sorting of the index
+	numEntries := 3*indexSummariesInterval + 1
+	w := newTestWriter(filePathPrefix)
+	err = w.Open(testNamespaceID, 0, testWriterStart)
+	assert.NoError(t, err)
+	for i := numEntries - 1; i >= 0; i-- {
+		data := []byte{byte(i), byte(i >> 8)}
+		assert.NoError(t, w.Write(
+			ts.StringID(fmt.Sprintf("foo%04d", i)),
+			bytesRefd(data),
+			digest.Checksum(data)))
+	}
+	assert.NoError(t, w.Close())
+
+	s := newTestSeeker(filePathPrefix)
+	err = s.Open(testNamespaceID, 0, testWriterStart)
+	assert.NoError(t, err)
+	assert.Equal(t, numEntries, s.Entries())
+	assert.Equal(t, 4, len(s.(*seeker).summaries))
+
+	for i := 0; i < numEntries; i++ {
+		id := ts.StringID(fmt.Sprintf("foo%04d", i))
+		assert.True(t, s.SeekOffset(id) >= 0)
+
+		data, err := s.Seek(id)
+		require.NoError(t, err)
+		data.IncRef()
+		assert.Equal(t, []byte{byte(i), byte(i >> 8)}, data.Get())
+		data.DecRef()
+	}
+
+	for _, id := range []string{"bar", "foo0000a", "foo0100a", "zoo"} {
+		assert.Equal(t, -1, s.SeekOffset(ts.StringID(id)))
+		_, err := s.Seek(ts.StringID(id))
+		assert.Equal(t, errSeekIDNotFound, err)
+	}
+
+	assert.NoError(t, s.Close())
+}
+
+func TestSeekBaselineFileset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	// Filesets without summaries or a bloom filter have their index scanned
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", []byte{4, 5, 6}},
+		{"baz", []byte{7, 8, 9}},
+	}
+	writeBaselineTestData(t, filePathPrefix, 0, testWriterStart, entries)
+
+	s := newTestSeeker(filePathPrefix)
+	err = s.Open(testNamespaceID, 0, testWriterStart)
+	require.NoError(t, err)
+	assert.Equal(t, len(entries), s.Entries())
+	assert.Equal(t, len(entries), len(s.IDs()))
+	assert.Equal(t, 0, len(s.(*seeker).summaries))
+
+	for _, entry := range entries {
+		data, err := s.Seek(ts.StringID(entry.id))
+		require.NoError(t, err)
+		data.IncRef()
+		assert.Equal(t, entry.data, data.Get())
+		data.DecRef()
+	}
+
+	assert.Equal(t, -1, s.SeekOffset(ts.StringID("qux")))
+	_, err = s.Seek(ts.StringID("qux"))
+	assert.Equal(t, errSeekIDNotFound, err)
+
+	assert.NoError(t, s.Close())
+}
+
+func TestSeekMmap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePathPrefix := filepath.Join(dir, "")
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(filePathPrefix)
+	err = w.Open(testNamespaceID, 0, testWriterStart)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write(
+		ts.StringID("foo1"),
+		bytesRefd([]byte{1, 2, 1}),
+		digest.Checksum([]byte{1, 2, 1})))
+	assert.NoError(t, w.Write(
+		ts.StringID("foo2"),
+		bytesRefd([]byte{1, 2, 2}),
+		digest.Checksum([]byte{1, 2, 2})))
+	assert.NoError(t, w.Close())
+
+	s := newSeeker(seekerOpts{
+		filePathPrefixes: []string{filePathPrefix},
+		bufferSize:       testReaderBufferSize,
+		keepIndexIDs:     true,
+		mmapEnabled:      true,
+	}).(*seeker)
+	err = s.Open(testNamespaceID, 0, testWriterStart)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(s.IDs()))
+
+	data, err := s.Seek(ts.StringID("foo2"))
+	require.NoError(t, err)
+	data.IncRef()
+	assert.Equal(t, []byte{1, 2, 2}, data.Get())
+
+	_, err = s.Seek(ts.StringID("foo"))
+	assert.Equal(t, errSeekIDNotFound, err)
+
+	// Data remains mapped after the seeker is closed until it is finalized
+	region := s.dataRegion
+	assert.NoError(t, s.Close())
+	assert.Equal(t, []byte{1, 2, 2}, data.Get())
+	data.DecRef()
+	data.Finalize()
+	assert.Nil(t, region.Bytes())
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m3db/m3x/time"
+)
+
+const (
+	snapshotsDirName    = "snapshots"
+	snapshotMetadataLen = 16
+)
+
+var (
+	errSnapshotMetadataCorrupt = errors.New("snapshot metadata file is corrupt")
+)
+
+// SnapshotMetadata describes a complete snapshot of the in-memory data.
+type SnapshotMetadata struct {
+	// Index is the index of the snapshot
+	Index int
+
+	// CommitLogStart is the start of the first commit log file not covered by the snapshot
+	CommitLogStart time.Time
+
+	// CommitLogIndex is the index of the first commit log file not covered by the snapshot
+	CommitLogIndex int
+}
+
+// Covers returns whether the snapshot covers the commit log file with the given start and index.
+func (m SnapshotMetadata) Covers(commitLogStart time.Time, commitLogIndex int) bool {
+	if commitLogStart.Equal(m.CommitLogStart) {
+		return commitLogIndex < m.CommitLogIndex
+	}
+	return commitLogStart.Before(m.CommitLogStart)
+}
+
+// SnapshotsDirPath returns the path to snapshots.
+func SnapshotsDirPath(prefix string) string {
+	return path.Join(prefix, snapshotsDirName)
+}
+
+// SnapshotDirPath returns the file path prefix of the filesets of a snapshot.
+func SnapshotDirPath(prefix string, index int) string {
+	return path.Join(SnapshotsDirPath(prefix), strconv.Itoa(index))
+}
+
+func snapshotFilePath(prefix string, index int) string {
+	fileName := fmt.Sprintf("%s%s%d%s", snapshotFilePrefix, separator, index, fileSuffix)
+	return path.Join(SnapshotsDirPath(prefix), fileName)
+}
+
+func snapshotIndexFromFileName(fname string) (int, error) {
+	str := strings.TrimPrefix(filepath.Base(fname), snapshotFilePrefix+separator)
+	return strconv.Atoi(strings.TrimSuffix(str, fileSuffix))
+}
+
+// NextSnapshotIndex returns the index of the next snapshot, which is greater
+// than the index of any snapshot started.
+func NextSnapshotIndex(prefix string) (int, error) {
+	entries, err := ioutil.ReadDir(SnapshotsDirPath(prefix))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	next := 0
+	for _, entry := range entries {
+		var (
+			index int
+			err   error
+		)
+		if entry.IsDir() {
+			index, err = strconv.Atoi(entry.Name())
+		} else {
+			index, err = snapshotIndexFromFileName(entry.Name())
+		}
+		if err == nil && index >= next {
+			next = index + 1
+		}
+	}
+	return next, nil
+}
+
+// WriteSnapshotMetadata completes a snapshot by writing its metadata file,
+// the file is written to a temporary path and renamed into place.
+func WriteSnapshotMetadata(
+	prefix string,
+	metadata SnapshotMetadata,
+	newFileMode os.FileMode,
+	newDirectoryMode os.FileMode,
+) error {
+	if err := os.MkdirAll(SnapshotsDirPath(prefix), newDirectoryMode); err != nil {
+		return err
+	}
+	filePath := snapshotFilePath(prefix, metadata.Index)
+	tmpFilePath := filePath + tmpFileSuffix
+	fd, err := OpenWritable(tmpFilePath, newFileMode)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, snapshotMetadataLen)
+	binary.LittleEndian.PutUint64(data[:8], uint64(metadata.CommitLogStart.UnixNano()))
+	binary.LittleEndian.PutUint64(data[8:], uint64(metadata.CommitLogIndex))
+	_, err = fd.Write(data)
+	if err == nil {
+		err = fd.Sync()
+	}
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, filePath)
+}
+
+// LatestSnapshotMetadata returns the metadata of the latest complete snapshot
+// and whether any snapshot is complete.
+func LatestSnapshotMetadata(prefix string) (SnapshotMetadata, bool, error) {
+	indexes, err := completeSnapshotIndexes(prefix)
+	if err != nil || len(indexes) == 0 {
+		return SnapshotMetadata{}, false, err
+	}
+	index := indexes[len(indexes)-1]
+	data, err := ioutil.ReadFile(snapshotFilePath(prefix, index))
+	if err != nil {
+		return SnapshotMetadata{}, false, err
+	}
+	if len(data) != snapshotMetadataLen {
+		return SnapshotMetadata{}, false, errSnapshotMetadataCorrupt
+	}
+	return SnapshotMetadata{
+		Index:          index,
+		CommitLogStart: xtime.FromNanoseconds(int64(binary.LittleEndian.Uint64(data[:8]))),
+		CommitLogIndex: int(binary.LittleEndian.Uint64(data[8:])),
+	}, true, nil
+}
+
+// SupersededSnapshots returns the paths of the snapshots older than the
+// latest complete snapshot along with their metadata files.
+func SupersededSnapshots(prefix string) ([]string, error) {
+	indexes, err := completeSnapshotIndexes(prefix)
+	if err != nil || len(indexes) == 0 {
+		return nil, err
+	}
+	latest := indexes[len(indexes)-1]
+
+	entries, err := ioutil.ReadDir(SnapshotsDirPath(prefix))
+	if err != nil {
+		return nil, err
+	}
+	var superseded []string
+	for _, entry := range entries {
+		var index int
+		if entry.IsDir() {
+			index, err = strconv.Atoi(entry.Name())
+		} else {
+			index, err = snapshotIndexFromFileName(entry.Name())
+		}
+		if err == nil && index < latest {
+			superseded = append(superseded, path.Join(SnapshotsDirPath(prefix), entry.Name()))
+		}
+	}
+	return superseded, nil
+}
+
+// completeSnapshotIndexes returns the indexes of the snapshots with a
+// metadata file in ascending order.
+func completeSnapshotIndexes(prefix string) ([]int, error) {
+	matched, err := filepath.Glob(path.Join(SnapshotsDirPath(prefix), snapshotFilePattern))
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]int, 0, len(matched))
+	for _, f := range matched {
+		index, err := snapshotIndexFromFileName(f)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes, nil
+}
+
+// CommitLogFilesCoveredBy returns all the commit log files covered by a snapshot.
+func CommitLogFilesCoveredBy(commitLogsDir string, snapshot SnapshotMetadata) ([]string, error) {
+	commitLogs, err := CommitLogFiles(commitLogsDir)
+	if err != nil {
+		return nil, err
+	}
+	// Matched files are sorted by their start and index in ascending order
+	var covered []string
+	for _, f := range commitLogs {
+		t, index, err := TimeAndIndexFromFileName(f)
+		if err != nil {
+			return nil, err
+		}
+		if !snapshot.Covers(t, index) {
+			break
+		}
+		covered = append(covered, f)
+	}
+	return covered, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotMetadataCovers(t *testing.T) {
+	start := time.Unix(0, 7200)
+	metadata := SnapshotMetadata{CommitLogStart: start, CommitLogIndex: 2}
+	require.True(t, metadata.Covers(start.Add(-time.Hour), 5))
+	require.True(t, metadata.Covers(start, 1))
+	require.False(t, metadata.Covers(start, 2))
+	require.False(t, metadata.Covers(start.Add(time.Hour), 0))
+}
+
+func TestWriteAndReadSnapshotMetadata(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	_, exists, err := LatestSnapshotMetadata(dir)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	index, err := NextSnapshotIndex(dir)
+	require.NoError(t, err)
+	require.Equal(t, 0, index)
+
+	for i := 0; i < 2; i++ {
+		metadata := SnapshotMetadata{
+			Index:          i,
+			CommitLogStart: time.Unix(0, int64(i)),
+			CommitLogIndex: i + 1,
+		}
+		require.NoError(t, WriteSnapshotMetadata(dir, metadata, 0666, 0755))
+
+		latest, exists, err := LatestSnapshotMetadata(dir)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, metadata.Index, latest.Index)
+		require.True(t, metadata.CommitLogStart.Equal(latest.CommitLogStart))
+		require.Equal(t, metadata.CommitLogIndex, latest.CommitLogIndex)
+	}
+
+	// Snapshots started but not complete are still skipped over
+	require.NoError(t, os.MkdirAll(SnapshotDirPath(dir, 4), 0755))
+	index, err = NextSnapshotIndex(dir)
+	require.NoError(t, err)
+	require.Equal(t, 5, index)
+
+	latest, _, err := LatestSnapshotMetadata(dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, latest.Index)
+}
+
+func TestSupersededSnapshots(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.MkdirAll(SnapshotDirPath(dir, i), 0755))
+	}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, WriteSnapshotMetadata(dir, SnapshotMetadata{Index: i}, 0666, 0755))
+	}
+
+	superseded, err := SupersededSnapshots(dir)
+	require.NoError(t, err)
+	snapshotsDir := SnapshotsDirPath(dir)
+	require.Equal(t, []string{
+		path.Join(snapshotsDir, "0"),
+		path.Join(snapshotsDir, "snapshot-0.db"),
+	}, superseded)
+
+	require.NoError(t, DeletePaths(superseded))
+	latest, exists, err := LatestSnapshotMetadata(dir)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, 1, latest.Index)
+	require.True(t, FileExists(SnapshotDirPath(dir, 2)))
+}
+
+func TestCommitLogFilesCoveredBy(t *testing.T) {
+	iter := 5
+	perSlot := 3
+	dir := createCommitLogFiles(t, iter, perSlot)
+	defer os.RemoveAll(dir)
+
+	files, err := CommitLogFilesCoveredBy(CommitLogsDirPath(dir), SnapshotMetadata{
+		CommitLogStart: time.Unix(0, 2),
+		CommitLogIndex: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2*perSlot+1, len(files))
+	for i := 0; i < 2; i++ {
+		for j := 0; j < perSlot; j++ {
+			validateCommitLogFiles(t, i, j, perSlot, i, dir, files)
+		}
+	}
+	validateCommitLogFiles(t, 2, 0, perSlot, 2, dir, files)
+}
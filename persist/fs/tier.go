@@ -0,0 +1,315 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/m3db/m3db/persist/encoding/msgpack"
+	"github.com/m3db/m3db/persist/schema"
+	"github.com/m3db/m3db/ratelimit"
+	"github.com/m3db/m3db/ts"
+)
+
+// filesetFileSuffixes are the suffixes of the files of a fileset version in
+// the order they are moved, the checkpoint file is last so a moved version
+// is only visible once complete
+var filesetFileSuffixes = []string{
+	infoFileSuffix,
+	indexFileSuffix,
+	summariesFileSuffix,
+	bloomFilterFileSuffix,
+	dataFileSuffix,
+	digestFileSuffix,
+	checkpointFileSuffix,
+}
+
+// optionalFilesetFileSuffixes are the suffixes of the files of a fileset
+// version that filesets written by earlier versions may not have
+var optionalFilesetFileSuffixes = map[string]struct{}{
+	summariesFileSuffix:   {},
+	bloomFilterFileSuffix: {},
+}
+
+// TierFilePathPrefixes returns the file path prefixes of all tiers that
+// filesets are searched in, the file path prefix filesets are written to
+// is first and takes precedence over the secondary tier.
+func TierFilePathPrefixes(filePathPrefix string, policy TieringPolicy) []string {
+	if !policy.Enabled() || policy.FilePathPrefix == filePathPrefix {
+		return []string{filePathPrefix}
+	}
+	return []string{filePathPrefix, policy.FilePathPrefix}
+}
+
+// FilesetTierFilePathPrefix returns the file path prefix of the first tier
+// with a complete fileset for the given namespace, shard and block start.
+func FilesetTierFilePathPrefix(
+	filePathPrefixes []string,
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+) (string, bool) {
+	for _, prefix := range filePathPrefixes {
+		if FilesetExistsAt(prefix, namespace, shard, blockStart) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// FilesetExistsAtAnyTier determines whether a complete fileset exists in any
+// tier for the given namespace, shard and block start.
+func FilesetExistsAtAnyTier(
+	filePathPrefixes []string,
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+) bool {
+	_, ok := FilesetTierFilePathPrefix(filePathPrefixes, namespace, shard, blockStart)
+	return ok
+}
+
+// ReadTierInfoFiles reads all the valid info entries of all tiers, the info
+// entry of a block start found in an earlier tier takes precedence.
+func ReadTierInfoFiles(
+	filePathPrefixes []string,
+	namespace ts.ID,
+	shard uint32,
+	readerBufferSize int,
+	decodingOpts msgpack.DecodingOptions,
+) []schema.IndexInfo {
+	if len(filePathPrefixes) == 1 {
+		return ReadInfoFiles(filePathPrefixes[0], namespace, shard, readerBufferSize, decodingOpts)
+	}
+
+	var (
+		indexEntries []schema.IndexInfo
+		seen         = make(map[int64]struct{})
+	)
+	for _, prefix := range filePathPrefixes {
+		for _, info := range ReadInfoFiles(prefix, namespace, shard, readerBufferSize, decodingOpts) {
+			if _, ok := seen[info.Start]; ok {
+				continue
+			}
+			seen[info.Start] = struct{}{}
+			indexEntries = append(indexEntries, info)
+		}
+	}
+	sort.Sort(indexInfosByStartAsc(indexEntries))
+	return indexEntries
+}
+
+type indexInfosByStartAsc []schema.IndexInfo
+
+func (e indexInfosByStartAsc) Len() int           { return len(e) }
+func (e indexInfosByStartAsc) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e indexInfosByStartAsc) Less(i, j int) bool { return e[i].Start < e[j].Start }
+
+// TierFilesetBlockStarts returns the block starts of the complete filesets
+// in all tiers for the given namespace and shard in ascending order.
+func TierFilesetBlockStarts(
+	filePathPrefixes []string,
+	namespace ts.ID,
+	shard uint32,
+) ([]time.Time, error) {
+	if len(filePathPrefixes) == 1 {
+		return FilesetBlockStarts(filePathPrefixes[0], namespace, shard)
+	}
+
+	var (
+		blockStarts []time.Time
+		seen        = make(map[int64]struct{})
+	)
+	for _, prefix := range filePathPrefixes {
+		tierBlockStarts, err := FilesetBlockStarts(prefix, namespace, shard)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tierBlockStarts {
+			if _, ok := seen[t.UnixNano()]; ok {
+				continue
+			}
+			seen[t.UnixNano()] = struct{}{}
+			blockStarts = append(blockStarts, t)
+		}
+	}
+	sort.Sort(timesAsc(blockStarts))
+	return blockStarts, nil
+}
+
+type timesAsc []time.Time
+
+func (t timesAsc) Len() int           { return len(t) }
+func (t timesAsc) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t timesAsc) Less(i, j int) bool { return t[i].Before(t[j]) }
+
+// SupersededTierFilesets returns all the fileset files in a tier for block
+// starts with a complete fileset in an earlier tier, such as when a block
+// moved to a secondary tier has since been rewritten.
+func SupersededTierFilesets(
+	filePathPrefixes []string,
+	namespace ts.ID,
+	shard uint32,
+) ([]string, error) {
+	var superseded []string
+	for i := 1; i < len(filePathPrefixes); i++ {
+		matched, err := filesetFiles(filePathPrefixes[i], namespace, shard, filesetFilePattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range matched {
+			t, err := TimeFromFileName(f)
+			if err != nil {
+				continue
+			}
+			if FilesetExistsAtAnyTier(filePathPrefixes[:i], namespace, shard, t) {
+				superseded = append(superseded, f)
+			}
+		}
+	}
+	return superseded, nil
+}
+
+// MoveFileset moves the latest complete version of the fileset for the given
+// namespace, shard and block start from one tier to another. The files are
+// copied as the next version in the destination tier, with the checkpoint file
+// copied last, before all versions in the source tier are removed. The copy is
+// rate limited the same as flushes are.
+func MoveFileset(
+	srcFilePathPrefix string,
+	dstFilePathPrefix string,
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	newFileMode os.FileMode,
+	newDirectoryMode os.FileMode,
+	rateLimitOpts ratelimit.Options,
+) error {
+	srcVersion, exists := LatestFilesetVersion(srcFilePathPrefix, namespace, shard, blockStart)
+	if !exists {
+		return errCheckpointFileNotFound
+	}
+	dstVersion := 0
+	if latest, exists := LatestFilesetVersion(dstFilePathPrefix, namespace, shard, blockStart); exists {
+		dstVersion = latest + 1
+	}
+
+	srcDir := ShardDirPath(srcFilePathPrefix, namespace, shard)
+	dstDir := ShardDirPath(dstFilePathPrefix, namespace, shard)
+	if err := os.MkdirAll(dstDir, newDirectoryMode); err != nil {
+		return err
+	}
+	limiter := newRateLimitedCopier(rateLimitOpts)
+	for _, suffix := range filesetFileSuffixes {
+		src := filesetPathFromTimeAndVersion(srcDir, blockStart, srcVersion, suffix)
+		if _, optional := optionalFilesetFileSuffixes[suffix]; optional && !FileExists(src) {
+			continue
+		}
+		dst := filesetPathFromTimeAndVersion(dstDir, blockStart, dstVersion, suffix)
+		if err := copyFile(src, dst, newFileMode, limiter); err != nil {
+			return err
+		}
+	}
+
+	var moved []string
+	for _, template := range []string{filesetFileForTimeTemplate, unversionedFileForTimeTemplate} {
+		pattern := fmt.Sprintf(template, blockStart.UnixNano())
+		matched, err := filesetFiles(srcFilePathPrefix, namespace, shard, pattern)
+		if err != nil {
+			return err
+		}
+		moved = append(moved, matched...)
+	}
+	return DeleteFiles(moved)
+}
+
+// copyFile copies a file to a temporary path that is synced and renamed into
+// place so the destination file is only ever visible once complete.
+func copyFile(src, dst string, newFileMode os.FileMode, limiter *rateLimitedCopier) error {
+	srcFd, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFd.Close()
+
+	tmpFilePath := dst + tmpFileSuffix
+	dstFd, err := OpenWritable(tmpFilePath, newFileMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(limiter.Writer(dstFd), srcFd)
+	if err == nil {
+		err = dstFd.Sync()
+	}
+	if closeErr := dstFd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return err
+	}
+	return os.Rename(tmpFilePath, dst)
+}
+
+// rateLimitedCopier limits the rate bytes are copied across all the files of
+// a fileset being moved, sleeping between writes once ahead of the limit.
+type rateLimitedCopier struct {
+	opts    ratelimit.Options
+	start   time.Time
+	written int64
+}
+
+func newRateLimitedCopier(opts ratelimit.Options) *rateLimitedCopier {
+	return &rateLimitedCopier{opts: opts}
+}
+
+// Writer returns a writer that writes to the destination at the rate limit.
+func (c *rateLimitedCopier) Writer(w io.Writer) io.Writer {
+	if c.opts == nil || !c.opts.LimitEnabled() || c.opts.LimitMbps() <= 0.0 {
+		return w
+	}
+	return rateLimitedWriter{copier: c, writer: w}
+}
+
+type rateLimitedWriter struct {
+	copier *rateLimitedCopier
+	writer io.Writer
+}
+
+func (w rateLimitedWriter) Write(p []byte) (int, error) {
+	c := w.copier
+	now := time.Now()
+	if c.start.IsZero() {
+		c.start = now
+	} else {
+		target := time.Duration(float64(time.Second) * float64(c.written) / float64(c.opts.LimitMbps()*bytesPerMegabit))
+		if elapsed := now.Sub(c.start); elapsed < target {
+			time.Sleep(target - elapsed)
+		}
+	}
+	n, err := w.writer.Write(p)
+	c.written += int64(n)
+	return n, err
+}
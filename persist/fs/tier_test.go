@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTierFilePathPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"/a"}, TierFilePathPrefixes("/a", TieringPolicy{}))
+	assert.Equal(t, []string{"/a"}, TierFilePathPrefixes("/a", TieringPolicy{FilePathPrefix: "/b"}))
+	assert.Equal(t, []string{"/a"}, TierFilePathPrefixes("/a", TieringPolicy{FilePathPrefix: "/a", Age: time.Hour}))
+	assert.Equal(t, []string{"/a", "/b"}, TierFilePathPrefixes("/a", TieringPolicy{FilePathPrefix: "/b", Age: time.Hour}))
+}
+
+func TestMoveFileset(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		primary   = filepath.Join(dir, "primary")
+		secondary = filepath.Join(dir, "secondary")
+		prefixes  = []string{primary, secondary}
+		entries   = []testEntry{
+			{"foo", []byte{1, 2, 3}},
+			{"bar", []byte{4, 5, 6}},
+		}
+	)
+	writeTestData(t, newTestWriter(primary), 0, testWriterStart, entries)
+
+	require.NoError(t, MoveFileset(primary, secondary, testNamespaceID, 0, testWriterStart,
+		defaultNewFileMode, defaultNewDirectoryMode, ratelimit.NewOptions()))
+	assert.False(t, FilesetExistsAt(primary, testNamespaceID, 0, testWriterStart))
+	files, err := filesetFiles(primary, testNamespaceID, 0, filesetFilePattern)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(files))
+
+	prefix, ok := FilesetTierFilePathPrefix(prefixes, testNamespaceID, 0, testWriterStart)
+	require.True(t, ok)
+	assert.Equal(t, secondary, prefix)
+	readTestData(t, NewTieredReader(prefixes, testReaderBufferSize, nil, nil), 0, testWriterStart, entries)
+
+	// Moving a rewritten fileset again is copied as the next version
+	rewritten := []testEntry{{"baz", []byte{7, 8, 9}}}
+	writeTestData(t, newTestWriter(primary), 0, testWriterStart, rewritten)
+	readTestData(t, NewTieredReader(prefixes, testReaderBufferSize, nil, nil), 0, testWriterStart, rewritten)
+
+	require.NoError(t, MoveFileset(primary, secondary, testNamespaceID, 0, testWriterStart,
+		defaultNewFileMode, defaultNewDirectoryMode, ratelimit.NewOptions()))
+	version, ok := LatestFilesetVersion(secondary, testNamespaceID, 0, testWriterStart)
+	require.True(t, ok)
+	assert.Equal(t, 1, version)
+	readTestData(t, NewTieredReader(prefixes, testReaderBufferSize, nil, nil), 0, testWriterStart, rewritten)
+}
+
+func TestMoveFilesetWithoutOptionalFiles(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		primary   = filepath.Join(dir, "primary")
+		secondary = filepath.Join(dir, "secondary")
+		entries   = []testEntry{{"foo", []byte{1, 2, 3}}}
+	)
+	writeTestData(t, newTestWriter(primary), 0, testWriterStart, entries)
+
+	// Filesets written before summaries and bloom filters are still moved
+	shardDir := ShardDirPath(primary, testNamespaceID, 0)
+	for suffix := range optionalFilesetFileSuffixes {
+		require.NoError(t, os.Remove(filesetPathFromTimeAndVersion(shardDir, testWriterStart, 0, suffix)))
+	}
+
+	require.NoError(t, MoveFileset(primary, secondary, testNamespaceID, 0, testWriterStart,
+		defaultNewFileMode, defaultNewDirectoryMode, ratelimit.NewOptions()))
+	assert.True(t, FilesetExistsAt(secondary, testNamespaceID, 0, testWriterStart))
+	assert.False(t, FilesetExistsAt(primary, testNamespaceID, 0, testWriterStart))
+}
+
+func TestTierFilesetsSuperseded(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		primary   = filepath.Join(dir, "primary")
+		secondary = filepath.Join(dir, "secondary")
+		prefixes  = []string{primary, secondary}
+		first     = testWriterStart
+		second    = testWriterStart.Add(testBlockSize)
+		entries   = []testEntry{{"foo", []byte{1, 2, 3}}}
+	)
+	writeTestData(t, newTestWriter(primary), 0, first, entries)
+	writeTestData(t, newTestWriter(secondary), 0, first, entries)
+	writeTestData(t, newTestWriter(secondary), 0, second, entries)
+
+	blockStarts, err := TierFilesetBlockStarts(prefixes, testNamespaceID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(blockStarts))
+	assert.True(t, blockStarts[0].Equal(first))
+	assert.True(t, blockStarts[1].Equal(second))
+
+	infos := ReadTierInfoFiles(prefixes, testNamespaceID, 0, testReaderBufferSize, nil)
+	require.Equal(t, 2, len(infos))
+	assert.Equal(t, first.UnixNano(), infos[0].Start)
+	assert.Equal(t, second.UnixNano(), infos[1].Start)
+
+	superseded, err := SupersededTierFilesets(prefixes, testNamespaceID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, len(filesetFileSuffixes), len(superseded))
+	shardDir := ShardDirPath(secondary, testNamespaceID, 0)
+	for _, f := range superseded {
+		assert.Equal(t, shardDir, filepath.Dir(f))
+		fileStart, err := TimeFromFileName(f)
+		require.NoError(t, err)
+		assert.True(t, fileStart.Equal(first))
+	}
+}
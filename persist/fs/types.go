@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
@@ -64,6 +65,7 @@ type FileSetReader interface {
 	Read() (id ts.ID, data checked.Bytes, checksum uint32, err error)
 
 	// ReadMetadata returns the next id and metadata or error, will return io.EOF at end of volume.
+	// The length and checksum of compressed volumes are those of the compressed data.
 	// Use either Read or ReadMetadata to progress through a volume, but not both.
 	ReadMetadata() (id ts.ID, length int, checksum uint32, err error)
 
@@ -80,6 +82,18 @@ type FileSetReader interface {
 	EntriesRead() int
 }
 
+// IndexSegmentWriter provides an unsynchronized writer for the series
+// and tags indexed for a block of a namespace
+type IndexSegmentWriter interface {
+	io.Closer
+
+	// Open opens the files for writing the index segment of the given namespace
+	Open(namespace ts.ID, blockStart time.Time) error
+
+	// Write will write the id and tags of a series and returns an error on a write error
+	Write(id ts.ID, tags ts.Tags) error
+}
+
 // FileSetSeeker provides an out of order reader for a TSDB file set
 type FileSetSeeker interface {
 	io.Closer
@@ -87,12 +101,12 @@ type FileSetSeeker interface {
 	// Open opens the files for the given shard and version for reading
 	Open(namespace ts.ID, shard uint32, start time.Time) error
 
-	// Seek returns the data for specified ID provided the index was loaded upon open. An
-	// error will be returned if the index was not loaded or ID cannot be found.
+	// Seek returns the data for specified ID, looking up its index entry with the bloom
+	// filter and summaries loaded upon open. An error will be returned if the ID cannot be found.
 	Seek(id ts.ID) (data checked.Bytes, err error)
 
-	// SeekOffset returns the offset for specified ID provided the index was loaded upon open. If
-	// the index was not loaded or ID cannot be found the value -1 will be returned.
+	// SeekOffset returns the offset for specified ID. If the ID cannot be found
+	// the value -1 will be returned.
 	// This can be helpful ahead of issuing a number of seek requests so that the seek
 	// requests can be made in order.
 	SeekOffset(id ts.ID) int
@@ -112,7 +126,7 @@ type FileSetSeekerManager interface {
 	io.Closer
 
 	// Open opens the seekers for a given namespace.
-	Open(namespace ts.ID) error
+	Open(nsMetadata namespace.Metadata) error
 
 	// CacheShardIndices will pre-parse the indexes for given shards
 	// to improve times when seeking to a block.
@@ -128,7 +142,7 @@ type BlockRetriever interface {
 	block.DatabaseBlockRetriever
 
 	// Open the block retriever to retrieve from a namespace
-	Open(namespace ts.ID) error
+	Open(nsMetadata namespace.Metadata) error
 }
 
 // RetrievableBlockSegmentReader is a retrievable block reader
@@ -197,6 +211,42 @@ type Options interface {
 
 	// ReaderBufferSize returns the buffer size for reading TSDB files
 	ReaderBufferSize() int
+
+	// SetMmapEnabled sets whether the block retriever memory maps the data
+	// and index files of filesets instead of reading them with buffered reads
+	SetMmapEnabled(value bool) Options
+
+	// MmapEnabled returns whether the block retriever memory maps the data
+	// and index files of filesets instead of reading them with buffered reads
+	MmapEnabled() bool
+
+	// SetCompression sets the codec used to compress the data of each series in new filesets
+	SetCompression(value CompressionType) Options
+
+	// Compression returns the codec used to compress the data of each series in new filesets
+	Compression() CompressionType
+
+	// SetTieringPolicy sets the policy for moving old filesets to a secondary tier
+	SetTieringPolicy(value TieringPolicy) Options
+
+	// TieringPolicy returns the policy for moving old filesets to a secondary tier
+	TieringPolicy() TieringPolicy
+}
+
+// TieringPolicy is a policy for moving the filesets of blocks older than an
+// age from the file path prefix to the file path prefix of a secondary tier,
+// e.g. from a fast but small volume to a large but slow volume.
+type TieringPolicy struct {
+	// FilePathPrefix is the file path prefix of the secondary tier
+	FilePathPrefix string
+
+	// Age is the age of the end of a block after which its fileset is moved
+	Age time.Duration
+}
+
+// Enabled returns whether filesets are moved to a secondary tier
+func (p TieringPolicy) Enabled() bool {
+	return p.FilePathPrefix != "" && p.Age > 0
 }
 
 // BlockRetrieverOptions represents the options for block retrieval
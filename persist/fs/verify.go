@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/ts"
+)
+
+// ErrEntryChecksumMismatch is returned when the data of a fileset entry
+// does not match the checksum recorded for it in the index
+type ErrEntryChecksumMismatch struct {
+	ID               string
+	ExpectedChecksum uint32
+	ActualChecksum   uint32
+}
+
+func (e ErrEntryChecksumMismatch) Error() string {
+	return fmt.Sprintf("entry %s expected checksum %d but found checksum %d",
+		e.ID, e.ExpectedChecksum, e.ActualChecksum)
+}
+
+// VerifyReadFn is called with the length of the data of each entry read
+// while verifying a fileset.
+type VerifyReadFn func(length int)
+
+// VerifyFileset verifies the latest complete version of the fileset for the
+// given namespace, shard and block start. The info, index and digest files are
+// validated against their digests when the fileset is opened, then every entry
+// is read to validate its data against its checksum and the data file against
+// its digest. The read function is optional and can be used to rate limit.
+func VerifyFileset(
+	reader FileSetReader,
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	readFn VerifyReadFn,
+) error {
+	if err := reader.Open(namespace, shard, blockStart); err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		id, data, checksum, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		data.IncRef()
+		length := data.Len()
+		actual := digest.Checksum(data.Get())
+		data.DecRef()
+		data.Finalize()
+
+		if actual != checksum {
+			return ErrEntryChecksumMismatch{
+				ID:               id.String(),
+				ExpectedChecksum: checksum,
+				ActualChecksum:   actual,
+			}
+		}
+		if readFn != nil {
+			readFn(length)
+		}
+	}
+
+	return reader.Validate()
+}
+
+// FilesetBlockStarts returns the block starts of the complete filesets for
+// the given namespace and shard in ascending order.
+func FilesetBlockStarts(filePathPrefix string, namespace ts.ID, shard uint32) ([]time.Time, error) {
+	matched, err := filesetFiles(filePathPrefix, namespace, shard, checkpointFilePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Matched files are sorted by block start then version
+	var blockStarts []time.Time
+	for _, f := range matched {
+		t, err := TimeFromFileName(f)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(blockStarts); n > 0 && blockStarts[n-1].Equal(t) {
+			continue
+		}
+		blockStarts = append(blockStarts, t)
+	}
+	return blockStarts, nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package fs
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/ts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyFileset(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	entries := []testEntry{
+		{"foo", []byte{1, 2, 3}},
+		{"bar", []byte{4, 5, 6}},
+	}
+	w := newTestWriter(dir)
+	writeTestData(t, w, 0, testWriterStart, entries)
+
+	var read int
+	err := VerifyFileset(newTestReader(dir), testNamespaceID, 0, testWriterStart, func(length int) {
+		read += length
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 6, read)
+
+	blockStarts, err := FilesetBlockStarts(dir, testNamespaceID, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(blockStarts))
+	assert.True(t, blockStarts[0].Equal(testWriterStart))
+
+	// Flip a byte of the data file
+	dataFilePath := filesetPathFromTimeAndVersion(ShardDirPath(dir, testNamespaceID, 0),
+		testWriterStart, 0, dataFileSuffix)
+	data, err := ioutil.ReadFile(dataFilePath)
+	require.NoError(t, err)
+	data[len(data)-1]++
+	require.NoError(t, ioutil.WriteFile(dataFilePath, data, defaultNewFileMode))
+
+	err = VerifyFileset(newTestReader(dir), testNamespaceID, 0, testWriterStart, nil)
+	require.Error(t, err)
+	assert.Equal(t, ErrEntryChecksumMismatch{
+		ID:               "bar",
+		ExpectedChecksum: digest.Checksum([]byte{4, 5, 6}),
+		ActualChecksum:   digest.Checksum([]byte{4, 5, 7}),
+	}, err)
+}
+
+func TestVerifyFilesetChecksumMismatch(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(dir)
+	require.NoError(t, w.Open(testNamespaceID, 0, testWriterStart))
+	require.NoError(t, w.Write(ts.StringID("foo"), bytesRefd([]byte{1, 2, 3}), 42))
+	require.NoError(t, w.Close())
+
+	err := VerifyFileset(newTestReader(dir), testNamespaceID, 0, testWriterStart, nil)
+	_, ok := err.(ErrEntryChecksumMismatch)
+	assert.True(t, ok)
+}
+
+func TestVerifyFilesetCorruptIndex(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	w := newTestWriter(dir)
+	writeTestData(t, w, 0, testWriterStart, []testEntry{{"foo", []byte{1, 2, 3}}})
+
+	indexFilePath := filesetPathFromTimeAndVersion(ShardDirPath(dir, testNamespaceID, 0),
+		testWriterStart, 0, indexFileSuffix)
+	data, err := ioutil.ReadFile(indexFilePath)
+	require.NoError(t, err)
+	data[0]++
+	require.NoError(t, ioutil.WriteFile(indexFilePath, data, defaultNewFileMode))
+
+	assert.Error(t, VerifyFileset(newTestReader(dir), testNamespaceID, 0, testWriterStart, nil))
+}
@@ -21,7 +21,9 @@
 package fs
 
 import (
+	"bytes"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/m3db/m3db/digest"
@@ -38,22 +40,45 @@ type writer struct {
 	filePathPrefix   string
 	newFileMode      os.FileMode
 	newDirectoryMode os.FileMode
+	compression      CompressionType
 
 	infoFdWithDigest           digest.FdWithDigestWriter
 	indexFdWithDigest          digest.FdWithDigestWriter
+	summariesFdWithDigest      digest.FdWithDigestWriter
+	bloomFilterFdWithDigest    digest.FdWithDigestWriter
 	dataFdWithDigest           digest.FdWithDigestWriter
 	digestFdWithDigestContents digest.FdWithDigestContentsWriter
 	checkpointFilePath         string
+	shardDir                   string
 
-	start      time.Time
-	currIdx    int64
-	currOffset int64
-	encoder    encoding.Encoder
-	digestBuf  digest.Buffer
-	idxData    []byte
-	err        error
+	start        time.Time
+	version      int
+	currIdx      int64
+	currOffset   int64
+	encoder      encoding.Encoder
+	digestBuf    digest.Buffer
+	idxData      []byte
+	indexEntries indexEntries
+	summaries    int64
+	bloomFilter  *bloomFilter
+	compressor   *compressor
+	err          error
 }
 
+type indexEntry struct {
+	index    int64
+	id       []byte
+	size     int64
+	offset   int64
+	checksum uint32
+}
+
+type indexEntries []indexEntry
+
+func (e indexEntries) Len() int           { return len(e) }
+func (e indexEntries) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e indexEntries) Less(i, j int) bool { return bytes.Compare(e[i].id, e[j].id) < 0 }
+
 // NewWriter returns a new writer for a filePathPrefix
 func NewWriter(
 	blockSize time.Duration,
@@ -61,44 +86,65 @@ func NewWriter(
 	bufferSize int,
 	newFileMode os.FileMode,
 	newDirectoryMode os.FileMode,
+	compression CompressionType,
 ) FileSetWriter {
 	return &writer{
 		blockSize:                  blockSize,
 		filePathPrefix:             filePathPrefix,
 		newFileMode:                newFileMode,
 		newDirectoryMode:           newDirectoryMode,
+		compression:                compression,
 		infoFdWithDigest:           digest.NewFdWithDigestWriter(bufferSize),
 		indexFdWithDigest:          digest.NewFdWithDigestWriter(bufferSize),
+		summariesFdWithDigest:      digest.NewFdWithDigestWriter(bufferSize),
+		bloomFilterFdWithDigest:    digest.NewFdWithDigestWriter(bufferSize),
 		dataFdWithDigest:           digest.NewFdWithDigestWriter(bufferSize),
 		digestFdWithDigestContents: digest.NewFdWithDigestContentsWriter(bufferSize),
 		encoder:                    msgpack.NewEncoder(),
 		digestBuf:                  digest.NewBuffer(),
 		idxData:                    make([]byte, idxLen),
+		compressor:                 newCompressor(),
 	}
 }
 
 // Open initializes the internal state for writing to the given shard,
 // specifically creating the shard directory if it doesn't exist, and
 // opening / truncating files associated with that shard for writing.
+// If a complete fileset already exists for the block start then a new
+// version of the fileset is written, which supersedes the existing
+// version once its checkpoint file is written.
 func (w *writer) Open(namespace ts.ID, shard uint32, blockStart time.Time) error {
 	shardDir := ShardDirPath(w.filePathPrefix, namespace, shard)
 	if err := os.MkdirAll(shardDir, w.newDirectoryMode); err != nil {
 		return err
 	}
 	w.start = blockStart
+	w.version = 0
+	if latest, exists := LatestFilesetVersion(w.filePathPrefix, namespace, shard, blockStart); exists {
+		w.version = latest + 1
+	}
 	w.currIdx = 0
 	w.currOffset = 0
-	w.checkpointFilePath = filesetPathFromTime(shardDir, blockStart, checkpointFileSuffix)
+	for i := range w.indexEntries {
+		w.indexEntries[i] = indexEntry{}
+	}
+	w.indexEntries = w.indexEntries[:0]
+	w.summaries = 0
+	w.bloomFilter = nil
+	w.shardDir = shardDir
+	w.checkpointFilePath = w.filesetPath(checkpointFileSuffix)
 	w.err = nil
 
-	var infoFd, indexFd, dataFd, digestFd *os.File
+	var infoFd, indexFd, summariesFd, bloomFilterFd, dataFd, digestFd *os.File
 	if err := openFiles(
 		w.openWritable,
 		map[string]**os.File{
-			filesetPathFromTime(shardDir, blockStart, infoFileSuffix):   &infoFd,
-			filesetPathFromTime(shardDir, blockStart, indexFileSuffix):  &indexFd,
-			filesetPathFromTime(shardDir, blockStart, dataFileSuffix):   &dataFd,
-			filesetPathFromTime(shardDir, blockStart, digestFileSuffix): &digestFd,
+			w.filesetPath(infoFileSuffix):        &infoFd,
+			w.filesetPath(indexFileSuffix):       &indexFd,
+			w.filesetPath(summariesFileSuffix):   &summariesFd,
+			w.filesetPath(bloomFilterFileSuffix): &bloomFilterFd,
+			w.filesetPath(dataFileSuffix):        &dataFd,
+			w.filesetPath(digestFileSuffix):      &digestFd,
 		},
 	); err != nil {
 		return err
@@ -106,6 +152,8 @@ func (w *writer) Open(namespace ts.ID, shard uint32, blockStart time.Time) error
 
 	w.infoFdWithDigest.Reset(infoFd)
 	w.indexFdWithDigest.Reset(indexFd)
+	w.summariesFdWithDigest.Reset(summariesFd)
+	w.bloomFilterFdWithDigest.Reset(bloomFilterFd)
 	w.dataFdWithDigest.Reset(dataFd)
 	w.digestFdWithDigestContents.Reset(digestFd)
 
@@ -164,16 +212,25 @@ func (w *writer) writeAll(
 		return nil
 	}
 
-	entry := schema.IndexEntry{
-		Index:    w.currIdx,
-		ID:       id.Data().Get(),
-		Size:     size,
-		Offset:   w.currOffset,
-		Checksum: int64(checksum),
+	// The checksum of compressed data is that of the bytes stored so that
+	// corruption of the stored bytes is detected before decompressing them,
+	// the reader returns the checksum of the decompressed data to callers.
+	var compressed []byte
+	if w.compression == CompressionDeflate {
+		var err error
+		if compressed, err = w.compressor.Compress(data, size); err != nil {
+			return err
+		}
+		size = int64(len(compressed))
+		checksum = digest.Checksum(compressed)
 	}
-	w.encoder.Reset()
-	if err := w.encoder.EncodeIndexEntry(entry); err != nil {
-		return err
+
+	entry := indexEntry{
+		index:    w.currIdx,
+		id:       append([]byte(nil), id.Data().Get()...),
+		size:     size,
+		offset:   w.currOffset,
+		checksum: checksum,
 	}
 
 	if err := w.writeData(marker); err != nil {
@@ -183,27 +240,88 @@ func (w *writer) writeAll(
 	if err := w.writeData(w.idxData); err != nil {
 		return err
 	}
-	for _, d := range data {
-		if d == nil {
-			continue
-		}
-		if err := w.writeData(d.Get()); err != nil {
+	if compressed != nil {
+		if err := w.writeData(compressed); err != nil {
 			return err
 		}
+	} else {
+		for _, d := range data {
+			if d == nil {
+				continue
+			}
+			if err := w.writeData(d.Get()); err != nil {
+				return err
+			}
+		}
 	}
-	if _, err := w.indexFdWithDigest.WriteBytes(w.encoder.Bytes()); err != nil {
-		return err
-	}
+	w.indexEntries = append(w.indexEntries, entry)
 	w.currIdx++
 
 	return nil
 }
 
+// writeIndexRelatedFiles writes the index entries sorted by ID, along with
+// a summary of every Nth entry and a bloom filter of all the IDs.
+func (w *writer) writeIndexRelatedFiles() error {
+	sort.Sort(w.indexEntries)
+
+	m, k := bloomFilterParams(len(w.indexEntries), bloomFilterFalsePositiveRate)
+	w.bloomFilter = newBloomFilter(m, k)
+
+	var indexOffset int64
+	for i, entry := range w.indexEntries {
+		if i%indexSummariesInterval == 0 {
+			summary := schema.IndexSummary{
+				Index:            int64(i),
+				ID:               entry.id,
+				IndexEntryOffset: indexOffset,
+			}
+			w.encoder.Reset()
+			if err := w.encoder.EncodeIndexSummary(summary); err != nil {
+				return err
+			}
+			if _, err := w.summariesFdWithDigest.WriteBytes(w.encoder.Bytes()); err != nil {
+				return err
+			}
+			w.summaries++
+		}
+
+		w.encoder.Reset()
+		if err := w.encoder.EncodeIndexEntry(schema.IndexEntry{
+			Index:    entry.index,
+			ID:       entry.id,
+			Size:     entry.size,
+			Offset:   entry.offset,
+			Checksum: int64(entry.checksum),
+		}); err != nil {
+			return err
+		}
+		written, err := w.indexFdWithDigest.WriteBytes(w.encoder.Bytes())
+		if err != nil {
+			return err
+		}
+		indexOffset += int64(written)
+
+		w.bloomFilter.Add(entry.id)
+	}
+
+	_, err := w.bloomFilterFdWithDigest.WriteBytes(w.bloomFilter.Bytes())
+	return err
+}
+
 func (w *writer) close() error {
+	if err := w.writeIndexRelatedFiles(); err != nil {
+		return err
+	}
+
 	info := schema.IndexInfo{
-		Start:     xtime.ToNanoseconds(w.start),
-		BlockSize: int64(w.blockSize),
-		Entries:   w.currIdx,
+		Start:        xtime.ToNanoseconds(w.start),
+		BlockSize:    int64(w.blockSize),
+		Entries:      w.currIdx,
+		Summaries:    w.summaries,
+		BloomFilterM: w.bloomFilter.m,
+		BloomFilterK: w.bloomFilter.k,
+		Compression:  int64(w.compression),
 	}
 
 	w.encoder.Reset()
@@ -219,6 +337,8 @@ func (w *writer) close() error {
 		w.infoFdWithDigest.Digest().Sum32(),
 		w.indexFdWithDigest.Digest().Sum32(),
 		w.dataFdWithDigest.Digest().Sum32(),
+		w.summariesFdWithDigest.Digest().Sum32(),
+		w.bloomFilterFdWithDigest.Digest().Sum32(),
 	); err != nil {
 		return err
 	}
@@ -226,6 +346,8 @@ func (w *writer) close() error {
 	if err := closeAll(
 		w.infoFdWithDigest,
 		w.indexFdWithDigest,
+		w.summariesFdWithDigest,
+		w.bloomFilterFdWithDigest,
 		w.dataFdWithDigest,
 		w.digestFdWithDigestContents,
 	); err != nil {
@@ -253,16 +375,26 @@ func (w *writer) Close() error {
 	return nil
 }
 
+// writeCheckpointFile writes the checkpoint file to a temporary path and
+// renames it into place so the version is only ever visible once complete.
 func (w *writer) writeCheckpointFile() error {
-	fd, err := w.openWritable(w.checkpointFilePath)
+	tmpFilePath := w.checkpointFilePath + tmpFileSuffix
+	fd, err := w.openWritable(tmpFilePath)
 	if err != nil {
 		return err
 	}
-	defer fd.Close()
-	if err := w.digestBuf.WriteDigestToFile(fd, w.digestFdWithDigestContents.Digest().Sum32()); err != nil {
+	err = w.digestBuf.WriteDigestToFile(fd, w.digestFdWithDigestContents.Digest().Sum32())
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
 		return err
 	}
-	return nil
+	return os.Rename(tmpFilePath, w.checkpointFilePath)
+}
+
+func (w *writer) filesetPath(suffix string) string {
+	return filesetPathFromTimeAndVersion(w.shardDir, w.start, w.version, suffix)
 }
 
 func (w *writer) openWritable(filePath string) (*os.File, error) {
@@ -26,6 +26,7 @@ package persist
 import (
 	time "time"
 
+	retention "github.com/m3db/m3db/retention"
 	ts "github.com/m3db/m3db/ts"
 
 	gomock "github.com/golang/mock/gomock"
@@ -84,15 +85,26 @@ func (_m *MockFlush) EXPECT() *_MockFlushRecorder {
 	return _m.recorder
 }
 
-func (_m *MockFlush) Prepare(namespace ts.ID, shard uint32, blockStart time.Time) (PreparedPersist, error) {
-	ret := _m.ctrl.Call(_m, "Prepare", namespace, shard, blockStart)
+func (_m *MockFlush) Prepare(namespace ts.ID, shard uint32, blockStart time.Time, ropts retention.Options) (PreparedPersist, error) {
+	ret := _m.ctrl.Call(_m, "Prepare", namespace, shard, blockStart, ropts)
 	ret0, _ := ret[0].(PreparedPersist)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (_mr *_MockFlushRecorder) Prepare(arg0, arg1, arg2 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "Prepare", arg0, arg1, arg2)
+func (_mr *_MockFlushRecorder) Prepare(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Prepare", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockFlush) PrepareRewrite(namespace ts.ID, shard uint32, blockStart time.Time, ropts retention.Options) (PreparedPersist, error) {
+	ret := _m.ctrl.Call(_m, "PrepareRewrite", namespace, shard, blockStart, ropts)
+	ret0, _ := ret[0].(PreparedPersist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockFlushRecorder) PrepareRewrite(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "PrepareRewrite", arg0, arg1, arg2, arg3)
 }
 
 func (_m *MockFlush) Done() error {
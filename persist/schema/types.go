@@ -22,9 +22,13 @@ package schema
 
 // IndexInfo stores metadata information about block filesets
 type IndexInfo struct {
-	Start     int64
-	BlockSize int64
-	Entries   int64
+	Start        int64
+	BlockSize    int64
+	Entries      int64
+	Summaries    int64
+	BloomFilterM uint64
+	BloomFilterK uint64
+	Compression  int64
 }
 
 // IndexEntry stores entry-level data for easier indexing
@@ -36,6 +40,25 @@ type IndexEntry struct {
 	Checksum int64
 }
 
+// IndexSummary stores the ID and index file offset of every Nth index entry
+type IndexSummary struct {
+	Index            int64
+	ID               []byte
+	IndexEntryOffset int64
+}
+
+// IndexSeries stores a series and its tags in an index segment
+type IndexSeries struct {
+	ID   []byte
+	Tags []IndexTag
+}
+
+// IndexTag stores a tag of a series in an index segment
+type IndexTag struct {
+	Name  []byte
+	Value []byte
+}
+
 // LogInfo stores summary information about a commit log
 type LogInfo struct {
 	Start    int64
@@ -59,4 +82,5 @@ type LogMetadata struct {
 	ID        []byte
 	Namespace []byte
 	Shard     uint32
+	Tags      []IndexTag
 }
@@ -23,6 +23,7 @@ package persist
 import (
 	"time"
 
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/ts"
 )
 
@@ -48,10 +49,25 @@ type Manager interface {
 // Flush is a persist flush cycle, each shard and block start permutation needs
 // to explicility be prepared.
 type Flush interface {
-	// Prepare prepares writing data for a given (shard, blockStart) combination,
-	// returning a PreparedPersist object and any error encountered during
-	// preparation if any.
-	Prepare(namespace ts.ID, shard uint32, blockStart time.Time) (PreparedPersist, error)
+	// Prepare prepares writing data for a given (shard, blockStart) combination
+	// of a namespace with the given retention options, returning a PreparedPersist
+	// object and any error encountered during preparation if any.
+	Prepare(
+		namespace ts.ID,
+		shard uint32,
+		blockStart time.Time,
+		ropts retention.Options,
+	) (PreparedPersist, error)
+
+	// PrepareRewrite prepares rewriting data for a given (shard, blockStart)
+	// combination regardless of whether data has already been persisted for
+	// it, any existing data remains readable until the rewrite completes.
+	PrepareRewrite(
+		namespace ts.ID,
+		shard uint32,
+		blockStart time.Time,
+		ropts retention.Options,
+	) (PreparedPersist, error)
 
 	// Done marks the flush as complete.
 	Done() error
@@ -0,0 +1,238 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3db/client"
+	"github.com/m3db/m3db/topology"
+)
+
+const (
+	consistencyLevelOne              = "one"
+	consistencyLevelUnstrictMajority = "unstrictMajority"
+	consistencyLevelMajority         = "majority"
+	consistencyLevelAll              = "all"
+
+	readReplicaStrategyAll     = "all"
+	readReplicaStrategyMinimum = "minimum"
+)
+
+// ClientConfiguration is the configuration for the cluster client.
+type ClientConfiguration struct {
+	// WriteConsistencyLevel is one of "one", "majority" or "all"
+	WriteConsistencyLevel string `yaml:"writeConsistencyLevel"`
+
+	// ReadConsistencyLevel is one of "one", "unstrictMajority", "majority" or "all"
+	ReadConsistencyLevel string `yaml:"readConsistencyLevel"`
+
+	// ConnectTimeout is the host connect timeout
+	ConnectTimeout time.Duration `yaml:"connectTimeout" validate:"min=0"`
+
+	// WriteTimeout is the write request timeout
+	WriteTimeout time.Duration `yaml:"writeTimeout" validate:"min=0"`
+
+	// FetchTimeout is the fetch request timeout
+	FetchTimeout time.Duration `yaml:"fetchTimeout" validate:"min=0"`
+
+	// MinConnectionCount is the minimum number of connections to each host
+	MinConnectionCount int `yaml:"minConnectionCount" validate:"min=0"`
+
+	// MaxConnectionCount is the maximum number of connections to each host
+	MaxConnectionCount int `yaml:"maxConnectionCount" validate:"min=0"`
+
+	// CircuitBreakerFailLimit is the number of consecutive requests to a host
+	// that time out or fail to reach it before requests to the host fail fast,
+	// zero disables the circuit breaker and is the default
+	CircuitBreakerFailLimit *int `yaml:"circuitBreakerFailLimit"`
+
+	// CircuitBreakerOpenDuration is the duration requests to a host fail fast
+	// before a probe request is allowed
+	CircuitBreakerOpenDuration time.Duration `yaml:"circuitBreakerOpenDuration" validate:"min=0"`
+
+	// WriteAsyncMaxPending is the maximum number of asynchronous writes
+	// pending completion before further asynchronous writes block
+	WriteAsyncMaxPending int `yaml:"writeAsyncMaxPending" validate:"min=0"`
+
+	// Hints is the configuration for storing hints for writes that failed to
+	// reach a replica to replay to the replica once it is healthy again
+	Hints *HintsConfiguration `yaml:"hints"`
+
+	// ReadReplicaStrategy is one of "all" or "minimum"
+	ReadReplicaStrategy string `yaml:"readReplicaStrategy"`
+
+	// ReadHedgeDelay is the delay after which reads from the minimum replicas
+	// are escalated to another replica
+	ReadHedgeDelay time.Duration `yaml:"readHedgeDelay" validate:"min=0"`
+
+	// ReadHedgePercentile is the percentile of the recent fetch latencies of
+	// the replicas read from after which reads are hedged to another replica
+	ReadHedgePercentile float64 `yaml:"readHedgePercentile" validate:"min=0,max=100"`
+
+	// ReadHedgeMaxRatio is the max ratio of hedged reads to reads, which caps
+	// the extra load hedged reads put on replicas
+	ReadHedgeMaxRatio *float64 `yaml:"readHedgeMaxRatio"`
+}
+
+// Validate validates the client configuration.
+func (c ClientConfiguration) Validate() error {
+	if c.WriteConsistencyLevel != "" {
+		if _, err := c.writeConsistencyLevel(); err != nil {
+			return err
+		}
+	}
+	if c.ReadConsistencyLevel != "" {
+		if _, err := c.readConsistencyLevel(); err != nil {
+			return err
+		}
+	}
+	if c.ReadReplicaStrategy != "" {
+		if _, err := c.readReplicaStrategy(); err != nil {
+			return err
+		}
+	}
+	if c.ReadHedgeMaxRatio != nil && *c.ReadHedgeMaxRatio < 0 {
+		return fmt.Errorf("client read hedge max ratio %f is negative", *c.ReadHedgeMaxRatio)
+	}
+	if c.MaxConnectionCount > 0 && c.MinConnectionCount > c.MaxConnectionCount {
+		return fmt.Errorf("client min connection count %d exceeds max connection count %d",
+			c.MinConnectionCount, c.MaxConnectionCount)
+	}
+	return nil
+}
+
+func (c ClientConfiguration) writeConsistencyLevel() (topology.ConsistencyLevel, error) {
+	switch c.WriteConsistencyLevel {
+	case consistencyLevelOne:
+		return topology.ConsistencyLevelOne, nil
+	case consistencyLevelMajority:
+		return topology.ConsistencyLevelMajority, nil
+	case consistencyLevelAll:
+		return topology.ConsistencyLevelAll, nil
+	}
+	return 0, fmt.Errorf("unknown client write consistency level %s", c.WriteConsistencyLevel)
+}
+
+func (c ClientConfiguration) readConsistencyLevel() (client.ReadConsistencyLevel, error) {
+	switch c.ReadConsistencyLevel {
+	case consistencyLevelOne:
+		return client.ReadConsistencyLevelOne, nil
+	case consistencyLevelUnstrictMajority:
+		return client.ReadConsistencyLevelUnstrictMajority, nil
+	case consistencyLevelMajority:
+		return client.ReadConsistencyLevelMajority, nil
+	case consistencyLevelAll:
+		return client.ReadConsistencyLevelAll, nil
+	}
+	return 0, fmt.Errorf("unknown client read consistency level %s", c.ReadConsistencyLevel)
+}
+
+func (c ClientConfiguration) readReplicaStrategy() (client.ReadReplicaStrategy, error) {
+	switch c.ReadReplicaStrategy {
+	case readReplicaStrategyAll:
+		return client.ReadReplicaStrategyAll, nil
+	case readReplicaStrategyMinimum:
+		return client.ReadReplicaStrategyMinimum, nil
+	}
+	return 0, fmt.Errorf("unknown client read replica strategy %s", c.ReadReplicaStrategy)
+}
+
+// Options returns the client options derived from the given options.
+func (c ClientConfiguration) Options(opts client.Options) client.Options {
+	if level, err := c.writeConsistencyLevel(); err == nil {
+		opts = opts.SetWriteConsistencyLevel(level)
+	}
+	if level, err := c.readConsistencyLevel(); err == nil {
+		opts = opts.SetReadConsistencyLevel(level)
+	}
+	if c.ConnectTimeout > 0 {
+		opts = opts.SetHostConnectTimeout(c.ConnectTimeout)
+	}
+	if c.WriteTimeout > 0 {
+		opts = opts.SetWriteRequestTimeout(c.WriteTimeout)
+	}
+	if c.FetchTimeout > 0 {
+		opts = opts.SetFetchRequestTimeout(c.FetchTimeout)
+	}
+	if c.MinConnectionCount > 0 {
+		opts = opts.SetMinConnectionCount(c.MinConnectionCount)
+	}
+	if c.MaxConnectionCount > 0 {
+		opts = opts.SetMaxConnectionCount(c.MaxConnectionCount)
+	}
+	if c.CircuitBreakerFailLimit != nil {
+		opts = opts.SetHostCircuitBreakerFailLimit(*c.CircuitBreakerFailLimit)
+	}
+	if c.CircuitBreakerOpenDuration > 0 {
+		opts = opts.SetHostCircuitBreakerOpenDuration(c.CircuitBreakerOpenDuration)
+	}
+	if c.WriteAsyncMaxPending > 0 {
+		opts = opts.SetWriteAsyncMaxPending(c.WriteAsyncMaxPending)
+	}
+	if c.Hints != nil {
+		opts = c.Hints.options(opts)
+	}
+	if strategy, err := c.readReplicaStrategy(); err == nil {
+		opts = opts.SetReadReplicaStrategy(strategy)
+	}
+	if c.ReadHedgeDelay > 0 {
+		opts = opts.SetReadHedgeDelay(c.ReadHedgeDelay)
+	}
+	if c.ReadHedgePercentile > 0 {
+		opts = opts.SetReadHedgePercentile(c.ReadHedgePercentile)
+	}
+	if c.ReadHedgeMaxRatio != nil {
+		opts = opts.SetReadHedgeMaxRatio(*c.ReadHedgeMaxRatio)
+	}
+	return opts
+}
+
+// HintsConfiguration is the configuration for storing hints for writes that
+// failed to reach a replica.
+type HintsConfiguration struct {
+	// FilePathPrefix is the directory hints are stored in
+	FilePathPrefix string `yaml:"filePathPrefix" validate:"nonzero"`
+
+	// TTL is the duration after which hints are dropped rather than replayed
+	TTL time.Duration `yaml:"ttl" validate:"min=0"`
+
+	// MaxBytesPerHost is the max bytes of hints stored for a host
+	MaxBytesPerHost int64 `yaml:"maxBytesPerHost" validate:"min=0"`
+
+	// ReplayInterval is the interval between replays of hints to hosts
+	ReplayInterval time.Duration `yaml:"replayInterval" validate:"min=0"`
+}
+
+func (c HintsConfiguration) options(opts client.Options) client.Options {
+	opts = opts.SetHintsFilePathPrefix(c.FilePathPrefix)
+	if c.TTL > 0 {
+		opts = opts.SetHintsTTL(c.TTL)
+	}
+	if c.MaxBytesPerHost > 0 {
+		opts = opts.SetHintsMaxBytesPerHost(c.MaxBytesPerHost)
+	}
+	if c.ReplayInterval > 0 {
+		opts = opts.SetHintsReplayInterval(c.ReplayInterval)
+	}
+	return opts
+}
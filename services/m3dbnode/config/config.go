@@ -0,0 +1,662 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage"
+	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/bootstrap/bootstrapper"
+	bcommitlog "github.com/m3db/m3db/storage/bootstrap/bootstrapper/commitlog"
+	bfs "github.com/m3db/m3db/storage/bootstrap/bootstrapper/fs"
+	"github.com/m3db/m3db/storage/bootstrap/bootstrapper/peers"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/storage/repair"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/config"
+	xerrors "github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/instrument"
+)
+
+const (
+	commitLogStrategyWriteWait   = "writeWait"
+	commitLogStrategyWriteBehind = "writeBehind"
+	commitLogStrategyWriteSync   = "writeSync"
+)
+
+var (
+	errNoNamespaces          = errors.New("no namespaces configured")
+	errNoBootstrappers       = errors.New("no bootstrappers configured")
+	errNoListenAddress       = errors.New("listen addresses must all be set")
+	errInvalidDirectoryMode  = errors.New("invalid filesystem directory mode")
+	errInvalidFileMode       = errors.New("invalid filesystem file mode")
+	errBufferPastTooLarge    = errors.New("retention buffer past must be less than the block size")
+	errBufferFutureTooLarge  = errors.New("retention buffer future must be less than the block size")
+	errBlockSizeTooLarge     = errors.New("retention block size must not exceed the retention period")
+	errEmptyNamespaceName    = errors.New("namespace name must be set")
+	errEmptyRollupSource     = errors.New("rollup source namespace must be set")
+	errNoTopology            = errors.New("one of static or dynamic topology must be configured")
+	errConflictingTopologies = errors.New("only one of static or dynamic topology may be configured")
+)
+
+// Configuration is the configuration for a m3dbnode.
+type Configuration struct {
+	// HostID is the host ID of the node, defaults to the hostname if not set
+	HostID string `yaml:"hostID"`
+
+	// ListenAddresses are the addresses the node servers listen on
+	ListenAddresses ListenAddressesConfiguration `yaml:"listenAddresses"`
+
+	// Filesystem is the filesystem configuration
+	Filesystem FilesystemConfiguration `yaml:"fs"`
+
+	// CommitLog is the commit log configuration
+	CommitLog CommitLogConfiguration `yaml:"commitlog"`
+
+	// Retention is the default retention for the database and its namespaces
+	Retention RetentionConfiguration `yaml:"retention"`
+
+	// Namespaces is the list of namespaces to create
+	Namespaces []NamespaceConfiguration `yaml:"namespaces" validate:"nonzero"`
+
+	// NamespaceRegistry is the dynamic namespace registry configuration,
+	// namespaces are static if not set
+	NamespaceRegistry *NamespaceRegistryConfiguration `yaml:"namespaceRegistry"`
+
+	// PoolingPolicy is the pool sizing configuration
+	PoolingPolicy PoolingPolicy `yaml:"pooling"`
+
+	// Bootstrap is the bootstrap configuration
+	Bootstrap BootstrapConfiguration `yaml:"bootstrap"`
+
+	// Repair is the repair configuration, repairs are disabled if not set
+	Repair *RepairConfiguration `yaml:"repair"`
+
+	// Topology is the topology configuration
+	Topology TopologyConfiguration `yaml:"topology"`
+
+	// Client is the cluster client configuration
+	Client ClientConfiguration `yaml:"client"`
+}
+
+// New loads a configuration from the file at the given path and validates it.
+func New(path string) (Configuration, error) {
+	var cfg Configuration
+	if err := xconfig.LoadFile(&cfg, path); err != nil {
+		return Configuration{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Configuration{}, fmt.Errorf("invalid configuration %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate validates the configuration.
+func (c Configuration) Validate() error {
+	multiErr := xerrors.NewMultiError()
+	multiErr = multiErr.Add(c.ListenAddresses.Validate())
+	multiErr = multiErr.Add(c.Filesystem.Validate())
+	multiErr = multiErr.Add(c.CommitLog.Validate())
+	multiErr = multiErr.Add(c.Retention.Validate())
+	multiErr = multiErr.Add(c.validateNamespaces())
+	multiErr = multiErr.Add(c.Bootstrap.Validate())
+	multiErr = multiErr.Add(c.Topology.Validate())
+	multiErr = multiErr.Add(c.Client.Validate())
+	return multiErr.FinalError()
+}
+
+func (c Configuration) validateNamespaces() error {
+	if len(c.Namespaces) == 0 {
+		return errNoNamespaces
+	}
+	names := make(map[string]struct{}, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		if err := ns.Validate(); err != nil {
+			return err
+		}
+		if _, exists := names[ns.Name]; exists {
+			return fmt.Errorf("duplicate namespace %s", ns.Name)
+		}
+		names[ns.Name] = struct{}{}
+	}
+	// Validates the rollups of the namespaces against their sources
+	_, err := namespace.NewMap(c.NamespacesMetadata())
+	return err
+}
+
+// ResolveHostID returns the configured host ID or the hostname if not set.
+func (c Configuration) ResolveHostID() (string, error) {
+	if c.HostID != "" {
+		return c.HostID, nil
+	}
+	return os.Hostname()
+}
+
+// NamespacesMetadata returns the metadata for the configured namespaces.
+func (c Configuration) NamespacesMetadata() []namespace.Metadata {
+	defaultRetentionOpts := c.Retention.Options()
+	namespaces := make([]namespace.Metadata, 0, len(c.Namespaces))
+	for _, ns := range c.Namespaces {
+		namespaces = append(namespaces, ns.Metadata(defaultRetentionOpts))
+	}
+	return namespaces
+}
+
+// NewNamespaceInitializer creates a namespace registry initializer, the
+// registry is dynamic if configured and static otherwise.
+func (c Configuration) NewNamespaceInitializer(
+	iopts instrument.Options,
+) (namespace.Initializer, error) {
+	if c.NamespaceRegistry != nil {
+		return c.NamespaceRegistry.NewInitializer(c.NamespacesMetadata(), iopts)
+	}
+	return namespace.NewStaticInitializer(c.NamespacesMetadata()), nil
+}
+
+// StorageOptions returns the storage options derived from the given options.
+func (c Configuration) StorageOptions(opts storage.Options) storage.Options {
+	ropts := c.Retention.Options()
+	opts = opts.SetRetentionOptions(ropts)
+
+	fsopts := c.Filesystem.Options(opts.CommitLogOptions().FilesystemOptions().
+		SetRetentionOptions(ropts))
+	copts := c.CommitLog.Options(opts.CommitLogOptions().
+		SetFilesystemOptions(fsopts))
+	fileOpOpts := opts.FileOpOptions().
+		SetRetentionOptions(ropts).
+		SetSnapshotInterval(c.Filesystem.SnapshotInterval).
+		SetScrubInterval(c.Filesystem.ScrubInterval)
+	if c.Filesystem.ScrubLimitMbps > 0 {
+		fileOpOpts = fileOpOpts.SetScrubRateLimitOptions(fileOpOpts.ScrubRateLimitOptions().
+			SetLimitMbps(c.Filesystem.ScrubLimitMbps))
+	}
+	opts = opts.
+		SetCommitLogOptions(copts).
+		SetPersistManager(fs.NewPersistManager(fsopts)).
+		SetFileOpOptions(fileOpOpts)
+
+	if c.Repair != nil {
+		opts = opts.
+			SetRepairEnabled(true).
+			SetRepairOptions(c.Repair.Options(opts.RepairOptions()))
+	} else {
+		opts = opts.SetRepairEnabled(false)
+	}
+
+	return c.PoolingPolicy.Apply(opts)
+}
+
+// NewBlockRetrieverManager creates a block retriever manager that retrieves
+// blocks of each namespace from its filesets using the filesystem options of
+// the given storage options, so reads honor the configured mmap and tiering.
+func (c Configuration) NewBlockRetrieverManager(
+	opts storage.Options,
+) block.DatabaseBlockRetrieverManager {
+	fsopts := opts.CommitLogOptions().FilesystemOptions()
+	retrieverOpts := fs.NewBlockRetrieverOptions().
+		SetBytesPool(opts.BytesPool()).
+		SetSegmentReaderPool(opts.SegmentReaderPool())
+	return block.NewDatabaseBlockRetrieverManager(
+		func(md namespace.Metadata) (block.DatabaseBlockRetriever, error) {
+			retriever := fs.NewBlockRetriever(retrieverOpts, fsopts)
+			if err := retriever.Open(md); err != nil {
+				return nil, err
+			}
+			return retriever, nil
+		})
+}
+
+// ListenAddressesConfiguration is the configuration for server listen addresses.
+type ListenAddressesConfiguration struct {
+	// HTTPCluster is the cluster HTTP server address
+	HTTPCluster string `yaml:"httpCluster"`
+
+	// TChannelCluster is the cluster TChannel server address
+	TChannelCluster string `yaml:"tchannelCluster"`
+
+	// HTTPNode is the node HTTP server address
+	HTTPNode string `yaml:"httpNode"`
+
+	// TChannelNode is the node TChannel server address
+	TChannelNode string `yaml:"tchannelNode"`
+}
+
+// Validate validates the listen addresses configuration.
+func (c ListenAddressesConfiguration) Validate() error {
+	if c.HTTPCluster == "" || c.TChannelCluster == "" ||
+		c.HTTPNode == "" || c.TChannelNode == "" {
+		return errNoListenAddress
+	}
+	return nil
+}
+
+// FilesystemConfiguration is the configuration for the filesystem.
+type FilesystemConfiguration struct {
+	// FilePathPrefix is the file path prefix for all data files
+	FilePathPrefix string `yaml:"filePathPrefix" validate:"nonzero"`
+
+	// WriterBufferSize is the buffer size used when writing data files
+	WriterBufferSize int `yaml:"writerBufferSize" validate:"min=0"`
+
+	// ReaderBufferSize is the buffer size used when reading data files
+	ReaderBufferSize int `yaml:"readerBufferSize" validate:"min=0"`
+
+	// NewFileMode is the file mode for new files as an octal string, e.g. "0666"
+	NewFileMode string `yaml:"newFileMode"`
+
+	// NewDirectoryMode is the directory mode for new directories as an octal string, e.g. "0755"
+	NewDirectoryMode string `yaml:"newDirectoryMode"`
+
+	// SnapshotInterval is the interval between snapshots of unflushed data,
+	// snapshots are disabled if unset
+	SnapshotInterval time.Duration `yaml:"snapshotInterval" validate:"min=0"`
+
+	// ScrubInterval is the interval between verifications of each flushed
+	// fileset, scrubbing is disabled if unset
+	ScrubInterval time.Duration `yaml:"scrubInterval" validate:"min=0"`
+
+	// ScrubLimitMbps is the rate limit for reading filesets when scrubbing,
+	// the default limit is used if unset
+	ScrubLimitMbps float64 `yaml:"scrubLimitMbps" validate:"min=0"`
+
+	// MmapEnabled memory maps the data and index files of filesets for reads
+	MmapEnabled bool `yaml:"mmapEnabled"`
+
+	// Compression is the compression applied to fileset data, one of "none" or "deflate"
+	Compression string `yaml:"compression"`
+
+	// Tiering moves filesets older than an age to a secondary file path
+	// prefix, filesets are only written to the file path prefix if unset
+	Tiering *TieringConfiguration `yaml:"tiering"`
+}
+
+// TieringConfiguration is the configuration for tiered fileset storage.
+type TieringConfiguration struct {
+	// FilePathPrefix is the file path prefix filesets are moved to
+	FilePathPrefix string `yaml:"filePathPrefix" validate:"nonzero"`
+
+	// Age is how long after the end of its block a fileset is moved
+	Age time.Duration `yaml:"age" validate:"nonzero"`
+}
+
+// Validate validates the filesystem configuration.
+func (c FilesystemConfiguration) Validate() error {
+	if _, err := parseFileMode(c.NewFileMode); err != nil {
+		return errInvalidFileMode
+	}
+	if _, err := parseFileMode(c.NewDirectoryMode); err != nil {
+		return errInvalidDirectoryMode
+	}
+	if c.Compression != "" {
+		if _, err := fs.ParseCompressionType(c.Compression); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Options returns the filesystem options derived from the given options.
+func (c FilesystemConfiguration) Options(opts fs.Options) fs.Options {
+	opts = opts.SetFilePathPrefix(c.FilePathPrefix)
+	if c.WriterBufferSize > 0 {
+		opts = opts.SetWriterBufferSize(c.WriterBufferSize)
+	}
+	if c.ReaderBufferSize > 0 {
+		opts = opts.SetReaderBufferSize(c.ReaderBufferSize)
+	}
+	if mode, _ := parseFileMode(c.NewFileMode); mode != 0 {
+		opts = opts.SetNewFileMode(mode)
+	}
+	if mode, _ := parseFileMode(c.NewDirectoryMode); mode != 0 {
+		opts = opts.SetNewDirectoryMode(mode | os.ModeDir)
+	}
+	if compression, err := fs.ParseCompressionType(c.Compression); err == nil {
+		opts = opts.SetCompression(compression)
+	}
+	if c.Tiering != nil {
+		opts = opts.SetTieringPolicy(fs.TieringPolicy{
+			FilePathPrefix: c.Tiering.FilePathPrefix,
+			Age:            c.Tiering.Age,
+		})
+	}
+	return opts.SetMmapEnabled(c.MmapEnabled)
+}
+
+func parseFileMode(str string) (os.FileMode, error) {
+	if str == "" {
+		return 0, nil
+	}
+	var mode uint32
+	if _, err := fmt.Sscanf(str, "%o", &mode); err != nil {
+		return 0, err
+	}
+	return os.FileMode(mode), nil
+}
+
+// CommitLogConfiguration is the configuration for the commit log.
+type CommitLogConfiguration struct {
+	// Strategy is the commit log write strategy, one of "writeWait", "writeBehind" or "writeSync"
+	Strategy string `yaml:"strategy"`
+
+	// FlushMaxBytes is the size of the buffer that triggers a flush when full
+	FlushMaxBytes int `yaml:"flushMaxBytes" validate:"min=0"`
+
+	// FlushEvery is the maximum interval between flushes
+	FlushEvery time.Duration `yaml:"flushEvery" validate:"min=0"`
+
+	// BacklogQueueSize is the size of the queue of pending writes
+	BacklogQueueSize int `yaml:"backlogQueueSize" validate:"min=0"`
+
+	// Stripes is the number of commit log files written concurrently, writes
+	// are assigned to stripes by series, defaults to a single stripe
+	Stripes int `yaml:"stripes" validate:"min=0"`
+
+	// BlockSize is the size of each commit log file, defaults to the retention block size
+	BlockSize time.Duration `yaml:"blockSize" validate:"min=0"`
+
+	// ReadCorruptionPolicy is how corrupt chunks are handled when replaying
+	// the commit log, one of "truncate", "skip" or "fail"
+	ReadCorruptionPolicy string `yaml:"readCorruptionPolicy"`
+}
+
+// Validate validates the commit log configuration.
+func (c CommitLogConfiguration) Validate() error {
+	if _, err := c.strategy(); err != nil {
+		return err
+	}
+	if c.ReadCorruptionPolicy != "" {
+		if _, err := commitlog.ParseReadCorruptionPolicy(c.ReadCorruptionPolicy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c CommitLogConfiguration) strategy() (commitlog.Strategy, error) {
+	switch c.Strategy {
+	case "", commitLogStrategyWriteWait:
+		return commitlog.StrategyWriteWait, nil
+	case commitLogStrategyWriteBehind:
+		return commitlog.StrategyWriteBehind, nil
+	case commitLogStrategyWriteSync:
+		return commitlog.StrategyWriteSync, nil
+	}
+	return 0, fmt.Errorf("unknown commit log strategy %s", c.Strategy)
+}
+
+// Options returns the commit log options derived from the given options.
+func (c CommitLogConfiguration) Options(opts commitlog.Options) commitlog.Options {
+	strategy, _ := c.strategy()
+	opts = opts.SetStrategy(strategy)
+	if c.FlushMaxBytes > 0 {
+		opts = opts.SetFlushSize(c.FlushMaxBytes)
+	}
+	if c.FlushEvery > 0 {
+		opts = opts.SetFlushInterval(c.FlushEvery)
+	}
+	if c.BacklogQueueSize > 0 {
+		opts = opts.SetBacklogQueueSize(c.BacklogQueueSize)
+	}
+	if c.Stripes > 0 {
+		opts = opts.SetNumStripes(c.Stripes)
+	}
+	if c.BlockSize > 0 {
+		opts = opts.SetRetentionOptions(opts.RetentionOptions().SetBlockSize(c.BlockSize))
+	}
+	if policy, err := commitlog.ParseReadCorruptionPolicy(c.ReadCorruptionPolicy); err == nil {
+		opts = opts.SetReadCorruptionPolicy(policy)
+	}
+	return opts
+}
+
+// RetentionConfiguration is the configuration for data retention.
+type RetentionConfiguration struct {
+	// RetentionPeriod is how long data is kept for
+	RetentionPeriod time.Duration `yaml:"retentionPeriod" validate:"nonzero"`
+
+	// BlockSize is the size of each block
+	BlockSize time.Duration `yaml:"blockSize" validate:"nonzero"`
+
+	// BufferFuture is how far into the future writes are accepted
+	BufferFuture time.Duration `yaml:"bufferFuture" validate:"min=0"`
+
+	// BufferPast is how far into the past writes are accepted
+	BufferPast time.Duration `yaml:"bufferPast" validate:"min=0"`
+
+	// BufferDrain is how long to wait before draining buffers into blocks
+	BufferDrain time.Duration `yaml:"bufferDrain" validate:"min=0"`
+}
+
+// Validate validates the retention configuration.
+func (c RetentionConfiguration) Validate() error {
+	if c.BlockSize > c.RetentionPeriod {
+		return errBlockSizeTooLarge
+	}
+	if c.BufferPast >= c.BlockSize {
+		return errBufferPastTooLarge
+	}
+	if c.BufferFuture >= c.BlockSize {
+		return errBufferFutureTooLarge
+	}
+	return nil
+}
+
+// Options returns the retention options.
+func (c RetentionConfiguration) Options() retention.Options {
+	opts := retention.NewOptions().
+		SetRetentionPeriod(c.RetentionPeriod).
+		SetBlockSize(c.BlockSize).
+		SetBufferFuture(c.BufferFuture).
+		SetBufferPast(c.BufferPast)
+	if c.BufferDrain > 0 {
+		opts = opts.SetBufferDrain(c.BufferDrain)
+	}
+	return opts
+}
+
+// NamespaceConfiguration is the configuration for a namespace.
+type NamespaceConfiguration struct {
+	// Name is the name of the namespace
+	Name string `yaml:"name" validate:"nonzero"`
+
+	// NeedsBootstrap is whether the namespace needs bootstrapping, defaults to true
+	NeedsBootstrap *bool `yaml:"needsBootstrap"`
+
+	// NeedsFlush is whether the namespace needs flushing, defaults to true
+	NeedsFlush *bool `yaml:"needsFlush"`
+
+	// WritesToCommitLog is whether writes to the namespace go to the commit log, defaults to true
+	WritesToCommitLog *bool `yaml:"writesToCommitLog"`
+
+	// NeedsFilesetCleanup is whether the namespace needs fileset cleanup, defaults to true
+	NeedsFilesetCleanup *bool `yaml:"needsFilesetCleanup"`
+
+	// NeedsRepair is whether the namespace needs repairing, defaults to true
+	NeedsRepair *bool `yaml:"needsRepair"`
+
+	// ColdWritesEnabled is whether writes outside the buffer window are accepted, defaults to false
+	ColdWritesEnabled bool `yaml:"coldWritesEnabled"`
+
+	// SyncCommitLogWrites is whether writes are acknowledged only once fsynced to the commit log, defaults to false
+	SyncCommitLogWrites bool `yaml:"syncCommitLogWrites"`
+
+	// Retention overrides the default retention for the namespace
+	Retention *RetentionConfiguration `yaml:"retention"`
+
+	// Rollup declares the namespace as a rollup of another namespace,
+	// rollup namespaces do not need flushing unless set otherwise
+	Rollup *RollupConfiguration `yaml:"rollup"`
+}
+
+// Validate validates the namespace configuration.
+func (c NamespaceConfiguration) Validate() error {
+	if c.Name == "" {
+		return errEmptyNamespaceName
+	}
+	if c.Retention != nil {
+		if err := c.Retention.Validate(); err != nil {
+			return fmt.Errorf("namespace %s: %v", c.Name, err)
+		}
+	}
+	if c.Rollup != nil {
+		if err := c.Rollup.Validate(); err != nil {
+			return fmt.Errorf("namespace %s: %v", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// Metadata returns the namespace metadata, using the given retention
+// options unless the namespace overrides them.
+func (c NamespaceConfiguration) Metadata(defaultRetentionOpts retention.Options) namespace.Metadata {
+	opts := namespace.NewOptions().SetRetentionOptions(defaultRetentionOpts)
+	if c.Retention != nil {
+		opts = opts.SetRetentionOptions(c.Retention.Options())
+	}
+	if c.Rollup != nil {
+		opts = opts.SetRollup(c.Rollup.Rollup()).SetNeedsFlush(false)
+	}
+	if c.NeedsBootstrap != nil {
+		opts = opts.SetNeedsBootstrap(*c.NeedsBootstrap)
+	}
+	if c.NeedsFlush != nil {
+		opts = opts.SetNeedsFlush(*c.NeedsFlush)
+	}
+	if c.WritesToCommitLog != nil {
+		opts = opts.SetWritesToCommitLog(*c.WritesToCommitLog)
+	}
+	if c.NeedsFilesetCleanup != nil {
+		opts = opts.SetNeedsFilesetCleanup(*c.NeedsFilesetCleanup)
+	}
+	if c.NeedsRepair != nil {
+		opts = opts.SetNeedsRepair(*c.NeedsRepair)
+	}
+	if c.ColdWritesEnabled {
+		opts = opts.SetColdWritesEnabled(true)
+	}
+	if c.SyncCommitLogWrites {
+		opts = opts.SetSyncCommitLogWrites(true)
+	}
+	return namespace.NewMetadata(ts.StringID(c.Name), opts)
+}
+
+// RollupConfiguration is the configuration for a rollup namespace.
+type RollupConfiguration struct {
+	// SourceNamespace is the name of the namespace to roll up
+	SourceNamespace string `yaml:"sourceNamespace" validate:"nonzero"`
+
+	// Resolution is the resolution of the rolled up datapoints
+	Resolution time.Duration `yaml:"resolution" validate:"nonzero"`
+
+	// Aggregation is one of "min", "max", "sum", "count", "avg" or "last"
+	Aggregation string `yaml:"aggregation" validate:"nonzero"`
+}
+
+// Validate validates the rollup configuration.
+func (c RollupConfiguration) Validate() error {
+	if c.SourceNamespace == "" {
+		return errEmptyRollupSource
+	}
+	_, err := encoding.ParseAggregationType(c.Aggregation)
+	return err
+}
+
+// Rollup returns the namespace rollup.
+func (c RollupConfiguration) Rollup() *namespace.Rollup {
+	aggregation, _ := encoding.ParseAggregationType(c.Aggregation)
+	return &namespace.Rollup{
+		SourceNamespace: ts.StringID(c.SourceNamespace),
+		Resolution:      c.Resolution,
+		Aggregation:     aggregation,
+	}
+}
+
+// RepairConfiguration is the configuration for repairs.
+type RepairConfiguration struct {
+	// Interval is the repair interval
+	Interval time.Duration `yaml:"interval" validate:"nonzero"`
+
+	// TimeOffset is the repair time offset
+	TimeOffset time.Duration `yaml:"timeOffset" validate:"min=0"`
+
+	// Jitter is the repair time jitter
+	Jitter time.Duration `yaml:"jitter" validate:"min=0"`
+
+	// CheckInterval is the repair check interval
+	CheckInterval time.Duration `yaml:"checkInterval" validate:"min=0"`
+
+	// Throttle is the repair throttle between shards
+	Throttle time.Duration `yaml:"throttle" validate:"min=0"`
+
+	// ShardConcurrency is the number of shards repaired concurrently
+	ShardConcurrency int `yaml:"shardConcurrency" validate:"min=0"`
+
+	// MaxRetries is the maximum number of retries for a failed block repair
+	MaxRetries int `yaml:"maxRetries" validate:"min=0"`
+}
+
+// Options returns the repair options derived from the given options.
+func (c RepairConfiguration) Options(opts repair.Options) repair.Options {
+	opts = opts.
+		SetRepairInterval(c.Interval).
+		SetRepairTimeOffset(c.TimeOffset).
+		SetRepairTimeJitter(c.Jitter)
+	if c.CheckInterval > 0 {
+		opts = opts.SetRepairCheckInterval(c.CheckInterval)
+	}
+	if c.Throttle > 0 {
+		opts = opts.SetRepairThrottle(c.Throttle)
+	}
+	if c.ShardConcurrency > 0 {
+		opts = opts.SetRepairShardConcurrency(c.ShardConcurrency)
+	}
+	if c.MaxRetries > 0 {
+		opts = opts.SetRepairMaxRetries(c.MaxRetries)
+	}
+	return opts
+}
+
+// Validate validates the bootstrap configuration.
+func (bsc BootstrapConfiguration) Validate() error {
+	if len(bsc.Bootstrappers) == 0 {
+		return errNoBootstrappers
+	}
+	for _, name := range bsc.Bootstrappers {
+		switch name {
+		case bootstrapper.NoOpAllBootstrapperName,
+			bootstrapper.NoOpNoneBootstrapperName,
+			bfs.FileSystemBootstrapperName,
+			bcommitlog.CommitLogBootstrapperName,
+			peers.PeersBootstrapperName:
+		default:
+			return fmt.Errorf("unknown bootstrapper name %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/client"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/storage"
+	"github.com/m3db/m3db/topology"
+	"github.com/m3db/m3x/instrument"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = "m3dbnode-local-config.yaml"
+
+func writeTestConfig(t *testing.T, contents string) string {
+	fd, err := ioutil.TempFile("", "m3dbnode-config")
+	require.NoError(t, err)
+	_, err = fd.WriteString(contents)
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+	return fd.Name()
+}
+
+func testRollupNamespace(source, aggregation string) string {
+	return "  - name: rollup\n" +
+		"    rollup:\n" +
+		"      sourceNamespace: " + source + "\n" +
+		"      resolution: 1m\n" +
+		"      aggregation: " + aggregation + "\n"
+}
+
+func TestNewConfigurationFromLocalConfig(t *testing.T) {
+	cfg, err := New(testConfigFile)
+	require.NoError(t, err)
+
+	require.Equal(t, "m3db_local", cfg.HostID)
+	require.Equal(t, "0.0.0.0:9003", cfg.ListenAddresses.TChannelNode)
+
+	namespaces := cfg.NamespacesMetadata()
+	require.Equal(t, 3, len(namespaces))
+	require.Equal(t, "default", namespaces[0].ID().String())
+	require.Equal(t, 2*time.Hour, namespaces[0].Options().RetentionOptions().BlockSize())
+	require.Equal(t, "metrics", namespaces[1].ID().String())
+	require.Equal(t, 12*time.Hour, namespaces[1].Options().RetentionOptions().BlockSize())
+	require.Equal(t, 720*time.Hour, namespaces[1].Options().RetentionOptions().RetentionPeriod())
+	require.False(t, namespaces[0].Options().ColdWritesEnabled())
+	require.True(t, namespaces[1].Options().ColdWritesEnabled())
+	require.False(t, namespaces[0].Options().SyncCommitLogWrites())
+	require.True(t, namespaces[1].Options().SyncCommitLogWrites())
+	require.Equal(t, "metrics_10m", namespaces[2].ID().String())
+	rollup := namespaces[2].Options().Rollup()
+	require.NotNil(t, rollup)
+	require.Equal(t, "metrics", rollup.SourceNamespace.String())
+	require.Equal(t, 10*time.Minute, rollup.Resolution)
+	require.Equal(t, encoding.AggregationAvg, rollup.Aggregation)
+	require.False(t, namespaces[2].Options().NeedsFlush())
+
+	require.Nil(t, cfg.NamespaceRegistry)
+	nsInit, err := cfg.NewNamespaceInitializer(instrument.NewOptions())
+	require.NoError(t, err)
+	registry, err := nsInit.Init()
+	require.NoError(t, err)
+	defer registry.Close()
+	require.Equal(t, 3, len(registry.Get().IDs()))
+
+	opts := cfg.StorageOptions(storage.NewOptions())
+	require.Equal(t, 48*time.Hour, opts.RetentionOptions().RetentionPeriod())
+	require.Equal(t, "/var/lib/m3db", opts.CommitLogOptions().FilesystemOptions().FilePathPrefix())
+	require.Equal(t, fs.TieringPolicy{FilePathPrefix: "/var/lib/m3db-cold", Age: 168 * time.Hour},
+		opts.CommitLogOptions().FilesystemOptions().TieringPolicy())
+	require.Equal(t, commitlog.StrategyWriteBehind, opts.CommitLogOptions().Strategy())
+	require.Equal(t, time.Second, opts.CommitLogOptions().FlushInterval())
+	require.Equal(t, commitlog.ReadCorruptionPolicySkip, opts.CommitLogOptions().ReadCorruptionPolicy())
+	require.Equal(t, 4, opts.CommitLogOptions().NumStripes())
+	require.Equal(t, 10*time.Minute, opts.FileOpOptions().SnapshotInterval())
+	require.Equal(t, 24*time.Hour, opts.FileOpOptions().ScrubInterval())
+	require.Equal(t, 20.0, opts.FileOpOptions().ScrubRateLimitOptions().LimitMbps())
+	require.True(t, opts.RepairEnabled())
+	require.Equal(t, 2*time.Hour, opts.RepairOptions().RepairInterval())
+
+	topoInit, err := cfg.Topology.NewInitializer(opts.InstrumentOptions())
+	require.NoError(t, err)
+	topo, err := topoInit.Init()
+	require.NoError(t, err)
+	defer topo.Close()
+	topoMap := topo.Get()
+	require.Equal(t, 1, topoMap.Replicas())
+	require.Equal(t, 64, len(topoMap.ShardSet().AllIDs()))
+
+	clientOpts := cfg.Client.Options(client.NewOptions())
+	require.Equal(t, topology.ConsistencyLevelMajority, clientOpts.WriteConsistencyLevel())
+}
+
+func TestNewBlockRetrieverManagerFromConfig(t *testing.T) {
+	cfg, err := New(testConfigFile)
+	require.NoError(t, err)
+
+	opts := cfg.StorageOptions(storage.NewOptions())
+	mgr := cfg.NewBlockRetrieverManager(opts)
+	require.NotNil(t, mgr)
+
+	md := cfg.NamespacesMetadata()[0]
+	retriever, err := mgr.Retriever(md)
+	require.NoError(t, err)
+	fsRetriever, ok := retriever.(fs.BlockRetriever)
+	require.True(t, ok)
+	defer fsRetriever.Close()
+
+	// The retriever of a namespace is only created once
+	same, err := mgr.Retriever(md)
+	require.NoError(t, err)
+	require.True(t, retriever == same)
+}
+
+func TestNewConfigurationMissingFile(t *testing.T) {
+	_, err := New("does-not-exist.yaml")
+	require.Error(t, err)
+}
+
+func TestNewConfigurationInvalid(t *testing.T) {
+	base := `
+listenAddresses:
+  httpCluster: 0.0.0.0:9000
+  tchannelCluster: 0.0.0.0:9001
+  httpNode: 0.0.0.0:9002
+  tchannelNode: 0.0.0.0:9003
+fs:
+  filePathPrefix: /var/lib/m3db
+retention:
+  retentionPeriod: 48h
+  blockSize: 2h
+namespaces:
+  - name: default
+bootstrap:
+  bootstrappers:
+    - filesystem
+`
+	staticTopology := `
+topology:
+  static:
+    shards: 8
+    replicas: 1
+    hosts:
+      - id: a
+        address: 127.0.0.1:9003
+`
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"no topology", base},
+		{"unknown bootstrapper", base + "    - unknown\n" + staticTopology},
+		{"unknown compression", strings.Replace(base, "fs:\n", "fs:\n  compression: lz4\n", 1) + staticTopology},
+		{"unknown commit log strategy", base + staticTopology + "commitlog:\n  strategy: sometimes\n"},
+		{"unknown commit log read corruption policy", base + staticTopology + "commitlog:\n  readCorruptionPolicy: ignore\n"},
+		{"too many replicas", base + `
+topology:
+  static:
+    shards: 8
+    replicas: 2
+    hosts:
+      - id: a
+        address: 127.0.0.1:9003
+`},
+		{"duplicate namespace", strings.Replace(base, "  - name: default\n",
+			"  - name: default\n  - name: default\n", 1) + staticTopology},
+		{"unknown rollup source", strings.Replace(base, "  - name: default\n",
+			"  - name: default\n"+testRollupNamespace("missing", "max"), 1) + staticTopology},
+		{"unknown rollup aggregation", strings.Replace(base, "  - name: default\n",
+			"  - name: default\n"+testRollupNamespace("default", "median"), 1) + staticTopology},
+		{"cold writes without flush", strings.Replace(base, "  - name: default\n",
+			"  - name: default\n    needsFlush: false\n    coldWritesEnabled: true\n", 1) + staticTopology},
+		{"unknown consistency level", base + staticTopology + "client:\n  writeConsistencyLevel: some\n"},
+	}
+
+	for _, test := range tests {
+		path := writeTestConfig(t, test.contents)
+		_, err := New(path)
+		require.Error(t, err, test.name)
+		require.NoError(t, os.Remove(path))
+	}
+
+	path := writeTestConfig(t, base+staticTopology)
+	defer os.Remove(path)
+	_, err := New(path)
+	require.NoError(t, err)
+}
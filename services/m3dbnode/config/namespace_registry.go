@@ -0,0 +1,67 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	etcdclient "github.com/m3db/m3cluster/client/etcd"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3x/instrument"
+)
+
+// NamespaceRegistryConfiguration is the configuration for a dynamic
+// namespace registry backed by m3cluster.
+type NamespaceRegistryConfiguration struct {
+	// Key is the KV key the namespace registry is stored at
+	Key string `yaml:"key"`
+
+	// InitTimeout is how long to wait for the registry to initialize
+	InitTimeout time.Duration `yaml:"initTimeout" validate:"min=0"`
+
+	// KV is the configuration of the m3cluster config service client
+	KV etcdclient.Configuration `yaml:"kv"`
+}
+
+// NewInitializer creates a dynamic namespace registry initializer, the
+// registry is seeded with the given namespaces if it does not exist yet.
+func (c NamespaceRegistryConfiguration) NewInitializer(
+	initial []namespace.Metadata,
+	iopts instrument.Options,
+) (namespace.Initializer, error) {
+	configSvcClient, err := etcdclient.NewConfigServiceClient(c.KV.NewOptions())
+	if err != nil {
+		return nil, fmt.Errorf("could not create config service client: %v", err)
+	}
+
+	opts := namespace.NewDynamicOptions().
+		SetConfigServiceClient(configSvcClient).
+		SetInitialNamespaces(initial).
+		SetInstrumentOptions(iopts)
+	if c.Key != "" {
+		opts = opts.SetRegistryKey(c.Key)
+	}
+	if c.InitTimeout > 0 {
+		opts = opts.SetInitTimeout(c.InitTimeout)
+	}
+	return namespace.NewDynamicInitializer(opts), nil
+}
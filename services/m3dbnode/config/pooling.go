@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"io"
+	"time"
+
+	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/encoding/m3tsz"
+	"github.com/m3db/m3db/storage"
+	"github.com/m3db/m3db/storage/series"
+	"github.com/m3db/m3db/ts"
+	xio "github.com/m3db/m3db/x/io"
+	"github.com/m3db/m3x/pool"
+)
+
+var timeZero time.Time
+
+// PoolPolicy is the sizing policy for an object pool, the pool
+// uses its default size if not set.
+type PoolPolicy struct {
+	// Size is the number of objects in the pool
+	Size int `yaml:"size" validate:"min=0"`
+}
+
+func (p PoolPolicy) options() pool.ObjectPoolOptions {
+	if p.Size <= 0 {
+		return nil
+	}
+	return pool.NewObjectPoolOptions().SetSize(p.Size)
+}
+
+// BucketPolicy is the sizing policy for a bytes pool bucket.
+type BucketPolicy struct {
+	// Capacity is the capacity of each byte slice in the bucket
+	Capacity int `yaml:"capacity" validate:"min=1"`
+
+	// Count is the number of byte slices in the bucket
+	Count int `yaml:"count" validate:"min=1"`
+}
+
+// PoolingPolicy is the sizing policy for the database pools.
+type PoolingPolicy struct {
+	// BytesPool is the list of buckets for the bytes pool, the default
+	// buckets are used if not set
+	BytesPool []BucketPolicy `yaml:"bytesPool"`
+
+	// ContextPool is the context pool policy
+	ContextPool PoolPolicy `yaml:"contextPool"`
+
+	// SeriesPool is the series pool policy
+	SeriesPool PoolPolicy `yaml:"seriesPool"`
+
+	// EncoderPool is the encoder pool policy
+	EncoderPool PoolPolicy `yaml:"encoderPool"`
+
+	// SegmentReaderPool is the segment reader pool policy
+	SegmentReaderPool PoolPolicy `yaml:"segmentReaderPool"`
+
+	// IteratorPool is the policy for both single and multi reader iterator pools
+	IteratorPool PoolPolicy `yaml:"iteratorPool"`
+
+	// IdentifierPool is the identifier pool policy
+	IdentifierPool PoolPolicy `yaml:"identifierPool"`
+}
+
+// Apply returns the storage options with pools sized by the policy.
+func (p PoolingPolicy) Apply(opts storage.Options) storage.Options {
+	bytesPool := opts.BytesPool()
+	if len(p.BytesPool) > 0 {
+		buckets := make([]pool.Bucket, 0, len(p.BytesPool))
+		for _, b := range p.BytesPool {
+			buckets = append(buckets, pool.Bucket{Capacity: b.Capacity, Count: b.Count})
+		}
+		bytesPool = pool.NewCheckedBytesPool(buckets, nil, func(s []pool.Bucket) pool.BytesPool {
+			return pool.NewBytesPool(s, nil)
+		})
+		bytesPool.Init()
+	}
+
+	segmentReaderPool := xio.NewSegmentReaderPool(p.SegmentReaderPool.options())
+	segmentReaderPool.Init()
+
+	encoderPool := encoding.NewEncoderPool(p.EncoderPool.options())
+	readerIteratorPool := encoding.NewReaderIteratorPool(p.IteratorPool.options())
+	multiReaderIteratorPool := encoding.NewMultiReaderIteratorPool(p.IteratorPool.options())
+
+	encodingOpts := encoding.NewOptions().
+		SetBytesPool(bytesPool).
+		SetEncoderPool(encoderPool).
+		SetReaderIteratorPool(readerIteratorPool).
+		SetSegmentReaderPool(segmentReaderPool)
+
+	encoderPool.Init(func() encoding.Encoder {
+		return m3tsz.NewEncoder(timeZero, nil, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
+	})
+	readerIteratorPool.Init(func(r io.Reader) encoding.ReaderIterator {
+		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
+	})
+	multiReaderIteratorPool.Init(func(r io.Reader) encoding.ReaderIterator {
+		return m3tsz.NewReaderIterator(r, m3tsz.DefaultIntOptimizationEnabled, encodingOpts)
+	})
+
+	blockOpts := opts.DatabaseBlockOptions().
+		SetEncoderPool(encoderPool).
+		SetReaderIteratorPool(readerIteratorPool).
+		SetMultiReaderIteratorPool(multiReaderIteratorPool)
+
+	opts = opts.
+		SetBytesPool(bytesPool).
+		SetContextPool(context.NewPool(p.ContextPool.options(), nil)).
+		SetSegmentReaderPool(segmentReaderPool).
+		SetEncoderPool(encoderPool).
+		SetReaderIteratorPool(readerIteratorPool).
+		SetMultiReaderIteratorPool(multiReaderIteratorPool).
+		SetIdentifierPool(ts.NewIdentifierPool(bytesPool, p.IdentifierPool.options())).
+		SetDatabaseBlockOptions(blockOpts)
+
+	seriesPool := series.NewDatabaseSeriesPool(storage.NewSeriesOptionsFromOptions(opts),
+		p.SeriesPool.options())
+	return opts.SetDatabaseSeriesPool(seriesPool)
+}
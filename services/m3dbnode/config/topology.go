@@ -0,0 +1,199 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	etcdclient "github.com/m3db/m3cluster/client/etcd"
+	"github.com/m3db/m3cluster/services"
+	"github.com/m3db/m3cluster/shard"
+	"github.com/m3db/m3db/sharding"
+	"github.com/m3db/m3db/topology"
+	"github.com/m3db/m3x/instrument"
+)
+
+var (
+	errNoStaticShards        = errors.New("static topology must have at least one shard")
+	errNoStaticHosts         = errors.New("static topology must have at least one host")
+	errNoDynamicService      = errors.New("dynamic topology must specify a service")
+	errInvalidStaticReplicas = errors.New("static topology replicas must be between one and the number of hosts")
+)
+
+// TopologyConfiguration is the configuration for the cluster topology,
+// exactly one of static or dynamic must be set.
+type TopologyConfiguration struct {
+	// Static is a static topology
+	Static *StaticTopologyConfiguration `yaml:"static"`
+
+	// Dynamic is a dynamic topology backed by m3cluster
+	Dynamic *DynamicTopologyConfiguration `yaml:"dynamic"`
+}
+
+// Validate validates the topology configuration.
+func (c TopologyConfiguration) Validate() error {
+	switch {
+	case c.Static == nil && c.Dynamic == nil:
+		return errNoTopology
+	case c.Static != nil && c.Dynamic != nil:
+		return errConflictingTopologies
+	case c.Static != nil:
+		return c.Static.Validate()
+	}
+	return c.Dynamic.Validate()
+}
+
+// NewInitializer creates a topology initializer from the configuration.
+func (c TopologyConfiguration) NewInitializer(
+	iopts instrument.Options,
+) (topology.Initializer, error) {
+	if c.Static != nil {
+		return c.Static.NewInitializer()
+	}
+	return c.Dynamic.NewInitializer(iopts)
+}
+
+// StaticHostConfiguration is the configuration for a host in a static topology.
+type StaticHostConfiguration struct {
+	// ID is the host ID
+	ID string `yaml:"id" validate:"nonzero"`
+
+	// Address is the node TChannel address of the host
+	Address string `yaml:"address" validate:"nonzero"`
+}
+
+// StaticTopologyConfiguration is the configuration for a static topology,
+// shards are assigned to hosts in round robin order.
+type StaticTopologyConfiguration struct {
+	// Shards is the number of shards
+	Shards int `yaml:"shards" validate:"min=1"`
+
+	// Replicas is the number of replicas of each shard
+	Replicas int `yaml:"replicas" validate:"min=1"`
+
+	// Hosts is the list of hosts
+	Hosts []StaticHostConfiguration `yaml:"hosts" validate:"nonzero"`
+}
+
+// Validate validates the static topology configuration.
+func (c StaticTopologyConfiguration) Validate() error {
+	if c.Shards <= 0 {
+		return errNoStaticShards
+	}
+	if len(c.Hosts) == 0 {
+		return errNoStaticHosts
+	}
+	if c.Replicas <= 0 || c.Replicas > len(c.Hosts) {
+		return errInvalidStaticReplicas
+	}
+	hosts := make(map[string]struct{}, len(c.Hosts))
+	for _, h := range c.Hosts {
+		if _, exists := hosts[h.ID]; exists {
+			return fmt.Errorf("duplicate static topology host %s", h.ID)
+		}
+		hosts[h.ID] = struct{}{}
+	}
+	return nil
+}
+
+// NewInitializer creates a static topology initializer.
+func (c StaticTopologyConfiguration) NewInitializer() (topology.Initializer, error) {
+	var (
+		numShards = uint32(c.Shards)
+		allIDs    = make([]uint32, 0, numShards)
+		hostIDs   = make([][]uint32, len(c.Hosts))
+	)
+	for i := uint32(0); i < numShards; i++ {
+		allIDs = append(allIDs, i)
+		for r := 0; r < c.Replicas; r++ {
+			idx := (int(i) + r) % len(c.Hosts)
+			hostIDs[idx] = append(hostIDs[idx], i)
+		}
+	}
+
+	hashGen := sharding.DefaultHashGen(int(numShards))
+	shardSet, err := sharding.NewShardSet(sharding.NewShards(allIDs, shard.Available), hashGen)
+	if err != nil {
+		return nil, err
+	}
+
+	hostShardSets := make([]topology.HostShardSet, 0, len(c.Hosts))
+	for i, h := range c.Hosts {
+		hostShards := sharding.NewShards(hostIDs[i], shard.Available)
+		hostShardSet, err := sharding.NewShardSet(hostShards, hashGen)
+		if err != nil {
+			return nil, err
+		}
+		host := topology.NewHost(h.ID, h.Address)
+		hostShardSets = append(hostShardSets, topology.NewHostShardSet(host, hostShardSet))
+	}
+
+	staticOptions := topology.NewStaticOptions().
+		SetShardSet(shardSet).
+		SetReplicas(c.Replicas).
+		SetHostShardSets(hostShardSets)
+	return topology.NewStaticInitializer(staticOptions), nil
+}
+
+// DynamicTopologyConfiguration is the configuration for a dynamic topology.
+type DynamicTopologyConfiguration struct {
+	// Service is the name of the m3db service in m3cluster
+	Service string `yaml:"service" validate:"nonzero"`
+
+	// InitTimeout is how long to wait for the topology to initialize
+	InitTimeout time.Duration `yaml:"initTimeout" validate:"min=0"`
+
+	// KV is the configuration of the m3cluster config service client
+	KV etcdclient.Configuration `yaml:"kv"`
+}
+
+// Validate validates the dynamic topology configuration.
+func (c DynamicTopologyConfiguration) Validate() error {
+	if c.Service == "" {
+		return errNoDynamicService
+	}
+	return nil
+}
+
+// NewInitializer creates a dynamic topology initializer.
+func (c DynamicTopologyConfiguration) NewInitializer(
+	iopts instrument.Options,
+) (topology.Initializer, error) {
+	configSvcClient, err := etcdclient.NewConfigServiceClient(c.KV.NewOptions())
+	if err != nil {
+		return nil, fmt.Errorf("could not create config service client: %v", err)
+	}
+
+	serviceID := services.NewServiceID().
+		SetName(c.Service).
+		SetEnvironment(c.KV.Env).
+		SetZone(c.KV.Zone)
+	opts := topology.NewDynamicOptions().
+		SetConfigServiceClient(configSvcClient).
+		SetServiceID(serviceID).
+		SetInstrumentOptions(iopts)
+	if c.InitTimeout > 0 {
+		opts = opts.SetInitTimeout(c.InitTimeout)
+	}
+	return topology.NewDynamicInitializer(opts), nil
+}
@@ -30,12 +30,16 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/client"
+	"github.com/m3db/m3db/services/m3dbnode/config"
 	"github.com/m3db/m3db/services/m3dbnode/server"
 	"github.com/m3db/m3db/storage"
 	"github.com/m3db/m3db/storage/cluster"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/topology"
 )
 
 var (
+	configFileArg          = flag.String("f", "", "Configuration file, flags other than this are ignored when set")
 	idArg                  = flag.String("id", "", "Node host ID")
 	httpClusterAddrArg     = flag.String("clusterhttpaddr", "0.0.0.0:9000", "Cluster HTTP server address")
 	tchannelClusterAddrArg = flag.String("clustertchanneladdr", "0.0.0.0:9001", "Cluster TChannel server address")
@@ -43,65 +47,49 @@ var (
 	tchannelNodeAddrArg    = flag.String("nodetchanneladdr", "0.0.0.0:9003", "Node TChannel server address")
 )
 
+type node struct {
+	id                  string
+	httpClusterAddr     string
+	tchannelClusterAddr string
+	httpNodeAddr        string
+	tchannelNodeAddr    string
+	db                  storage.Database
+	client              client.Client
+	storageOpts         storage.Options
+}
+
 func main() {
 	flag.Parse()
 
-	if *httpClusterAddrArg == "" ||
-		*tchannelClusterAddrArg == "" ||
-		*httpNodeAddrArg == "" ||
-		*tchannelNodeAddrArg == "" {
-		flag.Usage()
-		os.Exit(1)
-	}
-
-	id := *idArg
-	httpClusterAddr := *httpClusterAddrArg
-	tchannelClusterAddr := *tchannelClusterAddrArg
-	httpNodeAddr := *httpNodeAddrArg
-	tchannelNodeAddr := *tchannelNodeAddrArg
-
-	storageOpts := storage.NewOptions()
-	fileOpOpts := storageOpts.FileOpOptions().
-		SetRetentionOptions(storageOpts.RetentionOptions())
-	storageOpts = storageOpts.
-		SetFileOpOptions(fileOpOpts)
-
-	log := storageOpts.InstrumentOptions().Logger()
-	topoInit, err := server.DefaultTopologyInitializer(id, tchannelNodeAddr)
-	if err != nil {
-		log.Fatalf("could not create topology initializer: %v", err)
-	}
-
-	if id == "" {
-		id, err = os.Hostname()
-		if err != nil {
-			log.Fatalf("could not get hostname: %v", err)
+	var (
+		n   node
+		err error
+	)
+	if *configFileArg != "" {
+		n, err = newNodeFromConfig(*configFileArg)
+	} else {
+		if *httpClusterAddrArg == "" ||
+			*tchannelClusterAddrArg == "" ||
+			*httpNodeAddrArg == "" ||
+			*tchannelNodeAddrArg == "" {
+			flag.Usage()
+			os.Exit(1)
 		}
+		n, err = newNodeFromFlags()
 	}
 
-	cli, err := client.NewClient(server.DefaultClientOptions(topoInit))
+	log := n.storageOpts.InstrumentOptions().Logger()
 	if err != nil {
-		log.Fatalf("could not create cluster client: %v", err)
+		log.Fatalf("could not create node: %v", err)
 	}
 
-	repairOpts := storageOpts.RepairOptions().
-		SetAdminClient(cli.(client.AdminClient))
-	storageOpts = storageOpts.
-		SetRepairOptions(repairOpts)
-
-	namespaces := server.DefaultNamespaces()
-
-	db, err := cluster.NewDatabase(namespaces, id, topoInit, storageOpts)
-	if err != nil {
-		log.Fatalf("could not create database: %v", err)
-	}
 	doneCh := make(chan struct{}, 1)
 	closedCh := make(chan struct{}, 1)
 	go func() {
 		if err := server.OpenAndServe(
-			httpClusterAddr, tchannelClusterAddr,
-			httpNodeAddr, tchannelNodeAddr,
-			db, cli, storageOpts, doneCh,
+			n.httpClusterAddr, n.tchannelClusterAddr,
+			n.httpNodeAddr, n.tchannelNodeAddr,
+			n.db, n.client, n.storageOpts, doneCh,
 		); err != nil {
 			log.Fatalf("server fatal error: %v", err)
 		}
@@ -132,6 +120,124 @@ func main() {
 	}
 }
 
+func newNodeFromFlags() (node, error) {
+	n := node{
+		id:                  *idArg,
+		httpClusterAddr:     *httpClusterAddrArg,
+		tchannelClusterAddr: *tchannelClusterAddrArg,
+		httpNodeAddr:        *httpNodeAddrArg,
+		tchannelNodeAddr:    *tchannelNodeAddrArg,
+	}
+
+	storageOpts := storage.NewOptions()
+	fileOpOpts := storageOpts.FileOpOptions().
+		SetRetentionOptions(storageOpts.RetentionOptions())
+	n.storageOpts = storageOpts.
+		SetFileOpOptions(fileOpOpts)
+
+	topoInit, err := server.DefaultTopologyInitializer(n.id, n.tchannelNodeAddr)
+	if err != nil {
+		return n, fmt.Errorf("could not create topology initializer: %v", err)
+	}
+
+	if n.id == "" {
+		n.id, err = os.Hostname()
+		if err != nil {
+			return n, fmt.Errorf("could not get hostname: %v", err)
+		}
+	}
+
+	n.client, err = client.NewClient(server.DefaultClientOptions(topoInit))
+	if err != nil {
+		return n, fmt.Errorf("could not create cluster client: %v", err)
+	}
+
+	repairOpts := n.storageOpts.RepairOptions().
+		SetAdminClient(n.client.(client.AdminClient))
+	n.storageOpts = n.storageOpts.
+		SetRepairOptions(repairOpts)
+
+	namespaces := server.DefaultNamespaces(n.storageOpts.RetentionOptions())
+	registry, err := namespace.NewStaticInitializer(namespaces).Init()
+	if err != nil {
+		return n, fmt.Errorf("could not create namespace registry: %v", err)
+	}
+	n.storageOpts = n.storageOpts.SetNamespaceRegistry(registry)
+
+	n.db, err = cluster.NewDatabase(namespaces, n.id, topoInit, n.storageOpts)
+	if err != nil {
+		return n, fmt.Errorf("could not create database: %v", err)
+	}
+	return n, nil
+}
+
+func newNodeFromConfig(path string) (node, error) {
+	n := node{storageOpts: storage.NewOptions()}
+
+	cfg, err := config.New(path)
+	if err != nil {
+		return n, fmt.Errorf("could not load configuration: %v", err)
+	}
+
+	n.id, err = cfg.ResolveHostID()
+	if err != nil {
+		return n, fmt.Errorf("could not get hostname: %v", err)
+	}
+	n.httpClusterAddr = cfg.ListenAddresses.HTTPCluster
+	n.tchannelClusterAddr = cfg.ListenAddresses.TChannelCluster
+	n.httpNodeAddr = cfg.ListenAddresses.HTTPNode
+	n.tchannelNodeAddr = cfg.ListenAddresses.TChannelNode
+
+	n.storageOpts = cfg.StorageOptions(n.storageOpts)
+
+	topoInit, err := cfg.Topology.NewInitializer(n.storageOpts.InstrumentOptions())
+	if err != nil {
+		return n, fmt.Errorf("could not create topology initializer: %v", err)
+	}
+
+	clientOpts := cfg.Client.Options(server.DefaultClientOptions(topoInit)).
+		SetInstrumentOptions(n.storageOpts.InstrumentOptions())
+	adminOpts := clientOpts.(client.AdminOptions).
+		SetOrigin(topology.NewHost(n.id, n.tchannelNodeAddr))
+	adminClient, err := client.NewAdminClient(adminOpts)
+	if err != nil {
+		return n, fmt.Errorf("could not create cluster client: %v", err)
+	}
+	n.client = adminClient
+
+	repairOpts := n.storageOpts.RepairOptions().
+		SetAdminClient(adminClient)
+	n.storageOpts = n.storageOpts.
+		SetRepairOptions(repairOpts)
+
+	blockRetrieverMgr := cfg.NewBlockRetrieverManager(n.storageOpts)
+	n.storageOpts = n.storageOpts.
+		SetDatabaseBlockRetrieverManager(blockRetrieverMgr)
+
+	bs, err := cfg.Bootstrap.New(n.storageOpts, adminClient, blockRetrieverMgr)
+	if err != nil {
+		return n, fmt.Errorf("could not create bootstrap process: %v", err)
+	}
+	n.storageOpts = n.storageOpts.SetBootstrapProcess(bs)
+
+	nsInit, err := cfg.NewNamespaceInitializer(n.storageOpts.InstrumentOptions())
+	if err != nil {
+		return n, fmt.Errorf("could not create namespace initializer: %v", err)
+	}
+	registry, err := nsInit.Init()
+	if err != nil {
+		return n, fmt.Errorf("could not create namespace registry: %v", err)
+	}
+	n.storageOpts = n.storageOpts.SetNamespaceRegistry(registry)
+
+	namespaces := registry.Get().Metadatas()
+	n.db, err = cluster.NewDatabase(namespaces, n.id, topoInit, n.storageOpts)
+	if err != nil {
+		return n, fmt.Errorf("could not create database: %v", err)
+	}
+	return n, nil
+}
+
 func interrupt() error {
 	c := make(chan os.Signal)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
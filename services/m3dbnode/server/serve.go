@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3db/network/server/tchannelthrift"
 	ttcluster "github.com/m3db/m3db/network/server/tchannelthrift/cluster"
 	ttnode "github.com/m3db/m3db/network/server/tchannelthrift/node"
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage"
 	"github.com/m3db/m3db/storage/namespace"
@@ -88,9 +89,9 @@ func DefaultTopologyInitializerForShardSet(
 	return topology.NewStaticInitializer(staticOptions), nil
 }
 
-// DefaultNamespaces creates a list of default namespaces
-func DefaultNamespaces() []namespace.Metadata {
-	opts := namespace.NewOptions()
+// DefaultNamespaces creates a list of default namespaces with the given retention options
+func DefaultNamespaces(retentionOpts retention.Options) []namespace.Metadata {
+	opts := namespace.NewOptions().SetRetentionOptions(retentionOpts)
 	return []namespace.Metadata{
 		namespace.NewMetadata(ts.StringID(defaultNamespaceName), opts),
 	}
@@ -28,6 +28,7 @@ import (
 
 	context "github.com/m3db/m3db/context"
 	encoding "github.com/m3db/m3db/encoding"
+	namespace "github.com/m3db/m3db/storage/namespace"
 	ts "github.com/m3db/m3db/ts"
 	io "github.com/m3db/m3db/x/io"
 	clock "github.com/m3db/m3x/clock"
@@ -504,8 +505,8 @@ func (_m *MockDatabaseBlockRetrieverManager) EXPECT() *_MockDatabaseBlockRetriev
 	return _m.recorder
 }
 
-func (_m *MockDatabaseBlockRetrieverManager) Retriever(namespace ts.ID) (DatabaseBlockRetriever, error) {
-	ret := _m.ctrl.Call(_m, "Retriever", namespace)
+func (_m *MockDatabaseBlockRetrieverManager) Retriever(nsMetadata namespace.Metadata) (DatabaseBlockRetriever, error) {
+	ret := _m.ctrl.Call(_m, "Retriever", nsMetadata)
 	ret0, _ := ret[0].(DatabaseBlockRetriever)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
@@ -515,6 +516,16 @@ func (_mr *_MockDatabaseBlockRetrieverManagerRecorder) Retriever(arg0 interface{
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Retriever", arg0)
 }
 
+func (_m *MockDatabaseBlockRetrieverManager) CloseRetriever(namespace ts.ID) error {
+	ret := _m.ctrl.Call(_m, "CloseRetriever", namespace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseBlockRetrieverManagerRecorder) CloseRetriever(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "CloseRetriever", arg0)
+}
+
 // Mock of DatabaseShardBlockRetrieverManager interface
 type MockDatabaseShardBlockRetrieverManager struct {
 	ctrl     *gomock.Controller
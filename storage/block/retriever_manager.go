@@ -21,9 +21,11 @@
 package block
 
 import (
+	"io"
 	"sync"
 	"time"
 
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 )
@@ -31,7 +33,7 @@ import (
 // NewDatabaseBlockRetrieverFn is a method for constructing
 // new database block retrievers
 type NewDatabaseBlockRetrieverFn func(
-	namespace ts.ID,
+	nsMetadata namespace.Metadata,
 ) (DatabaseBlockRetriever, error)
 
 // NewDatabaseBlockRetrieverManager creates a new manager
@@ -52,8 +54,10 @@ type blockRetrieverManager struct {
 }
 
 func (m *blockRetrieverManager) Retriever(
-	namespace ts.ID,
+	nsMetadata namespace.Metadata,
 ) (DatabaseBlockRetriever, error) {
+	namespace := nsMetadata.ID()
+
 	m.RLock()
 	retriever, ok := m.retrievers[namespace.Hash()]
 	m.RUnlock()
@@ -71,7 +75,7 @@ func (m *blockRetrieverManager) Retriever(
 	}
 
 	var err error
-	retriever, err = m.newRetrieverFn(namespace)
+	retriever, err = m.newRetrieverFn(nsMetadata)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +84,21 @@ func (m *blockRetrieverManager) Retriever(
 	return retriever, nil
 }
 
+func (m *blockRetrieverManager) CloseRetriever(namespace ts.ID) error {
+	m.Lock()
+	retriever, ok := m.retrievers[namespace.Hash()]
+	delete(m.retrievers, namespace.Hash())
+	m.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if closer, ok := retriever.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 type shardBlockRetriever struct {
 	DatabaseBlockRetriever
 	shard uint32
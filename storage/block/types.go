@@ -25,6 +25,7 @@ import (
 
 	"github.com/m3db/m3db/context"
 	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/topology"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
@@ -243,7 +244,11 @@ type DatabaseShardBlockRetriever interface {
 // DatabaseBlockRetrieverManager creates and holds block retrievers
 // for different namespaces.
 type DatabaseBlockRetrieverManager interface {
-	Retriever(namespace ts.ID) (DatabaseBlockRetriever, error)
+	Retriever(nsMetadata namespace.Metadata) (DatabaseBlockRetriever, error)
+
+	// CloseRetriever closes and removes the retriever for a namespace
+	// releasing the seekers and files it holds open.
+	CloseRetriever(namespace ts.ID) error
 }
 
 // DatabaseShardBlockRetrieverManager creates and holds shard block
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/log"
@@ -58,6 +59,15 @@ var (
 	// errShardNotBootstrappedToFlush raised when trying to flush data for a shard that's not yet bootstrapped.
 	errShardNotBootstrappedToFlush = errors.New("shard is not yet bootstrapped to flush")
 
+	// errShardNotBootstrappedToLoad raised when trying to load data for a shard that's not yet bootstrapped.
+	errShardNotBootstrappedToLoad = errors.New("shard is not yet bootstrapped to load")
+
+	// errShardNotBootstrappedToRollup raised when trying to roll up data into a shard that's not yet bootstrapped.
+	errShardNotBootstrappedToRollup = errors.New("shard is not yet bootstrapped to roll up")
+
+	// errShardNotBootstrappedToSnapshot raised when trying to snapshot data for a shard that's not yet bootstrapped.
+	errShardNotBootstrappedToSnapshot = errors.New("shard is not yet bootstrapped to snapshot")
+
 	// errShardNotBootstrappedToRead raised when trying to read data for a shard that's not yet bootstrapped.
 	errShardNotBootstrappedToRead = errors.New("shard is not yet bootstrapped to read")
 
@@ -169,8 +179,10 @@ func (m *bootstrapManager) Report() {
 	}
 }
 
-func (m *bootstrapManager) targetRanges(at time.Time) []bootstrap.TargetRange {
-	ropts := m.opts.RetentionOptions()
+func (m *bootstrapManager) targetRanges(
+	ropts retention.Options,
+	at time.Time,
+) []bootstrap.TargetRange {
 	start := at.Add(-ropts.RetentionPeriod()).
 		Truncate(ropts.BlockSize())
 	midPoint := at.
@@ -201,13 +213,15 @@ func (m *bootstrapManager) targetRanges(at time.Time) []bootstrap.TargetRange {
 }
 
 func (m *bootstrapManager) bootstrap() error {
-	targetRanges := m.targetRanges(m.nowFn())
-
 	// NB(xichen): each bootstrapper should be responsible for choosing the most
 	// efficient way of bootstrapping database shards, be it sequential or parallel.
 	multiErr := xerrors.NewMultiError()
 
+	now := m.nowFn()
 	for _, namespace := range m.database.getOwnedNamespaces() {
+		// Each namespace is bootstrapped for the ranges covered by its own retention
+		targetRanges := m.targetRanges(namespace.Options().RetentionOptions(), now)
+
 		start := m.nowFn()
 		if err := namespace.Bootstrap(m.process, targetRanges); err != nil {
 			multiErr = multiErr.Add(err)
@@ -25,7 +25,7 @@ import (
 
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
-	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/log"
 )
@@ -68,7 +68,7 @@ func (b *baseBootstrapper) Can(strategy bootstrap.Strategy) bool {
 }
 
 func (b *baseBootstrapper) Bootstrap(
-	namespace ts.ID,
+	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 	opts bootstrap.RunOptions,
 ) (result.BootstrapResult, error) {
@@ -76,7 +76,7 @@ func (b *baseBootstrapper) Bootstrap(
 		return nil, nil
 	}
 
-	available := b.src.Available(namespace, shardsTimeRanges)
+	available := b.src.Available(ns, shardsTimeRanges)
 	remaining := shardsTimeRanges.Copy()
 	remaining.Subtract(available)
 
@@ -92,7 +92,7 @@ func (b *baseBootstrapper) Bootstrap(
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			nextResult, nextErr = b.next.Bootstrap(namespace, remaining, opts)
+			nextResult, nextErr = b.next.Bootstrap(ns, remaining, opts)
 		}()
 	}
 
@@ -109,7 +109,7 @@ func (b *baseBootstrapper) Bootstrap(
 	nowFn := b.opts.ClockOptions().NowFn()
 	begin := nowFn()
 
-	currResult, currErr = b.src.Read(namespace, available, opts)
+	currResult, currErr = b.src.Read(ns, available, opts)
 
 	logFields = append(logFields, xlog.NewLogField("took", nowFn().Sub(begin).String()))
 	if currErr != nil {
@@ -146,7 +146,7 @@ func (b *baseBootstrapper) Bootstrap(
 	// If there are some time ranges the current bootstrapper could not fulfill,
 	// pass it along to the next bootstrapper
 	if !currUnfulfilled.IsEmpty() {
-		nextResult, nextErr = b.next.Bootstrap(namespace, currUnfulfilled, opts)
+		nextResult, nextErr = b.next.Bootstrap(ns, currUnfulfilled, opts)
 		if nextErr != nil {
 			return nil, nextErr
 		}
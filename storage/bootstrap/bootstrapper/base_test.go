@@ -27,6 +27,7 @@ import (
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/time"
 
@@ -34,7 +35,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-var testNamespaceID = ts.StringID("testNamespace")
+var (
+	testNamespaceID = ts.StringID("testNamespace")
+	testNsMetadata  = namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+)
 
 var (
 	testTargetStart    = time.Now()
@@ -208,11 +212,11 @@ func TestBaseBootstrapperEmptyRange(t *testing.T) {
 	_, _, base := testBaseBootstrapper(t, ctrl)
 
 	// Test non-nil empty range
-	res, err := base.Bootstrap(testNamespaceID, map[uint32]xtime.Ranges{}, testDefaultRunOpts)
+	res, err := base.Bootstrap(testNsMetadata, map[uint32]xtime.Ranges{}, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.Nil(t, res)
 
-	res, err = base.Bootstrap(testNamespaceID, nil, testDefaultRunOpts)
+	res, err = base.Bootstrap(testNsMetadata, nil, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.Nil(t, res)
 }
@@ -228,13 +232,13 @@ func TestBaseBootstrapperCurrentNoUnfulfilled(t *testing.T) {
 	})
 
 	source.EXPECT().
-		Available(testNamespaceID, targetRanges).
+		Available(testNsMetadata, targetRanges).
 		Return(targetRanges)
 	source.EXPECT().
-		Read(testNamespaceID, targetRanges, testDefaultRunOpts).
+		Read(testNsMetadata, targetRanges, testDefaultRunOpts).
 		Return(result, nil)
 
-	res, err := base.Bootstrap(testNamespaceID, targetRanges, testDefaultRunOpts)
+	res, err := base.Bootstrap(testNsMetadata, targetRanges, testDefaultRunOpts)
 	require.NoError(t, err)
 	validateResult(t, result, res)
 }
@@ -265,13 +269,13 @@ func TestBaseBootstrapperCurrentSomeUnfulfilled(t *testing.T) {
 	})
 
 	source.EXPECT().
-		Available(testNamespaceID, targetRanges).
+		Available(testNsMetadata, targetRanges).
 		Return(targetRanges)
 	source.EXPECT().
-		Read(testNamespaceID, targetRanges, testDefaultRunOpts).
+		Read(testNsMetadata, targetRanges, testDefaultRunOpts).
 		Return(currResult, nil)
 	next.EXPECT().
-		Bootstrap(testNamespaceID, shardTimeRangesMatcher{nextTargetRanges},
+		Bootstrap(testNsMetadata, shardTimeRangesMatcher{nextTargetRanges},
 			testDefaultRunOpts).
 		Return(nextResult, nil)
 
@@ -279,7 +283,7 @@ func TestBaseBootstrapperCurrentSomeUnfulfilled(t *testing.T) {
 		testShard: {result: shardResult(entries...)},
 	})
 
-	res, err := base.Bootstrap(testNamespaceID, targetRanges,
+	res, err := base.Bootstrap(testNsMetadata, targetRanges,
 		testDefaultRunOpts)
 	require.NoError(t, err)
 	validateResult(t, expectedResult, res)
@@ -299,18 +303,18 @@ func testBasebootstrapperNext(t *testing.T, nextUnfulfilled result.ShardTimeRang
 	})
 
 	source.EXPECT().
-		Available(testNamespaceID, targetRanges).
+		Available(testNsMetadata, targetRanges).
 		Return(nil)
 	source.EXPECT().
-		Read(testNamespaceID, shardTimeRangesMatcher{nil},
+		Read(testNsMetadata, shardTimeRangesMatcher{nil},
 			testDefaultRunOpts).
 		Return(nil, nil)
 	next.EXPECT().
-		Bootstrap(testNamespaceID, shardTimeRangesMatcher{targetRanges},
+		Bootstrap(testNsMetadata, shardTimeRangesMatcher{targetRanges},
 			testDefaultRunOpts).
 		Return(nextResult, nil)
 
-	res, err := base.Bootstrap(testNamespaceID, targetRanges,
+	res, err := base.Bootstrap(testNsMetadata, targetRanges,
 		testDefaultRunOpts)
 	require.NoError(t, err)
 	validateResult(t, nextResult, res)
@@ -375,22 +379,22 @@ func TestBaseBootstrapperBoth(t *testing.T) {
 	})
 
 	source.EXPECT().Can(bootstrap.BootstrapParallel).Return(true)
-	source.EXPECT().Available(testNamespaceID, targetRanges).Return(availableRanges)
+	source.EXPECT().Available(testNsMetadata, targetRanges).Return(availableRanges)
 	source.EXPECT().
-		Read(testNamespaceID, shardTimeRangesMatcher{availableRanges},
+		Read(testNsMetadata, shardTimeRangesMatcher{availableRanges},
 			testDefaultRunOpts).
 		Return(currResult, nil)
 	next.EXPECT().Can(bootstrap.BootstrapParallel).Return(true)
 	next.EXPECT().
-		Bootstrap(testNamespaceID, shardTimeRangesMatcher{remainingRanges},
+		Bootstrap(testNsMetadata, shardTimeRangesMatcher{remainingRanges},
 			testDefaultRunOpts).
 		Return(nextResult, nil)
 	next.EXPECT().
-		Bootstrap(testNamespaceID, shardTimeRangesMatcher{currResult.Unfulfilled()},
+		Bootstrap(testNsMetadata, shardTimeRangesMatcher{currResult.Unfulfilled()},
 			testDefaultRunOpts).
 		Return(fallBackResult, nil)
 
-	res, err := base.Bootstrap(testNamespaceID, targetRanges,
+	res, err := base.Bootstrap(testNsMetadata, targetRanges,
 		testDefaultRunOpts)
 	require.NoError(t, err)
 
@@ -21,21 +21,28 @@
 package commitlog
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
 	"github.com/m3db/m3db/persist/fs/commitlog"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/time"
 )
 
-type newIteratorFn func(opts commitlog.Options) (commitlog.Iterator, error)
+type newIteratorFn func(
+	opts commitlog.Options,
+	filter commitlog.FileFilterPredicate,
+	seriesFilter commitlog.SeriesFilterPredicate,
+) (commitlog.Iterator, error)
 
 type commitLogSource struct {
 	opts          Options
@@ -69,7 +76,7 @@ func (s *commitLogSource) Can(strategy bootstrap.Strategy) bool {
 }
 
 func (s *commitLogSource) Available(
-	namespace ts.ID,
+	_ namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 ) result.ShardTimeRanges {
 	// Commit log bootstrapper is a last ditch effort, so fulfill all
@@ -79,7 +86,7 @@ func (s *commitLogSource) Available(
 }
 
 func (s *commitLogSource) Read(
-	namespace ts.ID,
+	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 	_ bootstrap.RunOptions,
 ) (result.BootstrapResult, error) {
@@ -87,21 +94,49 @@ func (s *commitLogSource) Read(
 		return nil, nil
 	}
 
-	iter, err := s.newIteratorFn(s.opts.CommitLogOptions())
+	var (
+		unmerged       = make(map[uint32]map[ts.Hash]encoderMap)
+		bopts          = s.opts.ResultOptions()
+		blopts         = bopts.DatabaseBlockOptions()
+		blockSize      = ns.Options().RetentionOptions().BlockSize()
+		encoderPool    = bopts.DatabaseBlockOptions().EncoderPool()
+		filePathPrefix = s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
+		filter         = commitlog.ReadAllPredicate()
+		errs           = 0
+	)
+
+	// Load the latest snapshot so that only the commit logs written after
+	// the snapshot began need to be replayed
+	snapshot, hasSnapshot, err := fs.LatestSnapshotMetadata(filePathPrefix)
+	if err != nil {
+		s.log.Errorf("error reading snapshot metadata, replaying all commit logs: %v", err)
+	}
+	if hasSnapshot {
+		snapshotErrs, err := s.readSnapshot(ns, shardsTimeRanges, snapshot, unmerged)
+		errs += snapshotErrs
+		if err != nil {
+			s.log.Errorf("error reading snapshot %d, replaying all commit logs: %v",
+				snapshot.Index, err)
+		} else {
+			filter = func(f commitlog.File) bool {
+				return !snapshot.Covers(f.Start, f.Index)
+			}
+		}
+	}
+
+	// Only read the entries of the shards being bootstrapped for this namespace
+	seriesFilter := func(namespace ts.ID, shard uint32) bool {
+		_, ok := shardsTimeRanges[shard]
+		return ok && namespace.Equal(ns.ID())
+	}
+
+	iter, err := s.newIteratorFn(s.opts.CommitLogOptions(), filter, seriesFilter)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create commit log iterator: %v", err)
 	}
 
 	defer iter.Close()
 
-	var (
-		unmerged    = make(map[uint32]map[ts.Hash]encoderMap)
-		bopts       = s.opts.ResultOptions()
-		blopts      = bopts.DatabaseBlockOptions()
-		blockSize   = bopts.RetentionOptions().BlockSize()
-		encoderPool = bopts.DatabaseBlockOptions().EncoderPool()
-		errs        = 0
-	)
 	for iter.Next() {
 		series, dp, unit, annotation := iter.Current()
 		ranges, ok := shardsTimeRanges[series.Shard]
@@ -121,46 +156,7 @@ func (s *commitLogSource) Read(
 			continue
 		}
 
-		unmergedShard, ok := unmerged[series.Shard]
-		if !ok {
-			unmergedShard = make(map[ts.Hash]encoderMap)
-			unmerged[series.Shard] = unmergedShard
-		}
-
-		unmergedSeries, ok := unmergedShard[series.ID.Hash()]
-		if !ok {
-			unmergedSeries = encoderMap{
-				id:       series.ID,
-				encoders: make(map[time.Time][]encoder)}
-			unmergedShard[series.ID.Hash()] = unmergedSeries
-		}
-
-		var (
-			err           error
-			unmergedBlock = unmergedSeries.encoders[blockStart]
-			wroteExisting = false
-		)
-		for i := range unmergedBlock {
-			if unmergedBlock[i].lastWriteAt.Before(dp.Timestamp) {
-				unmergedBlock[i].lastWriteAt = dp.Timestamp
-				err = unmergedBlock[i].enc.Encode(dp, unit, annotation)
-				wroteExisting = true
-				break
-			}
-		}
-		if !wroteExisting {
-			enc := encoderPool.Get()
-			enc.Reset(blockStart, blopts.DatabaseBlockAllocSize())
-
-			err = enc.Encode(dp, unit, annotation)
-			if err == nil {
-				unmergedBlock = append(unmergedBlock, encoder{
-					lastWriteAt: dp.Timestamp,
-					enc:         enc,
-				})
-				unmergedSeries.encoders[blockStart] = unmergedBlock
-			}
-		}
+		err := s.encode(unmerged, series.Shard, series.ID, blockStart, dp, unit, annotation)
 		if err != nil {
 			errs++
 		}
@@ -169,6 +165,9 @@ func (s *commitLogSource) Read(
 		s.log.Errorf("error bootstrapping from commit log: %d block encode errors", errs)
 	}
 	if err := iter.Err(); err != nil {
+		if s.opts.CommitLogOptions().ReadCorruptionPolicy() == commitlog.ReadCorruptionPolicyFail {
+			return nil, fmt.Errorf("error reading commit log: %v", err)
+		}
 		s.log.Errorf("error reading commit log: %v", err)
 	}
 
@@ -248,3 +247,120 @@ func (s *commitLogSource) Read(
 
 	return bootstrapResult, nil
 }
+
+// encode encodes a datapoint into the unmerged blocks of a series, starting
+// a new encoder for the block if the datapoint is out of order.
+func (s *commitLogSource) encode(
+	unmerged map[uint32]map[ts.Hash]encoderMap,
+	shard uint32,
+	id ts.ID,
+	blockStart time.Time,
+	dp ts.Datapoint,
+	unit xtime.Unit,
+	annotation ts.Annotation,
+) error {
+	unmergedShard, ok := unmerged[shard]
+	if !ok {
+		unmergedShard = make(map[ts.Hash]encoderMap)
+		unmerged[shard] = unmergedShard
+	}
+
+	unmergedSeries, ok := unmergedShard[id.Hash()]
+	if !ok {
+		unmergedSeries = encoderMap{
+			id:       id,
+			encoders: make(map[time.Time][]encoder)}
+		unmergedShard[id.Hash()] = unmergedSeries
+	}
+
+	unmergedBlock := unmergedSeries.encoders[blockStart]
+	for i := range unmergedBlock {
+		if unmergedBlock[i].lastWriteAt.Before(dp.Timestamp) {
+			unmergedBlock[i].lastWriteAt = dp.Timestamp
+			return unmergedBlock[i].enc.Encode(dp, unit, annotation)
+		}
+	}
+
+	blopts := s.opts.ResultOptions().DatabaseBlockOptions()
+	enc := blopts.EncoderPool().Get()
+	enc.Reset(blockStart, blopts.DatabaseBlockAllocSize())
+	if err := enc.Encode(dp, unit, annotation); err != nil {
+		return err
+	}
+	unmergedSeries.encoders[blockStart] = append(unmergedBlock, encoder{
+		lastWriteAt: dp.Timestamp,
+		enc:         enc,
+	})
+	return nil
+}
+
+// readSnapshot encodes the data of a snapshot for the requested ranges into
+// the unmerged blocks, returning the number of datapoints that failed to encode.
+func (s *commitLogSource) readSnapshot(
+	ns namespace.Metadata,
+	shardsTimeRanges result.ShardTimeRanges,
+	snapshot fs.SnapshotMetadata,
+	unmerged map[uint32]map[ts.Hash]encoderMap,
+) (int, error) {
+	var (
+		fsOpts      = s.opts.CommitLogOptions().FilesystemOptions()
+		snapshotDir = fs.SnapshotDirPath(fsOpts.FilePathPrefix(), snapshot.Index)
+		blockSize   = ns.Options().RetentionOptions().BlockSize()
+		iterPool    = s.opts.ResultOptions().DatabaseBlockOptions().ReaderIteratorPool()
+		errs        = 0
+	)
+	for shard, ranges := range shardsTimeRanges {
+		blockStarts := make(map[time.Time]struct{})
+		it := ranges.Iter()
+		for it.Next() {
+			r := it.Value()
+			for t := r.Start.Truncate(blockSize); t.Before(r.End); t = t.Add(blockSize) {
+				blockStarts[t] = struct{}{}
+			}
+		}
+
+		for blockStart := range blockStarts {
+			if !fs.FilesetExistsAt(snapshotDir, ns.ID(), shard, blockStart) {
+				continue
+			}
+			reader := fs.NewReader(snapshotDir, fsOpts.ReaderBufferSize(), nil, fsOpts.DecodingOptions())
+			if err := reader.Open(ns.ID(), shard, blockStart); err != nil {
+				return errs, err
+			}
+			for {
+				id, data, _, err := reader.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					reader.Close()
+					return errs, err
+				}
+
+				data.IncRef()
+				iter := iterPool.Get()
+				iter.Reset(bytes.NewReader(data.Get()))
+				for iter.Next() {
+					dp, unit, annotation := iter.Current()
+					if err := s.encode(unmerged, shard, id, blockStart, dp, unit, annotation); err != nil {
+						errs++
+					}
+				}
+				err = iter.Err()
+				iter.Close()
+				data.DecRef()
+				data.Finalize()
+				if err != nil {
+					reader.Close()
+					return errs, err
+				}
+			}
+			err := reader.Validate()
+			reader.Close()
+			if err != nil {
+				return errs, err
+			}
+		}
+	}
+	return errs, nil
+}
@@ -23,17 +23,24 @@ package commitlog
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/digest"
 	"github.com/m3db/m3db/encoding"
 	"github.com/m3db/m3db/encoding/m3tsz"
+	"github.com/m3db/m3db/persist/fs"
 	"github.com/m3db/m3db/persist/fs/commitlog"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/time"
 
 	"github.com/stretchr/testify/require"
@@ -41,6 +48,7 @@ import (
 
 var (
 	testNamespaceID    = ts.StringID("testnamespace")
+	testNsMetadata     = namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
 	testDefaultRunOpts = bootstrap.NewRunOptions().SetIncremental(true)
 )
 
@@ -72,7 +80,7 @@ func testOptions() Options {
 func TestAvailableEmptyRangeError(t *testing.T) {
 	opts := testOptions()
 	src := newCommitLogSource(opts)
-	res := src.Available(testNamespaceID, result.ShardTimeRanges{})
+	res := src.Available(testNsMetadata, result.ShardTimeRanges{})
 	require.True(t, result.ShardTimeRanges{}.Equal(res))
 }
 
@@ -81,7 +89,7 @@ func TestReadEmpty(t *testing.T) {
 
 	src := newCommitLogSource(opts)
 
-	res, err := src.Read(testNamespaceID, result.ShardTimeRanges{},
+	res, err := src.Read(testNsMetadata, result.ShardTimeRanges{},
 		testDefaultRunOpts)
 	require.Nil(t, res)
 	require.Nil(t, err)
@@ -91,7 +99,11 @@ func TestReadErrorOnNewIteratorError(t *testing.T) {
 	opts := testOptions()
 	src := newCommitLogSource(opts).(*commitLogSource)
 
-	src.newIteratorFn = func(_ commitlog.Options) (commitlog.Iterator, error) {
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
 		return nil, fmt.Errorf("an error")
 	}
 
@@ -100,7 +112,7 @@ func TestReadErrorOnNewIteratorError(t *testing.T) {
 		Start: time.Now(),
 		End:   time.Now().Add(time.Hour),
 	})
-	res, err := src.Read(testNamespaceID, result.ShardTimeRanges{0: ranges},
+	res, err := src.Read(testNsMetadata, result.ShardTimeRanges{0: ranges},
 		testDefaultRunOpts)
 	require.Error(t, err)
 	require.Nil(t, res)
@@ -136,12 +148,16 @@ func TestReadOrderedValues(t *testing.T) {
 		// "baz" is in shard 2 and should not be returned
 		{baz, start.Add(4 * time.Minute), 1.0, xtime.Second, nil},
 	}
-	src.newIteratorFn = func(_ commitlog.Options) (commitlog.Iterator, error) {
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
 		return newTestCommitLogIterator(values, nil), nil
 	}
 
 	targetRanges := result.ShardTimeRanges{0: ranges, 1: ranges}
-	res, err := src.Read(testNamespaceID, targetRanges, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, targetRanges, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, 2, len(res.ShardResults()))
@@ -149,6 +165,82 @@ func TestReadOrderedValues(t *testing.T) {
 	requireShardResults(t, values[:4], res.ShardResults(), opts)
 }
 
+func TestReadFiltersNamespaceAndShards(t *testing.T) {
+	opts := testOptions()
+	src := newCommitLogSource(opts).(*commitLogSource)
+
+	var seriesFilter commitlog.SeriesFilterPredicate
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		filter commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
+		seriesFilter = filter
+		return newTestCommitLogIterator(nil, nil), nil
+	}
+
+	ranges := xtime.NewRanges()
+	ranges = ranges.AddRange(xtime.Range{
+		Start: time.Now(),
+		End:   time.Now().Add(time.Hour),
+	})
+	_, err := src.Read(testNsMetadata, result.ShardTimeRanges{0: ranges, 1: ranges},
+		testDefaultRunOpts)
+	require.NoError(t, err)
+
+	require.NotNil(t, seriesFilter)
+	require.True(t, seriesFilter(testNamespaceID, 0))
+	require.True(t, seriesFilter(testNamespaceID, 1))
+	require.False(t, seriesFilter(testNamespaceID, 2))
+	require.False(t, seriesFilter(ts.StringID("other"), 0))
+}
+
+func TestReadAppliesSeriesFilter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commitlogs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := testOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	clOpts = clOpts.SetFilesystemOptions(fsOpts)
+	opts = opts.SetCommitLogOptions(clOpts)
+	src := newCommitLogSource(opts)
+
+	blockSize := opts.ResultOptions().RetentionOptions().BlockSize()
+	now := time.Now()
+	start := now.Truncate(blockSize).Add(-blockSize)
+	ranges := xtime.NewRanges()
+	ranges = ranges.AddRange(xtime.Range{Start: start, End: now})
+
+	foo := commitlog.Series{UniqueIndex: 0, Namespace: testNamespaceID, Shard: 0, ID: ts.StringID("foo")}
+	bar := commitlog.Series{UniqueIndex: 1, Namespace: testNamespaceID, Shard: 2, ID: ts.StringID("bar")}
+	baz := commitlog.Series{UniqueIndex: 2, Namespace: ts.StringID("other"), Shard: 0, ID: ts.StringID("baz")}
+	values := []testValue{
+		{foo, start, 1.0, xtime.Second, nil},
+		{bar, start.Add(time.Minute), 2.0, xtime.Second, nil},
+		{baz, start.Add(2 * time.Minute), 3.0, xtime.Second, nil},
+		{foo, start.Add(3 * time.Minute), 4.0, xtime.Second, nil},
+	}
+
+	commitLog := commitlog.NewCommitLog(clOpts)
+	require.NoError(t, commitLog.Open())
+	ctx := context.NewContext()
+	for _, v := range values {
+		dp := ts.Datapoint{Timestamp: v.t, Value: v.v}
+		require.NoError(t, commitLog.WriteBehind(ctx, v.s, dp, v.u, v.a))
+	}
+	ctx.Close()
+	require.NoError(t, commitLog.Close())
+
+	// Only the entries of the series in the namespace and shards being
+	// bootstrapped are read from the commit log
+	res, err := src.Read(testNsMetadata, result.ShardTimeRanges{0: ranges}, testDefaultRunOpts)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	requireShardResults(t, []testValue{values[0], values[3]}, res.ShardResults(), opts)
+}
+
 func TestReadUnorderedValues(t *testing.T) {
 	opts := testOptions()
 	src := newCommitLogSource(opts).(*commitLogSource)
@@ -176,12 +268,16 @@ func TestReadUnorderedValues(t *testing.T) {
 		{foo, start.Add(3 * time.Minute), 4.0, xtime.Second, nil},
 		{foo, start, 5.0, xtime.Second, nil},
 	}
-	src.newIteratorFn = func(_ commitlog.Options) (commitlog.Iterator, error) {
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
 		return newTestCommitLogIterator(values, nil), nil
 	}
 
 	targetRanges := result.ShardTimeRanges{0: ranges, 1: ranges}
-	res, err := src.Read(testNamespaceID, targetRanges, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, targetRanges, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, 1, len(res.ShardResults()))
@@ -215,12 +311,16 @@ func TestReadTrimsToRanges(t *testing.T) {
 		{foo, start.Add(1 * time.Minute), 3.0, xtime.Nanosecond, nil},
 		{foo, end.Truncate(blockSize).Add(blockSize).Add(time.Nanosecond), 4.0, xtime.Nanosecond, nil},
 	}
-	src.newIteratorFn = func(_ commitlog.Options) (commitlog.Iterator, error) {
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
 		return newTestCommitLogIterator(values, nil), nil
 	}
 
 	targetRanges := result.ShardTimeRanges{0: ranges, 1: ranges}
-	res, err := src.Read(testNamespaceID, targetRanges, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, targetRanges, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, 1, len(res.ShardResults()))
@@ -228,6 +328,74 @@ func TestReadTrimsToRanges(t *testing.T) {
 	requireShardResults(t, values[1:3], res.ShardResults(), opts)
 }
 
+func TestReadFromSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts := testOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	opts = opts.SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts))
+	src := newCommitLogSource(opts).(*commitLogSource)
+
+	blockSize := opts.ResultOptions().RetentionOptions().BlockSize()
+	now := time.Now()
+	start := now.Truncate(blockSize).Add(-blockSize)
+	ranges := xtime.NewRanges()
+	ranges = ranges.AddRange(xtime.Range{Start: start, End: now})
+
+	foo := commitlog.Series{Shard: 0, ID: ts.StringID("foo")}
+	snapshotValues := []testValue{
+		{foo, start, 1.0, xtime.Second, nil},
+		{foo, start.Add(time.Minute), 2.0, xtime.Second, nil},
+	}
+	commitLogValues := []testValue{
+		{foo, start.Add(2 * time.Minute), 3.0, xtime.Second, nil},
+	}
+
+	// Write the snapshot values to a snapshot fileset
+	enc := opts.ResultOptions().DatabaseBlockOptions().EncoderPool().Get()
+	enc.Reset(start, 0)
+	for _, v := range snapshotValues {
+		require.NoError(t, enc.Encode(ts.Datapoint{Timestamp: v.t, Value: v.v}, v.u, v.a))
+	}
+	segment := enc.Discard()
+	w := fs.NewWriter(blockSize, fs.SnapshotDirPath(dir, 1), fsOpts.WriterBufferSize(),
+		fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), fsOpts.Compression())
+	require.NoError(t, w.Open(testNamespaceID, foo.Shard, start))
+	require.NoError(t, w.WriteAll(foo.ID, []checked.Bytes{segment.Head, segment.Tail},
+		digest.SegmentChecksum(segment)))
+	require.NoError(t, w.Close())
+
+	commitLogStart := start.Add(time.Hour)
+	require.NoError(t, fs.WriteSnapshotMetadata(dir, fs.SnapshotMetadata{
+		Index:          1,
+		CommitLogStart: commitLogStart,
+		CommitLogIndex: 0,
+	}, fsOpts.NewFileMode(), fsOpts.NewDirectoryMode()))
+
+	var filter commitlog.FileFilterPredicate
+	src.newIteratorFn = func(
+		_ commitlog.Options,
+		f commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
+		filter = f
+		return newTestCommitLogIterator(commitLogValues, nil), nil
+	}
+
+	targetRanges := result.ShardTimeRanges{0: ranges}
+	res, err := src.Read(testNsMetadata, targetRanges, testDefaultRunOpts)
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	requireShardResults(t, append(snapshotValues, commitLogValues...), res.ShardResults(), opts)
+
+	// Only commit logs written after the snapshot began are replayed
+	require.False(t, filter(commitlog.File{Start: start}))
+	require.True(t, filter(commitlog.File{Start: commitLogStart}))
+}
+
 type testValue struct {
 	s commitlog.Series
 	t time.Time
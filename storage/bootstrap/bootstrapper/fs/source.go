@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/pool"
@@ -37,7 +38,7 @@ import (
 )
 
 type newFileSetReaderFn func(
-	filePathPrefix string,
+	filePathPrefixes []string,
 	readerBufferSize int,
 	bytesPool pool.CheckedBytesPool,
 	decodingOpts msgpack.DecodingOptions,
@@ -46,7 +47,7 @@ type newFileSetReaderFn func(
 type fileSystemSource struct {
 	opts             Options
 	log              xlog.Logger
-	filePathPrefix   string
+	filePathPrefixes []string
 	readerBufferSize int
 	decodingOpts     msgpack.DecodingOptions
 	newReaderFn      newFileSetReaderFn
@@ -61,10 +62,10 @@ func newFileSystemSource(prefix string, opts Options) bootstrap.Source {
 	return &fileSystemSource{
 		opts:             opts,
 		log:              opts.ResultOptions().InstrumentOptions().Logger(),
-		filePathPrefix:   prefix,
+		filePathPrefixes: fs.TierFilePathPrefixes(prefix, fileSystemOpts.TieringPolicy()),
 		readerBufferSize: fileSystemOpts.ReaderBufferSize(),
 		decodingOpts:     fileSystemOpts.DecodingOptions(),
-		newReaderFn:      fs.NewReader,
+		newReaderFn:      fs.NewTieredReader,
 		processors:       processors,
 	}
 }
@@ -78,12 +79,12 @@ func (s *fileSystemSource) Can(strategy bootstrap.Strategy) bool {
 }
 
 func (s *fileSystemSource) Available(
-	namespace ts.ID,
+	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 ) result.ShardTimeRanges {
 	result := make(map[uint32]xtime.Ranges)
 	for shard, ranges := range shardsTimeRanges {
-		result[shard] = s.shardAvailability(namespace, shard, ranges)
+		result[shard] = s.shardAvailability(ns.ID(), shard, ranges)
 	}
 	return result
 }
@@ -97,7 +98,7 @@ func (s *fileSystemSource) shardAvailability(
 		return nil
 	}
 
-	entries := fs.ReadInfoFiles(s.filePathPrefix, namespace, shard, s.readerBufferSize, s.decodingOpts)
+	entries := fs.ReadTierInfoFiles(s.filePathPrefixes, namespace, shard, s.readerBufferSize, s.decodingOpts)
 	if len(entries) == 0 {
 		return nil
 	}
@@ -122,7 +123,7 @@ func (s *fileSystemSource) enqueueReaders(
 	readersCh chan<- shardReaders,
 ) {
 	for shard, tr := range shardsTimeRanges {
-		files := fs.ReadInfoFiles(s.filePathPrefix, namespace, shard, s.readerBufferSize, s.decodingOpts)
+		files := fs.ReadTierInfoFiles(s.filePathPrefixes, namespace, shard, s.readerBufferSize, s.decodingOpts)
 		if len(files) == 0 {
 			if tr == nil {
 				tr = xtime.NewRanges()
@@ -352,7 +353,7 @@ func (s *fileSystemSource) bootstrapFromReaders(
 }
 
 func (s *fileSystemSource) Read(
-	namespace ts.ID,
+	ns namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 	_ bootstrap.RunOptions,
 ) (result.BootstrapResult, error) {
@@ -360,21 +361,23 @@ func (s *fileSystemSource) Read(
 		return nil, nil
 	}
 
+	nsID := ns.ID()
+
 	var blockRetriever block.DatabaseBlockRetriever
 	blockRetrieverMgr := s.opts.DatabaseBlockRetrieverManager()
 	if blockRetrieverMgr != nil {
 		s.log.WithFields(
-			xlog.NewLogField("namespace", namespace.String()),
+			xlog.NewLogField("namespace", nsID.String()),
 		).Infof("filesystem bootstrapper resolving block retriever")
 
 		var err error
-		blockRetriever, err = blockRetrieverMgr.Retriever(namespace)
+		blockRetriever, err = blockRetrieverMgr.Retriever(ns)
 		if err != nil {
 			return nil, err
 		}
 
 		s.log.WithFields(
-			xlog.NewLogField("namespace", namespace.String()),
+			xlog.NewLogField("namespace", nsID.String()),
 			xlog.NewLogField("shards", len(shardsTimeRanges)),
 		).Infof("filesystem bootstrapper caching block retriever shard indices")
 
@@ -396,14 +399,14 @@ func (s *fileSystemSource) Read(
 	).Infof("filesystem bootstrapper bootstrapping shards for ranges")
 	readerPool := newReaderPool(func() fs.FileSetReader {
 		return s.newReaderFn(
-			s.filePathPrefix,
+			s.filePathPrefixes,
 			s.readerBufferSize,
 			s.opts.ResultOptions().DatabaseBlockOptions().BytesPool(),
 			s.decodingOpts,
 		)
 	})
 	readersCh := make(chan shardReaders)
-	go s.enqueueReaders(namespace, shardsTimeRanges, readerPool, readersCh)
+	go s.enqueueReaders(nsID, shardsTimeRanges, readerPool, readersCh)
 	return s.bootstrapFromReaders(readerPool, blockRetriever, readersCh), nil
 }
 
@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3db/persist/fs"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/checked"
 	"github.com/m3db/m3x/pool"
@@ -47,6 +48,7 @@ import (
 var (
 	testShard            = uint32(0)
 	testNamespaceID      = ts.StringID("testNs")
+	testNsMetadata       = namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
 	testStart            = time.Now()
 	testBlockSize        = 2 * time.Hour
 	testFileMode         = os.FileMode(0666)
@@ -114,7 +116,8 @@ func writeGoodFiles(t *testing.T, dir string, namespace ts.ID, shard uint32) {
 }
 
 func writeTSDBFiles(t *testing.T, dir string, namespace ts.ID, shard uint32, start time.Time, id string, data []byte) {
-	w := fs.NewWriter(testBlockSize, dir, testWriterBufferSize, testFileMode, testDirMode)
+	w := fs.NewWriter(testBlockSize, dir, testWriterBufferSize, testFileMode, testDirMode,
+		fs.CompressionNone)
 	require.NoError(t, w.Open(namespace, shard, start))
 
 	bytes := checked.NewBytes(data, nil)
@@ -144,14 +147,14 @@ func validateTimeRanges(t *testing.T, tr xtime.Ranges, expected []xtime.Range) {
 
 func TestAvailableEmptyRangeError(t *testing.T) {
 	src := newFileSystemSource("foo", NewOptions())
-	res := src.Available(testNamespaceID, map[uint32]xtime.Ranges{0: nil})
+	res := src.Available(testNsMetadata, map[uint32]xtime.Ranges{0: nil})
 	require.NotNil(t, res)
 	require.True(t, res.IsEmpty())
 }
 
 func TestAvailablePatternError(t *testing.T) {
 	src := newFileSystemSource("[[", NewOptions())
-	res := src.Available(testNamespaceID, testShardTimeRanges())
+	res := src.Available(testNsMetadata, testShardTimeRanges())
 	require.NotNil(t, res)
 	require.True(t, res.IsEmpty())
 }
@@ -166,7 +169,7 @@ func TestAvailableReadInfoError(t *testing.T) {
 	writeInfoFile(t, dir, testNamespaceID, shard, testStart, []byte{0x1, 0x2})
 
 	src := newFileSystemSource(dir, NewOptions())
-	res := src.Available(testNamespaceID, testShardTimeRanges())
+	res := src.Available(testNsMetadata, testShardTimeRanges())
 	require.NotNil(t, res)
 	require.True(t, res.IsEmpty())
 }
@@ -181,7 +184,7 @@ func TestAvailableDigestOfDigestMismatch(t *testing.T) {
 	writeDigestFile(t, dir, testNamespaceID, shard, testStart, nil)
 
 	src := newFileSystemSource(dir, NewOptions())
-	res := src.Available(testNamespaceID, testShardTimeRanges())
+	res := src.Available(testNsMetadata, testShardTimeRanges())
 	require.NotNil(t, res)
 	require.True(t, res.IsEmpty())
 }
@@ -194,7 +197,7 @@ func TestAvailableTimeRangeFilter(t *testing.T) {
 	writeGoodFiles(t, dir, testNamespaceID, shard)
 
 	src := newFileSystemSource(dir, NewOptions())
-	res := src.Available(testNamespaceID, testShardTimeRanges())
+	res := src.Available(testNsMetadata, testShardTimeRanges())
 	require.NotNil(t, res)
 	require.Equal(t, 1, len(res))
 	require.NotNil(t, res[testShard])
@@ -216,7 +219,7 @@ func TestAvailableTimeRangePartialError(t *testing.T) {
 	writeInfoFile(t, dir, testNamespaceID, shard, testStart.Add(4*time.Hour), []byte{0x1, 0x2})
 
 	src := newFileSystemSource(dir, NewOptions())
-	res := src.Available(testNamespaceID, testShardTimeRanges())
+	res := src.Available(testNsMetadata, testShardTimeRanges())
 	require.NotNil(t, res)
 	require.Equal(t, 1, len(res))
 	require.NotNil(t, res[testShard])
@@ -230,14 +233,14 @@ func TestAvailableTimeRangePartialError(t *testing.T) {
 
 func TestReadEmptyRangeErr(t *testing.T) {
 	src := newFileSystemSource("foo", NewOptions())
-	res, err := src.Read(testNamespaceID, nil, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, nil, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.Nil(t, res)
 }
 
 func TestReadPatternError(t *testing.T) {
 	src := newFileSystemSource("[[", NewOptions())
-	res, err := src.Read(testNamespaceID,
+	res, err := src.Read(testNsMetadata,
 		map[uint32]xtime.Ranges{testShard: xtime.NewRanges()},
 		testDefaultRunOpts)
 	require.NoError(t, err)
@@ -269,7 +272,7 @@ func TestReadOpenFileError(t *testing.T) {
 	writeInfoFile(t, dir, testNamespaceID, shard, testStart, nil)
 
 	src := newFileSystemSource(dir, NewOptions())
-	res, err := src.Read(testNamespaceID, testShardTimeRanges(),
+	res, err := src.Read(testNsMetadata, testShardTimeRanges(),
 		testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
@@ -293,7 +296,7 @@ func TestReadDataCorruptionError(t *testing.T) {
 
 	src := newFileSystemSource(dir, NewOptions())
 	strs := testShardTimeRanges()
-	res, err := src.Read(testNamespaceID, strs, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, strs, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.Equal(t, 0, len(res.ShardResults()))
@@ -314,7 +317,7 @@ func validateReadResults(
 		},
 	}
 
-	res, err := src.Read(testNamespaceID, strs, testDefaultRunOpts)
+	res, err := src.Read(testNsMetadata, strs, testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
 	require.NotNil(t, res.ShardResults())
@@ -359,6 +362,26 @@ func TestReadTimeFilter(t *testing.T) {
 	validateReadResults(t, src, dir, testShardTimeRanges())
 }
 
+func TestReadUnversionedFilesets(t *testing.T) {
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+
+	// Filesets written before versioning have no version in their file names
+	// and are read as version 0
+	writeGoodFiles(t, dir, testNamespaceID, testShard)
+	shardDir := fs.ShardDirPath(dir, testNamespaceID, testShard)
+	for _, start := range []time.Time{testStart, testStart.Add(10 * time.Hour)} {
+		for _, suffix := range []string{"info", "index", "data", "digest", "checkpoint"} {
+			filePath := path.Join(shardDir, fmt.Sprintf("fileset-%d-%s.db", xtime.ToNanoseconds(start), suffix))
+			_, err := os.Stat(filePath)
+			require.NoError(t, err)
+		}
+	}
+
+	src := newFileSystemSource(dir, NewOptions())
+	validateReadResults(t, src, dir, testShardTimeRanges())
+}
+
 func TestReadPartialError(t *testing.T) {
 	dir := createTempDir(t)
 	defer os.RemoveAll(dir)
@@ -381,7 +404,7 @@ func TestReadValidateError(t *testing.T) {
 	reader := fs.NewMockFileSetReader(ctrl)
 	src := newFileSystemSource(dir, NewOptions()).(*fileSystemSource)
 	src.newReaderFn = func(
-		filePathPrefix string,
+		filePathPrefixes []string,
 		readerBufferSize int,
 		b pool.CheckedBytesPool,
 		decodingOpts msgpack.DecodingOptions,
@@ -406,7 +429,7 @@ func TestReadValidateError(t *testing.T) {
 	reader.EXPECT().Validate().Return(errors.New("foo"))
 	reader.EXPECT().Close().Return(nil)
 
-	res, err := src.Read(testNamespaceID, testShardTimeRanges(),
+	res, err := src.Read(testNsMetadata, testShardTimeRanges(),
 		testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
@@ -430,7 +453,7 @@ func TestReadDeleteOnError(t *testing.T) {
 	reader := fs.NewMockFileSetReader(ctrl)
 	src := newFileSystemSource(dir, NewOptions()).(*fileSystemSource)
 	src.newReaderFn = func(
-		filePathPrefix string,
+		filePathPrefixes []string,
 		readerBufferSize int,
 		b pool.CheckedBytesPool,
 		decodingOpts msgpack.DecodingOptions,
@@ -467,7 +490,7 @@ func TestReadDeleteOnError(t *testing.T) {
 		reader.EXPECT().Close().Return(nil),
 	)
 
-	res, err := src.Read(testNamespaceID, testShardTimeRanges(),
+	res, err := src.Read(testNsMetadata, testShardTimeRanges(),
 		testDefaultRunOpts)
 	require.NoError(t, err)
 	require.NotNil(t, res)
@@ -29,6 +29,7 @@ import (
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/sync"
@@ -42,7 +43,7 @@ type peersSource struct {
 }
 
 type incrementalFlush struct {
-	namespace         ts.ID
+	nsMetadata        namespace.Metadata
 	shard             uint32
 	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager
 	shardResult       result.ShardResult
@@ -71,7 +72,7 @@ func (s *peersSource) Can(strategy bootstrap.Strategy) bool {
 }
 
 func (s *peersSource) Available(
-	namespace ts.ID,
+	_ namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 ) result.ShardTimeRanges {
 	// Peers should be able to fulfill all data
@@ -79,7 +80,7 @@ func (s *peersSource) Available(
 }
 
 func (s *peersSource) Read(
-	namespace ts.ID,
+	nsMetadata namespace.Metadata,
 	shardsTimeRanges result.ShardTimeRanges,
 	opts bootstrap.RunOptions,
 ) (result.BootstrapResult, error) {
@@ -87,6 +88,8 @@ func (s *peersSource) Read(
 		return nil, nil
 	}
 
+	nsID := nsMetadata.ID()
+
 	var (
 		blockRetriever    block.DatabaseBlockRetriever
 		shardRetrieverMgr block.DatabaseShardBlockRetrieverManager
@@ -98,10 +101,10 @@ func (s *peersSource) Read(
 		persistManager := s.opts.PersistManager()
 		if retrieverMgr != nil && persistManager != nil {
 			s.log.WithFields(
-				xlog.NewLogField("namespace", namespace.String()),
+				xlog.NewLogField("namespace", nsID.String()),
 			).Infof("peers bootstrapper resolving block retriever")
 
-			r, err := retrieverMgr.Retriever(namespace)
+			r, err := retrieverMgr.Retriever(nsMetadata)
 			if err != nil {
 				return nil, err
 			}
@@ -134,7 +137,7 @@ func (s *peersSource) Read(
 		incrementalWg       sync.WaitGroup
 		incrementalMaxQueue = s.opts.IncrementalPersistMaxQueueSize()
 		incrementalQueue    = make(chan incrementalFlush, incrementalMaxQueue)
-		bopts               = s.opts.ResultOptions()
+		bopts               = s.opts.ResultOptions().SetRetentionOptions(nsMetadata.Options().RetentionOptions())
 		count               = len(shardsTimeRanges)
 		concurrency         = s.opts.DefaultShardConcurrency()
 	)
@@ -155,7 +158,7 @@ func (s *peersSource) Read(
 			defer incrementalWg.Done()
 
 			for flush := range incrementalQueue {
-				err := s.incrementalFlush(persistFlush, flush.namespace,
+				err := s.incrementalFlush(persistFlush, flush.nsMetadata,
 					flush.shard, flush.shardRetrieverMgr, flush.shardResult, flush.timeRange)
 				if err != nil {
 					s.log.WithFields(
@@ -178,12 +181,12 @@ func (s *peersSource) Read(
 			for it.Next() {
 				currRange := it.Value()
 
-				shardResult, err := session.FetchBootstrapBlocksFromPeers(namespace,
+				shardResult, err := session.FetchBootstrapBlocksFromPeers(nsID,
 					shard, currRange.Start, currRange.End, bopts)
 
 				if err == nil && incremental {
 					incrementalQueue <- incrementalFlush{
-						namespace:         namespace,
+						nsMetadata:        nsMetadata,
 						shard:             shard,
 						shardRetrieverMgr: shardRetrieverMgr,
 						shardResult:       shardResult,
@@ -224,20 +227,21 @@ func (s *peersSource) Read(
 
 func (s *peersSource) incrementalFlush(
 	flush persist.Flush,
-	namespace ts.ID,
+	nsMetadata namespace.Metadata,
 	shard uint32,
 	shardRetrieverMgr block.DatabaseShardBlockRetrieverManager,
 	shardResult result.ShardResult,
 	tr xtime.Range,
 ) error {
 	var (
-		ropts          = s.opts.ResultOptions().RetentionOptions()
+		nsID           = nsMetadata.ID()
+		ropts          = nsMetadata.Options().RetentionOptions()
 		blockSize      = ropts.BlockSize()
 		shardRetriever = shardRetrieverMgr.ShardRetriever(shard)
 		tmpCtx         = context.NewContext()
 	)
 	for start := tr.Start; start.Before(tr.End); start = start.Add(blockSize) {
-		prepared, err := flush.Prepare(namespace, shard, start)
+		prepared, err := flush.Prepare(nsID, shard, start, ropts)
 		if err != nil {
 			return err
 		}
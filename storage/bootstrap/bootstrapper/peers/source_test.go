@@ -30,6 +30,7 @@ import (
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/checked"
@@ -42,6 +43,7 @@ import (
 
 var (
 	testNamespace          = ts.StringID("testnamespace")
+	testNsMetadata         = namespace.NewMetadata(testNamespace, namespace.NewOptions())
 	testDefaultRunOpts     = bootstrap.NewRunOptions().SetIncremental(false)
 	testIncrementalRunOpts = bootstrap.NewRunOptions().SetIncremental(true)
 	testBlockOpts          = block.NewOptions()
@@ -58,10 +60,10 @@ func TestPeersSourceEmptyShardTimeRanges(t *testing.T) {
 	src := newPeersSource(NewOptions())
 
 	target := result.ShardTimeRanges{}
-	available := src.Available(testNamespace, target)
+	available := src.Available(testNsMetadata, target)
 	assert.Equal(t, target, available)
 
-	r, err := src.Read(testNamespace, target, testDefaultRunOpts)
+	r, err := src.Read(testNsMetadata, target, testDefaultRunOpts)
 	assert.NoError(t, err)
 	assert.Nil(t, r)
 }
@@ -90,7 +92,7 @@ func TestPeersSourceReturnsErrorForAdminSession(t *testing.T) {
 		1: xtime.NewRanges().AddRange(xtime.Range{Start: start, End: end}),
 	}
 
-	_, err := src.Read(testNamespace, target, testDefaultRunOpts)
+	_, err := src.Read(testNsMetadata, target, testDefaultRunOpts)
 	require.Error(t, err)
 	assert.Equal(t, expectedErr, err)
 }
@@ -132,7 +134,7 @@ func TestPeersSourceReturnsFulfilledAndUnfulfilled(t *testing.T) {
 		1: xtime.NewRanges().AddRange(xtime.Range{Start: start, End: end}),
 	}
 
-	r, err := src.Read(testNamespace, target, testDefaultRunOpts)
+	r, err := src.Read(testNsMetadata, target, testDefaultRunOpts)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(r.ShardResults()))
@@ -201,7 +203,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 
 	mockRetrieverMgr := block.NewMockDatabaseBlockRetrieverManager(ctrl)
 	mockRetrieverMgr.EXPECT().
-		Retriever(ts.NewIDMatcher(testNamespace.String())).
+		Retriever(testNsMetadata).
 		Return(mockRetriever, nil)
 
 	opts = opts.SetDatabaseBlockRetrieverManager(mockRetrieverMgr)
@@ -211,7 +213,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 	persists := make(map[string]int)
 	closes := make(map[string]int)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				persists["foo"]++
@@ -226,7 +228,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start.Add(ropts.BlockSize())).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start.Add(ropts.BlockSize()), testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				persists["bar"]++
@@ -241,7 +243,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				persists["baz"]++
@@ -256,7 +258,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start.Add(ropts.BlockSize())).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start.Add(ropts.BlockSize()), testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				assert.Fail(t, "no expected shard 1 second block")
@@ -280,7 +282,7 @@ func TestPeersSourceIncrementalRun(t *testing.T) {
 		1: xtime.NewRanges().AddRange(xtime.Range{Start: start, End: end}),
 	}
 
-	r, err := src.Read(testNamespace, target, testIncrementalRunOpts)
+	r, err := src.Read(testNsMetadata, target, testIncrementalRunOpts)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 2, len(r.ShardResults()))
@@ -383,7 +385,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 
 	mockRetrieverMgr := block.NewMockDatabaseBlockRetrieverManager(ctrl)
 	mockRetrieverMgr.EXPECT().
-		Retriever(ts.NewIDMatcher(testNamespace.String())).
+		Retriever(testNsMetadata).
 		Return(mockRetriever, nil)
 
 	opts = opts.SetDatabaseBlockRetrieverManager(mockRetrieverMgr)
@@ -393,7 +395,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 	persists := make(map[string]int)
 	closes := make(map[string]int)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(0), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				assert.Fail(t, "not expecting to flush shard 0 at start")
@@ -405,7 +407,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(1), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				assert.Fail(t, "not expecting to flush shard 0 at start + block size")
@@ -417,7 +419,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(2), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(2), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				persists["baz"]++
@@ -429,7 +431,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 			},
 		}, nil)
 	mockFlush.EXPECT().
-		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(3), start).
+		Prepare(ts.NewIDMatcher(testNamespace.String()), uint32(3), start, testNsMetadata.Options().RetentionOptions()).
 		Return(persist.PreparedPersist{
 			Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
 				persists["qux"]++
@@ -455,7 +457,7 @@ func TestPeersSourceContinuesOnIncrementalFlushErrors(t *testing.T) {
 		3: xtime.NewRanges().AddRange(xtime.Range{Start: start, End: end}),
 	}
 
-	r, err := src.Read(testNamespace, target, testIncrementalRunOpts)
+	r, err := src.Read(testNsMetadata, target, testIncrementalRunOpts)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 4, len(r.ShardResults()))
@@ -25,7 +25,7 @@ import (
 	"sync"
 
 	"github.com/m3db/m3db/storage/bootstrap/result"
-	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3x/log"
 	"github.com/m3db/m3x/time"
 )
@@ -67,7 +67,7 @@ func (b *bootstrapProcess) Bootstrapper() Bootstrapper {
 }
 
 func (b *bootstrapProcess) Run(
-	namespace ts.ID,
+	ns namespace.Metadata,
 	shards []uint32,
 	targetRanges []TargetRange,
 ) (result.BootstrapResult, error) {
@@ -88,7 +88,7 @@ func (b *bootstrapProcess) Run(
 
 		logFields := []xlog.LogField{
 			xlog.NewLogField("bootstrapper", b.bootstrapper.String()),
-			xlog.NewLogField("namespace", namespace.String()),
+			xlog.NewLogField("namespace", ns.ID().String()),
 			xlog.NewLogField("numShards", len(shards)),
 			xlog.NewLogField("from", window.Start.String()),
 			xlog.NewLogField("to", window.End.String()),
@@ -104,7 +104,7 @@ func (b *bootstrapProcess) Run(
 			opts = NewRunOptions()
 		}
 
-		res, err := bootstrapper.Bootstrap(namespace, shardsTimeRanges, opts)
+		res, err := bootstrapper.Bootstrap(ns, shardsTimeRanges, opts)
 
 		logFields = append(logFields, xlog.NewLogField("took", nowFn().Sub(begin).String()))
 		if err != nil {
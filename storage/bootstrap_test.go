@@ -45,6 +45,7 @@ func TestDatabaseBootstrapWithBootstrapError(t *testing.T) {
 		}))
 
 	namespace := NewMockdatabaseNamespace(ctrl)
+	namespace.EXPECT().Options().Return(testNamespaceOptions())
 	namespace.EXPECT().Bootstrap(nil, gomock.Any()).Return(fmt.Errorf("an error"))
 	namespace.EXPECT().ID().Return(ts.StringID("test"))
 	namespaces := map[string]databaseNamespace{
@@ -84,7 +85,7 @@ func TestDatabaseBootstrapTargetRanges(t *testing.T) {
 
 	db := &mockDatabase{opts: opts}
 	bsm := newBootstrapManager(db, nil, opts).(*bootstrapManager)
-	ranges := bsm.targetRanges(now)
+	ranges := bsm.targetRanges(ropts, now)
 
 	var all [][]time.Time
 	for _, target := range ranges {
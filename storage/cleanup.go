@@ -27,6 +27,7 @@ import (
 
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3x/errors"
 
 	"github.com/uber-go/tally"
@@ -38,6 +39,12 @@ type commitLogFilesForTimeFn func(commitLogsDir string, t time.Time) ([]string,
 
 type deleteFilesFn func(files []string) error
 
+type latestSnapshotFn func(prefix string) (fs.SnapshotMetadata, bool, error)
+
+type supersededSnapshotsFn func(prefix string) ([]string, error)
+
+type commitLogFilesCoveredByFn func(commitLogsDir string, snapshot fs.SnapshotMetadata) ([]string, error)
+
 type cleanupManager struct {
 	sync.RWMutex
 
@@ -51,6 +58,10 @@ type cleanupManager struct {
 	commitLogFilesBeforeFn  commitLogFilesBeforeFn
 	commitLogFilesForTimeFn commitLogFilesForTimeFn
 	deleteFilesFn           deleteFilesFn
+	latestSnapshotFn        latestSnapshotFn
+	supersededSnapshotsFn   supersededSnapshotsFn
+	coveredCommitLogsFn     commitLogFilesCoveredByFn
+	deletePathsFn           deleteFilesFn
 	cleanupInProgress       bool
 	status                  tally.Gauge
 }
@@ -71,7 +82,11 @@ func newCleanupManager(database database, fm databaseFlushManager, scope tally.S
 		fm:             fm,
 		commitLogFilesBeforeFn:  fs.CommitLogFilesBefore,
 		commitLogFilesForTimeFn: fs.CommitLogFilesForTime,
-		deleteFilesFn:           fs.DeleteFiles,
+		deleteFilesFn:           fs.DeleteCommitLogFiles,
+		latestSnapshotFn:        fs.LatestSnapshotMetadata,
+		supersededSnapshotsFn:   fs.SupersededSnapshots,
+		coveredCommitLogsFn:     fs.CommitLogFilesCoveredBy,
+		deletePathsFn:           fs.DeletePaths,
 		status:                  scope.Gauge("cleanup"),
 	}
 }
@@ -88,9 +103,12 @@ func (m *cleanupManager) Cleanup(t time.Time) error {
 	}()
 
 	multiErr := xerrors.NewMultiError()
-	filesetFilesStart := m.fm.FlushTimeStart(t)
-	if err := m.cleanupFilesetFiles(filesetFilesStart); err != nil {
-		detailedErr := fmt.Errorf("encountered errors when cleaning up fileset files for %v: %v", filesetFilesStart, err)
+	if err := m.cleanupFilesetFiles(t); err != nil {
+		detailedErr := fmt.Errorf("encountered errors when cleaning up fileset files for %v: %v", t, err)
+		multiErr = multiErr.Add(detailedErr)
+	}
+	if err := m.tierFilesetFiles(t); err != nil {
+		detailedErr := fmt.Errorf("encountered errors when tiering fileset files for %v: %v", t, err)
 		multiErr = multiErr.Add(detailedErr)
 	}
 	commitLogStart, commitLogTimes := m.commitLogTimes(t)
@@ -98,6 +116,10 @@ func (m *cleanupManager) Cleanup(t time.Time) error {
 		detailedErr := fmt.Errorf("encountered errors when cleaning up commit logs for commitLogStart %v commitLogTimes %v: %v", commitLogStart, commitLogTimes, err)
 		multiErr = multiErr.Add(detailedErr)
 	}
+	if err := m.cleanupSnapshots(); err != nil {
+		detailedErr := fmt.Errorf("encountered errors when cleaning up snapshots: %v", err)
+		multiErr = multiErr.Add(detailedErr)
+	}
 
 	return multiErr.FinalError()
 }
@@ -114,11 +136,14 @@ func (m *cleanupManager) Report() {
 	}
 }
 
-func (m *cleanupManager) cleanupFilesetFiles(earliestToRetain time.Time) error {
+// cleanupFilesetFiles removes the fileset files of each namespace that fall
+// outside of the retention period of that namespace
+func (m *cleanupManager) cleanupFilesetFiles(t time.Time) error {
 	multiErr := xerrors.NewMultiError()
 
 	namespaces := m.database.getOwnedNamespaces()
 	for _, n := range namespaces {
+		earliestToRetain := retention.FlushTimeStart(n.Options().RetentionOptions(), t)
 		if err := n.CleanupFileset(earliestToRetain); err != nil {
 			multiErr = multiErr.Add(err)
 		}
@@ -127,6 +152,25 @@ func (m *cleanupManager) cleanupFilesetFiles(earliestToRetain time.Time) error {
 	return multiErr.FinalError()
 }
 
+// tierFilesetFiles moves the fileset files of each namespace older than the
+// age of the tiering policy to the secondary tier
+func (m *cleanupManager) tierFilesetFiles(t time.Time) error {
+	if !m.opts.CommitLogOptions().FilesystemOptions().TieringPolicy().Enabled() {
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+
+	namespaces := m.database.getOwnedNamespaces()
+	for _, n := range namespaces {
+		if err := n.TierFilesets(t); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
 // NB(xichen): since each commit log contains data needed for bootstrapping not only
 // its own block size period but also its left and right block neighbors due to past
 // writes and future writes, we need to shift flush time range by block size as the
@@ -141,19 +185,18 @@ func (m *cleanupManager) commitLogTimeRange(t time.Time) (time.Time, time.Time)
 func (m *cleanupManager) commitLogTimes(t time.Time) (time.Time, []time.Time) {
 	earliest, latest := m.commitLogTimeRange(t)
 
+	// Cold writes are only durable in the commit logs until a cold flush has
+	// merged them into the filesets of their blocks
+	if m.coldWritesPending() {
+		return earliest, nil
+	}
+
 	// TODO(xichen): preallocate the slice here
 	var commitLogTimes []time.Time
 	for commitLogTime := latest; !commitLogTime.Before(earliest); commitLogTime = commitLogTime.Add(-m.blockSize) {
-		hasFlushedAll := true
 		leftBlockStart := commitLogTime.Add(-m.blockSize)
-		rightBlockStart := commitLogTime.Add(m.blockSize)
-		for blockStart := leftBlockStart; !blockStart.After(rightBlockStart); blockStart = blockStart.Add(m.blockSize) {
-			if m.fm.NeedsFlush(blockStart) {
-				hasFlushedAll = false
-				break
-			}
-		}
-		if hasFlushedAll {
+		rightBlockEnd := commitLogTime.Add(2 * m.blockSize)
+		if !m.fm.NeedsFlush(leftBlockStart, rightBlockEnd) {
 			commitLogTimes = append(commitLogTimes, commitLogTime)
 		}
 	}
@@ -161,6 +204,17 @@ func (m *cleanupManager) commitLogTimes(t time.Time) (time.Time, []time.Time) {
 	return earliest, commitLogTimes
 }
 
+// coldWritesPending returns whether any namespace has cold writes not yet
+// merged into the filesets of their blocks.
+func (m *cleanupManager) coldWritesPending() bool {
+	for _, n := range m.database.getOwnedNamespaces() {
+		if n.ColdWritesPending() {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *cleanupManager) cleanupCommitLogs(earliestToRetain time.Time, cleanupTimes []time.Time) error {
 	multiErr := xerrors.NewMultiError()
 	toCleanup, err := m.commitLogFilesBeforeFn(m.commitLogsDir, earliestToRetain)
@@ -182,3 +236,35 @@ func (m *cleanupManager) cleanupCommitLogs(earliestToRetain time.Time, cleanupTi
 
 	return multiErr.FinalError()
 }
+
+// cleanupSnapshots removes the snapshots superseded by the latest complete
+// snapshot as well as the commit logs covered by it
+func (m *cleanupManager) cleanupSnapshots() error {
+	multiErr := xerrors.NewMultiError()
+	superseded, err := m.supersededSnapshotsFn(m.filePathPrefix)
+	if err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	if err := m.deletePathsFn(superseded); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+
+	snapshot, ok, err := m.latestSnapshotFn(m.filePathPrefix)
+	if err != nil || !ok {
+		return multiErr.Add(err).FinalError()
+	}
+	// Snapshots do not include cold writes to flushed blocks so the commit
+	// logs they cover are kept until the cold writes have been merged
+	if m.coldWritesPending() {
+		return multiErr.FinalError()
+	}
+	covered, err := m.coveredCommitLogsFn(m.commitLogsDir, snapshot)
+	if err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	if err := m.deleteFilesFn(covered); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+
+	return multiErr.FinalError()
+}
@@ -26,6 +26,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
@@ -34,7 +38,14 @@ import (
 func testCleanupManager(ctrl *gomock.Controller) (*mockDatabase, *MockdatabaseFlushManager, *cleanupManager) {
 	db := newMockDatabase()
 	fm := NewMockdatabaseFlushManager(ctrl)
-	return db, fm, newCleanupManager(db, fm, tally.NoopScope).(*cleanupManager)
+	mgr := newCleanupManager(db, fm, tally.NoopScope).(*cleanupManager)
+	mgr.supersededSnapshotsFn = func(_ string) ([]string, error) {
+		return nil, nil
+	}
+	mgr.latestSnapshotFn = func(_ string) (fs.SnapshotMetadata, bool, error) {
+		return fs.SnapshotMetadata{}, false, nil
+	}
+	return db, fm, mgr
 }
 
 func TestCleanupManagerCleanup(t *testing.T) {
@@ -44,6 +55,8 @@ func TestCleanupManagerCleanup(t *testing.T) {
 	ts := time.Unix(36000, 0)
 	start := time.Unix(14400, 0)
 	end := time.Unix(28800, 0)
+	nsOpts := namespace.NewOptions()
+	filesetStart := retention.FlushTimeStart(nsOpts.RetentionOptions(), ts)
 	db, fm, mgr := testCleanupManager(ctrl)
 
 	inputs := []struct {
@@ -57,7 +70,9 @@ func TestCleanupManagerCleanup(t *testing.T) {
 	namespaces := make(map[string]databaseNamespace)
 	for _, input := range inputs {
 		ns := NewMockdatabaseNamespace(ctrl)
-		ns.EXPECT().CleanupFileset(start).Return(input.err)
+		ns.EXPECT().Options().Return(nsOpts)
+		ns.EXPECT().CleanupFileset(filesetStart).Return(input.err)
+		ns.EXPECT().ColdWritesPending().Return(false).AnyTimes()
 		namespaces[input.name] = ns
 	}
 	db.namespaces = namespaces
@@ -78,16 +93,11 @@ func TestCleanupManagerCleanup(t *testing.T) {
 	}
 
 	gomock.InOrder(
-		fm.EXPECT().FlushTimeStart(ts).Return(start),
 		fm.EXPECT().FlushTimeStart(ts).Return(start),
 		fm.EXPECT().FlushTimeEnd(ts).Return(end),
-		fm.EXPECT().NeedsFlush(time.Unix(14400, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(21600, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(28800, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(7200, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(14400, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(21600, 0)).Return(false),
-		fm.EXPECT().NeedsFlush(time.Unix(0, 0)).Return(true),
+		fm.EXPECT().NeedsFlush(time.Unix(14400, 0), time.Unix(36000, 0)).Return(false),
+		fm.EXPECT().NeedsFlush(time.Unix(7200, 0), time.Unix(28800, 0)).Return(false),
+		fm.EXPECT().NeedsFlush(time.Unix(0, 0), time.Unix(21600, 0)).Return(true),
 	)
 
 	require.Error(t, mgr.Cleanup(ts))
@@ -108,3 +118,62 @@ func TestCleanupManagerCommitLogTimeRange(t *testing.T) {
 	require.Equal(t, time.Unix(0, 0), cs)
 	require.Equal(t, time.Unix(7200, 0), ce)
 }
+
+func TestCleanupManagerCleanupSnapshots(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, _, mgr := testCleanupManager(ctrl)
+	snapshot := fs.SnapshotMetadata{Index: 2, CommitLogStart: time.Unix(7200, 0)}
+	mgr.supersededSnapshotsFn = func(_ string) ([]string, error) {
+		return []string{"snapshot-1"}, nil
+	}
+	mgr.latestSnapshotFn = func(_ string) (fs.SnapshotMetadata, bool, error) {
+		return snapshot, true, nil
+	}
+	mgr.coveredCommitLogsFn = func(_ string, s fs.SnapshotMetadata) ([]string, error) {
+		require.Equal(t, snapshot, s)
+		return []string{"commitlog-0"}, nil
+	}
+	var deletedPaths, deletedFiles []string
+	mgr.deletePathsFn = func(paths []string) error {
+		deletedPaths = append(deletedPaths, paths...)
+		return nil
+	}
+	mgr.deleteFilesFn = func(files []string) error {
+		deletedFiles = append(deletedFiles, files...)
+		return nil
+	}
+
+	require.NoError(t, mgr.cleanupSnapshots())
+	require.Equal(t, []string{"snapshot-1"}, deletedPaths)
+	require.Equal(t, []string{"commitlog-0"}, deletedFiles)
+}
+
+func TestCleanupManagerKeepsCommitLogsWithColdWritesPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, fm, mgr := testCleanupManager(ctrl)
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ColdWritesPending().Return(true).AnyTimes()
+	db.namespaces = map[string]databaseNamespace{
+		"foo": ns,
+	}
+
+	ts := time.Unix(36000, 0)
+	fm.EXPECT().FlushTimeStart(ts).Return(time.Unix(14400, 0))
+	fm.EXPECT().FlushTimeEnd(ts).Return(time.Unix(28800, 0))
+	earliest, times := mgr.commitLogTimes(ts)
+	require.Equal(t, time.Unix(7200, 0), earliest)
+	require.Empty(t, times)
+
+	mgr.latestSnapshotFn = func(_ string) (fs.SnapshotMetadata, bool, error) {
+		return fs.SnapshotMetadata{Index: 2}, true, nil
+	}
+	mgr.coveredCommitLogsFn = func(_ string, _ fs.SnapshotMetadata) ([]string, error) {
+		require.FailNow(t, "commit logs covered by the snapshot should be kept")
+		return nil, nil
+	}
+	require.NoError(t, mgr.cleanupSnapshots())
+}
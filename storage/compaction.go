@@ -0,0 +1,157 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/persist"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/x/io"
+)
+
+// shardCompaction merges the fileset already flushed for a block into the
+// new version of the fileset written when the block is rewritten, so that
+// series with data only on disk are carried over to the new version.
+type shardCompaction struct {
+	opts       Options
+	blockStart time.Time
+	seeker     fs.FileSetSeeker
+	persisted  map[ts.Hash]struct{}
+}
+
+func newShardCompaction(
+	namespace ts.ID,
+	shard uint32,
+	blockStart time.Time,
+	opts Options,
+) (*shardCompaction, error) {
+	c := &shardCompaction{
+		opts:       opts,
+		blockStart: blockStart,
+		persisted:  make(map[ts.Hash]struct{}),
+	}
+
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	prefixes := fs.TierFilePathPrefixes(fsOpts.FilePathPrefix(), fsOpts.TieringPolicy())
+	if !fs.FilesetExistsAtAnyTier(prefixes, namespace, shard, blockStart) {
+		return c, nil
+	}
+
+	seeker := fs.NewTieredSeeker(prefixes, fsOpts.ReaderBufferSize(),
+		opts.BytesPool(), fsOpts.DecodingOptions())
+	if err := seeker.Open(namespace, shard, blockStart); err != nil {
+		return nil, err
+	}
+	c.seeker = seeker
+	return c, nil
+}
+
+// PersistFn returns a persist function that merges the data of each series
+// with its data in the existing fileset before persisting it.
+func (c *shardCompaction) PersistFn(persistFn persist.Fn) persist.Fn {
+	return func(id ts.ID, segment ts.Segment, checksum uint32) error {
+		c.persisted[id.Hash()] = struct{}{}
+		if c.seeker == nil || c.seeker.SeekOffset(id) < 0 {
+			return persistFn(id, segment, checksum)
+		}
+
+		data, err := c.seeker.Seek(id)
+		if err != nil {
+			return err
+		}
+		existing := ts.NewSegment(data, nil, ts.FinalizeHead)
+		defer existing.Finalize()
+
+		// Blocks unchanged since they were flushed are persisted as is
+		if digest.SegmentChecksum(existing) == checksum {
+			return persistFn(id, segment, checksum)
+		}
+
+		merged, err := c.merge(segment, existing)
+		if err != nil {
+			return err
+		}
+		err = persistFn(id, merged, digest.SegmentChecksum(merged))
+		merged.Finalize()
+		return err
+	}
+}
+
+// PersistRemaining persists the series of the existing fileset that were
+// not persisted from memory.
+func (c *shardCompaction) PersistRemaining(persistFn persist.Fn) error {
+	if c.seeker == nil {
+		return nil
+	}
+	for _, id := range c.seeker.IDs() {
+		if _, ok := c.persisted[id.Hash()]; ok {
+			continue
+		}
+		data, err := c.seeker.Seek(id)
+		if err != nil {
+			return err
+		}
+		segment := ts.NewSegment(data, nil, ts.FinalizeHead)
+		err = persistFn(id, segment, digest.SegmentChecksum(segment))
+		segment.Finalize()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge merges the data flushed from memory with the existing data,
+// deduplicating datapoints present in both.
+func (c *shardCompaction) merge(segment, existing ts.Segment) (ts.Segment, error) {
+	iter := c.opts.MultiReaderIteratorPool().Get()
+	iter.Reset([]io.Reader{
+		xio.NewSegmentReader(segment),
+		xio.NewSegmentReader(existing),
+	})
+	defer iter.Close()
+
+	encoder := c.opts.EncoderPool().Get()
+	encoder.Reset(c.blockStart, 0)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return ts.Segment{}, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return ts.Segment{}, err
+	}
+	return encoder.Discard(), nil
+}
+
+func (c *shardCompaction) Close() error {
+	if c.seeker == nil {
+		return nil
+	}
+	return c.seeker.Close()
+}
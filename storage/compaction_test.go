@@ -0,0 +1,147 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/persist"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/x/io"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testCompactionOptions(t *testing.T) (Options, string) {
+	dir, err := ioutil.TempDir("", "compaction")
+	require.NoError(t, err)
+
+	opts := testDatabaseOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	return opts.SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts)), dir
+}
+
+func testCompactionSegment(
+	t *testing.T,
+	opts Options,
+	blockStart time.Time,
+	dps []ts.Datapoint,
+) ts.Segment {
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(blockStart, 0)
+	for _, dp := range dps {
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	return encoder.Discard()
+}
+
+func testCompactionDatapoints(t *testing.T, opts Options, segment ts.Segment) []ts.Datapoint {
+	iter := opts.ReaderIteratorPool().Get()
+	iter.Reset(xio.NewSegmentReader(segment))
+	defer iter.Close()
+
+	var dps []ts.Datapoint
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		dps = append(dps, dp)
+	}
+	require.NoError(t, iter.Err())
+	return dps
+}
+
+func TestShardFlushCompactsRewrittenBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, dir := testCompactionOptions(t)
+	defer os.RemoveAll(dir)
+
+	blockSize := opts.RetentionOptions().BlockSize()
+	blockStart := time.Now().Truncate(blockSize).Add(-2 * blockSize)
+	foo, bar := ts.StringID("foo"), ts.StringID("bar")
+	dp := func(offset time.Duration, value float64) ts.Datapoint {
+		return ts.Datapoint{Timestamp: blockStart.Add(offset), Value: value}
+	}
+
+	// Write the existing fileset for the block
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	writer := fs.NewWriter(blockSize, dir, fsOpts.WriterBufferSize(),
+		fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), fsOpts.Compression())
+	require.NoError(t, writer.Open(testNamespaceID, 0, blockStart))
+	for _, s := range []struct {
+		id  ts.ID
+		dps []ts.Datapoint
+	}{
+		{foo, []ts.Datapoint{dp(time.Minute, 1), dp(2*time.Minute, 2)}},
+		{bar, []ts.Datapoint{dp(time.Minute, 3)}},
+	} {
+		segment := testCompactionSegment(t, opts, blockStart, s.dps)
+		require.NoError(t, writer.WriteAll(s.id, []checked.Bytes{segment.Head, segment.Tail},
+			digest.SegmentChecksum(segment)))
+		segment.Finalize()
+	}
+	require.NoError(t, writer.Close())
+
+	shard := testDatabaseShard(opts)
+	defer shard.Close()
+	shard.bs = bootstrapped
+	shard.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+	shard.markFlushStateNeedsRewrite(blockStart)
+
+	// Only foo is held in memory and it has received a late datapoint
+	series := addMockSeries(ctrl, shard, foo, 0)
+	series.EXPECT().Flush(gomock.Any(), blockStart, gomock.Any()).Do(
+		func(_ context.Context, _ time.Time, persistFn persist.Fn) {
+			segment := testCompactionSegment(t, opts, blockStart,
+				[]ts.Datapoint{dp(2*time.Minute, 2), dp(3*time.Minute, 4)})
+			defer segment.Finalize()
+			require.NoError(t, persistFn(foo, segment, digest.SegmentChecksum(segment)))
+		}).Return(nil)
+
+	persisted := make(map[string][]ts.Datapoint)
+	prepared := persist.PreparedPersist{
+		Persist: func(id ts.ID, segment ts.Segment, checksum uint32) error {
+			require.Equal(t, digest.SegmentChecksum(segment), checksum)
+			persisted[id.String()] = testCompactionDatapoints(t, opts, segment)
+			return nil
+		},
+		Close: func() error { return nil },
+	}
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().PrepareRewrite(testNamespaceID, shard.ID(), blockStart, gomock.Any()).Return(prepared, nil)
+
+	require.NoError(t, shard.Flush(testNamespaceID, blockStart, flush))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, shard.FlushState(blockStart))
+	require.Equal(t, map[string][]ts.Datapoint{
+		"foo": {dp(time.Minute, 1), dp(2*time.Minute, 2), dp(3*time.Minute, 4)},
+		"bar": {dp(time.Minute, 3)},
+	}, persisted)
+}
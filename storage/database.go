@@ -32,6 +32,7 @@ import (
 	"github.com/m3db/m3db/persist/fs/commitlog"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/index"
 	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/counter"
@@ -58,6 +59,12 @@ var (
 
 	// errCommitLogStrategyUnknown raised when trying to use an unknown commit log strategy
 	errCommitLogStrategyUnknown = errors.New("database commit log strategy is unknown")
+
+	// errNamespaceAlreadyExists raised when trying to add a namespace that already exists
+	errNamespaceAlreadyExists = errors.New("database namespace already exists")
+
+	// errDeleteInvalidRange raised when trying to delete a range that does not start before it ends
+	errDeleteInvalidRange = errors.New("delete range start must be before its end")
 )
 
 type databaseState int
@@ -88,9 +95,13 @@ type db struct {
 	nowFn clock.NowFn
 
 	namespaces       map[ts.Hash]databaseNamespace
+	shardSet         sharding.ShardSet
 	commitLog        commitlog.CommitLog
 	writeCommitLogFn writeCommitLogFn
 
+	nsWatch       namespace.Watch
+	nsWatchDoneCh chan struct{}
+
 	state    databaseState
 	mediator databaseMediator
 
@@ -108,17 +119,23 @@ type db struct {
 
 type databaseMetrics struct {
 	write               instrument.MethodMetrics
+	writeTagged         instrument.MethodMetrics
 	read                instrument.MethodMetrics
+	queryIDs            instrument.MethodMetrics
 	fetchBlocks         instrument.MethodMetrics
 	fetchBlocksMetadata instrument.MethodMetrics
+	delete              instrument.MethodMetrics
 }
 
 func newDatabaseMetrics(scope tally.Scope, samplingRate float64) databaseMetrics {
 	return databaseMetrics{
 		write:               instrument.NewMethodMetrics(scope, "write", samplingRate),
+		writeTagged:         instrument.NewMethodMetrics(scope, "writeTagged", samplingRate),
 		read:                instrument.NewMethodMetrics(scope, "read", samplingRate),
+		queryIDs:            instrument.NewMethodMetrics(scope, "queryIDs", samplingRate),
 		fetchBlocks:         instrument.NewMethodMetrics(scope, "fetchBlocks", samplingRate),
 		fetchBlocksMetadata: instrument.NewMethodMetrics(scope, "fetchBlocksMetadata", samplingRate),
+		delete:              instrument.NewMethodMetrics(scope, "delete", samplingRate),
 	}
 }
 
@@ -134,6 +151,7 @@ func NewDatabase(
 	d := &db{
 		opts:         opts,
 		nowFn:        opts.ClockOptions().NowFn(),
+		shardSet:     shardSet,
 		tickDeadline: opts.RetentionOptions().BufferDrain(),
 		scope:        scope,
 		metrics:      newDatabaseMetrics(scope, iopts.MetricsSamplingRate()),
@@ -154,27 +172,22 @@ func NewDatabase(
 		d.writeCommitLogFn = d.commitLog.Write
 	case commitlog.StrategyWriteBehind:
 		d.writeCommitLogFn = d.commitLog.WriteBehind
+	case commitlog.StrategyWriteSync:
+		d.writeCommitLogFn = d.commitLog.WriteSync
 	default:
 		return nil, errCommitLogStrategyUnknown
 	}
 
 	ns := make(map[ts.Hash]databaseNamespace, len(namespaces))
-	blockRetrieverMgr := opts.DatabaseBlockRetrieverManager()
 	for _, n := range namespaces {
 		if _, exists := ns[n.ID().Hash()]; exists {
 			return nil, errDuplicateNamespaces
 		}
-		var blockRetriever block.DatabaseBlockRetriever
-		if blockRetrieverMgr != nil {
-			var newRetrieverErr error
-			blockRetriever, newRetrieverErr =
-				blockRetrieverMgr.Retriever(n.ID())
-			if newRetrieverErr != nil {
-				return nil, newRetrieverErr
-			}
+		newNs, err := d.newDatabaseNamespace(n, shardSet)
+		if err != nil {
+			return nil, err
 		}
-		ns[n.ID().Hash()] = newDatabaseNamespace(n, shardSet, blockRetriever,
-			d, d.writeCommitLogFn, d.opts)
+		ns[n.ID().Hash()] = newNs
 	}
 	d.namespaces = ns
 
@@ -188,14 +201,40 @@ func NewDatabase(
 	return d, nil
 }
 
+func (d *db) newDatabaseNamespace(
+	md namespace.Metadata,
+	shardSet sharding.ShardSet,
+) (databaseNamespace, error) {
+	if nopts := md.Options(); nopts.RetentionOptionsInherited() {
+		// Namespaces that do not set retention options use those of the database
+		md = namespace.NewMetadata(md.ID(), nopts.SetRetentionOptions(d.opts.RetentionOptions()))
+	}
+	var blockRetriever block.DatabaseBlockRetriever
+	if blockRetrieverMgr := d.opts.DatabaseBlockRetrieverManager(); blockRetrieverMgr != nil {
+		var err error
+		blockRetriever, err = blockRetrieverMgr.Retriever(md)
+		if err != nil {
+			return nil, err
+		}
+	}
+	writeCommitLogFn := d.writeCommitLogFn
+	if md.Options().SyncCommitLogWrites() {
+		// Writes to this namespace are only acknowledged once fsynced
+		writeCommitLogFn = d.commitLog.WriteSync
+	}
+	return newDatabaseNamespace(md, shardSet, blockRetriever,
+		d, writeCommitLogFn, d.opts), nil
+}
+
 func (d *db) Options() Options {
 	// Options are immutable safe to pass the current reference
 	return d.opts
 }
 
 func (d *db) AssignShardSet(shardSet sharding.ShardSet) {
-	d.RLock()
-	defer d.RUnlock()
+	d.Lock()
+	defer d.Unlock()
+	d.shardSet = shardSet
 	for _, ns := range d.namespaces {
 		ns.AssignShardSet(shardSet)
 	}
@@ -225,7 +264,10 @@ func (d *db) Open() error {
 	}
 	d.state = databaseOpen
 
-	return d.mediator.Open()
+	if err := d.mediator.Open(); err != nil {
+		return err
+	}
+	return d.startNamespaceWatchWithLock()
 }
 
 func (d *db) Close() error {
@@ -239,6 +281,8 @@ func (d *db) Close() error {
 	}
 	d.state = databaseClosed
 
+	d.stopNamespaceWatchWithLock()
+
 	// For now just remove all namespaces, in future this could be made more explicit.  However
 	// this is nice as we do not need to do any other branching now in write/read methods.
 	d.namespaces = nil
@@ -278,6 +322,49 @@ func (d *db) Write(
 	return err
 }
 
+func (d *db) WriteTagged(
+	ctx context.Context,
+	namespace ts.ID,
+	id ts.ID,
+	tags ts.Tags,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	callStart := d.nowFn()
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.writeTagged.ReportError(d.nowFn().Sub(callStart))
+		return err
+	}
+
+	err = n.WriteTagged(ctx, id, tags, timestamp, value, unit, annotation)
+	if err == commitlog.ErrCommitLogQueueFull {
+		d.errors.Record(1)
+	}
+	d.metrics.writeTagged.ReportSuccessOrError(err, d.nowFn().Sub(callStart))
+	return err
+}
+
+func (d *db) QueryIDs(
+	ctx context.Context,
+	namespace ts.ID,
+	query index.Query,
+	opts index.QueryOptions,
+) (index.QueryResults, error) {
+	callStart := d.nowFn()
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.queryIDs.ReportError(d.nowFn().Sub(callStart))
+		return index.QueryResults{}, err
+	}
+
+	res, err := n.QueryIDs(ctx, query, opts)
+	d.metrics.queryIDs.ReportSuccessOrError(err, d.nowFn().Sub(callStart))
+	return res, err
+}
+
 func (d *db) ReadEncoded(
 	ctx context.Context,
 	namespace ts.ID,
@@ -365,6 +452,77 @@ func (d *db) Truncate(namespace ts.ID) (int64, error) {
 	return n.Truncate()
 }
 
+func (d *db) Delete(namespace ts.ID, id ts.ID, start, end time.Time) error {
+	callStart := d.nowFn()
+	if !start.Before(end) {
+		d.metrics.delete.ReportError(d.nowFn().Sub(callStart))
+		return xerrors.NewInvalidParamsError(errDeleteInvalidRange)
+	}
+
+	n, err := d.namespaceFor(namespace)
+	if err != nil {
+		d.metrics.delete.ReportError(d.nowFn().Sub(callStart))
+		return xerrors.NewInvalidParamsError(err)
+	}
+
+	err = n.Delete(id, start, end)
+	d.metrics.delete.ReportSuccessOrError(err, d.nowFn().Sub(callStart))
+	return err
+}
+
+func (d *db) AddNamespace(md namespace.Metadata) error {
+	d.Lock()
+	if d.state == databaseClosed {
+		d.Unlock()
+		return errDatabaseAlreadyClosed
+	}
+	if _, exists := d.namespaces[md.ID().Hash()]; exists {
+		d.Unlock()
+		return errNamespaceAlreadyExists
+	}
+	n, err := d.newDatabaseNamespace(md, d.shardSet)
+	if err != nil {
+		d.Unlock()
+		return err
+	}
+	d.namespaces[md.ID().Hash()] = n
+	bootstrapped := d.bootstraps > 0
+	d.Unlock()
+
+	// NB(r): Trigger another bootstrap to bootstrap the new namespace, the
+	// shards of the namespaces that are already bootstrapped are skipped
+	if bootstrapped {
+		go d.mediator.Bootstrap()
+	}
+	return nil
+}
+
+func (d *db) RemoveNamespace(id ts.ID) error {
+	// Wait for any flush or cleanup in progress to complete so the namespace
+	// is not closed while its files are being written
+	d.mediator.DisableFileOps()
+	defer d.mediator.EnableFileOps()
+
+	d.Lock()
+	n, exists := d.namespaces[id.Hash()]
+	if !exists {
+		d.Unlock()
+		return fmt.Errorf("no such namespace %s", id)
+	}
+	delete(d.namespaces, id.Hash())
+	d.Unlock()
+
+	// NB(r): The fileset files of the namespace are left on disk as the
+	// cleanup manager only considers namespaces owned by the database
+	if err := n.Close(); err != nil {
+		return err
+	}
+	if blockRetrieverMgr := d.opts.DatabaseBlockRetrieverManager(); blockRetrieverMgr != nil {
+		return blockRetrieverMgr.CloseRetriever(id)
+	}
+	return nil
+}
+
 func (d *db) IsOverloaded() bool {
 	return d.errors.Count(d.errWindow) > d.errThreshold
 }
@@ -390,6 +548,95 @@ func (d *db) getOwnedNamespaces() []databaseNamespace {
 	return namespaces
 }
 
+func (d *db) rotateCommitLog() (commitlog.File, error) {
+	return d.commitLog.RotateLogs()
+}
+
+func (d *db) startNamespaceWatchWithLock() error {
+	registry := d.opts.NamespaceRegistry()
+	if registry == nil {
+		return nil
+	}
+
+	watch, err := registry.Watch()
+	if err != nil {
+		return err
+	}
+
+	d.nsWatch = watch
+	d.nsWatchDoneCh = make(chan struct{})
+
+	go d.activeNamespaceWatch(watch, d.nsWatchDoneCh)
+
+	return nil
+}
+
+func (d *db) stopNamespaceWatchWithLock() {
+	if d.nsWatch == nil {
+		return
+	}
+
+	close(d.nsWatchDoneCh)
+	d.nsWatch.Close()
+	d.nsWatch = nil
+}
+
+func (d *db) activeNamespaceWatch(
+	watch namespace.Watch,
+	doneCh chan struct{},
+) {
+	for {
+		select {
+		case <-doneCh:
+			return
+		case _, ok := <-watch.C():
+			if !ok {
+				return
+			}
+			if m := watch.Get(); m != nil {
+				d.updateNamespaces(m)
+			}
+		}
+	}
+}
+
+// updateNamespaces adds and removes namespaces to match the namespace map,
+// namespaces that exist in both are left unchanged
+func (d *db) updateNamespaces(m namespace.Map) {
+	var (
+		log     = d.opts.InstrumentOptions().Logger()
+		adding  []namespace.Metadata
+		removed []ts.ID
+	)
+	d.RLock()
+	for _, md := range m.Metadatas() {
+		if _, exists := d.namespaces[md.ID().Hash()]; !exists {
+			adding = append(adding, md)
+		}
+	}
+	for _, n := range d.namespaces {
+		if _, exists := m.Get(n.ID()); !exists {
+			removed = append(removed, n.ID())
+		}
+	}
+	d.RUnlock()
+
+	for _, md := range adding {
+		if err := d.AddNamespace(md); err != nil {
+			log.Errorf("could not add namespace %s: %v", md.ID().String(), err)
+			continue
+		}
+		log.Infof("added namespace %s", md.ID().String())
+	}
+	for _, id := range removed {
+		if err := d.RemoveNamespace(id); err != nil {
+			log.Errorf("could not remove namespace %s: %v", id.String(), err)
+			continue
+		}
+		log.Infof("removed namespace %s", id.String())
+	}
+}
+
 func (d *db) nextIndex() uint64 {
 	created := atomic.AddUint64(&d.created, 1)
 	return created - 1
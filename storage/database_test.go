@@ -29,9 +29,12 @@ import (
 	"github.com/m3db/m3cluster/shard"
 	"github.com/m3db/m3db/client"
 	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/persist/fs/commitlog"
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
@@ -61,6 +64,8 @@ func (d *mockDatabase) IsBootstrapped() bool                      { return d.bs
 func (d *mockDatabase) IsOverloaded() bool                        { return false }
 func (d *mockDatabase) Repair() error                             { return nil }
 func (d *mockDatabase) Truncate(namespace ts.ID) (int64, error)   { return 0, nil }
+func (d *mockDatabase) AddNamespace(md namespace.Metadata) error  { return nil }
+func (d *mockDatabase) RemoveNamespace(id ts.ID) error            { return nil }
 func (d *mockDatabase) flush(t time.Time, async bool)             {}
 
 func (d *mockDatabase) getOwnedNamespaces() []databaseNamespace {
@@ -71,6 +76,10 @@ func (d *mockDatabase) getOwnedNamespaces() []databaseNamespace {
 	return namespaces
 }
 
+func (d *mockDatabase) rotateCommitLog() (commitlog.File, error) {
+	return commitlog.File{}, nil
+}
+
 func (d *mockDatabase) Write(
 	context.Context, ts.ID, ts.ID,
 	time.Time, float64, xtime.Unit, []byte,
@@ -78,6 +87,19 @@ func (d *mockDatabase) Write(
 	return nil
 }
 
+func (d *mockDatabase) WriteTagged(
+	context.Context, ts.ID, ts.ID, ts.Tags,
+	time.Time, float64, xtime.Unit, []byte,
+) error {
+	return nil
+}
+
+func (d *mockDatabase) QueryIDs(
+	context.Context, ts.ID, index.Query, index.QueryOptions,
+) (index.QueryResults, error) {
+	return index.QueryResults{}, nil
+}
+
 func (d *mockDatabase) ReadEncoded(
 	context.Context, ts.ID, ts.ID,
 	time.Time, time.Time,
@@ -85,6 +107,10 @@ func (d *mockDatabase) ReadEncoded(
 	return nil, nil
 }
 
+func (d *mockDatabase) Delete(ts.ID, ts.ID, time.Time, time.Time) error {
+	return nil
+}
+
 func (d *mockDatabase) FetchBlocks(
 	context.Context, ts.ID,
 	uint32, ts.ID, []time.Time,
@@ -117,6 +143,11 @@ func testDatabaseOptions() Options {
 	return defaultTestDatabaseOptions
 }
 
+func testNamespaceOptions() namespace.Options {
+	return namespace.NewOptions().
+		SetRetentionOptions(defaultTestDatabaseOptions.RetentionOptions())
+}
+
 func newTestDatabase(t *testing.T, bs bootstrapState) *db {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -372,3 +403,116 @@ func TestDatabaseBootstrappedAssignShardSet(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestDatabaseAddNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := newTestDatabase(t, bootstrapped)
+	shardSet, err := sharding.NewShardSet(
+		sharding.NewShards([]uint32{0, 1}, shard.Available), nil)
+	require.NoError(t, err)
+	d.shardSet = shardSet
+
+	mediator := NewMockdatabaseMediator(ctrl)
+	mediator.EXPECT().Bootstrap().Return(nil)
+	d.mediator = mediator
+
+	assert.NoError(t, d.Bootstrap())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	mediator.EXPECT().Bootstrap().Return(nil).Do(func() {
+		wg.Done()
+	})
+
+	md := namespace.NewMetadata(ts.StringID("testns"), testNamespaceOptions())
+	require.NoError(t, d.AddNamespace(md))
+	require.Equal(t, errNamespaceAlreadyExists, d.AddNamespace(md))
+
+	wg.Wait()
+
+	namespaces := d.Namespaces()
+	require.Equal(t, 1, len(namespaces))
+	require.Equal(t, "testns", namespaces[0].ID().String())
+	require.Equal(t, 2, len(namespaces[0].Shards()))
+}
+
+func TestDatabaseAddNamespaceInheritsRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := newTestDatabase(t, bootstrapNotStarted)
+	shardSet, err := sharding.NewShardSet(
+		sharding.NewShards([]uint32{0, 1}, shard.Available), nil)
+	require.NoError(t, err)
+	d.shardSet = shardSet
+
+	ropts := retention.NewOptions().
+		SetBlockSize(4 * time.Hour).
+		SetRetentionPeriod(4 * 24 * time.Hour)
+	inherited := namespace.NewMetadata(ts.StringID("inherited"), namespace.NewOptions())
+	explicit := namespace.NewMetadata(ts.StringID("explicit"),
+		namespace.NewOptions().SetRetentionOptions(ropts))
+	require.True(t, inherited.Options().RetentionOptionsInherited())
+	require.False(t, explicit.Options().RetentionOptionsInherited())
+	require.NoError(t, d.AddNamespace(inherited))
+	require.NoError(t, d.AddNamespace(explicit))
+
+	// Only namespaces without retention options use those of the database
+	namespaces := d.Namespaces()
+	sort.Sort(NamespacesByID(namespaces))
+	require.Equal(t, 2, len(namespaces))
+	require.Equal(t, "explicit", namespaces[0].ID().String())
+	require.Equal(t, ropts, namespaces[0].Options().RetentionOptions())
+	require.Equal(t, "inherited", namespaces[1].ID().String())
+	require.Equal(t, d.opts.RetentionOptions(), namespaces[1].Options().RetentionOptions())
+	require.False(t, namespaces[1].Options().RetentionOptionsInherited())
+}
+
+func TestDatabaseRemoveNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := newTestDatabase(t, bootstrapped)
+	ns := dbAddNewMockNamespace(ctrl, d, "testns")
+	ns.EXPECT().Close().Return(nil)
+
+	// The retriever of the namespace is closed to release its seekers
+	blockRetrieverMgr := block.NewMockDatabaseBlockRetrieverManager(ctrl)
+	blockRetrieverMgr.EXPECT().CloseRetriever(ts.NewIDMatcher("testns")).Return(nil)
+	d.opts = d.opts.SetDatabaseBlockRetrieverManager(blockRetrieverMgr)
+
+	require.NoError(t, d.RemoveNamespace(ts.StringID("testns")))
+	require.Error(t, d.RemoveNamespace(ts.StringID("testns")))
+	require.Equal(t, 0, len(d.Namespaces()))
+}
+
+func TestDatabaseUpdateNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	d := newTestDatabase(t, bootstrapNotStarted)
+	shardSet, err := sharding.NewShardSet(
+		sharding.NewShards([]uint32{0, 1}, shard.Available), nil)
+	require.NoError(t, err)
+	d.shardSet = shardSet
+
+	removed := dbAddNewMockNamespace(ctrl, d, "testns1")
+	removed.EXPECT().Close().Return(nil)
+	dbAddNewMockNamespace(ctrl, d, "testns2")
+
+	m, err := namespace.NewMap([]namespace.Metadata{
+		namespace.NewMetadata(ts.StringID("testns2"), testNamespaceOptions()),
+		namespace.NewMetadata(ts.StringID("testns3"), testNamespaceOptions()),
+	})
+	require.NoError(t, err)
+
+	d.updateNamespaces(m)
+
+	result := d.Namespaces()
+	require.Equal(t, 2, len(result))
+	sort.Sort(NamespacesByID(result))
+	assert.Equal(t, "testns2", result[0].ID().String())
+	assert.Equal(t, "testns3", result[1].ID().String())
+}
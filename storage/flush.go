@@ -44,7 +44,6 @@ type flushManager struct {
 	database        database
 	opts            Options
 	nowFn           clock.NowFn
-	blockSize       time.Duration
 	pm              persist.Manager
 	flushInProgress bool
 	status          tally.Gauge
@@ -53,20 +52,22 @@ type flushManager struct {
 func newFlushManager(database database, scope tally.Scope) databaseFlushManager {
 	opts := database.Options()
 	return &flushManager{
-		database:  database,
-		opts:      opts,
-		nowFn:     opts.ClockOptions().NowFn(),
-		blockSize: opts.RetentionOptions().BlockSize(),
-		pm:        opts.PersistManager(),
-		status:    scope.Gauge("flush"),
+		database: database,
+		opts:     opts,
+		nowFn:    opts.ClockOptions().NowFn(),
+		pm:       opts.PersistManager(),
+		status:   scope.Gauge("flush"),
 	}
 }
 
-func (m *flushManager) NeedsFlush(t time.Time) bool {
+func (m *flushManager) NeedsFlush(start, end time.Time) bool {
 	namespaces := m.database.getOwnedNamespaces()
 	for _, n := range namespaces {
-		if n.NeedsFlush(t) {
-			return true
+		blockSize := n.Options().RetentionOptions().BlockSize()
+		for t := start.Truncate(blockSize); t.Before(end); t = t.Add(blockSize) {
+			if n.NeedsFlush(t) {
+				return true
+			}
 		}
 	}
 	return false
@@ -81,8 +82,16 @@ func (m *flushManager) FlushTimeEnd(t time.Time) time.Time {
 }
 
 func (m *flushManager) Flush(curr time.Time) error {
-	timesToFlush := m.flushTimes(curr)
-	if len(timesToFlush) == 0 {
+	var (
+		namespaces   = m.database.getOwnedNamespaces()
+		timesToFlush = make([][]time.Time, len(namespaces))
+		numToFlush   int
+	)
+	for i, n := range namespaces {
+		timesToFlush[i] = m.namespaceFlushTimes(n, curr)
+		numToFlush += len(timesToFlush[i])
+	}
+	if numToFlush == 0 {
 		return nil
 	}
 
@@ -108,9 +117,59 @@ func (m *flushManager) Flush(curr time.Time) error {
 	}()
 
 	multiErr := xerrors.NewMultiError()
-	for _, flushTime := range timesToFlush {
-		if err := m.flushWithTime(flushTime, flush); err != nil {
-			multiErr = multiErr.Add(err)
+	for i, n := range namespaces {
+		for _, flushTime := range timesToFlush[i] {
+			// NB(xichen): we still want to proceed if a namespace fails to flush its data.
+			// Probably want to emit a counter here, but for now just log it.
+			if err := n.Flush(flushTime, flush); err != nil {
+				detailedErr := fmt.Errorf("namespace %s failed to flush data: %v",
+					n.ID().String(), err)
+				multiErr = multiErr.Add(detailedErr)
+			}
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+func (m *flushManager) ColdFlush(curr time.Time) error {
+	var namespaces []databaseNamespace
+	for _, n := range m.database.getOwnedNamespaces() {
+		if n.Options().ColdWritesEnabled() {
+			namespaces = append(namespaces, n)
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	m.Lock()
+	if m.flushInProgress {
+		m.Unlock()
+		return errFlushAlreadyInProgress
+	}
+	m.flushInProgress = true
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.flushInProgress = false
+		m.Unlock()
+	}()
+
+	flush, err := m.pm.StartFlush()
+	if err != nil {
+		return err
+	}
+
+	defer flush.Done()
+
+	multiErr := xerrors.NewMultiError()
+	for _, n := range namespaces {
+		if err := n.ColdFlush(flush); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to cold flush data: %v",
+				n.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
 		}
 	}
 
@@ -129,33 +188,23 @@ func (m *flushManager) Report() {
 	}
 }
 
-func (m *flushManager) flushTimes(curr time.Time) []time.Time {
-	earliest, latest := m.FlushTimeStart(curr), m.FlushTimeEnd(curr)
+// namespaceFlushTimes returns the block starts that need to be flushed for a
+// namespace, using the retention options of the namespace
+func (m *flushManager) namespaceFlushTimes(n databaseNamespace, curr time.Time) []time.Time {
+	var (
+		ropts     = n.Options().RetentionOptions()
+		blockSize = ropts.BlockSize()
+		earliest  = retention.FlushTimeStart(ropts, curr)
+		latest    = retention.FlushTimeEnd(ropts, curr)
+	)
 
 	// NB(xichen): could preallocate slice here.
 	var flushTimes []time.Time
-	for t := latest; !t.Before(earliest); t = t.Add(-m.blockSize) {
-		if m.NeedsFlush(t) {
+	for t := latest; !t.Before(earliest); t = t.Add(-blockSize) {
+		if n.NeedsFlush(t) {
 			flushTimes = append(flushTimes, t)
 		}
 	}
 
 	return flushTimes
 }
-
-// flushWithTime flushes in-memory data across all namespaces for a given
-// time, returning any error encountered during flushing
-func (m *flushManager) flushWithTime(t time.Time, flush persist.Flush) error {
-	multiErr := xerrors.NewMultiError()
-	namespaces := m.database.getOwnedNamespaces()
-	for _, n := range namespaces {
-		// NB(xichen): we still want to proceed if a namespace fails to flush its data.
-		// Probably want to emit a counter here, but for now just log it.
-		if err := n.Flush(t, flush); err != nil {
-			detailedErr := fmt.Errorf("namespace %s failed to flush data: %v",
-				n.ID().String(), err)
-			multiErr = multiErr.Add(detailedErr)
-		}
-	}
-	return multiErr.FinalError()
-}
@@ -24,6 +24,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/m3db/m3db/persist"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"github.com/uber-go/tally"
@@ -33,26 +37,100 @@ func TestFlushManagerNeedsFlush(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	namespace := NewMockdatabaseNamespace(ctrl)
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Options().Return(namespace.NewOptions()).AnyTimes()
 
 	db := newMockDatabase()
 	db.namespaces = map[string]databaseNamespace{
-		"testns": namespace,
+		"testns": ns,
 	}
 
 	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
 
-	now := time.Now()
+	start := time.Unix(7200, 0)
+	end := start.Add(4 * time.Hour)
 
-	namespace.EXPECT().
-		NeedsFlush(now).
-		Return(true)
-	require.True(t, fm.NeedsFlush(now))
+	gomock.InOrder(
+		ns.EXPECT().NeedsFlush(start).Return(false),
+		ns.EXPECT().NeedsFlush(start.Add(2*time.Hour)).Return(true),
+	)
+	require.True(t, fm.NeedsFlush(start, end))
 
-	namespace.EXPECT().
-		NeedsFlush(now).
-		Return(false)
-	require.False(t, fm.NeedsFlush(now))
+	gomock.InOrder(
+		ns.EXPECT().NeedsFlush(start).Return(false),
+		ns.EXPECT().NeedsFlush(start.Add(2*time.Hour)).Return(false),
+	)
+	require.False(t, fm.NeedsFlush(start, end))
+}
+
+func TestFlushManagerFlushUsesNamespaceRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(86400*2+4*3600+1800, 0)
+	ropts := retention.NewOptions().
+		SetBlockSize(4 * time.Hour).
+		SetRetentionPeriod(8 * time.Hour)
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Options().Return(namespace.NewOptions().SetRetentionOptions(ropts)).AnyTimes()
+
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().Done().Return(nil)
+	pm := persist.NewMockManager(ctrl)
+	pm.EXPECT().StartFlush().Return(flush, nil)
+
+	db := newMockDatabase()
+	db.opts = db.opts.SetPersistManager(pm)
+	db.namespaces = map[string]databaseNamespace{
+		"testns": ns,
+	}
+
+	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+
+	// Only the block starts within the namespace retention aligned to the
+	// namespace block size are considered for flushing
+	earliest := retention.FlushTimeStart(ropts, now)
+	latest := retention.FlushTimeEnd(ropts, now)
+	for t := latest; !t.Before(earliest); t = t.Add(-ropts.BlockSize()) {
+		ns.EXPECT().NeedsFlush(t).Return(true)
+		ns.EXPECT().Flush(t, flush).Return(nil)
+	}
+
+	require.NoError(t, fm.Flush(now))
+}
+
+func TestFlushManagerColdFlushOnlyColdWritesNamespaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cold := NewMockdatabaseNamespace(ctrl)
+	cold.EXPECT().Options().Return(namespace.NewOptions().SetColdWritesEnabled(true)).AnyTimes()
+	hot := NewMockdatabaseNamespace(ctrl)
+	hot.EXPECT().Options().Return(namespace.NewOptions()).AnyTimes()
+
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().Done().Return(nil)
+	pm := persist.NewMockManager(ctrl)
+	pm.EXPECT().StartFlush().Return(flush, nil)
+
+	db := newMockDatabase()
+	db.opts = db.opts.SetPersistManager(pm)
+	db.namespaces = map[string]databaseNamespace{
+		"cold": cold,
+		"hot":  hot,
+	}
+
+	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+
+	cold.EXPECT().ColdFlush(flush).Return(nil)
+	require.NoError(t, fm.ColdFlush(time.Now()))
+
+	// No flush is started when no namespace accepts cold writes
+	db.namespaces = map[string]databaseNamespace{
+		"hot": hot,
+	}
+	require.NoError(t, fm.ColdFlush(time.Now()))
 }
 
 func TestFlushManagerFlushTimeStart(t *testing.T) {
@@ -86,3 +164,24 @@ func TestFlushManagerFlushTimeEnd(t *testing.T) {
 		require.Equal(t, input.expected, fm.FlushTimeEnd(input.ts))
 	}
 }
+
+func TestFlushManagerColdFlushInProgressDoesNotStartFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cold := NewMockdatabaseNamespace(ctrl)
+	cold.EXPECT().Options().Return(namespace.NewOptions().SetColdWritesEnabled(true)).AnyTimes()
+
+	// The persist manager is not asked to start a flush
+	pm := persist.NewMockManager(ctrl)
+
+	db := newMockDatabase()
+	db.opts = db.opts.SetPersistManager(pm)
+	db.namespaces = map[string]databaseNamespace{
+		"cold": cold,
+	}
+
+	fm := newFlushManager(db, tally.NoopScope).(*flushManager)
+	fm.flushInProgress = true
+	require.Equal(t, errFlushAlreadyInProgress, fm.ColdFlush(time.Now()))
+}
@@ -60,6 +60,8 @@ const (
 type fileSystemManager struct {
 	databaseFlushManager
 	databaseCleanupManager
+	databaseRollupManager
+	databaseSnapshotManager
 	sync.RWMutex
 
 	log      xlog.Logger
@@ -82,6 +84,8 @@ func newFileSystemManager(
 	scope := instrumentOpts.MetricsScope().SubScope("fs")
 	fm := newFlushManager(database, scope)
 	cm := newCleanupManager(database, fm, scope)
+	rm := newRollupManager(database, scope)
+	sm := newSnapshotManager(database, scope)
 
 	var jitter time.Duration
 	if maxJitter := fileOpts.Jitter(); maxJitter > 0 {
@@ -92,8 +96,10 @@ func newFileSystemManager(
 	}
 
 	return &fileSystemManager{
-		databaseFlushManager:   fm,
-		databaseCleanupManager: cm,
+		databaseFlushManager:    fm,
+		databaseCleanupManager:  cm,
+		databaseRollupManager:   rm,
+		databaseSnapshotManager: sm,
 		log:      instrumentOpts.Logger(),
 		database: database,
 		opts:     opts,
@@ -143,6 +149,18 @@ func (m *fileSystemManager) Run(t time.Time, runType runType, forceType forceTyp
 		if err := m.Flush(t); err != nil {
 			m.log.Errorf("error when flushing data for time %v: %v", t, err)
 		}
+		// Merge cold writes into the filesets of blocks already flushed
+		if err := m.ColdFlush(t); err != nil {
+			m.log.Errorf("error when cold flushing data for time %v: %v", t, err)
+		}
+		// Roll up blocks of source namespaces once they have been flushed
+		if err := m.Rollup(t); err != nil {
+			m.log.Errorf("error when rolling up data for time %v: %v", t, err)
+		}
+		// Snapshot data that remains unflushed so less of the commit log is replayed
+		if err := m.Snapshot(t); err != nil {
+			m.log.Errorf("error when snapshotting data for time %v: %v", t, err)
+		}
 		m.Lock()
 		m.status = fileOpNotStarted
 		m.Unlock()
@@ -159,6 +177,8 @@ func (m *fileSystemManager) Run(t time.Time, runType runType, forceType forceTyp
 func (m *fileSystemManager) Report() {
 	m.databaseCleanupManager.Report()
 	m.databaseFlushManager.Report()
+	m.databaseRollupManager.Report()
+	m.databaseSnapshotManager.Report()
 }
 
 func (m *fileSystemManager) shouldRunWithLock() bool {
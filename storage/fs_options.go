@@ -24,19 +24,27 @@ import (
 	"errors"
 	"time"
 
+	"github.com/m3db/m3db/ratelimit"
 	"github.com/m3db/m3db/retention"
 )
 
 var (
 	errNoRetentionOptions = errors.New("no retention options in file op options")
 	errJitterTooBig       = errors.New("file op jitter is not smaller than block size")
+	errSnapshotInterval   = errors.New("file op snapshot interval is negative")
+	errScrubInterval      = errors.New("file op scrub interval is negative")
+	errNoScrubRateLimit   = errors.New("no scrub rate limit options in file op options")
 
-	defaultFileOpJitter = 5 * time.Minute
+	defaultFileOpJitter   = 5 * time.Minute
+	defaultScrubLimitMbps = 50.0
 )
 
 type fileOpOptions struct {
-	retentionOpts retention.Options
-	jitter        time.Duration
+	retentionOpts    retention.Options
+	jitter           time.Duration
+	snapshotInterval time.Duration
+	scrubInterval    time.Duration
+	scrubRateLimit   ratelimit.Options
 }
 
 // NewFileOpOptions creates a new file op options
@@ -44,6 +52,9 @@ func NewFileOpOptions() FileOpOptions {
 	return &fileOpOptions{
 		retentionOpts: retention.NewOptions(),
 		jitter:        defaultFileOpJitter,
+		scrubRateLimit: ratelimit.NewOptions().
+			SetLimitEnabled(true).
+			SetLimitMbps(defaultScrubLimitMbps),
 	}
 }
 
@@ -67,6 +78,36 @@ func (o *fileOpOptions) Jitter() time.Duration {
 	return o.jitter
 }
 
+func (o *fileOpOptions) SetSnapshotInterval(value time.Duration) FileOpOptions {
+	opts := *o
+	opts.snapshotInterval = value
+	return &opts
+}
+
+func (o *fileOpOptions) SnapshotInterval() time.Duration {
+	return o.snapshotInterval
+}
+
+func (o *fileOpOptions) SetScrubInterval(value time.Duration) FileOpOptions {
+	opts := *o
+	opts.scrubInterval = value
+	return &opts
+}
+
+func (o *fileOpOptions) ScrubInterval() time.Duration {
+	return o.scrubInterval
+}
+
+func (o *fileOpOptions) SetScrubRateLimitOptions(value ratelimit.Options) FileOpOptions {
+	opts := *o
+	opts.scrubRateLimit = value
+	return &opts
+}
+
+func (o *fileOpOptions) ScrubRateLimitOptions() ratelimit.Options {
+	return o.scrubRateLimit
+}
+
 func (o *fileOpOptions) Validate() error {
 	if o.retentionOpts == nil {
 		return errNoRetentionOptions
@@ -74,5 +115,14 @@ func (o *fileOpOptions) Validate() error {
 	if o.jitter >= o.retentionOpts.BlockSize() {
 		return errJitterTooBig
 	}
+	if o.snapshotInterval < 0 {
+		return errSnapshotInterval
+	}
+	if o.scrubInterval < 0 {
+		return errScrubInterval
+	}
+	if o.scrubRateLimit == nil {
+		return errNoScrubRateLimit
+	}
 	return nil
 }
@@ -71,16 +71,23 @@ func TestFileSystemManagerRun(t *testing.T) {
 	database.bs = bootstrapped
 	fm := NewMockdatabaseFlushManager(ctrl)
 	cm := NewMockdatabaseCleanupManager(ctrl)
+	rm := NewMockdatabaseRollupManager(ctrl)
+	sm := NewMockdatabaseSnapshotManager(ctrl)
 	fsm, err := newFileSystemManager(database, testDatabaseOptions())
 	require.NoError(t, err)
 	mgr := fsm.(*fileSystemManager)
 	mgr.databaseFlushManager = fm
 	mgr.databaseCleanupManager = cm
+	mgr.databaseRollupManager = rm
+	mgr.databaseSnapshotManager = sm
 
 	ts := time.Now()
 	gomock.InOrder(
 		cm.EXPECT().Cleanup(ts).Return(errors.New("foo")),
 		fm.EXPECT().Flush(ts).Return(errors.New("bar")),
+		fm.EXPECT().ColdFlush(ts).Return(errors.New("quux")),
+		rm.EXPECT().Rollup(ts).Return(errors.New("baz")),
+		sm.EXPECT().Snapshot(ts).Return(errors.New("qux")),
 	)
 
 	mgr.Run(ts, syncRun, noForce)
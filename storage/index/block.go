@@ -0,0 +1,235 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/ts"
+)
+
+// postingsList is the set of series with a given tag value
+type postingsList map[ts.Hash]struct{}
+
+type indexedSeries struct {
+	id   ts.ID
+	tags ts.Tags
+}
+
+// block is the in-memory inverted index of the series written to a block
+type block struct {
+	sync.RWMutex
+
+	start       time.Time
+	series      map[ts.Hash]indexedSeries
+	postings    map[string]map[string]postingsList
+	unpersisted bool
+}
+
+func newBlock(start time.Time) *block {
+	return &block{
+		start:    start,
+		series:   make(map[ts.Hash]indexedSeries),
+		postings: make(map[string]map[string]postingsList),
+	}
+}
+
+func (b *block) contains(hash ts.Hash) bool {
+	b.RLock()
+	_, ok := b.series[hash]
+	b.RUnlock()
+	return ok
+}
+
+// insert indexes a series, the ID and tags are copied so the
+// caller retains ownership of them
+func (b *block) insert(id ts.ID, tags ts.Tags) {
+	b.insertWithPersisted(id, tags, false)
+}
+
+// insertPersisted indexes a series already in the persisted index segment
+func (b *block) insertPersisted(id ts.ID, tags ts.Tags) {
+	b.insertWithPersisted(id, tags, true)
+}
+
+func (b *block) insertWithPersisted(id ts.ID, tags ts.Tags, persisted bool) {
+	hash := id.Hash()
+	if b.contains(hash) {
+		return
+	}
+
+	series := indexedSeries{
+		id:   ts.StringID(id.String()),
+		tags: make(ts.Tags, 0, len(tags)),
+	}
+	for _, tag := range tags {
+		series.tags = append(series.tags, ts.Tag{
+			Name:  ts.StringID(tag.Name.String()),
+			Value: ts.StringID(tag.Value.String()),
+		})
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if _, ok := b.series[hash]; ok {
+		return
+	}
+	b.series[hash] = series
+	if !persisted {
+		b.unpersisted = true
+	}
+	for _, tag := range series.tags {
+		name, value := tag.Name.String(), tag.Value.String()
+		values, ok := b.postings[name]
+		if !ok {
+			values = make(map[string]postingsList)
+			b.postings[name] = values
+		}
+		postings, ok := values[value]
+		if !ok {
+			postings = make(postingsList)
+			values[value] = postings
+		}
+		postings[hash] = struct{}{}
+	}
+}
+
+// query returns the series matched by all the matchers
+func (b *block) query(matchers []compiledMatcher, fn func(hash ts.Hash, series indexedSeries) bool) {
+	b.RLock()
+	defer b.RUnlock()
+
+	var candidates postingsList
+	for _, m := range matchers {
+		if m.negate {
+			continue
+		}
+		matched := b.matchWithRLock(m)
+		if candidates == nil {
+			candidates = matched
+		} else {
+			candidates = intersect(candidates, matched)
+		}
+		if len(candidates) == 0 {
+			return
+		}
+	}
+	if candidates == nil {
+		// Only negated matchers, start from every series in the block
+		candidates = make(postingsList, len(b.series))
+		for hash := range b.series {
+			candidates[hash] = struct{}{}
+		}
+	}
+
+	for _, m := range matchers {
+		if !m.negate {
+			continue
+		}
+		excluded := b.matchWithRLock(m)
+		candidates = difference(candidates, excluded)
+		if len(candidates) == 0 {
+			return
+		}
+	}
+
+	for hash := range candidates {
+		if !fn(hash, b.series[hash]) {
+			return
+		}
+	}
+}
+
+func (b *block) matchWithRLock(m compiledMatcher) postingsList {
+	values, ok := b.postings[m.name]
+	if !ok {
+		return nil
+	}
+	if m.re == nil {
+		return values[m.value]
+	}
+	var matched postingsList
+	for value, postings := range values {
+		if !m.re.MatchString(value) {
+			continue
+		}
+		if matched == nil {
+			matched = make(postingsList, len(postings))
+		}
+		for hash := range postings {
+			matched[hash] = struct{}{}
+		}
+	}
+	return matched
+}
+
+// persistSeries returns all the series of the block to persist and marks the
+// block as persisted, unless onlyUnpersisted is set and no series have been
+// inserted since the block was last persisted
+func (b *block) persistSeries(onlyUnpersisted bool) ([]indexedSeries, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	if onlyUnpersisted && !b.unpersisted {
+		return nil, false
+	}
+	series := make([]indexedSeries, 0, len(b.series))
+	for _, s := range b.series {
+		series = append(series, s)
+	}
+	b.unpersisted = false
+	return series, true
+}
+
+// persistFailed marks the block as unpersisted after a failed persist
+func (b *block) persistFailed() {
+	b.Lock()
+	b.unpersisted = true
+	b.Unlock()
+}
+
+func intersect(a, b postingsList) postingsList {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	result := make(postingsList, len(a))
+	for hash := range a {
+		if _, ok := b[hash]; ok {
+			result[hash] = struct{}{}
+		}
+	}
+	return result
+}
+
+func difference(a, b postingsList) postingsList {
+	if len(b) == 0 {
+		return a
+	}
+	result := make(postingsList, len(a))
+	for hash := range a {
+		if _, ok := b[hash]; !ok {
+			result[hash] = struct{}{}
+		}
+	}
+	return result
+}
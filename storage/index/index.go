@@ -0,0 +1,303 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/checked"
+	xerrors "github.com/m3db/m3x/errors"
+)
+
+var (
+	errIndexClosed = errors.New("index is closed")
+)
+
+type nsIndex struct {
+	sync.RWMutex
+
+	id     ts.ID
+	ropts  retention.Options
+	fsOpts fs.Options
+	nowFn  clock.NowFn
+	blocks map[int64]*block
+	closed bool
+}
+
+// NewNamespaceIndex creates a new index for a namespace
+func NewNamespaceIndex(md namespace.Metadata, opts Options) NamespaceIndex {
+	return &nsIndex{
+		id:     md.ID(),
+		ropts:  md.Options().RetentionOptions(),
+		fsOpts: opts.FilesystemOptions(),
+		nowFn:  opts.ClockOptions().NowFn(),
+		blocks: make(map[int64]*block),
+	}
+}
+
+func (i *nsIndex) Insert(id ts.ID, tags ts.Tags, timestamp time.Time) error {
+	blockStart := timestamp.Truncate(i.ropts.BlockSize())
+	b, err := i.blockFor(blockStart)
+	if err != nil {
+		return err
+	}
+	b.insert(id, tags)
+	return nil
+}
+
+func (i *nsIndex) blockFor(blockStart time.Time) (*block, error) {
+	key := blockStart.UnixNano()
+
+	i.RLock()
+	if i.closed {
+		i.RUnlock()
+		return nil, errIndexClosed
+	}
+	b, ok := i.blocks[key]
+	i.RUnlock()
+	if ok {
+		return b, nil
+	}
+
+	i.Lock()
+	defer i.Unlock()
+
+	if i.closed {
+		return nil, errIndexClosed
+	}
+	b, ok = i.blocks[key]
+	if !ok {
+		b = newBlock(blockStart)
+		i.blocks[key] = b
+	}
+	return b, nil
+}
+
+func (i *nsIndex) Query(query Query, opts QueryOptions) (QueryResults, error) {
+	matchers, err := compileQuery(query)
+	if err != nil {
+		return QueryResults{}, err
+	}
+
+	i.RLock()
+	if i.closed {
+		i.RUnlock()
+		return QueryResults{}, errIndexClosed
+	}
+	blocks := make([]*block, 0, len(i.blocks))
+	blockSize := i.ropts.BlockSize()
+	for _, b := range i.blocks {
+		blockEnd := b.start.Add(blockSize)
+		if blockEnd.After(opts.StartInclusive) && b.start.Before(opts.EndExclusive) {
+			blocks = append(blocks, b)
+		}
+	}
+	i.RUnlock()
+
+	// Query the most recent blocks first so a limited query
+	// returns the series that were written most recently
+	sort.Sort(blocksByStartDesc(blocks))
+
+	var (
+		results = QueryResults{Exhaustive: true}
+		seen    = make(map[ts.Hash]struct{})
+	)
+	for _, b := range blocks {
+		b.query(matchers, func(hash ts.Hash, series indexedSeries) bool {
+			if _, ok := seen[hash]; ok {
+				return true
+			}
+			if opts.Limit > 0 && len(results.Results) >= opts.Limit {
+				results.Exhaustive = false
+				return false
+			}
+			seen[hash] = struct{}{}
+			results.Results = append(results.Results, QueryResult{
+				ID:   series.id,
+				Tags: series.tags,
+			})
+			return true
+		})
+		if !results.Exhaustive {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (i *nsIndex) Flush(blockStart time.Time) error {
+	i.RLock()
+	if i.closed {
+		i.RUnlock()
+		return errIndexClosed
+	}
+	b, ok := i.blocks[blockStart.UnixNano()]
+	i.RUnlock()
+	if !ok {
+		// Nothing was indexed for this block
+		return nil
+	}
+
+	series, _ := b.persistSeries(false)
+	if err := i.persist(blockStart, series); err != nil {
+		b.persistFailed()
+		return err
+	}
+	return nil
+}
+
+func (i *nsIndex) Snapshot() error {
+	i.RLock()
+	if i.closed {
+		i.RUnlock()
+		return errIndexClosed
+	}
+	blocks := make([]*block, 0, len(i.blocks))
+	for _, b := range i.blocks {
+		blocks = append(blocks, b)
+	}
+	i.RUnlock()
+
+	multiErr := xerrors.NewMultiError()
+	for _, b := range blocks {
+		series, ok := b.persistSeries(true)
+		if !ok {
+			continue
+		}
+		if err := i.persist(b.start, series); err != nil {
+			b.persistFailed()
+			detailedErr := fmt.Errorf("unable to persist index segment for block %v: %v",
+				b.start, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (i *nsIndex) persist(blockStart time.Time, series []indexedSeries) error {
+	writer := fs.NewIndexSegmentWriter(
+		i.ropts.BlockSize(),
+		i.fsOpts.FilePathPrefix(),
+		i.fsOpts.WriterBufferSize(),
+		i.fsOpts.NewFileMode(),
+		i.fsOpts.NewDirectoryMode(),
+	)
+	if err := writer.Open(i.id, blockStart); err != nil {
+		return err
+	}
+	for _, s := range series {
+		if err := writer.Write(s.id, s.tags); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func (i *nsIndex) Bootstrap() error {
+	var (
+		prefix   = i.fsOpts.FilePathPrefix()
+		earliest = retention.FlushTimeStart(i.ropts, i.nowFn())
+		multiErr = xerrors.NewMultiError()
+	)
+	blockStarts, err := fs.IndexSegmentBlockStarts(prefix, i.id)
+	if err != nil {
+		return err
+	}
+	for _, blockStart := range blockStarts {
+		if blockStart.Before(earliest) {
+			continue
+		}
+		series, err := fs.ReadIndexSegment(prefix, i.id, blockStart,
+			i.fsOpts.ReaderBufferSize(), i.fsOpts.DecodingOptions())
+		if err != nil {
+			detailedErr := fmt.Errorf("unable to read index segment for block %v: %v",
+				blockStart, err)
+			multiErr = multiErr.Add(detailedErr)
+			continue
+		}
+		b, err := i.blockFor(blockStart)
+		if err != nil {
+			return err
+		}
+		for _, s := range series {
+			id := ts.BinaryID(checked.NewBytes(s.ID, nil))
+			tags := make(ts.Tags, 0, len(s.Tags))
+			for _, tag := range s.Tags {
+				tags = append(tags, ts.Tag{
+					Name:  ts.BinaryID(checked.NewBytes(tag.Name, nil)),
+					Value: ts.BinaryID(checked.NewBytes(tag.Value, nil)),
+				})
+			}
+			b.insertPersisted(id, tags)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+func (i *nsIndex) Tick() {
+	earliest := retention.FlushTimeStart(i.ropts, i.nowFn())
+
+	i.Lock()
+	for key, b := range i.blocks {
+		if b.start.Before(earliest) {
+			delete(i.blocks, key)
+		}
+	}
+	i.Unlock()
+}
+
+func (i *nsIndex) CleanupSegments(earliestToRetain time.Time) error {
+	files, err := fs.IndexSegmentFilesBefore(i.fsOpts.FilePathPrefix(), i.id, earliestToRetain)
+	if err != nil {
+		return err
+	}
+	return fs.DeleteFiles(files)
+}
+
+func (i *nsIndex) Close() error {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.closed {
+		return errIndexClosed
+	}
+	i.closed = true
+	i.blocks = nil
+	return nil
+}
+
+type blocksByStartDesc []*block
+
+func (b blocksByStartDesc) Len() int      { return len(b) }
+func (b blocksByStartDesc) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b blocksByStartDesc) Less(i, j int) bool {
+	return b[i].start.After(b[j].start)
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T, filePathPrefix string, now time.Time) NamespaceIndex {
+	ropts := retention.NewOptions().
+		SetBlockSize(2 * time.Hour).
+		SetRetentionPeriod(24 * time.Hour)
+	md := namespace.NewMetadata(ts.StringID("testns"),
+		namespace.NewOptions().SetRetentionOptions(ropts))
+	opts := NewOptions()
+	opts = opts.
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time { return now })).
+		SetFilesystemOptions(opts.FilesystemOptions().SetFilePathPrefix(filePathPrefix))
+	return NewNamespaceIndex(md, opts)
+}
+
+func testTags(pairs ...string) ts.Tags {
+	tags := make(ts.Tags, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		tags = append(tags, ts.Tag{
+			Name:  ts.StringID(pairs[i]),
+			Value: ts.StringID(pairs[i+1]),
+		})
+	}
+	return tags
+}
+
+func queryIDs(t *testing.T, idx NamespaceIndex, opts QueryOptions, matchers ...Matcher) []string {
+	results, err := idx.Query(Query{Matchers: matchers}, opts)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(results.Results))
+	for _, r := range results.Results {
+		ids = append(ids, r.ID.String())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestIndexQuery(t *testing.T) {
+	now := time.Now().Truncate(2 * time.Hour)
+	idx := newTestIndex(t, "", now)
+	defer idx.Close()
+
+	require.NoError(t, idx.Insert(ts.StringID("a"), testTags("city", "nyc", "host", "h1"), now))
+	require.NoError(t, idx.Insert(ts.StringID("b"), testTags("city", "sf", "host", "h2"), now))
+	require.NoError(t, idx.Insert(ts.StringID("c"), testTags("city", "nyc"), now))
+
+	opts := QueryOptions{StartInclusive: now, EndExclusive: now.Add(time.Hour)}
+	tests := []struct {
+		matchers []Matcher
+		expected []string
+	}{
+		{[]Matcher{{Type: MatchEqual, Name: "city", Value: "nyc"}}, []string{"a", "c"}},
+		{[]Matcher{{Type: MatchNotEqual, Name: "city", Value: "nyc"}}, []string{"b"}},
+		{[]Matcher{{Type: MatchRegexp, Name: "host", Value: "h.*"}}, []string{"a", "b"}},
+		{[]Matcher{{Type: MatchRegexp, Name: "host", Value: "h"}}, []string{}},
+		{[]Matcher{{Type: MatchNotRegexp, Name: "host", Value: "h1"}}, []string{"b", "c"}},
+		{[]Matcher{
+			{Type: MatchEqual, Name: "city", Value: "nyc"},
+			{Type: MatchNotEqual, Name: "host", Value: "h1"},
+		}, []string{"c"}},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, queryIDs(t, idx, opts, test.matchers...))
+	}
+
+	// Outside of the indexed block
+	outside := QueryOptions{StartInclusive: now.Add(-2 * time.Hour), EndExclusive: now}
+	require.Equal(t, []string{}, queryIDs(t, idx, outside,
+		Matcher{Type: MatchEqual, Name: "city", Value: "nyc"}))
+
+	_, err := idx.Query(Query{}, opts)
+	require.Error(t, err)
+}
+
+func TestIndexQueryLimit(t *testing.T) {
+	now := time.Now().Truncate(2 * time.Hour)
+	idx := newTestIndex(t, "", now)
+	defer idx.Close()
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, idx.Insert(ts.StringID(id), testTags("city", "nyc"), now))
+	}
+
+	query := Query{Matchers: []Matcher{{Type: MatchEqual, Name: "city", Value: "nyc"}}}
+	results, err := idx.Query(query, QueryOptions{
+		StartInclusive: now,
+		EndExclusive:   now.Add(time.Hour),
+		Limit:          2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(results.Results))
+	require.False(t, results.Exhaustive)
+
+	results, err = idx.Query(query, QueryOptions{
+		StartInclusive: now,
+		EndExclusive:   now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, len(results.Results))
+	require.True(t, results.Exhaustive)
+}
+
+func TestIndexFlushBootstrap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testindex")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now().Truncate(2 * time.Hour)
+	blockStart := now.Add(-4 * time.Hour)
+	idx := newTestIndex(t, dir, now)
+	require.NoError(t, idx.Insert(ts.StringID("a"), testTags("city", "nyc"), blockStart))
+	require.NoError(t, idx.Insert(ts.StringID("b"), testTags("city", "sf"), blockStart))
+	require.NoError(t, idx.Flush(blockStart))
+	require.NoError(t, idx.Close())
+
+	idx = newTestIndex(t, dir, now)
+	defer idx.Close()
+	require.NoError(t, idx.Bootstrap())
+
+	opts := QueryOptions{StartInclusive: blockStart, EndExclusive: now}
+	require.Equal(t, []string{"b"}, queryIDs(t, idx, opts,
+		Matcher{Type: MatchEqual, Name: "city", Value: "sf"}))
+
+	require.NoError(t, idx.CleanupSegments(now))
+	idx = newTestIndex(t, dir, now)
+	defer idx.Close()
+	require.NoError(t, idx.Bootstrap())
+	require.Equal(t, []string{}, queryIDs(t, idx, opts,
+		Matcher{Type: MatchEqual, Name: "city", Value: "sf"}))
+}
+
+func TestIndexSnapshotPersistsOnlyUnpersistedBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testindex")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var (
+		nsID         = ts.StringID("testns")
+		now          = time.Now().Truncate(2 * time.Hour)
+		flushedStart = now.Add(-4 * time.Hour)
+		idx          = newTestIndex(t, dir, now)
+	)
+	assertNumSegments := func(expected int) {
+		blockStarts, err := fs.IndexSegmentBlockStarts(dir, nsID)
+		require.NoError(t, err)
+		require.Equal(t, expected, len(blockStarts))
+	}
+
+	require.NoError(t, idx.Insert(ts.StringID("a"), testTags("city", "nyc"), flushedStart))
+	require.NoError(t, idx.Flush(flushedStart))
+	require.NoError(t, idx.Insert(ts.StringID("b"), testTags("city", "sf"), now))
+	require.NoError(t, idx.Snapshot())
+	assertNumSegments(2)
+
+	// Blocks without series indexed since they were last persisted are not rewritten
+	files, err := fs.IndexSegmentFilesBefore(dir, nsID, now)
+	require.NoError(t, err)
+	for _, f := range files {
+		require.NoError(t, os.Remove(f))
+	}
+	assertNumSegments(1)
+	require.NoError(t, idx.Snapshot())
+	assertNumSegments(1)
+
+	require.NoError(t, idx.Insert(ts.StringID("c"), testTags("city", "la"), flushedStart))
+	require.NoError(t, idx.Snapshot())
+	assertNumSegments(2)
+	require.NoError(t, idx.Close())
+
+	idx = newTestIndex(t, dir, now)
+	defer idx.Close()
+	require.NoError(t, idx.Bootstrap())
+
+	opts := QueryOptions{StartInclusive: flushedStart, EndExclusive: now.Add(time.Hour)}
+	require.Equal(t, []string{"a", "b", "c"}, queryIDs(t, idx, opts,
+		Matcher{Type: MatchRegexp, Name: "city", Value: ".*"}))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/m3db/m3x/errors"
+)
+
+var (
+	errEmptyQuery         = errors.New("query must have at least one matcher")
+	errEmptyMatcherName   = errors.New("matcher must have a tag name")
+	errUnknownMatcherType = errors.New("unknown matcher type")
+)
+
+type compiledMatcher struct {
+	name   string
+	value  string
+	re     *regexp.Regexp
+	negate bool
+}
+
+// compileQuery compiles the matchers of a query, errors are marked as
+// invalid params since they are caused by a malformed query
+func compileQuery(query Query) ([]compiledMatcher, error) {
+	if len(query.Matchers) == 0 {
+		return nil, xerrors.NewInvalidParamsError(errEmptyQuery)
+	}
+	matchers := make([]compiledMatcher, 0, len(query.Matchers))
+	for _, m := range query.Matchers {
+		compiled, err := compileMatcher(m)
+		if err != nil {
+			return nil, xerrors.NewInvalidParamsError(err)
+		}
+		matchers = append(matchers, compiled)
+	}
+	return matchers, nil
+}
+
+func compileMatcher(m Matcher) (compiledMatcher, error) {
+	if m.Name == "" {
+		return compiledMatcher{}, errEmptyMatcherName
+	}
+	compiled := compiledMatcher{name: m.Name, value: m.Value}
+	switch m.Type {
+	case MatchEqual:
+	case MatchNotEqual:
+		compiled.negate = true
+	case MatchRegexp, MatchNotRegexp:
+		// Regular expressions must match the entire tag value
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return compiledMatcher{}, fmt.Errorf("invalid matcher regexp %s: %v", m.Value, err)
+		}
+		compiled.re = re
+		compiled.negate = m.Type == MatchNotRegexp
+	default:
+		return compiledMatcher{}, errUnknownMatcherType
+	}
+	return compiled, nil
+}
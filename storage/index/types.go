@@ -0,0 +1,134 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package index
+
+import (
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/instrument"
+)
+
+// MatcherType is the type of a tag matcher
+type MatcherType int
+
+const (
+	// MatchEqual matches series with a tag equal to a value
+	MatchEqual MatcherType = iota
+
+	// MatchNotEqual matches series without a tag equal to a value
+	MatchNotEqual
+
+	// MatchRegexp matches series with a tag matching a regular expression
+	MatchRegexp
+
+	// MatchNotRegexp matches series without a tag matching a regular expression
+	MatchNotRegexp
+)
+
+// Matcher matches series by the value of one of their tags
+type Matcher struct {
+	Type  MatcherType
+	Name  string
+	Value string
+}
+
+// Query is a conjunction of matchers, a series must satisfy
+// every matcher to be returned
+type Query struct {
+	Matchers []Matcher
+}
+
+// QueryOptions are the options for a query
+type QueryOptions struct {
+	StartInclusive time.Time
+	EndExclusive   time.Time
+
+	// Limit is the maximum number of series returned, zero means no limit
+	Limit int
+}
+
+// QueryResult is a series matched by a query, the ID and tags are
+// owned by the index and must not be finalized by the caller
+type QueryResult struct {
+	ID   ts.ID
+	Tags ts.Tags
+}
+
+// QueryResults are the series matched by a query
+type QueryResults struct {
+	Results []QueryResult
+
+	// Exhaustive is false when matched series were omitted due to the limit
+	Exhaustive bool
+}
+
+// NamespaceIndex indexes the series of a namespace by their tags
+// for each block of the namespace
+type NamespaceIndex interface {
+	// Insert indexes a series and its tags for the block containing the timestamp
+	Insert(id ts.ID, tags ts.Tags, timestamp time.Time) error
+
+	// Query returns the series matched by the query in blocks overlapping the query range
+	Query(query Query, opts QueryOptions) (QueryResults, error)
+
+	// Flush persists the index segment of a block
+	Flush(blockStart time.Time) error
+
+	// Snapshot persists the index segments of the blocks with series
+	// indexed since the blocks were last persisted
+	Snapshot() error
+
+	// Bootstrap loads the persisted index segments within retention
+	Bootstrap() error
+
+	// Tick removes the blocks that have fallen out of retention
+	Tick()
+
+	// CleanupSegments removes the persisted index segments before a time
+	CleanupSegments(earliestToRetain time.Time) error
+
+	// Close closes the index
+	Close() error
+}
+
+// Options represents the options for a namespace index
+type Options interface {
+	// SetClockOptions sets the clock options
+	SetClockOptions(value clock.Options) Options
+
+	// ClockOptions returns the clock options
+	ClockOptions() clock.Options
+
+	// SetInstrumentOptions sets the instrumentation options
+	SetInstrumentOptions(value instrument.Options) Options
+
+	// InstrumentOptions returns the instrumentation options
+	InstrumentOptions() instrument.Options
+
+	// SetFilesystemOptions sets the filesystem options used to persist segments
+	SetFilesystemOptions(value fs.Options) Options
+
+	// FilesystemOptions returns the filesystem options used to persist segments
+	FilesystemOptions() fs.Options
+}
@@ -70,6 +70,7 @@ type mediator struct {
 	databaseTickManager
 	databaseRepairer
 
+	scrubber databaseScrubber
 	opts     Options
 	nowFn    clock.NowFn
 	sleepFn  sleepFn
@@ -104,6 +105,7 @@ func newMediator(database database, opts Options) (databaseMediator, error) {
 		}
 	}
 
+	d.scrubber = newDatabaseScrubber(database, opts)
 	d.databaseTickManager = newTickManager(database, opts)
 	d.databaseBootstrapManager = newBootstrapManager(database, d, opts)
 	return d, nil
@@ -119,6 +121,7 @@ func (m *mediator) Open() error {
 	go m.reportLoop()
 	go m.ongoingTick()
 	m.databaseRepairer.Start()
+	m.scrubber.Start()
 	return nil
 }
 
@@ -150,6 +153,7 @@ func (m *mediator) Tick(softDeadline time.Duration, runType runType, forceType f
 func (m *mediator) Report() {
 	m.databaseBootstrapManager.Report()
 	m.databaseRepairer.Report()
+	m.scrubber.Report()
 	m.databaseFileSystemManager.Report()
 }
 
@@ -165,6 +169,7 @@ func (m *mediator) Close() error {
 	m.state = mediatorClosed
 	close(m.closedCh)
 	m.databaseRepairer.Stop()
+	m.scrubber.Stop()
 
 	return nil
 }
@@ -30,13 +30,17 @@ import (
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/context"
 	"github.com/m3db/m3db/persist"
+	"github.com/m3db/m3db/persist/fs"
 	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/retention"
 	m3dbruntime "github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/index"
 	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/storage/series"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3db/x/io"
 	"github.com/m3db/m3x/errors"
@@ -48,6 +52,12 @@ import (
 	"github.com/uber-go/tally"
 )
 
+type newCommitLogIteratorFn func(
+	opts commitlog.Options,
+	filter commitlog.FileFilterPredicate,
+	seriesFilter commitlog.SeriesFilterPredicate,
+) (commitlog.Iterator, error)
+
 func commitLogWriteNoOp(
 	ctx context.Context,
 	series commitlog.Series,
@@ -62,6 +72,7 @@ type dbNamespace struct {
 	sync.RWMutex
 
 	id             ts.ID
+	metadata       namespace.Metadata
 	shardSet       sharding.ShardSet
 	blockRetriever block.DatabaseBlockRetriever
 	opts           Options
@@ -70,13 +81,15 @@ type dbNamespace struct {
 	nowFn          clock.NowFn
 	log            xlog.Logger
 	bs             bootstrapState
+	index          index.NamespaceIndex
 
 	// Contains an entry to all shards for fast shard lookup, an
 	// entry will be nil when this shard does not belong to current database
 	shards []databaseShard
 
-	increasingIndex  increasingIndex
-	writeCommitLogFn writeCommitLogFn
+	increasingIndex        increasingIndex
+	writeCommitLogFn       writeCommitLogFn
+	newCommitLogIteratorFn newCommitLogIteratorFn
 
 	tickWorkers            xsync.WorkerPool
 	tickWorkersConcurrency int
@@ -87,6 +100,9 @@ type dbNamespace struct {
 type databaseNamespaceMetrics struct {
 	bootstrap      instrument.MethodMetrics
 	flush          instrument.MethodMetrics
+	coldFlush      instrument.MethodMetrics
+	rollup         instrument.MethodMetrics
+	snapshot       instrument.MethodMetrics
 	unfulfilled    tally.Counter
 	bootstrapStart tally.Counter
 	bootstrapEnd   tally.Counter
@@ -119,6 +135,9 @@ func newDatabaseNamespaceMetrics(scope tally.Scope, samplingRate float64) databa
 	return databaseNamespaceMetrics{
 		bootstrap:      instrument.NewMethodMetrics(scope, "bootstrap", samplingRate),
 		flush:          instrument.NewMethodMetrics(scope, "flush", samplingRate),
+		coldFlush:      instrument.NewMethodMetrics(scope, "cold-flush", samplingRate),
+		rollup:         instrument.NewMethodMetrics(scope, "rollup", samplingRate),
+		snapshot:       instrument.NewMethodMetrics(scope, "snapshot", samplingRate),
 		unfulfilled:    scope.Counter("bootstrap.unfulfilled"),
 		bootstrapStart: scope.Counter("bootstrap.start"),
 		bootstrapEnd:   scope.Counter("bootstrap.end"),
@@ -152,6 +171,15 @@ func newDatabaseNamespace(
 ) databaseNamespace {
 	id := metadata.ID()
 	nopts := metadata.Options()
+
+	// Shards and series in this namespace use the namespace retention
+	// options rather than those configured for the database
+	opts = opts.SetRetentionOptions(nopts.RetentionOptions())
+	if nopts.ColdWritesEnabled() {
+		seriesOpts := NewSeriesOptionsFromOptions(opts).SetColdWritesEnabled(true)
+		opts = opts.SetDatabaseSeriesPool(series.NewDatabaseSeriesPool(seriesOpts, nil))
+	}
+
 	fn := writeCommitLogFn
 	if !nopts.WritesToCommitLog() {
 		fn = commitLogWriteNoOp
@@ -163,20 +191,28 @@ func newDatabaseNamespace(
 			"namespace": id.String(),
 		})
 
+	indexOpts := index.NewOptions().
+		SetClockOptions(opts.ClockOptions()).
+		SetInstrumentOptions(iops).
+		SetFilesystemOptions(opts.CommitLogOptions().FilesystemOptions())
+
 	tickWorkersConcurrency := int(math.Max(1, float64(runtime.NumCPU())/8))
 	tickWorkers := xsync.NewWorkerPool(tickWorkersConcurrency)
 	tickWorkers.Init()
 
 	n := &dbNamespace{
 		id:                     id,
+		metadata:               metadata,
 		shardSet:               shardSet,
 		blockRetriever:         blockRetriever,
 		opts:                   opts,
 		nopts:                  nopts,
 		nowFn:                  opts.ClockOptions().NowFn(),
 		log:                    opts.InstrumentOptions().Logger(),
+		index:                  index.NewNamespaceIndex(metadata, indexOpts),
 		increasingIndex:        increasingIndex,
 		writeCommitLogFn:       fn,
+		newCommitLogIteratorFn: commitlog.NewIterator,
 		tickWorkers:            tickWorkers,
 		tickWorkersConcurrency: tickWorkersConcurrency,
 		metrics:                newDatabaseNamespaceMetrics(scope, iops.MetricsSamplingRate()),
@@ -199,6 +235,10 @@ func (n *dbNamespace) NumSeries() int64 {
 	return count
 }
 
+func (n *dbNamespace) Options() namespace.Options {
+	return n.nopts
+}
+
 func (n *dbNamespace) Shards() []Shard {
 	n.RLock()
 	shards := n.shardSet.AllIDs()
@@ -305,6 +345,8 @@ func (n *dbNamespace) Tick(c context.Cancellable, softDeadline time.Duration) {
 		return
 	}
 
+	n.index.Tick()
+
 	n.metrics.tick.activeSeries.Update(float64(r.activeSeries))
 	n.metrics.tick.expiredSeries.Inc(int64(r.expiredSeries))
 	n.metrics.tick.activeBlocks.Update(float64(r.activeBlocks))
@@ -332,6 +374,33 @@ func (n *dbNamespace) Write(
 	return shard.Write(ctx, id, timestamp, value, unit, annotation)
 }
 
+func (n *dbNamespace) WriteTagged(
+	ctx context.Context,
+	id ts.ID,
+	tags ts.Tags,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	shard, err := n.shardFor(id)
+	if err != nil {
+		return err
+	}
+	if err := shard.WriteTagged(ctx, id, tags, timestamp, value, unit, annotation); err != nil {
+		return err
+	}
+	return n.index.Insert(id, tags, timestamp)
+}
+
+func (n *dbNamespace) QueryIDs(
+	ctx context.Context,
+	query index.Query,
+	opts index.QueryOptions,
+) (index.QueryResults, error) {
+	return n.index.Query(query, opts)
+}
+
 func (n *dbNamespace) ReadEncoded(
 	ctx context.Context,
 	id ts.ID,
@@ -344,6 +413,14 @@ func (n *dbNamespace) ReadEncoded(
 	return shard.ReadEncoded(ctx, id, start, end)
 }
 
+func (n *dbNamespace) Delete(id ts.ID, start, end time.Time) error {
+	shard, err := n.shardFor(id)
+	if err != nil {
+		return err
+	}
+	return shard.Delete(id, start, end)
+}
+
 func (n *dbNamespace) FetchBlocks(
 	ctx context.Context,
 	shardID uint32,
@@ -422,7 +499,7 @@ func (n *dbNamespace) Bootstrap(
 		shardIDs[i] = shard.ID()
 	}
 
-	bootstrapResult, err := process.Run(n.id, shardIDs, targetRanges)
+	bootstrapResult, err := process.Run(n.metadata, shardIDs, targetRanges)
 	if err != nil {
 		n.log.Errorf("bootstrap for namespace %s aborted due to error: %v",
 			n.id.String(), err)
@@ -471,6 +548,19 @@ func (n *dbNamespace) Bootstrap(
 
 	wg.Wait()
 
+	// The tags of series in blocks whose index segments are not yet flushed
+	// and cold writes to blocks fulfilled from their filesets are only held
+	// by the commit log
+	if n.nopts.WritesToCommitLog() {
+		if err := n.replayCommitLog(shards); err != nil {
+			multiErr = multiErr.Add(fmt.Errorf("failed to replay commit log: %v", err))
+		}
+	}
+
+	if err := n.index.Bootstrap(); err != nil {
+		multiErr = multiErr.Add(fmt.Errorf("index failed to bootstrap: %v", err))
+	}
+
 	// Counter, tag this with namespace
 	unfulfilled := int64(len(bootstrapResult.Unfulfilled()))
 	n.metrics.unfulfilled.Inc(unfulfilled)
@@ -517,6 +607,11 @@ func (n *dbNamespace) Flush(
 		}
 	}
 
+	if err := n.index.Flush(blockStart); err != nil {
+		detailedErr := fmt.Errorf("index failed to flush data: %v", err)
+		multiErr = multiErr.Add(detailedErr)
+	}
+
 	res := multiErr.FinalError()
 	n.metrics.flush.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
 	return res
@@ -560,6 +655,217 @@ func (n *dbNamespace) NeedsFlush(blockStart time.Time) bool {
 	return false
 }
 
+func (n *dbNamespace) ColdFlush(flush persist.Flush) error {
+	callStart := n.nowFn()
+
+	n.RLock()
+	if n.bs != bootstrapped {
+		n.RUnlock()
+		n.metrics.coldFlush.ReportError(n.nowFn().Sub(callStart))
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	if !n.nopts.NeedsFlush() || !n.nopts.ColdWritesEnabled() {
+		n.metrics.coldFlush.ReportSuccess(n.nowFn().Sub(callStart))
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	shards := n.getOwnedShards()
+	for _, shard := range shards {
+		// We still want to proceed if a shard fails to cold flush its data.
+		if err := shard.ColdFlush(n.id, flush); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to cold flush data: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	res := multiErr.FinalError()
+	n.metrics.coldFlush.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
+	return res
+}
+
+func (n *dbNamespace) ColdWritesPending() bool {
+	for _, shard := range n.getOwnedShards() {
+		if shard.ColdWritesPending() {
+			return true
+		}
+	}
+	return false
+}
+
+// replayCommitLog reindexes the tagged series in the commit log since only
+// flushed index segments are bootstrapped from disk. If cold writes are
+// enabled it also writes the commit log entries of blocks that have already
+// been flushed back into their shards as cold writes, the bootstrappers
+// fulfil these blocks from their filesets which do not yet contain the cold
+// writes that were not merged into them by a cold flush before a restart.
+func (n *dbNamespace) replayCommitLog(shards []databaseShard) error {
+	var (
+		fsOpts     = n.opts.CommitLogOptions().FilesystemOptions()
+		prefixes   = fs.TierFilePathPrefixes(fsOpts.FilePathPrefix(), fsOpts.TieringPolicy())
+		ropts      = n.nopts.RetentionOptions()
+		blockSize  = ropts.BlockSize()
+		earliest   = retention.FlushTimeStart(ropts, n.nowFn())
+		coldWrites = n.nopts.ColdWritesEnabled()
+		byID       = make(map[uint32]databaseShard, len(shards))
+		flushed    = make(map[uint32]map[time.Time]bool, len(shards))
+		errs       = 0
+		indexErrs  = 0
+	)
+	for _, shard := range shards {
+		byID[shard.ID()] = shard
+		flushed[shard.ID()] = make(map[time.Time]bool)
+	}
+
+	// Commit logs covered by a snapshot are read as well since snapshots
+	// do not include the data of flushed blocks
+	seriesFilter := func(namespace ts.ID, shard uint32) bool {
+		_, ok := byID[shard]
+		return ok && namespace.Equal(n.id)
+	}
+	iter, err := n.newCommitLogIteratorFn(n.opts.CommitLogOptions(),
+		commitlog.ReadAllPredicate(), seriesFilter)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	ctx := context.NewContext()
+	for iter.Next() {
+		entry, dp, unit, annotation := iter.Current()
+		shard, ok := byID[entry.Shard]
+		if !ok {
+			continue
+		}
+		blockStart := dp.Timestamp.Truncate(blockSize)
+		if len(entry.Tags) > 0 && !blockStart.Before(earliest) {
+			// Series already in a flushed index segment are not inserted twice
+			if err := n.index.Insert(entry.ID, entry.Tags, dp.Timestamp); err != nil {
+				indexErrs++
+			}
+		}
+		if !coldWrites {
+			continue
+		}
+
+		exists, ok := flushed[entry.Shard][blockStart]
+		if !ok {
+			exists = fs.FilesetExistsAtAnyTier(prefixes, n.id, entry.Shard, blockStart)
+			flushed[entry.Shard][blockStart] = exists
+		}
+		if !exists {
+			// Blocks not yet flushed are bootstrapped from the commit log
+			continue
+		}
+
+		// Datapoints already in the fileset are deduplicated when the cold
+		// writes are merged into the block
+		ctx.Reset()
+		err := shard.Write(ctx, entry.ID, dp.Timestamp, dp.Value, unit, annotation)
+		ctx.BlockingClose()
+		if err != nil && !xerrors.IsInvalidParams(err) {
+			errs++
+		}
+	}
+	if err := iter.Err(); err != nil {
+		n.log.Errorf("error reading commit log to replay: %v", err)
+	}
+
+	multiErr := xerrors.NewMultiError()
+	if indexErrs > 0 {
+		multiErr = multiErr.Add(fmt.Errorf("%d tagged series failed to reindex", indexErrs))
+	}
+	if errs > 0 {
+		multiErr = multiErr.Add(fmt.Errorf("%d cold writes failed to replay", errs))
+	}
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) Rollup(source Namespace, blockStarts []time.Time) error {
+	callStart := n.nowFn()
+
+	n.RLock()
+	if n.bs != bootstrapped {
+		n.RUnlock()
+		n.metrics.rollup.ReportError(n.nowFn().Sub(callStart))
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	rollup := n.nopts.Rollup()
+	if rollup == nil || !rollup.SourceNamespace.Equal(source.ID()) {
+		n.metrics.rollup.ReportSuccess(n.nowFn().Sub(callStart))
+		return nil
+	}
+
+	multiErr := xerrors.NewMultiError()
+	shards := n.getOwnedShards()
+	for _, shard := range shards {
+		// We still want to proceed if a shard fails to roll up its data.
+		if err := shard.Rollup(n.metadata, blockStarts); err != nil {
+			detailedErr := fmt.Errorf("shard %d failed to roll up data: %v",
+				shard.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	res := multiErr.FinalError()
+	n.metrics.rollup.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
+	return res
+}
+
+func (n *dbNamespace) Snapshot(filePathPrefix string, t time.Time) error {
+	callStart := n.nowFn()
+
+	n.RLock()
+	if n.bs != bootstrapped {
+		n.RUnlock()
+		n.metrics.snapshot.ReportError(n.nowFn().Sub(callStart))
+		return errNamespaceNotBootstrapped
+	}
+	n.RUnlock()
+
+	// Only the data of namespaces that write to the commit log needs to be
+	// snapshotted before the commit logs covered by the snapshot are removed,
+	// including namespaces that are never flushed
+	if !n.nopts.WritesToCommitLog() {
+		n.metrics.snapshot.ReportSuccess(n.nowFn().Sub(callStart))
+		return nil
+	}
+
+	var (
+		ropts     = n.nopts.RetentionOptions()
+		blockSize = ropts.BlockSize()
+		earliest  = retention.FlushTimeStart(ropts, t)
+		latest    = t.Add(ropts.BufferFuture()).Truncate(blockSize)
+		multiErr  = xerrors.NewMultiError()
+		shards    = n.getOwnedShards()
+	)
+	for _, shard := range shards {
+		for blockStart := earliest; !blockStart.After(latest); blockStart = blockStart.Add(blockSize) {
+			// We still want to proceed if a shard fails to snapshot its data.
+			if err := shard.Snapshot(n.id, blockStart, filePathPrefix); err != nil {
+				detailedErr := fmt.Errorf("shard %d failed to snapshot data: %v",
+					shard.ID(), err)
+				multiErr = multiErr.Add(detailedErr)
+			}
+		}
+	}
+
+	// The tags of series in unflushed blocks are otherwise only held by the
+	// commit logs that are removed once covered by the snapshot
+	if err := n.index.Snapshot(); err != nil {
+		multiErr = multiErr.Add(fmt.Errorf("index failed to snapshot: %v", err))
+	}
+
+	res := multiErr.FinalError()
+	n.metrics.snapshot.ReportSuccessOrError(res, n.nowFn().Sub(callStart))
+	return res
+}
+
 func (n *dbNamespace) CleanupFileset(earliestToRetain time.Time) error {
 	if !n.nopts.NeedsFilesetCleanup() {
 		return nil
@@ -572,7 +878,28 @@ func (n *dbNamespace) CleanupFileset(earliestToRetain time.Time) error {
 			multiErr = multiErr.Add(err)
 		}
 	}
+	if err := n.index.CleanupSegments(earliestToRetain); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+
+	return multiErr.FinalError()
+}
+
+func (n *dbNamespace) TierFilesets(t time.Time) error {
+	policy := n.opts.CommitLogOptions().FilesystemOptions().TieringPolicy()
+	if !policy.Enabled() || !n.nopts.NeedsFilesetCleanup() {
+		return nil
+	}
 
+	var (
+		olderThan = t.Add(-policy.Age)
+		multiErr  = xerrors.NewMultiError()
+	)
+	for _, shard := range n.getOwnedShards() {
+		if err := shard.TierFilesets(n.metadata, olderThan); err != nil {
+			multiErr = multiErr.Add(err)
+		}
+	}
 	return multiErr.FinalError()
 }
 
@@ -637,7 +964,7 @@ func (n *dbNamespace) Repair(
 			ctx := n.opts.ContextPool().Get()
 			defer ctx.Close()
 
-			metadataRes, err := shard.Repair(ctx, n.id, tr, repairer)
+			metadataRes, err := shard.Repair(ctx, n.metadata, tr, repairer)
 
 			mutex.Lock()
 			if err != nil {
@@ -677,6 +1004,42 @@ func (n *dbNamespace) Repair(
 	return multiErr.FinalError()
 }
 
+func (n *dbNamespace) MarkBlockCorrupt(shardID uint32, blockStart time.Time) error {
+	n.RLock()
+	shard, err := n.shardAtWithRLock(shardID)
+	n.RUnlock()
+	if err != nil {
+		return err
+	}
+	shard.MarkBlockCorrupt(blockStart)
+	return nil
+}
+
+func (n *dbNamespace) Close() error {
+	n.Lock()
+	shards := n.shards
+	n.shardSet = sharding.NewEmptyShardSet(n.shardSet.HashFn())
+	n.shards = nil
+	n.Unlock()
+
+	multiErr := xerrors.NewMultiError()
+	for _, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if err := shard.Close(); err != nil {
+			n.metrics.shards.closeErrors.Inc(1)
+			multiErr = multiErr.Add(err)
+			continue
+		}
+		n.metrics.shards.close.Inc(1)
+	}
+	if err := n.index.Close(); err != nil {
+		multiErr = multiErr.Add(err)
+	}
+	return multiErr.FinalError()
+}
+
 func (n *dbNamespace) getOwnedShards() []databaseShard {
 	n.RLock()
 	shards := n.shardSet.AllIDs()
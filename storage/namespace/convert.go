@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/generated/proto/schema"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/ts"
+)
+
+func toProto(m Map) *schema.NamespaceRegistry {
+	reg := &schema.NamespaceRegistry{
+		Namespaces: make([]*schema.NamespaceMetadata, 0, len(m.Metadatas())),
+	}
+	for _, md := range m.Metadatas() {
+		opts := md.Options()
+		nsOpts := &schema.NamespaceOptions{
+			NeedsBootstrap:      opts.NeedsBootstrap(),
+			NeedsFlush:          opts.NeedsFlush(),
+			WritesToCommitLog:   opts.WritesToCommitLog(),
+			NeedsFilesetCleanup: opts.NeedsFilesetCleanup(),
+			NeedsRepair:         opts.NeedsRepair(),
+			ColdWritesEnabled:   opts.ColdWritesEnabled(),
+			SyncCommitLogWrites: opts.SyncCommitLogWrites(),
+		}
+		// Inherited retention options are left unset to remain inherited
+		if !opts.RetentionOptionsInherited() {
+			ropts := opts.RetentionOptions()
+			nsOpts.RetentionOptions = &schema.NamespaceRetentionOptions{
+				RetentionPeriodNanos:                     ropts.RetentionPeriod().Nanoseconds(),
+				BlockSizeNanos:                           ropts.BlockSize().Nanoseconds(),
+				BufferFutureNanos:                        ropts.BufferFuture().Nanoseconds(),
+				BufferPastNanos:                          ropts.BufferPast().Nanoseconds(),
+				BlockDataExpiry:                          ropts.BlockDataExpiry(),
+				BlockDataExpiryAfterNotAccessPeriodNanos: ropts.BlockDataExpiryAfterNotAccessedPeriod().Nanoseconds(),
+			}
+		}
+		if rollup := opts.Rollup(); rollup != nil {
+			nsOpts.RollupOptions = &schema.NamespaceRollupOptions{
+				SourceNamespace: rollup.SourceNamespace.String(),
+				ResolutionNanos: rollup.Resolution.Nanoseconds(),
+				Aggregation:     rollup.Aggregation.String(),
+			}
+		}
+		reg.Namespaces = append(reg.Namespaces, &schema.NamespaceMetadata{
+			Id:      md.ID().String(),
+			Options: nsOpts,
+		})
+	}
+	return reg
+}
+
+func fromProto(reg *schema.NamespaceRegistry) (Map, error) {
+	metadatas := make([]Metadata, 0, len(reg.GetNamespaces()))
+	for _, ns := range reg.GetNamespaces() {
+		opts := NewOptions()
+		if nsOpts := ns.GetOptions(); nsOpts != nil {
+			opts = opts.
+				SetNeedsBootstrap(nsOpts.NeedsBootstrap).
+				SetNeedsFlush(nsOpts.NeedsFlush).
+				SetWritesToCommitLog(nsOpts.WritesToCommitLog).
+				SetNeedsFilesetCleanup(nsOpts.NeedsFilesetCleanup).
+				SetNeedsRepair(nsOpts.NeedsRepair).
+				SetColdWritesEnabled(nsOpts.ColdWritesEnabled).
+				SetSyncCommitLogWrites(nsOpts.SyncCommitLogWrites)
+			if ropts := nsOpts.GetRetentionOptions(); ropts != nil {
+				if ropts.RetentionPeriodNanos <= 0 || ropts.BlockSizeNanos <= 0 {
+					return nil, fmt.Errorf("namespace %s has invalid retention options", ns.Id)
+				}
+				opts = opts.SetRetentionOptions(retention.NewOptions().
+					SetRetentionPeriod(time.Duration(ropts.RetentionPeriodNanos)).
+					SetBlockSize(time.Duration(ropts.BlockSizeNanos)).
+					SetBufferFuture(time.Duration(ropts.BufferFutureNanos)).
+					SetBufferPast(time.Duration(ropts.BufferPastNanos)).
+					SetBlockDataExpiry(ropts.BlockDataExpiry).
+					SetBlockDataExpiryAfterNotAccessedPeriod(
+						time.Duration(ropts.BlockDataExpiryAfterNotAccessPeriodNanos)))
+			}
+			if rollup := nsOpts.GetRollupOptions(); rollup != nil {
+				aggregation, err := encoding.ParseAggregationType(rollup.Aggregation)
+				if err != nil {
+					return nil, fmt.Errorf("namespace %s has invalid rollup options: %v", ns.Id, err)
+				}
+				opts = opts.SetRollup(&Rollup{
+					SourceNamespace: ts.StringID(rollup.SourceNamespace),
+					Resolution:      time.Duration(rollup.ResolutionNanos),
+					Aggregation:     aggregation,
+				})
+			}
+		}
+		metadatas = append(metadatas, NewMetadata(ts.StringID(ns.Id), opts))
+	}
+	return NewMap(metadatas)
+}
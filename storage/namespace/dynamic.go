@@ -0,0 +1,265 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3cluster/kv"
+	"github.com/m3db/m3db/generated/proto/schema"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/log"
+	"github.com/m3db/m3x/watch"
+)
+
+var (
+	errInitTimeOut     = errors.New("timed out waiting for initial value")
+	errInvalidRegistry = errors.New("could not parse latest value from config service")
+)
+
+type dynamicInitializer struct {
+	sync.Mutex
+	opts DynamicOptions
+	reg  Registry
+}
+
+// NewDynamicInitializer returns a dynamic registry initializer
+func NewDynamicInitializer(opts DynamicOptions) Initializer {
+	return &dynamicInitializer{opts: opts}
+}
+
+func (i *dynamicInitializer) Init() (Registry, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.reg != nil {
+		return i.reg, nil
+	}
+
+	if err := i.opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	reg, err := newDynamicRegistry(i.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	i.reg = reg
+	return i.reg, nil
+}
+
+type dynamicRegistry struct {
+	sync.RWMutex
+	opts      DynamicOptions
+	store     kv.Store
+	watch     kv.ValueWatch
+	watchable xwatch.Watchable
+	closed    bool
+	logger    xlog.Logger
+}
+
+func newDynamicRegistry(opts DynamicOptions) (Registry, error) {
+	store, err := opts.ConfigServiceClient().KV()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := opts.InstrumentOptions().Logger()
+	if initial := opts.InitialNamespaces(); len(initial) > 0 {
+		m, err := NewMap(initial)
+		if err != nil {
+			return nil, err
+		}
+		_, err = store.SetIfNotExists(opts.RegistryKey(), toProto(m))
+		if err != nil && err != kv.ErrAlreadyExists {
+			return nil, err
+		}
+	}
+
+	watch, err := store.Watch(opts.RegistryKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = waitOnInit(watch, opts.InitTimeout()); err != nil {
+		logger.Errorf("dynamic namespace registry initialization timed out in %s: %v",
+			opts.InitTimeout().String(), err)
+		return nil, err
+	}
+
+	m, err := getMapFromUpdate(watch.Get())
+	if err != nil {
+		logger.Errorf("dynamic namespace registry received invalid initial value: %v",
+			err)
+		return nil, err
+	}
+
+	watchable := xwatch.NewWatchable()
+	watchable.Update(m)
+
+	r := &dynamicRegistry{
+		opts:      opts,
+		store:     store,
+		watch:     watch,
+		watchable: watchable,
+		logger:    logger,
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *dynamicRegistry) isClosed() bool {
+	r.RLock()
+	closed := r.closed
+	r.RUnlock()
+	return closed
+}
+
+func (r *dynamicRegistry) run() {
+	for !r.isClosed() {
+		if _, ok := <-r.watch.C(); !ok {
+			r.Close()
+			break
+		}
+
+		m, err := getMapFromUpdate(r.watch.Get())
+		if err != nil {
+			r.logger.Warnf("dynamic namespace registry received invalid update: %v", err)
+			continue
+		}
+		r.watchable.Update(m)
+	}
+}
+
+func (r *dynamicRegistry) Get() Map {
+	return r.watchable.Get().(Map)
+}
+
+func (r *dynamicRegistry) Watch() (Watch, error) {
+	_, w, err := r.watchable.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return NewWatch(w), err
+}
+
+func (r *dynamicRegistry) Add(md Metadata) error {
+	return r.update(func(current Map) (Map, error) {
+		if _, exists := current.Get(md.ID()); exists {
+			return nil, fmt.Errorf("namespace %s already exists", md.ID().String())
+		}
+		return NewMap(append(append([]Metadata(nil), current.Metadatas()...), md))
+	})
+}
+
+func (r *dynamicRegistry) Remove(id ts.ID) error {
+	return r.update(func(current Map) (Map, error) {
+		return withoutNamespace(current, id)
+	})
+}
+
+// update performs a check and set of the registry value, retrying
+// if the value was concurrently modified by another writer
+func (r *dynamicRegistry) update(fn func(current Map) (Map, error)) error {
+	if r.isClosed() {
+		return errRegistryClosed
+	}
+
+	key := r.opts.RegistryKey()
+	for {
+		var (
+			current Map
+			version int
+		)
+		value, err := r.store.Get(key)
+		switch err {
+		case nil:
+			current, err = getMapFromUpdate(value)
+			if err != nil {
+				return err
+			}
+			version = value.Version()
+		case kv.ErrNotFound:
+			current, err = NewMap(nil)
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		if version == 0 {
+			_, err = r.store.SetIfNotExists(key, toProto(next))
+		} else {
+			_, err = r.store.CheckAndSet(key, version, toProto(next))
+		}
+		if err == kv.ErrAlreadyExists || err == kv.ErrVersionMismatch {
+			continue
+		}
+		return err
+	}
+}
+
+func (r *dynamicRegistry) Close() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	r.closed = true
+
+	r.watch.Close()
+	r.watchable.Close()
+}
+
+func waitOnInit(w kv.ValueWatch, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-w.C():
+		return nil
+	case <-time.After(d):
+		return errInitTimeOut
+	}
+}
+
+func getMapFromUpdate(value kv.Value) (Map, error) {
+	if value == nil {
+		return nil, errInvalidRegistry
+	}
+	var reg schema.NamespaceRegistry
+	if err := value.Unmarshal(&reg); err != nil {
+		return nil, err
+	}
+	return fromProto(&reg)
+}
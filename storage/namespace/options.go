@@ -20,6 +20,16 @@
 
 package namespace
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3x/instrument"
+)
+
 const (
 	// Namespace requires bootstrapping by default
 	defaultNeedsBootstrap = true
@@ -35,6 +45,12 @@ const (
 
 	// Namespace requires repair by default
 	defaultNeedsRepair = true
+
+	// Namespace rejects writes outside the buffer window by default
+	defaultColdWritesEnabled = false
+
+	// Namespace acknowledges writes before the commit log is fsynced by default
+	defaultSyncCommitLogWrites = false
 )
 
 type options struct {
@@ -43,6 +59,11 @@ type options struct {
 	writesToCommitLog   bool
 	needsFilesetCleanup bool
 	needsRepair         bool
+	retentionOpts       retention.Options
+	retentionInherited  bool
+	rollup              *Rollup
+	coldWritesEnabled   bool
+	syncCommitLogWrites bool
 }
 
 // NewOptions creates a new namespace options
@@ -53,6 +74,10 @@ func NewOptions() Options {
 		writesToCommitLog:   defaultWritesToCommitLog,
 		needsFilesetCleanup: defaultNeedsFilesetCleanup,
 		needsRepair:         defaultNeedsRepair,
+		retentionOpts:       retention.NewOptions(),
+		retentionInherited:  true,
+		coldWritesEnabled:   defaultColdWritesEnabled,
+		syncCommitLogWrites: defaultSyncCommitLogWrites,
 	}
 }
 
@@ -105,3 +130,138 @@ func (o *options) SetNeedsRepair(value bool) Options {
 func (o *options) NeedsRepair() bool {
 	return o.needsRepair
 }
+
+func (o *options) SetRetentionOptions(value retention.Options) Options {
+	opts := *o
+	opts.retentionOpts = value
+	opts.retentionInherited = false
+	return &opts
+}
+
+func (o *options) RetentionOptions() retention.Options {
+	return o.retentionOpts
+}
+
+func (o *options) RetentionOptionsInherited() bool {
+	return o.retentionInherited
+}
+
+func (o *options) SetRollup(value *Rollup) Options {
+	opts := *o
+	opts.rollup = value
+	return &opts
+}
+
+func (o *options) Rollup() *Rollup {
+	return o.rollup
+}
+
+func (o *options) SetColdWritesEnabled(value bool) Options {
+	opts := *o
+	opts.coldWritesEnabled = value
+	return &opts
+}
+
+func (o *options) ColdWritesEnabled() bool {
+	return o.coldWritesEnabled
+}
+
+func (o *options) SetSyncCommitLogWrites(value bool) Options {
+	opts := *o
+	opts.syncCommitLogWrites = value
+	return &opts
+}
+
+func (o *options) SyncCommitLogWrites() bool {
+	return o.syncCommitLogWrites
+}
+
+const (
+	defaultRegistryKey = "m3db.node.namespaces"
+	defaultInitTimeout = 10 * time.Second
+)
+
+var (
+	errNoConfigServiceClient = errors.New("no config service client")
+	errNoRegistryKey         = errors.New("no registry key")
+)
+
+type dynamicOptions struct {
+	configServiceClient client.Client
+	registryKey         string
+	initialNamespaces   []Metadata
+	instrumentOptions   instrument.Options
+	initTimeout         time.Duration
+}
+
+// NewDynamicOptions creates a new set of dynamic registry options
+func NewDynamicOptions() DynamicOptions {
+	return &dynamicOptions{
+		registryKey:       defaultRegistryKey,
+		instrumentOptions: instrument.NewOptions(),
+		initTimeout:       defaultInitTimeout,
+	}
+}
+
+func (o *dynamicOptions) Validate() error {
+	if o.ConfigServiceClient() == nil {
+		return errNoConfigServiceClient
+	}
+	if _, err := o.ConfigServiceClient().KV(); err != nil {
+		return fmt.Errorf("no kv store client, %v", err)
+	}
+	if o.RegistryKey() == "" {
+		return errNoRegistryKey
+	}
+	return nil
+}
+
+func (o *dynamicOptions) SetConfigServiceClient(c client.Client) DynamicOptions {
+	opts := *o
+	opts.configServiceClient = c
+	return &opts
+}
+
+func (o *dynamicOptions) ConfigServiceClient() client.Client {
+	return o.configServiceClient
+}
+
+func (o *dynamicOptions) SetRegistryKey(value string) DynamicOptions {
+	opts := *o
+	opts.registryKey = value
+	return &opts
+}
+
+func (o *dynamicOptions) RegistryKey() string {
+	return o.registryKey
+}
+
+func (o *dynamicOptions) SetInitialNamespaces(value []Metadata) DynamicOptions {
+	opts := *o
+	opts.initialNamespaces = value
+	return &opts
+}
+
+func (o *dynamicOptions) InitialNamespaces() []Metadata {
+	return o.initialNamespaces
+}
+
+func (o *dynamicOptions) SetInstrumentOptions(value instrument.Options) DynamicOptions {
+	opts := *o
+	opts.instrumentOptions = value
+	return &opts
+}
+
+func (o *dynamicOptions) InstrumentOptions() instrument.Options {
+	return o.instrumentOptions
+}
+
+func (o *dynamicOptions) SetInitTimeout(value time.Duration) DynamicOptions {
+	opts := *o
+	opts.initTimeout = value
+	return &opts
+}
+
+func (o *dynamicOptions) InitTimeout() time.Duration {
+	return o.initTimeout
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/watch"
+)
+
+var (
+	errRegistryClosed     = errors.New("namespace registry is closed")
+	errDuplicateNamespace = errors.New("namespace map contains duplicate namespaces")
+	errColdWritesNoFlush  = errors.New("namespace with cold writes enabled must flush in-memory data")
+	errSyncNoCommitLog    = errors.New("namespace with synced commit log writes must write to the commit log")
+)
+
+type nsMap struct {
+	namespaces map[ts.Hash]Metadata
+	ids        []ts.ID
+	metadatas  []Metadata
+}
+
+// NewMap creates a new namespace map from a list of namespaces
+func NewMap(metadatas []Metadata) (Map, error) {
+	m := &nsMap{
+		namespaces: make(map[ts.Hash]Metadata, len(metadatas)),
+		ids:        make([]ts.ID, 0, len(metadatas)),
+		metadatas:  make([]Metadata, 0, len(metadatas)),
+	}
+	for _, md := range metadatas {
+		hash := md.ID().Hash()
+		if _, exists := m.namespaces[hash]; exists {
+			return nil, errDuplicateNamespace
+		}
+		m.namespaces[hash] = md
+		m.ids = append(m.ids, md.ID())
+		m.metadatas = append(m.metadatas, md)
+	}
+	for _, md := range m.metadatas {
+		if err := validateRollup(md, m); err != nil {
+			return nil, err
+		}
+		if md.Options().ColdWritesEnabled() && !md.Options().NeedsFlush() {
+			// Cold writes are only persisted by merging them into the flushed filesets
+			return nil, errColdWritesNoFlush
+		}
+		if md.Options().SyncCommitLogWrites() && !md.Options().WritesToCommitLog() {
+			return nil, errSyncNoCommitLog
+		}
+	}
+	return m, nil
+}
+
+func (m *nsMap) Get(id ts.ID) (Metadata, bool) {
+	md, ok := m.namespaces[id.Hash()]
+	return md, ok
+}
+
+func (m *nsMap) IDs() []ts.ID {
+	return m.ids
+}
+
+func (m *nsMap) Metadatas() []Metadata {
+	return m.metadatas
+}
+
+type mapWatch struct {
+	xwatch.Watch
+}
+
+// NewWatch creates a new watch on a namespace map
+// from a generic watch that watches a Map
+func NewWatch(w xwatch.Watch) Watch {
+	return &mapWatch{w}
+}
+
+func (w *mapWatch) C() <-chan struct{} {
+	return w.Watch.C()
+}
+
+func (w *mapWatch) Get() Map {
+	value := w.Watch.Get()
+	if value == nil {
+		return nil
+	}
+	return value.(Map)
+}
+
+func (w *mapWatch) Close() {
+	w.Watch.Close()
+}
+
+type staticInitializer struct {
+	metadatas []Metadata
+}
+
+// NewStaticInitializer creates a new static registry initializer,
+// namespaces added or removed at runtime are held only in memory
+func NewStaticInitializer(metadatas []Metadata) Initializer {
+	return staticInitializer{metadatas: metadatas}
+}
+
+func (i staticInitializer) Init() (Registry, error) {
+	m, err := NewMap(i.metadatas)
+	if err != nil {
+		return nil, err
+	}
+	watchable := xwatch.NewWatchable()
+	watchable.Update(m)
+	return &staticRegistry{watchable: watchable}, nil
+}
+
+type staticRegistry struct {
+	sync.Mutex
+	watchable xwatch.Watchable
+	closed    bool
+}
+
+func (r *staticRegistry) Get() Map {
+	return r.watchable.Get().(Map)
+}
+
+func (r *staticRegistry) Watch() (Watch, error) {
+	_, w, err := r.watchable.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return NewWatch(w), err
+}
+
+func (r *staticRegistry) Add(md Metadata) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closed {
+		return errRegistryClosed
+	}
+
+	current := r.Get()
+	if _, exists := current.Get(md.ID()); exists {
+		return fmt.Errorf("namespace %s already exists", md.ID().String())
+	}
+	m, err := NewMap(append(append([]Metadata(nil), current.Metadatas()...), md))
+	if err != nil {
+		return err
+	}
+	r.watchable.Update(m)
+	return nil
+}
+
+func (r *staticRegistry) Remove(id ts.ID) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closed {
+		return errRegistryClosed
+	}
+
+	m, err := withoutNamespace(r.Get(), id)
+	if err != nil {
+		return err
+	}
+	r.watchable.Update(m)
+	return nil
+}
+
+func (r *staticRegistry) Close() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closed {
+		return
+	}
+
+	r.closed = true
+	r.watchable.Close()
+}
+
+func withoutNamespace(m Map, id ts.ID) (Map, error) {
+	if _, exists := m.Get(id); !exists {
+		return nil, fmt.Errorf("no such namespace %s", id.String())
+	}
+	metadatas := make([]Metadata, 0, len(m.Metadatas()))
+	for _, md := range m.Metadatas() {
+		if md.ID().Equal(id) {
+			continue
+		}
+		metadatas = append(metadatas, md)
+	}
+	return NewMap(metadatas)
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/ts"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testMetadatas() []Metadata {
+	ropts := retention.NewOptions().
+		SetRetentionPeriod(24 * time.Hour).
+		SetBlockSize(time.Hour)
+	return []Metadata{
+		NewMetadata(ts.StringID("foo"), NewOptions().SetRetentionOptions(ropts).SetSyncCommitLogWrites(true)),
+		NewMetadata(ts.StringID("bar"), NewOptions().SetNeedsRepair(false).SetColdWritesEnabled(true)),
+	}
+}
+
+func TestNewMapDuplicateNamespaces(t *testing.T) {
+	mds := testMetadatas()
+	_, err := NewMap(append(mds, mds[0]))
+	require.Equal(t, errDuplicateNamespace, err)
+}
+
+func TestNewMapColdWritesWithoutFlush(t *testing.T) {
+	md := NewMetadata(ts.StringID("baz"), NewOptions().
+		SetNeedsFlush(false).
+		SetColdWritesEnabled(true))
+	_, err := NewMap(append(testMetadatas(), md))
+	require.Equal(t, errColdWritesNoFlush, err)
+}
+
+func TestNewMapSyncCommitLogWritesWithoutCommitLog(t *testing.T) {
+	md := NewMetadata(ts.StringID("baz"), NewOptions().
+		SetWritesToCommitLog(false).
+		SetSyncCommitLogWrites(true))
+	_, err := NewMap(append(testMetadatas(), md))
+	require.Equal(t, errSyncNoCommitLog, err)
+}
+
+func TestStaticRegistryAddRemove(t *testing.T) {
+	reg, err := NewStaticInitializer(testMetadatas()).Init()
+	require.NoError(t, err)
+	defer reg.Close()
+
+	w, err := reg.Watch()
+	require.NoError(t, err)
+	defer w.Close()
+
+	<-w.C()
+	require.Equal(t, 2, len(w.Get().IDs()))
+
+	baz := NewMetadata(ts.StringID("baz"), NewOptions())
+	require.NoError(t, reg.Add(baz))
+	require.Error(t, reg.Add(baz))
+
+	<-w.C()
+	_, ok := w.Get().Get(baz.ID())
+	require.True(t, ok)
+	require.Equal(t, 3, len(w.Get().Metadatas()))
+
+	require.NoError(t, reg.Remove(ts.StringID("foo")))
+	require.Error(t, reg.Remove(ts.StringID("foo")))
+
+	<-w.C()
+	_, ok = w.Get().Get(ts.StringID("foo"))
+	require.False(t, ok)
+	require.Equal(t, 2, len(reg.Get().Metadatas()))
+
+	reg.Close()
+	require.Equal(t, errRegistryClosed, reg.Add(baz))
+}
+
+func TestConvertProtoRoundTrip(t *testing.T) {
+	m, err := NewMap(testMetadatas())
+	require.NoError(t, err)
+
+	converted, err := fromProto(toProto(m))
+	require.NoError(t, err)
+	require.Equal(t, len(m.Metadatas()), len(converted.Metadatas()))
+
+	for _, expected := range m.Metadatas() {
+		actual, ok := converted.Get(expected.ID())
+		require.True(t, ok)
+		eopts, aopts := expected.Options(), actual.Options()
+		require.Equal(t, eopts.NeedsBootstrap(), aopts.NeedsBootstrap())
+		require.Equal(t, eopts.NeedsFlush(), aopts.NeedsFlush())
+		require.Equal(t, eopts.WritesToCommitLog(), aopts.WritesToCommitLog())
+		require.Equal(t, eopts.NeedsFilesetCleanup(), aopts.NeedsFilesetCleanup())
+		require.Equal(t, eopts.NeedsRepair(), aopts.NeedsRepair())
+		require.Equal(t, eopts.ColdWritesEnabled(), aopts.ColdWritesEnabled())
+		require.Equal(t, eopts.SyncCommitLogWrites(), aopts.SyncCommitLogWrites())
+		require.Equal(t, eopts.RetentionOptionsInherited(), aopts.RetentionOptionsInherited())
+		require.Equal(t, eopts.RetentionOptions().RetentionPeriod(),
+			aopts.RetentionOptions().RetentionPeriod())
+		require.Equal(t, eopts.RetentionOptions().BlockSize(),
+			aopts.RetentionOptions().BlockSize())
+	}
+}
+
+func testRollupMetadata(rollup *Rollup) Metadata {
+	ropts := retention.NewOptions().
+		SetRetentionPeriod(30 * 24 * time.Hour).
+		SetBlockSize(4 * time.Hour)
+	opts := NewOptions().
+		SetNeedsFlush(false).
+		SetRetentionOptions(ropts).
+		SetRollup(rollup)
+	return NewMetadata(ts.StringID("foo_rollup"), opts)
+}
+
+func TestNewMapRollup(t *testing.T) {
+	valid := Rollup{
+		SourceNamespace: ts.StringID("foo"),
+		Resolution:      time.Minute,
+		Aggregation:     encoding.AggregationAvg,
+	}
+	_, err := NewMap(append(testMetadatas(), testRollupMetadata(&valid)))
+	require.NoError(t, err)
+
+	missingSource := valid
+	missingSource.SourceNamespace = ts.StringID("baz")
+	badResolution := valid
+	badResolution.Resolution = 7 * time.Minute
+	noAggregation := valid
+	noAggregation.Aggregation = encoding.AggregationNone
+
+	for _, rollup := range []Rollup{missingSource, badResolution, noAggregation} {
+		rollup := rollup
+		_, err := NewMap(append(testMetadatas(), testRollupMetadata(&rollup)))
+		require.Error(t, err)
+	}
+
+	flushed := testRollupMetadata(&valid)
+	flushed = NewMetadata(flushed.ID(), flushed.Options().SetNeedsFlush(true))
+	_, err = NewMap(append(testMetadatas(), flushed))
+	require.Error(t, err)
+}
+
+func TestConvertProtoRoundTripRollup(t *testing.T) {
+	rollup := &Rollup{
+		SourceNamespace: ts.StringID("foo"),
+		Resolution:      time.Minute,
+		Aggregation:     encoding.AggregationMax,
+	}
+	m, err := NewMap(append(testMetadatas(), testRollupMetadata(rollup)))
+	require.NoError(t, err)
+
+	converted, err := fromProto(toProto(m))
+	require.NoError(t, err)
+
+	md, ok := converted.Get(ts.StringID("foo_rollup"))
+	require.True(t, ok)
+	actual := md.Options().Rollup()
+	require.NotNil(t, actual)
+	require.True(t, rollup.SourceNamespace.Equal(actual.SourceNamespace))
+	require.Equal(t, rollup.Resolution, actual.Resolution)
+	require.Equal(t, rollup.Aggregation, actual.Aggregation)
+
+	md, ok = converted.Get(ts.StringID("foo"))
+	require.True(t, ok)
+	require.Nil(t, md.Options().Rollup())
+}
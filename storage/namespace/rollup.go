@@ -0,0 +1,79 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package namespace
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/m3db/m3db/encoding"
+)
+
+var (
+	errRollupSourceNotFound    = errors.New("rollup source namespace does not exist")
+	errRollupSourceIsRollup    = errors.New("rollup source namespace is itself a rollup")
+	errRollupInvalidResolution = errors.New("rollup resolution must be positive and divide the source block size")
+	errRollupInvalidBlockSize  = errors.New("rollup block size must be a multiple of the source block size")
+	errRollupNoAggregation     = errors.New("rollup must specify an aggregation")
+	errRollupNeedsFlush        = errors.New("rollup namespace must not flush in-memory data")
+)
+
+// validateRollup validates the rollup of a namespace against its source
+// namespace in the namespace map.
+func validateRollup(md Metadata, m Map) error {
+	rollup := md.Options().Rollup()
+	if rollup == nil {
+		return nil
+	}
+	if err := rollup.validate(md, m); err != nil {
+		return fmt.Errorf("namespace %s has invalid rollup: %v", md.ID().String(), err)
+	}
+	return nil
+}
+
+func (r *Rollup) validate(md Metadata, m Map) error {
+	if r.SourceNamespace == nil || r.SourceNamespace.Equal(md.ID()) {
+		return errRollupSourceNotFound
+	}
+	source, ok := m.Get(r.SourceNamespace)
+	if !ok {
+		return errRollupSourceNotFound
+	}
+	if source.Options().Rollup() != nil {
+		return errRollupSourceIsRollup
+	}
+	sourceBlockSize := source.Options().RetentionOptions().BlockSize()
+	if r.Resolution <= 0 || sourceBlockSize%r.Resolution != 0 {
+		return errRollupInvalidResolution
+	}
+	if md.Options().RetentionOptions().BlockSize()%sourceBlockSize != 0 {
+		return errRollupInvalidBlockSize
+	}
+	if r.Aggregation == encoding.AggregationNone {
+		return errRollupNoAggregation
+	}
+	if md.Options().NeedsFlush() {
+		// The flush of the in-memory data of the namespace would overwrite
+		// the filesets written by the rollup
+		return errRollupNeedsFlush
+	}
+	return nil
+}
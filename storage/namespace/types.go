@@ -20,7 +20,15 @@
 
 package namespace
 
-import "github.com/m3db/m3db/ts"
+import (
+	"time"
+
+	"github.com/m3db/m3cluster/client"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/instrument"
+)
 
 // Options controls namespace behavior
 type Options interface {
@@ -53,6 +61,48 @@ type Options interface {
 
 	// NeedsRepair returns whether the data for this namespace needs to be repaired
 	NeedsRepair() bool
+
+	// SetRetentionOptions sets the retention options for this namespace
+	SetRetentionOptions(value retention.Options) Options
+
+	// RetentionOptions returns the retention options for this namespace
+	RetentionOptions() retention.Options
+
+	// RetentionOptionsInherited returns whether the retention options were not set
+	// for this namespace, the namespace then uses those of the database it is added to
+	RetentionOptionsInherited() bool
+
+	// SetRollup sets the source namespace this namespace is a rollup of, nil if none
+	SetRollup(value *Rollup) Options
+
+	// Rollup returns the source namespace this namespace is a rollup of, nil if none
+	Rollup() *Rollup
+
+	// SetColdWritesEnabled sets whether writes outside the buffer window are accepted
+	SetColdWritesEnabled(value bool) Options
+
+	// ColdWritesEnabled returns whether writes outside the buffer window are accepted
+	ColdWritesEnabled() bool
+
+	// SetSyncCommitLogWrites sets whether writes are acknowledged only once fsynced to the commit log
+	SetSyncCommitLogWrites(value bool) Options
+
+	// SyncCommitLogWrites returns whether writes are acknowledged only once fsynced to the commit log
+	SyncCommitLogWrites() bool
+}
+
+// Rollup declares a namespace as a lower resolution rollup of a source
+// namespace, flushed blocks of the source namespace are aggregated in the
+// background and written to the filesets of the rollup namespace.
+type Rollup struct {
+	// SourceNamespace is the ID of the namespace to roll up
+	SourceNamespace ts.ID
+
+	// Resolution is the resolution of the rolled up datapoints
+	Resolution time.Duration
+
+	// Aggregation is the aggregation applied to datapoints within each resolution step
+	Aggregation encoding.AggregationType
 }
 
 // Metadata represents namespace metadata information
@@ -63,3 +113,87 @@ type Metadata interface {
 	// Options is the namespace options
 	Options() Options
 }
+
+// Map is a snapshot of the namespaces held by a registry
+type Map interface {
+	// Get returns the metadata for a namespace and whether it exists
+	Get(id ts.ID) (Metadata, bool)
+
+	// IDs returns the IDs of the namespaces
+	IDs() []ts.ID
+
+	// Metadatas returns the metadata of the namespaces
+	Metadatas() []Metadata
+}
+
+// Watch is a watch on a namespace map
+type Watch interface {
+	// C is the notification channel for when a value becomes available
+	C() <-chan struct{}
+
+	// Get the current namespace map
+	Get() Map
+
+	// Close the watch on the namespace map
+	Close()
+}
+
+// Registry is a container of a namespace map and disseminates namespace map changes
+type Registry interface {
+	// Get the namespace map
+	Get() Map
+
+	// Watch for the namespace map
+	Watch() (Watch, error)
+
+	// Add adds a namespace to the registry
+	Add(md Metadata) error
+
+	// Remove removes a namespace from the registry
+	Remove(id ts.ID) error
+
+	// Close will close the registry
+	Close()
+}
+
+// Initializer can init new registries
+type Initializer interface {
+	// Init will return a new registry
+	Init() (Registry, error)
+}
+
+// DynamicOptions is a set of options for a dynamic registry
+type DynamicOptions interface {
+	// Validate validates the options
+	Validate() error
+
+	// SetConfigServiceClient sets the client of ConfigService
+	SetConfigServiceClient(c client.Client) DynamicOptions
+
+	// ConfigServiceClient returns the client of ConfigService
+	ConfigServiceClient() client.Client
+
+	// SetRegistryKey sets the kv key of the namespace registry
+	SetRegistryKey(value string) DynamicOptions
+
+	// RegistryKey returns the kv key of the namespace registry
+	RegistryKey() string
+
+	// SetInitialNamespaces sets the namespaces to seed an empty registry with
+	SetInitialNamespaces(value []Metadata) DynamicOptions
+
+	// InitialNamespaces returns the namespaces to seed an empty registry with
+	InitialNamespaces() []Metadata
+
+	// SetInstrumentOptions sets the instrumentation options
+	SetInstrumentOptions(value instrument.Options) DynamicOptions
+
+	// InstrumentOptions returns the instrumentation options
+	InstrumentOptions() instrument.Options
+
+	// SetInitTimeout sets the waiting time for the dynamic registry to be initialized
+	SetInitTimeout(value time.Duration) DynamicOptions
+
+	// InitTimeout returns the waiting time for the dynamic registry to be initialized
+	InitTimeout() time.Duration
+}
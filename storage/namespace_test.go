@@ -23,17 +23,23 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/m3db/m3cluster/shard"
 	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/fs/commitlog"
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/index"
 	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/ts"
@@ -52,7 +58,7 @@ var testNamespaceID = ts.StringID("testNs")
 var testShardIDs = sharding.NewShards([]uint32{0, 1}, shard.Available)
 
 func newTestNamespace(t *testing.T) *dbNamespace {
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return testShardIDs[0].ID() }
 	shardSet, err := sharding.NewShardSet(testShardIDs, hashFn)
 	require.NoError(t, err)
@@ -117,6 +123,37 @@ func TestNamespaceWriteShardOwned(t *testing.T) {
 	require.NoError(t, ns.Write(ctx, id, ts, val, unit, ant))
 }
 
+func TestNamespaceWriteTaggedQueryIDs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	id := ts.StringID("foo")
+	tags := ts.Tags{{Name: ts.StringID("city"), Value: ts.StringID("nyc")}}
+	now := time.Now()
+
+	ns := newTestNamespace(t)
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().WriteTagged(ctx, id, tags, now, 1.0, xtime.Second, nil).Return(nil)
+	ns.shards[testShardIDs[0].ID()] = shard
+
+	require.NoError(t, ns.WriteTagged(ctx, id, tags, now, 1.0, xtime.Second, nil))
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchEqual, Name: "city", Value: "nyc"},
+	}}
+	res, err := ns.QueryIDs(ctx, query, index.QueryOptions{
+		StartInclusive: now.Add(-time.Minute),
+		EndExclusive:   now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.True(t, res.Exhaustive)
+	require.Equal(t, 1, len(res.Results))
+	require.True(t, id.Equal(res.Results[0].ID))
+}
+
 func TestNamespaceReadEncodedShardNotOwned(t *testing.T) {
 	ctx := context.NewContext()
 	defer ctx.Close()
@@ -288,7 +325,7 @@ func TestNamespaceBootstrapAllShards(t *testing.T) {
 	errs := []error{nil, errors.New("foo")}
 	bs := bootstrap.NewMockProcess(ctrl)
 	bs.EXPECT().
-		Run(ns.ID(), sharding.IDs(testShardIDs), ranges).
+		Run(ns.metadata, sharding.IDs(testShardIDs), ranges).
 		Return(result.NewBootstrapResult(), nil)
 	for i := range errs {
 		shard := NewMockdatabaseShard(ctrl)
@@ -333,7 +370,7 @@ func TestNamespaceBootstrapOnlyNonBootstrappedShards(t *testing.T) {
 	ns := newTestNamespace(t)
 	bs := bootstrap.NewMockProcess(ctrl)
 	bs.EXPECT().
-		Run(ns.ID(), sharding.IDs(needsBootstrap), ranges).
+		Run(ns.metadata, sharding.IDs(needsBootstrap), ranges).
 		Return(result.NewBootstrapResult(), nil)
 
 	for _, testShard := range needsBootstrap {
@@ -389,6 +426,166 @@ func TestNamespaceFlushAllShards(t *testing.T) {
 	require.Error(t, ns.Flush(blockStart, nil))
 }
 
+func TestNamespaceColdFlushColdWritesDisabled(t *testing.T) {
+	ns := newTestNamespace(t)
+	ns.bs = bootstrapped
+	require.NoError(t, ns.ColdFlush(nil))
+}
+
+func TestNamespaceColdFlushAllShards(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns := newTestNamespace(t)
+	ns.bs = bootstrapped
+	ns.nopts = ns.nopts.SetColdWritesEnabled(true)
+	errs := []error{nil, errors.New("foo")}
+	for i := range errs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().ColdFlush(ts.NewIDMatcher(testNamespaceID.String()), nil).Return(errs[i])
+		if errs[i] != nil {
+			shard.EXPECT().ID().Return(testShardIDs[i].ID())
+		}
+		ns.shards[testShardIDs[i].ID()] = shard
+	}
+
+	require.Error(t, ns.ColdFlush(nil))
+}
+
+func TestNamespaceReplayColdWritesOnlyFlushedBlocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dir, err := ioutil.TempDir("", "testdir")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ns := newTestNamespace(t)
+	ns.nopts = ns.nopts.SetColdWritesEnabled(true)
+	clOpts := ns.opts.CommitLogOptions()
+	ns.opts = ns.opts.SetCommitLogOptions(clOpts.SetFilesystemOptions(
+		clOpts.FilesystemOptions().SetFilePathPrefix(dir)))
+
+	// Only the first block has been flushed to a fileset
+	blockSize := ns.nopts.RetentionOptions().BlockSize()
+	flushedStart := time.Now().Add(-10 * blockSize).Truncate(blockSize)
+	unflushedStart := flushedStart.Add(blockSize)
+	shardDir := fs.ShardDirPath(dir, testNamespaceID, 0)
+	require.NoError(t, os.MkdirAll(shardDir, os.ModePerm))
+	checkpointFile := path.Join(shardDir, fmt.Sprintf("fileset-%d-checkpoint.db", flushedStart.UnixNano()))
+	require.NoError(t, ioutil.WriteFile(checkpointFile, nil, os.ModePerm))
+
+	entry := commitlog.Series{Namespace: testNamespaceID, ID: ts.StringID("foo"), Shard: 0}
+	iter := commitlog.NewMockIterator(ctrl)
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(entry, ts.Datapoint{Timestamp: flushedStart, Value: 1}, xtime.Second, nil),
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(entry, ts.Datapoint{Timestamp: unflushedStart, Value: 2}, xtime.Second, nil),
+		iter.EXPECT().Next().Return(false),
+		iter.EXPECT().Err().Return(nil),
+		iter.EXPECT().Close(),
+	)
+	ns.newCommitLogIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		seriesFilter commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
+		require.True(t, seriesFilter(testNamespaceID, 0))
+		require.False(t, seriesFilter(testNamespaceID, 1))
+		return iter, nil
+	}
+
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+	shard.EXPECT().Write(gomock.Any(), entry.ID, flushedStart, 1.0, xtime.Second, gomock.Any()).Return(nil)
+
+	require.NoError(t, ns.replayCommitLog([]databaseShard{shard}))
+}
+
+func TestNamespaceReplayCommitLogReindexesTaggedSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	ns := newTestNamespace(t)
+	now := time.Now()
+	tagged := commitlog.Series{
+		Namespace: testNamespaceID,
+		ID:        ts.StringID("foo"),
+		Shard:     0,
+		Tags:      ts.Tags{{Name: ts.StringID("city"), Value: ts.StringID("nyc")}},
+	}
+	untagged := commitlog.Series{Namespace: testNamespaceID, ID: ts.StringID("bar"), Shard: 0}
+	iter := commitlog.NewMockIterator(ctrl)
+	gomock.InOrder(
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(tagged, ts.Datapoint{Timestamp: now, Value: 1}, xtime.Second, nil),
+		iter.EXPECT().Next().Return(true),
+		iter.EXPECT().Current().Return(untagged, ts.Datapoint{Timestamp: now, Value: 2}, xtime.Second, nil),
+		iter.EXPECT().Next().Return(false),
+		iter.EXPECT().Err().Return(nil),
+		iter.EXPECT().Close(),
+	)
+	ns.newCommitLogIteratorFn = func(
+		_ commitlog.Options,
+		_ commitlog.FileFilterPredicate,
+		_ commitlog.SeriesFilterPredicate,
+	) (commitlog.Iterator, error) {
+		return iter, nil
+	}
+
+	// Cold writes are disabled so no writes are replayed into the shard
+	shard := NewMockdatabaseShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+
+	require.NoError(t, ns.replayCommitLog([]databaseShard{shard}))
+
+	query := index.Query{Matchers: []index.Matcher{
+		{Type: index.MatchEqual, Name: "city", Value: "nyc"},
+	}}
+	res, err := ns.QueryIDs(ctx, query, index.QueryOptions{
+		StartInclusive: now.Add(-time.Minute),
+		EndExclusive:   now.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res.Results))
+	require.True(t, tagged.ID.Equal(res.Results[0].ID))
+}
+
+func TestNamespaceSnapshotDoesNotWriteToCommitLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns := newTestNamespace(t)
+	ns.bs = bootstrapped
+	ns.nopts = ns.nopts.SetWritesToCommitLog(false)
+	for _, id := range testShardIDs {
+		ns.shards[id.ID()] = NewMockdatabaseShard(ctrl)
+	}
+
+	require.NoError(t, ns.Snapshot("foo", time.Now()))
+}
+
+func TestNamespaceSnapshotNotFlushedWritesToCommitLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ns := newTestNamespace(t)
+	ns.bs = bootstrapped
+	ns.nopts = ns.nopts.SetNeedsFlush(false).SetWritesToCommitLog(true)
+	for _, id := range testShardIDs {
+		shard := NewMockdatabaseShard(ctrl)
+		shard.EXPECT().Snapshot(ts.NewIDMatcher(testNamespaceID.String()), gomock.Any(), "foo").
+			Return(nil).MinTimes(1)
+		ns.shards[id.ID()] = shard
+	}
+
+	require.NoError(t, ns.Snapshot("foo", time.Now()))
+}
+
 func TestNamespaceCleanupFilesetDontNeedCleanup(t *testing.T) {
 	ns := newTestNamespace(t)
 	ns.nopts = ns.nopts.SetNeedsFilesetCleanup(false)
@@ -460,7 +657,7 @@ func TestNamespaceRepair(t *testing.T) {
 			}
 		}
 		shard.EXPECT().
-			Repair(gomock.Any(), ts.NewIDMatcher(testNamespaceID.String()), repairTimeRange, repairer).
+			Repair(gomock.Any(), ns.metadata, repairTimeRange, repairer).
 			Return(res, errs[i])
 		ns.shards[testShardIDs[i].ID()] = shard
 	}
@@ -505,7 +702,7 @@ func TestNamespaceAssignShardSet(t *testing.T) {
 	closingErrors := shard.NewShards([]shard.Shard{shards[3]})
 	adding := shard.NewShards([]shard.Shard{shards[4]})
 
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return shards[0].ID() }
 	shardSet, err := sharding.NewShardSet(prevAssignment.All(), hashFn)
 	require.NoError(t, err)
@@ -571,7 +768,7 @@ func TestNamespaceNeedsFlushAllSuccess(t *testing.T) {
 
 	shards := sharding.NewShards([]uint32{0, 2, 4}, shard.Available)
 
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return shards[0].ID() }
 	shardSet, err := sharding.NewShardSet(shards, hashFn)
 	require.NoError(t, err)
@@ -605,7 +802,7 @@ func TestNamespaceNeedsFlushCountsLeastNumFailures(t *testing.T) {
 
 	shards := sharding.NewShards([]uint32{0, 2, 4}, shard.Available)
 
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return shards[0].ID() }
 	shardSet, err := sharding.NewShardSet(shards, hashFn)
 	require.NoError(t, err)
@@ -655,7 +852,7 @@ func TestNamespaceNeedsFlushAnyNotStarted(t *testing.T) {
 
 	shards := sharding.NewShards([]uint32{0, 2, 4}, shard.Available)
 
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return shards[0].ID() }
 	shardSet, err := sharding.NewShardSet(shards, hashFn)
 	require.NoError(t, err)
@@ -700,7 +897,7 @@ func TestNamespaceNeedsFlushInProgress(t *testing.T) {
 
 	shards := sharding.NewShards([]uint32{0, 2, 4}, shard.Available)
 
-	metadata := namespace.NewMetadata(testNamespaceID, namespace.NewOptions())
+	metadata := namespace.NewMetadata(testNamespaceID, testNamespaceOptions())
 	hashFn := func(identifier ts.ID) uint32 { return shards[0].ID() }
 	shardSet, err := sharding.NewShardSet(shards, hashFn)
 	require.NoError(t, err)
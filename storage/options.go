@@ -35,6 +35,7 @@ import (
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/storage/series"
 	"github.com/m3db/m3db/ts"
@@ -106,6 +107,7 @@ type options struct {
 	persistManager                 persist.Manager
 	maxFlushRetries                int
 	blockRetrieverManager          block.DatabaseBlockRetrieverManager
+	namespaceRegistry              namespace.Registry
 	contextPool                    context.Pool
 	seriesPool                     series.DatabaseSeriesPool
 	bytesPool                      pool.CheckedBytesPool
@@ -403,6 +405,16 @@ func (o *options) DatabaseBlockRetrieverManager() block.DatabaseBlockRetrieverMa
 	return o.blockRetrieverManager
 }
 
+func (o *options) SetNamespaceRegistry(value namespace.Registry) Options {
+	opts := *o
+	opts.namespaceRegistry = value
+	return &opts
+}
+
+func (o *options) NamespaceRegistry() namespace.Registry {
+	return o.namespaceRegistry
+}
+
 func (o *options) SetContextPool(value context.Pool) Options {
 	opts := *o
 	opts.contextPool = value
@@ -34,9 +34,11 @@ import (
 	"github.com/m3db/m3db/client"
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/context"
-	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
+	"github.com/m3db/m3db/topology"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/errors"
 	"github.com/m3db/m3x/log"
@@ -53,15 +55,14 @@ var (
 type recordFn func(namespace ts.ID, shard databaseShard, diffRes repair.MetadataComparisonResult)
 
 type shardRepairer struct {
-	opts      Options
-	rpopts    repair.Options
-	rtopts    retention.Options
-	client    client.AdminClient
-	recordFn  recordFn
-	logger    xlog.Logger
-	scope     tally.Scope
-	nowFn     clock.NowFn
-	blockSize time.Duration
+	opts     Options
+	rpopts   repair.Options
+	resOpts  result.Options
+	client   client.AdminClient
+	recordFn recordFn
+	logger   xlog.Logger
+	scope    tally.Scope
+	nowFn    clock.NowFn
 }
 
 func newShardRepairer(opts Options, rpopts repair.Options) (databaseShardRepairer, error) {
@@ -72,17 +73,19 @@ func newShardRepairer(opts Options, rpopts repair.Options) (databaseShardRepaire
 
 	iopts := opts.InstrumentOptions()
 	scope := iopts.MetricsScope().SubScope("database.repair").Tagged(map[string]string{"host": hostname})
-	rtopts := opts.RetentionOptions()
+	resOpts := result.NewOptions().
+		SetClockOptions(opts.ClockOptions()).
+		SetInstrumentOptions(iopts).
+		SetDatabaseBlockOptions(opts.DatabaseBlockOptions())
 
 	r := shardRepairer{
-		opts:      opts,
-		rpopts:    rpopts,
-		rtopts:    rtopts,
-		client:    rpopts.AdminClient(),
-		logger:    iopts.Logger(),
-		scope:     scope,
-		nowFn:     opts.ClockOptions().NowFn(),
-		blockSize: rtopts.BlockSize(),
+		opts:    opts,
+		rpopts:  rpopts,
+		resOpts: resOpts,
+		client:  rpopts.AdminClient(),
+		logger:  iopts.Logger(),
+		scope:   scope,
+		nowFn:   opts.ClockOptions().NowFn(),
 	}
 	r.recordFn = r.recordDifferences
 
@@ -95,7 +98,7 @@ func (r shardRepairer) Options() repair.Options {
 
 func (r shardRepairer) Repair(
 	ctx context.Context,
-	namespace ts.ID,
+	nsMeta namespace.Metadata,
 	tr xtime.Range,
 	shard databaseShard,
 ) (repair.MetadataComparisonResult, error) {
@@ -105,6 +108,7 @@ func (r shardRepairer) Repair(
 	}
 
 	var (
+		nsID     = nsMeta.ID()
 		start    = tr.Start
 		end      = tr.End
 		origin   = session.Origin()
@@ -126,7 +130,7 @@ func (r shardRepairer) Repair(
 	metadata.AddLocalMetadata(origin, localIter)
 
 	// Add peer metadata
-	peerIter, err := session.FetchBlocksMetadataFromPeers(namespace, shard.ID(), start, end)
+	peerIter, err := session.FetchBlocksMetadataFromPeers(nsID, shard.ID(), start, end)
 	if err != nil {
 		return repair.MetadataComparisonResult{}, err
 	}
@@ -136,11 +140,131 @@ func (r shardRepairer) Repair(
 
 	metadataRes := metadata.Compare()
 
-	r.recordFn(namespace, shard, metadataRes)
+	r.recordFn(nsID, shard, metadataRes)
+
+	if err := r.repairDifferences(session, origin, nsMeta, shard, metadataRes); err != nil {
+		return metadataRes, err
+	}
 
 	return metadataRes, nil
 }
 
+// repairDifferences fetches the blocks that differ between the local host and its
+// peers and loads them into the shard, the shard merges them with any existing
+// data and marks the affected filesets to be flushed again
+func (r shardRepairer) repairDifferences(
+	session client.AdminSession,
+	origin topology.Host,
+	nsMeta namespace.Metadata,
+	shard databaseShard,
+	diffRes repair.MetadataComparisonResult,
+) error {
+	metadatas := r.peerBlocksToFetch(origin, diffRes)
+	if len(metadatas) == 0 {
+		return nil
+	}
+
+	var (
+		nsID    = nsMeta.ID()
+		resOpts = r.resOpts.SetRetentionOptions(nsMeta.Options().RetentionOptions())
+	)
+	blocksIter, err := session.FetchBlocksFromPeers(nsID, shard.ID(), metadatas, resOpts)
+	if err != nil {
+		return err
+	}
+
+	var (
+		blockOpts = r.opts.DatabaseBlockOptions()
+		series    = make(map[ts.Hash]result.DatabaseSeriesBlocks)
+		numBlocks int64
+	)
+	for blocksIter.Next() {
+		_, id, bl := blocksIter.Current()
+		idHash := id.Hash()
+		entry, exists := series[idHash]
+		if !exists {
+			entry = result.DatabaseSeriesBlocks{
+				ID:     id,
+				Blocks: block.NewDatabaseSeriesBlocks(0, blockOpts),
+			}
+			series[idHash] = entry
+		}
+		// Multiple peers can return a replica of the same block, merge them
+		// so the block loaded into the shard contains the union of the data
+		if existing, ok := entry.Blocks.BlockAt(bl.StartTime()); ok {
+			bl.Merge(existing)
+		}
+		entry.Blocks.AddBlock(bl)
+		numBlocks++
+	}
+	if err := blocksIter.Err(); err != nil {
+		for _, entry := range series {
+			entry.Blocks.Close()
+		}
+		return err
+	}
+
+	if err := shard.Load(series); err != nil {
+		return err
+	}
+
+	repairedScope := r.scope.Tagged(map[string]string{
+		"namespace": nsID.String(),
+		"shard":     strconv.Itoa(int(shard.ID())),
+	})
+	repairedScope.Counter("repaired-series").Inc(int64(len(series)))
+	repairedScope.Counter("repaired-blocks").Inc(numBlocks)
+
+	return nil
+}
+
+// peerBlocksToFetch returns the peer replicas of all blocks that have either a
+// size or a checksum difference between the local host and its peers
+func (r shardRepairer) peerBlocksToFetch(
+	origin topology.Host,
+	diffRes repair.MetadataComparisonResult,
+) []block.ReplicaMetadata {
+	type replicaKey struct {
+		idHash ts.Hash
+		start  time.Time
+		host   string
+	}
+
+	var (
+		metadatas []block.ReplicaMetadata
+		seen      = make(map[replicaKey]struct{})
+	)
+	for _, diffs := range []repair.ReplicaSeriesMetadata{
+		diffRes.SizeDifferences,
+		diffRes.ChecksumDifferences,
+	} {
+		for idHash, series := range diffs.Series() {
+			for start, blockMetadata := range series.Metadata.Blocks() {
+				for _, hm := range blockMetadata.Metadata() {
+					if hm.Host.ID() == origin.ID() || hm.Size == 0 {
+						continue
+					}
+					key := replicaKey{idHash: idHash, start: start, host: hm.Host.ID()}
+					if _, ok := seen[key]; ok {
+						continue
+					}
+					seen[key] = struct{}{}
+					metadatas = append(metadatas, block.ReplicaMetadata{
+						Metadata: block.Metadata{
+							Start:    start,
+							Size:     hm.Size,
+							Checksum: hm.Checksum,
+						},
+						ID:   series.ID,
+						Host: hm.Host,
+					})
+				}
+			}
+		}
+	}
+	return metadatas
+}
+
 func (r shardRepairer) recordDifferences(
 	namespace ts.ID,
 	shard databaseShard,
@@ -186,14 +310,15 @@ type repairState struct {
 	NumFailures int
 }
 
+type namespaceRepairStates map[time.Time]repairState
+
 type dbRepairer struct {
 	sync.Mutex
 
-	database      database
-	ropts         repair.Options
-	rtopts        retention.Options
-	shardRepairer databaseShardRepairer
-	repairStates  map[time.Time]repairState
+	database         database
+	ropts            repair.Options
+	shardRepairer    databaseShardRepairer
+	repairStatesByNs map[ts.Hash]namespaceRepairStates
 
 	repairFn            repairFn
 	sleepFn             sleepFn
@@ -234,9 +359,8 @@ func newDatabaseRepairer(database database, opts Options) (databaseRepairer, err
 	r := &dbRepairer{
 		database:            database,
 		ropts:               ropts,
-		rtopts:              opts.RetentionOptions(),
 		shardRepairer:       shardRepairer,
-		repairStates:        make(map[time.Time]repairState),
+		repairStatesByNs:    make(map[ts.Hash]namespaceRepairStates),
 		sleepFn:             time.Sleep,
 		nowFn:               nowFn,
 		logger:              opts.InstrumentOptions().Logger(),
@@ -287,17 +411,18 @@ func (r *dbRepairer) run() {
 	}
 }
 
-func (r *dbRepairer) repairTimeRanges() xtime.Ranges {
+func (r *dbRepairer) namespaceRepairTimeRanges(ns databaseNamespace) xtime.Ranges {
 	var (
+		rtopts    = ns.Options().RetentionOptions()
 		now       = r.nowFn()
-		blockSize = r.rtopts.BlockSize()
-		start     = now.Add(-r.rtopts.RetentionPeriod()).Truncate(blockSize)
-		end       = now.Add(-r.rtopts.BufferPast()).Truncate(blockSize)
+		blockSize = rtopts.BlockSize()
+		start     = now.Add(-rtopts.RetentionPeriod()).Truncate(blockSize)
+		end       = now.Add(-rtopts.BufferPast()).Truncate(blockSize)
 	)
 
 	targetRanges := xtime.NewRanges().AddRange(xtime.Range{Start: start, End: end})
-	for t := range r.repairStates {
-		if !r.needsRepair(t) {
+	for t := range r.repairStatesByNs[ns.ID().Hash()] {
+		if !r.needsRepair(ns.ID(), t) {
 			targetRanges = targetRanges.RemoveRange(xtime.Range{Start: t, End: t.Add(blockSize)})
 		}
 	}
@@ -305,8 +430,8 @@ func (r *dbRepairer) repairTimeRanges() xtime.Ranges {
 	return targetRanges
 }
 
-func (r *dbRepairer) needsRepair(t time.Time) bool {
-	repairState, exists := r.repairStates[t]
+func (r *dbRepairer) needsRepair(ns ts.ID, t time.Time) bool {
+	repairState, exists := r.repairStatesByNs[ns.Hash()][t]
 	if !exists {
 		return true
 	}
@@ -342,22 +467,33 @@ func (r *dbRepairer) Repair() error {
 	}()
 
 	multiErr := xerrors.NewMultiError()
-	blockSize := r.rtopts.BlockSize()
-	iter := r.repairTimeRanges().Iter()
-	for iter.Next() {
-		tr := iter.Value()
-		err := r.repairWithTimeRange(tr)
-		for t := tr.Start; t.Before(tr.End); t = t.Add(blockSize) {
-			repairState := r.repairStates[t]
-			if err == nil {
-				repairState.Status = repairSuccess
-			} else {
-				repairState.Status = repairFailed
-				repairState.NumFailures++
+	namespaces := r.database.getOwnedNamespaces()
+	for _, n := range namespaces {
+		var (
+			nsHash    = n.ID().Hash()
+			blockSize = n.Options().RetentionOptions().BlockSize()
+			iter      = r.namespaceRepairTimeRanges(n).Iter()
+		)
+		repairStates, ok := r.repairStatesByNs[nsHash]
+		if !ok {
+			repairStates = make(namespaceRepairStates)
+			r.repairStatesByNs[nsHash] = repairStates
+		}
+		for iter.Next() {
+			tr := iter.Value()
+			err := r.repairNamespaceWithTimeRange(n, tr)
+			for t := tr.Start; t.Before(tr.End); t = t.Add(blockSize) {
+				repairState := repairStates[t]
+				if err == nil {
+					repairState.Status = repairSuccess
+				} else {
+					repairState.Status = repairFailed
+					repairState.NumFailures++
+				}
+				repairStates[t] = repairState
 			}
-			r.repairStates[t] = repairState
+			multiErr = multiErr.Add(err)
 		}
-		multiErr = multiErr.Add(err)
 	}
 
 	return multiErr.FinalError()
@@ -371,16 +507,11 @@ func (r *dbRepairer) Report() {
 	}
 }
 
-func (r *dbRepairer) repairWithTimeRange(tr xtime.Range) error {
-	multiErr := xerrors.NewMultiError()
-	namespaces := r.database.getOwnedNamespaces()
-	for _, n := range namespaces {
-		if err := n.Repair(r.shardRepairer, tr); err != nil {
-			detailedErr := fmt.Errorf("namespace %s failed to repair time range %v: %v", n.ID().String(), tr, err)
-			multiErr = multiErr.Add(detailedErr)
-		}
+func (r *dbRepairer) repairNamespaceWithTimeRange(n databaseNamespace, tr xtime.Range) error {
+	if err := n.Repair(r.shardRepairer, tr); err != nil {
+		return fmt.Errorf("namespace %s failed to repair time range %v: %v", n.ID().String(), tr, err)
 	}
-	return multiErr.FinalError()
+	return nil
 }
 
 var noOpRepairer databaseRepairer = repairerNoOp{}
@@ -29,6 +29,8 @@ import (
 	"github.com/m3db/m3db/client"
 	"github.com/m3db/m3db/context"
 	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/topology"
 	"github.com/m3db/m3db/ts"
@@ -234,7 +236,8 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		SetInstrumentOptions(iopts.SetMetricsScope(tally.NoopScope))
 
 	var (
-		namespace       = ts.StringID("testNamespace")
+		nsID            = ts.StringID("testNamespace")
+		nsMeta          = namespace.NewMetadata(nsID, testNamespaceOptions())
 		start           = now
 		end             = now.Add(rtopts.BlockSize())
 		repairTimeRange = xtime.Range{Start: start, End: end}
@@ -292,9 +295,33 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		peerIter.EXPECT().Err().Return(nil),
 	)
 	session.EXPECT().
-		FetchBlocksMetadataFromPeers(namespace, shardID, start, end).
+		FetchBlocksMetadataFromPeers(nsID, shardID, start, end).
 		Return(peerIter, nil)
 
+	repairedBlock := block.NewDatabaseBlock(now.Add(time.Hour), ts.Segment{}, opts.DatabaseBlockOptions())
+	blocksIter := client.NewMockPeerBlocksIter(ctrl)
+	gomock.InOrder(
+		blocksIter.EXPECT().Next().Return(true),
+		blocksIter.EXPECT().Current().Return(topology.NewHost("1", "addr1"), ts.StringID("foo"), repairedBlock),
+		blocksIter.EXPECT().Next().Return(false),
+		blocksIter.EXPECT().Err().Return(nil),
+	)
+	expectedFetch := []block.ReplicaMetadata{
+		{
+			Metadata: block.Metadata{Start: now.Add(time.Hour), Size: sizes[0], Checksum: &checksums[1]},
+			ID:       ts.StringID("foo"),
+			Host:     topology.NewHost("1", "addr1"),
+		},
+	}
+	session.EXPECT().
+		FetchBlocksFromPeers(nsID, shardID, expectedFetch, any).
+		Return(blocksIter, nil)
+
+	var loaded map[ts.Hash]result.DatabaseSeriesBlocks
+	shard.EXPECT().Load(any).Do(func(series map[ts.Hash]result.DatabaseSeriesBlocks) {
+		loaded = series
+	}).Return(nil)
+
 	var (
 		resNamespace ts.ID
 		resShard     databaseShard
@@ -304,15 +331,16 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 	databaseShardRepairer, err := newShardRepairer(opts, rpOpts)
 	require.NoError(t, err)
 	repairer := databaseShardRepairer.(shardRepairer)
-	repairer.recordFn = func(namespace ts.ID, shard databaseShard, diffRes repair.MetadataComparisonResult) {
-		resNamespace = namespace
+	repairer.recordFn = func(nsID ts.ID, shard databaseShard, diffRes repair.MetadataComparisonResult) {
+		resNamespace = nsID
 		resShard = shard
 		resDiff = diffRes
 	}
 
 	ctx := context.NewContext()
-	repairer.Repair(ctx, namespace, repairTimeRange, shard)
-	require.Equal(t, namespace, resNamespace)
+	_, err = repairer.Repair(ctx, nsMeta, repairTimeRange, shard)
+	require.NoError(t, err)
+	require.Equal(t, nsID, resNamespace)
 	require.Equal(t, resShard, shard)
 	require.Equal(t, int64(2), resDiff.NumSeries)
 	require.Equal(t, int64(3), resDiff.NumBlocks)
@@ -331,6 +359,14 @@ func TestDatabaseShardRepairerRepair(t *testing.T) {
 		{Host: topology.NewHost("1", "addr1"), Size: sizes[0], Checksum: &checksums[1]},
 	}
 	require.Equal(t, expected, block.Metadata())
+
+	require.Equal(t, 1, len(loaded))
+	loadedSeries, exists := loaded[ts.StringID("foo").Hash()]
+	require.True(t, exists)
+	require.Equal(t, ts.StringID("foo"), loadedSeries.ID)
+	loadedBlock, exists := loadedSeries.Blocks.BlockAt(now.Add(time.Hour))
+	require.True(t, exists)
+	require.Equal(t, repairedBlock, loadedBlock)
 }
 
 func TestRepairerRepairTimes(t *testing.T) {
@@ -355,11 +391,19 @@ func TestRepairerRepairTimes(t *testing.T) {
 	repairer, err := newDatabaseRepairer(database, database.opts)
 	require.NoError(t, err)
 	r := repairer.(*dbRepairer)
+
+	nsID := ts.StringID("testNamespace")
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ID().Return(nsID).AnyTimes()
+	ns.EXPECT().Options().Return(testNamespaceOptions()).AnyTimes()
+
+	repairStates := make(namespaceRepairStates)
 	for _, input := range inputTimes {
-		r.repairStates[input.bs] = input.rs
+		repairStates[input.bs] = input.rs
 	}
+	r.repairStatesByNs[nsID.Hash()] = repairStates
 
-	res := r.repairTimeRanges()
+	res := r.namespaceRepairTimeRanges(ns)
 	expectedRanges := xtime.NewRanges().
 		AddRange(xtime.Range{Start: time.Unix(14400, 0), End: time.Unix(28800, 0)}).
 		AddRange(xtime.Range{Start: time.Unix(50400, 0), End: time.Unix(187200, 0)})
@@ -385,16 +429,14 @@ func TestRepairerRepairWithTime(t *testing.T) {
 		{"bar", errors.New("some other error")},
 		{"baz", nil},
 	}
-	namespaces := make(map[string]databaseNamespace)
 	for _, input := range inputs {
 		ns := NewMockdatabaseNamespace(ctrl)
 		ns.EXPECT().Repair(gomock.Not(nil), repairTimeRange).Return(input.err)
 		if input.err != nil {
 			ns.EXPECT().ID().Return(ts.StringID(input.name))
+			require.Error(t, r.repairNamespaceWithTimeRange(ns, repairTimeRange))
+		} else {
+			require.NoError(t, r.repairNamespaceWithTimeRange(ns, repairTimeRange))
 		}
-		namespaces[input.name] = ns
 	}
-	database.namespaces = namespaces
-
-	require.Error(t, r.repairWithTimeRange(repairTimeRange))
 }
@@ -0,0 +1,420 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/block"
+	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/x/io"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	rollupProgressFileName = "rollup-progress.db"
+	rollupProgressLen      = 8
+)
+
+var (
+	errRollupAlreadyInProgress = errors.New("rollup already in progress")
+	errRollupProgressCorrupt   = errors.New("rollup progress file is corrupt")
+)
+
+type rollupManager struct {
+	sync.RWMutex
+
+	database         database
+	rollupInProgress bool
+	status           tally.Gauge
+}
+
+func newRollupManager(database database, scope tally.Scope) databaseRollupManager {
+	return &rollupManager{
+		database: database,
+		status:   scope.Gauge("rollup"),
+	}
+}
+
+func (m *rollupManager) Rollup(t time.Time) error {
+	m.Lock()
+	if m.rollupInProgress {
+		m.Unlock()
+		return errRollupAlreadyInProgress
+	}
+	m.rollupInProgress = true
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.rollupInProgress = false
+		m.Unlock()
+	}()
+
+	namespaces := m.database.getOwnedNamespaces()
+	byID := make(map[ts.Hash]databaseNamespace, len(namespaces))
+	for _, n := range namespaces {
+		byID[n.ID().Hash()] = n
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, n := range namespaces {
+		rollup := n.Options().Rollup()
+		if rollup == nil {
+			continue
+		}
+		source, ok := byID[rollup.SourceNamespace.Hash()]
+		if !ok {
+			multiErr = multiErr.Add(fmt.Errorf("namespace %s rollup source %s does not exist",
+				n.ID().String(), rollup.SourceNamespace.String()))
+			continue
+		}
+		blockStarts := m.rollupTimes(n, source, t)
+		if len(blockStarts) == 0 {
+			continue
+		}
+		// NB: we still want to proceed if a namespace fails to roll up its data.
+		if err := n.Rollup(source, blockStarts); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to roll up data: %v",
+				n.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+func (m *rollupManager) Report() {
+	m.RLock()
+	rollupInProgress := m.rollupInProgress
+	m.RUnlock()
+
+	if rollupInProgress {
+		m.status.Update(1)
+	} else {
+		m.status.Update(0)
+	}
+}
+
+// rollupTimes returns the block starts of the source namespace that may have
+// been flushed and that fall within the retention of the rollup namespace,
+// in ascending order
+func (m *rollupManager) rollupTimes(n, source databaseNamespace, t time.Time) []time.Time {
+	var (
+		sourceRopts = source.Options().RetentionOptions()
+		blockSize   = sourceRopts.BlockSize()
+		earliest    = retention.FlushTimeStart(sourceRopts, t)
+		latest      = retention.FlushTimeEnd(sourceRopts, t)
+	)
+	if targetEarliest := retention.FlushTimeStart(n.Options().RetentionOptions(), t); targetEarliest.After(earliest) {
+		earliest = targetEarliest
+	}
+
+	var rollupTimes []time.Time
+	for t := earliest; !t.After(latest); t = t.Add(blockSize) {
+		rollupTimes = append(rollupTimes, t)
+	}
+	return rollupTimes
+}
+
+// shardRollup rolls up the filesets of a shard of a source namespace into
+// the filesets of the same shard of a rollup namespace.
+type shardRollup struct {
+	opts           Options
+	fsOpts         fs.Options
+	filePathPrefix string
+	tierPrefixes   []string
+	namespace      ts.ID
+	blockSize      time.Duration
+	rollup         namespace.Rollup
+	shard          uint32
+}
+
+func newShardRollup(ns namespace.Metadata, shard uint32, opts Options) shardRollup {
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	return shardRollup{
+		opts:           opts,
+		fsOpts:         fsOpts,
+		filePathPrefix: fsOpts.FilePathPrefix(),
+		tierPrefixes:   fs.TierFilePathPrefixes(fsOpts.FilePathPrefix(), fsOpts.TieringPolicy()),
+		namespace:      ns.ID(),
+		blockSize:      ns.Options().RetentionOptions().BlockSize(),
+		rollup:         *ns.Options().Rollup(),
+		shard:          shard,
+	}
+}
+
+// sourceFlushed returns whether the source fileset for a block start exists.
+func (r shardRollup) sourceFlushed(blockStart time.Time) bool {
+	return fs.FilesetExistsAtAnyTier(r.tierPrefixes, r.rollup.SourceNamespace, r.shard, blockStart)
+}
+
+// rollupSeries is a series of the rollup fileset being written.
+type rollupSeries struct {
+	id       ts.ID
+	existing ts.Segment
+	rolledUp ts.Segment
+}
+
+// Rollup aggregates the source fileset for a block start and merges the
+// result into the rollup fileset of the block containing the block start,
+// returning the aggregated series as blocks to load into the shard.
+func (r shardRollup) Rollup(blockStart time.Time) (map[ts.Hash]result.DatabaseSeriesBlocks, error) {
+	var (
+		targetStart = blockStart.Truncate(r.blockSize)
+		series      = make(map[ts.Hash]*rollupSeries)
+		ordered     []*rollupSeries
+	)
+	defer func() {
+		for _, s := range ordered {
+			s.existing.Finalize()
+			s.rolledUp.Finalize()
+		}
+	}()
+
+	// Read any data already rolled up into the target block
+	if fs.FilesetExistsAtAnyTier(r.tierPrefixes, r.namespace, r.shard, targetStart) {
+		err := r.read(r.namespace, targetStart, func(id ts.ID, data checked.Bytes) error {
+			s := &rollupSeries{id: id, existing: ts.NewSegment(data, nil, ts.FinalizeHead)}
+			series[id.Hash()] = s
+			ordered = append(ordered, s)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := r.read(r.rollup.SourceNamespace, blockStart, func(id ts.ID, data checked.Bytes) error {
+		data.IncRef()
+		rolledUp, err := r.aggregate(blockStart, targetStart, bytes.NewReader(data.Get()))
+		data.DecRef()
+		data.Finalize()
+		if err != nil {
+			return err
+		}
+		s, ok := series[id.Hash()]
+		if !ok {
+			s = &rollupSeries{id: id}
+			series[id.Hash()] = s
+			ordered = append(ordered, s)
+		} else {
+			id.Finalize()
+		}
+		s.rolledUp = rolledUp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.write(targetStart, ordered); err != nil {
+		return nil, err
+	}
+
+	blockOpts := r.opts.DatabaseBlockOptions()
+	rolledUp := make(map[ts.Hash]result.DatabaseSeriesBlocks, len(ordered))
+	for _, s := range ordered {
+		if s.rolledUp.Len() == 0 {
+			continue
+		}
+		bl := blockOpts.DatabaseBlockPool().Get()
+		bl.Reset(targetStart, s.rolledUp)
+		s.rolledUp = ts.Segment{}
+		blocks := block.NewDatabaseSeriesBlocks(1, blockOpts)
+		blocks.AddBlock(bl)
+		rolledUp[s.id.Hash()] = result.DatabaseSeriesBlocks{ID: s.id, Blocks: blocks}
+	}
+	return rolledUp, nil
+}
+
+func (r shardRollup) read(
+	namespace ts.ID,
+	blockStart time.Time,
+	fn func(id ts.ID, data checked.Bytes) error,
+) error {
+	reader := fs.NewTieredReader(r.tierPrefixes, r.fsOpts.ReaderBufferSize(),
+		r.opts.BytesPool(), r.fsOpts.DecodingOptions())
+	if err := reader.Open(namespace, r.shard, blockStart); err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for {
+		id, data, _, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(id, data); err != nil {
+			return err
+		}
+	}
+	return reader.Validate()
+}
+
+// aggregate downsamples the encoded data of a source block into a segment
+// encoded from the start of the target block.
+func (r shardRollup) aggregate(
+	blockStart, targetStart time.Time,
+	reader io.Reader,
+) (ts.Segment, error) {
+	iter := r.opts.ReaderIteratorPool().Get()
+	iter.Reset(reader)
+	downsampled := encoding.NewDownsampleIterator(iter, blockStart,
+		r.rollup.Resolution, r.rollup.Aggregation)
+	defer downsampled.Close()
+	return r.encode(targetStart, downsampled)
+}
+
+func (r shardRollup) encode(start time.Time, iter encoding.Iterator) (ts.Segment, error) {
+	encoder := r.opts.EncoderPool().Get()
+	encoder.Reset(start, 0)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return ts.Segment{}, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return ts.Segment{}, err
+	}
+	return encoder.Discard(), nil
+}
+
+func (r shardRollup) write(targetStart time.Time, series []*rollupSeries) error {
+	writer := fs.NewWriter(r.blockSize, r.filePathPrefix, r.fsOpts.WriterBufferSize(),
+		r.fsOpts.NewFileMode(), r.fsOpts.NewDirectoryMode(), r.fsOpts.Compression())
+	if err := writer.Open(r.namespace, r.shard, targetStart); err != nil {
+		return err
+	}
+
+	multiErr := xerrors.NewMultiError()
+	segmentHolder := make([]checked.Bytes, 2)
+	for _, s := range series {
+		segment, merged, err := r.merge(targetStart, s)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+		segmentHolder[0], segmentHolder[1] = segment.Head, segment.Tail
+		err = writer.WriteAll(s.id, segmentHolder, digest.SegmentChecksum(segment))
+		if merged {
+			segment.Finalize()
+		}
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			break
+		}
+	}
+
+	multiErr = multiErr.Add(writer.Close())
+	return multiErr.FinalError()
+}
+
+// merge returns the existing data of a series merged with its newly rolled up
+// data and whether a new segment was allocated for the merged data, merging
+// deduplicates any datapoints rolled up before a restart.
+func (r shardRollup) merge(targetStart time.Time, s *rollupSeries) (ts.Segment, bool, error) {
+	if s.existing.Len() == 0 {
+		return s.rolledUp, false, nil
+	}
+	if s.rolledUp.Len() == 0 {
+		return s.existing, false, nil
+	}
+	iter := r.opts.MultiReaderIteratorPool().Get()
+	iter.Reset([]io.Reader{
+		xio.NewSegmentReader(s.existing),
+		xio.NewSegmentReader(s.rolledUp),
+	})
+	defer iter.Close()
+	merged, err := r.encode(targetStart, iter)
+	return merged, err == nil, err
+}
+
+// Progress returns the latest source block start rolled up into the shard
+// and whether any block has been rolled up.
+func (r shardRollup) Progress() (time.Time, bool, error) {
+	data, err := ioutil.ReadFile(r.progressFilePath())
+	if os.IsNotExist(err) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(data) != rollupProgressLen {
+		return time.Time{}, false, errRollupProgressCorrupt
+	}
+	nanos := int64(binary.LittleEndian.Uint64(data))
+	return xtime.FromNanoseconds(nanos), true, nil
+}
+
+// MarkProgress records a source block start as rolled up, the progress
+// file is replaced atomically so a restart resumes after the block start.
+func (r shardRollup) MarkProgress(blockStart time.Time) error {
+	filePath := r.progressFilePath()
+	tmpFilePath := filePath + ".tmp"
+	fd, err := fs.OpenWritable(tmpFilePath, r.fsOpts.NewFileMode())
+	if err != nil {
+		return err
+	}
+	data := make([]byte, rollupProgressLen)
+	binary.LittleEndian.PutUint64(data, uint64(blockStart.UnixNano()))
+	_, err = fd.Write(data)
+	if err == nil {
+		err = fd.Sync()
+	}
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, filePath)
+}
+
+func (r shardRollup) progressFilePath() string {
+	shardDir := fs.ShardDirPath(r.filePathPrefix, r.namespace, r.shard)
+	return path.Join(shardDir, rollupProgressFileName)
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/x/io"
+	"github.com/m3db/m3x/checked"
+	"github.com/m3db/m3x/time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+var (
+	testRollupSourceID = ts.StringID("source")
+	testRollupTargetID = ts.StringID("rollup")
+)
+
+func TestRollupManagerRollupUsesNamespaceRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Unix(86400*4+4*3600+1800, 0)
+	sourceRopts := retention.NewOptions().
+		SetBlockSize(2 * time.Hour).
+		SetRetentionPeriod(24 * time.Hour)
+	targetRopts := retention.NewOptions().
+		SetBlockSize(4 * time.Hour).
+		SetRetentionPeriod(8 * time.Hour)
+
+	source := NewMockdatabaseNamespace(ctrl)
+	source.EXPECT().ID().Return(testRollupSourceID).AnyTimes()
+	source.EXPECT().Options().Return(namespace.NewOptions().
+		SetRetentionOptions(sourceRopts)).AnyTimes()
+
+	target := NewMockdatabaseNamespace(ctrl)
+	target.EXPECT().ID().Return(testRollupTargetID).AnyTimes()
+	target.EXPECT().Options().Return(namespace.NewOptions().
+		SetRetentionOptions(targetRopts).
+		SetRollup(&namespace.Rollup{
+			SourceNamespace: testRollupSourceID,
+			Resolution:      time.Minute,
+			Aggregation:     encoding.AggregationMax,
+		})).AnyTimes()
+
+	db := newMockDatabase()
+	db.namespaces = map[string]databaseNamespace{
+		"source": source,
+		"rollup": target,
+	}
+
+	// Only the source block starts that fall within the retention of the
+	// rollup namespace are rolled up
+	var expected []time.Time
+	earliest := retention.FlushTimeStart(targetRopts, now)
+	latest := retention.FlushTimeEnd(sourceRopts, now)
+	for t := earliest; !t.After(latest); t = t.Add(sourceRopts.BlockSize()) {
+		expected = append(expected, t)
+	}
+	target.EXPECT().Rollup(source, expected).Return(nil)
+
+	rm := newRollupManager(db, tally.NoopScope)
+	require.NoError(t, rm.Rollup(now))
+}
+
+type testRollupValue struct {
+	offset time.Duration
+	value  float64
+}
+
+func testRollupOptions(t *testing.T) (Options, namespace.Metadata, string) {
+	dir, err := ioutil.TempDir("", "rollup")
+	require.NoError(t, err)
+
+	opts := testDatabaseOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	ropts := retention.NewOptions().
+		SetBlockSize(4 * time.Hour).
+		SetRetentionPeriod(30 * 24 * time.Hour)
+	opts = opts.
+		SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts)).
+		SetRetentionOptions(ropts)
+
+	md := namespace.NewMetadata(testRollupTargetID, namespace.NewOptions().
+		SetNeedsFlush(false).
+		SetRetentionOptions(ropts).
+		SetRollup(&namespace.Rollup{
+			SourceNamespace: testRollupSourceID,
+			Resolution:      time.Minute,
+			Aggregation:     encoding.AggregationMax,
+		}))
+	return opts, md, dir
+}
+
+func writeTestRollupSource(
+	t *testing.T,
+	opts Options,
+	id ts.ID,
+	blockStart time.Time,
+	values []testRollupValue,
+) {
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	writer := fs.NewWriter(2*time.Hour, fsOpts.FilePathPrefix(), fsOpts.WriterBufferSize(),
+		fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), fsOpts.Compression())
+	require.NoError(t, writer.Open(testRollupSourceID, 0, blockStart))
+
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(blockStart, 0)
+	for _, v := range values {
+		dp := ts.Datapoint{Timestamp: blockStart.Add(v.offset), Value: v.value}
+		require.NoError(t, encoder.Encode(dp, xtime.Second, nil))
+	}
+	segment := encoder.Discard()
+	defer segment.Finalize()
+
+	checksum := digest.SegmentChecksum(segment)
+	require.NoError(t, writer.WriteAll(id, []checked.Bytes{segment.Head, segment.Tail}, checksum))
+	require.NoError(t, writer.Close())
+}
+
+func readTestRollupTarget(t *testing.T, opts Options, blockStart time.Time) map[string][]ts.Datapoint {
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	reader := fs.NewReader(fsOpts.FilePathPrefix(), fsOpts.ReaderBufferSize(), nil, fsOpts.DecodingOptions())
+	require.NoError(t, reader.Open(testRollupTargetID, 0, blockStart))
+	defer reader.Close()
+
+	results := make(map[string][]ts.Datapoint)
+	for {
+		id, data, _, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data.IncRef()
+		results[id.String()] = decodeTestRollup(t, opts, bytes.NewReader(data.Get()))
+		data.DecRef()
+	}
+	require.NoError(t, reader.Validate())
+	return results
+}
+
+func decodeTestRollup(t *testing.T, opts Options, readers ...io.Reader) []ts.Datapoint {
+	iter := opts.MultiReaderIteratorPool().Get()
+	iter.Reset(readers)
+	defer iter.Close()
+
+	var dps []ts.Datapoint
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		dps = append(dps, dp)
+	}
+	require.NoError(t, iter.Err())
+	return dps
+}
+
+func TestShardRollup(t *testing.T) {
+	opts, md, dir := testRollupOptions(t)
+	defer os.RemoveAll(dir)
+
+	targetStart := time.Now().Truncate(4 * time.Hour).Add(-2 * 24 * time.Hour)
+	first, second := targetStart, targetStart.Add(2*time.Hour)
+	blockStarts := []time.Time{first, second}
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	require.NoError(t, s.Bootstrap(nil))
+
+	id := ts.StringID("foo")
+	writeTestRollupSource(t, opts, id, first, []testRollupValue{
+		{10 * time.Second, 1},
+		{20 * time.Second, 3},
+		{70 * time.Second, 5},
+	})
+
+	// The second source block is not flushed yet so only the first is rolled up
+	require.NoError(t, s.Rollup(md, blockStarts))
+	r := newShardRollup(md, s.ID(), opts)
+	progress, ok, err := r.Progress()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, first, progress)
+
+	expected := []ts.Datapoint{
+		{Timestamp: first, Value: 3},
+		{Timestamp: first.Add(time.Minute), Value: 5},
+	}
+	require.Equal(t, map[string][]ts.Datapoint{"foo": expected}, readTestRollupTarget(t, opts, targetStart))
+
+	// Rolled up data is readable from the shard
+	ctx := context.NewContext()
+	encoded, err := s.ReadEncoded(ctx, id, targetStart, targetStart.Add(4*time.Hour))
+	require.NoError(t, err)
+	iter := opts.MultiReaderIteratorPool().Get()
+	iter.ResetSliceOfSlices(xio.NewReaderSliceOfSlicesFromSegmentReadersIterator(encoded))
+	var read []ts.Datapoint
+	for iter.Next() {
+		dp, _, _ := iter.Current()
+		read = append(read, dp)
+	}
+	require.NoError(t, iter.Err())
+	iter.Close()
+	ctx.Close()
+	require.Equal(t, expected, read)
+
+	// Rolling up the second source block merges it into the same rollup block
+	writeTestRollupSource(t, opts, id, second, []testRollupValue{
+		{30 * time.Second, 7},
+	})
+	require.NoError(t, s.Rollup(md, blockStarts))
+	progress, ok, err = r.Progress()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, second, progress)
+
+	expected = append(expected, ts.Datapoint{Timestamp: second, Value: 7})
+	require.Equal(t, map[string][]ts.Datapoint{"foo": expected}, readTestRollupTarget(t, opts, targetStart))
+}
+
+func TestShardRollupResumesAfterRestart(t *testing.T) {
+	opts, md, dir := testRollupOptions(t)
+	defer os.RemoveAll(dir)
+
+	targetStart := time.Now().Truncate(4 * time.Hour).Add(-2 * 24 * time.Hour)
+	first, second := targetStart, targetStart.Add(2*time.Hour)
+
+	id := ts.StringID("foo")
+	writeTestRollupSource(t, opts, id, first, []testRollupValue{{10 * time.Second, 1}})
+	writeTestRollupSource(t, opts, id, second, []testRollupValue{{10 * time.Second, 2}})
+
+	// Roll up both blocks but only record progress for the first, as if the
+	// node restarted after writing the rollup fileset for the second
+	s := testDatabaseShard(opts)
+	require.NoError(t, s.Bootstrap(nil))
+	require.NoError(t, s.Rollup(md, []time.Time{first, second}))
+	s.Close()
+
+	r := newShardRollup(md, 0, opts)
+	require.NoError(t, r.MarkProgress(first))
+
+	s = testDatabaseShard(opts)
+	defer s.Close()
+	require.NoError(t, s.Bootstrap(nil))
+	require.NoError(t, s.Rollup(md, []time.Time{first, second}))
+
+	progress, ok, err := r.Progress()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, second, progress)
+
+	// Rolling up the second block again does not duplicate its datapoints
+	expected := []ts.Datapoint{
+		{Timestamp: first, Value: 1},
+		{Timestamp: second, Value: 2},
+	}
+	require.Equal(t, map[string][]ts.Datapoint{"foo": expected}, readTestRollupTarget(t, opts, targetStart))
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m3db/m3db/clock"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ratelimit"
+	"github.com/m3db/m3db/retention"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/errors"
+	"github.com/m3db/m3x/log"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	scrubCheckInterval   = time.Minute
+	scrubBytesPerMegabit = 1024 * 1024 / 8
+)
+
+var (
+	errScrubInProgress = errors.New("scrub already in progress")
+	errScrubStopped    = errors.New("scrub stopped")
+)
+
+type scrubFn func() error
+
+type filesetKey struct {
+	namespace  ts.Hash
+	shard      uint32
+	blockStart time.Time
+}
+
+type scrubberMetrics struct {
+	verified tally.Counter
+	corrupt  tally.Counter
+	errors   tally.Counter
+}
+
+func newScrubberMetrics(scope tally.Scope) scrubberMetrics {
+	return scrubberMetrics{
+		verified: scope.Counter("verified"),
+		corrupt:  scope.Counter("corrupt"),
+		errors:   scope.Counter("errors"),
+	}
+}
+
+type dbScrubber struct {
+	sync.Mutex
+
+	database     database
+	reader       fs.FileSetReader
+	tierPrefixes []string
+	scrubbedAt   map[filesetKey]time.Time
+
+	scrubFn            scrubFn
+	sleepFn            sleepFn
+	nowFn              clock.NowFn
+	logger             xlog.Logger
+	scrubInterval      time.Duration
+	scrubCheckInterval time.Duration
+	rateLimitOpts      ratelimit.Options
+	closed             bool
+	running            int32
+	status             tally.Gauge
+	metrics            scrubberMetrics
+
+	// Rate limiting state of the scrub in progress
+	start     time.Time
+	bytesRead int64
+	count     int
+}
+
+func newDatabaseScrubber(database database, opts Options) databaseScrubber {
+	fileOpts := opts.FileOpOptions()
+	if fileOpts.ScrubInterval() <= 0 {
+		return newNoopDatabaseScrubber()
+	}
+
+	var (
+		fsOpts   = opts.CommitLogOptions().FilesystemOptions()
+		scope    = opts.InstrumentOptions().MetricsScope()
+		prefixes = fs.TierFilePathPrefixes(fsOpts.FilePathPrefix(), fsOpts.TieringPolicy())
+	)
+	s := &dbScrubber{
+		database: database,
+		reader: fs.NewTieredReader(prefixes, fsOpts.ReaderBufferSize(),
+			opts.BytesPool(), fsOpts.DecodingOptions()),
+		tierPrefixes:       prefixes,
+		scrubbedAt:         make(map[filesetKey]time.Time),
+		sleepFn:            time.Sleep,
+		nowFn:              opts.ClockOptions().NowFn(),
+		logger:             opts.InstrumentOptions().Logger(),
+		scrubInterval:      fileOpts.ScrubInterval(),
+		scrubCheckInterval: scrubCheckInterval,
+		rateLimitOpts:      fileOpts.ScrubRateLimitOptions(),
+		status:             scope.Gauge("scrub"),
+		metrics:            newScrubberMetrics(scope.SubScope("scrub")),
+	}
+	s.scrubFn = s.Scrub
+
+	return s
+}
+
+func (s *dbScrubber) run() {
+	for !s.isClosed() {
+		s.sleepFn(s.scrubCheckInterval)
+
+		if err := s.scrubFn(); err != nil && err != errScrubStopped {
+			s.logger.Errorf("error scrubbing filesets: %v", err)
+		}
+	}
+}
+
+func (s *dbScrubber) isClosed() bool {
+	s.Lock()
+	closed := s.closed
+	s.Unlock()
+	return closed
+}
+
+func (s *dbScrubber) Start() {
+	go s.run()
+}
+
+func (s *dbScrubber) Stop() {
+	s.Lock()
+	s.closed = true
+	s.Unlock()
+}
+
+func (s *dbScrubber) Scrub() error {
+	// Don't scrub filesets before the database is bootstrapped as they
+	// may still be read by the bootstrappers
+	if !s.database.IsBootstrapped() {
+		return nil
+	}
+
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return errScrubInProgress
+	}
+
+	defer func() {
+		atomic.StoreInt32(&s.running, 0)
+	}()
+
+	s.start = s.nowFn()
+	s.bytesRead = 0
+	s.count = 0
+
+	multiErr := xerrors.NewMultiError()
+	for _, n := range s.database.getOwnedNamespaces() {
+		if err := s.scrubNamespace(n); err != nil {
+			if err == errScrubStopped {
+				return err
+			}
+			multiErr = multiErr.Add(err)
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+func (s *dbScrubber) scrubNamespace(n databaseNamespace) error {
+	var (
+		now      = s.nowFn()
+		nsHash   = n.ID().Hash()
+		earliest = retention.FlushTimeStart(n.Options().RetentionOptions(), now)
+		multiErr = xerrors.NewMultiError()
+	)
+	for key := range s.scrubbedAt {
+		if key.namespace == nsHash && key.blockStart.Before(earliest) {
+			delete(s.scrubbedAt, key)
+		}
+	}
+
+	for _, shard := range n.Shards() {
+		if !shard.IsBootstrapped() {
+			continue
+		}
+
+		blockStarts, err := fs.TierFilesetBlockStarts(s.tierPrefixes, n.ID(), shard.ID())
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		for _, blockStart := range blockStarts {
+			if blockStart.Before(earliest) {
+				// Expired filesets are removed by the next cleanup
+				continue
+			}
+			key := filesetKey{namespace: nsHash, shard: shard.ID(), blockStart: blockStart}
+			if scrubbedAt, ok := s.scrubbedAt[key]; ok && now.Sub(scrubbedAt) < s.scrubInterval {
+				continue
+			}
+			if s.isClosed() {
+				return errScrubStopped
+			}
+
+			err := fs.VerifyFileset(s.reader, n.ID(), shard.ID(), blockStart, s.rateLimit)
+			if os.IsNotExist(err) {
+				// The fileset was superseded by a newer version or cleaned up
+				// while being verified, it is verified by the next scrub
+				continue
+			}
+			s.scrubbedAt[key] = s.nowFn()
+			s.metrics.verified.Inc(1)
+			if err == nil {
+				continue
+			}
+
+			s.metrics.corrupt.Inc(1)
+			s.logger.Errorf("namespace %s shard %d fileset at %v is corrupt: %v",
+				n.ID().String(), shard.ID(), blockStart, err)
+			if err := n.MarkBlockCorrupt(shard.ID(), blockStart); err != nil {
+				s.metrics.errors.Inc(1)
+				multiErr = multiErr.Add(fmt.Errorf(
+					"namespace %s failed to mark shard %d block %v corrupt: %v",
+					n.ID().String(), shard.ID(), blockStart, err))
+			}
+		}
+	}
+
+	return multiErr.FinalError()
+}
+
+// rateLimit sleeps as required to keep the rate filesets are read at
+// under the scrub rate limit
+func (s *dbScrubber) rateLimit(length int) {
+	s.bytesRead += int64(length)
+	s.count++
+
+	opts := s.rateLimitOpts
+	limitMbps := opts.LimitMbps()
+	if !opts.LimitEnabled() || limitMbps <= 0.0 || s.count < opts.LimitCheckEvery() {
+		return
+	}
+	s.count = 0
+
+	target := time.Duration(float64(time.Second) * float64(s.bytesRead) / (limitMbps * scrubBytesPerMegabit))
+	if elapsed := s.nowFn().Sub(s.start); elapsed < target {
+		s.sleepFn(target - elapsed)
+	}
+}
+
+func (s *dbScrubber) Report() {
+	if atomic.LoadInt32(&s.running) == 1 {
+		s.status.Update(1)
+	} else {
+		s.status.Update(0)
+	}
+}
+
+var noOpScrubber databaseScrubber = scrubberNoOp{}
+
+type scrubberNoOp struct{}
+
+func newNoopDatabaseScrubber() databaseScrubber { return noOpScrubber }
+
+func (s scrubberNoOp) Start()       {}
+func (s scrubberNoOp) Stop()        {}
+func (s scrubberNoOp) Scrub() error { return nil }
+func (s scrubberNoOp) Report()      {}
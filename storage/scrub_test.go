@@ -0,0 +1,168 @@
+// Copyright (c) 2016 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ratelimit"
+	"github.com/m3db/m3db/storage/namespace"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/checked"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func testScrubOptions(t *testing.T) (Options, string) {
+	dir, err := ioutil.TempDir("", "scrub")
+	require.NoError(t, err)
+
+	opts := testDatabaseOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	return opts.
+		SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts)).
+		SetFileOpOptions(opts.FileOpOptions().
+			SetScrubInterval(time.Hour).
+			SetScrubRateLimitOptions(ratelimit.NewOptions())), dir
+}
+
+func TestDatabaseScrubberDisabled(t *testing.T) {
+	mockDatabase := newMockDatabase()
+	scrubber := newDatabaseScrubber(mockDatabase, mockDatabase.opts)
+	require.Equal(t, noOpScrubber, scrubber)
+}
+
+func TestDatabaseScrubberScrubNotBootstrapped(t *testing.T) {
+	opts, dir := testScrubOptions(t)
+	defer os.RemoveAll(dir)
+
+	mockDatabase := newMockDatabase()
+	mockDatabase.opts = opts
+	mockDatabase.bs = bootstrapNotStarted
+
+	scrubber := newDatabaseScrubber(mockDatabase, mockDatabase.opts).(*dbScrubber)
+	require.NoError(t, scrubber.Scrub())
+	require.Equal(t, 0, len(scrubber.scrubbedAt))
+}
+
+func TestDatabaseScrubberScrub(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, dir := testScrubOptions(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		nsOpts       = namespace.NewOptions()
+		blockSize    = nsOpts.RetentionOptions().BlockSize()
+		now          = time.Now()
+		validStart   = now.Truncate(blockSize).Add(-2 * blockSize)
+		corruptStart = validStart.Add(-blockSize)
+		data         = []byte{1, 2, 3}
+	)
+
+	// Write a valid fileset and a fileset with an entry that does not
+	// match its checksum
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	writer := fs.NewWriter(blockSize, dir, fsOpts.WriterBufferSize(),
+		fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), fsOpts.Compression())
+	for blockStart, checksum := range map[time.Time]uint32{
+		validStart:   digest.Checksum(data),
+		corruptStart: digest.Checksum(data) + 1,
+	} {
+		bytes := checked.NewBytes(data, nil)
+		bytes.IncRef()
+		require.NoError(t, writer.Open(testNamespaceID, 0, blockStart))
+		require.NoError(t, writer.Write(ts.StringID("foo"), bytes, checksum))
+		require.NoError(t, writer.Close())
+		bytes.DecRef()
+	}
+
+	shard := NewMockShard(ctrl)
+	shard.EXPECT().ID().Return(uint32(0)).AnyTimes()
+	shard.EXPECT().IsBootstrapped().Return(true).AnyTimes()
+
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ID().Return(testNamespaceID).AnyTimes()
+	ns.EXPECT().Options().Return(nsOpts).AnyTimes()
+	ns.EXPECT().Shards().Return([]Shard{shard}).AnyTimes()
+	ns.EXPECT().MarkBlockCorrupt(uint32(0), corruptStart).Return(nil).Times(2)
+
+	mockDatabase := newMockDatabase()
+	mockDatabase.opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+	mockDatabase.bs = bootstrapped
+	mockDatabase.namespaces = map[string]databaseNamespace{
+		testNamespaceID.String(): ns,
+	}
+
+	scrubber := newDatabaseScrubber(mockDatabase, mockDatabase.opts).(*dbScrubber)
+	require.NoError(t, scrubber.Scrub())
+	require.Equal(t, 2, len(scrubber.scrubbedAt))
+
+	// Filesets are not verified again within the scrub interval
+	now = now.Add(time.Minute)
+	require.NoError(t, scrubber.Scrub())
+
+	now = now.Add(time.Hour)
+	require.NoError(t, scrubber.Scrub())
+}
+
+func TestDatabaseScrubberRateLimit(t *testing.T) {
+	opts, dir := testScrubOptions(t)
+	defer os.RemoveAll(dir)
+
+	var (
+		now   = time.Now()
+		slept time.Duration
+	)
+	mockDatabase := newMockDatabase()
+	mockDatabase.opts = opts.
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		})).
+		SetFileOpOptions(opts.FileOpOptions().
+			SetScrubRateLimitOptions(ratelimit.NewOptions().
+				SetLimitEnabled(true).
+				SetLimitMbps(1).
+				SetLimitCheckEvery(2)))
+
+	scrubber := newDatabaseScrubber(mockDatabase, mockDatabase.opts).(*dbScrubber)
+	scrubber.sleepFn = func(d time.Duration) {
+		slept += d
+	}
+	scrubber.start = now
+
+	// The limit is only checked every other read
+	scrubber.rateLimit(scrubBytesPerMegabit / 2)
+	require.Equal(t, time.Duration(0), slept)
+
+	scrubber.rateLimit(scrubBytesPerMegabit / 2)
+	require.Equal(t, time.Second, slept)
+}
@@ -96,6 +96,14 @@ type databaseBuffer interface {
 
 	Bootstrap(bl block.DatabaseBlock) error
 
+	ColdMinMax() (time.Time, time.Time)
+
+	ColdStreams(ctx context.Context, blockStart time.Time) []xio.SegmentReader
+
+	ColdStreamsLen(blockStart time.Time) int
+
+	DrainCold(blockStart time.Time) (block.DatabaseBlock, bool)
+
 	Reset()
 }
 
@@ -114,6 +122,7 @@ type dbBuffer struct {
 	drainFn           databaseBufferDrainFn
 	pastMostBucketIdx int
 	buckets           [bucketsLen]dbBufferBucket
+	coldBuckets       map[time.Time]*dbBufferBucket
 	blockSize         time.Duration
 	bufferPast        time.Duration
 	bufferFuture      time.Duration
@@ -129,12 +138,18 @@ func newDatabaseBuffer(drainFn databaseBufferDrainFn, opts Options) databaseBuff
 		blockSize:    opts.RetentionOptions().BlockSize(),
 		bufferPast:   opts.RetentionOptions().BufferPast(),
 		bufferFuture: opts.RetentionOptions().BufferFuture(),
+		coldBuckets:  make(map[time.Time]*dbBufferBucket),
 	}
 	b.Reset()
 	return b
 }
 
 func (b *dbBuffer) Reset() {
+	for start, bucket := range b.coldBuckets {
+		bucket.finalize()
+		delete(b.coldBuckets, start)
+	}
+
 	// Avoid capturing any variables with callback
 	b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketResetStart)
 }
@@ -172,7 +187,7 @@ func (b *dbBuffer) Write(
 		return xerrors.NewInvalidParamsError(errTooFuture)
 	}
 	if !pastLimit.Before(timestamp) {
-		return xerrors.NewInvalidParamsError(errTooPast)
+		return b.writeCold(now, timestamp, value, unit, annotation)
 	}
 
 	bucketStart := timestamp.Truncate(b.blockSize)
@@ -185,6 +200,44 @@ func (b *dbBuffer) Write(
 	return b.buckets[idx].write(timestamp, value, unit, annotation)
 }
 
+// writeCold writes a datapoint older than the buffer past window, either to
+// the bucket of its block if the bucket has not yet drained or to a cold
+// bucket that is merged into the flushed block by the next cold flush.
+func (b *dbBuffer) writeCold(
+	now time.Time,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	if !b.opts.ColdWritesEnabled() {
+		return xerrors.NewInvalidParamsError(errTooPast)
+	}
+
+	bucketStart := timestamp.Truncate(b.blockSize)
+	if bucketStart.Before(b.coldExpireCutoff(now)) {
+		return xerrors.NewInvalidParamsError(errTooPast)
+	}
+
+	idx := b.writableBucketIdx(timestamp)
+	if b.buckets[idx].start.Equal(bucketStart) && !b.buckets[idx].drained {
+		return b.buckets[idx].write(timestamp, value, unit, annotation)
+	}
+
+	bucket, ok := b.coldBuckets[bucketStart]
+	if !ok {
+		bucket = &dbBufferBucket{opts: b.opts}
+		bucket.resetTo(bucketStart)
+		b.coldBuckets[bucketStart] = bucket
+	}
+	return bucket.write(timestamp, value, unit, annotation)
+}
+
+func (b *dbBuffer) coldExpireCutoff(now time.Time) time.Time {
+	retentionPeriod := b.opts.RetentionOptions().RetentionPeriod()
+	return now.Add(-retentionPeriod).Truncate(b.blockSize)
+}
+
 func (b *dbBuffer) writableBucketIdx(t time.Time) int {
 	return int((t.UnixNano() / int64(b.blockSize)) % bucketsLen)
 }
@@ -194,6 +247,9 @@ func (b *dbBuffer) IsEmpty() bool {
 	for i := range b.buckets {
 		canReadAny = canReadAny || b.buckets[i].canRead()
 	}
+	for _, bucket := range b.coldBuckets {
+		canReadAny = canReadAny || bucket.canRead()
+	}
 	return !canReadAny
 }
 
@@ -209,6 +265,11 @@ func (b *dbBuffer) Stats() bufferStats {
 		}
 		stats.wiredBlocks++
 	}
+	for _, bucket := range b.coldBuckets {
+		if bucket.canRead() {
+			stats.wiredBlocks++
+		}
+	}
 	return stats
 }
 
@@ -225,6 +286,15 @@ func bucketNeedsDrain(now time.Time, b *dbBuffer, idx int, start time.Time) int
 }
 
 func (b *dbBuffer) DrainAndReset() drainAndResetResult {
+	// Cold writes not merged before their block expired are discarded
+	expireCutoff := b.coldExpireCutoff(b.nowFn())
+	for start, bucket := range b.coldBuckets {
+		if start.Before(expireCutoff) {
+			bucket.finalize()
+			delete(b.coldBuckets, start)
+		}
+	}
+
 	// Avoid capturing any variables with callback
 	mergedOutOfOrder := b.computedForEachBucketAsc(computeAndResetBucketIdx, bucketDrainAndReset)
 	return drainAndResetResult{
@@ -278,6 +348,54 @@ func (b *dbBuffer) Bootstrap(bl block.DatabaseBlock) error {
 	return nil
 }
 
+func (b *dbBuffer) ColdMinMax() (time.Time, time.Time) {
+	var min, max time.Time
+	for start := range b.coldBuckets {
+		if min.IsZero() || start.Before(min) {
+			min = start
+		}
+		if max.IsZero() || start.After(max) {
+			max = start
+		}
+	}
+	return min, max
+}
+
+func (b *dbBuffer) ColdStreams(ctx context.Context, blockStart time.Time) []xio.SegmentReader {
+	bucket, ok := b.coldBuckets[blockStart]
+	if !ok || !bucket.canRead() {
+		return nil
+	}
+	return bucket.streams(ctx)
+}
+
+func (b *dbBuffer) ColdStreamsLen(blockStart time.Time) int {
+	bucket, ok := b.coldBuckets[blockStart]
+	if !ok || !bucket.canRead() {
+		return 0
+	}
+	return bucket.streamsLen()
+}
+
+func (b *dbBuffer) DrainCold(blockStart time.Time) (block.DatabaseBlock, bool) {
+	bucket, ok := b.coldBuckets[blockStart]
+	if !ok {
+		return nil, false
+	}
+	delete(b.coldBuckets, blockStart)
+	defer bucket.finalize()
+
+	if !bucket.canRead() {
+		return nil, false
+	}
+	merged := bucket.discardMerged()
+	if merged.block.Len() == 0 {
+		merged.block.Close()
+		return nil, false
+	}
+	return merged.block, true
+}
+
 // forEachBucketAsc iterates over the buckets in time ascending order
 // to read bucket data
 func (b *dbBuffer) forEachBucketAsc(fn func(*dbBufferBucket)) {
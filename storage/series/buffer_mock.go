@@ -156,6 +156,48 @@ func (_mr *_MockdatabaseBufferRecorder) Bootstrap(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Bootstrap", arg0)
 }
 
+func (_m *MockdatabaseBuffer) ColdMinMax() (time.Time, time.Time) {
+	ret := _m.ctrl.Call(_m, "ColdMinMax")
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(time.Time)
+	return ret0, ret1
+}
+
+func (_mr *_MockdatabaseBufferRecorder) ColdMinMax() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdMinMax")
+}
+
+func (_m *MockdatabaseBuffer) ColdStreams(ctx context.Context, blockStart time.Time) []io.SegmentReader {
+	ret := _m.ctrl.Call(_m, "ColdStreams", ctx, blockStart)
+	ret0, _ := ret[0].([]io.SegmentReader)
+	return ret0
+}
+
+func (_mr *_MockdatabaseBufferRecorder) ColdStreams(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdStreams", arg0, arg1)
+}
+
+func (_m *MockdatabaseBuffer) ColdStreamsLen(blockStart time.Time) int {
+	ret := _m.ctrl.Call(_m, "ColdStreamsLen", blockStart)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+func (_mr *_MockdatabaseBufferRecorder) ColdStreamsLen(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdStreamsLen", arg0)
+}
+
+func (_m *MockdatabaseBuffer) DrainCold(blockStart time.Time) (block.DatabaseBlock, bool) {
+	ret := _m.ctrl.Call(_m, "DrainCold", blockStart)
+	ret0, _ := ret[0].(block.DatabaseBlock)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+func (_mr *_MockdatabaseBufferRecorder) DrainCold(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "DrainCold", arg0)
+}
+
 func (_m *MockdatabaseBuffer) Reset() {
 	_m.ctrl.Call(_m, "Reset")
 }
@@ -101,6 +101,84 @@ func TestBufferWriteTooPast(t *testing.T) {
 	assert.True(t, xerrors.IsInvalidParams(err))
 }
 
+func TestBufferWriteCold(t *testing.T) {
+	opts := newBufferTestOptions().SetColdWritesEnabled(true)
+	rops := opts.RetentionOptions()
+	curr := time.Now().Truncate(rops.BlockSize())
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil, opts).(*dbBuffer)
+
+	coldStart := curr.Add(-3 * rops.BlockSize())
+	data := []value{
+		{coldStart.Add(secs(1)), 1, xtime.Second, nil},
+		{coldStart.Add(secs(2)), 2, xtime.Second, nil},
+	}
+	for _, v := range data {
+		ctx := context.NewContext()
+		assert.NoError(t, buffer.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+		ctx.Close()
+	}
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// Writes older than the retention period are still rejected
+	err := buffer.Write(ctx, curr.Add(-rops.RetentionPeriod()).Add(-rops.BlockSize()), 1, xtime.Second, nil)
+	assert.Error(t, err)
+	assert.True(t, xerrors.IsInvalidParams(err))
+
+	assert.False(t, buffer.IsEmpty())
+	assert.Equal(t, 0, len(buffer.ReadEncoded(ctx, timeZero, timeDistantFuture)))
+	min, max := buffer.ColdMinMax()
+	assert.Equal(t, coldStart, min)
+	assert.Equal(t, coldStart, max)
+	assert.True(t, buffer.ColdStreamsLen(coldStart) > 0)
+	assertValuesEqual(t, data, [][]xio.SegmentReader{buffer.ColdStreams(ctx, coldStart)}, opts)
+
+	bl, ok := buffer.DrainCold(coldStart)
+	require.True(t, ok)
+	assert.Equal(t, coldStart, bl.StartTime())
+	stream, err := bl.Stream(ctx)
+	require.NoError(t, err)
+	assertValuesEqual(t, data, [][]xio.SegmentReader{{stream}}, opts)
+
+	assert.True(t, buffer.IsEmpty())
+	_, ok = buffer.DrainCold(coldStart)
+	assert.False(t, ok)
+
+	// Cold writes are discarded once their block expires
+	assert.NoError(t, buffer.Write(ctx, data[0].timestamp, 1, xtime.Second, nil))
+	curr = curr.Add(rops.RetentionPeriod())
+	buffer.DrainAndReset()
+	min, _ = buffer.ColdMinMax()
+	assert.True(t, min.IsZero())
+}
+
+func TestBufferWriteColdToUndrainedBucket(t *testing.T) {
+	opts := newBufferTestOptions().SetColdWritesEnabled(true)
+	rops := opts.RetentionOptions()
+	blockStart := time.Now().Truncate(rops.BlockSize())
+	curr := blockStart.Add(mins(1))
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	buffer := newDatabaseBuffer(nil, opts).(*dbBuffer)
+
+	// The previous block has not yet drained so takes the write
+	data := []value{
+		{blockStart.Add(-rops.BlockSize()).Add(secs(1)), 1, xtime.Second, nil},
+	}
+	ctx := context.NewContext()
+	defer ctx.Close()
+	assert.NoError(t, buffer.Write(ctx, data[0].timestamp, data[0].value, data[0].unit, data[0].annotation))
+
+	min, _ := buffer.ColdMinMax()
+	assert.True(t, min.IsZero())
+	assertValuesEqual(t, data, buffer.ReadEncoded(ctx, timeZero, timeDistantFuture), opts)
+}
+
 func TestBufferWriteRead(t *testing.T) {
 	opts := newBufferTestOptions()
 	rops := opts.RetentionOptions()
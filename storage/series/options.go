@@ -41,6 +41,7 @@ type options struct {
 	multiReaderIteratorPool       encoding.MultiReaderIteratorPool
 	fetchBlockMetadataResultsPool block.FetchBlockMetadataResultsPool
 	identifierPool                ts.IdentifierPool
+	coldWritesEnabled             bool
 }
 
 // NewOptions creates new database series options
@@ -153,3 +154,13 @@ func (o *options) SetIdentifierPool(value ts.IdentifierPool) Options {
 func (o *options) IdentifierPool() ts.IdentifierPool {
 	return o.identifierPool
 }
+
+func (o *options) SetColdWritesEnabled(value bool) Options {
+	opts := *o
+	opts.coldWritesEnabled = value
+	return &opts
+}
+
+func (o *options) ColdWritesEnabled() bool {
+	return o.coldWritesEnabled
+}
@@ -23,6 +23,7 @@ package series
 import (
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -262,26 +263,43 @@ func (s *dbSeries) ReadEncoded(
 	s.RLock()
 	defer s.RUnlock()
 
+	minTime, maxTime := s.buffer.ColdMinMax()
 	if s.blocks.Len() > 0 {
+		if minTime.IsZero() || s.blocks.MinTime().Before(minTime) {
+			minTime = s.blocks.MinTime()
+		}
+		if maxTime.IsZero() || s.blocks.MaxTime().After(maxTime) {
+			maxTime = s.blocks.MaxTime()
+		}
+	}
+
+	if !minTime.IsZero() {
 		// Squeeze the lookup window by what's available to make range queries like [0, infinity) possible
-		if s.blocks.MinTime().After(alignedStart) {
-			alignedStart = s.blocks.MinTime()
+		if minTime.After(alignedStart) {
+			alignedStart = minTime
 		}
-		if s.blocks.MaxTime().Before(alignedEnd) {
-			alignedEnd = s.blocks.MaxTime()
+		if maxTime.Before(alignedEnd) {
+			alignedEnd = maxTime
 		}
 		for blockAt := alignedStart; !blockAt.After(alignedEnd); blockAt = blockAt.Add(blockSize) {
+			var streams []xio.SegmentReader
 			if block, ok := s.blocks.BlockAt(blockAt); ok {
 				stream, err := block.Stream(ctx)
 				if err != nil {
 					return nil, err
 				}
 				if stream != nil {
-					results = append(results, []xio.SegmentReader{stream})
+					streams = append(streams, stream)
 					// NB(r): Mark this block as read now
 					block.SetLastReadTime(now)
 				}
 			}
+			// Cold writes are read together with the block they belong to
+			// so that the datapoints of the block are merged in order
+			streams = append(streams, s.buffer.ColdStreams(ctx, blockAt)...)
+			if len(streams) > 0 {
+				results = append(results, streams)
+			}
 		}
 	}
 
@@ -300,6 +318,7 @@ func (s *dbSeries) FetchBlocks(ctx context.Context, starts []time.Time) []block.
 	defer s.RUnlock()
 
 	for _, start := range starts {
+		coldStreams := s.buffer.ColdStreams(ctx, start)
 		if b, exists := s.blocks.BlockAt(start); exists {
 			stream, err := b.Stream(ctx)
 			if err != nil {
@@ -307,12 +326,21 @@ func (s *dbSeries) FetchBlocks(ctx context.Context, starts []time.Time) []block.
 					fmt.Errorf("unable to retrieve block stream for series %s time %v: %v",
 						s.id.String(), start, err), nil)
 				res = append(res, r)
-			} else if stream != nil {
+				continue
+			} else if stream != nil && len(coldStreams) == 0 {
 				checksum := b.Checksum()
 				r := block.NewFetchBlockResult(start, []xio.SegmentReader{stream}, nil, &checksum)
 				res = append(res, r)
+			} else if stream != nil {
+				// The block checksum does not cover the cold writes
+				streams := append([]xio.SegmentReader{stream}, coldStreams...)
+				res = append(res, block.NewFetchBlockResult(start, streams, nil, nil))
+				continue
 			}
 		}
+		if len(coldStreams) > 0 {
+			res = append(res, block.NewFetchBlockResult(start, coldStreams, nil, nil))
+		}
 	}
 
 	if !s.buffer.IsEmpty() {
@@ -348,10 +376,12 @@ func (s *dbSeries) FetchBlocksMetadata(
 			checksum *uint32
 			lastRead time.Time
 		)
+		coldLen := s.buffer.ColdStreamsLen(t)
 		if opts.IncludeSizes {
-			size = int64(b.Len())
+			size = int64(b.Len() + coldLen)
 		}
-		if opts.IncludeChecksums {
+		// The block checksum does not cover the cold writes
+		if opts.IncludeChecksums && coldLen == 0 {
 			v := b.Checksum()
 			checksum = &v
 		}
@@ -366,6 +396,31 @@ func (s *dbSeries) FetchBlocksMetadata(
 		})
 	}
 
+	// Iterate over the cold writes for blocks not held by the series
+	coldMin, coldMax := s.buffer.ColdMinMax()
+	if !coldMin.IsZero() {
+		for t := coldMin; !t.After(coldMax); t = t.Add(blockSize) {
+			if !start.Before(t.Add(blockSize)) || !t.Before(end) {
+				continue
+			}
+			if _, exists := blocks[t]; exists {
+				continue
+			}
+			coldLen := s.buffer.ColdStreamsLen(t)
+			if coldLen == 0 {
+				continue
+			}
+			var size int64
+			if opts.IncludeSizes {
+				size = int64(coldLen)
+			}
+			res.Add(block.FetchBlockMetadataResult{
+				Start: t,
+				Size:  size,
+			})
+		}
+	}
+
 	// Iterate over the encoders in the database buffer
 	if !s.buffer.IsEmpty() {
 		bufferResults := s.buffer.FetchBlocksMetadata(ctx, start, end, opts)
@@ -482,6 +537,114 @@ func (s *dbSeries) newBootstrapBlockError(
 	return xerrors.NewRenamedError(err, renamed)
 }
 
+// NB(r): Loading a block that overlaps an existing block will read the
+// existing block while holding the series lock, which may mean retrieving
+// it from disk. This is acceptable since blocks are only loaded infrequently,
+// e.g. when a repair finds a block differs from the block held by peers.
+func (s *dbSeries) Load(ctx context.Context, blocks block.DatabaseSeriesBlocks) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.bs != bootstrapped {
+		return errSeriesNotBootstrapped
+	}
+
+	multiErr := xerrors.NewMultiError()
+	min, _ := s.buffer.MinMax()
+	for t, bl := range blocks.AllBlocks() {
+		// If the block falls within the buffer then we emplace it there
+		// and it will be merged when the buffer drains
+		if !t.Before(min) {
+			if err := s.buffer.Bootstrap(bl); err != nil {
+				multiErr = multiErr.Add(s.newLoadBlockError(bl, err))
+			}
+			continue
+		}
+
+		existingBlock, ok := s.blocks.BlockAt(t)
+		if !ok {
+			s.blocks.AddBlock(bl)
+			continue
+		}
+
+		// A block not yet retrieved from a corrupt fileset cannot be read
+		// to be merged, the loaded block replaces it
+		if !existingBlock.IsRetrieved() && s.blockRetriever != nil &&
+			s.blockRetriever.IsBlockCorrupt(t) {
+			s.blocks.AddBlock(bl)
+			existingBlock.Close()
+			continue
+		}
+
+		mergedBlock, err := s.mergeBlockEncoded(ctx, bl, existingBlock)
+		if err != nil {
+			multiErr = multiErr.Add(s.newLoadBlockError(bl, err))
+			bl.Close()
+			continue
+		}
+
+		s.blocks.AddBlock(mergedBlock)
+		existingBlock.Close()
+		bl.Close()
+	}
+
+	return multiErr.FinalError()
+}
+
+// mergeBlockEncoded eagerly merges two blocks into a new block rather than
+// lazily merging the streams so the resulting block has a checksum that
+// reflects the merged data.
+func (s *dbSeries) mergeBlockEncoded(
+	ctx context.Context,
+	a, b block.DatabaseBlock,
+) (block.DatabaseBlock, error) {
+	blockStart := a.StartTime()
+
+	readers := make([]io.Reader, 0, 2)
+	for _, bl := range []block.DatabaseBlock{a, b} {
+		stream, err := bl.Stream(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if stream != nil {
+			readers = append(readers, stream)
+		}
+	}
+
+	bopts := s.opts.DatabaseBlockOptions()
+	encoder := bopts.EncoderPool().Get()
+	encoder.Reset(blockStart, bopts.DatabaseBlockAllocSize())
+
+	iter := s.opts.MultiReaderIteratorPool().Get()
+	iter.Reset(readers)
+	defer iter.Close()
+
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return nil, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		encoder.Close()
+		return nil, err
+	}
+
+	mergedBlock := bopts.DatabaseBlockPool().Get()
+	mergedBlock.Reset(blockStart, encoder.Discard())
+	return mergedBlock, nil
+}
+
+func (s *dbSeries) newLoadBlockError(
+	b block.DatabaseBlock,
+	err error,
+) error {
+	msgFmt := "load series error occurred for %s block at %s: %v"
+	renamed := fmt.Errorf(msgFmt, s.id.String(), b.StartTime().String(), err)
+	return xerrors.NewRenamedError(err, renamed)
+}
+
 func (s *dbSeries) Flush(
 	ctx context.Context,
 	blockStart time.Time,
@@ -518,6 +681,37 @@ func (s *dbSeries) Flush(
 	return persistFn(s.id, segment, b.Checksum())
 }
 
+// NB: Merging the cold writes reads the existing block while holding the
+// series lock, which may mean retrieving it from disk. This is acceptable
+// since cold writes are only merged once per cold flush of a block.
+func (s *dbSeries) MergeColdWrites(ctx context.Context, blockStart time.Time) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	coldBlock, ok := s.buffer.DrainCold(blockStart)
+	if !ok {
+		return false, nil
+	}
+
+	existingBlock, ok := s.blocks.BlockAt(blockStart)
+	if !ok {
+		s.blocks.AddBlock(coldBlock)
+		return true, nil
+	}
+
+	mergedBlock, err := s.mergeBlockEncoded(ctx, coldBlock, existingBlock)
+	if err != nil {
+		// Keep the cold writes readable by lazily merging the streams
+		s.mergeBlock(s.blocks, coldBlock)
+		return true, err
+	}
+
+	s.blocks.AddBlock(mergedBlock)
+	existingBlock.Close()
+	coldBlock.Close()
+	return true, nil
+}
+
 func (s *dbSeries) Close() {
 	s.Lock()
 	defer s.Unlock()
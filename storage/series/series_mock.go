@@ -135,6 +135,27 @@ func (_mr *_MockDatabaseSeriesRecorder) IsEmpty() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "IsEmpty")
 }
 
+func (_m *MockDatabaseSeries) Load(_param0 context.Context, _param1 block.DatabaseSeriesBlocks) error {
+	ret := _m.ctrl.Call(_m, "Load", _param0, _param1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseSeriesRecorder) Load(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Load", arg0, arg1)
+}
+
+func (_m *MockDatabaseSeries) MergeColdWrites(_param0 context.Context, _param1 time0.Time) (bool, error) {
+	ret := _m.ctrl.Call(_m, "MergeColdWrites", _param0, _param1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockDatabaseSeriesRecorder) MergeColdWrites(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MergeColdWrites", arg0, arg1)
+}
+
 func (_m *MockDatabaseSeries) ReadEncoded(_param0 context.Context, _param1 time0.Time, _param2 time0.Time) ([][]io.SegmentReader, error) {
 	ret := _m.ctrl.Call(_m, "ReadEncoded", _param0, _param1, _param2)
 	ret0, _ := ret[0].([][]io.SegmentReader)
@@ -333,6 +333,135 @@ func TestSeriesBootstrapWithError(t *testing.T) {
 	require.Equal(t, 1, series.blocks.Len())
 }
 
+func newSeriesTestBlock(
+	t *testing.T,
+	start time.Time,
+	values []value,
+	opts Options,
+) block.DatabaseBlock {
+	encoder := opts.EncoderPool().Get()
+	encoder.Reset(start, 0)
+	for _, v := range values {
+		dp := ts.Datapoint{Timestamp: v.timestamp, Value: v.value}
+		require.NoError(t, encoder.Encode(dp, v.unit, v.annotation))
+	}
+	bl := opts.DatabaseBlockOptions().DatabaseBlockPool().Get()
+	bl.Reset(start, encoder.Discard())
+	return bl
+}
+
+func TestSeriesLoadMergesExistingBlock(t *testing.T) {
+	opts := newSeriesTestOptions()
+	blockSize := opts.RetentionOptions().BlockSize()
+	curr := time.Now().Truncate(blockSize)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ts.StringID("foo"), opts).(*dbSeries)
+	assert.NoError(t, series.Bootstrap(nil))
+
+	blockStart := curr.Add(-4 * blockSize)
+	existing := []value{
+		{blockStart, 1, xtime.Second, nil},
+		{blockStart.Add(mins(1)), 3, xtime.Second, nil},
+	}
+	loaded := []value{
+		{blockStart.Add(30 * time.Second), 2, xtime.Second, nil},
+		{blockStart.Add(mins(1.5)), 4, xtime.Second, nil},
+	}
+	series.blocks.AddBlock(newSeriesTestBlock(t, blockStart, existing, opts))
+
+	blocks := block.NewDatabaseSeriesBlocks(0, opts.DatabaseBlockOptions())
+	blocks.AddBlock(newSeriesTestBlock(t, blockStart, loaded, opts))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	require.NoError(t, series.Load(ctx, blocks))
+	require.Equal(t, 1, series.blocks.Len())
+
+	expected := []value{existing[0], loaded[0], existing[1], loaded[1]}
+	results, err := series.ReadEncoded(ctx, blockStart, blockStart.Add(blockSize))
+	require.NoError(t, err)
+	assertValuesEqual(t, expected, results, opts)
+
+	// Ensure the checksum reflects the merged data
+	merged, ok := series.blocks.BlockAt(blockStart)
+	require.True(t, ok)
+	stream, err := merged.Stream(ctx)
+	require.NoError(t, err)
+	segment, err := stream.Segment()
+	require.NoError(t, err)
+	require.Equal(t, digest.SegmentChecksum(segment), merged.Checksum())
+}
+
+func TestSeriesMergeColdWrites(t *testing.T) {
+	opts := newSeriesTestOptions().SetColdWritesEnabled(true)
+	blockSize := opts.RetentionOptions().BlockSize()
+	curr := time.Now().Truncate(blockSize)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return curr
+	}))
+	series := NewDatabaseSeries(ts.StringID("foo"), opts).(*dbSeries)
+	assert.NoError(t, series.Bootstrap(nil))
+
+	blockStart := curr.Add(-4 * blockSize)
+	existing := []value{
+		{blockStart, 1, xtime.Second, nil},
+		{blockStart.Add(mins(1)), 3, xtime.Second, nil},
+	}
+	cold := []value{
+		{blockStart.Add(-blockSize), 0, xtime.Second, nil},
+		{blockStart.Add(30 * time.Second), 2, xtime.Second, nil},
+		{blockStart.Add(mins(1.5)), 4, xtime.Second, nil},
+	}
+	series.blocks.AddBlock(newSeriesTestBlock(t, blockStart, existing, opts))
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	for _, v := range cold {
+		require.NoError(t, series.Write(ctx, v.timestamp, v.value, v.unit, v.annotation))
+	}
+
+	// Cold writes are readable in order before being merged
+	expected := []value{cold[0], existing[0], cold[1], existing[1], cold[2]}
+	results, err := series.ReadEncoded(ctx, timeZero, timeDistantFuture)
+	require.NoError(t, err)
+	assertValuesEqual(t, expected, results, opts)
+
+	merged, err := series.MergeColdWrites(ctx, blockStart)
+	require.NoError(t, err)
+	require.True(t, merged)
+	merged, err = series.MergeColdWrites(ctx, blockStart)
+	require.NoError(t, err)
+	require.False(t, merged)
+	merged, err = series.MergeColdWrites(ctx, blockStart.Add(-blockSize))
+	require.NoError(t, err)
+	require.True(t, merged)
+	require.Equal(t, 2, series.blocks.Len())
+
+	results, err = series.ReadEncoded(ctx, timeZero, timeDistantFuture)
+	require.NoError(t, err)
+	assertValuesEqual(t, expected, results, opts)
+
+	// Ensure the checksum reflects the merged data
+	mergedBlock, ok := series.blocks.BlockAt(blockStart)
+	require.True(t, ok)
+	stream, err := mergedBlock.Stream(ctx)
+	require.NoError(t, err)
+	segment, err := stream.Segment()
+	require.NoError(t, err)
+	require.Equal(t, digest.SegmentChecksum(segment), mergedBlock.Checksum())
+}
+
+func TestSeriesLoadNotBootstrapped(t *testing.T) {
+	opts := newSeriesTestOptions()
+	series := NewDatabaseSeries(ts.StringID("foo"), opts).(*dbSeries)
+	blocks := block.NewDatabaseSeriesBlocks(0, opts.DatabaseBlockOptions())
+	require.Equal(t, errSeriesNotBootstrapped, series.Load(context.NewContext(), blocks))
+}
+
 func TestSeriesFetchBlocks(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -358,6 +487,9 @@ func TestSeriesFetchBlocks(t *testing.T) {
 
 	// Set up the buffer
 	buffer := NewMockdatabaseBuffer(ctrl)
+	for _, start := range starts {
+		buffer.EXPECT().ColdStreams(ctx, start).Return(nil)
+	}
 	buffer.EXPECT().IsEmpty().Return(false)
 	buffer.EXPECT().FetchBlocks(ctx, starts).Return([]block.FetchBlockResult{block.NewFetchBlockResult(starts[2], nil, nil, nil)})
 
@@ -421,6 +553,8 @@ func TestSeriesFetchBlocksMetadata(t *testing.T) {
 		IncludeChecksums: true,
 		IncludeLastRead:  true,
 	}
+	buffer.EXPECT().ColdStreamsLen(starts[0]).Return(0)
+	buffer.EXPECT().ColdMinMax().Return(time.Time{}, time.Time{})
 	buffer.EXPECT().IsEmpty().Return(false)
 	buffer.EXPECT().
 		FetchBlocksMetadata(ctx, start, end, fetchOpts).
@@ -77,9 +77,18 @@ type DatabaseSeries interface {
 	// Bootstrap merges the raw series bootstrapped along with any buffered data
 	Bootstrap(blocks block.DatabaseSeriesBlocks) error
 
+	// Load merges blocks retrieved from elsewhere, such as from peers during
+	// a repair, with any existing data for the series, the series takes
+	// ownership of the blocks
+	Load(ctx context.Context, blocks block.DatabaseSeriesBlocks) error
+
 	// Flush flushes the data blocks of this series for a given start time
 	Flush(ctx context.Context, blockStart time.Time, persistFn persist.Fn) error
 
+	// MergeColdWrites merges the cold writes buffered for a block into the
+	// data block, returning whether there were any cold writes to merge
+	MergeColdWrites(ctx context.Context, blockStart time.Time) (bool, error)
+
 	// Close will close the series and if pooled returned to the pool
 	Close()
 
@@ -99,6 +108,10 @@ type QueryableBlockRetriever interface {
 	// IsBlockRetrievable returns whether a block is retrievable
 	// for a given block start time
 	IsBlockRetrievable(blockStart time.Time) bool
+
+	// IsBlockCorrupt returns whether the persisted block for a given
+	// block start time is corrupt and must not be read
+	IsBlockCorrupt(blockStart time.Time) bool
 }
 
 // TickStatus is the status of a series for a given tick
@@ -191,4 +204,10 @@ type Options interface {
 
 	// IdentifierPool returns the identifierPool
 	IdentifierPool() ts.IdentifierPool
+
+	// SetColdWritesEnabled sets whether writes outside the buffer window are accepted
+	SetColdWritesEnabled(value bool) Options
+
+	// ColdWritesEnabled returns whether writes outside the buffer window are accepted
+	ColdWritesEnabled() bool
 }
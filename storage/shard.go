@@ -24,24 +24,30 @@ import (
 	"container/list"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/m3db/m3db/clock"
 	"github.com/m3db/m3db/context"
+	"github.com/m3db/m3db/digest"
 	"github.com/m3db/m3db/persist"
 	"github.com/m3db/m3db/persist/fs"
 	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/ratelimit"
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/storage/series"
 	"github.com/m3db/m3db/ts"
 	xio "github.com/m3db/m3db/x/io"
+	"github.com/m3db/m3x/checked"
 	xclose "github.com/m3db/m3x/close"
 	xerrors "github.com/m3db/m3x/errors"
 	xtime "github.com/m3db/m3x/time"
@@ -63,6 +69,23 @@ var (
 
 type filesetBeforeFn func(filePathPrefix string, namespace ts.ID, shardID uint32, t time.Time) ([]string, error)
 
+type supersededFilesetsFn func(filePathPrefix string, namespace ts.ID, shardID uint32) ([]string, error)
+
+type supersededTierFilesetsFn func(filePathPrefixes []string, namespace ts.ID, shardID uint32) ([]string, error)
+
+type filesetBlockStartsFn func(filePathPrefix string, namespace ts.ID, shardID uint32) ([]time.Time, error)
+
+type moveFilesetFn func(
+	srcFilePathPrefix string,
+	dstFilePathPrefix string,
+	namespace ts.ID,
+	shardID uint32,
+	blockStart time.Time,
+	newFileMode os.FileMode,
+	newDirectoryMode os.FileMode,
+	rateLimitOpts ratelimit.Options,
+) error
+
 type tickPolicy int
 
 const (
@@ -81,30 +104,35 @@ const (
 type dbShard struct {
 	sync.RWMutex
 	block.DatabaseBlockRetriever
-	opts                    Options
-	nowFn                   clock.NowFn
-	state                   dbShardState
-	namespace               ts.ID
-	seriesBlockRetriever    series.QueryableBlockRetriever
-	shard                   uint32
-	increasingIndex         increasingIndex
-	seriesPool              series.DatabaseSeriesPool
-	writeCommitLogFn        writeCommitLogFn
-	insertQueue             *dbShardInsertQueue
-	lookup                  map[ts.Hash]*list.Element
-	list                    *list.List
-	bs                      bootstrapState
-	newSeriesBootstrapped   bool
-	filesetBeforeFn         filesetBeforeFn
-	deleteFilesFn           deleteFilesFn
-	tickSleepIfAheadEvery   int
-	sleepFn                 func(time.Duration)
-	identifierPool          ts.IdentifierPool
-	contextPool             context.Pool
-	flushState              shardFlushState
-	runtimeOptsListenCloser xclose.SimpleCloser
-	currRuntimeOptions      dbShardRuntimeOptions
-	metrics                 dbShardMetrics
+	opts                     Options
+	nowFn                    clock.NowFn
+	state                    dbShardState
+	namespace                ts.ID
+	seriesBlockRetriever     series.QueryableBlockRetriever
+	shard                    uint32
+	increasingIndex          increasingIndex
+	seriesPool               series.DatabaseSeriesPool
+	writeCommitLogFn         writeCommitLogFn
+	insertQueue              *dbShardInsertQueue
+	lookup                   map[ts.Hash]*list.Element
+	list                     *list.List
+	bs                       bootstrapState
+	newSeriesBootstrapped    bool
+	filesetBeforeFn          filesetBeforeFn
+	supersededFilesetsFn     supersededFilesetsFn
+	supersededTierFilesetsFn supersededTierFilesetsFn
+	filesetBlockStartsFn     filesetBlockStartsFn
+	moveFilesetFn            moveFilesetFn
+	deleteFilesFn            deleteFilesFn
+	tickSleepIfAheadEvery    int
+	sleepFn                  func(time.Duration)
+	identifierPool           ts.IdentifierPool
+	contextPool              context.Pool
+	flushState               shardFlushState
+	tombstones               *shardTombstones
+	runtimeOptsListenCloser  xclose.SimpleCloser
+	currRuntimeOptions       dbShardRuntimeOptions
+	metrics                  dbShardMetrics
 }
 
 type dbShardRuntimeOptions struct {
@@ -152,11 +180,23 @@ type dbShardEntryWorkFn func(entry *dbShardEntry) bool
 type shardFlushState struct {
 	sync.RWMutex
 	statesByTime map[time.Time]fileOpState
+	// rewritesByTime are block starts already flushed that
+	// have since had data loaded and need to be flushed again
+	rewritesByTime map[time.Time]struct{}
+	// coldByTime are block starts that have received cold writes
+	// not yet merged into their flushed filesets
+	coldByTime map[time.Time]struct{}
+	// corruptByTime are block starts whose flushed filesets failed
+	// verification and are not read until they are rewritten
+	corruptByTime map[time.Time]struct{}
 }
 
 func newShardFlushState() shardFlushState {
 	return shardFlushState{
-		statesByTime: make(map[time.Time]fileOpState),
+		statesByTime:   make(map[time.Time]fileOpState),
+		rewritesByTime: make(map[time.Time]struct{}),
+		coldByTime:     make(map[time.Time]struct{}),
+		corruptByTime:  make(map[time.Time]struct{}),
 	}
 }
 
@@ -173,24 +213,29 @@ func newDatabaseShard(
 		SubScope("dbshard")
 
 	d := &dbShard{
-		opts:                  opts,
-		nowFn:                 opts.ClockOptions().NowFn(),
-		state:                 dbShardStateOpen,
-		namespace:             namespace,
-		shard:                 shard,
-		increasingIndex:       increasingIndex,
-		seriesPool:            opts.DatabaseSeriesPool(),
-		writeCommitLogFn:      writeCommitLogFn,
-		lookup:                make(map[ts.Hash]*list.Element),
-		list:                  list.New(),
-		filesetBeforeFn:       fs.FilesetBefore,
-		deleteFilesFn:         fs.DeleteFiles,
-		tickSleepIfAheadEvery: defaultTickSleepIfAheadEvery,
-		sleepFn:               time.Sleep,
-		identifierPool:        opts.IdentifierPool(),
-		contextPool:           opts.ContextPool(),
-		flushState:            newShardFlushState(),
-		metrics:               newDbShardMetrics(scope),
+		opts:                     opts,
+		nowFn:                    opts.ClockOptions().NowFn(),
+		state:                    dbShardStateOpen,
+		namespace:                namespace,
+		shard:                    shard,
+		increasingIndex:          increasingIndex,
+		seriesPool:               opts.DatabaseSeriesPool(),
+		writeCommitLogFn:         writeCommitLogFn,
+		lookup:                   make(map[ts.Hash]*list.Element),
+		list:                     list.New(),
+		filesetBeforeFn:          fs.FilesetBefore,
+		supersededFilesetsFn:     fs.SupersededFilesets,
+		supersededTierFilesetsFn: fs.SupersededTierFilesets,
+		filesetBlockStartsFn:     fs.FilesetBlockStarts,
+		moveFilesetFn:            fs.MoveFileset,
+		deleteFilesFn:            fs.DeleteFiles,
+		tickSleepIfAheadEvery:    defaultTickSleepIfAheadEvery,
+		sleepFn:                  time.Sleep,
+		identifierPool:           opts.IdentifierPool(),
+		contextPool:              opts.ContextPool(),
+		flushState:               newShardFlushState(),
+		tombstones:               newShardTombstones(namespace, shard, opts),
+		metrics:                  newDbShardMetrics(scope),
 	}
 	d.insertQueue = newDbShardInsertQueue(d.insertSeriesEntries, scope)
 	d.insertQueue.Start()
@@ -244,6 +289,9 @@ func (s *dbShard) Stream(
 
 // IsBlockRetrievable implements series.SeriesBlockRetriever
 func (s *dbShard) IsBlockRetrievable(blockStart time.Time) bool {
+	if s.IsBlockCorrupt(blockStart) {
+		return false
+	}
 	flushState := s.FlushState(blockStart)
 	switch flushState.Status {
 	case fileOpNotStarted, fileOpInProgress, fileOpFailed:
@@ -466,6 +514,30 @@ func (s *dbShard) Write(
 	value float64,
 	unit xtime.Unit,
 	annotation []byte,
+) error {
+	return s.write(ctx, id, nil, timestamp, value, unit, annotation)
+}
+
+func (s *dbShard) WriteTagged(
+	ctx context.Context,
+	id ts.ID,
+	tags ts.Tags,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
+) error {
+	return s.write(ctx, id, tags, timestamp, value, unit, annotation)
+}
+
+func (s *dbShard) write(
+	ctx context.Context,
+	id ts.ID,
+	tags ts.Tags,
+	timestamp time.Time,
+	value float64,
+	unit xtime.Unit,
+	annotation []byte,
 ) error {
 	// Prepare write
 	entry, opts, err := s.tryRetrieveWritableSeries(id)
@@ -536,12 +608,28 @@ func (s *dbShard) Write(
 		commitLogSeriesUniqueIndex = result.uniqueIndex
 	}
 
+	var (
+		ropts      = s.opts.RetentionOptions()
+		blockSize  = ropts.BlockSize()
+		blockStart = timestamp.Truncate(blockSize)
+		now        = s.nowFn()
+	)
+	if !now.Add(-ropts.BufferPast()).Before(timestamp) {
+		// Cold writes are merged into the flushed block by the next cold flush
+		s.markFlushStateColdWrites(blockStart)
+	} else if !blockStart.Add(blockSize).After(now) &&
+		s.FlushState(blockStart).Status == fileOpSuccess {
+		// Blocks already flushed that receive late writes need to be rewritten
+		s.markFlushStateNeedsRewrite(blockStart)
+	}
+
 	// Write commit log
 	series := commitlog.Series{
 		UniqueIndex: commitLogSeriesUniqueIndex,
 		Namespace:   s.namespace,
 		ID:          commitLogSeriesID,
 		Shard:       s.shard,
+		Tags:        tags,
 	}
 
 	datapoint := ts.Datapoint{
@@ -566,7 +654,43 @@ func (s *dbShard) ReadEncoded(
 	if err != nil {
 		return nil, err
 	}
-	return entry.series.ReadEncoded(ctx, start, end)
+	encoded, err := entry.series.ReadEncoded(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterTombstonedEncoded(ctx, id, start, end, encoded)
+}
+
+// filterTombstonedEncoded removes the deleted datapoints of a series from
+// the streams read for it.
+func (s *dbShard) filterTombstonedEncoded(
+	ctx context.Context,
+	id ts.ID,
+	start, end time.Time,
+	encoded [][]xio.SegmentReader,
+) ([][]xio.SegmentReader, error) {
+	ranges, err := s.tombstones.Ranges(id)
+	if err != nil {
+		return nil, err
+	}
+	if !overlapsTombstones(ranges, start, end) {
+		return encoded, nil
+	}
+
+	filtered := make([][]xio.SegmentReader, 0, len(encoded))
+	for _, readers := range encoded {
+		segment, ok, err := filterTombstoned(s.opts, toReaders(readers), ranges)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		reader := xio.NewSegmentReader(segment)
+		ctx.RegisterFinalizer(reader)
+		filtered = append(filtered, []xio.SegmentReader{reader})
+	}
+	return filtered, nil
 }
 
 // lookupEntryWithLock returns the entry for a given id while holding a read lock or a write lock.
@@ -715,7 +839,49 @@ func (s *dbShard) FetchBlocks(
 	if err != nil {
 		return nil, err
 	}
-	return entry.series.FetchBlocks(ctx, starts), nil
+	return s.filterTombstonedFetchBlocks(ctx, id, entry.series.FetchBlocks(ctx, starts))
+}
+
+// filterTombstonedFetchBlocks removes the deleted datapoints of a series from
+// the blocks fetched for it, omitting blocks with no datapoints remaining.
+func (s *dbShard) filterTombstonedFetchBlocks(
+	ctx context.Context,
+	id ts.ID,
+	results []block.FetchBlockResult,
+) ([]block.FetchBlockResult, error) {
+	ranges, err := s.tombstones.Ranges(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranges) == 0 {
+		return results, nil
+	}
+
+	blockSize := s.opts.RetentionOptions().BlockSize()
+	filtered := make([]block.FetchBlockResult, 0, len(results))
+	for _, result := range results {
+		start := result.Start()
+		if result.Err() != nil || !overlapsTombstones(ranges, start, start.Add(blockSize)) {
+			filtered = append(filtered, result)
+			continue
+		}
+		segment, ok, err := filterTombstoned(s.opts, toReaders(result.Readers()), ranges)
+		if err != nil {
+			filtered = append(filtered, block.NewFetchBlockResult(start, nil,
+				fmt.Errorf("unable to filter deleted data for series %s time %v: %v",
+					id.String(), start, err), nil))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		reader := xio.NewSegmentReader(segment)
+		ctx.RegisterFinalizer(reader)
+		checksum := digest.SegmentChecksum(segment)
+		filtered = append(filtered, block.NewFetchBlockResult(start,
+			[]xio.SegmentReader{reader}, nil, &checksum))
+	}
+	return filtered, nil
 }
 
 func (s *dbShard) FetchBlocksMetadata(
@@ -728,6 +894,7 @@ func (s *dbShard) FetchBlocksMetadata(
 	var (
 		res            = s.opts.FetchBlocksMetadataResultsPool().Get()
 		tmpCtx         = context.NewContext()
+		corrupt        = s.corruptBlockStarts()
 		pNextPageToken *int64
 	)
 
@@ -750,6 +917,12 @@ func (s *dbShard) FetchBlocksMetadata(
 		metadata := entry.series.FetchBlocksMetadata(tmpCtx, start, end, opts)
 		tmpCtx.BlockingClose()
 
+		// Omit corrupt blocks so they are repaired from peers and
+		// peers do not fetch them from this shard
+		if len(corrupt) > 0 {
+			filterCorruptBlocksMetadata(metadata.Blocks, corrupt)
+		}
+
 		// If the blocksMetadata is empty, the series have no data within the specified
 		// time range so we don't return it to the client
 		if len(metadata.Blocks.Results()) == 0 {
@@ -786,6 +959,9 @@ func (s *dbShard) Bootstrap(
 
 	multiErr := xerrors.NewMultiError()
 	for _, dbBlocks := range bootstrappedSeries {
+		// Deleted data is still filtered when read if this fails
+		multiErr = multiErr.Add(s.filterTombstonedBlocks(dbBlocks.ID, dbBlocks.Blocks))
+
 		entry, err := s.writableSeries(dbBlocks.ID)
 		if err != nil {
 			multiErr = multiErr.Add(err)
@@ -817,6 +993,14 @@ func (s *dbShard) Bootstrap(
 		return true
 	})
 
+	// Rewrite any blocks with data deleted before a restart as the deleted
+	// data may not have been compacted out of their filesets yet
+	deleted, err := s.tombstones.All()
+	multiErr = multiErr.Add(err)
+	for _, r := range deleted {
+		s.markFlushStateNeedsCompaction(r.Start, r.End)
+	}
+
 	s.Lock()
 	s.bs = bootstrapped
 	s.Unlock()
@@ -824,6 +1008,56 @@ func (s *dbShard) Bootstrap(
 	return multiErr.FinalError()
 }
 
+func (s *dbShard) Load(
+	series map[ts.Hash]result.DatabaseSeriesBlocks,
+) error {
+	s.RLock()
+	if s.bs != bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToLoad
+	}
+	s.RUnlock()
+
+	var (
+		multiErr    = xerrors.NewMultiError()
+		blockStarts = make(map[time.Time]struct{})
+		tmpCtx      = context.NewContext()
+	)
+	for _, dbBlocks := range series {
+		for t := range dbBlocks.Blocks.AllBlocks() {
+			blockStarts[t] = struct{}{}
+		}
+
+		// Make sure data deleted from this shard is not loaded back from peers
+		if err := s.filterTombstonedBlocks(dbBlocks.ID, dbBlocks.Blocks); err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		entry, err := s.writableSeries(dbBlocks.ID)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			continue
+		}
+
+		// Use a temporary context here so the stream readers used to merge
+		// blocks can be returned to pool after we finish loading the series
+		tmpCtx.Reset()
+		err = entry.series.Load(tmpCtx, dbBlocks.Blocks)
+		tmpCtx.BlockingClose()
+		entry.decrementWriterCount()
+		multiErr = multiErr.Add(err)
+	}
+
+	// Any blocks already flushed need to be flushed again to persist the
+	// data that was loaded into them
+	for t := range blockStarts {
+		s.markFlushStateNeedsRewrite(t)
+	}
+
+	return multiErr.FinalError()
+}
+
 func (s *dbShard) Flush(
 	namespace ts.ID,
 	blockStart time.Time,
@@ -837,8 +1071,28 @@ func (s *dbShard) Flush(
 	}
 	s.RUnlock()
 
-	var multiErr xerrors.MultiError
-	prepared, err := flush.Prepare(namespace, s.ID(), blockStart)
+	var (
+		multiErr   xerrors.MultiError
+		prepared   persist.PreparedPersist
+		compaction *shardCompaction
+		err        error
+	)
+	if s.needsRewrite(blockStart) {
+		// Series with data only in the existing fileset are merged into the
+		// rewritten fileset by compacting the existing fileset, unless it is
+		// corrupt in which case the data loaded from peers replaces it
+		if !s.IsBlockCorrupt(blockStart) {
+			compaction, err = newShardCompaction(namespace, s.ID(), blockStart, s.opts)
+			if err != nil {
+				s.markFlushStateFail(blockStart)
+				return err
+			}
+			defer compaction.Close()
+		}
+		prepared, err = flush.PrepareRewrite(namespace, s.ID(), blockStart, s.opts.RetentionOptions())
+	} else {
+		prepared, err = flush.Prepare(namespace, s.ID(), blockStart, s.opts.RetentionOptions())
+	}
 	multiErr = multiErr.Add(err)
 
 	if prepared.Persist == nil {
@@ -854,18 +1108,27 @@ func (s *dbShard) Flush(
 	}
 
 	// If we encounter an error when persisting a series, we continue regardless.
+	persistFn := s.filterTombstonedPersistFn(blockStart, prepared.Persist)
+	flushFn := persistFn
+	if compaction != nil {
+		flushFn = compaction.PersistFn(persistFn)
+	}
 	tmpCtx := context.NewContext()
 	s.forEachShardEntry(func(entry *dbShardEntry) bool {
 		series := entry.series
 		// Use a temporary context here so the stream readers can be returned to
 		// pool after we finish fetching flushing the series
 		tmpCtx.Reset()
-		err := series.Flush(tmpCtx, blockStart, prepared.Persist)
+		err := series.Flush(tmpCtx, blockStart, flushFn)
 		tmpCtx.BlockingClose()
 		multiErr = multiErr.Add(err)
 		return true
 	})
 
+	if compaction != nil {
+		multiErr = multiErr.Add(compaction.PersistRemaining(persistFn))
+	}
+
 	if err := prepared.Close(); err != nil {
 		multiErr = multiErr.Add(err)
 	}
@@ -882,6 +1145,290 @@ func (s *dbShard) Flush(
 	return resultErr
 }
 
+func (s *dbShard) ColdFlush(
+	namespace ts.ID,
+	flush persist.Flush,
+) error {
+	// We don't flush data when the shard is still bootstrapping
+	s.RLock()
+	if s.bs != bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToFlush
+	}
+	s.RUnlock()
+
+	multiErr := xerrors.NewMultiError()
+	for _, blockStart := range s.coldFlushTimes() {
+		if err := s.coldFlush(namespace, blockStart, flush); err != nil {
+			detailedErr := fmt.Errorf("failed to cold flush block %v: %v", blockStart, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
+// coldFlush merges the cold writes of each series into the series block and
+// rewrites the fileset of the block with the merged data.
+func (s *dbShard) coldFlush(
+	namespace ts.ID,
+	blockStart time.Time,
+	flush persist.Flush,
+) error {
+	// Cold writes received from here on are merged by the next cold flush
+	s.unmarkFlushStateColdWrites(blockStart)
+
+	var (
+		multiErr xerrors.MultiError
+		merged   bool
+	)
+	tmpCtx := context.NewContext()
+	s.forEachShardEntry(func(entry *dbShardEntry) bool {
+		tmpCtx.Reset()
+		ok, err := entry.series.MergeColdWrites(tmpCtx, blockStart)
+		tmpCtx.BlockingClose()
+		merged = merged || ok
+		multiErr = multiErr.Add(err)
+		return true
+	})
+	if !merged {
+		return multiErr.FinalError()
+	}
+
+	// A failed rewrite remains marked as needing a rewrite and is retried by
+	// the next flush
+	s.markFlushStateNeedsRewrite(blockStart)
+	multiErr = multiErr.Add(s.Flush(namespace, blockStart, flush))
+	return multiErr.FinalError()
+}
+
+// filterTombstonedPersistFn returns a persist function that removes the
+// deleted datapoints of each series before persisting it, compacting
+// deleted data out of filesets as they are written.
+func (s *dbShard) filterTombstonedPersistFn(
+	blockStart time.Time,
+	persistFn persist.Fn,
+) persist.Fn {
+	blockEnd := blockStart.Add(s.opts.RetentionOptions().BlockSize())
+	return func(id ts.ID, segment ts.Segment, checksum uint32) error {
+		ranges, err := s.tombstones.Ranges(id)
+		if err != nil {
+			return err
+		}
+		if !overlapsTombstones(ranges, blockStart, blockEnd) {
+			return persistFn(id, segment, checksum)
+		}
+		readers := []io.Reader{xio.NewSegmentReader(segment)}
+		filtered, ok, err := filterTombstoned(s.opts, readers, ranges)
+		if err != nil || !ok {
+			return err
+		}
+		err = persistFn(id, filtered, digest.SegmentChecksum(filtered))
+		filtered.Finalize()
+		return err
+	}
+}
+
+// filterTombstonedBlocks removes the deleted datapoints of a series from the
+// blocks held in memory, blocks yet to be retrieved are filtered when read.
+func (s *dbShard) filterTombstonedBlocks(
+	id ts.ID,
+	blocks block.DatabaseSeriesBlocks,
+) error {
+	ranges, err := s.tombstones.Ranges(id)
+	if err != nil || len(ranges) == 0 {
+		return err
+	}
+
+	var (
+		blockOpts = s.opts.DatabaseBlockOptions()
+		blockSize = s.opts.RetentionOptions().BlockSize()
+		starts    []time.Time
+	)
+	for start, bl := range blocks.AllBlocks() {
+		if bl.IsRetrieved() && overlapsTombstones(ranges, start, start.Add(blockSize)) {
+			starts = append(starts, start)
+		}
+	}
+	if len(starts) == 0 {
+		return nil
+	}
+
+	tmpCtx := context.NewContext()
+	defer tmpCtx.BlockingClose()
+	for _, start := range starts {
+		bl, _ := blocks.BlockAt(start)
+		stream, err := bl.Stream(tmpCtx)
+		if err != nil {
+			return err
+		}
+		var readers []io.Reader
+		if stream != nil {
+			readers = append(readers, stream)
+		}
+		segment, ok, err := filterTombstoned(s.opts, readers, ranges)
+		if err != nil {
+			return err
+		}
+		blocks.RemoveBlockAt(start)
+		bl.Close()
+		if !ok {
+			continue
+		}
+		filtered := blockOpts.DatabaseBlockPool().Get()
+		filtered.Reset(start, segment)
+		blocks.AddBlock(filtered)
+	}
+	return nil
+}
+
+func (s *dbShard) Delete(id ts.ID, start, end time.Time) error {
+	var (
+		ropts    = s.opts.RetentionOptions()
+		now      = s.nowFn()
+		earliest = retention.FlushTimeStart(ropts, now)
+		latest   = now.Add(ropts.BufferFuture())
+	)
+	// Data outside of retention has expired so the tombstone only needs to
+	// cover the range within retention, this also means deleting an open
+	// ended range does not delete datapoints written after the delete
+	if start.Before(earliest) {
+		start = earliest
+	}
+	if end.After(latest) {
+		end = latest
+	}
+	if !start.Before(end) {
+		return nil
+	}
+
+	if err := s.tombstones.Add(id, xtime.Range{Start: start, End: end}); err != nil {
+		return err
+	}
+	s.markFlushStateNeedsCompaction(start, end)
+	return nil
+}
+
+func (s *dbShard) Rollup(
+	ns namespace.Metadata,
+	blockStarts []time.Time,
+) error {
+	s.RLock()
+	if s.bs != bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToRollup
+	}
+	s.RUnlock()
+
+	r := newShardRollup(ns, s.ID(), s.opts)
+	progress, hasProgress, err := r.Progress()
+	if err != nil {
+		return err
+	}
+
+	for _, blockStart := range blockStarts {
+		if hasProgress && !blockStart.After(progress) {
+			continue
+		}
+		// Blocks are rolled up in order so that the progress only needs to
+		// track the latest block start rolled up
+		if !r.sourceFlushed(blockStart) {
+			return nil
+		}
+		rolledUp, err := r.Rollup(blockStart)
+		if err != nil {
+			return err
+		}
+		// Load the rolled up data so it can be read without bootstrapping
+		// the filesets that were just written
+		if err := s.Load(rolledUp); err != nil {
+			return err
+		}
+		if err := r.MarkProgress(blockStart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *dbShard) Snapshot(
+	namespace ts.ID,
+	blockStart time.Time,
+	filePathPrefix string,
+) error {
+	s.RLock()
+	if s.bs != bootstrapped {
+		s.RUnlock()
+		return errShardNotBootstrappedToSnapshot
+	}
+	s.RUnlock()
+
+	// Data of flushed blocks is already durable in filesets
+	if s.FlushState(blockStart).Status == fileOpSuccess {
+		return nil
+	}
+
+	var (
+		fsOpts        = s.opts.CommitLogOptions().FilesystemOptions()
+		blockSize     = s.opts.RetentionOptions().BlockSize()
+		blockEnd      = blockStart.Add(blockSize)
+		segmentHolder = make([]checked.Bytes, 2)
+		writer        fs.FileSetWriter
+		multiErr      xerrors.MultiError
+	)
+	tmpCtx := context.NewContext()
+	s.forEachShardEntry(func(entry *dbShardEntry) bool {
+		series := entry.series
+		tmpCtx.Reset()
+		defer tmpCtx.BlockingClose()
+
+		encoded, err := series.ReadEncoded(tmpCtx, blockStart, blockEnd)
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			return true
+		}
+		var readers []io.Reader
+		for _, blockReaders := range encoded {
+			readers = append(readers, toReaders(blockReaders)...)
+		}
+		if len(readers) == 0 {
+			return true
+		}
+		ranges, err := s.tombstones.Ranges(series.ID())
+		if err != nil {
+			multiErr = multiErr.Add(err)
+			return true
+		}
+		// Merge the buffered and cached data of the block into one segment
+		segment, ok, err := filterTombstoned(s.opts, readers, ranges)
+		if err != nil || !ok {
+			multiErr = multiErr.Add(err)
+			return true
+		}
+		defer segment.Finalize()
+
+		// Only create the snapshot fileset when there is data to write
+		if writer == nil {
+			writer = fs.NewWriter(blockSize, filePathPrefix, fsOpts.WriterBufferSize(),
+				fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), fsOpts.Compression())
+			if err := writer.Open(namespace, s.ID(), blockStart); err != nil {
+				writer = nil
+				multiErr = multiErr.Add(err)
+				return false
+			}
+		}
+		segmentHolder[0], segmentHolder[1] = segment.Head, segment.Tail
+		err = writer.WriteAll(series.ID(), segmentHolder, digest.SegmentChecksum(segment))
+		multiErr = multiErr.Add(err)
+		return err == nil
+	})
+
+	if writer != nil {
+		multiErr = multiErr.Add(writer.Close())
+	}
+	return multiErr.FinalError()
+}
+
 func (s *dbShard) FlushState(blockStart time.Time) fileOpState {
 	s.flushState.RLock()
 	state, ok := s.flushState.statesByTime[blockStart]
@@ -896,9 +1443,132 @@ func (s *dbShard) FlushState(blockStart time.Time) fileOpState {
 func (s *dbShard) markFlushStateSuccess(blockStart time.Time) {
 	s.flushState.Lock()
 	s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+	delete(s.flushState.rewritesByTime, blockStart)
+	delete(s.flushState.corruptByTime, blockStart)
+	s.flushState.Unlock()
+}
+
+func (s *dbShard) MarkBlockCorrupt(blockStart time.Time) {
+	s.flushState.Lock()
+	s.flushState.corruptByTime[blockStart] = struct{}{}
+	// The fileset cannot be compacted into a rewrite, so it is only rewritten
+	// once the block is loaded from peers. Deleted data is still filtered out
+	// by the tombstones when it is.
+	if s.flushState.statesByTime[blockStart].Status != fileOpInProgress {
+		s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+		delete(s.flushState.rewritesByTime, blockStart)
+	}
 	s.flushState.Unlock()
 }
 
+func (s *dbShard) IsBlockCorrupt(blockStart time.Time) bool {
+	s.flushState.RLock()
+	_, ok := s.flushState.corruptByTime[blockStart]
+	s.flushState.RUnlock()
+	return ok
+}
+
+func (s *dbShard) corruptBlockStarts() map[time.Time]struct{} {
+	s.flushState.RLock()
+	defer s.flushState.RUnlock()
+	if len(s.flushState.corruptByTime) == 0 {
+		return nil
+	}
+	corrupt := make(map[time.Time]struct{}, len(s.flushState.corruptByTime))
+	for t := range s.flushState.corruptByTime {
+		corrupt[t] = struct{}{}
+	}
+	return corrupt
+}
+
+func filterCorruptBlocksMetadata(
+	blocks block.FetchBlockMetadataResults,
+	corrupt map[time.Time]struct{},
+) {
+	var (
+		results = blocks.Results()
+		kept    = make([]block.FetchBlockMetadataResult, 0, len(results))
+	)
+	for _, res := range results {
+		if _, ok := corrupt[res.Start]; !ok {
+			kept = append(kept, res)
+		}
+	}
+	if len(kept) == len(results) {
+		return
+	}
+	blocks.Reset()
+	for _, res := range kept {
+		blocks.Add(res)
+	}
+}
+
+func (s *dbShard) markFlushStateNeedsRewrite(blockStart time.Time) {
+	s.flushState.Lock()
+	state := s.flushState.statesByTime[blockStart]
+	if state.Status == fileOpSuccess {
+		s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpNotStarted}
+		s.flushState.rewritesByTime[blockStart] = struct{}{}
+	}
+	s.flushState.Unlock()
+}
+
+// markFlushStateNeedsCompaction marks the blocks overlapping a deleted range
+// to be rewritten by their next flush, whether or not they have been flushed,
+// so the deleted data is compacted out of their filesets.
+func (s *dbShard) markFlushStateNeedsCompaction(start, end time.Time) {
+	blockSize := s.opts.RetentionOptions().BlockSize()
+	s.flushState.Lock()
+	for t := start.Truncate(blockSize); t.Before(end); t = t.Add(blockSize) {
+		state := s.flushState.statesByTime[t]
+		if state.Status == fileOpSuccess {
+			s.flushState.statesByTime[t] = fileOpState{Status: fileOpNotStarted}
+		}
+		s.flushState.rewritesByTime[t] = struct{}{}
+	}
+	s.flushState.Unlock()
+}
+
+func (s *dbShard) markFlushStateColdWrites(blockStart time.Time) {
+	s.flushState.Lock()
+	s.flushState.coldByTime[blockStart] = struct{}{}
+	s.flushState.Unlock()
+}
+
+func (s *dbShard) unmarkFlushStateColdWrites(blockStart time.Time) {
+	s.flushState.Lock()
+	delete(s.flushState.coldByTime, blockStart)
+	s.flushState.Unlock()
+}
+
+func (s *dbShard) ColdWritesPending() bool {
+	s.flushState.RLock()
+	pending := len(s.flushState.coldByTime) > 0
+	s.flushState.RUnlock()
+	return pending
+}
+
+// coldFlushTimes returns the block starts with cold writes that have been
+// flushed, cold writes to blocks not yet flushed wait for their flush.
+func (s *dbShard) coldFlushTimes() []time.Time {
+	s.flushState.RLock()
+	var blockStarts []time.Time
+	for t := range s.flushState.coldByTime {
+		if s.flushState.statesByTime[t].Status == fileOpSuccess {
+			blockStarts = append(blockStarts, t)
+		}
+	}
+	s.flushState.RUnlock()
+	return blockStarts
+}
+
+func (s *dbShard) needsRewrite(blockStart time.Time) bool {
+	s.flushState.RLock()
+	_, ok := s.flushState.rewritesByTime[blockStart]
+	s.flushState.RUnlock()
+	return ok
+}
+
 func (s *dbShard) markFlushStateFail(blockStart time.Time) {
 	s.flushState.Lock()
 	state := s.flushState.statesByTime[blockStart]
@@ -915,30 +1585,108 @@ func (s *dbShard) removeAnyFlushStatesTooEarly() {
 	for t := range s.flushState.statesByTime {
 		if t.Before(earliestFlush) {
 			delete(s.flushState.statesByTime, t)
+			delete(s.flushState.rewritesByTime, t)
+			delete(s.flushState.corruptByTime, t)
+		}
+	}
+	for t := range s.flushState.coldByTime {
+		if t.Before(earliestFlush) {
+			delete(s.flushState.coldByTime, t)
 		}
 	}
 	s.flushState.Unlock()
 }
 
 func (s *dbShard) CleanupFileset(namespace ts.ID, earliestToRetain time.Time) error {
-	filePathPrefix := s.opts.CommitLogOptions().FilesystemOptions().FilePathPrefix()
-	multiErr := xerrors.NewMultiError()
-	expired, err := s.filesetBeforeFn(filePathPrefix, namespace, s.ID(), earliestToRetain)
-	if err != nil {
-		detailedErr := fmt.Errorf("encountered errors when getting fileset files for prefix %s namespace %s shard %d: %v", filePathPrefix, namespace, s.ID(), err)
-		multiErr = multiErr.Add(detailedErr)
+	var (
+		fsOpts   = s.opts.CommitLogOptions().FilesystemOptions()
+		prefixes = fs.TierFilePathPrefixes(fsOpts.FilePathPrefix(), fsOpts.TieringPolicy())
+		multiErr = xerrors.NewMultiError()
+		toDelete []string
+	)
+	for _, filePathPrefix := range prefixes {
+		expired, err := s.filesetBeforeFn(filePathPrefix, namespace, s.ID(), earliestToRetain)
+		if err != nil {
+			detailedErr := fmt.Errorf("encountered errors when getting fileset files for prefix %s namespace %s shard %d: %v", filePathPrefix, namespace, s.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		// Versions of filesets replaced by a rewrite are no longer read
+		superseded, err := s.supersededFilesetsFn(filePathPrefix, namespace, s.ID())
+		if err != nil {
+			detailedErr := fmt.Errorf("encountered errors when getting superseded fileset files for prefix %s namespace %s shard %d: %v", filePathPrefix, namespace, s.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		toDelete = append(toDelete, expired...)
+		toDelete = append(toDelete, superseded...)
+	}
+	if len(prefixes) > 1 {
+		// Filesets moved to a later tier are no longer read once their block
+		// has been rewritten to an earlier tier
+		superseded, err := s.supersededTierFilesetsFn(prefixes, namespace, s.ID())
+		if err != nil {
+			detailedErr := fmt.Errorf("encountered errors when getting superseded tier fileset files for namespace %s shard %d: %v", namespace, s.ID(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+		toDelete = append(toDelete, superseded...)
+	}
+	if err := s.deleteFilesFn(toDelete); err != nil {
+		multiErr = multiErr.Add(err)
 	}
-	if err := s.deleteFilesFn(expired); err != nil {
+	if err := s.tombstones.Expire(earliestToRetain); err != nil {
 		multiErr = multiErr.Add(err)
 	}
 	return multiErr.FinalError()
 }
 
+func (s *dbShard) TierFilesets(ns namespace.Metadata, olderThan time.Time) error {
+	fsOpts := s.opts.CommitLogOptions().FilesystemOptions()
+	policy := fsOpts.TieringPolicy()
+	if !policy.Enabled() || policy.FilePathPrefix == fsOpts.FilePathPrefix() {
+		return nil
+	}
+
+	var (
+		namespace     = ns.ID()
+		blockSize     = ns.Options().RetentionOptions().BlockSize()
+		rateLimitOpts = s.opts.RuntimeOptionsManager().Get().PersistRateLimitOptions()
+	)
+
+	blockStarts, err := s.filesetBlockStartsFn(fsOpts.FilePathPrefix(), namespace, s.ID())
+	if err != nil {
+		return fmt.Errorf("encountered errors when getting fileset block starts for prefix %s namespace %s shard %d: %v", fsOpts.FilePathPrefix(), namespace, s.ID(), err)
+	}
+
+	multiErr := xerrors.NewMultiError()
+	for _, blockStart := range blockStarts {
+		if !blockStart.Add(blockSize).Before(olderThan) {
+			// Block starts are in ascending order
+			break
+		}
+		if s.FlushState(blockStart).Status == fileOpInProgress {
+			continue
+		}
+		if err := s.moveFilesetFn(fsOpts.FilePathPrefix(), policy.FilePathPrefix,
+			namespace, s.ID(), blockStart, fsOpts.NewFileMode(), fsOpts.NewDirectoryMode(), rateLimitOpts); err != nil {
+			detailedErr := fmt.Errorf("failed to move fileset for namespace %s shard %d block %v to prefix %s: %v", namespace, s.ID(), blockStart, policy.FilePathPrefix, err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	return multiErr.FinalError()
+}
+
 func (s *dbShard) Repair(
 	ctx context.Context,
-	namespace ts.ID,
+	ns namespace.Metadata,
 	tr xtime.Range,
 	repairer databaseShardRepairer,
 ) (repair.MetadataComparisonResult, error) {
-	return repairer.Repair(ctx, namespace, tr, s)
+	return repairer.Repair(ctx, ns, tr, s)
+}
+
+func toReaders(segmentReaders []xio.SegmentReader) []io.Reader {
+	readers := make([]io.Reader, 0, len(segmentReaders))
+	for _, reader := range segmentReaders {
+		readers = append(readers, reader)
+	}
+	return readers
 }
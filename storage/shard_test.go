@@ -23,6 +23,8 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -31,9 +33,13 @@ import (
 
 	"github.com/m3db/m3db/context"
 	"github.com/m3db/m3db/persist"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/ratelimit"
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/series"
 	"github.com/m3db/m3db/ts"
 	"github.com/m3db/m3x/time"
@@ -155,7 +161,7 @@ func TestShardFlushNoPersistFuncNoError(t *testing.T) {
 	blockStart := time.Unix(21600, 0)
 	flush := persist.NewMockFlush(ctrl)
 	prepared := persist.PreparedPersist{Persist: nil}
-	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart).Return(prepared, nil)
+	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, nil)
 
 	err := s.Flush(testNamespaceID, blockStart, flush)
 	require.Nil(t, err)
@@ -181,7 +187,7 @@ func TestShardFlushNoPersistFuncWithError(t *testing.T) {
 	flush := persist.NewMockFlush(ctrl)
 	prepared := persist.PreparedPersist{}
 	expectedErr := errors.New("some error")
-	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart).Return(prepared, expectedErr)
+	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, expectedErr)
 
 	actualErr := s.Flush(testNamespaceID, blockStart, flush)
 	require.NotNil(t, actualErr)
@@ -215,7 +221,7 @@ func TestShardFlushSeriesFlushError(t *testing.T) {
 		Close:   func() error { closed = true; return nil },
 	}
 	expectedErr := errors.New("error foo")
-	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart).Return(prepared, expectedErr)
+	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, expectedErr)
 
 	flushed := make(map[int]struct{})
 	for i := 0; i < 2; i++ {
@@ -276,7 +282,7 @@ func TestShardFlushSeriesFlushSuccess(t *testing.T) {
 		Close:   func() error { closed = true; return nil },
 	}
 
-	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart).Return(prepared, nil)
+	flush.EXPECT().Prepare(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, nil)
 
 	flushed := make(map[int]struct{})
 	for i := 0; i < 2; i++ {
@@ -311,6 +317,251 @@ func TestShardFlushSeriesFlushSuccess(t *testing.T) {
 	}, flushState)
 }
 
+func TestShardLoadRewritesFlushedBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	blockStart := time.Unix(21600, 0)
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	s.bs = bootstrapped
+	s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+
+	blocks := block.NewDatabaseSeriesBlocks(0, opts.DatabaseBlockOptions())
+	blocks.AddBlock(block.NewDatabaseBlock(blockStart, ts.Segment{}, opts.DatabaseBlockOptions()))
+
+	id := ts.StringID("foo")
+	series := addMockSeries(ctrl, s, id, 0)
+	series.EXPECT().Load(gomock.Any(), blocks).Return(nil)
+
+	err := s.Load(map[ts.Hash]result.DatabaseSeriesBlocks{
+		id.Hash(): {ID: id, Blocks: blocks},
+	})
+	require.NoError(t, err)
+	require.Equal(t, fileOpState{Status: fileOpNotStarted}, s.FlushState(blockStart))
+
+	prepared := persist.PreparedPersist{
+		Persist: func(ts.ID, ts.Segment, uint32) error { return nil },
+		Close:   func() error { return nil },
+	}
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().PrepareRewrite(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, nil)
+	series.EXPECT().Flush(gomock.Any(), blockStart, gomock.Any()).Return(nil)
+
+	require.NoError(t, s.Flush(testNamespaceID, blockStart, flush))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, s.FlushState(blockStart))
+	require.False(t, s.needsRewrite(blockStart))
+}
+
+func TestShardMarkBlockCorrupt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	blockSize := opts.RetentionOptions().BlockSize()
+	blockStart := time.Unix(21600, 0)
+	nextBlockStart := blockStart.Add(blockSize)
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	s.bs = bootstrapped
+
+	s.MarkBlockCorrupt(blockStart)
+	require.True(t, s.IsBlockCorrupt(blockStart))
+	require.False(t, s.IsBlockRetrievable(blockStart))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, s.FlushState(blockStart))
+
+	// Corrupt blocks are omitted from the blocks metadata
+	id := ts.StringID("foo")
+	series := addMockSeries(ctrl, s, id, 0)
+	results := block.NewFetchBlockMetadataResults()
+	results.Add(block.NewFetchBlockMetadataResult(blockStart, 1, nil, time.Time{}, nil))
+	results.Add(block.NewFetchBlockMetadataResult(nextBlockStart, 2, nil, time.Time{}, nil))
+	fetchOpts := block.FetchBlocksMetadataOptions{IncludeSizes: true}
+	series.EXPECT().
+		FetchBlocksMetadata(gomock.Not(nil), blockStart, nextBlockStart.Add(blockSize), fetchOpts).
+		Return(block.NewFetchBlocksMetadataResult(id, results))
+
+	res, _ := s.FetchBlocksMetadata(ctx, blockStart, nextBlockStart.Add(blockSize),
+		math.MaxInt64, 0, fetchOpts)
+	require.Equal(t, 1, len(res.Results()))
+	blocksRes := res.Results()[0].Blocks.Results()
+	require.Equal(t, 1, len(blocksRes))
+	require.Equal(t, nextBlockStart, blocksRes[0].Start)
+
+	// Loading the block from peers rewrites the fileset without compacting
+	// the corrupt fileset
+	blocks := block.NewDatabaseSeriesBlocks(0, opts.DatabaseBlockOptions())
+	blocks.AddBlock(block.NewDatabaseBlock(blockStart, ts.Segment{}, opts.DatabaseBlockOptions()))
+	series.EXPECT().Load(gomock.Any(), blocks).Return(nil)
+	require.NoError(t, s.Load(map[ts.Hash]result.DatabaseSeriesBlocks{
+		id.Hash(): {ID: id, Blocks: blocks},
+	}))
+	require.True(t, s.needsRewrite(blockStart))
+
+	prepared := persist.PreparedPersist{
+		Persist: func(ts.ID, ts.Segment, uint32) error { return nil },
+		Close:   func() error { return nil },
+	}
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().PrepareRewrite(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, nil)
+	series.EXPECT().Flush(gomock.Any(), blockStart, gomock.Any()).Return(nil)
+
+	require.NoError(t, s.Flush(testNamespaceID, blockStart, flush))
+	require.False(t, s.IsBlockCorrupt(blockStart))
+	require.True(t, s.IsBlockRetrievable(blockStart))
+}
+
+func TestShardWriteTaggedWritesTagsToCommitLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var written []commitlog.Series
+	captureFn := func(
+		ctx context.Context,
+		series commitlog.Series,
+		datapoint ts.Datapoint,
+		unit xtime.Unit,
+		annotation ts.Annotation,
+	) error {
+		written = append(written, series)
+		return nil
+	}
+	s := newDatabaseShard(ts.StringID("namespace"), 0, nil,
+		&testIncreasingIndex{}, captureFn, true, testDatabaseOptions()).(*dbShard)
+	defer s.Close()
+
+	id := ts.StringID("foo")
+	tags := ts.Tags{{Name: ts.StringID("city"), Value: ts.StringID("nyc")}}
+	series := addMockSeries(ctrl, s, id, 0)
+	series.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	now := time.Now()
+	require.NoError(t, s.WriteTagged(ctx, id, tags, now, 1.0, xtime.Second, nil))
+	require.NoError(t, s.Write(ctx, id, now, 2.0, xtime.Second, nil))
+
+	require.Equal(t, 2, len(written))
+	require.Equal(t, tags, written[0].Tags)
+	require.Nil(t, written[1].Tags)
+}
+
+func TestShardWriteRewritesFlushedBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	blockSize := opts.RetentionOptions().BlockSize()
+	blockStart := time.Now().Truncate(blockSize).Add(-blockSize)
+	now := blockStart.Add(blockSize).Add(time.Minute)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+
+	id := ts.StringID("foo")
+	series := addMockSeries(ctrl, s, id, 0)
+	series.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	// A write to the current block does not affect the flushed block
+	require.NoError(t, s.Write(ctx, id, now, 1.0, xtime.Second, nil))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, s.FlushState(blockStart))
+	require.False(t, s.needsRewrite(blockStart))
+
+	require.NoError(t, s.Write(ctx, id, now.Add(-2*time.Minute), 2.0, xtime.Second, nil))
+	require.Equal(t, fileOpState{Status: fileOpNotStarted}, s.FlushState(blockStart))
+	require.True(t, s.needsRewrite(blockStart))
+}
+
+func TestShardWriteMarksColdWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	ropts := opts.RetentionOptions()
+	blockSize := ropts.BlockSize()
+	now := time.Now().Truncate(blockSize)
+	blockStart := now.Add(-2 * blockSize)
+	opts = opts.SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+		return now
+	}))
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+
+	id := ts.StringID("foo")
+	series := addMockSeries(ctrl, s, id, 0)
+	series.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any(), gomock.Any()).Return(nil)
+
+	ctx := context.NewContext()
+	defer ctx.Close()
+
+	require.False(t, s.ColdWritesPending())
+
+	// Cold writes wait for the cold flush rather than rewriting the block
+	require.NoError(t, s.Write(ctx, id, blockStart.Add(time.Minute), 1.0, xtime.Second, nil))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, s.FlushState(blockStart))
+	require.False(t, s.needsRewrite(blockStart))
+	require.Equal(t, []time.Time{blockStart}, s.coldFlushTimes())
+	require.True(t, s.ColdWritesPending())
+}
+
+func TestShardColdFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts := testDatabaseOptions()
+	blockSize := opts.RetentionOptions().BlockSize()
+	blockStart := time.Unix(21600, 0)
+	unflushed := blockStart.Add(blockSize)
+
+	s := testDatabaseShard(opts)
+	defer s.Close()
+	s.bs = bootstrapped
+	s.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+	s.markFlushStateColdWrites(blockStart)
+	s.markFlushStateColdWrites(unflushed)
+
+	prepared := persist.PreparedPersist{
+		Persist: func(ts.ID, ts.Segment, uint32) error { return nil },
+		Close:   func() error { return nil },
+	}
+	flush := persist.NewMockFlush(ctrl)
+	flush.EXPECT().PrepareRewrite(testNamespaceID, s.shard, blockStart, gomock.Any()).Return(prepared, nil)
+
+	for i, merged := range []bool{true, false} {
+		series := addMockSeries(ctrl, s, ts.StringID("foo"+strconv.Itoa(i)), uint64(i))
+		series.EXPECT().MergeColdWrites(gomock.Any(), blockStart).Return(merged, nil)
+		series.EXPECT().Flush(gomock.Any(), blockStart, gomock.Any()).Return(nil)
+	}
+
+	require.NoError(t, s.ColdFlush(testNamespaceID, flush))
+	require.Equal(t, fileOpState{Status: fileOpSuccess}, s.FlushState(blockStart))
+	require.False(t, s.needsRewrite(blockStart))
+
+	// Cold writes to blocks not yet flushed wait for the flush of the block
+	_, ok := s.flushState.coldByTime[unflushed]
+	require.True(t, ok)
+	require.Empty(t, s.coldFlushTimes())
+}
+
 func addTestSeries(shard *dbShard, id ts.ID) series.DatabaseSeries {
 	series := series.NewDatabaseSeries(id, NewSeriesOptionsFromOptions(shard.opts))
 	series.Bootstrap(nil)
@@ -573,11 +824,96 @@ func TestShardCleanupFileset(t *testing.T) {
 	shard.filesetBeforeFn = func(_ string, namespace ts.ID, shardID uint32, t time.Time) ([]string, error) {
 		return []string{namespace.String(), strconv.Itoa(int(shardID))}, nil
 	}
+	shard.supersededFilesetsFn = func(_ string, namespace ts.ID, shardID uint32) ([]string, error) {
+		return []string{"superseded"}, nil
+	}
+	var deletedFiles []string
+	shard.deleteFilesFn = func(files []string) error {
+		deletedFiles = append(deletedFiles, files...)
+		return nil
+	}
+	require.NoError(t, shard.CleanupFileset(testNamespaceID, time.Now()))
+	require.Equal(t, []string{testNamespaceID.String(), "0", "superseded"}, deletedFiles)
+}
+
+func testTieredDatabaseOptions() Options {
+	opts := testDatabaseOptions()
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	return opts.SetCommitLogOptions(opts.CommitLogOptions().SetFilesystemOptions(
+		fsOpts.SetTieringPolicy(fs.TieringPolicy{
+			FilePathPrefix: fsOpts.FilePathPrefix() + "-secondary",
+			Age:            24 * time.Hour,
+		})))
+}
+
+func TestShardCleanupFilesetTiered(t *testing.T) {
+	opts := testTieredDatabaseOptions()
+	shard := testDatabaseShard(opts)
+	defer shard.Close()
+	shard.filesetBeforeFn = func(prefix string, namespace ts.ID, shardID uint32, t time.Time) ([]string, error) {
+		return []string{prefix}, nil
+	}
+	shard.supersededFilesetsFn = func(_ string, namespace ts.ID, shardID uint32) ([]string, error) {
+		return nil, nil
+	}
+	shard.supersededTierFilesetsFn = func(prefixes []string, namespace ts.ID, shardID uint32) ([]string, error) {
+		return []string{"superseded"}, nil
+	}
 	var deletedFiles []string
 	shard.deleteFilesFn = func(files []string) error {
 		deletedFiles = append(deletedFiles, files...)
 		return nil
 	}
 	require.NoError(t, shard.CleanupFileset(testNamespaceID, time.Now()))
-	require.Equal(t, []string{testNamespaceID.String(), "0"}, deletedFiles)
+
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	require.Equal(t, []string{
+		fsOpts.FilePathPrefix(),
+		fsOpts.TieringPolicy().FilePathPrefix,
+		"superseded",
+	}, deletedFiles)
+}
+
+func TestShardTierFilesets(t *testing.T) {
+	opts := testTieredDatabaseOptions()
+	shard := testDatabaseShard(opts)
+	defer shard.Close()
+
+	// The namespace block size is used rather than the default block size
+	ropts := opts.RetentionOptions().SetBlockSize(2 * opts.RetentionOptions().BlockSize())
+	var (
+		fsOpts      = opts.CommitLogOptions().FilesystemOptions()
+		blockSize   = ropts.BlockSize()
+		ns          = namespace.NewMetadata(testNamespaceID, namespace.NewOptions().SetRetentionOptions(ropts))
+		olderThan   = time.Unix(0, 0).Add(10 * blockSize)
+		inProgress  = olderThan.Add(-3 * blockSize)
+		blockStarts = []time.Time{
+			olderThan.Add(-4 * blockSize),
+			inProgress,
+			olderThan.Add(-2 * blockSize),
+			olderThan.Add(-blockSize),
+			olderThan,
+		}
+		moved []time.Time
+	)
+	shard.flushState.statesByTime[inProgress] = fileOpState{Status: fileOpInProgress}
+	shard.filesetBlockStartsFn = func(prefix string, namespace ts.ID, shardID uint32) ([]time.Time, error) {
+		require.Equal(t, fsOpts.FilePathPrefix(), prefix)
+		return blockStarts, nil
+	}
+	shard.moveFilesetFn = func(
+		src, dst string,
+		namespace ts.ID,
+		shardID uint32,
+		blockStart time.Time,
+		_, _ os.FileMode,
+		_ ratelimit.Options,
+	) error {
+		require.Equal(t, fsOpts.FilePathPrefix(), src)
+		require.Equal(t, fsOpts.TieringPolicy().FilePathPrefix, dst)
+		moved = append(moved, blockStart)
+		return nil
+	}
+	require.NoError(t, shard.TierFilesets(ns, olderThan))
+	require.Equal(t, []time.Time{blockStarts[0], blockStarts[2]}, moved)
 }
@@ -0,0 +1,150 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3x/errors"
+
+	"github.com/uber-go/tally"
+)
+
+var (
+	errSnapshotAlreadyInProgress = errors.New("snapshot already in progress")
+)
+
+type nextSnapshotIndexFn func(prefix string) (int, error)
+
+type writeSnapshotMetadataFn func(
+	prefix string,
+	metadata fs.SnapshotMetadata,
+	newFileMode os.FileMode,
+	newDirectoryMode os.FileMode,
+) error
+
+type snapshotManager struct {
+	sync.RWMutex
+
+	database                database
+	opts                    Options
+	interval                time.Duration
+	filePathPrefix          string
+	nextSnapshotIndexFn     nextSnapshotIndexFn
+	writeSnapshotMetadataFn writeSnapshotMetadataFn
+	lastSnapshotAt          time.Time
+	snapshotInProgress      bool
+	status                  tally.Gauge
+}
+
+func newSnapshotManager(database database, scope tally.Scope) databaseSnapshotManager {
+	opts := database.Options()
+	return &snapshotManager{
+		database:                database,
+		opts:                    opts,
+		interval:                opts.FileOpOptions().SnapshotInterval(),
+		filePathPrefix:          opts.CommitLogOptions().FilesystemOptions().FilePathPrefix(),
+		nextSnapshotIndexFn:     fs.NextSnapshotIndex,
+		writeSnapshotMetadataFn: fs.WriteSnapshotMetadata,
+		status:                  scope.Gauge("snapshot"),
+	}
+}
+
+func (m *snapshotManager) Snapshot(t time.Time) error {
+	m.Lock()
+	if m.interval <= 0 || t.Before(m.lastSnapshotAt.Add(m.interval)) {
+		m.Unlock()
+		return nil
+	}
+	if m.snapshotInProgress {
+		m.Unlock()
+		return errSnapshotAlreadyInProgress
+	}
+	m.snapshotInProgress = true
+	m.Unlock()
+
+	defer func() {
+		m.Lock()
+		m.snapshotInProgress = false
+		m.Unlock()
+	}()
+
+	// Rotate the commit log first so every write in the commit logs before
+	// the rotated to file is in memory when the snapshot is taken
+	file, err := m.database.rotateCommitLog()
+	if err != nil {
+		return fmt.Errorf("unable to rotate commit log: %v", err)
+	}
+	index, err := m.nextSnapshotIndexFn(m.filePathPrefix)
+	if err != nil {
+		return err
+	}
+
+	var (
+		snapshotDir = fs.SnapshotDirPath(m.filePathPrefix, index)
+		multiErr    = xerrors.NewMultiError()
+	)
+	for _, n := range m.database.getOwnedNamespaces() {
+		// We still want to proceed if a namespace fails to snapshot its data.
+		if err := n.Snapshot(snapshotDir, t); err != nil {
+			detailedErr := fmt.Errorf("namespace %s failed to snapshot data: %v",
+				n.ID().String(), err)
+			multiErr = multiErr.Add(detailedErr)
+		}
+	}
+	if err := multiErr.FinalError(); err != nil {
+		// The snapshot is incomplete and is not recorded so the commit logs
+		// it would have covered are still replayed and retained
+		return err
+	}
+
+	fsOpts := m.opts.CommitLogOptions().FilesystemOptions()
+	metadata := fs.SnapshotMetadata{
+		Index:          index,
+		CommitLogStart: file.Start,
+		CommitLogIndex: file.Index,
+	}
+	if err := m.writeSnapshotMetadataFn(m.filePathPrefix, metadata,
+		fsOpts.NewFileMode(), fsOpts.NewDirectoryMode()); err != nil {
+		return err
+	}
+
+	m.Lock()
+	m.lastSnapshotAt = t
+	m.Unlock()
+	return nil
+}
+
+func (m *snapshotManager) Report() {
+	m.RLock()
+	snapshotInProgress := m.snapshotInProgress
+	m.RUnlock()
+
+	if snapshotInProgress {
+		m.status.Update(1)
+	} else {
+		m.status.Update(0)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3db/x/io"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/uber-go/tally"
+)
+
+func testSnapshotManager(
+	ctrl *gomock.Controller,
+	interval time.Duration,
+) (*Mockdatabase, *snapshotManager) {
+	opts := testDatabaseOptions()
+	opts = opts.SetFileOpOptions(opts.FileOpOptions().SetSnapshotInterval(interval))
+	db := NewMockdatabase(ctrl)
+	db.EXPECT().Options().Return(opts).AnyTimes()
+	return db, newSnapshotManager(db, tally.NoopScope).(*snapshotManager)
+}
+
+func TestSnapshotManagerSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, mgr := testSnapshotManager(ctrl, time.Minute)
+	mgr.nextSnapshotIndexFn = func(_ string) (int, error) {
+		return 3, nil
+	}
+	var written []fs.SnapshotMetadata
+	mgr.writeSnapshotMetadataFn = func(
+		_ string,
+		metadata fs.SnapshotMetadata,
+		_ os.FileMode,
+		_ os.FileMode,
+	) error {
+		written = append(written, metadata)
+		return nil
+	}
+
+	now := time.Now()
+	file := commitlog.File{Start: now.Add(-time.Hour), Index: 2}
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().Snapshot(fs.SnapshotDirPath(mgr.filePathPrefix, 3), now).Return(nil)
+	gomock.InOrder(
+		db.EXPECT().rotateCommitLog().Return(file, nil),
+		db.EXPECT().getOwnedNamespaces().Return([]databaseNamespace{ns}),
+	)
+
+	require.NoError(t, mgr.Snapshot(now))
+	require.Equal(t, []fs.SnapshotMetadata{
+		{Index: 3, CommitLogStart: file.Start, CommitLogIndex: file.Index},
+	}, written)
+
+	// No snapshot is taken until the interval has elapsed
+	require.NoError(t, mgr.Snapshot(now.Add(time.Second)))
+	require.Equal(t, 1, len(written))
+}
+
+func TestSnapshotManagerSnapshotNamespaceError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, mgr := testSnapshotManager(ctrl, time.Minute)
+	mgr.nextSnapshotIndexFn = func(_ string) (int, error) {
+		return 0, nil
+	}
+	mgr.writeSnapshotMetadataFn = func(string, fs.SnapshotMetadata, os.FileMode, os.FileMode) error {
+		require.FailNow(t, "incomplete snapshot must not be recorded")
+		return nil
+	}
+
+	now := time.Now()
+	ns := NewMockdatabaseNamespace(ctrl)
+	ns.EXPECT().ID().Return(ts.StringID("foo"))
+	ns.EXPECT().Snapshot(gomock.Any(), now).Return(errors.New("an error"))
+	db.EXPECT().rotateCommitLog().Return(commitlog.File{}, nil)
+	db.EXPECT().getOwnedNamespaces().Return([]databaseNamespace{ns})
+
+	require.Error(t, mgr.Snapshot(now))
+}
+
+func TestSnapshotManagerSnapshotDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, mgr := testSnapshotManager(ctrl, 0)
+	require.NoError(t, mgr.Snapshot(time.Now()))
+}
+
+func TestShardSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	opts, dir := testCompactionOptions(t)
+	defer os.RemoveAll(dir)
+
+	blockSize := opts.RetentionOptions().BlockSize()
+	blockStart := time.Now().Truncate(blockSize)
+	blockEnd := blockStart.Add(blockSize)
+	dp := func(offset time.Duration, value float64) ts.Datapoint {
+		return ts.Datapoint{Timestamp: blockStart.Add(offset), Value: value}
+	}
+
+	shard := testDatabaseShard(opts)
+	defer shard.Close()
+	shard.bs = bootstrapped
+
+	// The buffered data of foo spans two buckets that are merged in the snapshot
+	foo := ts.StringID("foo")
+	fooSeries := addMockSeries(ctrl, shard, foo, 0)
+	fooSeries.EXPECT().ReadEncoded(gomock.Any(), blockStart, blockEnd).Return([][]xio.SegmentReader{
+		{xio.NewSegmentReader(testCompactionSegment(t, opts, blockStart,
+			[]ts.Datapoint{dp(time.Minute, 1), dp(3*time.Minute, 3)}))},
+		{xio.NewSegmentReader(testCompactionSegment(t, opts, blockStart,
+			[]ts.Datapoint{dp(2*time.Minute, 2)}))},
+	}, nil)
+	bar := ts.StringID("bar")
+	barSeries := addMockSeries(ctrl, shard, bar, 1)
+	barSeries.EXPECT().ReadEncoded(gomock.Any(), blockStart, blockEnd).Return(nil, nil)
+
+	snapshotDir := fs.SnapshotDirPath(dir, 0)
+	require.NoError(t, shard.Snapshot(testNamespaceID, blockStart, snapshotDir))
+
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	reader := fs.NewReader(snapshotDir, fsOpts.ReaderBufferSize(), nil, fsOpts.DecodingOptions())
+	require.NoError(t, reader.Open(testNamespaceID, shard.ID(), blockStart))
+	defer reader.Close()
+
+	id, data, _, err := reader.Read()
+	require.NoError(t, err)
+	require.Equal(t, foo.String(), id.String())
+	segment := ts.NewSegment(data, nil, ts.FinalizeNone)
+	require.Equal(t, []ts.Datapoint{dp(time.Minute, 1), dp(2*time.Minute, 2), dp(3*time.Minute, 3)},
+		testCompactionDatapoints(t, opts, segment))
+
+	_, _, _, err = reader.Read()
+	require.Equal(t, io.EOF, err)
+
+	// Flushed blocks are not snapshotted
+	shard.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+	require.NoError(t, shard.Snapshot(testNamespaceID, blockStart, fs.SnapshotDirPath(dir, 1)))
+	require.False(t, fs.FilesetExistsAt(fs.SnapshotDirPath(dir, 1), testNamespaceID, shard.ID(), blockStart))
+}
+
+func TestShardSnapshotNotBootstrapped(t *testing.T) {
+	shard := testDatabaseShard(testDatabaseOptions())
+	defer shard.Close()
+	shard.bs = bootstrapping
+
+	err := shard.Snapshot(testNamespaceID, time.Now(), "")
+	require.Equal(t, errShardNotBootstrappedToSnapshot, err)
+}
@@ -31,12 +31,15 @@ import (
 	encoding "github.com/m3db/m3db/encoding"
 	persist "github.com/m3db/m3db/persist"
 	commitlog "github.com/m3db/m3db/persist/fs/commitlog"
+	ratelimit "github.com/m3db/m3db/ratelimit"
 	retention "github.com/m3db/m3db/retention"
 	runtime "github.com/m3db/m3db/runtime"
 	sharding "github.com/m3db/m3db/sharding"
 	block "github.com/m3db/m3db/storage/block"
 	bootstrap "github.com/m3db/m3db/storage/bootstrap"
 	result "github.com/m3db/m3db/storage/bootstrap/result"
+	index "github.com/m3db/m3db/storage/index"
+	namespace "github.com/m3db/m3db/storage/namespace"
 	repair "github.com/m3db/m3db/storage/repair"
 	series "github.com/m3db/m3db/storage/series"
 	ts "github.com/m3db/m3db/ts"
@@ -128,6 +131,27 @@ func (_mr *_MockDatabaseRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5, arg6
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+func (_m *MockDatabase) WriteTagged(ctx context.Context, namespace ts.ID, id ts.ID, tags ts.Tags, timestamp time.Time, value float64, unit time0.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", ctx, namespace, id, tags, timestamp, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+}
+
+func (_m *MockDatabase) QueryIDs(ctx context.Context, namespace ts.ID, query index.Query, opts index.QueryOptions) (index.QueryResults, error) {
+	ret := _m.ctrl.Call(_m, "QueryIDs", ctx, namespace, query, opts)
+	ret0, _ := ret[0].(index.QueryResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockDatabaseRecorder) QueryIDs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "QueryIDs", arg0, arg1, arg2, arg3)
+}
+
 func (_m *MockDatabase) ReadEncoded(ctx context.Context, namespace ts.ID, id ts.ID, start time.Time, end time.Time) ([][]io.SegmentReader, error) {
 	ret := _m.ctrl.Call(_m, "ReadEncoded", ctx, namespace, id, start, end)
 	ret0, _ := ret[0].([][]io.SegmentReader)
@@ -213,6 +237,36 @@ func (_mr *_MockDatabaseRecorder) Truncate(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Truncate", arg0)
 }
 
+func (_m *MockDatabase) Delete(namespace ts.ID, id ts.ID, start time.Time, end time.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", namespace, id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2, arg3)
+}
+
+func (_m *MockDatabase) AddNamespace(md namespace.Metadata) error {
+	ret := _m.ctrl.Call(_m, "AddNamespace", md)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseRecorder) AddNamespace(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddNamespace", arg0)
+}
+
+func (_m *MockDatabase) RemoveNamespace(id ts.ID) error {
+	ret := _m.ctrl.Call(_m, "RemoveNamespace", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockDatabaseRecorder) RemoveNamespace(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RemoveNamespace", arg0)
+}
+
 // Mock of database interface
 type Mockdatabase struct {
 	ctrl     *gomock.Controller
@@ -292,6 +346,27 @@ func (_mr *_MockdatabaseRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5, arg6
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+func (_m *Mockdatabase) WriteTagged(ctx context.Context, namespace ts.ID, id ts.ID, tags ts.Tags, timestamp time.Time, value float64, unit time0.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", ctx, namespace, id, tags, timestamp, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
+}
+
+func (_m *Mockdatabase) QueryIDs(ctx context.Context, namespace ts.ID, query index.Query, opts index.QueryOptions) (index.QueryResults, error) {
+	ret := _m.ctrl.Call(_m, "QueryIDs", ctx, namespace, query, opts)
+	ret0, _ := ret[0].(index.QueryResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockdatabaseRecorder) QueryIDs(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "QueryIDs", arg0, arg1, arg2, arg3)
+}
+
 func (_m *Mockdatabase) ReadEncoded(ctx context.Context, namespace ts.ID, id ts.ID, start time.Time, end time.Time) ([][]io.SegmentReader, error) {
 	ret := _m.ctrl.Call(_m, "ReadEncoded", ctx, namespace, id, start, end)
 	ret0, _ := ret[0].([][]io.SegmentReader)
@@ -377,6 +452,36 @@ func (_mr *_MockdatabaseRecorder) Truncate(arg0 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Truncate", arg0)
 }
 
+func (_m *Mockdatabase) Delete(namespace ts.ID, id ts.ID, start time.Time, end time.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", namespace, id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseRecorder) Delete(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2, arg3)
+}
+
+func (_m *Mockdatabase) AddNamespace(md namespace.Metadata) error {
+	ret := _m.ctrl.Call(_m, "AddNamespace", md)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseRecorder) AddNamespace(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "AddNamespace", arg0)
+}
+
+func (_m *Mockdatabase) RemoveNamespace(id ts.ID) error {
+	ret := _m.ctrl.Call(_m, "RemoveNamespace", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseRecorder) RemoveNamespace(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "RemoveNamespace", arg0)
+}
+
 func (_m *Mockdatabase) getOwnedNamespaces() []databaseNamespace {
 	ret := _m.ctrl.Call(_m, "getOwnedNamespaces")
 	ret0, _ := ret[0].([]databaseNamespace)
@@ -387,6 +492,17 @@ func (_mr *_MockdatabaseRecorder) getOwnedNamespaces() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "getOwnedNamespaces")
 }
 
+func (_m *Mockdatabase) rotateCommitLog() (commitlog.File, error) {
+	ret := _m.ctrl.Call(_m, "rotateCommitLog")
+	ret0, _ := ret[0].(commitlog.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockdatabaseRecorder) rotateCommitLog() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "rotateCommitLog")
+}
+
 // Mock of Namespace interface
 type MockNamespace struct {
 	ctrl     *gomock.Controller
@@ -438,6 +554,16 @@ func (_mr *_MockNamespaceRecorder) Shards() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Shards")
 }
 
+func (_m *MockNamespace) Options() namespace.Options {
+	ret := _m.ctrl.Call(_m, "Options")
+	ret0, _ := ret[0].(namespace.Options)
+	return ret0
+}
+
+func (_mr *_MockNamespaceRecorder) Options() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Options")
+}
+
 // Mock of databaseNamespace interface
 type MockdatabaseNamespace struct {
 	ctrl     *gomock.Controller
@@ -489,6 +615,16 @@ func (_mr *_MockdatabaseNamespaceRecorder) Shards() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Shards")
 }
 
+func (_m *MockdatabaseNamespace) Options() namespace.Options {
+	ret := _m.ctrl.Call(_m, "Options")
+	ret0, _ := ret[0].(namespace.Options)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) Options() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Options")
+}
+
 func (_m *MockdatabaseNamespace) AssignShardSet(shardSet sharding.ShardSet) {
 	_m.ctrl.Call(_m, "AssignShardSet", shardSet)
 }
@@ -515,6 +651,27 @@ func (_mr *_MockdatabaseNamespaceRecorder) Write(arg0, arg1, arg2, arg3, arg4, a
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockdatabaseNamespace) WriteTagged(ctx context.Context, id ts.ID, tags ts.Tags, timestamp time.Time, value float64, unit time0.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", ctx, id, tags, timestamp, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+func (_m *MockdatabaseNamespace) QueryIDs(ctx context.Context, query index.Query, opts index.QueryOptions) (index.QueryResults, error) {
+	ret := _m.ctrl.Call(_m, "QueryIDs", ctx, query, opts)
+	ret0, _ := ret[0].(index.QueryResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) QueryIDs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "QueryIDs", arg0, arg1, arg2)
+}
+
 func (_m *MockdatabaseNamespace) ReadEncoded(ctx context.Context, id ts.ID, start time.Time, end time.Time) ([][]io.SegmentReader, error) {
 	ret := _m.ctrl.Call(_m, "ReadEncoded", ctx, id, start, end)
 	ret0, _ := ret[0].([][]io.SegmentReader)
@@ -526,6 +683,16 @@ func (_mr *_MockdatabaseNamespaceRecorder) ReadEncoded(arg0, arg1, arg2, arg3 in
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadEncoded", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockdatabaseNamespace) Delete(id ts.ID, start time.Time, end time.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2)
+}
+
 func (_m *MockdatabaseNamespace) FetchBlocks(ctx context.Context, shardID uint32, id ts.ID, starts []time.Time) ([]block.FetchBlockResult, error) {
 	ret := _m.ctrl.Call(_m, "FetchBlocks", ctx, shardID, id, starts)
 	ret0, _ := ret[0].([]block.FetchBlockResult)
@@ -579,6 +746,46 @@ func (_mr *_MockdatabaseNamespaceRecorder) NeedsFlush(arg0 interface{}) *gomock.
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "NeedsFlush", arg0)
 }
 
+func (_m *MockdatabaseNamespace) ColdFlush(flush persist.Flush) error {
+	ret := _m.ctrl.Call(_m, "ColdFlush", flush)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) ColdFlush(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdFlush", arg0)
+}
+
+func (_m *MockdatabaseNamespace) ColdWritesPending() bool {
+	ret := _m.ctrl.Call(_m, "ColdWritesPending")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) ColdWritesPending() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdWritesPending")
+}
+
+func (_m *MockdatabaseNamespace) Rollup(source Namespace, blockStarts []time.Time) error {
+	ret := _m.ctrl.Call(_m, "Rollup", source, blockStarts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) Rollup(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rollup", arg0, arg1)
+}
+
+func (_m *MockdatabaseNamespace) Snapshot(filePathPrefix string, t time.Time) error {
+	ret := _m.ctrl.Call(_m, "Snapshot", filePathPrefix, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) Snapshot(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Snapshot", arg0, arg1)
+}
+
 func (_m *MockdatabaseNamespace) CleanupFileset(earliestToRetain time.Time) error {
 	ret := _m.ctrl.Call(_m, "CleanupFileset", earliestToRetain)
 	ret0, _ := ret[0].(error)
@@ -589,6 +796,16 @@ func (_mr *_MockdatabaseNamespaceRecorder) CleanupFileset(arg0 interface{}) *gom
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "CleanupFileset", arg0)
 }
 
+func (_m *MockdatabaseNamespace) TierFilesets(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "TierFilesets", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) TierFilesets(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "TierFilesets", arg0)
+}
+
 func (_m *MockdatabaseNamespace) Truncate() (int64, error) {
 	ret := _m.ctrl.Call(_m, "Truncate")
 	ret0, _ := ret[0].(int64)
@@ -610,6 +827,26 @@ func (_mr *_MockdatabaseNamespaceRecorder) Repair(arg0, arg1 interface{}) *gomoc
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Repair", arg0, arg1)
 }
 
+func (_m *MockdatabaseNamespace) MarkBlockCorrupt(shardID uint32, blockStart time.Time) error {
+	ret := _m.ctrl.Call(_m, "MarkBlockCorrupt", shardID, blockStart)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) MarkBlockCorrupt(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MarkBlockCorrupt", arg0, arg1)
+}
+
+func (_m *MockdatabaseNamespace) Close() error {
+	ret := _m.ctrl.Call(_m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseNamespaceRecorder) Close() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Close")
+}
+
 // Mock of Shard interface
 type MockShard struct {
 	ctrl     *gomock.Controller
@@ -742,6 +979,16 @@ func (_mr *_MockdatabaseShardRecorder) Write(arg0, arg1, arg2, arg3, arg4, arg5
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Write", arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+func (_m *MockdatabaseShard) WriteTagged(ctx context.Context, id ts.ID, tags ts.Tags, timestamp time.Time, value float64, unit time0.Unit, annotation []byte) error {
+	ret := _m.ctrl.Call(_m, "WriteTagged", ctx, id, tags, timestamp, value, unit, annotation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) WriteTagged(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "WriteTagged", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 func (_m *MockdatabaseShard) ReadEncoded(ctx context.Context, id ts.ID, start time.Time, end time.Time) ([][]io.SegmentReader, error) {
 	ret := _m.ctrl.Call(_m, "ReadEncoded", ctx, id, start, end)
 	ret0, _ := ret[0].([][]io.SegmentReader)
@@ -753,6 +1000,16 @@ func (_mr *_MockdatabaseShardRecorder) ReadEncoded(arg0, arg1, arg2, arg3 interf
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "ReadEncoded", arg0, arg1, arg2, arg3)
 }
 
+func (_m *MockdatabaseShard) Delete(id ts.ID, start time.Time, end time.Time) error {
+	ret := _m.ctrl.Call(_m, "Delete", id, start, end)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) Delete(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Delete", arg0, arg1, arg2)
+}
+
 func (_m *MockdatabaseShard) FetchBlocks(ctx context.Context, id ts.ID, starts []time.Time) ([]block.FetchBlockResult, error) {
 	ret := _m.ctrl.Call(_m, "FetchBlocks", ctx, id, starts)
 	ret0, _ := ret[0].([]block.FetchBlockResult)
@@ -785,6 +1042,16 @@ func (_mr *_MockdatabaseShardRecorder) Bootstrap(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Bootstrap", arg0)
 }
 
+func (_m *MockdatabaseShard) Load(series map[ts.Hash]result.DatabaseSeriesBlocks) error {
+	ret := _m.ctrl.Call(_m, "Load", series)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) Load(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Load", arg0)
+}
+
 func (_m *MockdatabaseShard) Flush(namespace ts.ID, blockStart time.Time, flush persist.Flush) error {
 	ret := _m.ctrl.Call(_m, "Flush", namespace, blockStart, flush)
 	ret0, _ := ret[0].(error)
@@ -805,6 +1072,64 @@ func (_mr *_MockdatabaseShardRecorder) FlushState(arg0 interface{}) *gomock.Call
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "FlushState", arg0)
 }
 
+func (_m *MockdatabaseShard) MarkBlockCorrupt(blockStart time.Time) {
+	_m.ctrl.Call(_m, "MarkBlockCorrupt", blockStart)
+}
+
+func (_mr *_MockdatabaseShardRecorder) MarkBlockCorrupt(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "MarkBlockCorrupt", arg0)
+}
+
+func (_m *MockdatabaseShard) IsBlockCorrupt(blockStart time.Time) bool {
+	ret := _m.ctrl.Call(_m, "IsBlockCorrupt", blockStart)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) IsBlockCorrupt(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "IsBlockCorrupt", arg0)
+}
+
+func (_m *MockdatabaseShard) ColdFlush(namespace ts.ID, flush persist.Flush) error {
+	ret := _m.ctrl.Call(_m, "ColdFlush", namespace, flush)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) ColdFlush(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdFlush", arg0, arg1)
+}
+
+func (_m *MockdatabaseShard) ColdWritesPending() bool {
+	ret := _m.ctrl.Call(_m, "ColdWritesPending")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) ColdWritesPending() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdWritesPending")
+}
+
+func (_m *MockdatabaseShard) Rollup(ns namespace.Metadata, blockStarts []time.Time) error {
+	ret := _m.ctrl.Call(_m, "Rollup", ns, blockStarts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) Rollup(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rollup", arg0, arg1)
+}
+
+func (_m *MockdatabaseShard) Snapshot(namespace ts.ID, blockStart time.Time, filePathPrefix string) error {
+	ret := _m.ctrl.Call(_m, "Snapshot", namespace, blockStart, filePathPrefix)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) Snapshot(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Snapshot", arg0, arg1, arg2)
+}
+
 func (_m *MockdatabaseShard) CleanupFileset(namespace ts.ID, earliestToRetain time.Time) error {
 	ret := _m.ctrl.Call(_m, "CleanupFileset", namespace, earliestToRetain)
 	ret0, _ := ret[0].(error)
@@ -815,8 +1140,18 @@ func (_mr *_MockdatabaseShardRecorder) CleanupFileset(arg0, arg1 interface{}) *g
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "CleanupFileset", arg0, arg1)
 }
 
-func (_m *MockdatabaseShard) Repair(ctx context.Context, namespace ts.ID, tr time0.Range, repairer databaseShardRepairer) (repair.MetadataComparisonResult, error) {
-	ret := _m.ctrl.Call(_m, "Repair", ctx, namespace, tr, repairer)
+func (_m *MockdatabaseShard) TierFilesets(ns namespace.Metadata, olderThan time.Time) error {
+	ret := _m.ctrl.Call(_m, "TierFilesets", ns, olderThan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseShardRecorder) TierFilesets(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "TierFilesets", arg0, arg1)
+}
+
+func (_m *MockdatabaseShard) Repair(ctx context.Context, ns namespace.Metadata, tr time0.Range, repairer databaseShardRepairer) (repair.MetadataComparisonResult, error) {
+	ret := _m.ctrl.Call(_m, "Repair", ctx, ns, tr, repairer)
 	ret0, _ := ret[0].(repair.MetadataComparisonResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
@@ -896,14 +1231,14 @@ func (_m *MockdatabaseFlushManager) EXPECT() *_MockdatabaseFlushManagerRecorder
 	return _m.recorder
 }
 
-func (_m *MockdatabaseFlushManager) NeedsFlush(t time.Time) bool {
-	ret := _m.ctrl.Call(_m, "NeedsFlush", t)
+func (_m *MockdatabaseFlushManager) NeedsFlush(start time.Time, end time.Time) bool {
+	ret := _m.ctrl.Call(_m, "NeedsFlush", start, end)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-func (_mr *_MockdatabaseFlushManagerRecorder) NeedsFlush(arg0 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCall(_mr.mock, "NeedsFlush", arg0)
+func (_mr *_MockdatabaseFlushManagerRecorder) NeedsFlush(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "NeedsFlush", arg0, arg1)
 }
 
 func (_m *MockdatabaseFlushManager) FlushTimeStart(t time.Time) time.Time {
@@ -936,6 +1271,16 @@ func (_mr *_MockdatabaseFlushManagerRecorder) Flush(arg0 interface{}) *gomock.Ca
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Flush", arg0)
 }
 
+func (_m *MockdatabaseFlushManager) ColdFlush(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "ColdFlush", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseFlushManagerRecorder) ColdFlush(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdFlush", arg0)
+}
+
 func (_m *MockdatabaseFlushManager) Report() {
 	_m.ctrl.Call(_m, "Report")
 }
@@ -1044,6 +1389,66 @@ func (_mr *_MockFileOpOptionsRecorder) Jitter() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Jitter")
 }
 
+func (_m *MockFileOpOptions) SetSnapshotInterval(value time.Duration) FileOpOptions {
+	ret := _m.ctrl.Call(_m, "SetSnapshotInterval", value)
+	ret0, _ := ret[0].(FileOpOptions)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) SetSnapshotInterval(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetSnapshotInterval", arg0)
+}
+
+func (_m *MockFileOpOptions) SnapshotInterval() time.Duration {
+	ret := _m.ctrl.Call(_m, "SnapshotInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) SnapshotInterval() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SnapshotInterval")
+}
+
+func (_m *MockFileOpOptions) SetScrubInterval(value time.Duration) FileOpOptions {
+	ret := _m.ctrl.Call(_m, "SetScrubInterval", value)
+	ret0, _ := ret[0].(FileOpOptions)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) SetScrubInterval(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetScrubInterval", arg0)
+}
+
+func (_m *MockFileOpOptions) ScrubInterval() time.Duration {
+	ret := _m.ctrl.Call(_m, "ScrubInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) ScrubInterval() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ScrubInterval")
+}
+
+func (_m *MockFileOpOptions) SetScrubRateLimitOptions(value ratelimit.Options) FileOpOptions {
+	ret := _m.ctrl.Call(_m, "SetScrubRateLimitOptions", value)
+	ret0, _ := ret[0].(FileOpOptions)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) SetScrubRateLimitOptions(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetScrubRateLimitOptions", arg0)
+}
+
+func (_m *MockFileOpOptions) ScrubRateLimitOptions() ratelimit.Options {
+	ret := _m.ctrl.Call(_m, "ScrubRateLimitOptions")
+	ret0, _ := ret[0].(ratelimit.Options)
+	return ret0
+}
+
+func (_mr *_MockFileOpOptionsRecorder) ScrubRateLimitOptions() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ScrubRateLimitOptions")
+}
+
 func (_m *MockFileOpOptions) Validate() error {
 	ret := _m.ctrl.Call(_m, "Validate")
 	ret0, _ := ret[0].(error)
@@ -1054,6 +1459,84 @@ func (_mr *_MockFileOpOptionsRecorder) Validate() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Validate")
 }
 
+// Mock of databaseRollupManager interface
+type MockdatabaseRollupManager struct {
+	ctrl     *gomock.Controller
+	recorder *_MockdatabaseRollupManagerRecorder
+}
+
+// Recorder for MockdatabaseRollupManager (not exported)
+type _MockdatabaseRollupManagerRecorder struct {
+	mock *MockdatabaseRollupManager
+}
+
+func NewMockdatabaseRollupManager(ctrl *gomock.Controller) *MockdatabaseRollupManager {
+	mock := &MockdatabaseRollupManager{ctrl: ctrl}
+	mock.recorder = &_MockdatabaseRollupManagerRecorder{mock}
+	return mock
+}
+
+func (_m *MockdatabaseRollupManager) EXPECT() *_MockdatabaseRollupManagerRecorder {
+	return _m.recorder
+}
+
+func (_m *MockdatabaseRollupManager) Rollup(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "Rollup", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseRollupManagerRecorder) Rollup(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rollup", arg0)
+}
+
+func (_m *MockdatabaseRollupManager) Report() {
+	_m.ctrl.Call(_m, "Report")
+}
+
+func (_mr *_MockdatabaseRollupManagerRecorder) Report() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Report")
+}
+
+// Mock of databaseSnapshotManager interface
+type MockdatabaseSnapshotManager struct {
+	ctrl     *gomock.Controller
+	recorder *_MockdatabaseSnapshotManagerRecorder
+}
+
+// Recorder for MockdatabaseSnapshotManager (not exported)
+type _MockdatabaseSnapshotManagerRecorder struct {
+	mock *MockdatabaseSnapshotManager
+}
+
+func NewMockdatabaseSnapshotManager(ctrl *gomock.Controller) *MockdatabaseSnapshotManager {
+	mock := &MockdatabaseSnapshotManager{ctrl: ctrl}
+	mock.recorder = &_MockdatabaseSnapshotManagerRecorder{mock}
+	return mock
+}
+
+func (_m *MockdatabaseSnapshotManager) EXPECT() *_MockdatabaseSnapshotManagerRecorder {
+	return _m.recorder
+}
+
+func (_m *MockdatabaseSnapshotManager) Snapshot(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "Snapshot", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseSnapshotManagerRecorder) Snapshot(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Snapshot", arg0)
+}
+
+func (_m *MockdatabaseSnapshotManager) Report() {
+	_m.ctrl.Call(_m, "Report")
+}
+
+func (_mr *_MockdatabaseSnapshotManagerRecorder) Report() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Report")
+}
+
 // Mock of databaseFileSystemManager interface
 type MockdatabaseFileSystemManager struct {
 	ctrl     *gomock.Controller
@@ -1095,6 +1578,36 @@ func (_mr *_MockdatabaseFileSystemManagerRecorder) Flush(arg0 interface{}) *gomo
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Flush", arg0)
 }
 
+func (_m *MockdatabaseFileSystemManager) ColdFlush(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "ColdFlush", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseFileSystemManagerRecorder) ColdFlush(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "ColdFlush", arg0)
+}
+
+func (_m *MockdatabaseFileSystemManager) Rollup(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "Rollup", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseFileSystemManagerRecorder) Rollup(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Rollup", arg0)
+}
+
+func (_m *MockdatabaseFileSystemManager) Snapshot(t time.Time) error {
+	ret := _m.ctrl.Call(_m, "Snapshot", t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseFileSystemManagerRecorder) Snapshot(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Snapshot", arg0)
+}
+
 func (_m *MockdatabaseFileSystemManager) Disable() fileOpStatus {
 	ret := _m.ctrl.Call(_m, "Disable")
 	ret0, _ := ret[0].(fileOpStatus)
@@ -1174,8 +1687,8 @@ func (_mr *_MockdatabaseShardRepairerRecorder) Options() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Options")
 }
 
-func (_m *MockdatabaseShardRepairer) Repair(ctx context.Context, namespace ts.ID, tr time0.Range, shard databaseShard) (repair.MetadataComparisonResult, error) {
-	ret := _m.ctrl.Call(_m, "Repair", ctx, namespace, tr, shard)
+func (_m *MockdatabaseShardRepairer) Repair(ctx context.Context, ns namespace.Metadata, tr time0.Range, shard databaseShard) (repair.MetadataComparisonResult, error) {
+	ret := _m.ctrl.Call(_m, "Repair", ctx, ns, tr, shard)
 	ret0, _ := ret[0].(repair.MetadataComparisonResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
@@ -1240,6 +1753,61 @@ func (_mr *_MockdatabaseRepairerRecorder) Report() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "Report")
 }
 
+// Mock of databaseScrubber interface
+type MockdatabaseScrubber struct {
+	ctrl     *gomock.Controller
+	recorder *_MockdatabaseScrubberRecorder
+}
+
+// Recorder for MockdatabaseScrubber (not exported)
+type _MockdatabaseScrubberRecorder struct {
+	mock *MockdatabaseScrubber
+}
+
+func NewMockdatabaseScrubber(ctrl *gomock.Controller) *MockdatabaseScrubber {
+	mock := &MockdatabaseScrubber{ctrl: ctrl}
+	mock.recorder = &_MockdatabaseScrubberRecorder{mock}
+	return mock
+}
+
+func (_m *MockdatabaseScrubber) EXPECT() *_MockdatabaseScrubberRecorder {
+	return _m.recorder
+}
+
+func (_m *MockdatabaseScrubber) Start() {
+	_m.ctrl.Call(_m, "Start")
+}
+
+func (_mr *_MockdatabaseScrubberRecorder) Start() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Start")
+}
+
+func (_m *MockdatabaseScrubber) Stop() {
+	_m.ctrl.Call(_m, "Stop")
+}
+
+func (_mr *_MockdatabaseScrubberRecorder) Stop() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Stop")
+}
+
+func (_m *MockdatabaseScrubber) Scrub() error {
+	ret := _m.ctrl.Call(_m, "Scrub")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (_mr *_MockdatabaseScrubberRecorder) Scrub() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Scrub")
+}
+
+func (_m *MockdatabaseScrubber) Report() {
+	_m.ctrl.Call(_m, "Report")
+}
+
+func (_mr *_MockdatabaseScrubberRecorder) Report() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "Report")
+}
+
 // Mock of databaseTickManager interface
 type MockdatabaseTickManager struct {
 	ctrl     *gomock.Controller
@@ -1727,6 +2295,26 @@ func (_mr *_MockOptionsRecorder) DatabaseBlockRetrieverManager() *gomock.Call {
 	return _mr.mock.ctrl.RecordCall(_mr.mock, "DatabaseBlockRetrieverManager")
 }
 
+func (_m *MockOptions) SetNamespaceRegistry(value namespace.Registry) Options {
+	ret := _m.ctrl.Call(_m, "SetNamespaceRegistry", value)
+	ret0, _ := ret[0].(Options)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) SetNamespaceRegistry(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "SetNamespaceRegistry", arg0)
+}
+
+func (_m *MockOptions) NamespaceRegistry() namespace.Registry {
+	ret := _m.ctrl.Call(_m, "NamespaceRegistry")
+	ret0, _ := ret[0].(namespace.Registry)
+	return ret0
+}
+
+func (_mr *_MockOptionsRecorder) NamespaceRegistry() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "NamespaceRegistry")
+}
+
 func (_m *MockOptions) SetContextPool(value context.Pool) Options {
 	ret := _m.ctrl.Call(_m, "SetContextPool", value)
 	ret0, _ := ret[0].(Options)
@@ -0,0 +1,337 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/m3db/m3db/digest"
+	"github.com/m3db/m3db/encoding"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/time"
+)
+
+const (
+	tombstonesFileName = "tombstones.db"
+
+	// tombstoneHeaderLen is the length of the ID length prefix of a record
+	tombstoneHeaderLen = 4
+	// tombstoneTrailerLen is the length of the range and checksum of a record
+	tombstoneTrailerLen = 20
+)
+
+var (
+	errTombstoneInvalidRange     = errors.New("tombstone range start must be before its end")
+	errTombstoneChecksumMismatch = errors.New("tombstone checksum mismatch")
+)
+
+type tombstonedSeries struct {
+	id     []byte
+	ranges []xtime.Range
+}
+
+// shardTombstones tracks the time ranges of series deleted from a shard.
+// Tombstones are appended to a file in the shard directory so deletions
+// survive restarts, they are loaded lazily and kept until they expire.
+type shardTombstones struct {
+	sync.RWMutex
+
+	filePath string
+	fileMode os.FileMode
+	dirMode  os.FileMode
+	loaded   bool
+	byID     map[ts.Hash]tombstonedSeries
+}
+
+func newShardTombstones(namespace ts.ID, shard uint32, opts Options) *shardTombstones {
+	fsOpts := opts.CommitLogOptions().FilesystemOptions()
+	shardDir := fs.ShardDirPath(fsOpts.FilePathPrefix(), namespace, shard)
+	return &shardTombstones{
+		filePath: path.Join(shardDir, tombstonesFileName),
+		fileMode: fsOpts.NewFileMode(),
+		dirMode:  fsOpts.NewDirectoryMode(),
+		byID:     make(map[ts.Hash]tombstonedSeries),
+	}
+}
+
+// Ranges returns the deleted ranges of a series.
+func (t *shardTombstones) Ranges(id ts.ID) ([]xtime.Range, error) {
+	t.RLock()
+	if t.loaded {
+		ranges := t.byID[id.Hash()].ranges
+		t.RUnlock()
+		return ranges, nil
+	}
+	t.RUnlock()
+
+	t.Lock()
+	defer t.Unlock()
+	if err := t.loadWithLock(); err != nil {
+		return nil, err
+	}
+	return t.byID[id.Hash()].ranges, nil
+}
+
+// All returns the deleted ranges of every series.
+func (t *shardTombstones) All() ([]xtime.Range, error) {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.loadWithLock(); err != nil {
+		return nil, err
+	}
+	var all []xtime.Range
+	for _, series := range t.byID {
+		all = append(all, series.ranges...)
+	}
+	return all, nil
+}
+
+// Add persists a deleted range of a series before it takes effect.
+func (t *shardTombstones) Add(id ts.ID, r xtime.Range) error {
+	if !r.Start.Before(r.End) {
+		return errTombstoneInvalidRange
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if err := t.loadWithLock(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(t.filePath), t.dirMode); err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(t.filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, t.fileMode)
+	if err != nil {
+		return err
+	}
+	_, err = fd.Write(encodeTombstone(id.Data().Get(), r))
+	if err == nil {
+		err = fd.Sync()
+	}
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	t.addWithLock(id.Data().Get(), r)
+	return nil
+}
+
+// Expire removes the tombstones that end before the earliest time retained,
+// as the data they cover has expired, and rewrites the tombstones file.
+func (t *shardTombstones) Expire(earliestToRetain time.Time) error {
+	t.Lock()
+	defer t.Unlock()
+	if err := t.loadWithLock(); err != nil {
+		return err
+	}
+
+	var (
+		expired bool
+		buf     bytes.Buffer
+	)
+	for hash, series := range t.byID {
+		retained := series.ranges[:0:0]
+		for _, r := range series.ranges {
+			if !r.End.After(earliestToRetain) {
+				expired = true
+				continue
+			}
+			retained = append(retained, r)
+			buf.Write(encodeTombstone(series.id, r))
+		}
+		if len(retained) == 0 {
+			delete(t.byID, hash)
+			continue
+		}
+		series.ranges = retained
+		t.byID[hash] = series
+	}
+	if !expired {
+		return nil
+	}
+
+	if len(t.byID) == 0 {
+		if err := os.Remove(t.filePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	tmpFilePath := t.filePath + ".tmp"
+	fd, err := fs.OpenWritable(tmpFilePath, t.fileMode)
+	if err != nil {
+		return err
+	}
+	_, err = fd.Write(buf.Bytes())
+	if err == nil {
+		err = fd.Sync()
+	}
+	if closeErr := fd.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFilePath, t.filePath)
+}
+
+func (t *shardTombstones) loadWithLock() error {
+	if t.loaded {
+		return nil
+	}
+	data, err := ioutil.ReadFile(t.filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// NB: records are only ever appended so only the last record can be
+	// partially written, decoding stops at the first record that is invalid
+	for len(data) > 0 {
+		id, r, n, err := decodeTombstone(data)
+		if err != nil {
+			break
+		}
+		t.addWithLock(id, r)
+		data = data[n:]
+	}
+	t.loaded = true
+	return nil
+}
+
+func (t *shardTombstones) addWithLock(id []byte, r xtime.Range) {
+	hash := ts.HashFn(id)
+	series, ok := t.byID[hash]
+	if !ok {
+		series.id = append([]byte(nil), id...)
+	}
+	// Copy on add so ranges returned to readers are never mutated
+	ranges := make([]xtime.Range, 0, len(series.ranges)+1)
+	ranges = append(ranges, series.ranges...)
+	series.ranges = append(ranges, r)
+	t.byID[hash] = series
+}
+
+func encodeTombstone(id []byte, r xtime.Range) []byte {
+	data := make([]byte, tombstoneHeaderLen+len(id)+tombstoneTrailerLen)
+	binary.LittleEndian.PutUint32(data, uint32(len(id)))
+	copy(data[tombstoneHeaderLen:], id)
+	trailer := data[tombstoneHeaderLen+len(id):]
+	binary.LittleEndian.PutUint64(trailer, uint64(r.Start.UnixNano()))
+	binary.LittleEndian.PutUint64(trailer[8:], uint64(r.End.UnixNano()))
+	checksum := digest.Checksum(data[:len(data)-4])
+	binary.LittleEndian.PutUint32(data[len(data)-4:], checksum)
+	return data
+}
+
+func decodeTombstone(data []byte) ([]byte, xtime.Range, int, error) {
+	if len(data) < tombstoneHeaderLen {
+		return nil, xtime.Range{}, 0, io.ErrUnexpectedEOF
+	}
+	idLen := int(binary.LittleEndian.Uint32(data))
+	n := tombstoneHeaderLen + idLen + tombstoneTrailerLen
+	if len(data) < n {
+		return nil, xtime.Range{}, 0, io.ErrUnexpectedEOF
+	}
+	checksum := binary.LittleEndian.Uint32(data[n-4:])
+	if digest.Checksum(data[:n-4]) != checksum {
+		return nil, xtime.Range{}, 0, errTombstoneChecksumMismatch
+	}
+	id := data[tombstoneHeaderLen : tombstoneHeaderLen+idLen]
+	trailer := data[tombstoneHeaderLen+idLen:]
+	r := xtime.Range{
+		Start: xtime.FromNanoseconds(int64(binary.LittleEndian.Uint64(trailer))),
+		End:   xtime.FromNanoseconds(int64(binary.LittleEndian.Uint64(trailer[8:]))),
+	}
+	return id, r, n, nil
+}
+
+// tombstoned returns whether a timestamp falls within any deleted range.
+func tombstoned(ranges []xtime.Range, t time.Time) bool {
+	for _, r := range ranges {
+		if !t.Before(r.Start) && t.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsTombstones returns whether any deleted range overlaps [start, end).
+func overlapsTombstones(ranges []xtime.Range, start, end time.Time) bool {
+	for _, r := range ranges {
+		if r.Start.Before(end) && start.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTombstoned merges the readers of a series and re-encodes the
+// datapoints that have not been deleted, returning false if none remain.
+func filterTombstoned(
+	opts Options,
+	readers []io.Reader,
+	ranges []xtime.Range,
+) (ts.Segment, bool, error) {
+	iter := opts.MultiReaderIteratorPool().Get()
+	iter.Reset(readers)
+	defer iter.Close()
+
+	var (
+		encoder   encoding.Encoder
+		blockSize = opts.RetentionOptions().BlockSize()
+	)
+	for iter.Next() {
+		dp, unit, annotation := iter.Current()
+		if tombstoned(ranges, dp.Timestamp) {
+			continue
+		}
+		if encoder == nil {
+			// Encode from the start of the block of the first datapoint retained
+			encoder = opts.EncoderPool().Get()
+			encoder.Reset(dp.Timestamp.Truncate(blockSize), 0)
+		}
+		if err := encoder.Encode(dp, unit, annotation); err != nil {
+			encoder.Close()
+			return ts.Segment{}, false, err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		if encoder != nil {
+			encoder.Close()
+		}
+		return ts.Segment{}, false, err
+	}
+	if encoder == nil {
+		return ts.Segment{}, false, nil
+	}
+	return encoder.Discard(), true, nil
+}
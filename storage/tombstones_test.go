@@ -0,0 +1,151 @@
+// Copyright (c) 2017 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/m3db/m3db/ts"
+	"github.com/m3db/m3x/time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testTombstonesOptions(t *testing.T, now time.Time) (Options, string) {
+	dir, err := ioutil.TempDir("", "tombstones")
+	require.NoError(t, err)
+
+	opts := testDatabaseOptions()
+	clOpts := opts.CommitLogOptions()
+	fsOpts := clOpts.FilesystemOptions().SetFilePathPrefix(dir)
+	opts = opts.
+		SetCommitLogOptions(clOpts.SetFilesystemOptions(fsOpts)).
+		SetClockOptions(opts.ClockOptions().SetNowFn(func() time.Time {
+			return now
+		}))
+	return opts, dir
+}
+
+func TestShardTombstonesAddAndLoad(t *testing.T) {
+	opts, dir := testTombstonesOptions(t, time.Now())
+	defer os.RemoveAll(dir)
+
+	start := time.Unix(7200, 0)
+	foo, bar := ts.StringID("foo"), ts.StringID("bar")
+	first := xtime.Range{Start: start, End: start.Add(time.Hour)}
+	second := xtime.Range{Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)}
+
+	tombstones := newShardTombstones(testNamespaceID, 0, opts)
+	require.Equal(t, errTombstoneInvalidRange,
+		tombstones.Add(foo, xtime.Range{Start: start, End: start}))
+	require.NoError(t, tombstones.Add(foo, first))
+	require.NoError(t, tombstones.Add(foo, second))
+	require.NoError(t, tombstones.Add(bar, first))
+
+	// Simulate a torn write of a trailing record
+	fd, err := os.OpenFile(tombstones.filePath, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = fd.Write(encodeTombstone([]byte("baz"), first)[:10])
+	require.NoError(t, err)
+	require.NoError(t, fd.Close())
+
+	loaded := newShardTombstones(testNamespaceID, 0, opts)
+	ranges, err := loaded.Ranges(foo)
+	require.NoError(t, err)
+	require.Equal(t, []xtime.Range{first, second}, ranges)
+	ranges, err = loaded.Ranges(bar)
+	require.NoError(t, err)
+	require.Equal(t, []xtime.Range{first}, ranges)
+	ranges, err = loaded.Ranges(ts.StringID("baz"))
+	require.NoError(t, err)
+	require.Empty(t, ranges)
+
+	all, err := loaded.All()
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+}
+
+func TestShardTombstonesExpire(t *testing.T) {
+	opts, dir := testTombstonesOptions(t, time.Now())
+	defer os.RemoveAll(dir)
+
+	start := time.Unix(7200, 0)
+	foo := ts.StringID("foo")
+	first := xtime.Range{Start: start, End: start.Add(time.Hour)}
+	second := xtime.Range{Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)}
+
+	tombstones := newShardTombstones(testNamespaceID, 0, opts)
+	require.NoError(t, tombstones.Add(foo, first))
+	require.NoError(t, tombstones.Add(foo, second))
+
+	require.NoError(t, tombstones.Expire(first.End))
+	ranges, err := newShardTombstones(testNamespaceID, 0, opts).Ranges(foo)
+	require.NoError(t, err)
+	require.Equal(t, []xtime.Range{second}, ranges)
+
+	require.NoError(t, tombstones.Expire(second.End))
+	_, err = os.Stat(tombstones.filePath)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestShardDeleteFiltersReads(t *testing.T) {
+	now := time.Now().Truncate(time.Hour).Add(30 * time.Minute)
+	opts, dir := testTombstonesOptions(t, now)
+	defer os.RemoveAll(dir)
+
+	shard := testDatabaseShard(opts)
+	defer shard.Close()
+
+	ctx := opts.ContextPool().Get()
+	defer ctx.Close()
+
+	id := ts.StringID("foo")
+	for i := 3; i > 0; i-- {
+		require.NoError(t, shard.Write(ctx, id, now.Add(-time.Duration(i)*time.Minute),
+			float64(i), xtime.Second, nil))
+	}
+
+	blockStart := now.Truncate(opts.RetentionOptions().BlockSize())
+	shard.flushState.statesByTime[blockStart] = fileOpState{Status: fileOpSuccess}
+
+	deleteStart := now.Add(-150 * time.Second)
+	require.NoError(t, shard.Delete(id, deleteStart, deleteStart.Add(time.Minute)))
+	require.Equal(t, fileOpState{Status: fileOpNotStarted}, shard.FlushState(blockStart))
+	require.True(t, shard.needsRewrite(blockStart))
+
+	encoded, err := shard.ReadEncoded(ctx, id, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+
+	var values []float64
+	for _, readers := range encoded {
+		iter := opts.MultiReaderIteratorPool().Get()
+		iter.Reset(toReaders(readers))
+		for iter.Next() {
+			dp, _, _ := iter.Current()
+			values = append(values, dp.Value)
+		}
+		require.NoError(t, iter.Err())
+		iter.Close()
+	}
+	require.Equal(t, []float64{3, 1}, values)
+}
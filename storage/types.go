@@ -29,12 +29,15 @@ import (
 	"github.com/m3db/m3db/encoding"
 	"github.com/m3db/m3db/persist"
 	"github.com/m3db/m3db/persist/fs/commitlog"
+	"github.com/m3db/m3db/ratelimit"
 	"github.com/m3db/m3db/retention"
 	"github.com/m3db/m3db/runtime"
 	"github.com/m3db/m3db/sharding"
 	"github.com/m3db/m3db/storage/block"
 	"github.com/m3db/m3db/storage/bootstrap"
 	"github.com/m3db/m3db/storage/bootstrap/result"
+	"github.com/m3db/m3db/storage/index"
+	"github.com/m3db/m3db/storage/namespace"
 	"github.com/m3db/m3db/storage/repair"
 	"github.com/m3db/m3db/storage/series"
 	"github.com/m3db/m3db/ts"
@@ -73,6 +76,26 @@ type Database interface {
 		annotation []byte,
 	) error
 
+	// WriteTagged writes a value for an ID and indexes the ID by its tags
+	WriteTagged(
+		ctx context.Context,
+		namespace ts.ID,
+		id ts.ID,
+		tags ts.Tags,
+		timestamp time.Time,
+		value float64,
+		unit xtime.Unit,
+		annotation []byte,
+	) error
+
+	// QueryIDs resolves a tag query to the IDs of the matching series
+	QueryIDs(
+		ctx context.Context,
+		namespace ts.ID,
+		query index.Query,
+		opts index.QueryOptions,
+	) (index.QueryResults, error)
+
 	// ReadEncoded retrieves encoded segments for an ID
 	ReadEncoded(
 		ctx context.Context,
@@ -117,6 +140,16 @@ type Database interface {
 
 	// Truncate truncates data for the given namespace
 	Truncate(namespace ts.ID) (int64, error)
+
+	// Delete deletes the data of an ID within [start, end) for the given namespace
+	Delete(namespace ts.ID, id ts.ID, start, end time.Time) error
+
+	// AddNamespace creates a namespace and bootstraps it if the
+	// database has already been bootstrapped
+	AddNamespace(md namespace.Metadata) error
+
+	// RemoveNamespace retires a namespace, its data files are left on disk
+	RemoveNamespace(id ts.ID) error
 }
 
 // database is the internal database interface
@@ -125,6 +158,10 @@ type database interface {
 
 	// getOwnedNamespaces returns the namespaces this database owns.
 	getOwnedNamespaces() []databaseNamespace
+
+	// rotateCommitLog rotates the commit log to a new file and returns it,
+	// writes enqueued before the rotation are in earlier commit log files.
+	rotateCommitLog() (commitlog.File, error)
 }
 
 // Namespace is a time series database namespace
@@ -137,6 +174,9 @@ type Namespace interface {
 
 	// Shards returns the shards
 	Shards() []Shard
+
+	// Options returns the namespace options
+	Options() namespace.Options
 }
 
 // NamespacesByID is a sortable slice of namespaces by ID
@@ -167,6 +207,24 @@ type databaseNamespace interface {
 		annotation []byte,
 	) error
 
+	// WriteTagged writes a data point and indexes the ID by its tags
+	WriteTagged(
+		ctx context.Context,
+		id ts.ID,
+		tags ts.Tags,
+		timestamp time.Time,
+		value float64,
+		unit xtime.Unit,
+		annotation []byte,
+	) error
+
+	// QueryIDs resolves a tag query to the IDs of the matching series
+	QueryIDs(
+		ctx context.Context,
+		query index.Query,
+		opts index.QueryOptions,
+	) (index.QueryResults, error)
+
 	// ReadEncoded reads data for given id within [start, end)
 	ReadEncoded(
 		ctx context.Context,
@@ -174,6 +232,9 @@ type databaseNamespace interface {
 		start, end time.Time,
 	) ([][]xio.SegmentReader, error)
 
+	// Delete deletes the data of an ID within [start, end)
+	Delete(id ts.ID, start, end time.Time) error
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -204,14 +265,39 @@ type databaseNamespace interface {
 	// NeedsFlush returns true if the namespace needs a flush for a block start.
 	NeedsFlush(blockStart time.Time) bool
 
+	// ColdFlush merges cold writes into the flushed filesets of their blocks
+	ColdFlush(flush persist.Flush) error
+
+	// ColdWritesPending returns whether the namespace has cold writes not yet
+	// merged into the flushed filesets of their blocks.
+	ColdWritesPending() bool
+
+	// Rollup rolls up the flushed blocks of the source namespace into this namespace
+	Rollup(source Namespace, blockStarts []time.Time) error
+
+	// Snapshot writes the unflushed in-memory data of the namespace as of
+	// time t to snapshot filesets under the file path prefix
+	Snapshot(filePathPrefix string, t time.Time) error
+
 	// CleanupFileset cleans up fileset files
 	CleanupFileset(earliestToRetain time.Time) error
 
+	// TierFilesets moves the filesets older than the age of the tiering
+	// policy as of time t to the secondary tier
+	TierFilesets(t time.Time) error
+
 	// Truncate truncates the in-memory data for this namespace
 	Truncate() (int64, error)
 
 	// Repair repairs the namespace data for a given time range
 	Repair(repairer databaseShardRepairer, tr xtime.Range) error
+
+	// MarkBlockCorrupt marks the flushed fileset of a shard and block start
+	// as corrupt so the block is re-fetched from peers by the next repair.
+	MarkBlockCorrupt(shardID uint32, blockStart time.Time) error
+
+	// Close closes the namespace shards
+	Close() error
 }
 
 // Shard is a time series database shard
@@ -244,12 +330,28 @@ type databaseShard interface {
 		annotation []byte,
 	) error
 
+	// WriteTagged writes a value and records the tags of the series
+	// in the commit log so the index can be rebuilt on bootstrap
+	WriteTagged(
+		ctx context.Context,
+		id ts.ID,
+		tags ts.Tags,
+		timestamp time.Time,
+		value float64,
+		unit xtime.Unit,
+		annotation []byte,
+	) error
+
 	ReadEncoded(
 		ctx context.Context,
 		id ts.ID,
 		start, end time.Time,
 	) ([][]xio.SegmentReader, error)
 
+	// Delete tombstones the data of an ID within [start, end) so it is no
+	// longer read and is compacted out of filesets when they are rewritten.
+	Delete(id ts.ID, start, end time.Time) error
+
 	// FetchBlocks retrieves data blocks for a given id and a list of block start times.
 	FetchBlocks(
 		ctx context.Context,
@@ -270,6 +372,13 @@ type databaseShard interface {
 		bootstrappedSeries map[ts.Hash]result.DatabaseSeriesBlocks,
 	) error
 
+	// Load merges series blocks retrieved from elsewhere, such as from
+	// peers during a repair, into the shard and marks any affected block
+	// starts that were already flushed to be flushed again.
+	Load(
+		series map[ts.Hash]result.DatabaseSeriesBlocks,
+	) error
+
 	// Flush flushes the series in this shard.
 	Flush(
 		namespace ts.ID,
@@ -280,13 +389,51 @@ type databaseShard interface {
 	// FlushState returns the flush state for this shard at block start.
 	FlushState(blockStart time.Time) fileOpState
 
+	// MarkBlockCorrupt marks the flushed fileset of a block start as corrupt
+	// so the block is re-fetched from peers by the next repair.
+	MarkBlockCorrupt(blockStart time.Time)
+
+	// IsBlockCorrupt returns whether the flushed fileset of a block start
+	// has been marked as corrupt.
+	IsBlockCorrupt(blockStart time.Time) bool
+
+	// ColdFlush merges the cold writes of the series in this shard into
+	// the flushed filesets of their blocks.
+	ColdFlush(
+		namespace ts.ID,
+		flush persist.Flush,
+	) error
+
+	// ColdWritesPending returns whether the shard has cold writes not yet
+	// merged into the flushed filesets of their blocks.
+	ColdWritesPending() bool
+
+	// Rollup rolls up the flushed blocks of the source namespace that have
+	// not been rolled up yet into the shard, in ascending block start order.
+	Rollup(
+		ns namespace.Metadata,
+		blockStarts []time.Time,
+	) error
+
+	// Snapshot writes the unflushed in-memory data of the shard for a
+	// block start to a snapshot fileset under the file path prefix.
+	Snapshot(
+		namespace ts.ID,
+		blockStart time.Time,
+		filePathPrefix string,
+	) error
+
 	// CleanupFileset cleans up fileset files
 	CleanupFileset(namespace ts.ID, earliestToRetain time.Time) error
 
+	// TierFilesets moves the filesets of blocks that ended before a given
+	// time to the secondary tier
+	TierFilesets(ns namespace.Metadata, olderThan time.Time) error
+
 	// Repair repairs the shard data for a given time
 	Repair(
 		ctx context.Context,
-		namespace ts.ID,
+		ns namespace.Metadata,
 		tr xtime.Range,
 		repairer databaseShardRepairer,
 	) (repair.MetadataComparisonResult, error)
@@ -306,18 +453,24 @@ type databaseBootstrapManager interface {
 
 // databaseFlushManager manages flushing in-memory data to persistent storage.
 type databaseFlushManager interface {
-	// NeedsFlush returns true if the data for a given time have been flushed.
-	NeedsFlush(t time.Time) bool
+	// NeedsFlush returns true if any namespace has data that needs to be
+	// flushed for a block overlapping the time range [start, end).
+	NeedsFlush(start, end time.Time) bool
 
-	// FlushTimeStart is the earliest flushable time.
+	// FlushTimeStart is the earliest flushable time using the database
+	// retention options.
 	FlushTimeStart(t time.Time) time.Time
 
-	// FlushTimeEnd is the latest flushable time.
+	// FlushTimeEnd is the latest flushable time using the database
+	// retention options.
 	FlushTimeEnd(t time.Time) time.Time
 
 	// Flush flushes in-memory data to persistent storage.
 	Flush(t time.Time) error
 
+	// ColdFlush merges cold writes into the flushed filesets of their blocks.
+	ColdFlush(t time.Time) error
+
 	// Report reports runtime information
 	Report()
 }
@@ -345,10 +498,53 @@ type FileOpOptions interface {
 	// Jitter returns the jitter for database file operations
 	Jitter() time.Duration
 
+	// SetSnapshotInterval sets the interval between snapshots of unflushed
+	// data, zero disables snapshotting
+	SetSnapshotInterval(value time.Duration) FileOpOptions
+
+	// SnapshotInterval returns the interval between snapshots of unflushed data
+	SnapshotInterval() time.Duration
+
+	// SetScrubInterval sets the interval between verifications of each
+	// flushed fileset, zero disables scrubbing
+	SetScrubInterval(value time.Duration) FileOpOptions
+
+	// ScrubInterval returns the interval between verifications of each
+	// flushed fileset
+	ScrubInterval() time.Duration
+
+	// SetScrubRateLimitOptions sets the rate limit options for reading
+	// filesets when scrubbing
+	SetScrubRateLimitOptions(value ratelimit.Options) FileOpOptions
+
+	// ScrubRateLimitOptions returns the rate limit options for reading
+	// filesets when scrubbing
+	ScrubRateLimitOptions() ratelimit.Options
+
 	// Validate validates the options
 	Validate() error
 }
 
+// databaseRollupManager rolls up flushed blocks of source namespaces into
+// their rollup namespaces.
+type databaseRollupManager interface {
+	// Rollup rolls up the flushed blocks that have not been rolled up yet.
+	Rollup(t time.Time) error
+
+	// Report reports runtime information
+	Report()
+}
+
+// databaseSnapshotManager snapshots unflushed in-memory data so that the
+// commit logs it covers need not be replayed when bootstrapping.
+type databaseSnapshotManager interface {
+	// Snapshot snapshots unflushed data if the snapshot interval has elapsed.
+	Snapshot(t time.Time) error
+
+	// Report reports runtime information
+	Report()
+}
+
 // databaseFileSystemManager manages the database related filesystem activities.
 type databaseFileSystemManager interface {
 	// Cleanup cleans up data not needed in the persistent storage.
@@ -357,6 +553,15 @@ type databaseFileSystemManager interface {
 	// Flush flushes in-memory data to persistent storage.
 	Flush(t time.Time) error
 
+	// ColdFlush merges cold writes into the flushed filesets of their blocks.
+	ColdFlush(t time.Time) error
+
+	// Rollup rolls up flushed blocks of source namespaces into their rollup namespaces.
+	Rollup(t time.Time) error
+
+	// Snapshot snapshots unflushed data if the snapshot interval has elapsed.
+	Snapshot(t time.Time) error
+
 	// Disable disables the filesystem manager and prevents it from
 	// performing file operations, returns the current file operation status
 	Disable() fileOpStatus
@@ -383,7 +588,7 @@ type databaseShardRepairer interface {
 	// Repair repairs the data for a given namespace and shard
 	Repair(
 		ctx context.Context,
-		namespace ts.ID,
+		ns namespace.Metadata,
 		tr xtime.Range,
 		shard databaseShard,
 	) (repair.MetadataComparisonResult, error)
@@ -404,6 +609,21 @@ type databaseRepairer interface {
 	Report()
 }
 
+// databaseScrubber periodically verifies flushed filesets
+type databaseScrubber interface {
+	// Start starts the scrub process
+	Start()
+
+	// Stop stops the scrub process
+	Stop()
+
+	// Scrub verifies the flushed filesets not verified within the scrub interval
+	Scrub() error
+
+	// Report reports runtime information
+	Report()
+}
+
 // databaseTickManager performs periodic ticking
 type databaseTickManager interface {
 	// Tick performs maintenance operations, restarting the current
@@ -554,6 +774,14 @@ type Options interface {
 	// containing data.
 	DatabaseBlockRetrieverManager() block.DatabaseBlockRetrieverManager
 
+	// SetNamespaceRegistry sets the namespace registry the database watches
+	// to add and remove namespaces at runtime, if not set the namespaces
+	// only change through calls to the database
+	SetNamespaceRegistry(value namespace.Registry) Options
+
+	// NamespaceRegistry returns the namespace registry the database watches
+	NamespaceRegistry() namespace.Registry
+
 	// SetContextPool sets the contextPool
 	SetContextPool(value context.Pool) Options
 
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/m3db/m3db/persist/encoding/msgpack"
+	"github.com/m3db/m3db/persist/fs"
+	"github.com/m3db/m3db/ts"
+	xlog "github.com/m3db/m3x/log"
+
+	"github.com/pborman/getopt"
+)
+
+const (
+	defaultBufferReadSize = 65536
+)
+
+func main() {
+	var (
+		optPathPrefix = getopt.StringLong("path-prefix", 'p', "", "Path prefix [e.g. /var/lib/m3db]")
+		optNamespace  = getopt.StringLong("namespace", 'n', "", "Namespace, all namespaces if unset [e.g. metrics]")
+		optShards     = getopt.StringLong("shards", 's', "", "Shards, all shards if unset [e.g. 1,2,3]")
+		log           = xlog.NewLogger(os.Stderr)
+	)
+	getopt.Parse()
+
+	if *optPathPrefix == "" {
+		getopt.Usage()
+		os.Exit(1)
+	}
+
+	namespaces, err := listDirs(fs.DataDirPath(*optPathPrefix))
+	if err != nil {
+		log.Fatalf("unable to list namespaces: %v", err)
+	}
+	if *optNamespace != "" {
+		namespaces = []string{*optNamespace}
+	}
+
+	var filterShards []string
+	if *optShards != "" {
+		for _, str := range strings.Split(*optShards, ",") {
+			if _, err := strconv.ParseUint(str, 10, 32); err != nil {
+				log.Fatalf("could not parse shard '%s': %v", str, err)
+			}
+			filterShards = append(filterShards, str)
+		}
+	}
+
+	var (
+		reader = fs.NewReader(*optPathPrefix, defaultBufferReadSize, nil,
+			msgpack.NewDecodingOptions())
+		verified int
+		corrupt  int
+	)
+	for _, namespace := range namespaces {
+		nsID := ts.StringID(namespace)
+		shards := filterShards
+		if shards == nil {
+			shards, err = listDirs(fs.NamespaceDirPath(*optPathPrefix, nsID))
+			if err != nil {
+				log.Fatalf("unable to list shards for namespace %s: %v", namespace, err)
+			}
+		}
+
+		for _, str := range shards {
+			value, err := strconv.ParseUint(str, 10, 32)
+			if err != nil {
+				// Not a shard directory
+				continue
+			}
+			shard := uint32(value)
+
+			blockStarts, err := fs.FilesetBlockStarts(*optPathPrefix, nsID, shard)
+			if err != nil {
+				log.Fatalf("unable to list filesets for namespace %s shard %d: %v",
+					namespace, shard, err)
+			}
+
+			for _, blockStart := range blockStarts {
+				verified++
+				err := fs.VerifyFileset(reader, nsID, shard, blockStart, nil)
+				if err == nil {
+					continue
+				}
+				corrupt++
+				fmt.Printf("namespace=%s shard=%d blockStart=%d: %v\n",
+					namespace, shard, blockStart.UnixNano(), err)
+			}
+		}
+	}
+
+	log.Infof("verified %d filesets, %d corrupt", verified, corrupt)
+	if corrupt > 0 {
+		os.Exit(1)
+	}
+}
+
+func listDirs(dirPath string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs, nil
+}
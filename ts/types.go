@@ -59,6 +59,15 @@ type IdentifierPool interface {
 	Clone(other ID) ID
 }
 
+// Tag represents a name/value pair describing a timeseries.
+type Tag struct {
+	Name  ID
+	Value ID
+}
+
+// Tags is a set of tags describing a timeseries.
+type Tags []Tag
+
 // Hash represents a form of ID suitable to be used as map keys.
 type Hash [md5.Size]byte
 